@@ -0,0 +1,167 @@
+// Package discord announces new reviews and comments to a Discord channel
+// through an incoming webhook.
+package discord
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/mtlynch/screenjournal/v2/announce/routes"
+	"github.com/mtlynch/screenjournal/v2/markdown"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/stars"
+)
+
+// Discord rejects embed descriptions longer than 4096 characters, but we keep
+// announcements much shorter so they don't flood the channel.
+const maxDescriptionLength = 500
+
+// embedColor is the accent color on the left side of the embed.
+const embedColor = 0x0d6efd
+
+type (
+	Announcer struct {
+		baseURL    string
+		webhookURL string
+		client     *http.Client
+	}
+
+	message struct {
+		Username        string          `json:"username"`
+		Embeds          []embed         `json:"embeds"`
+		AllowedMentions allowedMentions `json:"allowed_mentions"`
+	}
+
+	embed struct {
+		Title       string       `json:"title"`
+		URL         string       `json:"url"`
+		Description string       `json:"description,omitempty"`
+		Color       int          `json:"color"`
+		Author      embedAuthor  `json:"author"`
+		Thumbnail   *embedImage  `json:"thumbnail,omitempty"`
+		Fields      []embedField `json:"fields,omitempty"`
+	}
+
+	embedAuthor struct {
+		Name string `json:"name"`
+	}
+
+	embedImage struct {
+		URL string `json:"url"`
+	}
+
+	embedField struct {
+		Name   string `json:"name"`
+		Value  string `json:"value"`
+		Inline bool   `json:"inline"`
+	}
+
+	// allowedMentions prevents text from users, like "@everyone" in a blurb,
+	// from pinging the channel.
+	allowedMentions struct {
+		Parse []string `json:"parse"`
+	}
+)
+
+func New(baseURL, webhookURL string, client *http.Client) Announcer {
+	return Announcer{
+		baseURL:    baseURL,
+		webhookURL: webhookURL,
+		client:     client,
+	}
+}
+
+func (a Announcer) AnnounceNewReview(r screenjournal.Review) error {
+	e := embed{
+		Title:       routes.Title(r).String() + routes.SeasonSuffix(r),
+		URL:         a.baseURL + routes.Review(r),
+		Description: truncate(markdown.RenderBlurbAsPlaintext(r.Blurb), maxDescriptionLength),
+		Color:       embedColor,
+		Author: embedAuthor{
+			Name: fmt.Sprintf("%s posted a new review", r.Owner),
+		},
+	}
+	if poster := routes.Poster(r); poster != "" {
+		e.Thumbnail = &embedImage{URL: poster}
+	}
+	if !r.Rating.IsNil() {
+		e.Fields = append(e.Fields, embedField{
+			Name:   "Rating",
+			Value:  stars.Plaintext(r.Rating),
+			Inline: true,
+		})
+	}
+	return a.post(e)
+}
+
+func (a Announcer) AnnounceNewComment(rc screenjournal.ReviewComment) error {
+	e := embed{
+		Title:       fmt.Sprintf("%s's review of %s%s", rc.Review.Owner, routes.Title(rc.Review), routes.SeasonSuffix(rc.Review)),
+		URL:         a.baseURL + routes.Comment(rc),
+		Description: truncate(markdown.RenderCommentAsPlaintext(rc.CommentText), maxDescriptionLength),
+		Color:       embedColor,
+		Author: embedAuthor{
+			Name: fmt.Sprintf("%s commented", rc.Owner),
+		},
+	}
+	if poster := routes.Poster(rc.Review); poster != "" {
+		e.Thumbnail = &embedImage{URL: poster}
+	}
+	return a.post(e)
+}
+
+func (a Announcer) AnnounceNewReaction(rr screenjournal.ReviewReaction) error {
+	// Reactions are too small to be worth a channel message.
+	log.Printf("not sending reaction from %s to Discord", rr.Owner)
+	return nil
+}
+
+func (a Announcer) AnnounceNewFriendRecommendation(fr screenjournal.FriendRecommendation) error {
+	// Recommendations are private between two users, so they don't go to a
+	// shared channel.
+	log.Printf("not sending recommendation from %s to %s to Discord", fr.From, fr.To)
+	return nil
+}
+
+func (a Announcer) AnnounceMention(m screenjournal.Mention) error {
+	// The channel already receives the review or comment that contains the
+	// mention.
+	log.Printf("not sending mention of %s to Discord", m.Mentioned)
+	return nil
+}
+
+func (a Announcer) post(e embed) error {
+	body, err := json.Marshal(message{
+		Username:        "ScreenJournal",
+		Embeds:          []embed{e},
+		AllowedMentions: allowedMentions{Parse: []string{}},
+	})
+	if err != nil {
+		return fmt.Errorf("serialize Discord message: %w", err)
+	}
+
+	res, err := a.client.Post(a.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("post message to Discord: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		details, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("Discord rejected message with HTTP %d: %s", res.StatusCode, details)
+	}
+
+	return nil
+}
+
+func truncate(s string, maxLength int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLength {
+		return s
+	}
+	return string(runes[:maxLength-1]) + "…"
+}
@@ -0,0 +1,167 @@
+package discord_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/announce/discord"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+// mockDiscord records the messages that it receives from the announcer.
+type mockDiscord struct {
+	messages []map[string]any
+}
+
+func (m *mockDiscord) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var msg map[string]any
+	if err := json.Unmarshal(body, &msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m.messages = append(m.messages, msg)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestAnnounceNewReview(t *testing.T) {
+	mock := mockDiscord{}
+	server := httptest.NewServer(&mock)
+	defer server.Close()
+
+	announcer := discord.New("https://dev.thescreenjournal.com", server.URL, server.Client())
+	if err := announcer.AnnounceNewReview(screenjournal.Review{
+		ID:     screenjournal.ReviewID(7),
+		Owner:  screenjournal.Username("alice"),
+		Rating: screenjournal.NewRating(7),
+		Blurb:  screenjournal.Blurb("Funny!\n\n!spoilers\n\nSandler wins the game."),
+		Movie: screenjournal.Movie{
+			ID:         screenjournal.MovieID(12),
+			Title:      screenjournal.MediaTitle("The Waterboy"),
+			PosterPath: url.URL{Path: "/the-waterboy.jpg"},
+		},
+	}); err != nil {
+		t.Fatalf("AnnounceNewReview err=%v, want=%v", err, nil)
+	}
+
+	if got, want := len(mock.messages), 1; got != want {
+		t.Fatalf("messages=%d, want=%d", got, want)
+	}
+	if got, want := mock.messages[0], map[string]any{
+		"username": "ScreenJournal",
+		"embeds": []any{
+			map[string]any{
+				"title":       "The Waterboy",
+				"url":         "https://dev.thescreenjournal.com/movies/12#review7",
+				"description": "Funny!",
+				"color":       float64(0x0d6efd),
+				"author": map[string]any{
+					"name": "alice posted a new review",
+				},
+				"thumbnail": map[string]any{
+					"url": "https://image.tmdb.org/t/p/w185/the-waterboy.jpg",
+				},
+				"fields": []any{
+					map[string]any{
+						"name":   "Rating",
+						"value":  "★★★½☆",
+						"inline": true,
+					},
+				},
+			},
+		},
+		"allowed_mentions": map[string]any{
+			"parse": []any{},
+		},
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("message=%+v, want=%+v", got, want)
+	}
+}
+
+func TestAnnounceNewComment(t *testing.T) {
+	mock := mockDiscord{}
+	server := httptest.NewServer(&mock)
+	defer server.Close()
+
+	announcer := discord.New("https://dev.thescreenjournal.com", server.URL, server.Client())
+	if err := announcer.AnnounceNewComment(screenjournal.ReviewComment{
+		ID:          screenjournal.CommentID(4),
+		Owner:       screenjournal.Username("bob"),
+		CommentText: screenjournal.CommentText("Agreed, **loved** it"),
+		Review: screenjournal.Review{
+			ID:           screenjournal.ReviewID(7),
+			Owner:        screenjournal.Username("alice"),
+			TvShow:       screenjournal.TvShow{ID: screenjournal.TvShowID(3), Title: "Party Down"},
+			TvShowSeason: screenjournal.TvShowSeason(2),
+		},
+	}); err != nil {
+		t.Fatalf("AnnounceNewComment err=%v, want=%v", err, nil)
+	}
+
+	if got, want := len(mock.messages), 1; got != want {
+		t.Fatalf("messages=%d, want=%d", got, want)
+	}
+	e := mock.messages[0]["embeds"].([]any)[0].(map[string]any)
+	if got, want := e["title"], "alice's review of Party Down (Season 2)"; got != want {
+		t.Errorf("title=%v, want=%v", got, want)
+	}
+	if got, want := e["url"], "https://dev.thescreenjournal.com/tv-shows/3?season=2#comment4"; got != want {
+		t.Errorf("url=%v, want=%v", got, want)
+	}
+	if got, want := e["description"], "Agreed, loved it"; got != want {
+		t.Errorf("description=%v, want=%v", got, want)
+	}
+	if _, ok := e["thumbnail"]; ok {
+		t.Errorf("embed has thumbnail, but TV show has no poster")
+	}
+}
+
+func TestAnnounceSkipsPrivateActivity(t *testing.T) {
+	mock := mockDiscord{}
+	server := httptest.NewServer(&mock)
+	defer server.Close()
+
+	announcer := discord.New("https://dev.thescreenjournal.com", server.URL, server.Client())
+	if err := announcer.AnnounceNewFriendRecommendation(screenjournal.FriendRecommendation{
+		From: screenjournal.Username("alice"),
+		To:   screenjournal.Username("bob"),
+	}); err != nil {
+		t.Fatalf("AnnounceNewFriendRecommendation err=%v, want=%v", err, nil)
+	}
+	announcer.AnnounceMention(screenjournal.Mention{
+		Mentioned: screenjournal.Username("bob"),
+	})
+
+	if got, want := len(mock.messages), 0; got != want {
+		t.Errorf("messages=%d, want=%d", got, want)
+	}
+}
+
+func TestAnnounceReportsRejectedMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	announcer := discord.New("https://dev.thescreenjournal.com", server.URL, server.Client())
+	err := announcer.AnnounceNewReview(screenjournal.Review{
+		ID:    screenjournal.ReviewID(7),
+		Owner: screenjournal.Username("alice"),
+		Movie: screenjournal.Movie{
+			ID:    screenjournal.MovieID(12),
+			Title: screenjournal.MediaTitle("The Waterboy"),
+		},
+	})
+	if err == nil {
+		t.Errorf("AnnounceNewReview err=%v, want an error", err)
+	}
+}
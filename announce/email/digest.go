@@ -0,0 +1,205 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/mail"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/announce/routes"
+	"github.com/mtlynch/screenjournal/v2/email"
+	"github.com/mtlynch/screenjournal/v2/markdown"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/stars"
+)
+
+// digestSlack lets a digest go out slightly before its period elapses so that
+// digests don't drift later each period when the job runs on an interval.
+const digestSlack = time.Hour
+
+type (
+	DigestStore interface {
+		ReadDigestSubscribers(screenjournal.DigestFrequency) ([]screenjournal.DigestSubscriber, error)
+		ReadDigestReviews(recipient screenjournal.Username, since, until time.Time) ([]screenjournal.Review, error)
+		ReadDigestComments(recipient screenjournal.Username, since, until time.Time) ([]screenjournal.ReviewComment, error)
+		UpdateLastDigestTime(screenjournal.Username, time.Time) error
+	}
+
+	// Digester emails users who prefer digests a single summary of the new
+	// reviews and comments since their last digest.
+	Digester struct {
+		baseURL   string
+		sender    email.Sender
+		store     DigestStore
+		tokenizer UnsubscribeTokenizer
+		clock     func() time.Time
+	}
+
+	digestReview struct {
+		Author       string
+		Title        string
+		SeasonSuffix string
+		Stars        string
+		Route        string
+	}
+
+	digestComment struct {
+		Author       string
+		ReviewAuthor string
+		Title        string
+		SeasonSuffix string
+		Route        string
+	}
+)
+
+func NewDigester(baseURL string, sender email.Sender, store DigestStore, tokenizer UnsubscribeTokenizer, clock func() time.Time) Digester {
+	return Digester{
+		baseURL:   baseURL,
+		sender:    sender,
+		store:     store,
+		tokenizer: tokenizer,
+		clock:     clock,
+	}
+}
+
+// Run sends due digests every interval until ctx is cancelled.
+func (d Digester) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		d.SendDue()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue sends a digest to every subscriber whose digest period has elapsed
+// since their last digest.
+func (d Digester) SendDue() {
+	now := d.clock()
+	for _, frequency := range []screenjournal.DigestFrequency{screenjournal.DigestDaily, screenjournal.DigestWeekly} {
+		subscribers, err := d.store.ReadDigestSubscribers(frequency)
+		if err != nil {
+			log.Printf("failed to read %s digest subscribers: %v", frequency, err)
+			continue
+		}
+		for _, subscriber := range subscribers {
+			d.sendIfDue(subscriber, now)
+		}
+	}
+}
+
+func (d Digester) sendIfDue(subscriber screenjournal.DigestSubscriber, now time.Time) {
+	period := subscriber.Preferences.Digest.Period()
+	since := subscriber.LastDigest
+	if since.IsZero() {
+		// The user just switched to digests, so cover the last period.
+		since = now.Add(-period)
+	} else if now.Sub(since) < period-digestSlack {
+		return
+	}
+
+	reviews := []screenjournal.Review{}
+	if subscriber.Preferences.NewReviews {
+		var err error
+		reviews, err = d.store.ReadDigestReviews(subscriber.Username, since, now)
+		if err != nil {
+			log.Printf("failed to read reviews for %s's digest: %v", subscriber.Username, err)
+			return
+		}
+	}
+	comments, err := d.store.ReadDigestComments(subscriber.Username, since, now)
+	if err != nil {
+		log.Printf("failed to read comments for %s's digest: %v", subscriber.Username, err)
+		return
+	}
+
+	if len(reviews) > 0 || len(comments) > 0 {
+		if err := d.send(subscriber, since, reviews, comments); err != nil {
+			log.Printf("failed to send digest to %s: %v", subscriber.Username, err)
+			return
+		}
+	}
+
+	if err := d.store.UpdateLastDigestTime(subscriber.Username, now); err != nil {
+		log.Printf("failed to save last digest time for %s: %v", subscriber.Username, err)
+	}
+}
+
+func (d Digester) send(subscriber screenjournal.DigestSubscriber, since time.Time, reviews []screenjournal.Review, comments []screenjournal.ReviewComment) error {
+	log.Printf("sending %s digest with %d review(s) and %d comment(s) to %s", subscriber.Preferences.Digest, len(reviews), len(comments), subscriber.Username)
+
+	digestReviews := make([]digestReview, 0, len(reviews))
+	for _, r := range reviews {
+		digestReviews = append(digestReviews, digestReview{
+			Author:       r.Owner.String(),
+			Title:        routes.Title(r).String(),
+			SeasonSuffix: routes.SeasonSuffix(r),
+			Stars:        stars.Plaintext(r.Rating),
+			Route:        routes.Review(r),
+		})
+	}
+	digestComments := make([]digestComment, 0, len(comments))
+	for _, rc := range comments {
+		digestComments = append(digestComments, digestComment{
+			Author:       rc.Owner.String(),
+			ReviewAuthor: rc.Review.Owner.String(),
+			Title:        routes.Title(rc.Review).String(),
+			SeasonSuffix: routes.SeasonSuffix(rc.Review),
+			Route:        routes.Comment(rc),
+		})
+	}
+
+	period := "today"
+	if subscriber.Preferences.Digest == screenjournal.DigestWeekly {
+		period = "this week"
+	}
+
+	unsubscribeRoute := routes.Unsubscribe(d.tokenizer.Token(subscriber.Username, screenjournal.UnsubscribeDigest))
+	bodyMarkdown := mustRenderTemplate("digest.tmpl.txt", struct {
+		Recipient        string
+		Period           string
+		Reviews          []digestReview
+		Comments         []digestComment
+		BaseURL          string
+		UnsubscribeRoute string
+	}{
+		Recipient:        subscriber.Username.String(),
+		Period:           period,
+		Reviews:          digestReviews,
+		Comments:         digestComments,
+		BaseURL:          d.baseURL,
+		UnsubscribeRoute: unsubscribeRoute,
+	})
+	return d.sender.Send(email.Message{
+		From: mail.Address{
+			Name:    "ScreenJournal",
+			Address: "activity@thescreenjournal.com",
+		},
+		To: []mail.Address{
+			{
+				Name:    subscriber.Username.String(),
+				Address: subscriber.Email.String(),
+			},
+		},
+		Subject:  fmt.Sprintf("Your %s ScreenJournal digest: %s, %s", subscriber.Preferences.Digest, pluralize(len(reviews), "review"), pluralize(len(comments), "comment")),
+		TextBody: bodyMarkdown.String(),
+		HtmlBody: markdown.RenderEmail(bodyMarkdown),
+		Headers:  unsubscribeHeaders(d.baseURL + unsubscribeRoute),
+		// A digest covers everything since the last one, so if ScreenJournal
+		// restarts before recording that it sent this digest, the retry has the
+		// same key.
+		IdempotencyKey: fmt.Sprintf("digest/%s/%s", subscriber.Preferences.Digest, since.UTC().Format(time.RFC3339)),
+	})
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
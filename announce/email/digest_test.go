@@ -0,0 +1,160 @@
+package email_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/diff"
+	email_announce "github.com/mtlynch/screenjournal/v2/announce/email"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type mockDigestStore struct {
+	subscribers []screenjournal.DigestSubscriber
+	reviews     []screenjournal.Review
+	comments    []screenjournal.ReviewComment
+	lastDigests map[screenjournal.Username]time.Time
+}
+
+func (s *mockDigestStore) ReadDigestSubscribers(frequency screenjournal.DigestFrequency) ([]screenjournal.DigestSubscriber, error) {
+	subscribers := []screenjournal.DigestSubscriber{}
+	for _, subscriber := range s.subscribers {
+		if subscriber.Preferences.Digest == frequency {
+			subscriber.LastDigest = s.lastDigests[subscriber.Username]
+			subscribers = append(subscribers, subscriber)
+		}
+	}
+	return subscribers, nil
+}
+
+func (s *mockDigestStore) ReadDigestReviews(recipient screenjournal.Username, since, until time.Time) ([]screenjournal.Review, error) {
+	reviews := []screenjournal.Review{}
+	for _, r := range s.reviews {
+		if !r.Owner.Equal(recipient) && r.Created.After(since) && !r.Created.After(until) {
+			reviews = append(reviews, r)
+		}
+	}
+	return reviews, nil
+}
+
+func (s *mockDigestStore) ReadDigestComments(recipient screenjournal.Username, since, until time.Time) ([]screenjournal.ReviewComment, error) {
+	comments := []screenjournal.ReviewComment{}
+	for _, rc := range s.comments {
+		if !rc.Owner.Equal(recipient) && rc.Review.Owner.Equal(recipient) && rc.Created.After(since) && !rc.Created.After(until) {
+			comments = append(comments, rc)
+		}
+	}
+	return comments, nil
+}
+
+func (s *mockDigestStore) UpdateLastDigestTime(username screenjournal.Username, t time.Time) error {
+	s.lastDigests[username] = t
+	return nil
+}
+
+func TestDigester(t *testing.T) {
+	now := mustParseTime(t, "2025-03-08T09:00:00Z")
+	waterboy := screenjournal.Review{
+		ID:      screenjournal.ReviewID(7),
+		Owner:   screenjournal.Username("bob"),
+		Rating:  screenjournal.NewRating(7),
+		Movie:   screenjournal.Movie{ID: screenjournal.MovieID(12), Title: "The Waterboy"},
+		Created: now.Add(-2 * time.Hour),
+	}
+	partyDown := screenjournal.Review{
+		ID:           screenjournal.ReviewID(8),
+		Owner:        screenjournal.Username("alice"),
+		TvShow:       screenjournal.TvShow{ID: screenjournal.TvShowID(3), Title: "Party Down"},
+		TvShowSeason: screenjournal.TvShowSeason(2),
+		Created:      now.Add(-10 * 24 * time.Hour),
+	}
+	store := mockDigestStore{
+		subscribers: []screenjournal.DigestSubscriber{
+			{
+				EmailSubscriber: screenjournal.EmailSubscriber{
+					Username: screenjournal.Username("alice"),
+					Email:    screenjournal.Email("alice.amberson@example.com"),
+				},
+				Preferences: screenjournal.NotificationPreferences{
+					NewReviews: true,
+					Digest:     screenjournal.DigestDaily,
+				},
+			},
+			{
+				EmailSubscriber: screenjournal.EmailSubscriber{
+					Username: screenjournal.Username("charlie"),
+					Email:    screenjournal.Email("charlie.barley@example.com"),
+				},
+				Preferences: screenjournal.NotificationPreferences{
+					NewReviews: true,
+					Digest:     screenjournal.DigestWeekly,
+				},
+			},
+		},
+		reviews: []screenjournal.Review{waterboy, partyDown},
+		comments: []screenjournal.ReviewComment{
+			{
+				ID:      screenjournal.CommentID(4),
+				Owner:   screenjournal.Username("bob"),
+				Review:  partyDown,
+				Created: now.Add(-time.Hour),
+			},
+		},
+		lastDigests: map[screenjournal.Username]time.Time{
+			// charlie got a weekly digest recently, so they're not due for another.
+			screenjournal.Username("charlie"): now.Add(-3 * 24 * time.Hour),
+		},
+	}
+	sender := mockEmailSender{}
+	digester := email_announce.NewDigester("https://dev.thescreenjournal.com", &sender, &store, mockTokenizer{}, func() time.Time { return now })
+
+	digester.SendDue()
+
+	if got, want := len(sender.emailsSent), 1; got != want {
+		t.Fatalf("emails sent=%d, want=%d", got, want)
+	}
+	msg := sender.emailsSent[0]
+	if got, want := msg.To[0].Address, "alice.amberson@example.com"; got != want {
+		t.Errorf("to=%v, want=%v", got, want)
+	}
+	if got, want := msg.Subject, "Your daily ScreenJournal digest: 1 review, 1 comment"; got != want {
+		t.Errorf("subject=%v, want=%v", got, want)
+	}
+	if d := diff.Diff(`Hey alice,
+
+Here's what you missed on ScreenJournal today.
+
+**New reviews**
+
+* bob reviewed *The Waterboy* ★★★½☆: https://dev.thescreenjournal.com/movies/12#review7
+
+**New comments**
+
+* bob commented on alice's review of *Party Down* (Season 2): https://dev.thescreenjournal.com/tv-shows/3?season=2#comment4
+
+-ScreenJournal Bot
+
+To unsubscribe from these emails, visit https://dev.thescreenjournal.com/unsubscribe/token-alice-digest
+
+To manage your notifications, visit https://dev.thescreenjournal.com/account/notifications
+`, msg.TextBody); d != "" {
+		t.Errorf("unexpected digest body: %s", d)
+	}
+	if got, want := store.lastDigests[screenjournal.Username("alice")], now; got != want {
+		t.Errorf("last digest=%v, want=%v", got, want)
+	}
+
+	// Running again shouldn't send alice a second digest for the same period.
+	digester.SendDue()
+	if got, want := len(sender.emailsSent), 1; got != want {
+		t.Errorf("emails sent=%d, want=%d", got, want)
+	}
+}
+
+func mustParseTime(t *testing.T, s string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf("failed to parse time %s: %v", s, err)
+	}
+	return parsed
+}
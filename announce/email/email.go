@@ -3,12 +3,14 @@ package email
 import (
 	"bytes"
 	"embed"
+	"errors"
 	"fmt"
 	"log"
 	"net/mail"
 	"path"
 	"text/template"
 
+	"github.com/mtlynch/screenjournal/v2/announce/routes"
 	"github.com/mtlynch/screenjournal/v2/email"
 	"github.com/mtlynch/screenjournal/v2/markdown"
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
@@ -16,65 +18,71 @@ import (
 
 type (
 	NotificationsStore interface {
-		ReadReviewSubscribers() ([]screenjournal.EmailSubscriber, error)
-		ReadCommentSubscribers(reviewID screenjournal.ReviewID, commentAuthor screenjournal.Username) ([]screenjournal.EmailSubscriber, error)
+		ReadReviewSubscribers(author screenjournal.Username) ([]screenjournal.EmailSubscriber, error)
+		ReadCommentSubscribers(reviewID screenjournal.ReviewID, commentAuthor screenjournal.Username, parentCommentID screenjournal.CommentID) ([]screenjournal.EmailSubscriber, error)
+		ReadComment(screenjournal.CommentID) (screenjournal.ReviewComment, error)
+		ReadUser(username screenjournal.Username) (screenjournal.User, error)
+		ReadNotificationPreferences(username screenjournal.Username) (screenjournal.NotificationPreferences, error)
+		ReadUserRelationship(username, target screenjournal.Username) (screenjournal.UserRelationship, error)
+	}
+
+	// UnsubscribeTokenizer creates tokens for links that unsubscribe a user
+	// from a category of email without logging in.
+	UnsubscribeTokenizer interface {
+		Token(screenjournal.Username, screenjournal.UnsubscribeCategory) screenjournal.UnsubscribeToken
 	}
 
 	Announcer struct {
-		baseURL string
-		sender  email.Sender
-		store   NotificationsStore
+		baseURL   string
+		sender    email.Sender
+		store     NotificationsStore
+		tokenizer UnsubscribeTokenizer
 	}
 )
 
-func New(baseURL string, sender email.Sender, store NotificationsStore) Announcer {
+func New(baseURL string, sender email.Sender, store NotificationsStore, tokenizer UnsubscribeTokenizer) Announcer {
 	return Announcer{
-		baseURL: baseURL,
-		sender:  sender,
-		store:   store,
+		baseURL:   baseURL,
+		sender:    sender,
+		store:     store,
+		tokenizer: tokenizer,
 	}
 }
 
-func (a Announcer) AnnounceNewReview(r screenjournal.Review) {
+func (a Announcer) AnnounceNewReview(r screenjournal.Review) error {
 	log.Printf("announcing new review from user %s of %s", r.Owner.String(), r.Movie.Title)
-	subscribers, err := a.store.ReadReviewSubscribers()
+	subscribers, err := a.store.ReadReviewSubscribers(r.Owner)
 	if err != nil {
-		log.Printf("failed to read announcement recipients from store: %v", err)
-		return
+		return fmt.Errorf("read announcement recipients: %w", err)
 	}
 	log.Printf("%d user(s) subscribed to new review notifications", len(subscribers))
+	errs := []error{}
 	for _, subscriber := range subscribers {
 		// Don't send a notification to the review author.
 		if subscriber.Username.Equal(r.Owner) {
 			continue
 		}
 
-		var title screenjournal.MediaTitle
-		var reviewRoute string
-		var seasonSuffix string
-		if !r.Movie.ID.IsZero() {
-			title = r.Movie.Title
-			reviewRoute = fmt.Sprintf("/movies/%d#review%d", r.Movie.ID.Int64(), r.ID.UInt64())
-		} else {
-			title = r.TvShow.Title
-			seasonSuffix = fmt.Sprintf(" (Season %d)", r.TvShowSeason.UInt8())
-			reviewRoute = fmt.Sprintf("/tv-shows/%d?season=%d#review%d", r.TvShow.ID.Int64(), r.TvShowSeason.UInt8(), r.ID.UInt64())
-		}
+		title := routes.Title(r)
+		seasonSuffix := routes.SeasonSuffix(r)
 
+		unsubscribeRoute := routes.Unsubscribe(a.tokenizer.Token(subscriber.Username, screenjournal.UnsubscribeNewReviews))
 		bodyMarkdown := mustRenderTemplate("new-review.tmpl.txt", struct {
-			Recipient    string
-			Title        string
-			SeasonSuffix string
-			Author       string
-			BaseURL      string
-			ReviewRoute  string
+			Recipient        string
+			Title            string
+			SeasonSuffix     string
+			Author           string
+			BaseURL          string
+			ReviewRoute      string
+			UnsubscribeRoute string
 		}{
-			Recipient:    subscriber.Username.String(),
-			Title:        title.String(),
-			SeasonSuffix: seasonSuffix,
-			Author:       r.Owner.String(),
-			BaseURL:      a.baseURL,
-			ReviewRoute:  reviewRoute,
+			Recipient:        subscriber.Username.String(),
+			Title:            title.String(),
+			SeasonSuffix:     seasonSuffix,
+			Author:           r.Owner.String(),
+			BaseURL:          a.baseURL,
+			ReviewRoute:      routes.Review(r),
+			UnsubscribeRoute: unsubscribeRoute,
 		})
 		bodyHtml := markdown.RenderEmail(bodyMarkdown)
 		msg := email.Message{
@@ -88,53 +96,65 @@ func (a Announcer) AnnounceNewReview(r screenjournal.Review) {
 					Address: subscriber.Email.String(),
 				},
 			},
-			Subject:  fmt.Sprintf("%s posted a new review: %s%s", r.Owner.String(), title, seasonSuffix),
-			TextBody: bodyMarkdown.String(),
-			HtmlBody: bodyHtml,
+			Subject:        fmt.Sprintf("%s posted a new review: %s%s", r.Owner.String(), title, seasonSuffix),
+			TextBody:       bodyMarkdown.String(),
+			HtmlBody:       bodyHtml,
+			Headers:        unsubscribeHeaders(a.baseURL + unsubscribeRoute),
+			IdempotencyKey: "review/" + r.ID.String(),
 		}
 		if err := a.sender.Send(msg); err != nil {
-			log.Printf("failed to send message [%s] to recipient [%s]", msg.Subject, msg.To[0].String())
-			continue
+			errs = append(errs, fmt.Errorf("send message [%s] to recipient [%s]: %w", msg.Subject, msg.To[0].String(), err))
 		}
 	}
+	return errors.Join(errs...)
 }
 
-func (a Announcer) AnnounceNewComment(rc screenjournal.ReviewComment) {
+func (a Announcer) AnnounceNewComment(rc screenjournal.ReviewComment) error {
 	log.Printf("announcing new comment from %s about %s's review of %s", rc.Owner, rc.Review.Owner, rc.Review.Movie.Title)
-	users, err := a.store.ReadCommentSubscribers(rc.Review.ID, rc.Owner)
+	users, err := a.store.ReadCommentSubscribers(rc.Review.ID, rc.Owner, rc.ParentID)
 	if err != nil {
-		log.Printf("failed to read announcement recipients from store: %v", err)
-		return
+		return fmt.Errorf("read announcement recipients: %w", err)
 	}
 	log.Printf("%d user(s) are on this thread and accept new comment notifications", len(users))
+
+	// The parent comment's author receives this as a reply, so their
+	// unsubscribe link has to turn off replies rather than all comments.
+	var parentOwner screenjournal.Username
+	if !rc.ParentID.IsZero() {
+		parent, err := a.store.ReadComment(rc.ParentID)
+		if err != nil {
+			return fmt.Errorf("read parent comment %v: %w", rc.ParentID, err)
+		}
+		parentOwner = parent.Owner
+	}
+
+	errs := []error{}
 	for _, u := range users {
-		var title screenjournal.MediaTitle
-		var commentRoute string
-		var seasonSuffix string
-		if !rc.Review.Movie.ID.IsZero() {
-			title = rc.Review.Movie.Title
-			commentRoute = fmt.Sprintf("/movies/%d#comment%d", rc.Review.Movie.ID.Int64(), rc.ID.UInt64())
-		} else {
-			title = rc.Review.TvShow.Title
-			seasonSuffix = fmt.Sprintf(" (Season %d)", rc.Review.TvShowSeason.UInt8())
-			commentRoute = fmt.Sprintf("/tv-shows/%d?season=%d#comment%d", rc.Review.TvShow.ID.Int64(), rc.Review.TvShowSeason.UInt8(), rc.ID.UInt64())
+		title := routes.Title(rc.Review)
+		seasonSuffix := routes.SeasonSuffix(rc.Review)
+		category := screenjournal.UnsubscribeComments
+		if u.Username.Equal(parentOwner) {
+			category = screenjournal.UnsubscribeReplies
 		}
+		unsubscribeRoute := routes.Unsubscribe(a.tokenizer.Token(u.Username, category))
 		bodyMarkdown := mustRenderTemplate("new-comment.tmpl.txt", struct {
-			Recipient     string
-			Title         string
-			SeasonSuffix  string
-			CommentAuthor string
-			ReviewAuthor  string
-			BaseURL       string
-			CommentRoute  string
+			Recipient        string
+			Title            string
+			SeasonSuffix     string
+			CommentAuthor    string
+			ReviewAuthor     string
+			BaseURL          string
+			CommentRoute     string
+			UnsubscribeRoute string
 		}{
-			Recipient:     u.Username.String(),
-			Title:         title.String(),
-			SeasonSuffix:  seasonSuffix,
-			CommentAuthor: rc.Owner.String(),
-			ReviewAuthor:  rc.Review.Owner.String(),
-			BaseURL:       a.baseURL,
-			CommentRoute:  commentRoute,
+			Recipient:        u.Username.String(),
+			Title:            title.String(),
+			SeasonSuffix:     seasonSuffix,
+			CommentAuthor:    rc.Owner.String(),
+			ReviewAuthor:     rc.Review.Owner.String(),
+			BaseURL:          a.baseURL,
+			CommentRoute:     routes.Comment(rc),
+			UnsubscribeRoute: unsubscribeRoute,
 		})
 		bodyHtml := markdown.RenderEmail(bodyMarkdown)
 		msg := email.Message{
@@ -148,15 +168,242 @@ func (a Announcer) AnnounceNewComment(rc screenjournal.ReviewComment) {
 					Address: u.Email.String(),
 				},
 			},
-			Subject:  fmt.Sprintf("%s commented on %s's review of %s%s", rc.Owner.String(), rc.Review.Owner, title, seasonSuffix),
-			TextBody: bodyMarkdown.String(),
-			HtmlBody: bodyHtml,
+			Subject:        fmt.Sprintf("%s commented on %s's review of %s%s", rc.Owner.String(), rc.Review.Owner, title, seasonSuffix),
+			TextBody:       bodyMarkdown.String(),
+			HtmlBody:       bodyHtml,
+			Headers:        unsubscribeHeaders(a.baseURL + unsubscribeRoute),
+			IdempotencyKey: "comment/" + rc.ID.String(),
 		}
 		if err := a.sender.Send(msg); err != nil {
-			log.Printf("failed to send message [%s] to recipient [%s]", msg.Subject, msg.To[0].String())
-			continue
+			errs = append(errs, fmt.Errorf("send message [%s] to recipient [%s]: %w", msg.Subject, msg.To[0].String(), err))
 		}
 	}
+	return errors.Join(errs...)
+}
+
+func (a Announcer) AnnounceNewFriendRecommendation(fr screenjournal.FriendRecommendation) error {
+	log.Printf("announcing recommendation of %s from %s to %s", fr.MediaTitle(), fr.From, fr.To)
+	recipient, err := a.store.ReadUser(fr.To)
+	if err != nil {
+		return fmt.Errorf("read recommendation recipient: %w", err)
+	}
+	if !recipient.EmailVerified {
+		log.Printf("not emailing %s about recommendation because their email address is unverified", recipient.Username)
+		return nil
+	}
+
+	var seasonSuffix string
+	if fr.MediaType() == screenjournal.MediaTypeTvShow {
+		seasonSuffix = fmt.Sprintf(" (Season %d)", fr.TvShowSeason.UInt8())
+	}
+
+	bodyMarkdown := mustRenderTemplate("new-friend-recommendation.tmpl.txt", struct {
+		Recipient    string
+		Sender       string
+		Title        string
+		SeasonSuffix string
+		Message      string
+		BaseURL      string
+	}{
+		Recipient:    recipient.Username.String(),
+		Sender:       fr.From.String(),
+		Title:        fr.MediaTitle().String(),
+		SeasonSuffix: seasonSuffix,
+		Message:      fr.Message.String(),
+		BaseURL:      a.baseURL,
+	})
+	msg := email.Message{
+		From: mail.Address{
+			Name:    "ScreenJournal",
+			Address: "activity@thescreenjournal.com",
+		},
+		To: []mail.Address{
+			{
+				Name:    recipient.Username.String(),
+				Address: recipient.Email.String(),
+			},
+		},
+		Subject:        fmt.Sprintf("%s recommended %s%s to you", fr.From, fr.MediaTitle(), seasonSuffix),
+		TextBody:       bodyMarkdown.String(),
+		HtmlBody:       markdown.RenderEmail(bodyMarkdown),
+		IdempotencyKey: "friend-recommendation/" + fr.ID.String(),
+	}
+	if err := a.sender.Send(msg); err != nil {
+		return fmt.Errorf("send message [%s] to recipient [%s]: %w", msg.Subject, msg.To[0].String(), err)
+	}
+	return nil
+}
+
+func (a Announcer) AnnounceNewReaction(rr screenjournal.ReviewReaction) error {
+	// Reactions are too lightweight to be worth an email, so they only appear
+	// in the in-app inbox.
+	log.Printf("not emailing about %s reaction from %s", rr.Emoji, rr.Owner)
+	return nil
+}
+
+func (a Announcer) AnnounceMention(m screenjournal.Mention) error {
+	log.Printf("announcing mention of %s by %s", m.Mentioned, m.Author)
+	prefs, err := a.store.ReadNotificationPreferences(m.Mentioned)
+	if err != nil {
+		return fmt.Errorf("read notification preferences for %s: %w", m.Mentioned, err)
+	}
+	if !prefs.Mentions {
+		log.Printf("%s has opted out of mention notifications", m.Mentioned)
+		return nil
+	}
+
+	relationship, err := a.store.ReadUserRelationship(m.Mentioned, m.Author)
+	if err != nil {
+		return fmt.Errorf("read relationship of %s to %s: %w", m.Mentioned, m.Author, err)
+	}
+	if relationship == screenjournal.RelationshipMute {
+		log.Printf("%s has muted %s, so not announcing mention", m.Mentioned, m.Author)
+		return nil
+	}
+
+	recipient, err := a.store.ReadUser(m.Mentioned)
+	if err != nil {
+		return fmt.Errorf("read mentioned user: %w", err)
+	}
+	if !recipient.EmailVerified {
+		log.Printf("not emailing %s about mention because their email address is unverified", recipient.Username)
+		return nil
+	}
+
+	title := routes.Title(m.Review)
+	seasonSuffix := routes.SeasonSuffix(m.Review)
+
+	var location string
+	var mentionRoute string
+	var mentionKey string
+	if m.Comment.ID.IsZero() {
+		location = fmt.Sprintf("their review of %s%s", title, seasonSuffix)
+		mentionRoute = routes.Review(m.Review)
+		mentionKey = "mention/review/" + m.Review.ID.String()
+	} else {
+		location = fmt.Sprintf("a comment on %s's review of %s%s", m.Review.Owner, title, seasonSuffix)
+		m.Comment.Review = m.Review
+		mentionRoute = routes.Comment(m.Comment)
+		mentionKey = "mention/comment/" + m.Comment.ID.String()
+	}
+
+	unsubscribeRoute := routes.Unsubscribe(a.tokenizer.Token(recipient.Username, screenjournal.UnsubscribeMentions))
+	bodyMarkdown := mustRenderTemplate("new-mention.tmpl.txt", struct {
+		Recipient        string
+		Author           string
+		Location         string
+		BaseURL          string
+		MentionRoute     string
+		UnsubscribeRoute string
+	}{
+		Recipient:        recipient.Username.String(),
+		Author:           m.Author.String(),
+		Location:         location,
+		BaseURL:          a.baseURL,
+		MentionRoute:     mentionRoute,
+		UnsubscribeRoute: unsubscribeRoute,
+	})
+	msg := email.Message{
+		From: mail.Address{
+			Name:    "ScreenJournal",
+			Address: "activity@thescreenjournal.com",
+		},
+		To: []mail.Address{
+			{
+				Name:    recipient.Username.String(),
+				Address: recipient.Email.String(),
+			},
+		},
+		Subject:        fmt.Sprintf("%s mentioned you in %s", m.Author, location),
+		TextBody:       bodyMarkdown.String(),
+		HtmlBody:       markdown.RenderEmail(bodyMarkdown),
+		Headers:        unsubscribeHeaders(a.baseURL + unsubscribeRoute),
+		IdempotencyKey: mentionKey,
+	}
+	if err := a.sender.Send(msg); err != nil {
+		return fmt.Errorf("send message [%s] to recipient [%s]: %w", msg.Subject, msg.To[0].String(), err)
+	}
+	return nil
+}
+
+// SendRecap emails a user their year-in-review recap. Each call sends a new
+// email, even if the user already received the same recap.
+func (a Announcer) SendRecap(user screenjournal.User, r screenjournal.Recap) error {
+	log.Printf("sending %d recap to %s", r.Year, user.Username)
+
+	var favoriteEmoji string
+	if r.FavoriteEmojiCount > 0 {
+		favoriteEmoji = r.FavoriteEmoji.String()
+	}
+
+	bodyMarkdown := mustRenderTemplate("recap.tmpl.txt", struct {
+		Recipient        string
+		Year             int
+		TitleCount       int
+		MovieHours       int
+		UntimedMovies    int
+		HighestRated     string
+		LowestRated      string
+		MostCommented    string
+		FavoriteReviewer string
+		FavoriteEmoji    string
+		BaseURL          string
+		RecapRoute       string
+	}{
+		Recipient:        user.Username.String(),
+		Year:             r.Year,
+		TitleCount:       len(r.TitlesWatched),
+		MovieHours:       r.MovieHoursWatched(),
+		UntimedMovies:    r.MoviesWithoutRuntime,
+		HighestRated:     reviewTitleOrEmpty(r.HighestRated),
+		LowestRated:      reviewTitleOrEmpty(r.LowestRated),
+		MostCommented:    reviewTitleOrEmpty(r.MostCommented),
+		FavoriteReviewer: r.FavoriteReviewer.String(),
+		FavoriteEmoji:    favoriteEmoji,
+		BaseURL:          a.baseURL,
+		RecapRoute:       fmt.Sprintf("/recap/%d/%s", r.Year, r.Username),
+	})
+	msg := email.Message{
+		From: mail.Address{
+			Name:    "ScreenJournal",
+			Address: "activity@thescreenjournal.com",
+		},
+		To: []mail.Address{
+			{
+				Name:    user.Username.String(),
+				Address: user.Email.String(),
+			},
+		},
+		Subject:  fmt.Sprintf("Your %d on ScreenJournal", r.Year),
+		TextBody: bodyMarkdown.String(),
+		HtmlBody: markdown.RenderEmail(bodyMarkdown),
+	}
+	if err := a.sender.Send(msg); err != nil {
+		return fmt.Errorf("send %d recap to %s: %w", r.Year, user.Username, err)
+	}
+
+	return nil
+}
+
+// reviewTitleOrEmpty returns the title of the media the review covers, or an
+// empty string if the review is empty.
+func reviewTitleOrEmpty(r screenjournal.Review) string {
+	if r.ID.IsZero() {
+		return ""
+	}
+	if !r.Movie.ID.IsZero() {
+		return r.Movie.Title.String()
+	}
+	return fmt.Sprintf("%s (Season %d)", r.TvShow.Title, r.TvShowSeason.UInt8())
+}
+
+// unsubscribeHeaders returns headers that let mail clients offer a one-click
+// unsubscribe button, as described in RFC 8058.
+func unsubscribeHeaders(unsubscribeURL string) mail.Header {
+	return mail.Header{
+		"List-Unsubscribe":      {"<" + unsubscribeURL + ">"},
+		"List-Unsubscribe-Post": {"List-Unsubscribe=One-Click"},
+	}
 }
 
 //go:embed templates
@@ -1,31 +1,38 @@
 package email_test
 
 import (
+	"fmt"
 	"net/mail"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-test/deep"
 	"github.com/kylelemons/godebug/diff"
 	email_announce "github.com/mtlynch/screenjournal/v2/announce/email"
 	"github.com/mtlynch/screenjournal/v2/email"
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
 )
 
 type mockNotificationsStore struct {
 	subscribers      []screenjournal.EmailSubscriber
 	commentsByReview map[screenjournal.ReviewID][]screenjournal.ReviewComment
 	reviewsByID      map[screenjournal.ReviewID]screenjournal.Review
+	mentionsOptOut   map[screenjournal.Username]bool
+	// mutes maps each user to the users they've muted.
+	mutes map[screenjournal.Username][]screenjournal.Username
 }
 
-func (ns mockNotificationsStore) ReadReviewSubscribers() ([]screenjournal.EmailSubscriber, error) {
+func (ns mockNotificationsStore) ReadReviewSubscribers(_ screenjournal.Username) ([]screenjournal.EmailSubscriber, error) {
 	return ns.subscribers, nil
 }
 
 func (ns mockNotificationsStore) ReadCommentSubscribers(
 	reviewID screenjournal.ReviewID,
 	commentAuthor screenjournal.Username,
+	_ screenjournal.CommentID,
 ) ([]screenjournal.EmailSubscriber, error) {
 	recipients := map[screenjournal.Username]struct{}{}
 	if ns.reviewsByID != nil {
@@ -58,6 +65,45 @@ func (ns mockNotificationsStore) ReadCommentSubscribers(
 	return filtered, nil
 }
 
+func (ns mockNotificationsStore) ReadComment(id screenjournal.CommentID) (screenjournal.ReviewComment, error) {
+	for _, comments := range ns.commentsByReview {
+		for _, comment := range comments {
+			if comment.ID == id {
+				return comment, nil
+			}
+		}
+	}
+	return screenjournal.ReviewComment{}, store.ErrCommentNotFound
+}
+
+func (ns mockNotificationsStore) ReadUser(username screenjournal.Username) (screenjournal.User, error) {
+	for _, subscriber := range ns.subscribers {
+		if subscriber.Username.Equal(username) {
+			return screenjournal.User{
+				Username:      subscriber.Username,
+				Email:         subscriber.Email,
+				EmailVerified: true,
+			}, nil
+		}
+	}
+	return screenjournal.User{}, store.ErrUserNotFound
+}
+
+func (ns mockNotificationsStore) ReadNotificationPreferences(username screenjournal.Username) (screenjournal.NotificationPreferences, error) {
+	return screenjournal.NotificationPreferences{
+		Mentions: !ns.mentionsOptOut[username],
+	}, nil
+}
+
+func (ns mockNotificationsStore) ReadUserRelationship(username, target screenjournal.Username) (screenjournal.UserRelationship, error) {
+	for _, muted := range ns.mutes[username] {
+		if muted.Equal(target) {
+			return screenjournal.RelationshipMute, nil
+		}
+	}
+	return screenjournal.RelationshipNone, nil
+}
+
 type mockEmailSender struct {
 	emailsSent []email.Message
 }
@@ -67,6 +113,12 @@ func (s *mockEmailSender) Send(message email.Message) error {
 	return nil
 }
 
+type mockTokenizer struct{}
+
+func (mockTokenizer) Token(username screenjournal.Username, category screenjournal.UnsubscribeCategory) screenjournal.UnsubscribeToken {
+	return screenjournal.UnsubscribeToken(fmt.Sprintf("token-%s-%s", username, category))
+}
+
 func TestAnnounceNewReview(t *testing.T) {
 	for _, tt := range []struct {
 		description    string
@@ -122,6 +174,8 @@ https://dev.thescreenjournal.com/movies/123#review456
 
 -ScreenJournal Bot
 
+To unsubscribe from these emails, visit https://dev.thescreenjournal.com/unsubscribe/token-alice-new-reviews
+
 To manage your notifications, visit https://dev.thescreenjournal.com/account/notifications
 `,
 					HtmlBody: `<p>Hey alice,</p>
@@ -132,7 +186,14 @@ To manage your notifications, visit https://dev.thescreenjournal.com/account/not
 
 <p>-ScreenJournal Bot</p>
 
+<p>To unsubscribe from these emails, visit <a href="https://dev.thescreenjournal.com/unsubscribe/token-alice-new-reviews">https://dev.thescreenjournal.com/unsubscribe/token-alice-new-reviews</a></p>
+
 <p>To manage your notifications, visit <a href="https://dev.thescreenjournal.com/account/notifications">https://dev.thescreenjournal.com/account/notifications</a></p>`,
+					Headers: mail.Header{
+						"List-Unsubscribe":      {"<https://dev.thescreenjournal.com/unsubscribe/token-alice-new-reviews>"},
+						"List-Unsubscribe-Post": {"List-Unsubscribe=One-Click"},
+					},
+					IdempotencyKey: "review/456",
 				},
 				{
 					From: mail.Address{
@@ -154,6 +215,8 @@ https://dev.thescreenjournal.com/movies/123#review456
 
 -ScreenJournal Bot
 
+To unsubscribe from these emails, visit https://dev.thescreenjournal.com/unsubscribe/token-charlie-new-reviews
+
 To manage your notifications, visit https://dev.thescreenjournal.com/account/notifications
 `,
 					HtmlBody: `<p>Hey charlie,</p>
@@ -164,7 +227,14 @@ To manage your notifications, visit https://dev.thescreenjournal.com/account/not
 
 <p>-ScreenJournal Bot</p>
 
+<p>To unsubscribe from these emails, visit <a href="https://dev.thescreenjournal.com/unsubscribe/token-charlie-new-reviews">https://dev.thescreenjournal.com/unsubscribe/token-charlie-new-reviews</a></p>
+
 <p>To manage your notifications, visit <a href="https://dev.thescreenjournal.com/account/notifications">https://dev.thescreenjournal.com/account/notifications</a></p>`,
+					Headers: mail.Header{
+						"List-Unsubscribe":      {"<https://dev.thescreenjournal.com/unsubscribe/token-charlie-new-reviews>"},
+						"List-Unsubscribe-Post": {"List-Unsubscribe=One-Click"},
+					},
+					IdempotencyKey: "review/456",
 				},
 			},
 		},
@@ -213,6 +283,8 @@ https://dev.thescreenjournal.com/tv-shows/789?season=3#review456
 
 -ScreenJournal Bot
 
+To unsubscribe from these emails, visit https://dev.thescreenjournal.com/unsubscribe/token-alice-new-reviews
+
 To manage your notifications, visit https://dev.thescreenjournal.com/account/notifications
 `,
 					HtmlBody: `<p>Hey alice,</p>
@@ -223,7 +295,14 @@ To manage your notifications, visit https://dev.thescreenjournal.com/account/not
 
 <p>-ScreenJournal Bot</p>
 
+<p>To unsubscribe from these emails, visit <a href="https://dev.thescreenjournal.com/unsubscribe/token-alice-new-reviews">https://dev.thescreenjournal.com/unsubscribe/token-alice-new-reviews</a></p>
+
 <p>To manage your notifications, visit <a href="https://dev.thescreenjournal.com/account/notifications">https://dev.thescreenjournal.com/account/notifications</a></p>`,
+					Headers: mail.Header{
+						"List-Unsubscribe":      {"<https://dev.thescreenjournal.com/unsubscribe/token-alice-new-reviews>"},
+						"List-Unsubscribe-Post": {"List-Unsubscribe=One-Click"},
+					},
+					IdempotencyKey: "review/456",
 				},
 			},
 		},
@@ -252,7 +331,7 @@ To manage your notifications, visit https://dev.thescreenjournal.com/account/not
 				emailsSent: []email.Message{},
 			}
 
-			announcer := email_announce.New("https://dev.thescreenjournal.com", &sender, tt.store)
+			announcer := email_announce.New("https://dev.thescreenjournal.com", &sender, tt.store, mockTokenizer{})
 			announcer.AnnounceNewReview(tt.review)
 
 			if len(sender.emailsSent) == len(tt.expectedEmails) {
@@ -352,6 +431,8 @@ https://dev.thescreenjournal.com/movies/123#comment707
 
 -ScreenJournal Bot
 
+To unsubscribe from these emails, visit https://dev.thescreenjournal.com/unsubscribe/token-bob-comments
+
 To manage your notifications, visit https://dev.thescreenjournal.com/account/notifications
 `,
 					HtmlBody: `<p>Hey bob,</p>
@@ -362,7 +443,14 @@ To manage your notifications, visit https://dev.thescreenjournal.com/account/not
 
 <p>-ScreenJournal Bot</p>
 
+<p>To unsubscribe from these emails, visit <a href="https://dev.thescreenjournal.com/unsubscribe/token-bob-comments">https://dev.thescreenjournal.com/unsubscribe/token-bob-comments</a></p>
+
 <p>To manage your notifications, visit <a href="https://dev.thescreenjournal.com/account/notifications">https://dev.thescreenjournal.com/account/notifications</a></p>`,
+					Headers: mail.Header{
+						"List-Unsubscribe":      {"<https://dev.thescreenjournal.com/unsubscribe/token-bob-comments>"},
+						"List-Unsubscribe-Post": {"List-Unsubscribe=One-Click"},
+					},
+					IdempotencyKey: "comment/707",
 				},
 				{
 					From: mail.Address{
@@ -384,6 +472,8 @@ https://dev.thescreenjournal.com/movies/123#comment707
 
 -ScreenJournal Bot
 
+To unsubscribe from these emails, visit https://dev.thescreenjournal.com/unsubscribe/token-charlie-comments
+
 To manage your notifications, visit https://dev.thescreenjournal.com/account/notifications
 `,
 					HtmlBody: `<p>Hey charlie,</p>
@@ -394,7 +484,14 @@ To manage your notifications, visit https://dev.thescreenjournal.com/account/not
 
 <p>-ScreenJournal Bot</p>
 
+<p>To unsubscribe from these emails, visit <a href="https://dev.thescreenjournal.com/unsubscribe/token-charlie-comments">https://dev.thescreenjournal.com/unsubscribe/token-charlie-comments</a></p>
+
 <p>To manage your notifications, visit <a href="https://dev.thescreenjournal.com/account/notifications">https://dev.thescreenjournal.com/account/notifications</a></p>`,
+					Headers: mail.Header{
+						"List-Unsubscribe":      {"<https://dev.thescreenjournal.com/unsubscribe/token-charlie-comments>"},
+						"List-Unsubscribe-Post": {"List-Unsubscribe=One-Click"},
+					},
+					IdempotencyKey: "comment/707",
 				},
 			},
 		},
@@ -466,6 +563,8 @@ https://dev.thescreenjournal.com/tv-shows/789?season=3#comment707
 
 -ScreenJournal Bot
 
+To unsubscribe from these emails, visit https://dev.thescreenjournal.com/unsubscribe/token-bob-comments
+
 To manage your notifications, visit https://dev.thescreenjournal.com/account/notifications
 `,
 					HtmlBody: `<p>Hey bob,</p>
@@ -476,7 +575,14 @@ To manage your notifications, visit https://dev.thescreenjournal.com/account/not
 
 <p>-ScreenJournal Bot</p>
 
+<p>To unsubscribe from these emails, visit <a href="https://dev.thescreenjournal.com/unsubscribe/token-bob-comments">https://dev.thescreenjournal.com/unsubscribe/token-bob-comments</a></p>
+
 <p>To manage your notifications, visit <a href="https://dev.thescreenjournal.com/account/notifications">https://dev.thescreenjournal.com/account/notifications</a></p>`,
+					Headers: mail.Header{
+						"List-Unsubscribe":      {"<https://dev.thescreenjournal.com/unsubscribe/token-bob-comments>"},
+						"List-Unsubscribe-Post": {"List-Unsubscribe=One-Click"},
+					},
+					IdempotencyKey: "comment/707",
 				},
 				{
 					From: mail.Address{
@@ -498,6 +604,8 @@ https://dev.thescreenjournal.com/tv-shows/789?season=3#comment707
 
 -ScreenJournal Bot
 
+To unsubscribe from these emails, visit https://dev.thescreenjournal.com/unsubscribe/token-dave-comments
+
 To manage your notifications, visit https://dev.thescreenjournal.com/account/notifications
 `,
 					HtmlBody: `<p>Hey dave,</p>
@@ -508,7 +616,14 @@ To manage your notifications, visit https://dev.thescreenjournal.com/account/not
 
 <p>-ScreenJournal Bot</p>
 
+<p>To unsubscribe from these emails, visit <a href="https://dev.thescreenjournal.com/unsubscribe/token-dave-comments">https://dev.thescreenjournal.com/unsubscribe/token-dave-comments</a></p>
+
 <p>To manage your notifications, visit <a href="https://dev.thescreenjournal.com/account/notifications">https://dev.thescreenjournal.com/account/notifications</a></p>`,
+					Headers: mail.Header{
+						"List-Unsubscribe":      {"<https://dev.thescreenjournal.com/unsubscribe/token-dave-comments>"},
+						"List-Unsubscribe-Post": {"List-Unsubscribe=One-Click"},
+					},
+					IdempotencyKey: "comment/707",
 				},
 			},
 		},
@@ -546,7 +661,7 @@ To manage your notifications, visit https://dev.thescreenjournal.com/account/not
 				emailsSent: []email.Message{},
 			}
 
-			announcer := email_announce.New("https://dev.thescreenjournal.com", &sender, tt.store)
+			announcer := email_announce.New("https://dev.thescreenjournal.com", &sender, tt.store, mockTokenizer{})
 			announcer.AnnounceNewComment(tt.comment)
 
 			if len(sender.emailsSent) == len(tt.expectedEmails) {
@@ -567,3 +682,360 @@ To manage your notifications, visit https://dev.thescreenjournal.com/account/not
 		})
 	}
 }
+
+func TestSendRecap(t *testing.T) {
+	sender := mockEmailSender{
+		emailsSent: []email.Message{},
+	}
+	announcer := email_announce.New("https://dev.thescreenjournal.com", &sender, mockNotificationsStore{}, mockTokenizer{})
+
+	theMatrix := screenjournal.Review{
+		ID: screenjournal.ReviewID(1),
+		Movie: screenjournal.Movie{
+			ID:    screenjournal.MovieID(10),
+			Title: screenjournal.MediaTitle("The Matrix"),
+		},
+	}
+	seinfeld := screenjournal.Review{
+		ID: screenjournal.ReviewID(2),
+		TvShow: screenjournal.TvShow{
+			ID:    screenjournal.TvShowID(4),
+			Title: screenjournal.MediaTitle("Seinfeld"),
+		},
+		TvShowSeason: screenjournal.TvShowSeason(2),
+	}
+	if err := announcer.SendRecap(screenjournal.User{
+		Username: screenjournal.Username("alice"),
+		Email:    screenjournal.Email("alice.amberson@example.com"),
+	}, screenjournal.Recap{
+		Username:                     screenjournal.Username("alice"),
+		Year:                         2024,
+		TitlesWatched:                []screenjournal.Review{theMatrix, seinfeld},
+		MovieTimeWatched:             226 * time.Minute,
+		HighestRated:                 theMatrix,
+		LowestRated:                  seinfeld,
+		MostCommented:                theMatrix,
+		MostCommentedCount:           2,
+		FavoriteReviewer:             screenjournal.Username("bob"),
+		FavoriteReviewerInteractions: 3,
+		FavoriteEmoji:                screenjournal.NewReactionEmoji("🥞"),
+		FavoriteEmojiCount:           2,
+	}); err != nil {
+		t.Fatalf("SendRecap err=%v, want=%v", err, nil)
+	}
+
+	expectedEmails := []email.Message{
+		{
+			From: mail.Address{
+				Name:    "ScreenJournal",
+				Address: "activity@thescreenjournal.com",
+			},
+			To: []mail.Address{
+				{
+					Name:    "alice",
+					Address: "alice.amberson@example.com",
+				},
+			},
+			Subject: "Your 2024 on ScreenJournal",
+			TextBody: `Hey alice,
+
+Here's your 2024 on ScreenJournal:
+
+* You watched 2 titles, including about 3 hours of movies.
+* Your highest-rated title was *The Matrix*.
+* Your lowest-rated title was *Seinfeld (Season 2)*.
+* Your review of *The Matrix* got the most comments.
+* You interacted with bob's reviews more than anyone else's.
+* Your go-to reaction was 🥞
+
+See your full recap:
+
+https://dev.thescreenjournal.com/recap/2024/alice
+
+-ScreenJournal Bot
+`,
+			HtmlBody: `<p>Hey alice,</p>
+
+<p>Here's your 2024 on ScreenJournal:</p>
+
+<ul>
+<li>You watched 2 titles, including about 3 hours of movies.</li>
+<li>Your highest-rated title was <em>The Matrix</em>.</li>
+<li>Your lowest-rated title was <em>Seinfeld (Season 2)</em>.</li>
+<li>Your review of <em>The Matrix</em> got the most comments.</li>
+<li>You interacted with bob's reviews more than anyone else's.</li>
+<li>Your go-to reaction was 🥞</li>
+</ul>
+
+<p>See your full recap:</p>
+
+<p><a href="https://dev.thescreenjournal.com/recap/2024/alice">https://dev.thescreenjournal.com/recap/2024/alice</a></p>
+
+<p>-ScreenJournal Bot</p>`,
+		},
+	}
+	if len(sender.emailsSent) == len(expectedEmails) {
+		if diff := diff.Diff(expectedEmails[0].TextBody, sender.emailsSent[0].TextBody); diff != "" {
+			t.Errorf("recap email (plaintext): %s", diff)
+		}
+	}
+	if got, want := sender.emailsSent, expectedEmails; !reflect.DeepEqual(got, want) {
+		t.Errorf("recap emails don't match expected: %s", strings.Join(deep.Equal(got, want), "\n"))
+	}
+}
+
+func TestAnnounceNewFriendRecommendation(t *testing.T) {
+	for _, tt := range []struct {
+		description    string
+		recommendation screenjournal.FriendRecommendation
+		expectedEmails []email.Message
+	}{
+		{
+			description: "announces movie recommendation with a message",
+			recommendation: screenjournal.FriendRecommendation{
+				ID:   screenjournal.FriendRecommendationID(1),
+				From: screenjournal.Username("userA"),
+				To:   screenjournal.Username("userB"),
+				Movie: screenjournal.Movie{
+					ID:    screenjournal.MovieID(1),
+					Title: screenjournal.MediaTitle("The Waterboy"),
+				},
+				Message: screenjournal.RecommendationMessage("Bobby Boucher is a hero."),
+			},
+			expectedEmails: []email.Message{
+				{
+					From: mail.Address{
+						Name:    "ScreenJournal",
+						Address: "activity@thescreenjournal.com",
+					},
+					To: []mail.Address{
+						{
+							Name:    "userB",
+							Address: "userB@example.com",
+						},
+					},
+					Subject: "userA recommended The Waterboy to you",
+					TextBody: `Hey userB,
+
+userA thinks you need to see *The Waterboy*!
+
+> Bobby Boucher is a hero.
+
+See all your recommendations:
+
+https://dev.thescreenjournal.com/friend-recommendations
+
+-ScreenJournal Bot
+`,
+					HtmlBody: `<p>Hey userB,</p>
+
+<p>userA thinks you need to see <em>The Waterboy</em>!</p>
+
+<blockquote>
+<p>Bobby Boucher is a hero.</p>
+</blockquote>
+
+<p>See all your recommendations:</p>
+
+<p><a href="https://dev.thescreenjournal.com/friend-recommendations">https://dev.thescreenjournal.com/friend-recommendations</a></p>
+
+<p>-ScreenJournal Bot</p>`,
+					IdempotencyKey: "friend-recommendation/1",
+				},
+			},
+		},
+		{
+			description: "announces TV recommendation without a message",
+			recommendation: screenjournal.FriendRecommendation{
+				ID:   screenjournal.FriendRecommendationID(2),
+				From: screenjournal.Username("userA"),
+				To:   screenjournal.Username("userB"),
+				TvShow: screenjournal.TvShow{
+					ID:    screenjournal.TvShowID(1),
+					Title: screenjournal.MediaTitle("Seinfeld"),
+				},
+				TvShowSeason: screenjournal.TvShowSeason(3),
+			},
+			expectedEmails: []email.Message{
+				{
+					From: mail.Address{
+						Name:    "ScreenJournal",
+						Address: "activity@thescreenjournal.com",
+					},
+					To: []mail.Address{
+						{
+							Name:    "userB",
+							Address: "userB@example.com",
+						},
+					},
+					Subject: "userA recommended Seinfeld (Season 3) to you",
+					TextBody: `Hey userB,
+
+userA thinks you need to see *Seinfeld* (Season 3)!
+
+See all your recommendations:
+
+https://dev.thescreenjournal.com/friend-recommendations
+
+-ScreenJournal Bot
+`,
+					HtmlBody: `<p>Hey userB,</p>
+
+<p>userA thinks you need to see <em>Seinfeld</em> (Season 3)!</p>
+
+<p>See all your recommendations:</p>
+
+<p><a href="https://dev.thescreenjournal.com/friend-recommendations">https://dev.thescreenjournal.com/friend-recommendations</a></p>
+
+<p>-ScreenJournal Bot</p>`,
+					IdempotencyKey: "friend-recommendation/2",
+				},
+			},
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			sender := mockEmailSender{
+				emailsSent: []email.Message{},
+			}
+			ns := mockNotificationsStore{
+				subscribers: []screenjournal.EmailSubscriber{
+					{
+						Username: screenjournal.Username("userB"),
+						Email:    screenjournal.Email("userB@example.com"),
+					},
+				},
+			}
+
+			announcer := email_announce.New("https://dev.thescreenjournal.com", &sender, ns, mockTokenizer{})
+			announcer.AnnounceNewFriendRecommendation(tt.recommendation)
+
+			if len(sender.emailsSent) == len(tt.expectedEmails) {
+				for i, emailGot := range sender.emailsSent {
+					emailWant := tt.expectedEmails[i]
+					if diff := diff.Diff(emailWant.TextBody, emailGot.TextBody); diff != "" {
+						t.Errorf("email #%d (plaintext): %s", i, diff)
+					}
+					if diff := diff.Diff(emailWant.HtmlBody, emailGot.HtmlBody); diff != "" {
+						t.Errorf("email #%d (html) %s", i, diff)
+					}
+				}
+			}
+
+			if got, want := sender.emailsSent, tt.expectedEmails; !reflect.DeepEqual(got, want) {
+				t.Errorf("unexpected announcement emails, got=%+v, want=%+v", got, want)
+			}
+		})
+	}
+}
+
+func TestAnnounceMention(t *testing.T) {
+	subscribers := []screenjournal.EmailSubscriber{
+		{
+			Username: screenjournal.Username("alice"),
+			Email:    screenjournal.Email("alice.amberson@example.com"),
+		},
+		{
+			Username: screenjournal.Username("bob"),
+			Email:    screenjournal.Email("bob.bobberton@example.com"),
+		},
+	}
+	review := screenjournal.Review{
+		ID:    screenjournal.ReviewID(12),
+		Owner: screenjournal.Username("bob"),
+		Movie: screenjournal.Movie{
+			ID:    screenjournal.MovieID(5),
+			Title: screenjournal.MediaTitle("Big"),
+		},
+	}
+	for _, tt := range []struct {
+		description     string
+		store           mockNotificationsStore
+		mention         screenjournal.Mention
+		expectedSubject string
+		expectedLink    string
+	}{
+		{
+			description: "announces mention in a review blurb",
+			store:       mockNotificationsStore{subscribers: subscribers},
+			mention: screenjournal.Mention{
+				Mentioned: screenjournal.Username("alice"),
+				Author:    screenjournal.Username("bob"),
+				Review:    review,
+			},
+			expectedSubject: "bob mentioned you in their review of Big",
+			expectedLink:    "https://dev.thescreenjournal.com/movies/5#review12",
+		},
+		{
+			description: "announces mention in a comment",
+			store:       mockNotificationsStore{subscribers: subscribers},
+			mention: screenjournal.Mention{
+				Mentioned: screenjournal.Username("alice"),
+				Author:    screenjournal.Username("bob"),
+				Review:    review,
+				Comment: screenjournal.ReviewComment{
+					ID: screenjournal.CommentID(7),
+				},
+			},
+			expectedSubject: "bob mentioned you in a comment on bob's review of Big",
+			expectedLink:    "https://dev.thescreenjournal.com/movies/5#comment7",
+		},
+		{
+			description: "doesn't announce mention to user who opted out",
+			store: mockNotificationsStore{
+				subscribers: subscribers,
+				mentionsOptOut: map[screenjournal.Username]bool{
+					screenjournal.Username("alice"): true,
+				},
+			},
+			mention: screenjournal.Mention{
+				Mentioned: screenjournal.Username("alice"),
+				Author:    screenjournal.Username("bob"),
+				Review:    review,
+			},
+		},
+		{
+			description: "doesn't announce mention from a muted user",
+			store: mockNotificationsStore{
+				subscribers: subscribers,
+				mutes: map[screenjournal.Username][]screenjournal.Username{
+					screenjournal.Username("alice"): {screenjournal.Username("bob")},
+				},
+			},
+			mention: screenjournal.Mention{
+				Mentioned: screenjournal.Username("alice"),
+				Author:    screenjournal.Username("bob"),
+				Review:    review,
+			},
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			sender := mockEmailSender{
+				emailsSent: []email.Message{},
+			}
+
+			announcer := email_announce.New("https://dev.thescreenjournal.com", &sender, tt.store, mockTokenizer{})
+			announcer.AnnounceMention(tt.mention)
+
+			if tt.expectedSubject == "" {
+				if got, want := len(sender.emailsSent), 0; got != want {
+					t.Fatalf("emailsSent=%d, want=%d", got, want)
+				}
+				return
+			}
+
+			if got, want := len(sender.emailsSent), 1; got != want {
+				t.Fatalf("emailsSent=%d, want=%d", got, want)
+			}
+			msg := sender.emailsSent[0]
+			if got, want := msg.Subject, tt.expectedSubject; got != want {
+				t.Errorf("subject=%q, want=%q", got, want)
+			}
+			if got, want := msg.To[0].Address, "alice.amberson@example.com"; got != want {
+				t.Errorf("to=%q, want=%q", got, want)
+			}
+			if !strings.Contains(msg.TextBody, tt.expectedLink) {
+				t.Errorf("body=%q, want it to contain %q", msg.TextBody, tt.expectedLink)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package inbox
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type (
+	NotificationsStore interface {
+		ReadUsersPublicMeta() ([]screenjournal.UserPublicMeta, error)
+		ReadComment(screenjournal.CommentID) (screenjournal.ReviewComment, error)
+		InsertNotification(screenjournal.Notification) error
+	}
+
+	// Announcer saves announcements as in-app notifications that users see on
+	// their notifications page.
+	Announcer struct {
+		store NotificationsStore
+	}
+)
+
+func New(store NotificationsStore) Announcer {
+	return Announcer{
+		store: store,
+	}
+}
+
+func (a Announcer) AnnounceNewReview(r screenjournal.Review) error {
+	users, err := a.store.ReadUsersPublicMeta()
+	if err != nil {
+		return fmt.Errorf("read users for new review notification: %w", err)
+	}
+	errs := []error{}
+	for _, u := range users {
+		errs = append(errs, a.notify(screenjournal.Notification{
+			Recipient: u.Username,
+			Actor:     r.Owner,
+			Kind:      screenjournal.NotificationKindNewReview,
+			Review:    r,
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+func (a Announcer) AnnounceNewComment(rc screenjournal.ReviewComment) error {
+	errs := []error{}
+	var parentOwner screenjournal.Username
+	if !rc.ParentID.IsZero() {
+		parent, err := a.store.ReadComment(rc.ParentID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("read parent comment %v for reply notification: %w", rc.ParentID, err))
+		} else {
+			parentOwner = parent.Owner
+			errs = append(errs, a.notify(screenjournal.Notification{
+				Recipient: parentOwner,
+				Actor:     rc.Owner,
+				Kind:      screenjournal.NotificationKindReply,
+				Review:    rc.Review,
+				Comment:   rc,
+			}))
+		}
+	}
+
+	// If the review owner already heard about this as a reply, don't notify
+	// them twice.
+	if rc.Review.Owner.Equal(parentOwner) {
+		return errors.Join(errs...)
+	}
+	errs = append(errs, a.notify(screenjournal.Notification{
+		Recipient: rc.Review.Owner,
+		Actor:     rc.Owner,
+		Kind:      screenjournal.NotificationKindComment,
+		Review:    rc.Review,
+		Comment:   rc,
+	}))
+	return errors.Join(errs...)
+}
+
+func (a Announcer) AnnounceNewReaction(rr screenjournal.ReviewReaction) error {
+	recipient := rr.Review.Owner
+	if rr.TargetType() == screenjournal.ReactionTargetComment {
+		recipient = rr.Comment.Owner
+	}
+	return a.notify(screenjournal.Notification{
+		Recipient: recipient,
+		Actor:     rr.Owner,
+		Kind:      screenjournal.NotificationKindReaction,
+		Review:    rr.Review,
+		Comment:   rr.Comment,
+		Emoji:     rr.Emoji,
+	})
+}
+
+func (a Announcer) AnnounceMention(m screenjournal.Mention) error {
+	return a.notify(screenjournal.Notification{
+		Recipient: m.Mentioned,
+		Actor:     m.Author,
+		Kind:      screenjournal.NotificationKindMention,
+		Review:    m.Review,
+		Comment:   m.Comment,
+	})
+}
+
+func (a Announcer) AnnounceNewFriendRecommendation(fr screenjournal.FriendRecommendation) error {
+	// Recommendations already have their own page, so they don't need a
+	// separate notification.
+	log.Printf("not adding inbox notification for recommendation from %s to %s", fr.From, fr.To)
+	return nil
+}
+
+// notify saves the notification unless the recipient is the user who caused
+// it.
+func (a Announcer) notify(n screenjournal.Notification) error {
+	if n.Recipient.Empty() || n.Recipient.Equal(n.Actor) {
+		return nil
+	}
+	if err := a.store.InsertNotification(n); err != nil {
+		return fmt.Errorf("save %v notification for %v: %w", n.Kind, n.Recipient, err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package inbox_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/announce/inbox"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+type mockNotificationsStore struct {
+	users         []screenjournal.UserPublicMeta
+	comments      map[screenjournal.CommentID]screenjournal.ReviewComment
+	notifications []screenjournal.Notification
+}
+
+func (ns *mockNotificationsStore) ReadUsersPublicMeta() ([]screenjournal.UserPublicMeta, error) {
+	return ns.users, nil
+}
+
+func (ns *mockNotificationsStore) ReadComment(id screenjournal.CommentID) (screenjournal.ReviewComment, error) {
+	c, ok := ns.comments[id]
+	if !ok {
+		return screenjournal.ReviewComment{}, store.ErrCommentNotFound
+	}
+	return c, nil
+}
+
+func (ns *mockNotificationsStore) InsertNotification(n screenjournal.Notification) error {
+	ns.notifications = append(ns.notifications, n)
+	return nil
+}
+
+type notificationSummary struct {
+	Recipient screenjournal.Username
+	Kind      screenjournal.NotificationKind
+}
+
+func TestAnnouncer(t *testing.T) {
+	review := screenjournal.Review{
+		ID:    screenjournal.ReviewID(1),
+		Owner: screenjournal.Username("owner"),
+	}
+	for _, tt := range []struct {
+		description string
+		announce    func(inbox.Announcer)
+		expected    []notificationSummary
+	}{
+		{
+			description: "notifies every user except the author of a new review",
+			announce: func(a inbox.Announcer) {
+				a.AnnounceNewReview(review)
+			},
+			expected: []notificationSummary{
+				{"userA", screenjournal.NotificationKindNewReview},
+				{"userB", screenjournal.NotificationKindNewReview},
+			},
+		},
+		{
+			description: "notifies review owner of a new comment",
+			announce: func(a inbox.Announcer) {
+				a.AnnounceNewComment(screenjournal.ReviewComment{
+					ID:     screenjournal.CommentID(2),
+					Owner:  screenjournal.Username("userA"),
+					Review: review,
+				})
+			},
+			expected: []notificationSummary{
+				{"owner", screenjournal.NotificationKindComment},
+			},
+		},
+		{
+			description: "notifies parent comment author and review owner of a reply",
+			announce: func(a inbox.Announcer) {
+				a.AnnounceNewComment(screenjournal.ReviewComment{
+					ID:       screenjournal.CommentID(3),
+					ParentID: screenjournal.CommentID(2),
+					Owner:    screenjournal.Username("userB"),
+					Review:   review,
+				})
+			},
+			expected: []notificationSummary{
+				{"userA", screenjournal.NotificationKindReply},
+				{"owner", screenjournal.NotificationKindComment},
+			},
+		},
+		{
+			description: "notifies review owner only once when someone replies to them",
+			announce: func(a inbox.Announcer) {
+				a.AnnounceNewComment(screenjournal.ReviewComment{
+					ID:       screenjournal.CommentID(5),
+					ParentID: screenjournal.CommentID(4),
+					Owner:    screenjournal.Username("userA"),
+					Review:   review,
+				})
+			},
+			expected: []notificationSummary{
+				{"owner", screenjournal.NotificationKindReply},
+			},
+		},
+		{
+			description: "doesn't notify review owner of their own comment",
+			announce: func(a inbox.Announcer) {
+				a.AnnounceNewComment(screenjournal.ReviewComment{
+					ID:     screenjournal.CommentID(6),
+					Owner:  screenjournal.Username("owner"),
+					Review: review,
+				})
+			},
+			expected: []notificationSummary{},
+		},
+		{
+			description: "notifies review owner of a reaction to their review",
+			announce: func(a inbox.Announcer) {
+				a.AnnounceNewReaction(screenjournal.ReviewReaction{
+					Owner:  screenjournal.Username("userA"),
+					Emoji:  screenjournal.NewReactionEmoji("👍"),
+					Review: review,
+				})
+			},
+			expected: []notificationSummary{
+				{"owner", screenjournal.NotificationKindReaction},
+			},
+		},
+		{
+			description: "notifies comment author of a reaction to their comment",
+			announce: func(a inbox.Announcer) {
+				a.AnnounceNewReaction(screenjournal.ReviewReaction{
+					Owner:  screenjournal.Username("userB"),
+					Emoji:  screenjournal.NewReactionEmoji("👍"),
+					Review: review,
+					Comment: screenjournal.ReviewComment{
+						ID:    screenjournal.CommentID(2),
+						Owner: screenjournal.Username("userA"),
+					},
+				})
+			},
+			expected: []notificationSummary{
+				{"userA", screenjournal.NotificationKindReaction},
+			},
+		},
+		{
+			description: "notifies mentioned user",
+			announce: func(a inbox.Announcer) {
+				a.AnnounceMention(screenjournal.Mention{
+					Mentioned: screenjournal.Username("userB"),
+					Author:    screenjournal.Username("owner"),
+					Review:    review,
+				})
+			},
+			expected: []notificationSummary{
+				{"userB", screenjournal.NotificationKindMention},
+			},
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			s := mockNotificationsStore{
+				users: []screenjournal.UserPublicMeta{
+					{Username: screenjournal.Username("owner")},
+					{Username: screenjournal.Username("userA")},
+					{Username: screenjournal.Username("userB")},
+				},
+				comments: map[screenjournal.CommentID]screenjournal.ReviewComment{
+					screenjournal.CommentID(2): {
+						ID:    screenjournal.CommentID(2),
+						Owner: screenjournal.Username("userA"),
+					},
+					screenjournal.CommentID(4): {
+						ID:    screenjournal.CommentID(4),
+						Owner: screenjournal.Username("owner"),
+					},
+				},
+			}
+
+			tt.announce(inbox.New(&s))
+
+			got := []notificationSummary{}
+			for _, n := range s.notifications {
+				got = append(got, notificationSummary{n.Recipient, n.Kind})
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("notifications=%+v, want=%+v", got, tt.expected)
+			}
+		})
+	}
+}
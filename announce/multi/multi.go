@@ -0,0 +1,125 @@
+package multi
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+// queueSize is how many announcements can wait for a backend before the
+// announcer starts dropping new announcements for that backend.
+const queueSize = 100
+
+type (
+	// Backend is a single destination for announcements, such as email or the
+	// in-app inbox.
+	Backend interface {
+		AnnounceNewReview(screenjournal.Review) error
+		AnnounceNewComment(screenjournal.ReviewComment) error
+		AnnounceNewReaction(screenjournal.ReviewReaction) error
+		AnnounceNewFriendRecommendation(screenjournal.FriendRecommendation) error
+		AnnounceMention(screenjournal.Mention) error
+	}
+
+	NamedBackend struct {
+		// Name identifies the backend in log messages.
+		Name    string
+		Backend Backend
+	}
+
+	// Announcer passes each announcement to all of its backends in the
+	// background, so that the request that caused the announcement doesn't
+	// wait on slow backends. Each backend works through its own queue, so a
+	// backend that is slow, fails, or panics doesn't hold up the others.
+	Announcer struct {
+		queues []queue
+	}
+
+	queue struct {
+		name          string
+		announcements chan announcement
+	}
+
+	announcement struct {
+		event    string
+		announce func(Backend) error
+	}
+)
+
+func New(backends ...NamedBackend) Announcer {
+	queues := make([]queue, 0, len(backends))
+	for _, nb := range backends {
+		q := queue{
+			name:          nb.Name,
+			announcements: make(chan announcement, queueSize),
+		}
+		go func() {
+			for a := range q.announcements {
+				deliver(nb, a)
+			}
+		}()
+		queues = append(queues, q)
+	}
+	return Announcer{
+		queues: queues,
+	}
+}
+
+func (a Announcer) AnnounceNewReview(r screenjournal.Review) {
+	a.dispatch(fmt.Sprintf("new review %v", r.ID), func(b Backend) error {
+		return b.AnnounceNewReview(r)
+	})
+}
+
+func (a Announcer) AnnounceNewComment(rc screenjournal.ReviewComment) {
+	a.dispatch(fmt.Sprintf("new comment %v", rc.ID), func(b Backend) error {
+		return b.AnnounceNewComment(rc)
+	})
+}
+
+func (a Announcer) AnnounceNewReaction(rr screenjournal.ReviewReaction) {
+	a.dispatch(fmt.Sprintf("new reaction %v", rr.ID), func(b Backend) error {
+		return b.AnnounceNewReaction(rr)
+	})
+}
+
+func (a Announcer) AnnounceNewFriendRecommendation(fr screenjournal.FriendRecommendation) {
+	a.dispatch(fmt.Sprintf("new recommendation %v", fr.ID), func(b Backend) error {
+		return b.AnnounceNewFriendRecommendation(fr)
+	})
+}
+
+func (a Announcer) AnnounceMention(m screenjournal.Mention) {
+	a.dispatch(fmt.Sprintf("mention of %v", m.Mentioned), func(b Backend) error {
+		return b.AnnounceMention(m)
+	})
+}
+
+// dispatch queues the announcement for each backend without waiting for any
+// of them to announce it.
+func (a Announcer) dispatch(event string, announce func(Backend) error) {
+	for _, q := range a.queues {
+		select {
+		case q.announcements <- announcement{event: event, announce: announce}:
+		default:
+			log.Printf("dropping %s because the %s announcer has too many announcements waiting", event, q.name)
+		}
+	}
+}
+
+// deliver passes the announcement to the backend and logs the result.
+func deliver(nb NamedBackend, a announcement) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("%s announcer panicked on %s after %v: %v", nb.Name, a.event, time.Since(start), r)
+		}
+	}()
+	if err := a.announce(nb.Backend); err != nil {
+		log.Printf("%s announcer failed on %s after %v: %v", nb.Name, a.event, time.Since(start), err)
+		return
+	}
+	log.Printf("%s announcer finished %s in %v", nb.Name, a.event, time.Since(start))
+}
@@ -0,0 +1,90 @@
+package multi_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/announce/multi"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type mockBackend struct {
+	reviews chan screenjournal.Review
+	// release blocks announcements until it's closed, if it's not nil.
+	release chan struct{}
+	panics  bool
+	err     error
+}
+
+func newMockBackend() *mockBackend {
+	return &mockBackend{
+		reviews: make(chan screenjournal.Review, 10),
+	}
+}
+
+func (b *mockBackend) AnnounceNewReview(r screenjournal.Review) error {
+	if b.release != nil {
+		<-b.release
+	}
+	if b.panics {
+		panic("dummy backend failure")
+	}
+	b.reviews <- r
+	return b.err
+}
+
+func (b *mockBackend) AnnounceNewComment(screenjournal.ReviewComment) error { return nil }
+
+func (b *mockBackend) AnnounceNewReaction(screenjournal.ReviewReaction) error { return nil }
+
+func (b *mockBackend) AnnounceNewFriendRecommendation(screenjournal.FriendRecommendation) error {
+	return nil
+}
+
+func (b *mockBackend) AnnounceMention(screenjournal.Mention) error { return nil }
+
+func TestAnnouncerDispatchesToAllBackends(t *testing.T) {
+	a := newMockBackend()
+	broken := newMockBackend()
+	broken.panics = true
+	failing := newMockBackend()
+	failing.err = errors.New("dummy backend error")
+	slow := newMockBackend()
+	slow.release = make(chan struct{})
+	defer close(slow.release)
+	b := newMockBackend()
+
+	announcer := multi.New(
+		multi.NamedBackend{Name: "a", Backend: a},
+		multi.NamedBackend{Name: "broken", Backend: broken},
+		multi.NamedBackend{Name: "failing", Backend: failing},
+		multi.NamedBackend{Name: "slow", Backend: slow},
+		multi.NamedBackend{Name: "b", Backend: b},
+	)
+
+	// The slow backend never finishes during the test, so these calls would
+	// hang if the announcer waited for its backends.
+	announcer.AnnounceNewReview(screenjournal.Review{ID: screenjournal.ReviewID(5)})
+	announcer.AnnounceNewReview(screenjournal.Review{ID: screenjournal.ReviewID(6)})
+
+	for _, tt := range []struct {
+		name    string
+		backend *mockBackend
+	}{
+		{"a", a},
+		{"failing", failing},
+		{"b", b},
+	} {
+		for _, want := range []screenjournal.ReviewID{5, 6} {
+			select {
+			case got := <-tt.backend.reviews:
+				if got.ID != want {
+					t.Errorf("backend %s received review ID %v, want %v", tt.name, got.ID, want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("backend %s never received review ID %v", tt.name, want)
+			}
+		}
+	}
+}
@@ -21,6 +21,18 @@ func (a Announcer) AnnounceNewComment(rc screenjournal.ReviewComment) {
 	log.Printf("skipping announcement of new comment from %s about %s's review of %s because no announcer is configured", rc.Owner, rc.Review.Owner, readMediaTitle(rc.Review))
 }
 
+func (a Announcer) AnnounceNewFriendRecommendation(fr screenjournal.FriendRecommendation) {
+	log.Printf("skipping announcement of recommendation of %s from %s to %s because no announcer is configured", fr.MediaTitle(), fr.From, fr.To)
+}
+
+func (a Announcer) AnnounceNewReaction(rr screenjournal.ReviewReaction) {
+	log.Printf("skipping announcement of %s reaction from %s to %s's review of %s because no announcer is configured", rr.Emoji, rr.Owner, rr.Review.Owner, readMediaTitle(rr.Review))
+}
+
+func (a Announcer) AnnounceMention(m screenjournal.Mention) {
+	log.Printf("skipping announcement of %s mentioning %s on %s because no announcer is configured", m.Author, m.Mentioned, readMediaTitle(m.Review))
+}
+
 func readMediaTitle(r screenjournal.Review) screenjournal.MediaTitle {
 	if !r.Movie.ID.IsZero() {
 		return r.Movie.Title
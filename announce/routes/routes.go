@@ -0,0 +1,64 @@
+// Package routes builds links to reviews and comments for announcements that
+// users see outside of ScreenJournal, like emails and webhook payloads.
+package routes
+
+import (
+	"fmt"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+// ReviewPage returns the route to the page that shows the review.
+func ReviewPage(r screenjournal.Review) string {
+	if !r.Movie.ID.IsZero() {
+		return fmt.Sprintf("/movies/%d", r.Movie.ID.Int64())
+	}
+	return fmt.Sprintf("/tv-shows/%d?season=%d", r.TvShow.ID.Int64(), r.TvShowSeason.UInt8())
+}
+
+// Review returns the route to the review's anchor on its media page.
+func Review(r screenjournal.Review) string {
+	return fmt.Sprintf("%s#review%d", ReviewPage(r), r.ID.UInt64())
+}
+
+// Comment returns the route to the comment's anchor on its review's media
+// page.
+func Comment(rc screenjournal.ReviewComment) string {
+	return fmt.Sprintf("%s#comment%d", ReviewPage(rc.Review), rc.ID.UInt64())
+}
+
+// Unsubscribe returns the route to the page that applies an unsubscribe
+// token.
+func Unsubscribe(token screenjournal.UnsubscribeToken) string {
+	return "/unsubscribe/" + token.String()
+}
+
+// Title returns the title of the reviewed media.
+func Title(r screenjournal.Review) screenjournal.MediaTitle {
+	if !r.Movie.ID.IsZero() {
+		return r.Movie.Title
+	}
+	return r.TvShow.Title
+}
+
+// SeasonSuffix returns a label for the TV show season, such as " (Season 2)",
+// or an empty string if the review is of a movie.
+func SeasonSuffix(r screenjournal.Review) string {
+	if !r.Movie.ID.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" (Season %d)", r.TvShowSeason.UInt8())
+}
+
+// Poster returns the URL of a thumbnail of the reviewed media's poster, or an
+// empty string if the media has no poster.
+func Poster(r screenjournal.Review) string {
+	pp := r.TvShow.PosterPath
+	if !r.Movie.ID.IsZero() {
+		pp = r.Movie.PosterPath
+	}
+	if pp.Path == "" {
+		return ""
+	}
+	return "https://image.tmdb.org/t/p/w185" + pp.Path
+}
@@ -0,0 +1,99 @@
+package routes_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/announce/routes"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+func TestRoutes(t *testing.T) {
+	for _, tt := range []struct {
+		description     string
+		review          screenjournal.Review
+		commentID       screenjournal.CommentID
+		expectedReview  string
+		expectedComment string
+		expectedSuffix  string
+	}{
+		{
+			description: "movie review",
+			review: screenjournal.Review{
+				ID:    screenjournal.ReviewID(3),
+				Movie: screenjournal.Movie{ID: screenjournal.MovieID(12)},
+			},
+			commentID:       screenjournal.CommentID(8),
+			expectedReview:  "/movies/12#review3",
+			expectedComment: "/movies/12#comment8",
+			expectedSuffix:  "",
+		},
+		{
+			description: "TV show review",
+			review: screenjournal.Review{
+				ID:           screenjournal.ReviewID(4),
+				TvShow:       screenjournal.TvShow{ID: screenjournal.TvShowID(5)},
+				TvShowSeason: screenjournal.TvShowSeason(2),
+			},
+			commentID:       screenjournal.CommentID(9),
+			expectedReview:  "/tv-shows/5?season=2#review4",
+			expectedComment: "/tv-shows/5?season=2#comment9",
+			expectedSuffix:  " (Season 2)",
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			if got, want := routes.Review(tt.review), tt.expectedReview; got != want {
+				t.Errorf("review route=%s, want=%s", got, want)
+			}
+			comment := screenjournal.ReviewComment{ID: tt.commentID, Review: tt.review}
+			if got, want := routes.Comment(comment), tt.expectedComment; got != want {
+				t.Errorf("comment route=%s, want=%s", got, want)
+			}
+			if got, want := routes.SeasonSuffix(tt.review), tt.expectedSuffix; got != want {
+				t.Errorf("season suffix=%q, want=%q", got, want)
+			}
+		})
+	}
+}
+
+func TestPoster(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		review      screenjournal.Review
+		expected    string
+	}{
+		{
+			description: "movie with poster",
+			review: screenjournal.Review{
+				Movie: screenjournal.Movie{
+					ID:         screenjournal.MovieID(12),
+					PosterPath: url.URL{Path: "/the-waterboy.jpg"},
+				},
+			},
+			expected: "https://image.tmdb.org/t/p/w185/the-waterboy.jpg",
+		},
+		{
+			description: "TV show with poster",
+			review: screenjournal.Review{
+				TvShow: screenjournal.TvShow{
+					ID:         screenjournal.TvShowID(5),
+					PosterPath: url.URL{Path: "/party-down.jpg"},
+				},
+			},
+			expected: "https://image.tmdb.org/t/p/w185/party-down.jpg",
+		},
+		{
+			description: "movie without poster",
+			review: screenjournal.Review{
+				Movie: screenjournal.Movie{ID: screenjournal.MovieID(12)},
+			},
+			expected: "",
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			if got, want := routes.Poster(tt.review), tt.expected; got != want {
+				t.Errorf("poster=%q, want=%q", got, want)
+			}
+		})
+	}
+}
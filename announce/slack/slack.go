@@ -0,0 +1,168 @@
+// Package slack announces new reviews and comments to a Slack channel through
+// an incoming webhook.
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/mtlynch/screenjournal/v2/announce/routes"
+	"github.com/mtlynch/screenjournal/v2/markdown"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/stars"
+)
+
+// Slack rejects section text longer than 3000 characters, but we keep
+// announcements much shorter so they don't flood the channel.
+const maxQuoteLength = 500
+
+type (
+	Announcer struct {
+		baseURL    string
+		webhookURL string
+		client     *http.Client
+	}
+
+	message struct {
+		// Text is the fallback for notifications and clients that can't
+		// render blocks.
+		Text   string  `json:"text"`
+		Blocks []block `json:"blocks"`
+	}
+
+	block struct {
+		Type      string     `json:"type"`
+		Text      *text      `json:"text,omitempty"`
+		Accessory *accessory `json:"accessory,omitempty"`
+	}
+
+	text struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+
+	accessory struct {
+		Type     string `json:"type"`
+		ImageURL string `json:"image_url"`
+		AltText  string `json:"alt_text"`
+	}
+)
+
+func New(baseURL, webhookURL string, client *http.Client) Announcer {
+	return Announcer{
+		baseURL:    baseURL,
+		webhookURL: webhookURL,
+		client:     client,
+	}
+}
+
+func (a Announcer) AnnounceNewReview(r screenjournal.Review) error {
+	title := routes.Title(r).String() + routes.SeasonSuffix(r)
+	lines := []string{
+		fmt.Sprintf("*%s* posted a new review of *<%s|%s>*", escape(r.Owner.String()), a.baseURL+routes.Review(r), escape(title)),
+	}
+	if !r.Rating.IsNil() {
+		lines = append(lines, stars.Plaintext(r.Rating))
+	}
+	if blurb := markdown.RenderBlurbAsPlaintext(r.Blurb); blurb != "" {
+		lines = append(lines, quote(blurb))
+	}
+	return a.post(message{
+		Text:   fmt.Sprintf("%s posted a new review of %s", r.Owner, title),
+		Blocks: []block{section(strings.Join(lines, "\n"), r)},
+	})
+}
+
+func (a Announcer) AnnounceNewComment(rc screenjournal.ReviewComment) error {
+	title := routes.Title(rc.Review).String() + routes.SeasonSuffix(rc.Review)
+	lines := []string{
+		fmt.Sprintf("*%s* commented on *<%s|%s's review of %s>*", escape(rc.Owner.String()), a.baseURL+routes.Comment(rc), escape(rc.Review.Owner.String()), escape(title)),
+	}
+	if comment := markdown.RenderCommentAsPlaintext(rc.CommentText); comment != "" {
+		lines = append(lines, quote(comment))
+	}
+	return a.post(message{
+		Text:   fmt.Sprintf("%s commented on %s's review of %s", rc.Owner, rc.Review.Owner, title),
+		Blocks: []block{section(strings.Join(lines, "\n"), rc.Review)},
+	})
+}
+
+func (a Announcer) AnnounceNewReaction(rr screenjournal.ReviewReaction) error {
+	// Reactions are too small to be worth a channel message.
+	log.Printf("not sending reaction from %s to Slack", rr.Owner)
+	return nil
+}
+
+func (a Announcer) AnnounceNewFriendRecommendation(fr screenjournal.FriendRecommendation) error {
+	// Recommendations are private between two users, so they don't go to a
+	// shared channel.
+	log.Printf("not sending recommendation from %s to %s to Slack", fr.From, fr.To)
+	return nil
+}
+
+func (a Announcer) AnnounceMention(m screenjournal.Mention) error {
+	// The channel already receives the review or comment that contains the
+	// mention.
+	log.Printf("not sending mention of %s to Slack", m.Mentioned)
+	return nil
+}
+
+func (a Announcer) post(m message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("serialize Slack message: %w", err)
+	}
+
+	res, err := a.client.Post(a.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("post message to Slack: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		details, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("Slack rejected message with HTTP %d: %s", res.StatusCode, details)
+	}
+
+	return nil
+}
+
+// section creates a block of mrkdwn text with the poster of the reviewed media
+// beside it.
+func section(mrkdwn string, r screenjournal.Review) block {
+	b := block{
+		Type: "section",
+		Text: &text{
+			Type: "mrkdwn",
+			Text: mrkdwn,
+		},
+	}
+	if poster := routes.Poster(r); poster != "" {
+		b.Accessory = &accessory{
+			Type:     "image",
+			ImageURL: poster,
+			AltText:  routes.Title(r).String(),
+		}
+	}
+	return b
+}
+
+// quote formats user-supplied text as a mrkdwn block quote.
+func quote(s string) string {
+	runes := []rune(s)
+	if len(runes) > maxQuoteLength {
+		s = string(runes[:maxQuoteLength-1]) + "…"
+	}
+	return "> " + strings.ReplaceAll(escape(s), "\n", "\n> ")
+}
+
+// escape replaces the characters that Slack treats as control sequences, so
+// that user-supplied text can't inject links or mentions like <!channel>.
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,137 @@
+package slack_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/announce/slack"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+// mockSlack records the messages that it receives from the announcer.
+type mockSlack struct {
+	messages []map[string]any
+}
+
+func (m *mockSlack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var msg map[string]any
+	if err := json.Unmarshal(body, &msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m.messages = append(m.messages, msg)
+	w.Write([]byte("ok"))
+}
+
+func TestAnnounceNewReview(t *testing.T) {
+	mock := mockSlack{}
+	server := httptest.NewServer(&mock)
+	defer server.Close()
+
+	announcer := slack.New("https://dev.thescreenjournal.com", server.URL, server.Client())
+	if err := announcer.AnnounceNewReview(screenjournal.Review{
+		ID:     screenjournal.ReviewID(7),
+		Owner:  screenjournal.Username("alice"),
+		Rating: screenjournal.NewRating(7),
+		Blurb:  screenjournal.Blurb("Funny & <!channel>\n\n!spoilers\n\nSandler wins the game."),
+		Movie: screenjournal.Movie{
+			ID:         screenjournal.MovieID(12),
+			Title:      screenjournal.MediaTitle("The Waterboy"),
+			PosterPath: url.URL{Path: "/the-waterboy.jpg"},
+		},
+	}); err != nil {
+		t.Fatalf("AnnounceNewReview err=%v, want=%v", err, nil)
+	}
+
+	if got, want := len(mock.messages), 1; got != want {
+		t.Fatalf("messages=%d, want=%d", got, want)
+	}
+	if got, want := mock.messages[0], map[string]any{
+		"text": "alice posted a new review of The Waterboy",
+		"blocks": []any{
+			map[string]any{
+				"type": "section",
+				"text": map[string]any{
+					"type": "mrkdwn",
+					"text": "*alice* posted a new review of *<https://dev.thescreenjournal.com/movies/12#review7|The Waterboy>*\n★★★½☆\n> Funny &amp; &lt;!channel&gt;",
+				},
+				"accessory": map[string]any{
+					"type":      "image",
+					"image_url": "https://image.tmdb.org/t/p/w185/the-waterboy.jpg",
+					"alt_text":  "The Waterboy",
+				},
+			},
+		},
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("message=%+v, want=%+v", got, want)
+	}
+}
+
+func TestAnnounceNewComment(t *testing.T) {
+	mock := mockSlack{}
+	server := httptest.NewServer(&mock)
+	defer server.Close()
+
+	announcer := slack.New("https://dev.thescreenjournal.com", server.URL, server.Client())
+	if err := announcer.AnnounceNewComment(screenjournal.ReviewComment{
+		ID:          screenjournal.CommentID(4),
+		Owner:       screenjournal.Username("bob"),
+		CommentText: screenjournal.CommentText("Agreed"),
+		Review: screenjournal.Review{
+			ID:           screenjournal.ReviewID(7),
+			Owner:        screenjournal.Username("alice"),
+			TvShow:       screenjournal.TvShow{ID: screenjournal.TvShowID(3), Title: "Party Down"},
+			TvShowSeason: screenjournal.TvShowSeason(2),
+		},
+	}); err != nil {
+		t.Fatalf("AnnounceNewComment err=%v, want=%v", err, nil)
+	}
+
+	if got, want := len(mock.messages), 1; got != want {
+		t.Fatalf("messages=%d, want=%d", got, want)
+	}
+	if got, want := mock.messages[0], map[string]any{
+		"text": "bob commented on alice's review of Party Down (Season 2)",
+		"blocks": []any{
+			map[string]any{
+				"type": "section",
+				"text": map[string]any{
+					"type": "mrkdwn",
+					"text": "*bob* commented on *<https://dev.thescreenjournal.com/tv-shows/3?season=2#comment4|alice's review of Party Down (Season 2)>*\n> Agreed",
+				},
+			},
+		},
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("message=%+v, want=%+v", got, want)
+	}
+}
+
+func TestAnnounceReportsRejectedMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	announcer := slack.New("https://dev.thescreenjournal.com", server.URL, server.Client())
+	err := announcer.AnnounceNewReview(screenjournal.Review{
+		ID:    screenjournal.ReviewID(7),
+		Owner: screenjournal.Username("alice"),
+		Movie: screenjournal.Movie{
+			ID:    screenjournal.MovieID(12),
+			Title: screenjournal.MediaTitle("The Waterboy"),
+		},
+	})
+	if err == nil {
+		t.Errorf("AnnounceNewReview err=%v, want an error", err)
+	}
+}
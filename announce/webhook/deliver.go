@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 of the request body, keyed with
+	// the webhook's secret.
+	SignatureHeader = "X-ScreenJournal-Signature"
+	EventHeader     = "X-ScreenJournal-Event"
+	DeliveryHeader  = "X-ScreenJournal-Delivery"
+
+	// maxAttempts is the number of times to try a delivery before giving up.
+	maxAttempts = 8
+
+	// initialBackoff is how long to wait before the first retry. Each later
+	// retry waits twice as long as the previous one.
+	initialBackoff = 30 * time.Second
+)
+
+// Deliverer sends queued webhook deliveries and retries the ones that fail.
+type Deliverer struct {
+	store  Store
+	client *http.Client
+	clock  func() time.Time
+}
+
+func NewDeliverer(store Store, client *http.Client, clock func() time.Time) Deliverer {
+	return Deliverer{
+		store:  store,
+		client: client,
+		clock:  clock,
+	}
+}
+
+// Run delivers due webhooks every interval until ctx is cancelled.
+func (d Deliverer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		d.DeliverDue()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue attempts every pending delivery whose next attempt time has
+// arrived.
+func (d Deliverer) DeliverDue() {
+	deliveries, err := d.store.ReadDueWebhookDeliveries(d.clock())
+	if err != nil {
+		log.Printf("failed to read due webhook deliveries: %v", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		delivery.Attempts++
+		var ok bool
+		delivery.LastResult, ok = d.send(delivery)
+		if ok {
+			log.Printf("delivered %s to webhook %v", delivery.Event, delivery.Webhook.ID)
+			delivery.Status = screenjournal.WebhookDeliveryDelivered
+		} else if delivery.Attempts >= maxAttempts {
+			log.Printf("giving up on %s delivery to webhook %v after %d attempts: %s", delivery.Event, delivery.Webhook.ID, delivery.Attempts, delivery.LastResult)
+			delivery.Status = screenjournal.WebhookDeliveryFailed
+		} else {
+			delivery.NextAttempt = d.clock().Add(backoff(delivery.Attempts))
+			log.Printf("failed to deliver %s to webhook %v (attempt %d), retrying at %v: %s", delivery.Event, delivery.Webhook.ID, delivery.Attempts, delivery.NextAttempt, delivery.LastResult)
+		}
+
+		if err := d.store.UpdateWebhookDelivery(delivery); err != nil {
+			log.Printf("failed to save webhook delivery %v: %v", delivery.ID, err)
+		}
+	}
+}
+
+// send POSTs the delivery's payload. It returns a description of the outcome
+// and whether the receiver accepted the payload.
+func (d Deliverer) send(delivery screenjournal.WebhookDelivery) (string, bool) {
+	req, err := http.NewRequest(http.MethodPost, delivery.Webhook.URL.String(), bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Sprintf("invalid request: %v", err), false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event.String())
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(SignatureHeader, Sign(delivery.Webhook.Secret, delivery.Payload))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err.Error(), false
+	}
+	defer func() {
+		if _, err := io.Copy(io.Discard, res.Body); err != nil {
+			log.Printf("failed to read webhook response body: %v", err)
+		}
+		if err := res.Body.Close(); err != nil {
+			log.Printf("failed to close webhook response body: %v", err)
+		}
+	}()
+
+	return fmt.Sprintf("HTTP %d", res.StatusCode), res.StatusCode >= 200 && res.StatusCode <= 299
+}
+
+// Sign returns the value of the signature header for the given payload.
+func Sign(secret screenjournal.WebhookSecret, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret.String()))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func backoff(attempts int) time.Duration {
+	return initialBackoff * time.Duration(1<<(attempts-1))
+}
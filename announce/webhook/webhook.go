@@ -0,0 +1,174 @@
+// Package webhook announces new activity by POSTing signed JSON payloads to
+// admin-configured URLs.
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/announce/routes"
+	"github.com/mtlynch/screenjournal/v2/markdown"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type (
+	Store interface {
+		ReadWebhooks() ([]screenjournal.Webhook, error)
+		InsertWebhookDelivery(screenjournal.WebhookDelivery) (screenjournal.WebhookDeliveryID, error)
+		ReadDueWebhookDeliveries(time.Time) ([]screenjournal.WebhookDelivery, error)
+		UpdateWebhookDelivery(screenjournal.WebhookDelivery) error
+	}
+
+	// Announcer queues a delivery to every webhook for each new review,
+	// comment, or reaction. A Deliverer sends the queued payloads.
+	Announcer struct {
+		baseURL string
+		store   Store
+		clock   func() time.Time
+	}
+
+	payload struct {
+		Event    screenjournal.WebhookEvent `json:"event"`
+		Actor    string                     `json:"actor"`
+		Title    string                     `json:"title"`
+		URL      string                     `json:"url"`
+		Review   reviewPayload              `json:"review"`
+		Comment  *commentPayload            `json:"comment,omitempty"`
+		Reaction *reactionPayload           `json:"reaction,omitempty"`
+	}
+
+	reviewPayload struct {
+		ID     uint64 `json:"id"`
+		Owner  string `json:"owner"`
+		Rating *uint8 `json:"rating"`
+		Blurb  string `json:"blurb"`
+	}
+
+	commentPayload struct {
+		ID    uint64 `json:"id"`
+		Owner string `json:"owner"`
+		Text  string `json:"text"`
+	}
+
+	reactionPayload struct {
+		ID        uint64 `json:"id"`
+		Owner     string `json:"owner"`
+		Emoji     string `json:"emoji"`
+		CommentID uint64 `json:"comment_id,omitempty"`
+	}
+)
+
+func New(baseURL string, store Store, clock func() time.Time) Announcer {
+	return Announcer{
+		baseURL: baseURL,
+		store:   store,
+		clock:   clock,
+	}
+}
+
+func (a Announcer) AnnounceNewReview(r screenjournal.Review) error {
+	return a.enqueue(payload{
+		Event:  screenjournal.WebhookEventNewReview,
+		Actor:  r.Owner.String(),
+		Title:  mediaTitle(r),
+		URL:    a.baseURL + routes.Review(r),
+		Review: newReviewPayload(r),
+	})
+}
+
+func (a Announcer) AnnounceNewComment(rc screenjournal.ReviewComment) error {
+	return a.enqueue(payload{
+		Event:  screenjournal.WebhookEventNewComment,
+		Actor:  rc.Owner.String(),
+		Title:  mediaTitle(rc.Review),
+		URL:    a.baseURL + routes.Comment(rc),
+		Review: newReviewPayload(rc.Review),
+		Comment: &commentPayload{
+			ID:    rc.ID.UInt64(),
+			Owner: rc.Owner.String(),
+			Text:  markdown.RenderCommentAsPlaintext(rc.CommentText),
+		},
+	})
+}
+
+func (a Announcer) AnnounceNewReaction(rr screenjournal.ReviewReaction) error {
+	url := a.baseURL + routes.Review(rr.Review)
+	if rr.TargetType() == screenjournal.ReactionTargetComment {
+		rr.Comment.Review = rr.Review
+		url = a.baseURL + routes.Comment(rr.Comment)
+	}
+	return a.enqueue(payload{
+		Event:  screenjournal.WebhookEventNewReaction,
+		Actor:  rr.Owner.String(),
+		Title:  mediaTitle(rr.Review),
+		URL:    url,
+		Review: newReviewPayload(rr.Review),
+		Reaction: &reactionPayload{
+			ID:        rr.ID.UInt64(),
+			Owner:     rr.Owner.String(),
+			Emoji:     rr.Emoji.String(),
+			CommentID: rr.Comment.ID.UInt64(),
+		},
+	})
+}
+
+func (a Announcer) AnnounceNewFriendRecommendation(fr screenjournal.FriendRecommendation) error {
+	// Recommendations are private between two users, so they don't go to
+	// webhooks.
+	log.Printf("not sending recommendation from %s to %s to webhooks", fr.From, fr.To)
+	return nil
+}
+
+func (a Announcer) AnnounceMention(m screenjournal.Mention) error {
+	// The webhook already receives the review or comment that contains the
+	// mention.
+	log.Printf("not sending mention of %s to webhooks", m.Mentioned)
+	return nil
+}
+
+// enqueue saves a delivery of p for every configured webhook.
+func (a Announcer) enqueue(p payload) error {
+	webhooks, err := a.store.ReadWebhooks()
+	if err != nil {
+		return fmt.Errorf("read webhooks: %w", err)
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("serialize %s webhook payload: %w", p.Event, err)
+	}
+
+	errs := []error{}
+	for _, wh := range webhooks {
+		if _, err := a.store.InsertWebhookDelivery(screenjournal.WebhookDelivery{
+			Webhook:     wh,
+			Event:       p.Event,
+			Payload:     body,
+			NextAttempt: a.clock(),
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("queue %s delivery to webhook %v: %w", p.Event, wh.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func newReviewPayload(r screenjournal.Review) reviewPayload {
+	return reviewPayload{
+		ID:     r.ID.UInt64(),
+		Owner:  r.Owner.String(),
+		Rating: r.Rating.Value,
+		// Receivers may show the blurb to anyone, so leave out spoilers and
+		// markdown syntax.
+		Blurb: markdown.RenderBlurbAsPlaintext(r.Blurb),
+	}
+}
+
+func mediaTitle(r screenjournal.Review) string {
+	return routes.Title(r).String() + routes.SeasonSuffix(r)
+}
@@ -0,0 +1,272 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/announce/webhook"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type mockStore struct {
+	webhooks   []screenjournal.Webhook
+	deliveries []screenjournal.WebhookDelivery
+}
+
+func (s *mockStore) ReadWebhooks() ([]screenjournal.Webhook, error) {
+	return s.webhooks, nil
+}
+
+func (s *mockStore) InsertWebhookDelivery(d screenjournal.WebhookDelivery) (screenjournal.WebhookDeliveryID, error) {
+	d.ID = screenjournal.WebhookDeliveryID(len(s.deliveries) + 1)
+	d.Status = screenjournal.WebhookDeliveryPending
+	s.deliveries = append(s.deliveries, d)
+	return d.ID, nil
+}
+
+func (s *mockStore) ReadDueWebhookDeliveries(now time.Time) ([]screenjournal.WebhookDelivery, error) {
+	due := []screenjournal.WebhookDelivery{}
+	for _, d := range s.deliveries {
+		if d.Status == screenjournal.WebhookDeliveryPending && !d.NextAttempt.After(now) {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (s *mockStore) UpdateWebhookDelivery(d screenjournal.WebhookDelivery) error {
+	s.deliveries[d.ID-1] = d
+	return nil
+}
+
+func mustParseTime(t *testing.T, s string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf("failed to parse time %s: %v", s, err)
+	}
+	return parsed
+}
+
+func TestAnnounceQueuesPayloadForEachWebhook(t *testing.T) {
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+	store := mockStore{
+		webhooks: []screenjournal.Webhook{
+			{ID: screenjournal.WebhookID(1), URL: "https://a.example.com/hook"},
+			{ID: screenjournal.WebhookID(2), URL: "https://b.example.com/hook"},
+		},
+	}
+	announcer := webhook.New("https://dev.thescreenjournal.com", &store, func() time.Time { return now })
+
+	review := screenjournal.Review{
+		ID:           screenjournal.ReviewID(7),
+		Owner:        screenjournal.Username("alice"),
+		Rating:       screenjournal.NewRating(8),
+		Blurb:        screenjournal.Blurb("Great!"),
+		TvShow:       screenjournal.TvShow{ID: screenjournal.TvShowID(3), Title: "Party Down"},
+		TvShowSeason: screenjournal.TvShowSeason(2),
+	}
+	announcer.AnnounceNewComment(screenjournal.ReviewComment{
+		ID:          screenjournal.CommentID(4),
+		Owner:       screenjournal.Username("bob"),
+		CommentText: screenjournal.CommentText("Agreed"),
+		Review:      review,
+	})
+
+	if got, want := len(store.deliveries), 2; got != want {
+		t.Fatalf("deliveries=%d, want=%d", got, want)
+	}
+	for i, d := range store.deliveries {
+		if got, want := d.Webhook.ID, store.webhooks[i].ID; got != want {
+			t.Errorf("delivery %d webhook=%v, want=%v", i, got, want)
+		}
+		if got, want := d.Event, screenjournal.WebhookEventNewComment; got != want {
+			t.Errorf("delivery %d event=%v, want=%v", i, got, want)
+		}
+		if got, want := d.NextAttempt, now; !got.Equal(want) {
+			t.Errorf("delivery %d next attempt=%v, want=%v", i, got, want)
+		}
+	}
+
+	var payload struct {
+		Event  string `json:"event"`
+		Actor  string `json:"actor"`
+		Title  string `json:"title"`
+		URL    string `json:"url"`
+		Review struct {
+			ID     uint64 `json:"id"`
+			Rating uint8  `json:"rating"`
+		} `json:"review"`
+		Comment struct {
+			Text string `json:"text"`
+		} `json:"comment"`
+	}
+	if err := json.Unmarshal(store.deliveries[0].Payload, &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if got, want := payload.URL, "https://dev.thescreenjournal.com/tv-shows/3?season=2#comment4"; got != want {
+		t.Errorf("url=%s, want=%s", got, want)
+	}
+	if got, want := payload.Title, "Party Down (Season 2)"; got != want {
+		t.Errorf("title=%s, want=%s", got, want)
+	}
+	if got, want := payload.Actor, "bob"; got != want {
+		t.Errorf("actor=%s, want=%s", got, want)
+	}
+	if got, want := payload.Review.Rating, uint8(8); got != want {
+		t.Errorf("rating=%d, want=%d", got, want)
+	}
+	if got, want := payload.Comment.Text, "Agreed"; got != want {
+		t.Errorf("comment text=%s, want=%s", got, want)
+	}
+}
+
+func TestAnnounceLeavesSpoilersOutOfPayload(t *testing.T) {
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+	store := mockStore{
+		webhooks: []screenjournal.Webhook{
+			{ID: screenjournal.WebhookID(1), URL: "https://a.example.com/hook"},
+		},
+	}
+	announcer := webhook.New("https://dev.thescreenjournal.com", &store, func() time.Time { return now })
+
+	if err := announcer.AnnounceNewReview(screenjournal.Review{
+		ID:    screenjournal.ReviewID(7),
+		Owner: screenjournal.Username("alice"),
+		Blurb: screenjournal.Blurb("**Funny!**\n\n!spoilers\n\nSandler wins the game."),
+		Movie: screenjournal.Movie{ID: screenjournal.MovieID(12), Title: "The Waterboy"},
+	}); err != nil {
+		t.Fatalf("AnnounceNewReview err=%v, want=%v", err, nil)
+	}
+
+	if got, want := len(store.deliveries), 1; got != want {
+		t.Fatalf("deliveries=%d, want=%d", got, want)
+	}
+	if strings.Contains(string(store.deliveries[0].Payload), "Sandler") {
+		t.Errorf("payload contains spoiler text: %s", store.deliveries[0].Payload)
+	}
+
+	var payload struct {
+		Review struct {
+			Blurb string `json:"blurb"`
+		} `json:"review"`
+	}
+	if err := json.Unmarshal(store.deliveries[0].Payload, &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if got, want := payload.Review.Blurb, "Funny!"; got != want {
+		t.Errorf("blurb=%q, want=%q", got, want)
+	}
+}
+
+func TestDeliverDue(t *testing.T) {
+	for _, tt := range []struct {
+		description         string
+		responseStatus      int
+		previousAttempts    int
+		expectedStatus      screenjournal.WebhookDeliveryStatus
+		expectedResult      string
+		expectedNextAttempt string
+	}{
+		{
+			description:         "marks successful delivery as delivered",
+			responseStatus:      http.StatusNoContent,
+			expectedStatus:      screenjournal.WebhookDeliveryDelivered,
+			expectedResult:      "HTTP 204",
+			expectedNextAttempt: "2025-03-01T12:00:00Z",
+		},
+		{
+			description:         "schedules a retry after a failed first attempt",
+			responseStatus:      http.StatusInternalServerError,
+			expectedStatus:      screenjournal.WebhookDeliveryPending,
+			expectedResult:      "HTTP 500",
+			expectedNextAttempt: "2025-03-01T12:00:30Z",
+		},
+		{
+			description:         "backs off exponentially on repeated failures",
+			responseStatus:      http.StatusBadGateway,
+			previousAttempts:    3,
+			expectedStatus:      screenjournal.WebhookDeliveryPending,
+			expectedResult:      "HTTP 502",
+			expectedNextAttempt: "2025-03-01T12:04:00Z",
+		},
+		{
+			description:         "gives up after the final attempt",
+			responseStatus:      http.StatusInternalServerError,
+			previousAttempts:    7,
+			expectedStatus:      screenjournal.WebhookDeliveryFailed,
+			expectedResult:      "HTTP 500",
+			expectedNextAttempt: "2025-03-01T12:00:00Z",
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			secret := screenjournal.WebhookSecret("dummy-secret")
+			body := []byte(`{"event":"review.created"}`)
+
+			var gotSignature string
+			var gotBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotSignature = r.Header.Get(webhook.SignatureHeader)
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.responseStatus)
+			}))
+			defer server.Close()
+
+			now := mustParseTime(t, "2025-03-01T12:00:00Z")
+			store := mockStore{
+				deliveries: []screenjournal.WebhookDelivery{
+					{
+						ID: screenjournal.WebhookDeliveryID(1),
+						Webhook: screenjournal.Webhook{
+							ID:     screenjournal.WebhookID(1),
+							URL:    screenjournal.WebhookURL(server.URL),
+							Secret: secret,
+						},
+						Event:       screenjournal.WebhookEventNewReview,
+						Payload:     body,
+						Status:      screenjournal.WebhookDeliveryPending,
+						Attempts:    tt.previousAttempts,
+						NextAttempt: now,
+					},
+				},
+			}
+
+			webhook.NewDeliverer(&store, server.Client(), func() time.Time { return now }).DeliverDue()
+
+			if got, want := string(gotBody), string(body); got != want {
+				t.Errorf("body=%s, want=%s", got, want)
+			}
+			if got, want := gotSignature, webhook.Sign(secret, body); got != want {
+				t.Errorf("signature=%s, want=%s", got, want)
+			}
+
+			d := store.deliveries[0]
+			if got, want := d.Status, tt.expectedStatus; got != want {
+				t.Errorf("status=%v, want=%v", got, want)
+			}
+			if got, want := d.Attempts, tt.previousAttempts+1; got != want {
+				t.Errorf("attempts=%d, want=%d", got, want)
+			}
+			if got, want := d.LastResult, tt.expectedResult; got != want {
+				t.Errorf("last result=%s, want=%s", got, want)
+			}
+			if got, want := d.NextAttempt, mustParseTime(t, tt.expectedNextAttempt); !got.Equal(want) {
+				t.Errorf("next attempt=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	signature := webhook.Sign(screenjournal.WebhookSecret("dummy-secret"), []byte(`{"event":"review.created"}`))
+
+	// Calculated with:
+	//   printf '{"event":"review.created"}' | openssl dgst -sha256 -hmac 'dummy-secret'
+	if got, want := signature, "sha256=1cdf14215d362ec35f5a583de5b07444846c2bbddc3adb05863fb44a89bd3e2b"; got != want {
+		t.Errorf("signature=%s, want=%s", got, want)
+	}
+}
@@ -1,27 +1,47 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"maps"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	gorilla "github.com/mtlynch/gorilla-handlers"
 
+	"github.com/mtlynch/screenjournal/v2/announce/discord"
 	email_announce "github.com/mtlynch/screenjournal/v2/announce/email"
+	"github.com/mtlynch/screenjournal/v2/announce/inbox"
+	"github.com/mtlynch/screenjournal/v2/announce/multi"
 	"github.com/mtlynch/screenjournal/v2/announce/quiet"
+	"github.com/mtlynch/screenjournal/v2/announce/slack"
+	"github.com/mtlynch/screenjournal/v2/announce/webhook"
 	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/email/outbox"
 	"github.com/mtlynch/screenjournal/v2/email/smtp"
+	"github.com/mtlynch/screenjournal/v2/emailverification"
+	emailverification_email "github.com/mtlynch/screenjournal/v2/emailverification/email"
 	"github.com/mtlynch/screenjournal/v2/handlers"
 	"github.com/mtlynch/screenjournal/v2/handlers/sessions"
+	invites_email "github.com/mtlynch/screenjournal/v2/invites/email"
 	"github.com/mtlynch/screenjournal/v2/metadata/tmdb"
+	"github.com/mtlynch/screenjournal/v2/oidc"
+	"github.com/mtlynch/screenjournal/v2/passkey"
 	"github.com/mtlynch/screenjournal/v2/passwordreset"
 	passwordreset_email "github.com/mtlynch/screenjournal/v2/passwordreset/email"
+	"github.com/mtlynch/screenjournal/v2/ratelimit"
+	"github.com/mtlynch/screenjournal/v2/sso"
 	"github.com/mtlynch/screenjournal/v2/store/sqlite"
+	"github.com/mtlynch/screenjournal/v2/twofactor"
+	"github.com/mtlynch/screenjournal/v2/unsubscribe"
+	"github.com/mtlynch/screenjournal/v2/webauthn"
 )
 
 func main() {
@@ -43,8 +63,69 @@ func main() {
 	}
 	sessionManager := sessions.NewManager(store, useTls)
 
-	var announcer handlers.Announcer
+	unsubscribeKey, err := store.ReadSigningKey("unsubscribe")
+	if err != nil {
+		log.Fatalf("failed to read unsubscribe signing key: %v", err)
+	}
+	unsubscriber := unsubscribe.New(unsubscribeKey)
+
+	loginChallengeKey, err := store.ReadSigningKey("login-challenge")
+	if err != nil {
+		log.Fatalf("failed to read login challenge signing key: %v", err)
+	}
+	twoFactor := twofactor.New(store, loginChallengeKey, time.Now)
+
+	// Passkeys are bound to the server's hostname, so they're only available
+	// when we know it.
+	var passkeys handlers.PasskeyAuthenticator
+	if baseURL := os.Getenv("SJ_BASE_URL"); baseURL != "" {
+		relyingParty, err := webauthn.New(baseURL, "ScreenJournal")
+		if err != nil {
+			log.Fatalf("failed to configure passkeys: %v", err)
+		}
+		passkeys = passkey.New(store, relyingParty, time.Now)
+	} else {
+		log.Printf("SJ_BASE_URL not set. Passkeys are disabled")
+	}
+
+	var singleSignOn handlers.SingleSignOn
+	singleSignOnName := os.Getenv("SJ_OIDC_PROVIDER_NAME")
+	if issuerURL := os.Getenv("SJ_OIDC_ISSUER_URL"); issuerURL != "" {
+		provider := oidc.New(oidc.Config{
+			IssuerURL:    issuerURL,
+			ClientID:     requireEnv("SJ_OIDC_CLIENT_ID"),
+			ClientSecret: os.Getenv("SJ_OIDC_CLIENT_SECRET"),
+			RedirectURL:  strings.TrimSuffix(requireEnv("SJ_BASE_URL"), "/") + "/auth/oidc/callback",
+			GroupsClaim:  os.Getenv("SJ_OIDC_GROUPS_CLAIM"),
+		}, &http.Client{Timeout: 10 * time.Second}, time.Now)
+		singleSignOn = sso.New(store, provider, os.Getenv("SJ_OIDC_SIGNUP_GROUP"), time.Now)
+		if singleSignOnName == "" {
+			singleSignOnName = "SSO"
+		}
+		log.Printf("single sign-on is enabled with identity provider %s", issuerURL)
+	}
+
+	backends := map[string]multi.Backend{
+		"inbox": inbox.New(store),
+	}
+	// Webhook payloads link back to ScreenJournal, so receivers need absolute
+	// URLs.
+	if baseURL := os.Getenv("SJ_BASE_URL"); baseURL != "" {
+		backends["webhook"] = webhook.New(baseURL, store, time.Now)
+	} else {
+		log.Printf("SJ_BASE_URL not set. Webhook announcements are disabled")
+	}
+	go webhook.NewDeliverer(store, &http.Client{Timeout: 10 * time.Second}, time.Now).Run(context.Background(), 15*time.Second)
+	if webhookURL := os.Getenv("SJ_DISCORD_WEBHOOK_URL"); webhookURL != "" {
+		backends["discord"] = discord.New(requireEnv("SJ_BASE_URL"), webhookURL, &http.Client{Timeout: 10 * time.Second})
+	}
+	if webhookURL := os.Getenv("SJ_SLACK_WEBHOOK_URL"); webhookURL != "" {
+		backends["slack"] = slack.New(requireEnv("SJ_BASE_URL"), webhookURL, &http.Client{Timeout: 10 * time.Second})
+	}
 	var passwordResetter handlers.PasswordResetter
+	var emailVerifier handlers.EmailVerifier
+	var recapSender handlers.RecapSender
+	var inviteSender handlers.InviteSender
 	if isSmtpEnabled() {
 		smtpHost := requireEnv("SJ_SMTP_HOST")
 		smtpPort, err := strconv.Atoi(requireEnv("SJ_SMTP_PORT"))
@@ -57,13 +138,21 @@ func main() {
 			log.Fatalf("failed to create mail sender: %v", err)
 		}
 		baseURL := requireEnv("SJ_BASE_URL")
-		announcer = email_announce.New(baseURL, mailSender, store)
+		go outbox.NewWorker(store, mailSender, time.Now).Run(context.Background(), 30*time.Second)
+		outboxSender := outbox.New(store, time.Now)
+		emailAnnouncer := email_announce.New(baseURL, outboxSender, store, unsubscriber)
+		go email_announce.NewDigester(baseURL, outboxSender, store, unsubscriber, time.Now).Run(context.Background(), time.Hour)
+		backends["email"] = emailAnnouncer
+		recapSender = emailAnnouncer
+		inviteSender = invites_email.New(baseURL, outboxSender)
 		passwordResetter = passwordreset.New(store, passwordreset_email.New(baseURL, mailSender), time.Now)
+		emailVerifier = emailverification.New(store, emailverification_email.New(baseURL, mailSender), time.Now)
 	} else {
 		log.Printf("SMTP not configured. Transactional emails are disabled")
-		announcer = quiet.New()
 	}
 
+	announcer := newAnnouncer(backends)
+
 	tmdbBaseURL := os.Getenv("SJ_TMDB_API_BASE_URL")
 	if tmdbBaseURL == "" {
 		tmdbBaseURL = tmdb.DefaultBaseURL
@@ -80,6 +169,15 @@ func main() {
 		Store:            store,
 		MetadataFinder:   metadataFinder,
 		PasswordResetter: passwordResetter,
+		EmailVerifier:    emailVerifier,
+		RecapSender:      recapSender,
+		InviteSender:     inviteSender,
+		Unsubscriber:     unsubscriber,
+		TwoFactor:        twoFactor,
+		Passkeys:         passkeys,
+		LoginLimiter:     ratelimit.NewLoginLimiter(store, time.Now),
+		SingleSignOn:     singleSignOn,
+		SingleSignOnName: singleSignOnName,
 	}).Router())
 	if os.Getenv("SJ_BEHIND_PROXY") != "" {
 		h = gorilla.ProxyIPHeadersHandler(h)
@@ -102,6 +200,42 @@ func main() {
 	log.Fatal(server.ListenAndServe())
 }
 
+// newAnnouncer creates an announcer that dispatches to each backend listed in
+// the SJ_ANNOUNCERS environment variable. If SJ_ANNOUNCERS is unset, it uses
+// every available backend.
+func newAnnouncer(available map[string]multi.Backend) handlers.Announcer {
+	names := []string{}
+	if raw, ok := os.LookupEnv("SJ_ANNOUNCERS"); ok {
+		for name := range strings.SplitSeq(raw, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	} else {
+		names = slices.Sorted(maps.Keys(available))
+	}
+
+	if len(names) == 0 {
+		log.Printf("no announcers configured")
+		return quiet.New()
+	}
+
+	backends := []multi.NamedBackend{}
+	for _, name := range names {
+		b, ok := available[name]
+		if !ok {
+			log.Fatalf("announcer %q is unknown or not configured", name)
+		}
+		backends = append(backends, multi.NamedBackend{
+			Name:    name,
+			Backend: b,
+		})
+	}
+	log.Printf("announcing to: %s", strings.Join(names, ", "))
+
+	return multi.New(backends...)
+}
+
 func requireEnv(key string) string {
 	val := os.Getenv(key)
 	if val == "" {
@@ -0,0 +1,102 @@
+package compatibility
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+// maxRatingDifference is the largest possible gap between two ratings, as
+// ratings range from 1 to 10.
+const maxRatingDifference = 9
+
+// Compare calculates how well the ratings of two users agree. The reviews can
+// include reviews from other users, which Compare ignores.
+func Compare(username, otherUsername screenjournal.Username, reviews []screenjournal.Review) screenjournal.Compatibility {
+	c := screenjournal.Compatibility{
+		Username:      username,
+		OtherUsername: otherUsername,
+		SharedTitles:  []screenjournal.SharedRating{},
+	}
+
+	ratings := LatestRatingsByTitle(username, reviews)
+	otherRatings := LatestRatingsByTitle(otherUsername, reviews)
+	for key, review := range ratings {
+		otherReview, ok := otherRatings[key]
+		if !ok {
+			continue
+		}
+		c.SharedTitles = append(c.SharedTitles, screenjournal.SharedRating{
+			Review:      review,
+			OtherReview: otherReview,
+		})
+	}
+	if len(c.SharedTitles) == 0 {
+		return c
+	}
+
+	slices.SortFunc(c.SharedTitles, func(a, b screenjournal.SharedRating) int {
+		if d := b.Difference() - a.Difference(); d != 0 {
+			return d
+		}
+		// Break ties by title so that the order is deterministic.
+		return strings.Compare(a.Review.TitleKey(), b.Review.TitleKey())
+	})
+
+	totalDifference := 0
+	for _, sr := range c.SharedTitles {
+		totalDifference += sr.Difference()
+	}
+	c.MeanAbsoluteDifference = float64(totalDifference) / float64(len(c.SharedTitles))
+	c.AgreementPercent = int(math.Round(100 * (1 - c.MeanAbsoluteDifference/maxRatingDifference)))
+	c.Correlation = pearsonCorrelation(c.SharedTitles)
+
+	return c
+}
+
+// LatestRatingsByTitle returns the user's rated reviews, keyed by title. If the
+// user reviewed a title more than once, only the most recent watch counts.
+func LatestRatingsByTitle(username screenjournal.Username, reviews []screenjournal.Review) map[string]screenjournal.Review {
+	ratings := map[string]screenjournal.Review{}
+	for _, r := range reviews {
+		if !r.Owner.Equal(username) || r.Rating.IsNil() {
+			continue
+		}
+		key := r.TitleKey()
+		if existing, ok := ratings[key]; ok && !r.Watched.Time().After(existing.Watched.Time()) {
+			continue
+		}
+		ratings[key] = r
+	}
+	return ratings
+}
+
+func pearsonCorrelation(shared []screenjournal.SharedRating) *float64 {
+	if len(shared) < 2 {
+		return nil
+	}
+
+	var sumX, sumY float64
+	for _, sr := range shared {
+		sumX += float64(sr.Review.Rating.UInt8())
+		sumY += float64(sr.OtherReview.Rating.UInt8())
+	}
+	meanX := sumX / float64(len(shared))
+	meanY := sumY / float64(len(shared))
+
+	var covariance, varianceX, varianceY float64
+	for _, sr := range shared {
+		dx := float64(sr.Review.Rating.UInt8()) - meanX
+		dy := float64(sr.OtherReview.Rating.UInt8()) - meanY
+		covariance += dx * dy
+		varianceX += dx * dx
+		varianceY += dy * dy
+	}
+	if varianceX == 0 || varianceY == 0 {
+		return nil
+	}
+
+	return new(covariance / math.Sqrt(varianceX*varianceY))
+}
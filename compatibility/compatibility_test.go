@@ -0,0 +1,138 @@
+package compatibility_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/compatibility"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+func makeReview(owner string, movieID int64, rating uint8, watched string) screenjournal.Review {
+	w, err := time.Parse(time.DateOnly, watched)
+	if err != nil {
+		panic(err)
+	}
+	return screenjournal.Review{
+		Owner:   screenjournal.Username(owner),
+		Rating:  screenjournal.NewRating(rating),
+		Watched: screenjournal.WatchDate(w),
+		Movie: screenjournal.Movie{
+			ID: screenjournal.MovieID(movieID),
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	for _, tt := range []struct {
+		explanation         string
+		reviews             []screenjournal.Review
+		sharedTitles        int
+		agreementPercent    int
+		meanDifference      float64
+		correlation         *float64
+		largestDisagreement screenjournal.MovieID
+	}{
+		{
+			"no shared titles",
+			[]screenjournal.Review{
+				makeReview("alice", 1, 8, "2024-01-01"),
+				makeReview("bob", 2, 8, "2024-01-01"),
+			},
+			0,
+			0,
+			0,
+			nil,
+			screenjournal.MovieID(0),
+		},
+		{
+			"identical ratings",
+			[]screenjournal.Review{
+				makeReview("alice", 1, 8, "2024-01-01"),
+				makeReview("alice", 2, 4, "2024-01-01"),
+				makeReview("bob", 1, 8, "2024-01-01"),
+				makeReview("bob", 2, 4, "2024-01-01"),
+				makeReview("carol", 1, 1, "2024-01-01"),
+			},
+			2,
+			100,
+			0,
+			new(1.0),
+			screenjournal.MovieID(1),
+		},
+		{
+			"opposite ratings",
+			[]screenjournal.Review{
+				makeReview("alice", 1, 10, "2024-01-01"),
+				makeReview("alice", 2, 1, "2024-01-01"),
+				makeReview("bob", 1, 1, "2024-01-01"),
+				makeReview("bob", 2, 10, "2024-01-01"),
+			},
+			2,
+			0,
+			9,
+			new(-1.0),
+			screenjournal.MovieID(1),
+		},
+		{
+			"partial agreement uses the most recent rewatch",
+			[]screenjournal.Review{
+				makeReview("alice", 1, 2, "2020-01-01"),
+				makeReview("alice", 1, 9, "2024-01-01"),
+				makeReview("alice", 2, 5, "2024-01-01"),
+				makeReview("alice", 3, 3, "2024-01-01"),
+				makeReview("bob", 1, 8, "2024-01-01"),
+				makeReview("bob", 2, 2, "2024-01-01"),
+				makeReview("bob", 3, 3, "2024-01-01"),
+			},
+			3,
+			85,
+			4.0 / 3.0,
+			new(0.8824975032927698),
+			screenjournal.MovieID(2),
+		},
+		{
+			"correlation is undefined when one user's ratings never vary",
+			[]screenjournal.Review{
+				makeReview("alice", 1, 7, "2024-01-01"),
+				makeReview("alice", 2, 7, "2024-01-01"),
+				makeReview("bob", 1, 8, "2024-01-01"),
+				makeReview("bob", 2, 6, "2024-01-01"),
+			},
+			2,
+			89,
+			1,
+			nil,
+			screenjournal.MovieID(1),
+		},
+	} {
+		t.Run(tt.explanation, func(t *testing.T) {
+			c := compatibility.Compare(screenjournal.Username("alice"), screenjournal.Username("bob"), tt.reviews)
+
+			if got, want := len(c.SharedTitles), tt.sharedTitles; got != want {
+				t.Fatalf("sharedTitles=%d, want=%d", got, want)
+			}
+			if got, want := c.AgreementPercent, tt.agreementPercent; got != want {
+				t.Errorf("agreementPercent=%d, want=%d", got, want)
+			}
+			if got, want := c.MeanAbsoluteDifference, tt.meanDifference; math.Abs(got-want) > 1e-9 {
+				t.Errorf("meanAbsoluteDifference=%v, want=%v", got, want)
+			}
+			if (c.Correlation == nil) != (tt.correlation == nil) {
+				t.Fatalf("correlation=%v, want=%v", c.Correlation, tt.correlation)
+			}
+			if tt.correlation != nil {
+				if got, want := *c.Correlation, *tt.correlation; math.Abs(got-want) > 1e-9 {
+					t.Errorf("correlation=%v, want=%v", got, want)
+				}
+			}
+			if tt.sharedTitles == 0 {
+				return
+			}
+			if got, want := c.SharedTitles[0].Review.Movie.ID, tt.largestDisagreement; got != want {
+				t.Errorf("largest disagreement=%v, want=%v", got, want)
+			}
+		})
+	}
+}
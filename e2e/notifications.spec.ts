@@ -11,7 +11,7 @@ test("notifications page reflects the backend store for new reviews", async ({
   page,
 }) => {
   await page.getByRole("menuitem", { name: "Account" }).click();
-  await page.getByRole("menuitem", { name: "Notifications" }).click();
+  await page.getByRole("menuitem", { name: "Notification settings" }).click();
 
   await expect(page).toHaveURL("/account/notifications");
 
@@ -52,43 +52,43 @@ test("notifications page reflects the backend store for new comments", async ({
   page,
 }) => {
   await page.getByRole("menuitem", { name: "Account" }).click();
-  await page.getByRole("menuitem", { name: "Notifications" }).click();
+  await page.getByRole("menuitem", { name: "Notification settings" }).click();
 
   await expect(page).toHaveURL("/account/notifications");
 
   await expect(
-    page.getByLabel("Email me when someone replies to me in a review comment")
+    page.getByLabel("Email me about new comments on reviews I'm part of")
   ).toBeChecked();
 
   // Turn off new comment notifications.
   await page
-    .getByLabel("Email me when someone replies to me in a review comment")
+    .getByLabel("Email me about new comments on reviews I'm part of")
     .click();
   await page.locator("form .btn-primary").click();
 
   await expect(
-    page.getByLabel("Email me when someone replies to me in a review comment")
+    page.getByLabel("Email me about new comments on reviews I'm part of")
   ).not.toBeChecked();
 
   await page.reload();
 
   await expect(
-    page.getByLabel("Email me when someone replies to me in a review comment")
+    page.getByLabel("Email me about new comments on reviews I'm part of")
   ).not.toBeChecked();
 
   // Turn on new comment notifications.
   await page
-    .getByLabel("Email me when someone replies to me in a review comment")
+    .getByLabel("Email me about new comments on reviews I'm part of")
     .click();
   await page.locator("form .btn-primary").click();
 
   await expect(
-    page.getByLabel("Email me when someone replies to me in a review comment")
+    page.getByLabel("Email me about new comments on reviews I'm part of")
   ).toBeChecked();
 
   await page.reload();
 
   await expect(
-    page.getByLabel("Email me when someone replies to me in a review comment")
+    page.getByLabel("Email me about new comments on reviews I'm part of")
   ).toBeChecked();
 });
@@ -0,0 +1,159 @@
+// Package outbox saves outgoing emails to the database and sends them in the
+// background, so that a mail server outage delays notifications instead of
+// losing them.
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/email"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+const (
+	// maxAttempts is the number of times to try sending an email before
+	// moving it to the dead-letter queue.
+	maxAttempts = 8
+
+	// initialBackoff is how long to wait before the first retry. Each later
+	// retry waits twice as long as the previous one.
+	initialBackoff = time.Minute
+)
+
+type (
+	Store interface {
+		InsertOutboxEmail(screenjournal.OutboxEmail) error
+		ReadDueOutboxEmails(time.Time) ([]screenjournal.OutboxEmail, error)
+		UpdateOutboxEmail(screenjournal.OutboxEmail) error
+	}
+
+	// Sender implements email.Sender by queueing messages in the outbox.
+	Sender struct {
+		store Store
+		clock func() time.Time
+	}
+
+	// Worker sends queued emails and retries the ones that fail.
+	Worker struct {
+		store  Store
+		sender email.Sender
+		clock  func() time.Time
+	}
+)
+
+func New(store Store, clock func() time.Time) Sender {
+	return Sender{
+		store: store,
+		clock: clock,
+	}
+}
+
+// Send queues the message. If the outbox already has a message with the same
+// idempotency key and recipients, Send does nothing, so callers can safely
+// repeat a send after a restart.
+func (s Sender) Send(msg email.Message) error {
+	k, err := key(msg)
+	if err != nil {
+		return err
+	}
+	return s.store.InsertOutboxEmail(screenjournal.OutboxEmail{
+		Key:         k,
+		From:        msg.From,
+		To:          msg.To,
+		Subject:     msg.Subject,
+		TextBody:    msg.TextBody,
+		HtmlBody:    msg.HtmlBody,
+		Headers:     msg.Headers,
+		NextAttempt: s.clock(),
+	})
+}
+
+func NewWorker(store Store, sender email.Sender, clock func() time.Time) Worker {
+	return Worker{
+		store:  store,
+		sender: sender,
+		clock:  clock,
+	}
+}
+
+// Run sends due emails every interval until ctx is cancelled.
+func (w Worker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		w.SendDue()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue attempts every pending email whose next attempt time has arrived.
+func (w Worker) SendDue() {
+	emails, err := w.store.ReadDueOutboxEmails(w.clock())
+	if err != nil {
+		log.Printf("failed to read due outbox emails: %v", err)
+		return
+	}
+
+	for _, e := range emails {
+		e.Attempts++
+		if err := w.sender.Send(email.Message{
+			From:     e.From,
+			To:       e.To,
+			Subject:  e.Subject,
+			Date:     e.Created,
+			TextBody: e.TextBody,
+			HtmlBody: e.HtmlBody,
+			Headers:  e.Headers,
+		}); err == nil {
+			e.Status = screenjournal.OutboxEmailSent
+			e.LastError = ""
+		} else if e.Attempts >= maxAttempts {
+			log.Printf("giving up on email %v [%s] after %d attempts: %v", e.ID, e.Subject, e.Attempts, err)
+			e.Status = screenjournal.OutboxEmailFailed
+			e.LastError = err.Error()
+		} else {
+			e.NextAttempt = w.clock().Add(backoff(e.Attempts))
+			e.LastError = err.Error()
+			log.Printf("failed to send email %v [%s] (attempt %d), retrying at %v: %v", e.ID, e.Subject, e.Attempts, e.NextAttempt, err)
+		}
+
+		if err := w.store.UpdateOutboxEmail(e); err != nil {
+			log.Printf("failed to save outbox email %v: %v", e.ID, err)
+		}
+	}
+}
+
+// key identifies a message by the event it's about and its recipients. A
+// message without an idempotency key gets a random key so that it never
+// matches an earlier message, even one with identical content.
+func key(msg email.Message) (screenjournal.OutboxEmailKey, error) {
+	if msg.IdempotencyKey == "" {
+		b := make([]byte, sha256.Size)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		return screenjournal.OutboxEmailKey(hex.EncodeToString(b)), nil
+	}
+
+	h := sha256.New()
+	h.Write([]byte(msg.IdempotencyKey))
+	h.Write([]byte{0})
+	for _, to := range msg.To {
+		h.Write([]byte(to.Address))
+		h.Write([]byte{0})
+	}
+	return screenjournal.OutboxEmailKey(hex.EncodeToString(h.Sum(nil))), nil
+}
+
+func backoff(attempts int) time.Duration {
+	return initialBackoff * time.Duration(1<<(attempts-1))
+}
@@ -0,0 +1,212 @@
+package outbox_test
+
+import (
+	"errors"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/email"
+	"github.com/mtlynch/screenjournal/v2/email/outbox"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type mockStore struct {
+	emails []screenjournal.OutboxEmail
+}
+
+func (s *mockStore) InsertOutboxEmail(e screenjournal.OutboxEmail) error {
+	for _, existing := range s.emails {
+		if existing.Key == e.Key {
+			return nil
+		}
+	}
+	e.ID = screenjournal.OutboxEmailID(len(s.emails) + 1)
+	e.Status = screenjournal.OutboxEmailPending
+	s.emails = append(s.emails, e)
+	return nil
+}
+
+func (s *mockStore) ReadDueOutboxEmails(now time.Time) ([]screenjournal.OutboxEmail, error) {
+	due := []screenjournal.OutboxEmail{}
+	for _, e := range s.emails {
+		if e.Status == screenjournal.OutboxEmailPending && !e.NextAttempt.After(now) {
+			due = append(due, e)
+		}
+	}
+	return due, nil
+}
+
+func (s *mockStore) UpdateOutboxEmail(e screenjournal.OutboxEmail) error {
+	s.emails[e.ID-1] = e
+	return nil
+}
+
+type mockSender struct {
+	err  error
+	sent []email.Message
+}
+
+func (s *mockSender) Send(msg email.Message) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func mustParseTime(t *testing.T, s string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf("failed to parse time %s: %v", s, err)
+	}
+	return parsed
+}
+
+func makeMessage(subject string) email.Message {
+	return email.Message{
+		From: mail.Address{Name: "ScreenJournal", Address: "activity@thescreenjournal.com"},
+		To: []mail.Address{
+			{Name: "userA", Address: "userA@example.com"},
+		},
+		Subject:  subject,
+		TextBody: "Hi userA,",
+		HtmlBody: "<p>Hi userA,</p>",
+	}
+}
+
+func makeEventMessage(subject, idempotencyKey string) email.Message {
+	msg := makeMessage(subject)
+	msg.IdempotencyKey = idempotencyKey
+	return msg
+}
+
+func TestSendQueuesEachMessageOnce(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		messages    []email.Message
+		queued      int
+	}{
+		{
+			description: "drops a repeat send for the same event",
+			messages: []email.Message{
+				makeEventMessage("first", "comment/1"),
+				makeEventMessage("second", "comment/2"),
+				makeEventMessage("first", "comment/1"),
+			},
+			queued: 2,
+		},
+		{
+			description: "queues identical content for two different events",
+			messages: []email.Message{
+				makeEventMessage("userB commented on your review", "comment/1"),
+				makeEventMessage("userB commented on your review", "comment/2"),
+			},
+			queued: 2,
+		},
+		{
+			description: "queues identical content when there's no idempotency key",
+			messages: []email.Message{
+				makeMessage("Your 2024 on ScreenJournal"),
+				makeMessage("Your 2024 on ScreenJournal"),
+			},
+			queued: 2,
+		},
+		{
+			description: "queues the same event for different recipients",
+			messages: []email.Message{
+				makeEventMessage("new review", "review/1"),
+				func() email.Message {
+					msg := makeEventMessage("new review", "review/1")
+					msg.To = []mail.Address{{Name: "userB", Address: "userB@example.com"}}
+					return msg
+				}(),
+			},
+			queued: 2,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			store := mockStore{}
+			now := mustParseTime(t, "2025-03-01T12:00:00Z")
+			sender := outbox.New(&store, func() time.Time { return now })
+
+			for _, msg := range tt.messages {
+				if err := sender.Send(msg); err != nil {
+					t.Fatalf("failed to queue message: %v", err)
+				}
+			}
+
+			if got, want := len(store.emails), tt.queued; got != want {
+				t.Fatalf("queued emails=%d, want=%d", got, want)
+			}
+			if got, want := store.emails[0].NextAttempt, now; got != want {
+				t.Errorf("next attempt=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestWorkerRetriesWithBackoffThenGivesUp(t *testing.T) {
+	store := mockStore{}
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+	clock := func() time.Time { return now }
+	if err := outbox.New(&store, clock).Send(makeMessage("hello")); err != nil {
+		t.Fatalf("failed to queue message: %v", err)
+	}
+
+	smtp := mockSender{err: errors.New("connection refused")}
+	worker := outbox.NewWorker(&store, &smtp, clock)
+
+	worker.SendDue()
+	if got, want := store.emails[0].Status, screenjournal.OutboxEmailPending; got != want {
+		t.Fatalf("status=%v, want=%v", got, want)
+	}
+	if got, want := store.emails[0].NextAttempt, now.Add(time.Minute); got != want {
+		t.Errorf("next attempt=%v, want=%v", got, want)
+	}
+	if got, want := store.emails[0].LastError, "connection refused"; got != want {
+		t.Errorf("last error=%v, want=%v", got, want)
+	}
+
+	// Nothing is due until the backoff passes.
+	worker.SendDue()
+	if got, want := store.emails[0].Attempts, 1; got != want {
+		t.Fatalf("attempts=%d, want=%d", got, want)
+	}
+
+	for store.emails[0].Status == screenjournal.OutboxEmailPending {
+		now = store.emails[0].NextAttempt
+		worker.SendDue()
+	}
+	if got, want := store.emails[0].Status, screenjournal.OutboxEmailFailed; got != want {
+		t.Errorf("status=%v, want=%v", got, want)
+	}
+	if got, want := store.emails[0].Attempts, 8; got != want {
+		t.Errorf("attempts=%d, want=%d", got, want)
+	}
+}
+
+func TestWorkerSendsDueEmails(t *testing.T) {
+	store := mockStore{}
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+	clock := func() time.Time { return now }
+	if err := outbox.New(&store, clock).Send(makeMessage("hello")); err != nil {
+		t.Fatalf("failed to queue message: %v", err)
+	}
+
+	smtp := mockSender{}
+	worker := outbox.NewWorker(&store, &smtp, clock)
+	worker.SendDue()
+	// A second pass shouldn't resend an email that was already sent.
+	worker.SendDue()
+
+	if got, want := len(smtp.sent), 1; got != want {
+		t.Fatalf("sent=%d, want=%d", got, want)
+	}
+	if got, want := smtp.sent[0].Subject, "hello"; got != want {
+		t.Errorf("subject=%v, want=%v", got, want)
+	}
+	if got, want := store.emails[0].Status, screenjournal.OutboxEmailSent; got != want {
+		t.Errorf("status=%v, want=%v", got, want)
+	}
+}
@@ -12,6 +12,13 @@ type Message struct {
 	Date     time.Time
 	TextBody string
 	HtmlBody string
+	// Headers are extra headers to include in the message, such as
+	// List-Unsubscribe.
+	Headers mail.Header
+	// IdempotencyKey identifies the event that the message is about, such as
+	// "comment/12", so that a queue can drop repeat sends of the same message
+	// to the same recipient. If it's empty, every send is a new message.
+	IdempotencyKey string
 }
 
 type Sender interface {
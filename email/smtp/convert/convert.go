@@ -1,10 +1,13 @@
 package convert
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"mime/multipart"
 	"mime/quotedprintable"
 	"net/textproto"
+	"slices"
 	"strings"
 
 	"github.com/mtlynch/screenjournal/v2/email"
@@ -15,6 +18,8 @@ type header struct {
 	Value string
 }
 
+var ErrInvalidHeader = errors.New("header name or value contains a line break")
+
 // Boundary to use in generating multipart messages. Really only useful in
 // testing.
 var MultipartBoundary = ""
@@ -34,9 +39,18 @@ func FromEmail(msg email.Message) (string, error) {
 	headers = append(headers, makeHeader("From", msg.From.String()))
 	headers = append(headers, makeHeader("To", msg.To[0].String()))
 	headers = append(headers, makeHeader("Subject", msg.Subject))
+	for _, name := range slices.Sorted(maps.Keys(msg.Headers)) {
+		for _, value := range msg.Headers[name] {
+			headers = append(headers, makeHeader(name, value))
+		}
+	}
 	headers = append(headers, makeHeader("MIME-Version", "1.0"))
 	headers = append(headers, makeHeader("Content-Type", fmt.Sprintf("multipart/alternative; boundary=\"%s\"", mpw.Boundary())))
 	for _, hdr := range headers {
+		// A line break would let the value inject its own headers.
+		if strings.ContainsAny(hdr.Name, "\r\n") || strings.ContainsAny(hdr.Value, "\r\n") {
+			return "", ErrInvalidHeader
+		}
 		sb.WriteString(fmt.Sprintf("%s: %s\r\n", hdr.Name, hdr.Value))
 	}
 
@@ -76,6 +76,46 @@ com/movies/1#review25</a></p>
 
 <p>-ScreenJournal Bot</p>
 --dummy-boundary-for-testing--
+`),
+		},
+		{
+			input: email.Message{
+				From: mail.Address{
+					Name:    "ScreenJournal Bot",
+					Address: "bot@sj.example.com",
+				},
+				To: []mail.Address{
+					{
+						Name:    "Alice User",
+						Address: "alice@user.example.com",
+					},
+				},
+				Subject:  "Frank posted a review of The Room",
+				TextBody: "Hi Alice",
+				HtmlBody: "<p>Hi Alice</p>",
+				Headers: mail.Header{
+					"List-Unsubscribe-Post": {"List-Unsubscribe=One-Click"},
+					"List-Unsubscribe":      {"<https://sj.example.com/unsubscribe/abc123>"},
+				},
+			},
+			expected: normalizeLineEndings(`From: "ScreenJournal Bot" <bot@sj.example.com>
+To: "Alice User" <alice@user.example.com>
+Subject: Frank posted a review of The Room
+List-Unsubscribe: <https://sj.example.com/unsubscribe/abc123>
+List-Unsubscribe-Post: List-Unsubscribe=One-Click
+MIME-Version: 1.0
+Content-Type: multipart/alternative; boundary="dummy-boundary-for-testing"
+--dummy-boundary-for-testing
+Content-Transfer-Encoding: quoted-printable
+Content-Type: text/plain; charset="UTF-8"
+
+Hi Alice
+--dummy-boundary-for-testing
+Content-Transfer-Encoding: quoted-printable
+Content-Type: text/html; charset="UTF-8"
+
+<p>Hi Alice</p>
+--dummy-boundary-for-testing--
 `),
 		},
 	}
@@ -95,3 +135,16 @@ com/movies/1#review25</a></p>
 func normalizeLineEndings(s string) string {
 	return strings.ReplaceAll(s, "\n", "\r\n")
 }
+
+func TestFromEmailRejectsHeaderInjection(t *testing.T) {
+	_, err := convert.FromEmail(email.Message{
+		From: mail.Address{Address: "bot@sj.example.com"},
+		To:   []mail.Address{{Address: "alice@user.example.com"}},
+		Headers: mail.Header{
+			"List-Unsubscribe": {"<https://sj.example.com/>\r\nBcc: eve@example.com"},
+		},
+	})
+	if got, want := err, convert.ErrInvalidHeader; got != want {
+		t.Errorf("err=%v, want=%v", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"log"
+	"net/mail"
+	"text/template"
+
+	"github.com/mtlynch/screenjournal/v2/email"
+	"github.com/mtlynch/screenjournal/v2/markdown"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type Sender struct {
+	baseURL string
+	sender  email.Sender
+}
+
+func New(baseURL string, sender email.Sender) Sender {
+	return Sender{
+		baseURL: baseURL,
+		sender:  sender,
+	}
+}
+
+//go:embed templates
+var templatesFS embed.FS
+
+var (
+	verifyEmailTemplate = template.Must(
+		template.New("verify-email.tmpl.txt").
+			ParseFS(templatesFS, "templates/verify-email.tmpl.txt"))
+	emailChangedTemplate = template.Must(
+		template.New("email-changed.tmpl.txt").
+			ParseFS(templatesFS, "templates/email-changed.tmpl.txt"))
+)
+
+// SendVerification emails a verification link to the address in v, which is
+// either the user's current address or the one they want to change to.
+func (s Sender) SendVerification(user screenjournal.User, v screenjournal.EmailVerification) error {
+	verifyURL := fmt.Sprintf("%s/account/verify-email?token=%s", s.baseURL, v.Token)
+	changing := user.Email != v.Email
+
+	var bodyBuf bytes.Buffer
+	if err := verifyEmailTemplate.Execute(&bodyBuf, struct {
+		Username  string
+		Changing  bool
+		VerifyURL string
+	}{
+		Username:  user.Username.String(),
+		Changing:  changing,
+		VerifyURL: verifyURL,
+	}); err != nil {
+		return fmt.Errorf("rendering verification email: %w", err)
+	}
+
+	subject := "Verify your ScreenJournal email address"
+	if changing {
+		subject = "Confirm your new ScreenJournal email address"
+	}
+
+	bodyMarkdown := screenjournal.EmailBodyMarkdown(bodyBuf.String())
+	msg := email.Message{
+		From: mail.Address{
+			Name:    "ScreenJournal",
+			Address: "accounts@thescreenjournal.com",
+		},
+		To: []mail.Address{
+			{
+				Name:    user.Username.String(),
+				Address: v.Email.String(),
+			},
+		},
+		Subject:  subject,
+		TextBody: bodyMarkdown.String(),
+		HtmlBody: markdown.RenderEmail(bodyMarkdown),
+	}
+
+	if err := s.sender.Send(msg); err != nil {
+		return fmt.Errorf("sending verification email for user %s: %w", user.Username, err)
+	}
+
+	log.Printf("sent verification email for user %s", user.Username)
+	return nil
+}
+
+// SendEmailChanged lets the user know at their old address that their email
+// address changed.
+func (s Sender) SendEmailChanged(user screenjournal.User, newEmail screenjournal.Email) error {
+	var bodyBuf bytes.Buffer
+	if err := emailChangedTemplate.Execute(&bodyBuf, struct {
+		Username string
+		NewEmail string
+	}{
+		Username: user.Username.String(),
+		NewEmail: newEmail.String(),
+	}); err != nil {
+		return fmt.Errorf("rendering email changed notice: %w", err)
+	}
+
+	bodyMarkdown := screenjournal.EmailBodyMarkdown(bodyBuf.String())
+	msg := email.Message{
+		From: mail.Address{
+			Name:    "ScreenJournal",
+			Address: "accounts@thescreenjournal.com",
+		},
+		To: []mail.Address{
+			{
+				Name:    user.Username.String(),
+				Address: user.Email.String(),
+			},
+		},
+		Subject:  "Your ScreenJournal email address changed",
+		TextBody: bodyMarkdown.String(),
+		HtmlBody: markdown.RenderEmail(bodyMarkdown),
+	}
+
+	if err := s.sender.Send(msg); err != nil {
+		return fmt.Errorf("sending email changed notice for user %s: %w", user.Username, err)
+	}
+
+	log.Printf("sent email changed notice for user %s", user.Username)
+	return nil
+}
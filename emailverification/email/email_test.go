@@ -0,0 +1,140 @@
+package email_test
+
+import (
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/diff"
+
+	"github.com/mtlynch/screenjournal/v2/email"
+	emailverification_email "github.com/mtlynch/screenjournal/v2/emailverification/email"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type mockEmailSender struct {
+	emailsSent []email.Message
+}
+
+func (s *mockEmailSender) Send(msg email.Message) error {
+	s.emailsSent = append(s.emailsSent, msg)
+	return nil
+}
+
+var dummyToken = screenjournal.NewPasswordResetTokenFromString("abc123tokenXYZ")
+
+func TestSendVerification(t *testing.T) {
+	user := screenjournal.User{
+		Username: screenjournal.Username("alice"),
+		Email:    screenjournal.Email("alice@example.com"),
+	}
+
+	for _, tt := range []struct {
+		description string
+		email       screenjournal.Email
+		subject     string
+		to          string
+		body        string
+	}{
+		{
+			description: "verifies the user's current address",
+			email:       "alice@example.com",
+			subject:     "Verify your ScreenJournal email address",
+			to:          "alice@example.com",
+			body: `Hi alice,
+
+Welcome to ScreenJournal! Before we can send you notifications, we need to make sure this is your email address.
+
+Click the link below to verify it:
+
+https://dev.thescreenjournal.com/account/verify-email?token=abc123tokenXYZ
+
+This link will self-destruct in 24 hours.
+
+If you didn't request this, you can ignore this email.
+
+-ScreenJournal Bot
+`,
+		},
+		{
+			description: "confirms a new address",
+			email:       "alice@example.org",
+			subject:     "Confirm your new ScreenJournal email address",
+			to:          "alice@example.org",
+			body: `Hi alice,
+
+Someone (hopefully you) asked to change the email address for your ScreenJournal account to this one.
+
+Click the link below to confirm the change:
+
+https://dev.thescreenjournal.com/account/verify-email?token=abc123tokenXYZ
+
+This link will self-destruct in 24 hours.
+
+If you didn't request this, you can ignore this email.
+
+-ScreenJournal Bot
+`,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			sender := &mockEmailSender{}
+			s := emailverification_email.New("https://dev.thescreenjournal.com", sender)
+
+			if err := s.SendVerification(user, screenjournal.EmailVerification{
+				Username:  user.Username,
+				Email:     tt.email,
+				Token:     dummyToken,
+				ExpiresAt: time.Date(2024, 1, 16, 12, 0, 0, 0, time.UTC),
+			}); err != nil {
+				t.Fatalf("err=%v, want=%v", err, nil)
+			}
+
+			if got, want := len(sender.emailsSent), 1; got != want {
+				t.Fatalf("email count=%d, want=%d", got, want)
+			}
+			msg := sender.emailsSent[0]
+			if got, want := msg.Subject, tt.subject; got != want {
+				t.Errorf("subject=%s, want=%s", got, want)
+			}
+			if got, want := msg.To, []mail.Address{{Name: "alice", Address: tt.to}}; len(got) != 1 || got[0] != want[0] {
+				t.Errorf("to=%v, want=%v", got, want)
+			}
+			if d := diff.Diff(tt.body, msg.TextBody); d != "" {
+				t.Errorf("email (plaintext): %s", d)
+			}
+		})
+	}
+}
+
+func TestSendEmailChanged(t *testing.T) {
+	sender := &mockEmailSender{}
+	s := emailverification_email.New("https://dev.thescreenjournal.com", sender)
+
+	user := screenjournal.User{
+		Username: screenjournal.Username("alice"),
+		Email:    screenjournal.Email("alice@example.com"),
+	}
+	if err := s.SendEmailChanged(user, screenjournal.Email("alice@example.org")); err != nil {
+		t.Fatalf("err=%v, want=%v", err, nil)
+	}
+
+	if got, want := len(sender.emailsSent), 1; got != want {
+		t.Fatalf("email count=%d, want=%d", got, want)
+	}
+	msg := sender.emailsSent[0]
+	if got, want := msg.To, []mail.Address{{Name: "alice", Address: "alice@example.com"}}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("to=%v, want=%v", got, want)
+	}
+	wantBody := `Hi alice,
+
+The email address for your ScreenJournal account just changed to alice@example.org. We won't send any more email to this address.
+
+If you didn't make this change, reply and let me know.
+
+-ScreenJournal Bot
+`
+	if d := diff.Diff(wantBody, msg.TextBody); d != "" {
+		t.Errorf("email (plaintext): %s", d)
+	}
+}
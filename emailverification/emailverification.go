@@ -0,0 +1,154 @@
+// Package emailverification confirms that users receive mail at their email
+// addresses, including new addresses they want to switch to.
+package emailverification
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/ratelimit"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+const verificationExpiry = 24 * time.Hour
+
+var (
+	ErrTooManyVerificationEmails = errors.New("too many verification emails requested, please try again later")
+	ErrAlreadyVerified           = errors.New("email address is already verified")
+	ErrSameEmail                 = errors.New("new email address is the same as your current one")
+	ErrEmailUnavailable          = errors.New("email address is associated with another account")
+	ErrInvalidToken              = errors.New("invalid or already used verification link")
+	ErrExpiredToken              = errors.New("verification link has expired")
+)
+
+type (
+	Store interface {
+		ReadUser(screenjournal.Username) (screenjournal.User, error)
+		ReadUserByEmail(screenjournal.Email) (screenjournal.User, error)
+		InsertEmailVerification(screenjournal.EmailVerification) error
+		UseEmailVerification(screenjournal.PasswordResetToken, time.Time) (screenjournal.EmailVerification, error)
+		UpdateUserEmail(screenjournal.Username, screenjournal.Email) error
+	}
+
+	emailSender interface {
+		SendVerification(screenjournal.User, screenjournal.EmailVerification) error
+		SendEmailChanged(screenjournal.User, screenjournal.Email) error
+	}
+
+	Verifier struct {
+		store       Store
+		emailSender emailSender
+		limiter     *ratelimit.EmailVerificationLimiter
+		now         func() time.Time
+	}
+)
+
+func New(store Store, sender emailSender, now func() time.Time) Verifier {
+	if now == nil {
+		panic("email verifier requires a clock")
+	}
+	return Verifier{
+		store:       store,
+		emailSender: sender,
+		limiter:     ratelimit.NewEmailVerificationLimiter(now),
+		now:         now,
+	}
+}
+
+// SendVerification emails the user a link to verify their current email
+// address.
+func (v Verifier) SendVerification(username screenjournal.Username) error {
+	user, err := v.store.ReadUser(username)
+	if err != nil {
+		return fmt.Errorf("read user %s: %w", username, err)
+	}
+	if user.EmailVerified {
+		return ErrAlreadyVerified
+	}
+
+	return v.send(user, user.Email)
+}
+
+// RequestChange emails a link to the user's new email address. The user's
+// address doesn't change until they follow the link.
+func (v Verifier) RequestChange(username screenjournal.Username, newEmail screenjournal.Email) error {
+	user, err := v.store.ReadUser(username)
+	if err != nil {
+		return fmt.Errorf("read user %s: %w", username, err)
+	}
+	if user.Email == newEmail {
+		return ErrSameEmail
+	}
+
+	if _, err := v.store.ReadUserByEmail(newEmail); err == nil {
+		return ErrEmailUnavailable
+	} else if !errors.Is(err, store.ErrUserNotFound) {
+		return fmt.Errorf("look up user by email: %w", err)
+	}
+
+	return v.send(user, newEmail)
+}
+
+func (v Verifier) send(user screenjournal.User, emailAddr screenjournal.Email) error {
+	if !v.limiter.HasAttemptsRemaining(user.Username) {
+		log.Printf("email verification rate limited for user %s", user.Username)
+		return ErrTooManyVerificationEmails
+	}
+
+	verification := screenjournal.EmailVerification{
+		Username:  user.Username,
+		Email:     emailAddr,
+		Token:     screenjournal.NewPasswordResetToken(),
+		ExpiresAt: v.now().Add(verificationExpiry),
+	}
+	if err := v.store.InsertEmailVerification(verification); err != nil {
+		return fmt.Errorf("insert email verification: %w", err)
+	}
+
+	if err := v.emailSender.SendVerification(user, verification); err != nil {
+		return fmt.Errorf("send verification email for user %s: %w", user.Username, err)
+	}
+
+	v.limiter.RecordAttempt(user.Username)
+	return nil
+}
+
+// Confirm verifies the email address that the token was sent to. If it's a new
+// address, it replaces the user's old one, and we let the old address know
+// about the change.
+func (v Verifier) Confirm(token screenjournal.PasswordResetToken) (screenjournal.Username, error) {
+	verification, err := v.store.UseEmailVerification(token, v.now())
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidEmailVerificationToken):
+			return screenjournal.Username(""), ErrInvalidToken
+		case errors.Is(err, store.ErrExpiredEmailVerificationToken):
+			return screenjournal.Username(""), ErrExpiredToken
+		}
+		return screenjournal.Username(""), fmt.Errorf("use email verification: %w", err)
+	}
+
+	user, err := v.store.ReadUser(verification.Username)
+	if err != nil {
+		return screenjournal.Username(""), fmt.Errorf("read user %s: %w", verification.Username, err)
+	}
+
+	if err := v.store.UpdateUserEmail(user.Username, verification.Email); err != nil {
+		if errors.Is(err, store.ErrEmailAssociatedWithAnotherAccount) {
+			return screenjournal.Username(""), ErrEmailUnavailable
+		}
+		return screenjournal.Username(""), fmt.Errorf("update email for user %s: %w", user.Username, err)
+	}
+
+	if user.Email != verification.Email {
+		log.Printf("changed email address for user %s", user.Username)
+		if err := v.emailSender.SendEmailChanged(user, verification.Email); err != nil {
+			log.Printf("failed to notify old email address of user %s about change: %v", user.Username, err)
+		}
+	}
+
+	return user.Username, nil
+}
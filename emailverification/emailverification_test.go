@@ -0,0 +1,165 @@
+package emailverification_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/emailverification"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/sqlite"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+type mockEmailSender struct {
+	verifications []screenjournal.EmailVerification
+	changeNotices []screenjournal.Email
+}
+
+func (s *mockEmailSender) SendVerification(user screenjournal.User, v screenjournal.EmailVerification) error {
+	s.verifications = append(s.verifications, v)
+	return nil
+}
+
+func (s *mockEmailSender) SendEmailChanged(user screenjournal.User, newEmail screenjournal.Email) error {
+	s.changeNotices = append(s.changeNotices, user.Email)
+	return nil
+}
+
+func TestSendVerificationAndConfirm(t *testing.T) {
+	dataStore := test_sqlite.New()
+	insertUsers(t, dataStore, screenjournal.User{Username: "alice", Email: "alice@example.com"})
+	sender := &mockEmailSender{}
+	v := emailverification.New(dataStore, sender, time.Now)
+
+	if err := v.SendVerification("alice"); err != nil {
+		t.Fatalf("SendVerification err=%v, want=%v", err, nil)
+	}
+	if got, want := len(sender.verifications), 1; got != want {
+		t.Fatalf("verifications sent=%d, want=%d", got, want)
+	}
+	if got, want := sender.verifications[0].Email, screenjournal.Email("alice@example.com"); got != want {
+		t.Errorf("verification sent to=%v, want=%v", got, want)
+	}
+
+	username, err := v.Confirm(sender.verifications[0].Token)
+	if err != nil {
+		t.Fatalf("Confirm err=%v, want=%v", err, nil)
+	}
+	if got, want := username, screenjournal.Username("alice"); !got.Equal(want) {
+		t.Errorf("username=%v, want=%v", got, want)
+	}
+
+	user, err := dataStore.ReadUser("alice")
+	if err != nil {
+		t.Fatalf("failed to read user: %v", err)
+	}
+	if !user.EmailVerified {
+		t.Errorf("email is still unverified after confirming")
+	}
+	if got, want := len(sender.changeNotices), 0; got != want {
+		t.Errorf("change notices sent=%d, want=%d", got, want)
+	}
+
+	if _, err := v.Confirm(sender.verifications[0].Token); !errors.Is(err, emailverification.ErrInvalidToken) {
+		t.Errorf("reused token err=%v, want=%v", err, emailverification.ErrInvalidToken)
+	}
+	if err := v.SendVerification("alice"); !errors.Is(err, emailverification.ErrAlreadyVerified) {
+		t.Errorf("second SendVerification err=%v, want=%v", err, emailverification.ErrAlreadyVerified)
+	}
+}
+
+func TestRequestChange(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		newEmail    screenjournal.Email
+		errOut      error
+	}{
+		{
+			description: "sends a confirmation to the new address",
+			newEmail:    "alice@example.org",
+		},
+		{
+			description: "rejects the current address",
+			newEmail:    "alice@example.com",
+			errOut:      emailverification.ErrSameEmail,
+		},
+		{
+			description: "rejects another user's address",
+			newEmail:    "bob@example.com",
+			errOut:      emailverification.ErrEmailUnavailable,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			insertUsers(t, dataStore,
+				screenjournal.User{Username: "alice", Email: "alice@example.com", EmailVerified: true},
+				screenjournal.User{Username: "bob", Email: "bob@example.com", EmailVerified: true})
+			sender := &mockEmailSender{}
+			v := emailverification.New(dataStore, sender, time.Now)
+
+			err := v.RequestChange("alice", tt.newEmail)
+			if got, want := err, tt.errOut; !errors.Is(got, want) {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if tt.errOut != nil {
+				if got, want := len(sender.verifications), 0; got != want {
+					t.Errorf("verifications sent=%d, want=%d", got, want)
+				}
+				return
+			}
+
+			// The address doesn't change until the user confirms it.
+			user, err := dataStore.ReadUser("alice")
+			if err != nil {
+				t.Fatalf("failed to read user: %v", err)
+			}
+			if got, want := user.Email, screenjournal.Email("alice@example.com"); got != want {
+				t.Errorf("email before confirming=%v, want=%v", got, want)
+			}
+
+			if _, err := v.Confirm(sender.verifications[0].Token); err != nil {
+				t.Fatalf("Confirm err=%v, want=%v", err, nil)
+			}
+
+			user, err = dataStore.ReadUser("alice")
+			if err != nil {
+				t.Fatalf("failed to read user: %v", err)
+			}
+			if got, want := user.Email, tt.newEmail; got != want {
+				t.Errorf("email after confirming=%v, want=%v", got, want)
+			}
+			if !user.EmailVerified {
+				t.Errorf("new email is unverified after confirming")
+			}
+			if got, want := sender.changeNotices, []screenjournal.Email{"alice@example.com"}; len(got) != 1 || got[0] != want[0] {
+				t.Errorf("change notices=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestRequestChangeIsRateLimited(t *testing.T) {
+	dataStore := test_sqlite.New()
+	insertUsers(t, dataStore, screenjournal.User{Username: "alice", Email: "alice@example.com"})
+	v := emailverification.New(dataStore, &mockEmailSender{}, time.Now)
+
+	for range 3 {
+		if err := v.RequestChange("alice", "alice@example.org"); err != nil {
+			t.Fatalf("err=%v, want=%v", err, nil)
+		}
+	}
+	if got, want := v.RequestChange("alice", "alice@example.org"), emailverification.ErrTooManyVerificationEmails; !errors.Is(got, want) {
+		t.Errorf("err=%v, want=%v", got, want)
+	}
+}
+
+func insertUsers(t *testing.T, dataStore sqlite.Store, users ...screenjournal.User) {
+	t.Helper()
+	for _, u := range users {
+		u.PasswordHash = screenjournal.PasswordHash("dummy-hash")
+		if err := dataStore.InsertUser(u); err != nil {
+			t.Fatalf("failed to insert user %s: %v", u.Username, err)
+		}
+	}
+}
@@ -45,6 +45,17 @@ func (s Server) accountChangePasswordPut() http.HandlerFunc {
 			return
 		}
 
+		// Sign out everywhere else in case someone else knew the old password.
+		current, err := s.sessionManager.SessionIDFromContext(r.Context())
+		if err != nil {
+			current = screenjournal.SessionID("")
+		}
+		if err := s.store.DeleteUserSessions(user.Username, current); err != nil {
+			log.Printf("failed to end other sessions for %v: %v", username, err)
+			http.Error(w, "Password updated, but failed to sign out your other sessions", http.StatusInternalServerError)
+			return
+		}
+
 		w.WriteHeader(http.StatusOK)
 		if _, err := fmt.Fprint(w, "Password updated"); err != nil {
 			log.Printf("failed to write response: %v", err)
@@ -84,8 +95,12 @@ func parseAccountChangePasswordPutRequest(r *http.Request) (accountChangePasswor
 }
 
 type accountNotificationsPutRequest struct {
-	NewReviews  bool
-	AllComments bool
+	NewReviews      bool
+	NewReviewsScope screenjournal.NotificationScope
+	AllComments     bool
+	Replies         bool
+	Mentions        bool
+	Digest          screenjournal.DigestFrequency
 }
 
 func (s Server) accountNotificationsPut() http.HandlerFunc {
@@ -98,8 +113,12 @@ func (s Server) accountNotificationsPut() http.HandlerFunc {
 
 		username := mustGetUsernameFromContext(r.Context())
 		if err = s.store.UpdateNotificationPreferences(username, screenjournal.NotificationPreferences{
-			NewReviews:     req.NewReviews,
-			AllNewComments: req.AllComments,
+			NewReviews:      req.NewReviews,
+			NewReviewsScope: req.NewReviewsScope,
+			AllNewComments:  req.AllComments,
+			Replies:         req.Replies,
+			Mentions:        req.Mentions,
+			Digest:          req.Digest,
 		}); err != nil {
 			log.Printf("failed to save notification preferences: %v", err)
 			http.Error(w, fmt.Sprintf("Failed to save notification preferences: %v", err), http.StatusInternalServerError)
@@ -119,8 +138,29 @@ func parseAccountNotificationsPutRequest(r *http.Request) (accountNotificationsP
 		return accountNotificationsPutRequest{}, err
 	}
 
+	// Clients that predate digests or follows don't send these fields, so they
+	// keep the defaults.
+	digest := screenjournal.DigestImmediate
+	if raw := r.PostFormValue("digest"); raw != "" {
+		var err error
+		if digest, err = parse.DigestFrequency(raw); err != nil {
+			return accountNotificationsPutRequest{}, err
+		}
+	}
+	scope := screenjournal.NotifyEveryone
+	if raw := r.PostFormValue("new-reviews-scope"); raw != "" {
+		var err error
+		if scope, err = parse.NotificationScope(raw); err != nil {
+			return accountNotificationsPutRequest{}, err
+		}
+	}
+
 	return accountNotificationsPutRequest{
-		NewReviews:  parse.CheckboxToBool(r.PostFormValue("new-reviews")),
-		AllComments: parse.CheckboxToBool(r.PostFormValue("all-comments")),
+		NewReviews:      parse.CheckboxToBool(r.PostFormValue("new-reviews")),
+		NewReviewsScope: scope,
+		AllComments:     parse.CheckboxToBool(r.PostFormValue("all-comments")),
+		Replies:         parse.CheckboxToBool(r.PostFormValue("replies")),
+		Mentions:        parse.CheckboxToBool(r.PostFormValue("mentions")),
+		Digest:          digest,
 	}, nil
 }
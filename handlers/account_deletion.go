@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+type accountDeletionRequest struct {
+	Password screenjournal.Password
+	Mode     screenjournal.AccountDeletionMode
+}
+
+type deleteAccountFormProps struct {
+	// Action is the URL that the form submits to.
+	Action string
+	// Username is the account to delete if it belongs to someone other than
+	// the logged-in user.
+	Username screenjournal.Username
+}
+
+func (s Server) accountDeletePost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := parseAccountDeletionRequest(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete account: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		if err := s.authenticator.Authenticate(username, req.Password); err != nil {
+			log.Printf("account deletion failed for user %s: %v", username, err)
+			http.Error(w, "Failed to delete account: password is incorrect", http.StatusUnauthorized)
+			return
+		}
+
+		if isAdmin(r.Context()) {
+			admins, err := s.store.CountAdmins()
+			if err != nil {
+				log.Printf("failed to count admins: %v", err)
+				http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+				return
+			}
+			if admins <= 1 {
+				http.Error(w, "You can't delete the only admin account", http.StatusConflict)
+				return
+			}
+		}
+
+		if err := s.store.DeleteUser(username, req.Mode); err != nil {
+			log.Printf("failed to delete user %s: %v", username, err)
+			http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+			return
+		}
+
+		// Deleting the user already deleted their sessions, so this just clears
+		// the cookie.
+		if err := s.sessionManager.LogOut(r.Context(), w); err != nil {
+			log.Printf("failed to end session for deleted user %s: %v", username, err)
+		}
+
+		w.Header().Set("HX-Redirect", "/")
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprint(w, "Your account has been deleted"); err != nil {
+			log.Printf("failed to write response: %v", err)
+		}
+	}
+}
+
+func (s Server) adminUsersDeleteGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			ParseFS(
+				templatesFS,
+				append(baseTemplates,
+					"templates/fragments/delete-account-form.html",
+					"templates/pages/admin-delete-user.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := s.store.ReadUser(username); errors.Is(err, store.ErrUserNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("failed to read user %s: %v", username, err)
+			http.Error(w, "Failed to read user", http.StatusInternalServerError)
+			return
+		}
+
+		renderTemplate(w, t, "base.html", struct {
+			commonProps
+			DeleteAccount deleteAccountFormProps
+		}{
+			commonProps: makeCommonProps(r.Context()),
+			DeleteAccount: deleteAccountFormProps{
+				Action:   fmt.Sprintf("/admin/users/%s/delete", username),
+				Username: username,
+			},
+		})
+	}
+}
+
+func (s Server) adminUsersDeletePost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+
+		req, err := parseAccountDeletionRequest(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete account: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		admin := mustGetUsernameFromContext(r.Context())
+		if username.Equal(admin) {
+			http.Error(w, "Delete your own account from your account page", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.authenticator.Authenticate(admin, req.Password); err != nil {
+			log.Printf("admin %s failed to confirm deletion of user %s: %v", admin, username, err)
+			http.Error(w, "Failed to delete account: password is incorrect", http.StatusUnauthorized)
+			return
+		}
+
+		if err := s.store.DeleteUser(username, req.Mode); errors.Is(err, store.ErrUserNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("failed to delete user %s: %v", username, err)
+			http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("admin %s deleted user %s", admin, username)
+
+		w.Header().Set("HX-Redirect", "/users")
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprintf(w, "Deleted %s's account", username); err != nil {
+			log.Printf("failed to write response: %v", err)
+		}
+	}
+}
+
+func parseAccountDeletionRequest(r *http.Request) (accountDeletionRequest, error) {
+	if err := r.ParseForm(); err != nil {
+		log.Printf("failed to decode account deletion request: %v", err)
+		return accountDeletionRequest{}, err
+	}
+
+	password, err := parse.Password(r.PostFormValue("password"))
+	if err != nil {
+		return accountDeletionRequest{}, err
+	}
+
+	mode, err := parse.AccountDeletionMode(r.PostFormValue("content"))
+	if err != nil {
+		return accountDeletionRequest{}, err
+	}
+
+	return accountDeletionRequest{
+		Password: password,
+		Mode:     mode,
+	}, nil
+}
@@ -0,0 +1,170 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestAccountDeletion(t *testing.T) {
+	for _, tt := range []struct {
+		description  string
+		route        string
+		payload      string
+		sessionToken string
+		status       int
+		redirect     string
+		deleted      []screenjournal.Username
+		kept         []screenjournal.Username
+	}{
+		{
+			description:  "user deletes their own account and content",
+			route:        "/account/delete",
+			payload:      "content=delete&password=p%40ssw0rd123",
+			sessionToken: "def456",
+			status:       http.StatusOK,
+			redirect:     "/",
+			deleted:      []screenjournal.Username{"userB"},
+			kept:         []screenjournal.Username{"userA"},
+		},
+		{
+			description:  "user deletes their own account and anonymizes content",
+			route:        "/account/delete",
+			payload:      "content=anonymize&password=p%40ssw0rd123",
+			sessionToken: "def456",
+			status:       http.StatusOK,
+			redirect:     "/",
+			deleted:      []screenjournal.Username{"userB"},
+			kept:         []screenjournal.Username{"userA"},
+		},
+		{
+			description:  "rejects account deletion with the wrong password",
+			route:        "/account/delete",
+			payload:      "content=delete&password=wrongpass",
+			sessionToken: "def456",
+			status:       http.StatusUnauthorized,
+			kept:         []screenjournal.Username{"userA", "userB"},
+		},
+		{
+			description:  "rejects account deletion without a content choice",
+			route:        "/account/delete",
+			payload:      "password=p%40ssw0rd123",
+			sessionToken: "def456",
+			status:       http.StatusBadRequest,
+			kept:         []screenjournal.Username{"userA", "userB"},
+		},
+		{
+			description:  "rejects account deletion from the only admin",
+			route:        "/account/delete",
+			payload:      "content=delete&password=dummyp%40ss",
+			sessionToken: "abc123",
+			status:       http.StatusConflict,
+			kept:         []screenjournal.Username{"userA", "userB"},
+		},
+		{
+			description:  "rejects account deletion without a session",
+			route:        "/account/delete",
+			payload:      "content=delete&password=p%40ssw0rd123",
+			sessionToken: "",
+			status:       http.StatusUnauthorized,
+			kept:         []screenjournal.Username{"userA", "userB"},
+		},
+		{
+			description:  "admin deletes another user's account",
+			route:        "/admin/users/userB/delete",
+			payload:      "content=anonymize&password=dummyp%40ss",
+			sessionToken: "abc123",
+			status:       http.StatusOK,
+			redirect:     "/users",
+			deleted:      []screenjournal.Username{"userB"},
+			kept:         []screenjournal.Username{"userA"},
+		},
+		{
+			description:  "rejects admin deletion with the wrong admin password",
+			route:        "/admin/users/userB/delete",
+			payload:      "content=anonymize&password=p%40ssw0rd123",
+			sessionToken: "abc123",
+			status:       http.StatusUnauthorized,
+			kept:         []screenjournal.Username{"userA", "userB"},
+		},
+		{
+			description:  "rejects admin deletion of the admin's own account",
+			route:        "/admin/users/userA/delete",
+			payload:      "content=delete&password=dummyp%40ss",
+			sessionToken: "abc123",
+			status:       http.StatusBadRequest,
+			kept:         []screenjournal.Username{"userA", "userB"},
+		},
+		{
+			description:  "rejects admin deletion of a user that doesn't exist",
+			route:        "/admin/users/nobody/delete",
+			payload:      "content=delete&password=dummyp%40ss",
+			sessionToken: "abc123",
+			status:       http.StatusNotFound,
+			kept:         []screenjournal.Username{"userA", "userB"},
+		},
+		{
+			description:  "rejects admin deletion from a non-admin",
+			route:        "/admin/users/userA/delete",
+			payload:      "content=delete&password=p%40ssw0rd123",
+			sessionToken: "def456",
+			status:       http.StatusForbidden,
+			kept:         []screenjournal.Username{"userA", "userB"},
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			insertMockUsers(t, dataStore, []screenjournal.User{userA, userB})
+
+			sessionManager := newMockSessionManager([]mockSessionEntry{
+				newMockSessionEntry("abc123", userA.Username),
+				newMockSessionEntry("def456", userB.Username),
+			})
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req, err := http.NewRequest(http.MethodPost, tt.route, strings.NewReader(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: tt.sessionToken,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+			res := rec.Result()
+
+			if got, want := res.StatusCode, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+			if got, want := res.Header.Get("HX-Redirect"), tt.redirect; got != want {
+				t.Errorf("HX-Redirect=%v, want=%v", got, want)
+			}
+
+			for _, username := range tt.deleted {
+				if _, err := dataStore.ReadUser(username); !errors.Is(err, store.ErrUserNotFound) {
+					t.Errorf("ReadUser(%s) err=%v, want=%v", username, err, store.ErrUserNotFound)
+				}
+			}
+			for _, username := range tt.kept {
+				if _, err := dataStore.ReadUser(username); err != nil {
+					t.Errorf("ReadUser(%s) err=%v, want=%v", username, err, nil)
+				}
+			}
+		})
+	}
+}
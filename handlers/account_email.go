@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/mtlynch/screenjournal/v2/emailverification"
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type verifyEmailPageProps struct {
+	commonProps
+	Token    screenjournal.PasswordResetToken
+	Verified bool
+}
+
+type emailStatusProps struct {
+	Email     screenjournal.Email
+	Verified  bool
+	Available bool
+}
+
+func (s Server) accountEmailPut() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.emailVerifier == nil {
+			http.Error(w, "Email changes are not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		newEmail, err := parse.Email(r.PostFormValue("email"))
+		if err != nil {
+			http.Error(w, "Invalid email address", http.StatusBadRequest)
+			return
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		if err := s.emailVerifier.RequestChange(username, newEmail); err != nil {
+			switch {
+			case errors.Is(err, emailverification.ErrSameEmail):
+				http.Error(w, "That's already your email address", http.StatusBadRequest)
+			case errors.Is(err, emailverification.ErrEmailUnavailable):
+				http.Error(w, "That email address is associated with another account", http.StatusConflict)
+			case errors.Is(err, emailverification.ErrTooManyVerificationEmails):
+				http.Error(w, "Too many verification emails. Please try again later.", http.StatusTooManyRequests)
+			default:
+				log.Printf("failed to request email change for user %s: %v", username, err)
+				http.Error(w, "Failed to send confirmation email", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprintf(w, "We sent a confirmation link to %s. Your email address will change once you follow it.", newEmail); err != nil {
+			log.Printf("failed to write response: %v", err)
+		}
+	}
+}
+
+func (s Server) accountEmailVerificationPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.emailVerifier == nil {
+			http.Error(w, "Email verification is not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		if err := s.emailVerifier.SendVerification(username); err != nil {
+			switch {
+			case errors.Is(err, emailverification.ErrAlreadyVerified):
+				http.Error(w, "Your email address is already verified", http.StatusBadRequest)
+			case errors.Is(err, emailverification.ErrTooManyVerificationEmails):
+				http.Error(w, "Too many verification emails. Please try again later.", http.StatusTooManyRequests)
+			default:
+				log.Printf("failed to send verification email for user %s: %v", username, err)
+				http.Error(w, "Failed to send verification email", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprint(w, "Verification email sent. Check your inbox."); err != nil {
+			log.Printf("failed to write response: %v", err)
+		}
+	}
+}
+
+// accountVerifyEmailGet asks the user to confirm their email address rather
+// than using up the token right away, since email scanners often follow links
+// before the user sees them.
+func (s Server) accountVerifyEmailGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			ParseFS(
+				templatesFS,
+				append(baseTemplates, "templates/pages/verify-email.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, err := parse.PasswordResetToken(r.URL.Query().Get("token"))
+		if err != nil {
+			http.Error(w, "Invalid verification link", http.StatusBadRequest)
+			return
+		}
+
+		renderTemplate(w, t, "base.html", verifyEmailPageProps{
+			commonProps: makeCommonProps(r.Context()),
+			Token:       token,
+		})
+	}
+}
+
+func (s Server) accountVerifyEmailPost() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			ParseFS(
+				templatesFS,
+				append(baseTemplates, "templates/pages/verify-email.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.emailVerifier == nil {
+			http.Error(w, "Email verification is not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		token, err := parse.PasswordResetToken(r.PostFormValue("token"))
+		if err != nil {
+			http.Error(w, "Invalid verification link", http.StatusBadRequest)
+			return
+		}
+
+		username, err := s.emailVerifier.Confirm(token)
+		if err != nil {
+			switch {
+			case errors.Is(err, emailverification.ErrInvalidToken),
+				errors.Is(err, emailverification.ErrExpiredToken):
+				http.Error(w, "This verification link is invalid or has expired. Please request a new one from your account page.", http.StatusBadRequest)
+			case errors.Is(err, emailverification.ErrEmailUnavailable):
+				http.Error(w, "That email address is now associated with another account", http.StatusConflict)
+			default:
+				log.Printf("failed to confirm email verification: %v", err)
+				http.Error(w, "Failed to verify email address", http.StatusInternalServerError)
+			}
+			return
+		}
+		log.Printf("verified email address for user %s", username)
+
+		renderTemplate(w, t, "base.html", verifyEmailPageProps{
+			commonProps: makeCommonProps(r.Context()),
+			Verified:    true,
+		})
+	}
+}
+
+func (s Server) readEmailStatus(username screenjournal.Username) (emailStatusProps, error) {
+	user, err := s.store.ReadUser(username)
+	if err != nil {
+		return emailStatusProps{}, err
+	}
+	return emailStatusProps{
+		Email:     user.Email,
+		Verified:  user.EmailVerified,
+		Available: s.emailVerifier != nil,
+	}, nil
+}
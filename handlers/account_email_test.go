@@ -0,0 +1,175 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/emailverification"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+type mockVerificationSender struct {
+	verifications []screenjournal.EmailVerification
+	changeNotices []screenjournal.User
+}
+
+func (s *mockVerificationSender) SendVerification(user screenjournal.User, v screenjournal.EmailVerification) error {
+	s.verifications = append(s.verifications, v)
+	return nil
+}
+
+func (s *mockVerificationSender) SendEmailChanged(user screenjournal.User, newEmail screenjournal.Email) error {
+	s.changeNotices = append(s.changeNotices, user)
+	return nil
+}
+
+func TestAccountEmailPut(t *testing.T) {
+	for _, tt := range []struct {
+		description       string
+		email             string
+		verifierAvailable bool
+		status            int
+		verificationsSent int
+	}{
+		{
+			description:       "sends a confirmation to the new address",
+			email:             "userA@example.org",
+			verifierAvailable: true,
+			status:            http.StatusOK,
+			verificationsSent: 1,
+		},
+		{
+			description:       "rejects an address that belongs to another user",
+			email:             "userB@example.com",
+			verifierAvailable: true,
+			status:            http.StatusConflict,
+		},
+		{
+			description:       "rejects an invalid address",
+			email:             "userA@example@org",
+			verifierAvailable: true,
+			status:            http.StatusBadRequest,
+		},
+		{
+			description:       "returns 503 when email is disabled",
+			email:             "userA@example.org",
+			verifierAvailable: false,
+			status:            http.StatusServiceUnavailable,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			sessions := []mockSessionEntry{
+				newMockSessionEntry("abc123", screenjournal.Username("userA")),
+				newMockSessionEntry("def456", screenjournal.Username("userB")),
+			}
+			insertMockUsersForSessions(t, dataStore, sessions)
+
+			sender := &mockVerificationSender{}
+			sessionManager := newMockSessionManager(sessions)
+			params := handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			}
+			if tt.verifierAvailable {
+				params.EmailVerifier = emailverification.New(dataStore, sender, time.Now)
+			}
+			s := handlers.New(params)
+
+			req := httptest.NewRequest(http.MethodPut, "/account/email", strings.NewReader("email="+url.QueryEscape(tt.email)))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: "abc123",
+			})
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+
+			if got, want := rec.Code, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+			if got, want := len(sender.verifications), tt.verificationsSent; got != want {
+				t.Errorf("verificationsSent=%d, want=%d", got, want)
+			}
+
+			// The address doesn't change until the user follows the link.
+			user, err := dataStore.ReadUser("userA")
+			if err != nil {
+				t.Fatalf("failed to read user: %v", err)
+			}
+			if got, want := user.Email, screenjournal.Email("userA@example.com"); got != want {
+				t.Errorf("email=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestAccountVerifyEmail(t *testing.T) {
+	dataStore := test_sqlite.New()
+	user := newMockUser("userA")
+	user.EmailVerified = false
+	if err := dataStore.InsertUser(user); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+
+	sender := &mockVerificationSender{}
+	verifier := emailverification.New(dataStore, sender, time.Now)
+	if err := verifier.RequestChange(user.Username, "userA@example.org"); err != nil {
+		t.Fatalf("failed to request email change: %v", err)
+	}
+	token := sender.verifications[0].Token
+
+	sessionManager := newMockSessionManager([]mockSessionEntry{})
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+		EmailVerifier:  verifier,
+	})
+
+	// Following the link shows a confirmation form without using the token.
+	req := httptest.NewRequest(http.MethodGet, "/account/verify-email?token="+token.String(), nil)
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("GET httpStatus=%v, want=%v", got, want)
+	}
+	if !strings.Contains(rec.Body.String(), `id="verify-email-form"`) {
+		t.Errorf("GET response has no confirmation form")
+	}
+
+	for _, want := range []int{http.StatusOK, http.StatusBadRequest} {
+		req = httptest.NewRequest(http.MethodPost, "/account/verify-email", strings.NewReader("token="+token.String()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec = httptest.NewRecorder()
+		s.Router().ServeHTTP(rec, req)
+		if got := rec.Code; got != want {
+			t.Fatalf("POST httpStatus=%v, want=%v", got, want)
+		}
+	}
+
+	updated, err := dataStore.ReadUser(user.Username)
+	if err != nil {
+		t.Fatalf("failed to read user: %v", err)
+	}
+	if got, want := updated.Email, screenjournal.Email("userA@example.org"); got != want {
+		t.Errorf("email=%v, want=%v", got, want)
+	}
+	if !updated.EmailVerified {
+		t.Errorf("email is unverified after following the link")
+	}
+	if got, want := len(sender.changeNotices), 1; got != want {
+		t.Fatalf("changeNotices=%d, want=%d", got, want)
+	}
+	if got, want := sender.changeNotices[0].Email, screenjournal.Email("userA@example.com"); got != want {
+		t.Errorf("change notice sent to=%v, want=%v", got, want)
+	}
+}
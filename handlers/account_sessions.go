@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+type (
+	sessionsProps struct {
+		Sessions []sessionProps
+	}
+
+	sessionProps struct {
+		Handle     screenjournal.SessionHandle
+		Current    bool
+		Device     string
+		IPAddress  string
+		CreatedAt  time.Time
+		LastSeenAt time.Time
+	}
+)
+
+func (s Server) accountSessionsDelete() http.HandlerFunc {
+	t := template.Must(template.New("sessions-list.html").Funcs(reviewPageFns).ParseFS(templatesFS, "templates/fragments/sessions-list.html"))
+	return func(w http.ResponseWriter, r *http.Request) {
+		handle, err := sessionHandleFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid session", http.StatusBadRequest)
+			return
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		sessions, err := s.store.ReadUserSessions(username, time.Now())
+		if err != nil {
+			log.Printf("failed to read sessions for user %s: %v", username, err)
+			http.Error(w, "Failed to read sessions", http.StatusInternalServerError)
+			return
+		}
+
+		var target screenjournal.SessionID
+		for _, session := range sessions {
+			if session.ID.Handle() == handle {
+				target = session.ID
+				break
+			}
+		}
+		if target.Empty() {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+
+		if current, err := s.sessionManager.SessionIDFromContext(r.Context()); err == nil && current == target {
+			http.Error(w, "To end your current session, log out instead", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.store.DeleteUserSession(username, target); err != nil {
+			if errors.Is(err, store.ErrSessionNotFound) {
+				http.Error(w, "Session not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("failed to end session for user %s: %v", username, err)
+			http.Error(w, "Failed to end session", http.StatusInternalServerError)
+			return
+		}
+
+		s.renderSessionsList(w, r, t, username)
+	}
+}
+
+// accountOtherSessionsDelete signs the user out everywhere except the
+// browser that sent the request.
+func (s Server) accountOtherSessionsDelete() http.HandlerFunc {
+	t := template.Must(template.New("sessions-list.html").Funcs(reviewPageFns).ParseFS(templatesFS, "templates/fragments/sessions-list.html"))
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := mustGetUsernameFromContext(r.Context())
+		current, err := s.sessionManager.SessionIDFromContext(r.Context())
+		if err != nil {
+			log.Printf("failed to identify current session for user %s: %v", username, err)
+			http.Error(w, "Failed to identify your current session", http.StatusInternalServerError)
+			return
+		}
+
+		if err := s.store.DeleteUserSessions(username, current); err != nil {
+			log.Printf("failed to end other sessions for user %s: %v", username, err)
+			http.Error(w, "Failed to end sessions", http.StatusInternalServerError)
+			return
+		}
+
+		s.renderSessionsList(w, r, t, username)
+	}
+}
+
+func (s Server) renderSessionsList(w http.ResponseWriter, r *http.Request, t *template.Template, username screenjournal.Username) {
+	props, err := s.readSessions(r, username)
+	if err != nil {
+		log.Printf("failed to read sessions for user %s: %v", username, err)
+		http.Error(w, "Failed to read sessions", http.StatusInternalServerError)
+		return
+	}
+	renderTemplate(w, t, "sessions-list.html", props)
+}
+
+func (s Server) readSessions(r *http.Request, username screenjournal.Username) (sessionsProps, error) {
+	sessions, err := s.store.ReadUserSessions(username, time.Now())
+	if err != nil {
+		return sessionsProps{}, err
+	}
+
+	// If we can't tell which session is current, we just don't mark one.
+	current, err := s.sessionManager.SessionIDFromContext(r.Context())
+	if err != nil {
+		current = screenjournal.SessionID("")
+	}
+
+	props := sessionsProps{
+		Sessions: make([]sessionProps, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		props.Sessions = append(props.Sessions, sessionProps{
+			Handle:     session.ID.Handle(),
+			Current:    !current.Empty() && session.ID == current,
+			Device:     describeUserAgent(session.Client.UserAgent),
+			IPAddress:  session.Client.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+		})
+	}
+	return props, nil
+}
+
+// describeUserAgent summarizes a User-Agent header as something like "Firefox
+// on Windows". It only needs to be good enough for users to recognize their
+// own devices.
+func describeUserAgent(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	// Order matters because most browsers claim to be several others.
+	for _, b := range []struct {
+		token string
+		name  string
+	}{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"FxiOS/", "Firefox"},
+		{"CriOS/", "Chrome"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	} {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	os := ""
+	for _, o := range []struct {
+		token string
+		name  string
+	}{
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, o.token) {
+			os = o.name
+			break
+		}
+	}
+
+	if os == "" {
+		return browser
+	}
+	return browser + " on " + os
+}
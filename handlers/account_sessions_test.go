@@ -0,0 +1,185 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	simple_sessions "codeberg.org/mtlynch/simpleauth/v3/sessions"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/sqlite"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+// mustInsertStoredSession saves a session in the store under the same token
+// that the mock session manager uses, so handlers can tell which stored
+// session is the current one.
+func mustInsertStoredSession(t *testing.T, dataStore sqlite.Store, token string, username screenjournal.Username, client screenjournal.SessionClient) {
+	t.Helper()
+	id, err := simple_sessions.NewID(token)
+	if err != nil {
+		t.Fatalf("failed to create session ID: %v", err)
+	}
+	userID, err := simple_sessions.NewUserID(username.String())
+	if err != nil {
+		t.Fatalf("failed to create user ID: %v", err)
+	}
+	now := time.Now()
+	if err := dataStore.InsertSession(context.Background(), simple_sessions.Session{
+		ID:        id,
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(24 * time.Hour),
+	}, client); err != nil {
+		t.Fatalf("failed to insert session: %v", err)
+	}
+}
+
+func storedSessionTokens(t *testing.T, dataStore sqlite.Store, username screenjournal.Username) []string {
+	t.Helper()
+	sessions, err := dataStore.ReadUserSessions(username, time.Now())
+	if err != nil {
+		t.Fatalf("failed to read sessions: %v", err)
+	}
+	tokens := []string{}
+	for _, session := range sessions {
+		tokens = append(tokens, session.ID.String())
+	}
+	return tokens
+}
+
+func TestAccountSessionsRoutes(t *testing.T) {
+	dataStore := test_sqlite.New()
+	sessions := []mockSessionEntry{
+		newMockSessionEntry("abc123", screenjournal.Username("userA")),
+		newMockSessionEntry("def456", screenjournal.Username("userB")),
+	}
+	insertMockUsersForSessions(t, dataStore, sessions)
+
+	mustInsertStoredSession(t, dataStore, "abc123", "userA", screenjournal.SessionClient{
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:138.0) Gecko/20100101 Firefox/138.0",
+		IPAddress: "203.0.113.7",
+	})
+	mustInsertStoredSession(t, dataStore, "laptop-token", "userA", screenjournal.SessionClient{
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36",
+		IPAddress: "198.51.100.20",
+	})
+	mustInsertStoredSession(t, dataStore, "phone-token", "userA", screenjournal.SessionClient{})
+	mustInsertStoredSession(t, dataStore, "def456", "userB", screenjournal.SessionClient{})
+
+	sessionManager := newMockSessionManager(sessions)
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	send := func(method, route string, sessionToken string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, route, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.AddCookie(&http.Cookie{
+			Name:  mockSessionTokenName,
+			Value: sessionToken,
+		})
+		rec := httptest.NewRecorder()
+		s.Router().ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+	sessionRoute := func(token string) string {
+		return "/account/security/sessions/" + screenjournal.SessionID(token).Handle().String()
+	}
+
+	status, body := send("GET", "/account/security", "abc123")
+	if got, want := status, http.StatusOK; got != want {
+		t.Fatalf("security page httpStatus=%v, want=%v", got, want)
+	}
+	for _, want := range []string{"Firefox on Linux", "203.0.113.7", "This browser", "Chrome on Windows", "198.51.100.20"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("security page doesn't contain %q", want)
+		}
+	}
+	if got, want := strings.Count(body, `data-testid="session"`), 3; got != want {
+		t.Errorf("security page lists %d sessions, want=%d", got, want)
+	}
+
+	if status, _ := send("DELETE", "/account/security/sessions/not-a-handle", "abc123"); status != http.StatusBadRequest {
+		t.Errorf("invalid handle httpStatus=%v, want=%v", status, http.StatusBadRequest)
+	}
+	if status, _ := send("DELETE", sessionRoute("def456"), "abc123"); status != http.StatusNotFound {
+		t.Errorf("ending another user's session httpStatus=%v, want=%v", status, http.StatusNotFound)
+	}
+	if status, _ := send("DELETE", sessionRoute("abc123"), "abc123"); status != http.StatusBadRequest {
+		t.Errorf("ending current session httpStatus=%v, want=%v", status, http.StatusBadRequest)
+	}
+
+	status, body = send("DELETE", sessionRoute("laptop-token"), "abc123")
+	if got, want := status, http.StatusOK; got != want {
+		t.Fatalf("ending session httpStatus=%v, want=%v", got, want)
+	}
+	if strings.Contains(body, "198.51.100.20") {
+		t.Errorf("session list still shows ended session: %s", body)
+	}
+	if got, want := len(storedSessionTokens(t, dataStore, "userA")), 2; got != want {
+		t.Errorf("count(sessions)=%d, want=%d", got, want)
+	}
+
+	if status, _ := send("DELETE", "/account/security/sessions", "abc123"); status != http.StatusOK {
+		t.Fatalf("ending other sessions httpStatus=%v, want=%v", status, http.StatusOK)
+	}
+	if got, want := storedSessionTokens(t, dataStore, "userA"), []string{"abc123"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("remaining sessions=%v, want=%v", got, want)
+	}
+	if got, want := storedSessionTokens(t, dataStore, "userB"), []string{"def456"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("other user's sessions=%v, want=%v", got, want)
+	}
+}
+
+func TestAccountChangePasswordEndsOtherSessions(t *testing.T) {
+	dataStore := test_sqlite.New()
+	if err := dataStore.InsertUser(screenjournal.User{
+		Username:     screenjournal.Username("userA"),
+		PasswordHash: mustCreatePasswordHash("oldpass123"),
+		Email:        screenjournal.Email("userA@example.com"),
+	}); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	mustInsertStoredSession(t, dataStore, "abc123", "userA", screenjournal.SessionClient{})
+	mustInsertStoredSession(t, dataStore, "laptop-token", "userA", screenjournal.SessionClient{})
+
+	sessionManager := newMockSessionManager([]mockSessionEntry{
+		newMockSessionEntry("abc123", screenjournal.Username("userA")),
+	})
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	req, err := http.NewRequest("PUT", "/account/password", strings.NewReader("old-password=oldpass123&password=newpass456"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{
+		Name:  mockSessionTokenName,
+		Value: "abc123",
+	})
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("httpStatus=%v, want=%v", got, want)
+	}
+
+	if got, want := storedSessionTokens(t, dataStore, "userA"), []string{"abc123"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("remaining sessions=%v, want=%v", got, want)
+	}
+}
@@ -152,8 +152,10 @@ func TestAccountNotificationsPut(t *testing.T) {
 				},
 			},
 			expectedPrefs: screenjournal.NotificationPreferences{
-				NewReviews:     true,
-				AllNewComments: true,
+				NewReviewsScope: screenjournal.NotifyEveryone,
+				NewReviews:      true,
+				AllNewComments:  true,
+				Digest:          screenjournal.DigestImmediate,
 			},
 			status: http.StatusOK,
 		},
@@ -170,8 +172,10 @@ func TestAccountNotificationsPut(t *testing.T) {
 				},
 			},
 			expectedPrefs: screenjournal.NotificationPreferences{
-				NewReviews:     false,
-				AllNewComments: true,
+				NewReviewsScope: screenjournal.NotifyEveryone,
+				NewReviews:      false,
+				AllNewComments:  true,
+				Digest:          screenjournal.DigestImmediate,
 			},
 			status: http.StatusOK,
 		},
@@ -188,11 +192,118 @@ func TestAccountNotificationsPut(t *testing.T) {
 				},
 			},
 			expectedPrefs: screenjournal.NotificationPreferences{
-				NewReviews:     true,
-				AllNewComments: false,
+				NewReviewsScope: screenjournal.NotifyEveryone,
+				NewReviews:      true,
+				AllNewComments:  false,
+				Digest:          screenjournal.DigestImmediate,
 			},
 			status: http.StatusOK,
 		},
+		{
+			description:  "allows user to subscribe only to mentions",
+			payload:      "mentions=on",
+			sessionToken: "abc123",
+			sessions: []mockSessionEntry{
+				{
+					token: "abc123",
+					session: mockSession{
+						Username: screenjournal.Username("userA"),
+					},
+				},
+			},
+			expectedPrefs: screenjournal.NotificationPreferences{
+				NewReviewsScope: screenjournal.NotifyEveryone,
+				Mentions:        true,
+				Digest:          screenjournal.DigestImmediate,
+			},
+			status: http.StatusOK,
+		},
+		{
+			description:  "allows user to subscribe only to replies",
+			payload:      "replies=on",
+			sessionToken: "abc123",
+			sessions: []mockSessionEntry{
+				{
+					token: "abc123",
+					session: mockSession{
+						Username: screenjournal.Username("userA"),
+					},
+				},
+			},
+			expectedPrefs: screenjournal.NotificationPreferences{
+				NewReviewsScope: screenjournal.NotifyEveryone,
+				Replies:         true,
+				Digest:          screenjournal.DigestImmediate,
+			},
+			status: http.StatusOK,
+		},
+		{
+			description:  "allows user to switch to a weekly digest",
+			payload:      "new-reviews=on&all-comments=on&digest=weekly",
+			sessionToken: "abc123",
+			sessions: []mockSessionEntry{
+				{
+					token: "abc123",
+					session: mockSession{
+						Username: screenjournal.Username("userA"),
+					},
+				},
+			},
+			expectedPrefs: screenjournal.NotificationPreferences{
+				NewReviewsScope: screenjournal.NotifyEveryone,
+				NewReviews:      true,
+				AllNewComments:  true,
+				Digest:          screenjournal.DigestWeekly,
+			},
+			status: http.StatusOK,
+		},
+		{
+			description:  "allows user to hear only about reviews from people they follow",
+			payload:      "new-reviews=on&new-reviews-scope=following",
+			sessionToken: "abc123",
+			sessions: []mockSessionEntry{
+				{
+					token: "abc123",
+					session: mockSession{
+						Username: screenjournal.Username("userA"),
+					},
+				},
+			},
+			expectedPrefs: screenjournal.NotificationPreferences{
+				NewReviews:      true,
+				NewReviewsScope: screenjournal.NotifyFollowing,
+				Digest:          screenjournal.DigestImmediate,
+			},
+			status: http.StatusOK,
+		},
+		{
+			description:  "rejects subscription update with invalid new review scope",
+			payload:      "new-reviews=on&new-reviews-scope=friends",
+			sessionToken: "abc123",
+			sessions: []mockSessionEntry{
+				{
+					token: "abc123",
+					session: mockSession{
+						Username: screenjournal.Username("userA"),
+					},
+				},
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			description:  "rejects subscription update with invalid digest frequency",
+			payload:      "new-reviews=on&digest=hourly",
+			sessionToken: "abc123",
+			sessions: []mockSessionEntry{
+				{
+					token: "abc123",
+					session: mockSession{
+						Username: screenjournal.Username("userA"),
+					},
+				},
+			},
+			status: http.StatusBadRequest,
+		},
 		{
 			description:  "rejects subscription update if user is not authenticated",
 			payload:      "new-reviews=on&all-comments=on",
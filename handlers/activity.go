@@ -8,6 +8,7 @@ import (
 	"slices"
 	"time"
 
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
 )
 
@@ -15,6 +16,9 @@ const (
 	activityKindReview   = "review"
 	activityKindComment  = "comment"
 	activityKindReaction = "reaction"
+	// activityKindCommentReaction is a reaction to a comment rather than to a
+	// review.
+	activityKindCommentReaction = "comment-reaction"
 )
 
 type activityItem struct {
@@ -30,6 +34,10 @@ type activityItem struct {
 	ReactionEmoji  screenjournal.ReactionEmoji
 }
 
+// activityFilterFollowing limits the activity page to users the viewer
+// follows.
+const activityFilterFollowing = "following"
+
 type activityGroup struct {
 	DateLabel string
 	Items     []activityItem
@@ -63,12 +71,48 @@ func (s Server) activityGet() http.HandlerFunc {
 			reviews[i].Reactions = rr
 		}
 
+		groups := buildActivityGroups(reviews)
+		filter := r.URL.Query().Get("filter")
+		switch filter {
+		case "":
+			// Show activity from everyone.
+		case activityFilterFollowing:
+			relationships, err := s.store.ReadUserRelationships(mustGetUsernameFromContext(r.Context()))
+			if err != nil {
+				log.Printf("failed to read user relationships: %v", err)
+				http.Error(w, "Failed to load activity", http.StatusInternalServerError)
+				return
+			}
+			groups = filterActivityGroups(groups, func(actor screenjournal.Username) bool {
+				return relationships[actor] == screenjournal.RelationshipFollow
+			})
+		default:
+			http.Error(w, "Invalid activity filter", http.StatusBadRequest)
+			return
+		}
+
+		var user screenjournal.Username
+		if raw := r.URL.Query().Get("user"); raw != "" {
+			user, err = parse.Username(raw)
+			if err != nil {
+				http.Error(w, "Invalid username", http.StatusBadRequest)
+				return
+			}
+			groups = filterActivityGroups(groups, func(actor screenjournal.Username) bool {
+				return actor.Equal(user)
+			})
+		}
+
 		renderTemplate(w, t, "base.html", struct {
 			commonProps
 			Groups []activityGroup
+			Filter string
+			User   screenjournal.Username
 		}{
 			commonProps: makeCommonProps(r.Context()),
-			Groups:      buildActivityGroups(reviews),
+			Groups:      groups,
+			Filter:      filter,
+			User:        user,
 		})
 	}
 }
@@ -99,6 +143,20 @@ func buildActivityGroups(reviews []screenjournal.Review) []activityGroup {
 				TargetText:     reviewTargetText,
 				TargetURL:      reviewCommentURL(review, comment.ID),
 			})
+
+			for _, reaction := range comment.Reactions {
+				items = append(items, activityItem{
+					Kind:           activityKindCommentReaction,
+					Created:        reaction.Created,
+					ActorName:      reaction.Owner,
+					ActorURL:       userReviewsURL(reaction.Owner),
+					TargetUserName: comment.Owner,
+					TargetUserURL:  userReviewsURL(comment.Owner),
+					TargetText:     reviewTargetText,
+					TargetURL:      reviewCommentURL(review, comment.ID),
+					ReactionEmoji:  reaction.Emoji,
+				})
+			}
 		}
 
 		for _, reaction := range review.Reactions {
@@ -141,6 +199,28 @@ func buildActivityGroups(reviews []screenjournal.Review) []activityGroup {
 	return groups
 }
 
+// filterActivityGroups keeps only the items whose actor passes the include
+// check, dropping any date groups left empty.
+func filterActivityGroups(groups []activityGroup, include func(screenjournal.Username) bool) []activityGroup {
+	filtered := []activityGroup{}
+	for _, group := range groups {
+		items := []activityItem{}
+		for _, item := range group.Items {
+			if include(item.ActorName) {
+				items = append(items, item)
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+		filtered = append(filtered, activityGroup{
+			DateLabel: group.DateLabel,
+			Items:     items,
+		})
+	}
+	return filtered
+}
+
 func activityDateKey(t time.Time) string {
 	local := t.In(time.Local)
 	return fmt.Sprintf("%04d-%02d-%02d", local.Year(), local.Month(), local.Day())
@@ -99,3 +99,89 @@ func TestBuildActivityGroupsUsesTvShowSeasonLinks(t *testing.T) {
 		t.Errorf("unexpected review target url: got %q want %q", got, want)
 	}
 }
+
+func TestBuildActivityGroupsIncludesCommentReactions(t *testing.T) {
+	loc := time.UTC
+	reviewTime := time.Date(2025, 1, 1, 10, 0, 0, 0, loc)
+	commentTime := time.Date(2025, 1, 1, 11, 0, 0, 0, loc)
+	reactionTime := time.Date(2025, 1, 1, 12, 0, 0, 0, loc)
+
+	review := screenjournal.Review{
+		ID:      screenjournal.ReviewID(12),
+		Owner:   screenjournal.Username("mike"),
+		Rating:  screenjournal.NewRating(7),
+		Movie:   screenjournal.Movie{ID: screenjournal.MovieID(3), Title: screenjournal.MediaTitle("Poker Face")},
+		Created: reviewTime,
+		Comments: []screenjournal.ReviewComment{
+			{
+				ID:      screenjournal.CommentID(44),
+				Owner:   screenjournal.Username("jamie"),
+				Created: commentTime,
+				Reactions: []screenjournal.ReviewReaction{
+					{
+						ID:      screenjournal.ReactionID(55),
+						Owner:   screenjournal.Username("joe"),
+						Emoji:   screenjournal.NewReactionEmoji("👍"),
+						Created: reactionTime,
+					},
+				},
+			},
+		},
+	}
+
+	groups := buildActivityGroups([]screenjournal.Review{review})
+	if got, want := len(groups), 1; got != want {
+		t.Fatalf("expected 1 group, got %d", got)
+	}
+	if got, want := len(groups[0].Items), 3; got != want {
+		t.Fatalf("expected 3 activity items, got %d", got)
+	}
+
+	item := groups[0].Items[0]
+	if got, want := item.Kind, activityKindCommentReaction; got != want {
+		t.Errorf("unexpected kind: got %s want %s", got, want)
+	}
+	if got, want := item.TargetUserName, screenjournal.Username("jamie"); !got.Equal(want) {
+		t.Errorf("unexpected target user: got %v want %v", got, want)
+	}
+	if got, want := item.TargetURL, "/movies/3#comment44"; got != want {
+		t.Errorf("unexpected target url: got %q want %q", got, want)
+	}
+	if got, want := item.ReactionEmoji.String(), "👍"; got != want {
+		t.Errorf("unexpected emoji: got %q want %q", got, want)
+	}
+}
+
+func TestFilterActivityGroupsDropsEmptyGroups(t *testing.T) {
+	groups := []activityGroup{
+		{
+			DateLabel: "Jan 2, 2025",
+			Items: []activityItem{
+				{Kind: activityKindReview, ActorName: screenjournal.Username("mike")},
+				{Kind: activityKindComment, ActorName: screenjournal.Username("jamie")},
+			},
+		},
+		{
+			DateLabel: "Jan 1, 2025",
+			Items: []activityItem{
+				{Kind: activityKindReaction, ActorName: screenjournal.Username("joe")},
+			},
+		},
+	}
+
+	filtered := filterActivityGroups(groups, func(actor screenjournal.Username) bool {
+		return actor.Equal(screenjournal.Username("jamie"))
+	})
+	if got, want := len(filtered), 1; got != want {
+		t.Fatalf("expected %d group, got %d", want, got)
+	}
+	if got, want := filtered[0].DateLabel, "Jan 2, 2025"; got != want {
+		t.Errorf("unexpected date label: got %q want %q", got, want)
+	}
+	if got, want := len(filtered[0].Items), 1; got != want {
+		t.Fatalf("expected %d activity item, got %d", want, got)
+	}
+	if got, want := filtered[0].Items[0].ActorName, screenjournal.Username("jamie"); got != want {
+		t.Errorf("unexpected actor: got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+type adminUserRowProps struct {
+	User screenjournal.UserAccountMeta
+	// IsSelf is true if the row is the logged-in admin's own account, which
+	// they can't demote or disable.
+	IsSelf bool
+}
+
+var adminUserRowFns = template.FuncMap{
+	"formatTime": formatIso8601Datetime,
+}
+
+func (s Server) adminUsersGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			Funcs(adminUserRowFns).
+			ParseFS(
+				templatesFS,
+				append(baseTemplates,
+					"templates/fragments/admin-user-row.html",
+					"templates/pages/admin-users.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		users, err := s.store.ReadUsersAccountMeta()
+		if err != nil {
+			log.Printf("failed to read user accounts: %v", err)
+			http.Error(w, "Failed to read users", http.StatusInternalServerError)
+			return
+		}
+
+		loggedInUsername := mustGetUsernameFromContext(r.Context())
+		rows := make([]adminUserRowProps, len(users))
+		for i, u := range users {
+			rows[i] = adminUserRowProps{
+				User:   u,
+				IsSelf: u.Username.Equal(loggedInUsername),
+			}
+		}
+
+		renderTemplate(w, t, "base.html", struct {
+			commonProps
+			Users []adminUserRowProps
+		}{
+			commonProps: makeCommonProps(r.Context()),
+			Users:       rows,
+		})
+	}
+}
+
+// adminUsersAdminPut promotes a user to admin or demotes them to a regular
+// member.
+func (s Server) adminUsersAdminPut() http.HandlerFunc {
+	t := template.Must(
+		template.New("admin-user-row.html").
+			Funcs(adminUserRowFns).
+			ParseFS(templatesFS, "templates/fragments/admin-user-row.html"))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+
+		isAdmin, err := strconv.ParseBool(r.PostFormValue("admin"))
+		if err != nil {
+			http.Error(w, "Invalid admin setting", http.StatusBadRequest)
+			return
+		}
+
+		if username.Equal(mustGetUsernameFromContext(r.Context())) {
+			http.Error(w, "You can't change your own admin status", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.store.UpdateUserAdmin(username, isAdmin); errors.Is(err, store.ErrUserNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("failed to update admin status for user %s: %v", username, err)
+			http.Error(w, "Failed to update admin status", http.StatusInternalServerError)
+			return
+		}
+
+		s.renderAdminUserRow(w, t, username)
+	}
+}
+
+// adminUsersDisabledPut disables or re-enables a user's account. Disabling an
+// account ends the user's sessions and stops them from logging in.
+func (s Server) adminUsersDisabledPut() http.HandlerFunc {
+	t := template.Must(
+		template.New("admin-user-row.html").
+			Funcs(adminUserRowFns).
+			ParseFS(templatesFS, "templates/fragments/admin-user-row.html"))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+
+		isDisabled, err := strconv.ParseBool(r.PostFormValue("disabled"))
+		if err != nil {
+			http.Error(w, "Invalid disabled setting", http.StatusBadRequest)
+			return
+		}
+
+		if username.Equal(mustGetUsernameFromContext(r.Context())) {
+			http.Error(w, "You can't disable your own account", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.store.UpdateUserDisabled(username, isDisabled); errors.Is(err, store.ErrUserNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("failed to update disabled status for user %s: %v", username, err)
+			http.Error(w, "Failed to update account status", http.StatusInternalServerError)
+			return
+		}
+
+		s.renderAdminUserRow(w, t, username)
+	}
+}
+
+// adminUsersPasswordResetPost emails the user a link to reset their password.
+func (s Server) adminUsersPasswordResetPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.passwordResetter == nil {
+			http.Error(w, "Password resets are not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		username, err := usernameFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+
+		user, err := s.store.ReadUser(username)
+		if errors.Is(err, store.ErrUserNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("failed to read user %s: %v", username, err)
+			http.Error(w, "Failed to read user", http.StatusInternalServerError)
+			return
+		}
+
+		if err := s.passwordResetter.SendEmail(user.Email); err != nil {
+			log.Printf("failed to send password reset email to user %s: %v", username, err)
+			http.Error(w, "Failed to send password reset email", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprintf(w, "Sent a password reset email to %s", username); err != nil {
+			log.Printf("failed to write response: %v", err)
+		}
+	}
+}
+
+func (s Server) renderAdminUserRow(w http.ResponseWriter, t *template.Template, username screenjournal.Username) {
+	user, err := s.store.ReadUserAccountMeta(username)
+	if err != nil {
+		log.Printf("failed to read account for user %s: %v", username, err)
+		http.Error(w, "Failed to read user", http.StatusInternalServerError)
+		return
+	}
+
+	renderTemplate(w, t, "admin-user-row.html", adminUserRowProps{
+		User: user,
+	})
+}
@@ -0,0 +1,272 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+type recordingPasswordResetter struct {
+	noopPasswordResetter
+	sentTo []screenjournal.Email
+}
+
+func (r *recordingPasswordResetter) SendEmail(email screenjournal.Email) error {
+	r.sentTo = append(r.sentTo, email)
+	return nil
+}
+
+func TestAdminUsersGet(t *testing.T) {
+	dataStore := test_sqlite.New()
+	insertMockUsers(t, dataStore, []screenjournal.User{userA, userB})
+	sessionManager := newMockSessionManager([]mockSessionEntry{
+		newMockSessionEntry("abc123", userA.Username),
+	})
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+	req.AddCookie(&http.Cookie{
+		Name:  mockSessionTokenName,
+		Value: "abc123",
+	})
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("httpStatus=%v, want=%v", got, want)
+	}
+	if got, want := strings.Count(rec.Body.String(), `data-testid="admin-user-row"`), 2; got != want {
+		t.Errorf("user rows=%d, want=%d", got, want)
+	}
+	// Admins can't demote or disable themselves, so only userB has those
+	// buttons.
+	if got, want := strings.Count(rec.Body.String(), `/disabled"`), 1; got != want {
+		t.Errorf("disable buttons=%d, want=%d", got, want)
+	}
+}
+
+func TestAdminUsersPut(t *testing.T) {
+	for _, tt := range []struct {
+		description    string
+		route          string
+		payload        string
+		sessionToken   string
+		status         int
+		wantIsAdmin    bool
+		wantIsDisabled bool
+	}{
+		{
+			description:  "admin promotes a user to admin",
+			route:        "/admin/users/userB/admin",
+			payload:      "admin=true",
+			sessionToken: "abc123",
+			status:       http.StatusOK,
+			wantIsAdmin:  true,
+		},
+		{
+			description:    "admin disables a user",
+			route:          "/admin/users/userB/disabled",
+			payload:        "disabled=true",
+			sessionToken:   "abc123",
+			status:         http.StatusOK,
+			wantIsDisabled: true,
+		},
+		{
+			description:  "admin re-enables a user",
+			route:        "/admin/users/userB/disabled",
+			payload:      "disabled=false",
+			sessionToken: "abc123",
+			status:       http.StatusOK,
+		},
+		{
+			description:  "rejects an invalid admin setting",
+			route:        "/admin/users/userB/admin",
+			payload:      "admin=maybe",
+			sessionToken: "abc123",
+			status:       http.StatusBadRequest,
+		},
+		{
+			description:  "rejects changes to a user that doesn't exist",
+			route:        "/admin/users/nobody/admin",
+			payload:      "admin=true",
+			sessionToken: "abc123",
+			status:       http.StatusNotFound,
+		},
+		{
+			description:  "rejects changes from a non-admin",
+			route:        "/admin/users/userB/admin",
+			payload:      "admin=true",
+			sessionToken: "def456",
+			status:       http.StatusForbidden,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			insertMockUsers(t, dataStore, []screenjournal.User{userA, userB})
+			sessionManager := newMockSessionManager([]mockSessionEntry{
+				newMockSessionEntry("abc123", userA.Username),
+				newMockSessionEntry("def456", userB.Username),
+			})
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req := httptest.NewRequest(http.MethodPut, tt.route, strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: tt.sessionToken,
+			})
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+
+			if got, want := rec.Code, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			user, err := dataStore.ReadUser(userB.Username)
+			if err != nil {
+				t.Fatalf("failed to read user: %v", err)
+			}
+			if got, want := user.IsAdmin, tt.wantIsAdmin; got != want {
+				t.Errorf("IsAdmin=%v, want=%v", got, want)
+			}
+			if got, want := user.IsDisabled, tt.wantIsDisabled; got != want {
+				t.Errorf("IsDisabled=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestAdminUsersPutRejectsChangesToSelf(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		route       string
+		payload     string
+	}{
+		{
+			description: "admin can't demote themselves",
+			route:       "/admin/users/userA/admin",
+			payload:     "admin=false",
+		},
+		{
+			description: "admin can't disable themselves",
+			route:       "/admin/users/userA/disabled",
+			payload:     "disabled=true",
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			insertMockUsers(t, dataStore, []screenjournal.User{userA})
+			sessionManager := newMockSessionManager([]mockSessionEntry{
+				newMockSessionEntry("abc123", userA.Username),
+			})
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req := httptest.NewRequest(http.MethodPut, tt.route, strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: "abc123",
+			})
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+
+			if got, want := rec.Code, http.StatusBadRequest; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			user, err := dataStore.ReadUser(userA.Username)
+			if err != nil {
+				t.Fatalf("failed to read user: %v", err)
+			}
+			if !user.IsAdmin || user.IsDisabled {
+				t.Errorf("IsAdmin=%v, IsDisabled=%v, want IsAdmin=true, IsDisabled=false", user.IsAdmin, user.IsDisabled)
+			}
+		})
+	}
+}
+
+func TestDisabledUserCantLogIn(t *testing.T) {
+	dataStore := test_sqlite.New()
+	insertMockUsers(t, dataStore, []screenjournal.User{userA, userB})
+	if err := dataStore.UpdateUserDisabled(userB.Username, true); err != nil {
+		t.Fatalf("failed to disable user: %v", err)
+	}
+	sessionManager := newMockSessionManager([]mockSessionEntry{
+		newMockSessionEntry("def456", userB.Username),
+	})
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth", strings.NewReader(`{"username": "userB", "password": "p@ssw0rd123"}`))
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+	if got, want := rec.Code, http.StatusForbidden; got != want {
+		t.Errorf("login httpStatus=%v, want=%v", got, want)
+	}
+
+	// A session that predates the user being disabled no longer works.
+	req = httptest.NewRequest(http.MethodPost, "/notifications/read", nil)
+	req.AddCookie(&http.Cookie{
+		Name:  mockSessionTokenName,
+		Value: "def456",
+	})
+	rec = httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+	if got, want := rec.Code, http.StatusUnauthorized; got != want {
+		t.Errorf("existing session httpStatus=%v, want=%v", got, want)
+	}
+}
+
+func TestAdminUsersPasswordResetPost(t *testing.T) {
+	dataStore := test_sqlite.New()
+	insertMockUsers(t, dataStore, []screenjournal.User{userA, userB})
+	sessionManager := newMockSessionManager([]mockSessionEntry{
+		newMockSessionEntry("abc123", userA.Username),
+	})
+	resetter := &recordingPasswordResetter{}
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:    auth.New(dataStore),
+		SessionManager:   &sessionManager,
+		Store:            dataStore,
+		PasswordResetter: resetter,
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/userB/password-reset", nil)
+	req.AddCookie(&http.Cookie{
+		Name:  mockSessionTokenName,
+		Value: "abc123",
+	})
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("httpStatus=%v, want=%v", got, want)
+	}
+	if got, want := len(resetter.sentTo), 1; got != want {
+		t.Fatalf("emails sent=%d, want=%d", got, want)
+	}
+	if got, want := resetter.sentTo[0], userB.Email; got != want {
+		t.Errorf("sent to=%v, want=%v", got, want)
+	}
+}
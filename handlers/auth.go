@@ -6,8 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 
 	simple_sessions "codeberg.org/mtlynch/simpleauth/v3/sessions"
 
@@ -22,8 +26,9 @@ type (
 	}
 
 	authenticatedSession struct {
-		Username screenjournal.Username
-		IsAdmin  bool
+		Username            screenjournal.Username
+		IsAdmin             bool
+		UnreadNotifications int
 	}
 )
 
@@ -38,25 +43,84 @@ func (s Server) authPost() http.HandlerFunc {
 			return
 		}
 
+		ip := clientIPFromRequest(r)
+		if s.loginLimiter != nil {
+			// Hold the lock until this attempt is recorded. Otherwise, parallel
+			// guesses could all pass the check before any of them count as a
+			// failure.
+			unlock := s.loginLimiter.Lock(username, ip)
+			defer unlock()
+
+			wait, err := s.loginLimiter.RetryAfter(username, ip)
+			if err != nil {
+				log.Printf("failed to check login limits for user %s: %v", username, err)
+				http.Error(w, "Failed to check login limits", http.StatusInternalServerError)
+				return
+			}
+			if wait > 0 {
+				log.Printf("rejecting login for user %s from %s for another %v", username, ip, wait)
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, fmt.Sprintf("Too many failed login attempts. Try again in %s.", describeWait(wait)), http.StatusTooManyRequests)
+				return
+			}
+		}
+
 		if err := s.authenticator.Authenticate(username, password); err != nil {
 			log.Printf("auth failed for user %s: %v", username, err)
+			if s.loginLimiter != nil {
+				if err := s.loginLimiter.RecordFailure(username, ip); err != nil {
+					log.Printf("failed to record failed login for user %s: %v", username, err)
+				}
+			}
 			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
 			return
 		}
 
-		userID, err := userIDFromUsername(username)
-		if err != nil {
-			log.Printf("failed to create user ID for user %s: %v", username.String(), err)
-			http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		if s.loginLimiter != nil {
+			if err := s.loginLimiter.RecordSuccess(username); err != nil {
+				log.Printf("failed to clear failed logins for user %s: %v", username, err)
+			}
+		}
+
+		// Check before asking for a second factor so that disabled users don't
+		// get a code prompt they can never get past.
+		if !s.checkAccountEnabled(w, username) {
 			return
 		}
 
-		if err := s.sessionManager.LogIn(r.Context(), w, userID); err != nil {
-			log.Printf("failed to create session for user %s: %v", username.String(), err)
+		twoFactorEnabled, err := s.isTwoFactorEnabled(username)
+		if err != nil {
+			log.Printf("failed to read two-factor configuration for user %s: %v", username, err)
 			http.Error(w, "Failed to create session", http.StatusInternalServerError)
 			return
 		}
+		if twoFactorEnabled {
+			s.requireSecondFactor(w, username)
+			return
+		}
+
+		s.logIn(w, r, username)
+	}
+}
+
+// logIn creates a session for a user who has passed every authentication
+// step.
+func (s Server) logIn(w http.ResponseWriter, r *http.Request, username screenjournal.Username) {
+	if !s.checkAccountEnabled(w, username) {
+		return
+	}
+
+	userID, err := userIDFromUsername(username)
+	if err != nil {
+		log.Printf("failed to create user ID for user %s: %v", username.String(), err)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
 
+	if err := s.sessionManager.LogIn(r.Context(), w, userID); err != nil {
+		log.Printf("failed to create session for user %s: %v", username.String(), err)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
 	}
 }
 
@@ -103,9 +167,29 @@ func (s Server) populateAuthenticationContext(next http.Handler) http.Handler {
 			return
 		}
 
+		// Disabling a user ends their sessions, but don't trust a session that
+		// survived.
+		if user.IsDisabled {
+			if err := s.sessionManager.LogOut(r.Context(), w); err != nil {
+				log.Printf("failed to end session for disabled user: %v", err)
+				http.Error(w, "Failed to end session", http.StatusInternalServerError)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		unread, err := s.store.CountUnreadNotifications(user.Username)
+		if err != nil {
+			log.Printf("failed to count unread notifications for %s: %v", user.Username, err)
+			http.Error(w, "Failed to read notifications", http.StatusInternalServerError)
+			return
+		}
+
 		session := authenticatedSession{
-			Username: user.Username,
-			IsAdmin:  user.IsAdmin,
+			Username:            user.Username,
+			IsAdmin:             user.IsAdmin,
+			UnreadNotifications: unread,
 		}
 		ctx := context.WithValue(r.Context(), contextKeySession, session)
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -163,6 +247,23 @@ func (s Server) requireAdmin(next http.Handler) http.Handler {
 	})
 }
 
+// checkAccountEnabled responds with an error and returns false if an admin has
+// disabled the user's account.
+func (s Server) checkAccountEnabled(w http.ResponseWriter, username screenjournal.Username) bool {
+	user, err := s.store.ReadUser(username)
+	if err != nil {
+		log.Printf("failed to read user %s: %v", username, err)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return false
+	}
+	if user.IsDisabled {
+		log.Printf("rejecting login for disabled user %s", username)
+		http.Error(w, "This account has been disabled. Contact an admin for help.", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
 func credentialsFromRequest(r *http.Request) (screenjournal.Username, screenjournal.Password, error) {
 	body := struct {
 		Username string `json:"username"`
@@ -187,6 +288,33 @@ func credentialsFromRequest(r *http.Request) (screenjournal.Username, screenjour
 	return username, password, nil
 }
 
+// clientIPFromRequest returns the IP address of the client. If the server runs
+// behind a proxy, the proxy middleware has already replaced RemoteAddr with
+// the client's address, which might not include a port.
+func clientIPFromRequest(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// describeWait rounds a wait time up to a human-friendly duration.
+func describeWait(wait time.Duration) string {
+	if wait <= time.Minute {
+		seconds := int(math.Ceil(wait.Seconds()))
+		if seconds == 1 {
+			return "1 second"
+		}
+		return fmt.Sprintf("%d seconds", seconds)
+	}
+	minutes := int(math.Ceil(wait.Minutes()))
+	if minutes == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
+
 func userIDFromUsername(username screenjournal.Username) (simple_sessions.UserID, error) {
 	return simple_sessions.NewUserID(username.String())
 }
@@ -204,6 +332,15 @@ func isAdmin(ctx context.Context) bool {
 	return sess.IsAdmin
 }
 
+func unreadNotificationCount(ctx context.Context) int {
+	sess, ok := sessionFromContext(ctx)
+	if !ok {
+		return 0
+	}
+
+	return sess.UnreadNotifications
+}
+
 func isAuthenticated(ctx context.Context) bool {
 	_, ok := sessionFromContext(ctx)
 	return ok
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/markdown"
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
 	"github.com/mtlynch/screenjournal/v2/store"
 )
@@ -15,6 +16,7 @@ import (
 type commentPostRequest struct {
 	ReviewID    screenjournal.ReviewID
 	CommentID   *screenjournal.CommentID
+	ParentID    screenjournal.CommentID
 	CommentText screenjournal.CommentText
 }
 
@@ -34,6 +36,13 @@ func (s Server) commentsAddGet() http.HandlerFunc {
 			return
 		}
 
+		// Replies don't have their own button, so cancelling a reply just clears
+		// the reply form.
+		if _, err := parentCommentIDFromQueryParams(r); err == nil {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
 		renderTemplate(w, t, "add-comment-button", struct {
 			ID screenjournal.ReviewID
 		}{
@@ -77,13 +86,20 @@ func (s Server) commentsEditGet() http.HandlerFunc {
 			commentText = *pCommentText
 		}
 
+		var parentID screenjournal.CommentID
+		if id, err := parentCommentIDFromQueryParams(r); err == nil {
+			parentID = id
+		}
+
 		renderTemplate(w, t, "comments-edit.html", struct {
 			ReviewID    screenjournal.ReviewID
 			CommentID   screenjournal.CommentID
+			ParentID    screenjournal.CommentID
 			CommentText screenjournal.CommentText
 		}{
 			ReviewID:    reviewID,
 			CommentID:   commentID,
+			ParentID:    parentID,
 			CommentText: commentText,
 		})
 	}
@@ -110,12 +126,13 @@ func (s Server) commentsGet() http.HandlerFunc {
 			return
 		}
 
+		loggedInUsername := mustGetUsernameFromContext(r.Context())
 		renderTemplate(w, t, "comment", struct {
-			Comment          screenjournal.ReviewComment
+			Thread           commentThread
 			LoggedInUsername screenjournal.Username
 		}{
-			Comment:          rc,
-			LoggedInUsername: mustGetUsernameFromContext(r.Context()),
+			Thread:           newCommentThread(rc, loggedInUsername, isAdmin(r.Context())),
+			LoggedInUsername: loggedInUsername,
 		})
 	}
 }
@@ -141,7 +158,24 @@ func (s Server) commentsPost() http.HandlerFunc {
 			return
 		}
 
+		if !req.ParentID.IsZero() {
+			parent, err := s.store.ReadComment(req.ParentID)
+			if err == store.ErrCommentNotFound {
+				http.Error(w, "Parent comment not found", http.StatusBadRequest)
+				return
+			} else if err != nil {
+				log.Printf("failed to read parent comment: %v", err)
+				http.Error(w, fmt.Sprintf("Failed to read parent comment: %v", err), http.StatusInternalServerError)
+				return
+			}
+			if parent.Review.ID != review.ID {
+				http.Error(w, "Parent comment belongs to a different review", http.StatusBadRequest)
+				return
+			}
+		}
+
 		rc := screenjournal.ReviewComment{
+			ParentID:    req.ParentID,
 			Review:      review,
 			Owner:       mustGetUsernameFromContext(r.Context()),
 			CommentText: req.CommentText,
@@ -157,17 +191,19 @@ func (s Server) commentsPost() http.HandlerFunc {
 		// the correct creation time.
 		rc.Created = time.Now()
 
-		if !renderTemplate(w, t, "comment", struct {
-			Comment          screenjournal.ReviewComment
+		loggedInUsername := mustGetUsernameFromContext(r.Context())
+		if !renderTemplate(w, t, "comment-thread", struct {
+			Thread           commentThread
 			LoggedInUsername screenjournal.Username
 		}{
-			Comment:          rc,
-			LoggedInUsername: mustGetUsernameFromContext(r.Context()),
+			Thread:           newCommentThread(rc, loggedInUsername, isAdmin(r.Context())),
+			LoggedInUsername: loggedInUsername,
 		}) {
 			return
 		}
 
 		s.announcer.AnnounceNewComment(rc)
+		s.announceMentions(rc.Owner, markdown.CommentMentions(rc.CommentText), nil, review, rc)
 	}
 }
 
@@ -198,6 +234,7 @@ func (s Server) commentsPut() http.HandlerFunc {
 			return
 		}
 
+		previousMentions := markdown.CommentMentions(rc.CommentText)
 		rc.CommentText = req.CommentText
 		if err := s.store.UpdateComment(rc); err != nil {
 			log.Printf("failed to update comment: %v", err)
@@ -205,13 +242,18 @@ func (s Server) commentsPut() http.HandlerFunc {
 			return
 		}
 
-		renderTemplate(w, t, "comment", struct {
-			Comment          screenjournal.ReviewComment
+		loggedInUsername := mustGetUsernameFromContext(r.Context())
+		if !renderTemplate(w, t, "comment", struct {
+			Thread           commentThread
 			LoggedInUsername screenjournal.Username
 		}{
-			Comment:          rc,
-			LoggedInUsername: mustGetUsernameFromContext(r.Context()),
-		})
+			Thread:           newCommentThread(rc, loggedInUsername, isAdmin(r.Context())),
+			LoggedInUsername: loggedInUsername,
+		}) {
+			return
+		}
+
+		s.announceMentions(rc.Owner, markdown.CommentMentions(rc.CommentText), previousMentions, rc.Review, rc)
 	}
 }
 
@@ -264,6 +306,14 @@ func parseCommentPostRequest(r *http.Request) (commentPostRequest, error) {
 		pCid = &cid
 	}
 
+	var parentID screenjournal.CommentID
+	if raw := r.PostFormValue("parent-comment-id"); raw != "" {
+		parentID, err = parse.CommentID(raw)
+		if err != nil {
+			return commentPostRequest{}, err
+		}
+	}
+
 	comment, err := parse.CommentText(r.PostFormValue("comment"))
 	if err != nil {
 		return commentPostRequest{}, err
@@ -272,6 +322,7 @@ func parseCommentPostRequest(r *http.Request) (commentPostRequest, error) {
 	return commentPostRequest{
 		ReviewID:    rid,
 		CommentID:   pCid,
+		ParentID:    parentID,
 		CommentText: comment,
 	}, nil
 }
@@ -60,6 +60,7 @@ func TestCommentsPost(t *testing.T) {
 		sessions         []mockSessionEntry
 		movies           []screenjournal.Movie
 		reviews          []screenjournal.Review
+		comments         []screenjournal.ReviewComment
 		status           int
 		expectedComments []screenjournal.ReviewComment
 	}{
@@ -87,6 +88,76 @@ func TestCommentsPost(t *testing.T) {
 				},
 			},
 		},
+		{
+			description:  "allows user to reply to an existing comment",
+			payload:      "review-id=1&parent-comment-id=1&comment=Thanks!",
+			sessionToken: makeCommentsTestData().sessions.userB.token,
+			sessions: []mockSessionEntry{
+				makeCommentsTestData().sessions.userA,
+				makeCommentsTestData().sessions.userB,
+			},
+			movies: []screenjournal.Movie{
+				makeCommentsTestData().movies.theWaterBoy,
+			},
+			reviews: []screenjournal.Review{
+				makeCommentsTestData().reviews.userBTheWaterBoy,
+			},
+			comments: []screenjournal.ReviewComment{
+				{
+					Owner:       makeCommentsTestData().sessions.userA.session.Username,
+					CommentText: screenjournal.CommentText("Good insights!"),
+					Review:      makeCommentsTestData().reviews.userBTheWaterBoy,
+				},
+			},
+			status: http.StatusOK,
+			expectedComments: []screenjournal.ReviewComment{
+				{
+					ID:          screenjournal.CommentID(1),
+					Owner:       makeCommentsTestData().sessions.userA.session.Username,
+					CommentText: screenjournal.CommentText("Good insights!"),
+					Review:      makeCommentsTestData().reviews.userBTheWaterBoy,
+				},
+				{
+					ID:          screenjournal.CommentID(2),
+					ParentID:    screenjournal.CommentID(1),
+					Owner:       makeCommentsTestData().sessions.userB.session.Username,
+					CommentText: screenjournal.CommentText("Thanks!"),
+					Review:      makeCommentsTestData().reviews.userBTheWaterBoy,
+				},
+			},
+		},
+		{
+			description:  "rejects a reply to a non-existent comment",
+			payload:      "review-id=1&parent-comment-id=999&comment=Thanks!",
+			sessionToken: makeCommentsTestData().sessions.userB.token,
+			sessions: []mockSessionEntry{
+				makeCommentsTestData().sessions.userA,
+				makeCommentsTestData().sessions.userB,
+			},
+			movies: []screenjournal.Movie{
+				makeCommentsTestData().movies.theWaterBoy,
+			},
+			reviews: []screenjournal.Review{
+				makeCommentsTestData().reviews.userBTheWaterBoy,
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			description:  "rejects an invalid parent comment ID",
+			payload:      "review-id=1&parent-comment-id=banana&comment=Thanks!",
+			sessionToken: makeCommentsTestData().sessions.userB.token,
+			sessions: []mockSessionEntry{
+				makeCommentsTestData().sessions.userA,
+				makeCommentsTestData().sessions.userB,
+			},
+			movies: []screenjournal.Movie{
+				makeCommentsTestData().movies.theWaterBoy,
+			},
+			reviews: []screenjournal.Review{
+				makeCommentsTestData().reviews.userBTheWaterBoy,
+			},
+			status: http.StatusBadRequest,
+		},
 		{
 			description:  "trims leading and trailing whitespace from a review comment",
 			payload:      "review-id=1&comment=%0AYes%2C%20but%20can%20you%20strip%20my%20whitespace%3F%0A",
@@ -201,6 +272,11 @@ func TestCommentsPost(t *testing.T) {
 					t.Fatalf("failed to insert mock review: %+v: %v", review, err)
 				}
 			}
+			for _, comment := range tt.comments {
+				if _, err := dataStore.InsertComment(comment); err != nil {
+					t.Fatalf("failed to insert mock comment: %+v: %v", comment, err)
+				}
+			}
 
 			announcer := mockAnnouncer{}
 			authenticator := auth.New(dataStore)
@@ -246,10 +322,11 @@ func TestCommentsPost(t *testing.T) {
 				t.Fatalf("commentCountAnnounced=%d, want=%d", got, want)
 			}
 
+			newComment := tt.expectedComments[len(tt.expectedComments)-1]
 			clearUnpredictableCommentProperties(&announcer.announcedComments[0])
-			clearUnpredictableCommentProperties(&tt.expectedComments[0])
-			if !reflect.DeepEqual(announcer.announcedComments, tt.expectedComments) {
-				t.Errorf("did not find expected announced comments: %v", deep.Equal(announcer.announcedComments, tt.expectedComments))
+			clearUnpredictableCommentProperties(&newComment)
+			if got, want := announcer.announcedComments[0], newComment; !reflect.DeepEqual(got, want) {
+				t.Errorf("did not find expected announced comment: %v", deep.Equal(got, want))
 			}
 		})
 	}
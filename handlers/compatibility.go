@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/mtlynch/screenjournal/v2/compatibility"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+type compatibilityMatrix struct {
+	Columns []screenjournal.Username
+	Rows    []compatibilityMatrixRow
+	SortBy  screenjournal.Username
+}
+
+type compatibilityMatrixRow struct {
+	Username screenjournal.Username
+	// Cells holds the row user's compatibility with each user in the matrix's
+	// columns, in the same order.
+	Cells []screenjournal.Compatibility
+}
+
+var compatibilityFns = template.FuncMap{
+	"compatibilityURL": compatibilityURL,
+	"formatCorrelation": func(c *float64) string {
+		if c == nil {
+			return "n/a"
+		}
+		return fmt.Sprintf("%.2f", *c)
+	},
+}
+
+func (s Server) compatibilityGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			Funcs(compatibilityFns).
+			Funcs(template.FuncMap{
+				"ratingToStars":    ratingToStars,
+				"reviewMediaTitle": reviewMediaTitle,
+				"reviewTargetURL": func(review screenjournal.Review) string {
+					return reviewTargetURL(review, review.ID)
+				},
+			}).
+			ParseFS(
+				templatesFS,
+				append(baseTemplates, "templates/pages/compatibility.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequestPath(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid username: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		otherUsername, err := otherUsernameFromRequestPath(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid username: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		for _, u := range []screenjournal.Username{username, otherUsername} {
+			if _, err := s.store.ReadUser(u); err != nil {
+				if errors.Is(err, store.ErrUserNotFound) {
+					http.Error(w, "User not found", http.StatusNotFound)
+					return
+				}
+				log.Printf("failed to read user %s: %v", u, err)
+				http.Error(w, "Failed to read user", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		c, err := s.readCompatibility(username, otherUsername)
+		if err != nil {
+			log.Printf("failed to calculate compatibility between %s and %s: %v", username, otherUsername, err)
+			http.Error(w, "Failed to calculate compatibility", http.StatusInternalServerError)
+			return
+		}
+
+		renderTemplate(w, t, "base.html", struct {
+			commonProps
+			Compatibility screenjournal.Compatibility
+		}{
+			commonProps:   makeCommonProps(r.Context()),
+			Compatibility: c,
+		})
+	}
+}
+
+// readCompatibility calculates the compatibility between two users from their
+// reviews.
+func (s Server) readCompatibility(username, otherUsername screenjournal.Username) (screenjournal.Compatibility, error) {
+	reviews := []screenjournal.Review{}
+	for _, u := range []screenjournal.Username{username, otherUsername} {
+		rr, err := s.store.ReadReviews(store.FilterReviewsByUsername(u))
+		if err != nil {
+			return screenjournal.Compatibility{}, err
+		}
+		reviews = append(reviews, rr...)
+	}
+
+	return compatibility.Compare(username, otherUsername, reviews), nil
+}
+
+// buildCompatibilityMatrix compares every pair of users. If sortBy is the name
+// of one of the users, rows are ordered from most to least compatible with
+// that user. Otherwise, rows are in the same order as users.
+func buildCompatibilityMatrix(users []screenjournal.Username, reviews []screenjournal.Review, sortBy screenjournal.Username) compatibilityMatrix {
+	m := compatibilityMatrix{
+		Columns: users,
+		Rows:    make([]compatibilityMatrixRow, len(users)),
+	}
+
+	sortColumn := -1
+	for i, u := range users {
+		if u.Equal(sortBy) {
+			sortColumn = i
+			m.SortBy = sortBy
+		}
+		m.Rows[i] = compatibilityMatrixRow{
+			Username: u,
+			Cells:    make([]screenjournal.Compatibility, len(users)),
+		}
+	}
+
+	for i, u := range users {
+		for j, other := range users {
+			if i == j {
+				m.Rows[i].Cells[j] = screenjournal.Compatibility{Username: u, OtherUsername: other}
+				continue
+			}
+			m.Rows[i].Cells[j] = compatibility.Compare(u, other, reviews)
+		}
+	}
+
+	if sortColumn < 0 {
+		return m
+	}
+
+	slices.SortStableFunc(m.Rows, func(a, b compatibilityMatrixRow) int {
+		// Keep the user the matrix is sorted by at the top.
+		if a.Username.Equal(sortBy) {
+			return -1
+		}
+		if b.Username.Equal(sortBy) {
+			return 1
+		}
+		ca, cb := a.Cells[sortColumn], b.Cells[sortColumn]
+		// Users with nothing in common sort to the bottom.
+		if ca.Empty() != cb.Empty() {
+			if ca.Empty() {
+				return 1
+			}
+			return -1
+		}
+		return cb.AgreementPercent - ca.AgreementPercent
+	})
+
+	return m
+}
+
+func compatibilityURL(username, otherUsername screenjournal.Username) string {
+	return fmt.Sprintf("/users/%s/compatibility/%s", username, otherUsername)
+}
@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestCompatibilityViews(t *testing.T) {
+	td := makeReactionsTestData()
+	userAReview := td.reviews.userBTheWaterBoy
+	userAReview.ID = screenjournal.ReviewID(2)
+	userAReview.Owner = td.sessions.userA.session.Username
+	userAReview.Rating = screenjournal.NewRating(8)
+
+	for _, tt := range []struct {
+		description  string
+		route        string
+		status       int
+		expectedText string
+	}{
+		{
+			description:  "shows compatibility on another user's reviews page",
+			route:        "/reviews/by/userB",
+			status:       http.StatusOK,
+			expectedText: "<b>67%</b>",
+		},
+		{
+			description:  "shows compatibility matrix on users page",
+			route:        "/users?sortBy=userB",
+			status:       http.StatusOK,
+			expectedText: ">67%</a",
+		},
+		{
+			description: "rejects invalid matrix sort",
+			route:       "/users?sortBy=bad%20name",
+			status:      http.StatusBadRequest,
+		},
+		{
+			description:  "shows shared titles between two users",
+			route:        "/users/userA/compatibility/userB",
+			status:       http.StatusOK,
+			expectedText: "The Waterboy",
+		},
+		{
+			description: "returns 404 when comparing with non-existent user",
+			route:       "/users/userA/compatibility/nobody",
+			status:      http.StatusNotFound,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			sessions := []mockSessionEntry{td.sessions.userA, td.sessions.userB}
+			insertMockUsersForSessions(t, dataStore, sessions)
+			if _, err := dataStore.InsertMovie(td.movies.theWaterBoy); err != nil {
+				t.Fatalf("failed to insert mock movie: %v", err)
+			}
+			for _, review := range []screenjournal.Review{td.reviews.userBTheWaterBoy, userAReview} {
+				if _, err := dataStore.InsertReview(review); err != nil {
+					t.Fatalf("failed to insert mock review: %v", err)
+				}
+			}
+
+			sessionManager := newMockSessionManager(sessions)
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req, err := http.NewRequest("GET", tt.route, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: td.sessions.userA.token,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+			res := rec.Result()
+
+			if got, want := res.StatusCode, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatalf("failed to read response body: %v", err)
+			}
+			if !strings.Contains(string(body), tt.expectedText) {
+				t.Errorf("response body doesn't contain %q", tt.expectedText)
+			}
+		})
+	}
+}
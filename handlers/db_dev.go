@@ -28,22 +28,25 @@ func (s *Server) addDevRoutes() {
 func (s Server) populateDummyData() http.HandlerFunc {
 	users := []screenjournal.User{
 		{
-			Username:     screenjournal.Username("dummyadmin"),
-			PasswordHash: mustCreatePasswordHash("dummypass"),
-			IsAdmin:      true,
-			Email:        screenjournal.Email("dummyadmin@example.com"),
+			Username:      screenjournal.Username("dummyadmin"),
+			PasswordHash:  mustCreatePasswordHash("dummypass"),
+			IsAdmin:       true,
+			Email:         screenjournal.Email("dummyadmin@example.com"),
+			EmailVerified: true,
 		},
 		{
-			Username:     screenjournal.Username("userA"),
-			PasswordHash: mustCreatePasswordHash("password123"),
-			IsAdmin:      false,
-			Email:        screenjournal.Email("userA@example.com"),
+			Username:      screenjournal.Username("userA"),
+			PasswordHash:  mustCreatePasswordHash("password123"),
+			IsAdmin:       false,
+			Email:         screenjournal.Email("userA@example.com"),
+			EmailVerified: true,
 		},
 		{
-			Username:     screenjournal.Username("userB"),
-			PasswordHash: mustCreatePasswordHash("password456"),
-			IsAdmin:      false,
-			Email:        screenjournal.Email("userB@example.com"),
+			Username:      screenjournal.Username("userB"),
+			PasswordHash:  mustCreatePasswordHash("password456"),
+			IsAdmin:       false,
+			Email:         screenjournal.Email("userB@example.com"),
+			EmailVerified: true,
 		},
 	}
 	movies := []screenjournal.Movie{
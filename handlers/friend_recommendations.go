@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+type (
+	friendRecommendationPostRequest struct {
+		Recipient    screenjournal.Username
+		MovieID      screenjournal.MovieID
+		TvShowID     screenjournal.TvShowID
+		TvShowSeason screenjournal.TvShowSeason
+		Message      screenjournal.RecommendationMessage
+	}
+
+	friendRecommendationViewModel struct {
+		screenjournal.FriendRecommendation
+		TitleText string
+		TitleURL  string
+		// RecipientReview is the recipient's review of the recommended title,
+		// if they've written one.
+		RecipientReview *screenjournal.Review
+		NewReviewURL    string
+	}
+)
+
+func (s Server) friendRecommendationsGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			Funcs(template.FuncMap{
+				"formatDate": formatActivityDate,
+				"reviewTargetURL": func(review screenjournal.Review) string {
+					return reviewTargetURL(review, review.ID)
+				},
+			}).
+			ParseFS(
+				templatesFS,
+				append(baseTemplates, "templates/pages/friend-recommendations.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := mustGetUsernameFromContext(r.Context())
+		frs, err := s.store.ReadFriendRecommendationsTo(username)
+		if err != nil {
+			log.Printf("failed to read recommendations for %s: %v", username, err)
+			http.Error(w, "Failed to read recommendations", http.StatusInternalServerError)
+			return
+		}
+
+		reviews, err := s.store.ReadReviews(store.FilterReviewsByUsername(username))
+		if err != nil {
+			log.Printf("failed to read reviews: %v", err)
+			http.Error(w, "Failed to read reviews", http.StatusInternalServerError)
+			return
+		}
+		reviewsByTitle := map[string]screenjournal.Review{}
+		for _, review := range reviews {
+			reviewsByTitle[review.TitleKey()] = review
+		}
+
+		pending := []friendRecommendationViewModel{}
+		resolved := []friendRecommendationViewModel{}
+		for _, fr := range frs {
+			vm := makeFriendRecommendationViewModel(fr)
+			if review, ok := reviewsByTitle[friendRecommendationTitle(fr).TitleKey()]; ok {
+				vm.RecipientReview = &review
+			}
+			if fr.Status == screenjournal.FriendRecommendationPending {
+				pending = append(pending, vm)
+			} else {
+				resolved = append(resolved, vm)
+			}
+		}
+
+		renderTemplate(w, t, "base.html", struct {
+			commonProps
+			Pending  []friendRecommendationViewModel
+			Resolved []friendRecommendationViewModel
+		}{
+			commonProps: makeCommonProps(r.Context()),
+			Pending:     pending,
+			Resolved:    resolved,
+		})
+	}
+}
+
+func (s Server) friendRecommendationsPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := parseFriendRecommendationPostRequest(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		sender := mustGetUsernameFromContext(r.Context())
+		if req.Recipient.Equal(sender) {
+			http.Error(w, "You can't recommend a title to yourself", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := s.store.ReadUser(req.Recipient); err != nil {
+			if errors.Is(err, store.ErrUserNotFound) {
+				http.Error(w, "Recipient not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("failed to read user %s: %v", req.Recipient, err)
+			http.Error(w, "Failed to read recipient", http.StatusInternalServerError)
+			return
+		}
+
+		fr := screenjournal.FriendRecommendation{
+			From:    sender,
+			To:      req.Recipient,
+			Message: req.Message,
+			Status:  screenjournal.FriendRecommendationPending,
+		}
+		if !req.MovieID.IsZero() {
+			fr.Movie, err = s.store.ReadMovie(req.MovieID)
+			if err == store.ErrMovieNotFound {
+				http.Error(w, "Movie not found", http.StatusNotFound)
+				return
+			}
+		} else {
+			fr.TvShow, err = s.store.ReadTvShow(req.TvShowID)
+			fr.TvShowSeason = req.TvShowSeason
+			if err == store.ErrTvShowNotFound {
+				http.Error(w, "TV show not found", http.StatusNotFound)
+				return
+			}
+		}
+		if err != nil {
+			log.Printf("failed to read recommended title: %v", err)
+			http.Error(w, "Failed to read title", http.StatusInternalServerError)
+			return
+		}
+
+		fr.ID, err = s.store.InsertFriendRecommendation(fr)
+		if err != nil {
+			log.Printf("failed to save recommendation: %v", err)
+			http.Error(w, "Failed to save recommendation", http.StatusInternalServerError)
+			return
+		}
+
+		s.announcer.AnnounceNewFriendRecommendation(fr)
+
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprintf(w, "Recommended to %s", fr.To); err != nil {
+			log.Printf("failed to write response: %v", err)
+		}
+	}
+}
+
+func (s Server) friendRecommendationsPut() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parse.FriendRecommendationID(mux.Vars(r)["recommendationID"])
+		if err != nil {
+			http.Error(w, "Invalid recommendation ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			log.Printf("failed to decode recommendation PUT request: %v", err)
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		status, err := parse.FriendRecommendationStatus(r.PostFormValue("status"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		fr, err := s.store.ReadFriendRecommendation(id)
+		if err == store.ErrFriendRecommendationNotFound {
+			http.Error(w, "Recommendation not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("failed to read recommendation: %v", err)
+			http.Error(w, "Failed to read recommendation", http.StatusInternalServerError)
+			return
+		}
+
+		if !fr.To.Equal(mustGetUsernameFromContext(r.Context())) {
+			http.Error(w, "Can't update another user's recommendation", http.StatusForbidden)
+			return
+		}
+
+		if err := s.store.UpdateFriendRecommendationStatus(id, status); err != nil {
+			log.Printf("failed to update recommendation: %v", err)
+			http.Error(w, "Failed to update recommendation", http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, r, "/friend-recommendations", http.StatusSeeOther)
+	}
+}
+
+// readFriendRecommendationRecipients returns every user that the given user
+// can recommend a title to.
+func (s Server) readFriendRecommendationRecipients(sender screenjournal.Username) ([]screenjournal.Username, error) {
+	users, err := s.store.ReadUsersPublicMeta()
+	if err != nil {
+		return []screenjournal.Username{}, err
+	}
+
+	recipients := []screenjournal.Username{}
+	for _, u := range users {
+		if !u.Username.Equal(sender) {
+			recipients = append(recipients, u.Username)
+		}
+	}
+	return recipients, nil
+}
+
+func parseFriendRecommendationPostRequest(r *http.Request) (friendRecommendationPostRequest, error) {
+	if err := r.ParseForm(); err != nil {
+		log.Printf("failed to decode recommendation POST request: %v", err)
+		return friendRecommendationPostRequest{}, err
+	}
+
+	recipient, err := parse.Username(r.PostFormValue("recipient"))
+	if err != nil {
+		return friendRecommendationPostRequest{}, err
+	}
+
+	message, err := parse.RecommendationMessage(r.PostFormValue("message"))
+	if err != nil {
+		return friendRecommendationPostRequest{}, err
+	}
+
+	req := friendRecommendationPostRequest{
+		Recipient: recipient,
+		Message:   message,
+	}
+
+	mediaType, err := parse.MediaType(r.PostFormValue("media-type"))
+	if err != nil {
+		return friendRecommendationPostRequest{}, err
+	}
+	if mediaType == screenjournal.MediaTypeMovie {
+		if req.MovieID, err = parse.MovieIDFromString(r.PostFormValue("media-id")); err != nil {
+			return friendRecommendationPostRequest{}, err
+		}
+	} else {
+		if req.TvShowID, err = parse.TvShowIDFromString(r.PostFormValue("media-id")); err != nil {
+			return friendRecommendationPostRequest{}, err
+		}
+		if req.TvShowSeason, err = parse.TvShowSeason(r.PostFormValue("season")); err != nil {
+			return friendRecommendationPostRequest{}, err
+		}
+	}
+
+	return req, nil
+}
+
+// friendRecommendationTitle returns a stub review of the recommended title so
+// that we can reuse the helpers for building review titles and links.
+func friendRecommendationTitle(fr screenjournal.FriendRecommendation) screenjournal.Review {
+	return screenjournal.Review{
+		Movie:        fr.Movie,
+		TvShow:       fr.TvShow,
+		TvShowSeason: fr.TvShowSeason,
+	}
+}
+
+func makeFriendRecommendationViewModel(fr screenjournal.FriendRecommendation) friendRecommendationViewModel {
+	title := friendRecommendationTitle(fr)
+	newReviewURL := fmt.Sprintf("/reviews/new/write?movieId=%d", fr.Movie.ID.Int64())
+	if fr.MediaType() == screenjournal.MediaTypeTvShow {
+		newReviewURL = fmt.Sprintf("/reviews/new/write?season=%d&mediaType=%s&tmdbId=%s", fr.TvShowSeason.UInt8(), screenjournal.MediaTypeTvShow, fr.TvShow.TmdbID)
+	}
+	return friendRecommendationViewModel{
+		FriendRecommendation: fr,
+		TitleText:            reviewMediaTitle(title),
+		TitleURL:             reviewPageURL(title),
+		NewReviewURL:         newReviewURL,
+	}
+}
@@ -0,0 +1,286 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestFriendRecommendationsPost(t *testing.T) {
+	for _, tt := range []struct {
+		description     string
+		payload         string
+		sessionToken    string
+		status          int
+		expectedInbox   []screenjournal.FriendRecommendation
+		expectAnnounced bool
+	}{
+		{
+			description:  "recommends a movie to another user",
+			payload:      "recipient=userB&media-type=movie&media-id=1&message=So+good",
+			sessionToken: makeReactionsTestData().sessions.userA.token,
+			status:       http.StatusOK,
+			expectedInbox: []screenjournal.FriendRecommendation{
+				{
+					ID:      screenjournal.FriendRecommendationID(1),
+					From:    screenjournal.Username("userA"),
+					To:      screenjournal.Username("userB"),
+					Message: screenjournal.RecommendationMessage("So good"),
+					Status:  screenjournal.FriendRecommendationPending,
+				},
+			},
+			expectAnnounced: true,
+		},
+		{
+			description:   "rejects recommendation to self",
+			payload:       "recipient=userA&media-type=movie&media-id=1",
+			sessionToken:  makeReactionsTestData().sessions.userA.token,
+			status:        http.StatusBadRequest,
+			expectedInbox: []screenjournal.FriendRecommendation{},
+		},
+		{
+			description:   "rejects recommendation to non-existent user",
+			payload:       "recipient=nobody&media-type=movie&media-id=1",
+			sessionToken:  makeReactionsTestData().sessions.userA.token,
+			status:        http.StatusNotFound,
+			expectedInbox: []screenjournal.FriendRecommendation{},
+		},
+		{
+			description:   "rejects recommendation of non-existent movie",
+			payload:       "recipient=userB&media-type=movie&media-id=999",
+			sessionToken:  makeReactionsTestData().sessions.userA.token,
+			status:        http.StatusNotFound,
+			expectedInbox: []screenjournal.FriendRecommendation{},
+		},
+		{
+			description:   "rejects unauthenticated request",
+			payload:       "recipient=userB&media-type=movie&media-id=1",
+			sessionToken:  "dummy-invalid-token",
+			status:        http.StatusUnauthorized,
+			expectedInbox: []screenjournal.FriendRecommendation{},
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			td := makeReactionsTestData()
+			sessions := []mockSessionEntry{td.sessions.userA, td.sessions.userB}
+			dataStore := test_sqlite.New()
+			insertMockUsersForSessions(t, dataStore, sessions)
+			if _, err := dataStore.InsertMovie(td.movies.theWaterBoy); err != nil {
+				t.Fatalf("failed to insert mock movie: %v", err)
+			}
+
+			announcer := mockAnnouncer{}
+			sessionManager := newMockSessionManager(sessions)
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				Announcer:      &announcer,
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req, err := http.NewRequest("POST", "/friend-recommendations", strings.NewReader(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: tt.sessionToken,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+
+			if got, want := rec.Result().StatusCode, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			inbox, err := dataStore.ReadFriendRecommendationsTo(screenjournal.Username("userB"))
+			if err != nil {
+				t.Fatalf("failed to read recommendations: %v", err)
+			}
+			if got, want := len(inbox), len(tt.expectedInbox); got != want {
+				t.Fatalf("inbox size=%d, want=%d", got, want)
+			}
+			for i, got := range inbox {
+				want := tt.expectedInbox[i]
+				if got.ID != want.ID || !got.From.Equal(want.From) || !got.To.Equal(want.To) || got.Message != want.Message || got.Status != want.Status {
+					t.Errorf("recommendation=%+v, want=%+v", got, want)
+				}
+				if got, want := got.Movie.Title, td.movies.theWaterBoy.Title; got != want {
+					t.Errorf("title=%v, want=%v", got, want)
+				}
+			}
+
+			if got, want := len(announcer.announcedFriendRecommendations) > 0, tt.expectAnnounced; got != want {
+				t.Errorf("announced=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestFriendRecommendationsPut(t *testing.T) {
+	for _, tt := range []struct {
+		description    string
+		payload        string
+		sessionToken   string
+		status         int
+		expectedStatus screenjournal.FriendRecommendationStatus
+	}{
+		{
+			description:    "recipient marks recommendation as watched",
+			payload:        "status=watched",
+			sessionToken:   makeReactionsTestData().sessions.userB.token,
+			status:         http.StatusOK,
+			expectedStatus: screenjournal.FriendRecommendationWatched,
+		},
+		{
+			description:    "recipient dismisses recommendation",
+			payload:        "status=dismissed",
+			sessionToken:   makeReactionsTestData().sessions.userB.token,
+			status:         http.StatusOK,
+			expectedStatus: screenjournal.FriendRecommendationDismissed,
+		},
+		{
+			description:    "rejects invalid status",
+			payload:        "status=pending",
+			sessionToken:   makeReactionsTestData().sessions.userB.token,
+			status:         http.StatusBadRequest,
+			expectedStatus: screenjournal.FriendRecommendationPending,
+		},
+		{
+			description:    "sender can't update recipient's recommendation",
+			payload:        "status=dismissed",
+			sessionToken:   makeReactionsTestData().sessions.userA.token,
+			status:         http.StatusForbidden,
+			expectedStatus: screenjournal.FriendRecommendationPending,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			td := makeReactionsTestData()
+			sessions := []mockSessionEntry{td.sessions.userA, td.sessions.userB}
+			dataStore := test_sqlite.New()
+			insertMockUsersForSessions(t, dataStore, sessions)
+			movieID, err := dataStore.InsertMovie(td.movies.theWaterBoy)
+			if err != nil {
+				t.Fatalf("failed to insert mock movie: %v", err)
+			}
+			id, err := dataStore.InsertFriendRecommendation(screenjournal.FriendRecommendation{
+				From:  td.sessions.userA.session.Username,
+				To:    td.sessions.userB.session.Username,
+				Movie: screenjournal.Movie{ID: movieID},
+			})
+			if err != nil {
+				t.Fatalf("failed to insert mock recommendation: %v", err)
+			}
+
+			sessionManager := newMockSessionManager(sessions)
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req, err := http.NewRequest("PUT", "/friend-recommendations/"+id.String(), strings.NewReader(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: tt.sessionToken,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+			res := rec.Result()
+
+			// Successful updates redirect back to the inbox.
+			status := res.StatusCode
+			if status == http.StatusSeeOther {
+				status = http.StatusOK
+			}
+			if got, want := status, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", res.StatusCode, want)
+			}
+
+			fr, err := dataStore.ReadFriendRecommendation(id)
+			if err != nil {
+				t.Fatalf("failed to read recommendation: %v", err)
+			}
+			if got, want := fr.Status, tt.expectedStatus; got != want {
+				t.Errorf("status=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestFriendRecommendationsGet(t *testing.T) {
+	td := makeReactionsTestData()
+	sessions := []mockSessionEntry{td.sessions.userA, td.sessions.userB}
+	dataStore := test_sqlite.New()
+	insertMockUsersForSessions(t, dataStore, sessions)
+	if _, err := dataStore.InsertMovie(td.movies.theWaterBoy); err != nil {
+		t.Fatalf("failed to insert mock movie: %v", err)
+	}
+	if _, err := dataStore.InsertReview(td.reviews.userBTheWaterBoy); err != nil {
+		t.Fatalf("failed to insert mock review: %v", err)
+	}
+	id, err := dataStore.InsertFriendRecommendation(screenjournal.FriendRecommendation{
+		From:    td.sessions.userA.session.Username,
+		To:      td.sessions.userB.session.Username,
+		Movie:   td.movies.theWaterBoy,
+		Message: screenjournal.RecommendationMessage("Water sports!"),
+	})
+	if err != nil {
+		t.Fatalf("failed to insert mock recommendation: %v", err)
+	}
+	if err := dataStore.UpdateFriendRecommendationStatus(id, screenjournal.FriendRecommendationWatched); err != nil {
+		t.Fatalf("failed to update mock recommendation: %v", err)
+	}
+
+	sessionManager := newMockSessionManager(sessions)
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	req, err := http.NewRequest("GET", "/friend-recommendations", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(&http.Cookie{
+		Name:  mockSessionTokenName,
+		Value: td.sessions.userB.token,
+	})
+
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+	res := rec.Result()
+
+	if got, want := res.StatusCode, http.StatusOK; got != want {
+		t.Fatalf("httpStatus=%v, want=%v", got, want)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	for _, want := range []string{
+		"The Waterboy",
+		"Water sports!",
+		`<a href="/movies/1#review1">See your review</a>`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("response body doesn't contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+// notificationsPageSize is how many notifications the notifications page shows
+// at once.
+const notificationsPageSize = 50
+
+type notificationViewModel struct {
+	Actor     screenjournal.Username
+	Kind      screenjournal.NotificationKind
+	TitleText string
+	URL       string
+	Emoji     screenjournal.ReactionEmoji
+	Read      bool
+	Created   time.Time
+}
+
+func (s Server) notificationsGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			Funcs(template.FuncMap{
+				"formatDate": formatActivityDate,
+			}).
+			ParseFS(
+				templatesFS,
+				append(baseTemplates, "templates/pages/notifications.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var before screenjournal.NotificationID
+		if raw := r.URL.Query().Get("before"); raw != "" {
+			var err error
+			if before, err = parse.NotificationID(raw); err != nil {
+				http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+				return
+			}
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		// Read one extra notification to find out whether there's another page.
+		notifications, err := s.store.ReadNotifications(username, before, notificationsPageSize+1)
+		if err != nil {
+			log.Printf("failed to read notifications for %s: %v", username, err)
+			http.Error(w, "Failed to read notifications", http.StatusInternalServerError)
+			return
+		}
+		var olderURL string
+		if len(notifications) > notificationsPageSize {
+			notifications = notifications[:notificationsPageSize]
+			olderURL = "/notifications?before=" + notifications[len(notifications)-1].ID.String()
+		}
+
+		vms := make([]notificationViewModel, len(notifications))
+		for i, n := range notifications {
+			vms[i] = makeNotificationViewModel(n)
+		}
+
+		renderTemplate(w, t, "base.html", struct {
+			commonProps
+			Notifications []notificationViewModel
+			// OlderURL is the link to the next page of notifications, or empty if
+			// there are no older notifications.
+			OlderURL string
+		}{
+			commonProps:   makeCommonProps(r.Context()),
+			Notifications: vms,
+			OlderURL:      olderURL,
+		})
+	}
+}
+
+func (s Server) notificationsReadPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := mustGetUsernameFromContext(r.Context())
+		if err := s.store.MarkAllNotificationsRead(username); err != nil {
+			log.Printf("failed to mark notifications read for %s: %v", username, err)
+			http.Error(w, fmt.Sprintf("Failed to mark notifications read: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+	}
+}
+
+func makeNotificationViewModel(n screenjournal.Notification) notificationViewModel {
+	url := reviewTargetURL(n.Review, n.Review.ID)
+	if !n.Comment.ID.IsZero() {
+		url = reviewCommentURL(n.Review, n.Comment.ID)
+	}
+	return notificationViewModel{
+		Actor:     n.Actor,
+		Kind:      n.Kind,
+		TitleText: reviewMediaTitle(n.Review),
+		URL:       url,
+		Emoji:     n.Emoji,
+		Read:      n.Read,
+		Created:   n.Created,
+	}
+}
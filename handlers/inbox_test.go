@@ -0,0 +1,156 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestNotifications(t *testing.T) {
+	td := makeReactionsTestData()
+	sessions := []mockSessionEntry{td.sessions.userA, td.sessions.userB}
+
+	dataStore := test_sqlite.New()
+	insertMockUsersForSessions(t, dataStore, sessions)
+	if _, err := dataStore.InsertMovie(td.movies.theWaterBoy); err != nil {
+		t.Fatalf("failed to insert mock movie: %v", err)
+	}
+	reviewID, err := dataStore.InsertReview(td.reviews.userBTheWaterBoy)
+	if err != nil {
+		t.Fatalf("failed to insert mock review: %v", err)
+	}
+	if err := dataStore.InsertNotification(screenjournal.Notification{
+		Recipient: td.sessions.userB.session.Username,
+		Actor:     td.sessions.userA.session.Username,
+		Kind:      screenjournal.NotificationKindReaction,
+		Review:    screenjournal.Review{ID: reviewID},
+		Emoji:     screenjournal.NewReactionEmoji("🥞"),
+	}); err != nil {
+		t.Fatalf("failed to insert notification: %v", err)
+	}
+
+	sessionManager := newMockSessionManager(sessions)
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	get := func() string {
+		req, err := http.NewRequest("GET", "/notifications", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.AddCookie(&http.Cookie{
+			Name:  mockSessionTokenName,
+			Value: td.sessions.userB.token,
+		})
+		rec := httptest.NewRecorder()
+		s.Router().ServeHTTP(rec, req)
+		if got, want := rec.Result().StatusCode, http.StatusOK; got != want {
+			t.Fatalf("httpStatus=%v, want=%v", got, want)
+		}
+		return rec.Body.String()
+	}
+
+	body := get()
+	if !strings.Contains(body, "reacted 🥞 to your post on") {
+		t.Errorf("notifications page doesn't show reaction notification")
+	}
+	if !strings.Contains(body, `data-testid="unread-notifications"`) {
+		t.Errorf("navbar doesn't show unread notification badge")
+	}
+
+	req, err := http.NewRequest("POST", "/notifications/read", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(&http.Cookie{
+		Name:  mockSessionTokenName,
+		Value: td.sessions.userB.token,
+	})
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+	if got, want := rec.Result().StatusCode, http.StatusSeeOther; got != want {
+		t.Fatalf("httpStatus=%v, want=%v", got, want)
+	}
+
+	body = get()
+	if strings.Contains(body, `data-testid="unread-notifications"`) {
+		t.Errorf("navbar still shows unread notification badge after marking all read")
+	}
+	if !strings.Contains(body, "reacted 🥞 to your post on") {
+		t.Errorf("notifications page no longer shows read notification")
+	}
+}
+
+func TestNotificationsPages(t *testing.T) {
+	td := makeReactionsTestData()
+	sessions := []mockSessionEntry{td.sessions.userA, td.sessions.userB}
+
+	dataStore := test_sqlite.New()
+	insertMockUsersForSessions(t, dataStore, sessions)
+	if _, err := dataStore.InsertMovie(td.movies.theWaterBoy); err != nil {
+		t.Fatalf("failed to insert mock movie: %v", err)
+	}
+	reviewID, err := dataStore.InsertReview(td.reviews.userBTheWaterBoy)
+	if err != nil {
+		t.Fatalf("failed to insert mock review: %v", err)
+	}
+	for range 51 {
+		if err := dataStore.InsertNotification(screenjournal.Notification{
+			Recipient: td.sessions.userB.session.Username,
+			Actor:     td.sessions.userA.session.Username,
+			Kind:      screenjournal.NotificationKindMention,
+			Review:    screenjournal.Review{ID: reviewID},
+		}); err != nil {
+			t.Fatalf("failed to insert notification: %v", err)
+		}
+	}
+
+	sessionManager := newMockSessionManager(sessions)
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	get := func(route string) string {
+		req, err := http.NewRequest("GET", route, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.AddCookie(&http.Cookie{
+			Name:  mockSessionTokenName,
+			Value: td.sessions.userB.token,
+		})
+		rec := httptest.NewRecorder()
+		s.Router().ServeHTTP(rec, req)
+		if got, want := rec.Result().StatusCode, http.StatusOK; got != want {
+			t.Fatalf("httpStatus=%v, want=%v", got, want)
+		}
+		return rec.Body.String()
+	}
+
+	body := get("/notifications")
+	if got, want := strings.Count(body, `data-testid="notification"`), 50; got != want {
+		t.Errorf("first page notifications=%d, want=%d", got, want)
+	}
+	if !strings.Contains(body, `href="/notifications?before=2"`) {
+		t.Errorf("first page doesn't link to older notifications")
+	}
+
+	body = get("/notifications?before=2")
+	if got, want := strings.Count(body, `data-testid="notification"`), 1; got != want {
+		t.Errorf("second page notifications=%d, want=%d", got, want)
+	}
+	if strings.Contains(body, `data-testid="older-notifications"`) {
+		t.Errorf("last page links to older notifications")
+	}
+}
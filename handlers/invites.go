@@ -1,47 +1,247 @@
 package handlers
 
 import (
+	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/mtlynch/screenjournal/v2/handlers/parse"
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
 )
 
 type invitesPostRequest struct {
 	Invitee screenjournal.Invitee
+	// Email is empty if the inviter wants to share the link themselves.
+	Email screenjournal.Email
+	// Lifetime is zero if the invite never expires.
+	Lifetime time.Duration
+	MaxUses  int
 }
 
+type inviteFormProps struct {
+	// Action is the URL that the form submits to.
+	Action         string
+	EmailAvailable bool
+	// CanSetLimits is true if the inviter can choose when the invite expires
+	// and how many people can use it.
+	CanSetLimits bool
+	MaxUses      int
+}
+
+type inviteRowProps struct {
+	Invite screenjournal.SignupInvitation
+	// ShowInviter is true on the admin page, which lists every user's invites.
+	ShowInviter bool
+	Expired     bool
+}
+
+var inviteRowFns = template.FuncMap{
+	"formatTime": formatIso8601Datetime,
+}
+
+// invitesPost creates a signup invite. Admins can choose how long the invite
+// lasts and how many people can use it. Members, if admins allow it, can only
+// create single-use invites that expire after a week, up to their limit.
 func (s Server) invitesPost() http.HandlerFunc {
-	t := template.Must(template.ParseFS(templatesFS, "templates/fragments/invite-row.html"))
+	t := template.Must(
+		template.New("invite-row.html").
+			Funcs(inviteRowFns).
+			ParseFS(templatesFS, "templates/fragments/invite-row.html"))
+
 	return func(w http.ResponseWriter, r *http.Request) {
 		req, err := parseInvitesPostRequest(r)
 		if err != nil {
 			log.Printf("failed to parse invites POST: %v", err)
-			http.Error(w, "Invalid invite creation", http.StatusBadRequest)
+			http.Error(w, fmt.Sprintf("Invalid invite creation: %v", err), http.StatusBadRequest)
 			return
 		}
 
+		if req.Email != "" && s.inviteSender == nil {
+			http.Error(w, "Emailing invites is not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		now := time.Now()
 		invitation := screenjournal.SignupInvitation{
 			Invitee:    req.Invitee,
 			InviteCode: screenjournal.NewInviteCode(),
+			Email:      req.Email,
+			InvitedBy:  username,
+			MaxUses:    req.MaxUses,
+		}
+		if isAdmin(r.Context()) {
+			if req.Lifetime > 0 {
+				invitation.Expires = now.Add(req.Lifetime)
+			}
+			if err := s.store.InsertSignupInvitation(invitation); err != nil {
+				log.Printf("failed to add new signup invite %+v: %v", invitation, err)
+				http.Error(w, "Failed to store new signup invite", http.StatusInternalServerError)
+				return
+			}
+		} else {
+			policy, err := s.store.ReadMemberInvitePolicy()
+			if err != nil {
+				log.Printf("failed to read member invite policy: %v", err)
+				http.Error(w, "Failed to read invite settings", http.StatusInternalServerError)
+				return
+			}
+			if !policy.Enabled {
+				http.Error(w, "Only admins can create invites on this server", http.StatusForbidden)
+				return
+			}
+
+			// Members' invites are single-use and expire so that each member's
+			// limit also caps how many people they can bring in.
+			invitation.MaxUses = 1
+			invitation.Expires = now.Add(screenjournal.MemberInviteLifetime)
+
+			if err := s.store.InsertMemberSignupInvitation(invitation, policy.Limit); err == store.ErrInviteLimitReached {
+				http.Error(w, fmt.Sprintf("You've already created all %d of your invites", policy.Limit), http.StatusForbidden)
+				return
+			} else if err != nil {
+				log.Printf("failed to add new signup invite %+v: %v", invitation, err)
+				http.Error(w, "Failed to store new signup invite", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if invitation.Email != "" {
+			if err := s.inviteSender.SendInvitation(invitation); err != nil {
+				log.Printf("failed to email invite for %s: %v", invitation.Invitee, err)
+				http.Error(w, "Created the invite but failed to email it", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		stored, err := s.store.ReadSignupInvitation(invitation.InviteCode)
+		if err != nil {
+			log.Printf("failed to read new signup invite for %s: %v", invitation.Invitee, err)
+			http.Error(w, "Failed to read new signup invite", http.StatusInternalServerError)
+			return
+		}
+
+		renderTemplate(w, t, "invite-row.html", inviteRowProps{
+			Invite:      stored,
+			ShowInviter: isAdmin(r.Context()),
+		})
+	}
+}
+
+// memberInvitesGet shows a member the invites they've created, if admins let
+// members create invites.
+func (s Server) memberInvitesGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			Funcs(inviteRowFns).
+			ParseFS(
+				templatesFS,
+				append(
+					baseTemplates,
+					"templates/fragments/invite-row.html",
+					"templates/fragments/invite-form.html",
+					"templates/pages/member-invites.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isAdmin(r.Context()) {
+			http.Redirect(w, r, "/admin/invites", http.StatusSeeOther)
+			return
+		}
+
+		policy, err := s.store.ReadMemberInvitePolicy()
+		if err != nil {
+			log.Printf("failed to read member invite policy: %v", err)
+			http.Error(w, "Failed to read invite settings", http.StatusInternalServerError)
+			return
+		}
+		if !policy.Enabled {
+			http.Error(w, "Only admins can create invites on this server", http.StatusForbidden)
+			return
 		}
-		if err := s.store.InsertSignupInvitation(invitation); err != nil {
-			log.Printf("failed to add new signup invite %+v: %v", invitation, err)
-			http.Error(w, "Failed to store new signup invite", http.StatusInternalServerError)
+
+		username := mustGetUsernameFromContext(r.Context())
+		invites, err := s.store.ReadSignupInvitationsByInviter(username)
+		if err != nil {
+			log.Printf("failed to read signup invitations from user %s: %v", username, err)
+			http.Error(w, "Failed to read signup invitations", http.StatusInternalServerError)
 			return
 		}
 
-		renderTemplate(w, t, "invite-row.html", struct {
-			Invitee    screenjournal.Invitee
-			InviteCode screenjournal.InviteCode
+		renderTemplate(w, t, "base.html", struct {
+			commonProps
+			Form      inviteFormProps
+			Invites   []inviteRowProps
+			Remaining int
+			Limit     int
 		}{
-			Invitee:    invitation.Invitee,
-			InviteCode: invitation.InviteCode,
+			commonProps: makeCommonProps(r.Context()),
+			Form: inviteFormProps{
+				Action:         "/invites",
+				EmailAvailable: s.inviteSender != nil,
+			},
+			Invites:   makeInviteRows(invites, false, time.Now()),
+			Remaining: max(policy.Limit-len(invites), 0),
+			Limit:     policy.Limit,
 		})
 	}
+}
+
+func (s Server) adminMemberInvitePolicyPut() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			log.Printf("failed to parse member invite policy form: %v", err)
+			http.Error(w, "Invalid member invite settings", http.StatusBadRequest)
+			return
+		}
+
+		limit, err := parse.MemberInviteLimit(r.PostFormValue("limit"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid member invite settings: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		policy := screenjournal.MemberInvitePolicy{
+			Enabled: parse.CheckboxToBool(r.PostFormValue("enabled")),
+			Limit:   limit,
+		}
+		if err := s.store.UpdateMemberInvitePolicy(policy); err != nil {
+			log.Printf("failed to update member invite policy: %v", err)
+			http.Error(w, "Failed to save member invite settings", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprint(w, "Saved member invite settings"); err != nil {
+			log.Printf("failed to write response: %v", err)
+		}
+	}
+}
 
+func makeInviteRows(invites []screenjournal.SignupInvitation, showInviter bool, now time.Time) []inviteRowProps {
+	rows := make([]inviteRowProps, len(invites))
+	for i, invite := range invites {
+		rows[i] = inviteRowProps{
+			Invite:      invite,
+			ShowInviter: showInviter,
+			Expired:     invite.Expired(now),
+		}
+	}
+	return rows
+}
+
+// inviteUnavailableReason explains why nobody can sign up with the invite
+// anymore, or returns an empty string if the invite still works.
+func inviteUnavailableReason(invite screenjournal.SignupInvitation, now time.Time) string {
+	if invite.Expired(now) {
+		return "This invite has expired"
+	}
+	if invite.RemainingUses() == 0 {
+		return "This invite has already been used"
+	}
+	return ""
 }
 
 func parseInvitesPostRequest(r *http.Request) (invitesPostRequest, error) {
@@ -54,7 +254,31 @@ func parseInvitesPostRequest(r *http.Request) (invitesPostRequest, error) {
 		return invitesPostRequest{}, err
 	}
 
+	var email screenjournal.Email
+	if raw := r.PostFormValue("email"); raw != "" {
+		if email, err = parse.Email(raw); err != nil {
+			return invitesPostRequest{}, err
+		}
+	}
+
+	var lifetime time.Duration
+	if raw := r.PostFormValue("expires"); raw != "" {
+		if lifetime, err = parse.InviteLifetime(raw); err != nil {
+			return invitesPostRequest{}, err
+		}
+	}
+
+	maxUses := 1
+	if raw := r.PostFormValue("maxUses"); raw != "" {
+		if maxUses, err = parse.InviteMaxUses(raw); err != nil {
+			return invitesPostRequest{}, err
+		}
+	}
+
 	return invitesPostRequest{
-		Invitee: invitee,
+		Invitee:  invitee,
+		Email:    email,
+		Lifetime: lifetime,
+		MaxUses:  maxUses,
 	}, nil
 }
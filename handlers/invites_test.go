@@ -34,6 +34,8 @@ func TestInvitesPost(t *testing.T) {
 		sessions        []mockSessionEntry
 		status          int
 		expectedInvitee screenjournal.Invitee
+		expectedMaxUses int
+		expectExpiry    bool
 	}{
 		{
 			description:  "creates a new invite successfully",
@@ -45,6 +47,40 @@ func TestInvitesPost(t *testing.T) {
 			},
 			status:          http.StatusOK,
 			expectedInvitee: screenjournal.Invitee("Frank"),
+			expectedMaxUses: 1,
+		},
+		{
+			description:  "creates an invite with an expiration and multiple uses",
+			payload:      "invitee=Frank&expires=7&maxUses=5",
+			sessionToken: makeInvitesTestData().sessions.adminUser.token,
+			sessions: []mockSessionEntry{
+				makeInvitesTestData().sessions.adminUser,
+				makeInvitesTestData().sessions.regularUser,
+			},
+			status:          http.StatusOK,
+			expectedInvitee: screenjournal.Invitee("Frank"),
+			expectedMaxUses: 5,
+			expectExpiry:    true,
+		},
+		{
+			description:  "rejects invite with too many uses",
+			payload:      "invitee=Frank&maxUses=1000",
+			sessionToken: makeInvitesTestData().sessions.adminUser.token,
+			sessions: []mockSessionEntry{
+				makeInvitesTestData().sessions.adminUser,
+				makeInvitesTestData().sessions.regularUser,
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			description:  "rejects emailed invite when email is not available",
+			payload:      "invitee=Frank&email=frank@example.com",
+			sessionToken: makeInvitesTestData().sessions.adminUser.token,
+			sessions: []mockSessionEntry{
+				makeInvitesTestData().sessions.adminUser,
+				makeInvitesTestData().sessions.regularUser,
+			},
+			status: http.StatusServiceUnavailable,
 		},
 		{
 			description:  "rejects request with missing invitee field",
@@ -128,6 +164,363 @@ func TestInvitesPost(t *testing.T) {
 			if got, want := invites[0].Invitee, tt.expectedInvitee; got != want {
 				t.Errorf("invitee=%+v, want=%+v", got, want)
 			}
+			if got, want := invites[0].InvitedBy, screenjournal.Username("admin"); got != want {
+				t.Errorf("invitedBy=%+v, want=%+v", got, want)
+			}
+			if got, want := invites[0].MaxUses, tt.expectedMaxUses; got != want {
+				t.Errorf("maxUses=%d, want=%d", got, want)
+			}
+			if got, want := !invites[0].Expires.IsZero(), tt.expectExpiry; got != want {
+				t.Errorf("has expiry=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+type mockInviteSender struct {
+	invitesSent []screenjournal.SignupInvitation
+}
+
+func (s *mockInviteSender) SendInvitation(invite screenjournal.SignupInvitation) error {
+	s.invitesSent = append(s.invitesSent, invite)
+	return nil
+}
+
+func TestInvitesPostSendsEmail(t *testing.T) {
+	dataStore := test_sqlite.New()
+	sessions := []mockSessionEntry{makeInvitesTestData().sessions.adminUser}
+	insertMockUsersForSessions(t, dataStore, sessions, screenjournal.Username("admin"))
+
+	sessionManager := newMockSessionManager(sessions)
+	inviteSender := &mockInviteSender{}
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+		InviteSender:   inviteSender,
+	})
+
+	req, err := http.NewRequest("POST", "/admin/invites", strings.NewReader("invitee=Frank&email=frank@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{
+		Name:  mockSessionTokenName,
+		Value: makeInvitesTestData().sessions.adminUser.token,
+	})
+
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+	res := rec.Result()
+
+	if got, want := res.StatusCode, http.StatusOK; got != want {
+		t.Fatalf("httpStatus=%v, want=%v", got, want)
+	}
+
+	if got, want := len(inviteSender.invitesSent), 1; got != want {
+		t.Fatalf("invites sent=%d, want=%d", got, want)
+	}
+	sent := inviteSender.invitesSent[0]
+	if got, want := sent.Email, screenjournal.Email("frank@example.com"); got != want {
+		t.Errorf("email=%v, want=%v", got, want)
+	}
+
+	invites, err := dataStore.ReadSignupInvitations()
+	if err != nil {
+		t.Fatalf("failed to read invites from datastore: %v", err)
+	}
+	if got, want := invites[0].InviteCode, sent.InviteCode; got != want {
+		t.Errorf("emailed invite code=%v, want=%v", got, want)
+	}
+	if got, want := invites[0].Email, screenjournal.Email("frank@example.com"); got != want {
+		t.Errorf("stored email=%v, want=%v", got, want)
+	}
+}
+
+func TestInvitesPostByMember(t *testing.T) {
+	for _, tt := range []struct {
+		description     string
+		policy          screenjournal.MemberInvitePolicy
+		existingInvites int
+		payload         string
+		status          int
+	}{
+		{
+			description: "member creates a single-use invite that expires",
+			policy: screenjournal.MemberInvitePolicy{
+				Enabled: true,
+				Limit:   2,
+			},
+			existingInvites: 1,
+			payload:         "invitee=Frank&maxUses=50",
+			status:          http.StatusOK,
+		},
+		{
+			description: "rejects member invite when admins haven't enabled member invites",
+			policy: screenjournal.MemberInvitePolicy{
+				Enabled: false,
+				Limit:   2,
+			},
+			payload: "invitee=Frank",
+			status:  http.StatusForbidden,
+		},
+		{
+			description: "rejects member invite when the member has used all their invites",
+			policy: screenjournal.MemberInvitePolicy{
+				Enabled: true,
+				Limit:   2,
+			},
+			existingInvites: 2,
+			payload:         "invitee=Frank",
+			status:          http.StatusForbidden,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			sessions := []mockSessionEntry{
+				makeInvitesTestData().sessions.adminUser,
+				makeInvitesTestData().sessions.regularUser,
+			}
+			insertMockUsersForSessions(t, dataStore, sessions, screenjournal.Username("admin"))
+
+			if err := dataStore.UpdateMemberInvitePolicy(tt.policy); err != nil {
+				t.Fatalf("failed to update member invite policy: %v", err)
+			}
+			for range tt.existingInvites {
+				if err := dataStore.InsertSignupInvitation(screenjournal.SignupInvitation{
+					Invitee:    screenjournal.Invitee("Someone"),
+					InviteCode: screenjournal.NewInviteCode(),
+					InvitedBy:  screenjournal.Username("regularUser"),
+				}); err != nil {
+					t.Fatalf("failed to insert mock invite: %v", err)
+				}
+			}
+
+			sessionManager := newMockSessionManager(sessions)
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req, err := http.NewRequest("POST", "/invites", strings.NewReader(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: makeInvitesTestData().sessions.regularUser.token,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+			res := rec.Result()
+
+			if got, want := res.StatusCode, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			invites, err := dataStore.ReadSignupInvitationsByInviter(screenjournal.Username("regularUser"))
+			if err != nil {
+				t.Fatalf("failed to read invites from datastore: %v", err)
+			}
+
+			if tt.status != http.StatusOK {
+				if got, want := len(invites), tt.existingInvites; got != want {
+					t.Errorf("invite count=%d, want=%d", got, want)
+				}
+				return
+			}
+
+			if got, want := len(invites), tt.existingInvites+1; got != want {
+				t.Fatalf("invite count=%d, want=%d", got, want)
+			}
+			created := invites[0]
+			if got, want := created.Invitee, screenjournal.Invitee("Frank"); got != want {
+				t.Errorf("invitee=%v, want=%v", got, want)
+			}
+			if got, want := created.MaxUses, 1; got != want {
+				t.Errorf("maxUses=%d, want=%d", got, want)
+			}
+			if created.Expires.IsZero() {
+				t.Errorf("expected member invite to expire")
+			}
+		})
+	}
+}
+
+func TestAdminMemberInvitePolicyPut(t *testing.T) {
+	for _, tt := range []struct {
+		description    string
+		payload        string
+		sessionToken   string
+		status         int
+		expectedPolicy screenjournal.MemberInvitePolicy
+	}{
+		{
+			description:  "enables member invites",
+			payload:      "enabled=on&limit=5",
+			sessionToken: makeInvitesTestData().sessions.adminUser.token,
+			status:       http.StatusOK,
+			expectedPolicy: screenjournal.MemberInvitePolicy{
+				Enabled: true,
+				Limit:   5,
+			},
+		},
+		{
+			description:  "disables member invites when the checkbox is unchecked",
+			payload:      "limit=5",
+			sessionToken: makeInvitesTestData().sessions.adminUser.token,
+			status:       http.StatusOK,
+			expectedPolicy: screenjournal.MemberInvitePolicy{
+				Enabled: false,
+				Limit:   5,
+			},
+		},
+		{
+			description:  "rejects a negative limit",
+			payload:      "enabled=on&limit=-1",
+			sessionToken: makeInvitesTestData().sessions.adminUser.token,
+			status:       http.StatusBadRequest,
+		},
+		{
+			description:  "rejects request from a user who isn't an admin",
+			payload:      "enabled=on&limit=5",
+			sessionToken: makeInvitesTestData().sessions.regularUser.token,
+			status:       http.StatusForbidden,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			sessions := []mockSessionEntry{
+				makeInvitesTestData().sessions.adminUser,
+				makeInvitesTestData().sessions.regularUser,
+			}
+			insertMockUsersForSessions(t, dataStore, sessions, screenjournal.Username("admin"))
+
+			sessionManager := newMockSessionManager(sessions)
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req, err := http.NewRequest("PUT", "/admin/invites/member-policy", strings.NewReader(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: tt.sessionToken,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+			res := rec.Result()
+
+			if got, want := res.StatusCode, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			policy, err := dataStore.ReadMemberInvitePolicy()
+			if err != nil {
+				t.Fatalf("failed to read member invite policy: %v", err)
+			}
+			if got, want := policy, tt.expectedPolicy; got != want {
+				t.Errorf("policy=%+v, want=%+v", got, want)
+			}
+		})
+	}
+}
+
+func TestInvitesGet(t *testing.T) {
+	for _, tt := range []struct {
+		description  string
+		route        string
+		sessionToken string
+		policy       screenjournal.MemberInvitePolicy
+		status       int
+	}{
+		{
+			description:  "admin sees every invite",
+			route:        "/admin/invites",
+			sessionToken: makeInvitesTestData().sessions.adminUser.token,
+			status:       http.StatusOK,
+		},
+		{
+			description:  "member sees their invites when member invites are enabled",
+			route:        "/invites",
+			sessionToken: makeInvitesTestData().sessions.regularUser.token,
+			policy: screenjournal.MemberInvitePolicy{
+				Enabled: true,
+				Limit:   3,
+			},
+			status: http.StatusOK,
+		},
+		{
+			description:  "member can't see invites page when member invites are disabled",
+			route:        "/invites",
+			sessionToken: makeInvitesTestData().sessions.regularUser.token,
+			status:       http.StatusForbidden,
+		},
+		{
+			description:  "admin gets redirected to the admin invites page",
+			route:        "/invites",
+			sessionToken: makeInvitesTestData().sessions.adminUser.token,
+			status:       http.StatusSeeOther,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			sessions := []mockSessionEntry{
+				makeInvitesTestData().sessions.adminUser,
+				makeInvitesTestData().sessions.regularUser,
+			}
+			insertMockUsersForSessions(t, dataStore, sessions, screenjournal.Username("admin"))
+
+			if err := dataStore.UpdateMemberInvitePolicy(tt.policy); err != nil {
+				t.Fatalf("failed to update member invite policy: %v", err)
+			}
+			for _, inviter := range []screenjournal.Username{"admin", "regularUser"} {
+				if err := dataStore.InsertSignupInvitation(screenjournal.SignupInvitation{
+					Invitee:    screenjournal.Invitee("Someone"),
+					InviteCode: screenjournal.NewInviteCode(),
+					InvitedBy:  inviter,
+				}); err != nil {
+					t.Fatalf("failed to insert mock invite: %v", err)
+				}
+			}
+
+			sessionManager := newMockSessionManager(sessions)
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req, err := http.NewRequest("GET", tt.route, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: tt.sessionToken,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+			res := rec.Result()
+
+			if got, want := res.StatusCode, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
 		})
 	}
 }
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/passwordreset"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type loginLinkPageProps struct {
+	commonProps
+	ServerSupportsLoginLinks bool
+	Submitted                bool
+	Username                 screenjournal.Username
+	Token                    screenjournal.PasswordResetToken
+}
+
+// logInEmailLinkGet shows the form for requesting a login link. When the user
+// follows a link from their email, it instead asks them to confirm the login.
+// Consuming the link on GET would let email scanners that prefetch links use
+// it up before the user clicks it.
+func (s Server) logInEmailLinkGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			ParseFS(
+				templatesFS,
+				append(baseTemplates, "templates/pages/login-link.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		props := loginLinkPageProps{
+			commonProps:              makeCommonProps(r.Context()),
+			ServerSupportsLoginLinks: s.passwordResetterForRequest() != nil,
+		}
+
+		if r.URL.Query().Has("token") {
+			username, err := parse.Username(r.URL.Query().Get("username"))
+			if err != nil {
+				http.Error(w, "Invalid username", http.StatusBadRequest)
+				return
+			}
+			token, err := parse.PasswordResetToken(r.URL.Query().Get("token"))
+			if err != nil {
+				http.Error(w, "Invalid login link", http.StatusBadRequest)
+				return
+			}
+			props.Username = username
+			props.Token = token
+		}
+
+		renderTemplate(w, t, "base.html", props)
+	}
+}
+
+func (s Server) logInEmailLinkPost() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			ParseFS(
+				templatesFS,
+				append(baseTemplates, "templates/pages/login-link.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		passwordResetter := s.passwordResetterForRequest()
+		if passwordResetter == nil {
+			http.Error(w, "Login links are not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		emailAddr, err := parse.Email(r.FormValue("email"))
+		if err != nil {
+			http.Error(w, "Invalid email address", http.StatusBadRequest)
+			return
+		}
+
+		if err := passwordResetter.SendLoginLink(emailAddr); err != nil {
+			log.Printf("failed to process login link request: %v", err)
+			http.Error(w, "Failed to process login link request", http.StatusInternalServerError)
+			return
+		}
+
+		// Render the same page whether or not the email matched a user.
+		renderTemplate(w, t, "base.html", loginLinkPageProps{
+			commonProps:              makeCommonProps(r.Context()),
+			ServerSupportsLoginLinks: true,
+			Submitted:                true,
+		})
+	}
+}
+
+func (s Server) logInEmailLinkConfirmPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		passwordResetter := s.passwordResetterForRequest()
+		if passwordResetter == nil {
+			http.Error(w, "Login links are not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		username, err := parse.Username(r.PostFormValue("username"))
+		if err != nil {
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+
+		token, err := parse.PasswordResetToken(r.PostFormValue("token"))
+		if err != nil {
+			http.Error(w, "Invalid login link", http.StatusBadRequest)
+			return
+		}
+
+		if err := passwordResetter.UseLoginLink(username, token); err != nil {
+			switch {
+			case errors.Is(err, passwordreset.ErrTooManyLoginLinkAttempts):
+				http.Error(w, "Too many login attempts. Please try again later.", http.StatusTooManyRequests)
+			case errors.Is(err, passwordreset.ErrInvalidLoginLink),
+				errors.Is(err, passwordreset.ErrExpiredLoginLink):
+				http.Error(w, "This login link is invalid or has expired. Please request a new one.", http.StatusUnauthorized)
+			default:
+				log.Printf("failed to use login link for user %s: %v", username, err)
+				http.Error(w, "Failed to log in", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		s.logInAndRedirect(w, r, username, defaultNextPath)
+	}
+}
@@ -0,0 +1,187 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/passwordreset"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+	"github.com/mtlynch/screenjournal/v2/twofactor"
+)
+
+func TestLogInEmailLinkGet(t *testing.T) {
+	for _, tt := range []struct {
+		description      string
+		passwordResetter handlers.PasswordResetter
+		query            string
+		status           int
+		bodyMustContain  string
+	}{
+		{
+			description:      "shows unavailable message when server can't send email",
+			passwordResetter: nil,
+			status:           http.StatusOK,
+			bodyMustContain:  "Login links are not available on this server.",
+		},
+		{
+			description:      "shows request form",
+			passwordResetter: noopPasswordResetter{},
+			status:           http.StatusOK,
+			bodyMustContain:  `id="login-link-form"`,
+		},
+		{
+			description:      "asks the user to confirm before using the link",
+			passwordResetter: noopPasswordResetter{},
+			query:            "?username=userA&token=ABCDEFGHJKLMNPQRSTUVWXYZabcdef23",
+			status:           http.StatusOK,
+			bodyMustContain:  `id="login-link-confirm-form"`,
+		},
+		{
+			description:      "rejects a malformed token",
+			passwordResetter: noopPasswordResetter{},
+			query:            "?username=userA&token=short",
+			status:           http.StatusBadRequest,
+			bodyMustContain:  "Invalid login link",
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			sessionManager := newMockSessionManager([]mockSessionEntry{})
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:    auth.New(dataStore),
+				SessionManager:   &sessionManager,
+				Store:            dataStore,
+				PasswordResetter: tt.passwordResetter,
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/login/email-link"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+
+			if got, want := rec.Code, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+			if got, want := strings.Contains(rec.Body.String(), tt.bodyMustContain), true; got != want {
+				t.Errorf("bodyContains(%q)=%v, want=%v", tt.bodyMustContain, got, want)
+			}
+		})
+	}
+}
+
+func TestLogInEmailLinkConfirmPost(t *testing.T) {
+	validToken := screenjournal.NewPasswordResetTokenFromString("ABCDEFGHJKLMNPQRSTUVWXYZabcdef23")
+	for _, tt := range []struct {
+		description      string
+		expiresAt        time.Time
+		token            string
+		twoFactor        bool
+		status           int
+		location         string
+		sessionCreated   bool
+		twoFactorPending bool
+	}{
+		{
+			description:    "logs in with a valid link",
+			expiresAt:      time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+			token:          validToken.String(),
+			status:         http.StatusFound,
+			location:       "/reviews",
+			sessionCreated: true,
+		},
+		{
+			description: "rejects an expired link",
+			expiresAt:   time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+			token:       validToken.String(),
+			status:      http.StatusUnauthorized,
+		},
+		{
+			description: "rejects the wrong token",
+			expiresAt:   time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+			token:       "ZZZZZZZZZZZZZZZZZZZZZZZZZZZZZZZZ",
+			status:      http.StatusUnauthorized,
+		},
+		{
+			description:      "asks a user with two-factor authentication for a code",
+			expiresAt:        time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+			token:            validToken.String(),
+			twoFactor:        true,
+			status:           http.StatusFound,
+			twoFactorPending: true,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			if err := dataStore.InsertUser(userA); err != nil {
+				t.Fatalf("failed to insert user: %v", err)
+			}
+			if err := dataStore.InsertLoginLinkEntry(screenjournal.LoginLinkEntry{
+				Username:  userA.Username,
+				Token:     validToken,
+				ExpiresAt: tt.expiresAt,
+			}); err != nil {
+				t.Fatalf("failed to insert login link: %v", err)
+			}
+
+			now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+			twoFactor := twofactor.New(dataStore, []byte("dummy-key"), func() time.Time { return now })
+			if tt.twoFactor {
+				secret, err := twoFactor.BeginEnrollment(userA.Username)
+				if err != nil {
+					t.Fatalf("failed to begin enrollment: %v", err)
+				}
+				if _, err := twoFactor.ConfirmEnrollment(userA.Username, mustTotpCode(t, secret, now)); err != nil {
+					t.Fatalf("failed to confirm enrollment: %v", err)
+				}
+			}
+
+			sessionManager := newMockSessionManager([]mockSessionEntry{})
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:    auth.New(dataStore),
+				SessionManager:   &sessionManager,
+				Store:            dataStore,
+				TwoFactor:        twoFactor,
+				PasswordResetter: passwordreset.NewNoEmail(dataStore, time.Now),
+			})
+
+			form := url.Values{
+				"username": {userA.Username.String()},
+				"token":    {tt.token},
+			}
+			req := httptest.NewRequest(http.MethodPost, "/login/email-link/confirm", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+
+			if got, want := rec.Code, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+			if tt.location != "" {
+				if got, want := rec.Header().Get("Location"), tt.location; got != want {
+					t.Errorf("Location=%v, want=%v", got, want)
+				}
+			}
+			if got, want := len(sessionManager.sessions) > 0, tt.sessionCreated; got != want {
+				t.Errorf("sessionCreated=%v, want=%v", got, want)
+			}
+			if tt.twoFactorPending {
+				location, err := url.Parse(rec.Header().Get("Location"))
+				if err != nil {
+					t.Fatalf("invalid Location: %v", err)
+				}
+				if got, want := location.Path, "/login"; got != want {
+					t.Errorf("redirect path=%v, want=%v", got, want)
+				}
+				if location.Query().Get("two-factor-challenge") == "" {
+					t.Errorf("redirect has no two-factor challenge")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+func (s Server) loginLockoutsGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			Funcs(template.FuncMap{
+				"formatTime": formatIso8601Datetime,
+			}).
+			ParseFS(
+				templatesFS,
+				append(baseTemplates, "templates/pages/login-lockouts.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		throttles, err := s.store.ReadBlockedLoginThrottles(time.Now())
+		if err != nil {
+			log.Printf("failed to read login lockouts: %v", err)
+			http.Error(w, "Failed to read login lockouts", http.StatusInternalServerError)
+			return
+		}
+
+		renderTemplate(w, t, "base.html", struct {
+			commonProps
+			Lockouts []screenjournal.LoginThrottle
+		}{
+			commonProps: makeCommonProps(r.Context()),
+			Lockouts:    throttles,
+		})
+	}
+}
+
+// loginLockoutsDelete clears the failed login count for a username or IP
+// address so that logins from it work right away.
+func (s Server) loginLockoutsDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope, key, err := loginThrottleFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid lockout", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.store.DeleteLoginThrottle(scope, key); err != nil {
+			if errors.Is(err, store.ErrLoginThrottleNotFound) {
+				http.Error(w, "Lockout not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("failed to clear login lockout for %s %s: %v", scope, key, err)
+			http.Error(w, "Failed to clear lockout", http.StatusInternalServerError)
+			return
+		}
+	}
+}
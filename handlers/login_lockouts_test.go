@@ -0,0 +1,184 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/ratelimit"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestAuthPostLoginLimits(t *testing.T) {
+	dataStore := test_sqlite.New()
+	if err := dataStore.InsertUser(userA); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	sessionManager := newMockSessionManager([]mockSessionEntry{})
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+		LoginLimiter:   ratelimit.NewLoginLimiter(dataStore, time.Now),
+	})
+
+	logIn := func(password string) *httptest.ResponseRecorder {
+		t.Helper()
+		req, err := http.NewRequest("POST", "/api/auth", strings.NewReader(`{"username": "userA", "password": "`+password+`"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.RemoteAddr = "203.0.113.7:52100"
+		rec := httptest.NewRecorder()
+		s.Router().ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := range 3 {
+		if got, want := logIn("wrongpass").Code, http.StatusUnauthorized; got != want {
+			t.Fatalf("failed login %d httpStatus=%v, want=%v", i+1, got, want)
+		}
+	}
+
+	// The correct password doesn't help while the username is delayed.
+	rec := logIn("dummyp@ss")
+	if got, want := rec.Code, http.StatusTooManyRequests; got != want {
+		t.Fatalf("delayed login httpStatus=%v, want=%v", got, want)
+	}
+	if got, want := rec.Header().Get("Retry-After"), "1"; got != want {
+		t.Errorf("Retry-After=%v, want=%v", got, want)
+	}
+
+	throttle, err := dataStore.ReadLoginThrottle(screenjournal.LoginThrottleScopeIP, "203.0.113.7")
+	if err != nil {
+		t.Fatalf("failed to read IP throttle: %v", err)
+	}
+	if got, want := throttle.Failures, 3; got != want {
+		t.Errorf("IP failures=%d, want=%d", got, want)
+	}
+}
+
+func TestLoginLockoutsRoutes(t *testing.T) {
+	dataStore := test_sqlite.New()
+	sessions := []mockSessionEntry{
+		makeInvitesTestData().sessions.adminUser,
+		makeInvitesTestData().sessions.regularUser,
+	}
+	insertMockUsersForSessions(t, dataStore, sessions, screenjournal.Username("admin"))
+
+	now := time.Now()
+	for _, throttle := range []screenjournal.LoginThrottle{
+		{
+			Scope:         screenjournal.LoginThrottleScopeUsername,
+			Key:           "userA",
+			Failures:      10,
+			LastFailureAt: now,
+			BlockedUntil:  now.Add(15 * time.Minute),
+		},
+		{
+			Scope:         screenjournal.LoginThrottleScopeIP,
+			Key:           "2001:db8::1",
+			Failures:      30,
+			LastFailureAt: now,
+			BlockedUntil:  now.Add(15 * time.Minute),
+		},
+	} {
+		if err := dataStore.UpsertLoginThrottle(throttle); err != nil {
+			t.Fatalf("failed to insert login throttle: %v", err)
+		}
+	}
+
+	sessionManager := newMockSessionManager(sessions)
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	send := func(method, route string, sessionToken string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, route, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.AddCookie(&http.Cookie{
+			Name:  mockSessionTokenName,
+			Value: sessionToken,
+		})
+		rec := httptest.NewRecorder()
+		s.Router().ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+	adminToken := makeInvitesTestData().sessions.adminUser.token
+	regularToken := makeInvitesTestData().sessions.regularUser.token
+
+	status, body := send("GET", "/admin/login-lockouts", adminToken)
+	if got, want := status, http.StatusOK; got != want {
+		t.Fatalf("lockouts page httpStatus=%v, want=%v", got, want)
+	}
+	if got, want := strings.Count(body, `data-testid="login-lockout"`), 2; got != want {
+		t.Errorf("lockouts page lists %d lockouts, want=%d", got, want)
+	}
+	for _, want := range []string{"userA", "2001:db8::1"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("lockouts page doesn't contain %q", want)
+		}
+	}
+
+	for _, tt := range []struct {
+		description  string
+		route        string
+		sessionToken string
+		status       int
+	}{
+		{
+			description:  "rejects clearing a lockout if user is not an admin",
+			route:        "/admin/login-lockouts/username/userA",
+			sessionToken: regularToken,
+			status:       http.StatusForbidden,
+		},
+		{
+			description:  "rejects an unknown lockout type",
+			route:        "/admin/login-lockouts/email/userA",
+			sessionToken: adminToken,
+			status:       http.StatusBadRequest,
+		},
+		{
+			description:  "returns 404 for a username that isn't locked out",
+			route:        "/admin/login-lockouts/username/userB",
+			sessionToken: adminToken,
+			status:       http.StatusNotFound,
+		},
+		{
+			description:  "clears a username lockout",
+			route:        "/admin/login-lockouts/username/userA",
+			sessionToken: adminToken,
+			status:       http.StatusOK,
+		},
+		{
+			description:  "clears an IP lockout",
+			route:        "/admin/login-lockouts/ip/2001:db8::1",
+			sessionToken: adminToken,
+			status:       http.StatusOK,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			if status, _ := send("DELETE", tt.route, tt.sessionToken); status != tt.status {
+				t.Errorf("httpStatus=%v, want=%v", status, tt.status)
+			}
+		})
+	}
+
+	if _, err := dataStore.ReadLoginThrottle(screenjournal.LoginThrottleScopeUsername, "userA"); err != store.ErrLoginThrottleNotFound {
+		t.Errorf("username throttle after clearing err=%v, want=%v", err, store.ErrLoginThrottleNotFound)
+	}
+	if _, err := dataStore.ReadLoginThrottle(screenjournal.LoginThrottleScopeIP, "2001:db8::1"); err != store.ErrLoginThrottleNotFound {
+		t.Errorf("IP throttle after clearing err=%v, want=%v", err, store.ErrLoginThrottleNotFound)
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+// announceMentions notifies each user in mentioned that author referred to
+// them. Users in previous were already mentioned before an edit, so they don't
+// receive a second notification.
+func (s Server) announceMentions(
+	author screenjournal.Username,
+	mentioned, previous []screenjournal.Username,
+	review screenjournal.Review,
+	comment screenjournal.ReviewComment,
+) {
+	alreadyMentioned := map[screenjournal.Username]bool{}
+	for _, u := range previous {
+		alreadyMentioned[u] = true
+	}
+
+	for _, username := range mentioned {
+		if username.Equal(author) || alreadyMentioned[username] {
+			continue
+		}
+		user, err := s.store.ReadUser(username)
+		if errors.Is(err, store.ErrUserNotFound) {
+			continue
+		} else if err != nil {
+			log.Printf("failed to read mentioned user %s: %v", username, err)
+			continue
+		}
+		s.announcer.AnnounceMention(screenjournal.Mention{
+			Mentioned: user.Username,
+			Author:    author,
+			Review:    review,
+			Comment:   comment,
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestCommentMentions(t *testing.T) {
+	for _, tt := range []struct {
+		description       string
+		method            string
+		payload           string
+		existingComment   screenjournal.CommentText
+		expectedMentioned []screenjournal.Username
+	}{
+		{
+			description:       "announces mention of an existing user in a new comment",
+			method:            "POST",
+			payload:           "review-id=1&comment=What+do+you+think,+@userB?",
+			expectedMentioned: []screenjournal.Username{"userB"},
+		},
+		{
+			description:       "ignores mentions of non-existent users",
+			method:            "POST",
+			payload:           "review-id=1&comment=Hi+@nobody",
+			expectedMentioned: []screenjournal.Username{},
+		},
+		{
+			description:       "ignores mentions of the comment author",
+			method:            "POST",
+			payload:           "review-id=1&comment=Note+to+self+@userA",
+			expectedMentioned: []screenjournal.Username{},
+		},
+		{
+			description:       "announces mentions added in an edit",
+			method:            "PUT",
+			payload:           "comment=Agreed,+@userB",
+			existingComment:   screenjournal.CommentText("Agreed"),
+			expectedMentioned: []screenjournal.Username{"userB"},
+		},
+		{
+			description:       "doesn't re-announce mentions already present before an edit",
+			method:            "PUT",
+			payload:           "comment=Agreed+wholeheartedly,+@userB",
+			existingComment:   screenjournal.CommentText("Agreed, @userB"),
+			expectedMentioned: []screenjournal.Username{},
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			td := makeCommentsTestData()
+			sessions := []mockSessionEntry{td.sessions.userA, td.sessions.userB}
+
+			dataStore := test_sqlite.New()
+			insertMockUsersForSessions(t, dataStore, sessions)
+			if _, err := dataStore.InsertMovie(td.movies.theWaterBoy); err != nil {
+				t.Fatalf("failed to insert mock movie: %v", err)
+			}
+			if _, err := dataStore.InsertReview(td.reviews.userBTheWaterBoy); err != nil {
+				t.Fatalf("failed to insert mock review: %v", err)
+			}
+
+			route := "/api/comments"
+			if tt.method == "PUT" {
+				if _, err := dataStore.InsertComment(screenjournal.ReviewComment{
+					Owner:       td.sessions.userA.session.Username,
+					CommentText: tt.existingComment,
+					Review:      td.reviews.userBTheWaterBoy,
+				}); err != nil {
+					t.Fatalf("failed to insert mock comment: %v", err)
+				}
+				route = "/api/comments/1"
+			}
+
+			announcer := mockAnnouncer{}
+			sessionManager := newMockSessionManager(sessions)
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				Announcer:      &announcer,
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req, err := http.NewRequest(tt.method, route, strings.NewReader(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: td.sessions.userA.token,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+
+			if got, want := rec.Result().StatusCode, http.StatusOK; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			mentioned := []screenjournal.Username{}
+			for _, m := range announcer.announcedMentions {
+				if got, want := m.Author, td.sessions.userA.session.Username; got != want {
+					t.Errorf("mention author=%v, want=%v", got, want)
+				}
+				if m.Comment.ID.IsZero() {
+					t.Errorf("mention is missing its comment")
+				}
+				mentioned = append(mentioned, m.Mentioned)
+			}
+			if got, want := mentioned, tt.expectedMentioned; !reflect.DeepEqual(got, want) {
+				t.Errorf("mentioned=%v, want=%v", got, want)
+			}
+		})
+	}
+}
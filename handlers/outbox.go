@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+func (s Server) emailOutboxGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			Funcs(template.FuncMap{
+				"formatTime": formatIso8601Datetime,
+			}).
+			ParseFS(
+				templatesFS,
+				append(baseTemplates, "templates/pages/email-outbox.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		emails, err := s.store.ReadFailedOutboxEmails()
+		if err != nil {
+			log.Printf("failed to read failed outbox emails: %v", err)
+			http.Error(w, "Failed to read failed emails", http.StatusInternalServerError)
+			return
+		}
+
+		renderTemplate(w, t, "base.html", struct {
+			commonProps
+			Emails []screenjournal.OutboxEmail
+		}{
+			commonProps: makeCommonProps(r.Context()),
+			Emails:      emails,
+		})
+	}
+}
+
+// emailOutboxRetryPost moves a failed email out of the dead-letter queue so
+// that the outbox worker tries sending it again.
+func (s Server) emailOutboxRetryPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := outboxEmailIDFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid email ID", http.StatusBadRequest)
+			return
+		}
+
+		e, err := s.store.ReadOutboxEmail(id)
+		if err != nil {
+			if errors.Is(err, store.ErrOutboxEmailNotFound) {
+				http.Error(w, "Email not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("failed to read outbox email %v: %v", id, err)
+			http.Error(w, "Failed to read email", http.StatusInternalServerError)
+			return
+		}
+
+		if e.Status != screenjournal.OutboxEmailFailed {
+			http.Error(w, "Only failed emails can be retried", http.StatusBadRequest)
+			return
+		}
+
+		e.Status = screenjournal.OutboxEmailPending
+		e.Attempts = 0
+		e.NextAttempt = time.Now()
+		if err := s.store.UpdateOutboxEmail(e); err != nil {
+			log.Printf("failed to requeue outbox email %v: %v", id, err)
+			http.Error(w, "Failed to retry email", http.StatusInternalServerError)
+			return
+		}
+	}
+}
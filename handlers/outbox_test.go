@@ -0,0 +1,169 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/sqlite"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+// insertFailedOutboxEmail adds an email to the outbox and marks it as
+// failed.
+func insertFailedOutboxEmail(t *testing.T, dataStore sqlite.Store, subject string) screenjournal.OutboxEmail {
+	if err := dataStore.InsertOutboxEmail(screenjournal.OutboxEmail{
+		Key:  screenjournal.OutboxEmailKey(subject),
+		From: mail.Address{Name: "ScreenJournal", Address: "activity@thescreenjournal.com"},
+		To: []mail.Address{
+			{Name: "userA", Address: "userA@example.com"},
+		},
+		Subject:     subject,
+		NextAttempt: time.Now(),
+	}); err != nil {
+		t.Fatalf("failed to insert outbox email: %v", err)
+	}
+	due, err := dataStore.ReadDueOutboxEmails(time.Now())
+	if err != nil {
+		t.Fatalf("failed to read outbox emails: %v", err)
+	}
+	e := due[len(due)-1]
+	e.Status = screenjournal.OutboxEmailFailed
+	e.Attempts = 8
+	e.LastError = "connection refused"
+	if err := dataStore.UpdateOutboxEmail(e); err != nil {
+		t.Fatalf("failed to update outbox email: %v", err)
+	}
+	return e
+}
+
+func TestEmailOutboxGet(t *testing.T) {
+	dataStore := test_sqlite.New()
+	sessions := []mockSessionEntry{
+		makeInvitesTestData().sessions.adminUser,
+	}
+	insertMockUsersForSessions(t, dataStore, sessions, screenjournal.Username("admin"))
+	insertFailedOutboxEmail(t, dataStore, "userB posted a new review: The Waterboy")
+
+	sessionManager := newMockSessionManager(sessions)
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	req, err := http.NewRequest("GET", "/admin/email-outbox", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(&http.Cookie{
+		Name:  mockSessionTokenName,
+		Value: makeInvitesTestData().sessions.adminUser.token,
+	})
+
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+
+	if got, want := rec.Result().StatusCode, http.StatusOK; got != want {
+		t.Fatalf("httpStatus=%v, want=%v", got, want)
+	}
+	for _, want := range []string{"userB posted a new review: The Waterboy", "userA@example.com", "connection refused"} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("failed emails page doesn't contain %q", want)
+		}
+	}
+}
+
+func TestEmailOutboxRetryPost(t *testing.T) {
+	for _, tt := range []struct {
+		description  string
+		route        string
+		sessionToken string
+		status       int
+	}{
+		{
+			description:  "moves failed email back to the outbox",
+			route:        "/admin/email-outbox/1/retry",
+			sessionToken: makeInvitesTestData().sessions.adminUser.token,
+			status:       http.StatusOK,
+		},
+		{
+			description:  "rejects retry of email that isn't in the dead-letter queue",
+			route:        "/admin/email-outbox/2/retry",
+			sessionToken: makeInvitesTestData().sessions.adminUser.token,
+			status:       http.StatusBadRequest,
+		},
+		{
+			description:  "returns 404 for nonexistent email",
+			route:        "/admin/email-outbox/99/retry",
+			sessionToken: makeInvitesTestData().sessions.adminUser.token,
+			status:       http.StatusNotFound,
+		},
+		{
+			description:  "rejects retry if user is not an admin",
+			route:        "/admin/email-outbox/1/retry",
+			sessionToken: makeInvitesTestData().sessions.regularUser.token,
+			status:       http.StatusForbidden,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			sessions := []mockSessionEntry{
+				makeInvitesTestData().sessions.adminUser,
+				makeInvitesTestData().sessions.regularUser,
+			}
+			insertMockUsersForSessions(t, dataStore, sessions, screenjournal.Username("admin"))
+			insertFailedOutboxEmail(t, dataStore, "failed")
+			if err := dataStore.InsertOutboxEmail(screenjournal.OutboxEmail{
+				Key:         screenjournal.OutboxEmailKey("pending"),
+				From:        mail.Address{Address: "activity@thescreenjournal.com"},
+				To:          []mail.Address{{Address: "userA@example.com"}},
+				Subject:     "pending",
+				NextAttempt: time.Now().Add(time.Hour),
+			}); err != nil {
+				t.Fatalf("failed to insert outbox email: %v", err)
+			}
+
+			sessionManager := newMockSessionManager(sessions)
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req, err := http.NewRequest("POST", tt.route, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: tt.sessionToken,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+
+			if got, want := rec.Result().StatusCode, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			e, err := dataStore.ReadOutboxEmail(screenjournal.OutboxEmailID(1))
+			if err != nil {
+				t.Fatalf("failed to read outbox email: %v", err)
+			}
+			expectedStatus := screenjournal.OutboxEmailFailed
+			if tt.status == http.StatusOK {
+				expectedStatus = screenjournal.OutboxEmailPending
+			}
+			if got, want := e.Status, expectedStatus; got != want {
+				t.Errorf("status=%v, want=%v", got, want)
+			}
+		})
+	}
+}
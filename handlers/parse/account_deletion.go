@@ -0,0 +1,18 @@
+package parse
+
+import (
+	"errors"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+var ErrInvalidAccountDeletionMode = errors.New("invalid account deletion mode: choose whether to delete or anonymize your content")
+
+func AccountDeletionMode(raw string) (screenjournal.AccountDeletionMode, error) {
+	switch mode := screenjournal.AccountDeletionMode(raw); mode {
+	case screenjournal.AccountDeletionDeleteContent, screenjournal.AccountDeletionAnonymizeContent:
+		return mode, nil
+	default:
+		return screenjournal.AccountDeletionMode(""), ErrInvalidAccountDeletionMode
+	}
+}
@@ -0,0 +1,54 @@
+package parse_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+func TestAccountDeletionMode(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		in          string
+		mode        screenjournal.AccountDeletionMode
+		err         error
+	}{
+		{
+			"delete is valid",
+			"delete",
+			screenjournal.AccountDeletionDeleteContent,
+			nil,
+		},
+		{
+			"anonymize is valid",
+			"anonymize",
+			screenjournal.AccountDeletionAnonymizeContent,
+			nil,
+		},
+		{
+			"empty string is invalid",
+			"",
+			screenjournal.AccountDeletionMode(""),
+			parse.ErrInvalidAccountDeletionMode,
+		},
+		{
+			"unrecognized mode is invalid",
+			"archive",
+			screenjournal.AccountDeletionMode(""),
+			parse.ErrInvalidAccountDeletionMode,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s]", tt.description, tt.in), func(t *testing.T) {
+			mode, err := parse.AccountDeletionMode(tt.in)
+
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := mode, tt.mode; got != want {
+				t.Errorf("mode=%v, want=%v", got, want)
+			}
+		})
+	}
+}
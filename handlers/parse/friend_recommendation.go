@@ -0,0 +1,59 @@
+package parse
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+const recommendationMessageMaxLength = 2000
+
+var (
+	ErrInvalidFriendRecommendationID     = errors.New("invalid recommendation ID")
+	ErrInvalidFriendRecommendationStatus = errors.New("invalid recommendation status")
+	ErrInvalidRecommendationMessage      = errors.New("invalid recommendation message")
+)
+
+func FriendRecommendationID(raw string) (screenjournal.FriendRecommendationID, error) {
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		log.Printf("failed to parse recommendation ID: %v", err)
+		return screenjournal.FriendRecommendationID(0), ErrInvalidFriendRecommendationID
+	}
+
+	if id == 0 {
+		return screenjournal.FriendRecommendationID(0), ErrInvalidFriendRecommendationID
+	}
+
+	return screenjournal.FriendRecommendationID(id), nil
+}
+
+// FriendRecommendationStatus parses a status that a recipient can assign to a
+// recommendation. Recommendations can't go back to pending once the recipient
+// has acted on them.
+func FriendRecommendationStatus(raw string) (screenjournal.FriendRecommendationStatus, error) {
+	switch s := screenjournal.FriendRecommendationStatus(raw); s {
+	case screenjournal.FriendRecommendationWatched, screenjournal.FriendRecommendationDismissed:
+		return s, nil
+	}
+	return screenjournal.FriendRecommendationStatus(""), ErrInvalidFriendRecommendationStatus
+}
+
+// RecommendationMessage parses the optional note that accompanies a
+// recommendation, so an empty message is valid.
+func RecommendationMessage(raw string) (screenjournal.RecommendationMessage, error) {
+	if len(raw) > recommendationMessageMaxLength {
+		return screenjournal.RecommendationMessage(""), ErrInvalidRecommendationMessage
+	}
+
+	message := strings.TrimSpace(raw)
+
+	if scriptTagPattern.FindString(message) != "" {
+		return screenjournal.RecommendationMessage(""), ErrInvalidRecommendationMessage
+	}
+
+	return screenjournal.RecommendationMessage(message), nil
+}
@@ -0,0 +1,142 @@
+package parse_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+func TestFriendRecommendationID(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		in          string
+		id          screenjournal.FriendRecommendationID
+		err         error
+	}{
+		{
+			"ID of 1 is valid",
+			"1",
+			screenjournal.FriendRecommendationID(1),
+			nil,
+		},
+		{
+			"ID of 0 is invalid",
+			"0",
+			screenjournal.FriendRecommendationID(0),
+			parse.ErrInvalidFriendRecommendationID,
+		},
+		{
+			"non-numeric ID is invalid",
+			"banana",
+			screenjournal.FriendRecommendationID(0),
+			parse.ErrInvalidFriendRecommendationID,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s]", tt.description, tt.in), func(t *testing.T) {
+			id, err := parse.FriendRecommendationID(tt.in)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := id, tt.id; got != want {
+				t.Errorf("id=%d, want=%d", got, want)
+			}
+		})
+	}
+}
+
+func TestFriendRecommendationStatus(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		in          string
+		status      screenjournal.FriendRecommendationStatus
+		err         error
+	}{
+		{
+			"watched is valid",
+			"watched",
+			screenjournal.FriendRecommendationWatched,
+			nil,
+		},
+		{
+			"dismissed is valid",
+			"dismissed",
+			screenjournal.FriendRecommendationDismissed,
+			nil,
+		},
+		{
+			"pending is invalid",
+			"pending",
+			screenjournal.FriendRecommendationStatus(""),
+			parse.ErrInvalidFriendRecommendationStatus,
+		},
+		{
+			"empty status is invalid",
+			"",
+			screenjournal.FriendRecommendationStatus(""),
+			parse.ErrInvalidFriendRecommendationStatus,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s]", tt.description, tt.in), func(t *testing.T) {
+			status, err := parse.FriendRecommendationStatus(tt.in)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := status, tt.status; got != want {
+				t.Errorf("status=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestRecommendationMessage(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		in          string
+		message     screenjournal.RecommendationMessage
+		err         error
+	}{
+		{
+			"regular message is valid",
+			"You'll love the ending!",
+			screenjournal.RecommendationMessage("You'll love the ending!"),
+			nil,
+		},
+		{
+			"empty message is valid",
+			"",
+			screenjournal.RecommendationMessage(""),
+			nil,
+		},
+		{
+			"surrounding whitespace is trimmed",
+			"  Trust me  \n",
+			screenjournal.RecommendationMessage("Trust me"),
+			nil,
+		},
+		{
+			"message with script tag is invalid",
+			"<script>alert(1)</script>",
+			screenjournal.RecommendationMessage(""),
+			parse.ErrInvalidRecommendationMessage,
+		},
+		{
+			"message that's too long is invalid",
+			strings.Repeat("A", 2001),
+			screenjournal.RecommendationMessage(""),
+			parse.ErrInvalidRecommendationMessage,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			message, err := parse.RecommendationMessage(tt.in)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := message, tt.message; got != want {
+				t.Errorf("message=%v, want=%v", got, want)
+			}
+		})
+	}
+}
@@ -2,17 +2,27 @@ package parse
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
 )
 
 var (
-	ErrInviteeInvalid    = errors.New("invalid invitee name")
-	ErrInviteCodeInvalid = errors.New("invalid invite code")
+	ErrInviteeInvalid           = errors.New("invalid invitee name")
+	ErrInviteCodeInvalid        = errors.New("invalid invite code")
+	ErrInviteMaxUsesInvalid     = fmt.Errorf("number of uses must be between 1 and %d", screenjournal.MaxInviteUses)
+	ErrInviteLifetimeInvalid    = fmt.Errorf("invite must expire within %d days", maxInviteLifetimeDays)
+	ErrMemberInviteLimitInvalid = fmt.Errorf("member invite limit must be between 0 and %d", screenjournal.MaxMemberInviteLimit)
 
 	validInviteCodeChars map[rune]bool
 )
 
+// maxInviteLifetimeDays is the longest an admin can set an invite to last
+// before it expires, short of never expiring.
+const maxInviteLifetimeDays = 365
+
 func init() {
 	validInviteCodeChars = make(map[rune]bool)
 	for _, c := range screenjournal.InviteCodeCharset {
@@ -38,3 +48,28 @@ func InviteCode(raw string) (screenjournal.InviteCode, error) {
 	}
 	return screenjournal.InviteCode(raw), nil
 }
+
+func InviteMaxUses(raw string) (int, error) {
+	uses, err := strconv.Atoi(raw)
+	if err != nil || uses < 1 || uses > screenjournal.MaxInviteUses {
+		return 0, ErrInviteMaxUsesInvalid
+	}
+	return uses, nil
+}
+
+// InviteLifetime parses the number of days until an invite expires.
+func InviteLifetime(raw string) (time.Duration, error) {
+	days, err := strconv.Atoi(raw)
+	if err != nil || days < 1 || days > maxInviteLifetimeDays {
+		return 0, ErrInviteLifetimeInvalid
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+func MemberInviteLimit(raw string) (int, error) {
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 0 || limit > screenjournal.MaxMemberInviteLimit {
+		return 0, ErrMemberInviteLimitInvalid
+	}
+	return limit, nil
+}
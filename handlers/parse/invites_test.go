@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mtlynch/screenjournal/v2/handlers/parse"
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
@@ -146,3 +147,150 @@ func TestInviteCode(t *testing.T) {
 		})
 	}
 }
+
+func TestInviteMaxUses(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		input       string
+		uses        int
+		err         error
+	}{
+		{
+			"single use is valid",
+			"1",
+			1,
+			nil,
+		},
+		{
+			"maximum number of uses is valid",
+			fmt.Sprintf("%d", screenjournal.MaxInviteUses),
+			screenjournal.MaxInviteUses,
+			nil,
+		},
+		{
+			"zero uses is invalid",
+			"0",
+			0,
+			parse.ErrInviteMaxUsesInvalid,
+		},
+		{
+			"more than the maximum number of uses is invalid",
+			fmt.Sprintf("%d", screenjournal.MaxInviteUses+1),
+			0,
+			parse.ErrInviteMaxUsesInvalid,
+		},
+		{
+			"non-numeric value is invalid",
+			"banana",
+			0,
+			parse.ErrInviteMaxUsesInvalid,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s]", tt.description, tt.input), func(t *testing.T) {
+			uses, err := parse.InviteMaxUses(tt.input)
+
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := uses, tt.uses; got != want {
+				t.Errorf("uses=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestInviteLifetime(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		input       string
+		lifetime    time.Duration
+		err         error
+	}{
+		{
+			"one day is valid",
+			"1",
+			24 * time.Hour,
+			nil,
+		},
+		{
+			"one year is valid",
+			"365",
+			365 * 24 * time.Hour,
+			nil,
+		},
+		{
+			"zero days is invalid",
+			"0",
+			0,
+			parse.ErrInviteLifetimeInvalid,
+		},
+		{
+			"more than a year is invalid",
+			"366",
+			0,
+			parse.ErrInviteLifetimeInvalid,
+		},
+		{
+			"empty string is invalid",
+			"",
+			0,
+			parse.ErrInviteLifetimeInvalid,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s]", tt.description, tt.input), func(t *testing.T) {
+			lifetime, err := parse.InviteLifetime(tt.input)
+
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := lifetime, tt.lifetime; got != want {
+				t.Errorf("lifetime=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestMemberInviteLimit(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		input       string
+		limit       int
+		err         error
+	}{
+		{
+			"zero is valid",
+			"0",
+			0,
+			nil,
+		},
+		{
+			"maximum limit is valid",
+			fmt.Sprintf("%d", screenjournal.MaxMemberInviteLimit),
+			screenjournal.MaxMemberInviteLimit,
+			nil,
+		},
+		{
+			"negative limit is invalid",
+			"-1",
+			0,
+			parse.ErrMemberInviteLimitInvalid,
+		},
+		{
+			"more than the maximum limit is invalid",
+			fmt.Sprintf("%d", screenjournal.MaxMemberInviteLimit+1),
+			0,
+			parse.ErrMemberInviteLimitInvalid,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s]", tt.description, tt.input), func(t *testing.T) {
+			limit, err := parse.MemberInviteLimit(tt.input)
+
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := limit, tt.limit; got != want {
+				t.Errorf("limit=%v, want=%v", got, want)
+			}
+		})
+	}
+}
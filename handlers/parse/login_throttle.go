@@ -0,0 +1,30 @@
+package parse
+
+import (
+	"errors"
+	"net"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+var ErrInvalidLoginThrottle = errors.New("invalid login throttle")
+
+// LoginThrottle parses the scope and key that identify a login throttle.
+func LoginThrottle(rawScope, rawKey string) (screenjournal.LoginThrottleScope, string, error) {
+	switch scope := screenjournal.LoginThrottleScope(rawScope); scope {
+	case screenjournal.LoginThrottleScopeUsername:
+		username, err := Username(rawKey)
+		if err != nil {
+			return screenjournal.LoginThrottleScope(""), "", ErrInvalidLoginThrottle
+		}
+		return scope, username.String(), nil
+	case screenjournal.LoginThrottleScopeIP:
+		ip := net.ParseIP(rawKey)
+		if ip == nil {
+			return screenjournal.LoginThrottleScope(""), "", ErrInvalidLoginThrottle
+		}
+		return scope, ip.String(), nil
+	default:
+		return screenjournal.LoginThrottleScope(""), "", ErrInvalidLoginThrottle
+	}
+}
@@ -0,0 +1,82 @@
+package parse_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+func TestLoginThrottle(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		scope       string
+		key         string
+		wantScope   screenjournal.LoginThrottleScope
+		wantKey     string
+		err         error
+	}{
+		{
+			"username throttle is valid",
+			"username",
+			"userA",
+			screenjournal.LoginThrottleScopeUsername,
+			"userA",
+			nil,
+		},
+		{
+			"IPv4 throttle is valid",
+			"ip",
+			"203.0.113.7",
+			screenjournal.LoginThrottleScopeIP,
+			"203.0.113.7",
+			nil,
+		},
+		{
+			"IPv6 throttle is valid",
+			"ip",
+			"2001:db8::1",
+			screenjournal.LoginThrottleScopeIP,
+			"2001:db8::1",
+			nil,
+		},
+		{
+			"invalid username is invalid",
+			"username",
+			"user A",
+			screenjournal.LoginThrottleScope(""),
+			"",
+			parse.ErrInvalidLoginThrottle,
+		},
+		{
+			"invalid IP is invalid",
+			"ip",
+			"not-an-ip",
+			screenjournal.LoginThrottleScope(""),
+			"",
+			parse.ErrInvalidLoginThrottle,
+		},
+		{
+			"unknown scope is invalid",
+			"email",
+			"userA",
+			screenjournal.LoginThrottleScope(""),
+			"",
+			parse.ErrInvalidLoginThrottle,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s/%s]", tt.description, tt.scope, tt.key), func(t *testing.T) {
+			scope, key, err := parse.LoginThrottle(tt.scope, tt.key)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := scope, tt.wantScope; got != want {
+				t.Errorf("scope=%v, want=%v", got, want)
+			}
+			if got, want := key, tt.wantKey; got != want {
+				t.Errorf("key=%v, want=%v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package parse
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+var (
+	ErrInvalidDigestFrequency   = errors.New("digest frequency must be immediate, daily, weekly, or off")
+	ErrInvalidNotificationScope = errors.New("notification scope must be everyone or following")
+	ErrInvalidUserRelationship  = errors.New("relationship must be follow, mute, or none")
+	ErrInvalidNotificationID    = errors.New("invalid notification ID")
+)
+
+func DigestFrequency(raw string) (screenjournal.DigestFrequency, error) {
+	switch f := screenjournal.DigestFrequency(raw); f {
+	case screenjournal.DigestImmediate, screenjournal.DigestDaily, screenjournal.DigestWeekly, screenjournal.DigestOff:
+		return f, nil
+	default:
+		return screenjournal.DigestFrequency(""), ErrInvalidDigestFrequency
+	}
+}
+
+func NotificationScope(raw string) (screenjournal.NotificationScope, error) {
+	switch s := screenjournal.NotificationScope(raw); s {
+	case screenjournal.NotifyEveryone, screenjournal.NotifyFollowing:
+		return s, nil
+	default:
+		return screenjournal.NotificationScope(""), ErrInvalidNotificationScope
+	}
+}
+
+// UserRelationship parses a relationship from a form value, where "none"
+// clears any existing follow or mute.
+func UserRelationship(raw string) (screenjournal.UserRelationship, error) {
+	switch raw {
+	case "none":
+		return screenjournal.RelationshipNone, nil
+	case screenjournal.RelationshipFollow.String(), screenjournal.RelationshipMute.String():
+		return screenjournal.UserRelationship(raw), nil
+	default:
+		return screenjournal.RelationshipNone, ErrInvalidUserRelationship
+	}
+}
+
+func NotificationID(raw string) (screenjournal.NotificationID, error) {
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		log.Printf("failed to parse notification ID: %v", err)
+		return screenjournal.NotificationID(0), ErrInvalidNotificationID
+	}
+
+	if id == 0 {
+		return screenjournal.NotificationID(0), ErrInvalidNotificationID
+	}
+
+	return screenjournal.NotificationID(id), nil
+}
@@ -0,0 +1,107 @@
+package parse_test
+
+import (
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+func TestDigestFrequency(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		in          string
+		frequency   screenjournal.DigestFrequency
+		err         error
+	}{
+		{"immediate is valid", "immediate", screenjournal.DigestImmediate, nil},
+		{"daily is valid", "daily", screenjournal.DigestDaily, nil},
+		{"weekly is valid", "weekly", screenjournal.DigestWeekly, nil},
+		{"off is valid", "off", screenjournal.DigestOff, nil},
+		{"empty string is invalid", "", screenjournal.DigestFrequency(""), parse.ErrInvalidDigestFrequency},
+		{"unknown frequency is invalid", "hourly", screenjournal.DigestFrequency(""), parse.ErrInvalidDigestFrequency},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			frequency, err := parse.DigestFrequency(tt.in)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := frequency, tt.frequency; got != want {
+				t.Errorf("frequency=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestNotificationScope(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		in          string
+		scope       screenjournal.NotificationScope
+		err         error
+	}{
+		{"everyone is valid", "everyone", screenjournal.NotifyEveryone, nil},
+		{"following is valid", "following", screenjournal.NotifyFollowing, nil},
+		{"empty string is invalid", "", screenjournal.NotificationScope(""), parse.ErrInvalidNotificationScope},
+		{"unknown scope is invalid", "friends", screenjournal.NotificationScope(""), parse.ErrInvalidNotificationScope},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			scope, err := parse.NotificationScope(tt.in)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := scope, tt.scope; got != want {
+				t.Errorf("scope=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestUserRelationship(t *testing.T) {
+	for _, tt := range []struct {
+		description  string
+		in           string
+		relationship screenjournal.UserRelationship
+		err          error
+	}{
+		{"follow is valid", "follow", screenjournal.RelationshipFollow, nil},
+		{"mute is valid", "mute", screenjournal.RelationshipMute, nil},
+		{"none clears the relationship", "none", screenjournal.RelationshipNone, nil},
+		{"empty string is invalid", "", screenjournal.RelationshipNone, parse.ErrInvalidUserRelationship},
+		{"unknown relationship is invalid", "block", screenjournal.RelationshipNone, parse.ErrInvalidUserRelationship},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			relationship, err := parse.UserRelationship(tt.in)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := relationship, tt.relationship; got != want {
+				t.Errorf("relationship=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestNotificationID(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		in          string
+		id          screenjournal.NotificationID
+		err         error
+	}{
+		{"ID of 1 is valid", "1", screenjournal.NotificationID(1), nil},
+		{"ID of 0 is invalid", "0", screenjournal.NotificationID(0), parse.ErrInvalidNotificationID},
+		{"ID of -1 is invalid", "-1", screenjournal.NotificationID(0), parse.ErrInvalidNotificationID},
+		{"non-numeric ID is invalid", "banana", screenjournal.NotificationID(0), parse.ErrInvalidNotificationID},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			id, err := parse.NotificationID(tt.in)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := id, tt.id; got != want {
+				t.Errorf("id=%v, want=%v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package parse
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+var ErrInvalidOutboxEmailID = errors.New("invalid outbox email ID")
+
+func OutboxEmailID(raw string) (screenjournal.OutboxEmailID, error) {
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		log.Printf("failed to parse outbox email ID: %v", err)
+		return screenjournal.OutboxEmailID(0), ErrInvalidOutboxEmailID
+	}
+
+	if id == 0 {
+		return screenjournal.OutboxEmailID(0), ErrInvalidOutboxEmailID
+	}
+
+	return screenjournal.OutboxEmailID(id), nil
+}
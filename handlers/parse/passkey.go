@@ -0,0 +1,43 @@
+package parse
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+var (
+	ErrInvalidPasskeyID   = errors.New("invalid passkey ID")
+	ErrInvalidPasskeyName = errors.New("passkey name must be 1-60 printable characters")
+)
+
+// passkeyIDMaxLength is the base64url length of the longest credential ID
+// that WebAuthn allows.
+const passkeyIDMaxLength = 1364
+
+func PasskeyID(raw string) (screenjournal.PasskeyID, error) {
+	if raw == "" || len(raw) > passkeyIDMaxLength {
+		return screenjournal.PasskeyID(""), ErrInvalidPasskeyID
+	}
+	if _, err := base64.RawURLEncoding.DecodeString(raw); err != nil {
+		return screenjournal.PasskeyID(""), ErrInvalidPasskeyID
+	}
+	return screenjournal.PasskeyID(raw), nil
+}
+
+func PasskeyName(raw string) (screenjournal.PasskeyName, error) {
+	name := strings.TrimSpace(raw)
+	if name == "" || utf8.RuneCountInString(name) > screenjournal.PasskeyNameMaxLength {
+		return screenjournal.PasskeyName(""), ErrInvalidPasskeyName
+	}
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return screenjournal.PasskeyName(""), ErrInvalidPasskeyName
+		}
+	}
+	return screenjournal.PasskeyName(name), nil
+}
@@ -0,0 +1,128 @@
+package parse_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+func TestPasskeyID(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		input       string
+		id          screenjournal.PasskeyID
+		err         error
+	}{
+		{
+			"base64url ID is valid",
+			"q83v-_8AEQ",
+			screenjournal.PasskeyID("q83v-_8AEQ"),
+			nil,
+		},
+		{
+			"empty string is invalid",
+			"",
+			screenjournal.PasskeyID(""),
+			parse.ErrInvalidPasskeyID,
+		},
+		{
+			"standard base64 characters are invalid",
+			"q83v+/8AEQ",
+			screenjournal.PasskeyID(""),
+			parse.ErrInvalidPasskeyID,
+		},
+		{
+			"padded ID is invalid",
+			"q83v-_8AEQ==",
+			screenjournal.PasskeyID(""),
+			parse.ErrInvalidPasskeyID,
+		},
+		{
+			"very long ID is invalid",
+			strings.Repeat("A", 1368),
+			screenjournal.PasskeyID(""),
+			parse.ErrInvalidPasskeyID,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s]", tt.description, tt.input), func(t *testing.T) {
+			id, err := parse.PasskeyID(tt.input)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := id, tt.id; got != want {
+				t.Errorf("id=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestPasskeyName(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		input       string
+		name        screenjournal.PasskeyName
+		err         error
+	}{
+		{
+			"simple name is valid",
+			"Work laptop",
+			screenjournal.PasskeyName("Work laptop"),
+			nil,
+		},
+		{
+			"name with non-ASCII characters is valid",
+			"Zoë's phone",
+			screenjournal.PasskeyName("Zoë's phone"),
+			nil,
+		},
+		{
+			"surrounding whitespace is removed",
+			"  Phone\n",
+			screenjournal.PasskeyName("Phone"),
+			nil,
+		},
+		{
+			"name at maximum length is valid",
+			strings.Repeat("é", 60),
+			screenjournal.PasskeyName(strings.Repeat("é", 60)),
+			nil,
+		},
+		{
+			"empty string is invalid",
+			"",
+			screenjournal.PasskeyName(""),
+			parse.ErrInvalidPasskeyName,
+		},
+		{
+			"whitespace-only string is invalid",
+			"   ",
+			screenjournal.PasskeyName(""),
+			parse.ErrInvalidPasskeyName,
+		},
+		{
+			"name with control characters is invalid",
+			"Phone\x00",
+			screenjournal.PasskeyName(""),
+			parse.ErrInvalidPasskeyName,
+		},
+		{
+			"name over maximum length is invalid",
+			strings.Repeat("a", 61),
+			screenjournal.PasskeyName(""),
+			parse.ErrInvalidPasskeyName,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s]", tt.description, tt.input), func(t *testing.T) {
+			name, err := parse.PasskeyName(tt.input)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := name, tt.name; got != want {
+				t.Errorf("name=%v, want=%v", got, want)
+			}
+		})
+	}
+}
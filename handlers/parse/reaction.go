@@ -9,8 +9,9 @@ import (
 )
 
 var (
-	ErrInvalidReactionID    = errors.New("invalid reaction ID")
-	ErrInvalidReactionEmoji = errors.New("invalid reaction emoji")
+	ErrInvalidReactionID         = errors.New("invalid reaction ID")
+	ErrInvalidReactionEmoji      = errors.New("invalid reaction emoji")
+	ErrInvalidReactionTargetType = errors.New("invalid reaction target type")
 )
 
 func ReactionID(raw string) (screenjournal.ReactionID, error) {
@@ -40,3 +41,14 @@ func ReactionEmoji(raw string) (screenjournal.ReactionEmoji, error) {
 
 	return screenjournal.ReactionEmoji{}, ErrInvalidReactionEmoji
 }
+
+func ReactionTargetType(raw string) (screenjournal.ReactionTargetType, error) {
+	switch screenjournal.ReactionTargetType(raw) {
+	case screenjournal.ReactionTargetReview:
+		return screenjournal.ReactionTargetReview, nil
+	case screenjournal.ReactionTargetComment:
+		return screenjournal.ReactionTargetComment, nil
+	}
+
+	return screenjournal.ReactionTargetType(""), ErrInvalidReactionTargetType
+}
@@ -126,3 +126,53 @@ func TestReactionEmoji(t *testing.T) {
 		})
 	}
 }
+
+func TestReactionTargetType(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		in          string
+		targetType  screenjournal.ReactionTargetType
+		err         error
+	}{
+		{
+			"review is valid",
+			"review",
+			screenjournal.ReactionTargetReview,
+			nil,
+		},
+		{
+			"comment is valid",
+			"comment",
+			screenjournal.ReactionTargetComment,
+			nil,
+		},
+		{
+			"empty string is invalid",
+			"",
+			screenjournal.ReactionTargetType(""),
+			parse.ErrInvalidReactionTargetType,
+		},
+		{
+			"target types are case-sensitive",
+			"Comment",
+			screenjournal.ReactionTargetType(""),
+			parse.ErrInvalidReactionTargetType,
+		},
+		{
+			"unknown target type is invalid",
+			"user",
+			screenjournal.ReactionTargetType(""),
+			parse.ErrInvalidReactionTargetType,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s]", tt.description, tt.in), func(t *testing.T) {
+			targetType, err := parse.ReactionTargetType(tt.in)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := targetType, tt.targetType; got != want {
+				t.Errorf("targetType=%v, want=%v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package parse
+
+import (
+	"errors"
+	"log"
+	"strconv"
+)
+
+// MinRecapYear is the earliest year that can have a recap. Watch dates before
+// 2020 are not allowed in the datastore.
+const MinRecapYear = 2020
+
+var ErrInvalidRecapYear = errors.New("invalid recap year")
+
+func RecapYear(raw string) (int, error) {
+	year, err := strconv.ParseUint(raw, 10, 16)
+	if err != nil {
+		log.Printf("failed to parse recap year: %v", err)
+		return 0, ErrInvalidRecapYear
+	}
+
+	if year < MinRecapYear || year > 9999 {
+		return 0, ErrInvalidRecapYear
+	}
+
+	return int(year), nil
+}
@@ -0,0 +1,70 @@
+package parse_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+)
+
+func TestRecapYear(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		in          string
+		year        int
+		err         error
+	}{
+		{
+			"typical year is valid",
+			"2024",
+			2024,
+			nil,
+		},
+		{
+			"earliest recap year is valid",
+			"2020",
+			2020,
+			nil,
+		},
+		{
+			"year before watch dates are allowed is invalid",
+			"2019",
+			0,
+			parse.ErrInvalidRecapYear,
+		},
+		{
+			"five-digit year is invalid",
+			"20240",
+			0,
+			parse.ErrInvalidRecapYear,
+		},
+		{
+			"negative year is invalid",
+			"-2024",
+			0,
+			parse.ErrInvalidRecapYear,
+		},
+		{
+			"non-numeric year is invalid",
+			"banana",
+			0,
+			parse.ErrInvalidRecapYear,
+		},
+		{
+			"empty year is invalid",
+			"",
+			0,
+			parse.ErrInvalidRecapYear,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s]", tt.description, tt.in), func(t *testing.T) {
+			year, err := parse.RecapYear(tt.in)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := year, tt.year; got != want {
+				t.Errorf("year=%d, want=%d", got, want)
+			}
+		})
+	}
+}
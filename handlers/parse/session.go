@@ -0,0 +1,23 @@
+package parse
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+var ErrInvalidSessionHandle = errors.New("invalid session handle")
+
+// sessionHandleLength is the length of a hex-encoded session handle.
+const sessionHandleLength = 32
+
+func SessionHandle(raw string) (screenjournal.SessionHandle, error) {
+	if len(raw) != sessionHandleLength {
+		return screenjournal.SessionHandle(""), ErrInvalidSessionHandle
+	}
+	if _, err := hex.DecodeString(raw); err != nil {
+		return screenjournal.SessionHandle(""), ErrInvalidSessionHandle
+	}
+	return screenjournal.SessionHandle(raw), nil
+}
@@ -0,0 +1,53 @@
+package parse_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+func TestSessionHandle(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		input       string
+		handle      screenjournal.SessionHandle
+		err         error
+	}{
+		{
+			"hex handle is valid",
+			"0123456789abcdef0123456789abcdef",
+			screenjournal.SessionHandle("0123456789abcdef0123456789abcdef"),
+			nil,
+		},
+		{
+			"empty string is invalid",
+			"",
+			screenjournal.SessionHandle(""),
+			parse.ErrInvalidSessionHandle,
+		},
+		{
+			"short handle is invalid",
+			"0123456789abcdef",
+			screenjournal.SessionHandle(""),
+			parse.ErrInvalidSessionHandle,
+		},
+		{
+			"non-hex characters are invalid",
+			"0123456789abcdef0123456789abcdeg",
+			screenjournal.SessionHandle(""),
+			parse.ErrInvalidSessionHandle,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s]", tt.description, tt.input), func(t *testing.T) {
+			handle, err := parse.SessionHandle(tt.input)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := handle, tt.handle; got != want {
+				t.Errorf("handle=%v, want=%v", got, want)
+			}
+		})
+	}
+}
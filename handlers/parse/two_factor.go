@@ -0,0 +1,45 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+var (
+	ErrInvalidTwoFactorCode  = errors.New("invalid two-factor code")
+	ErrInvalidLoginChallenge = errors.New("invalid login challenge")
+)
+
+// twoFactorCodeMaxLength is long enough for a recovery code with extra
+// separators, which is longer than any TOTP code.
+const twoFactorCodeMaxLength = 32
+
+// loginChallengeMaxLength is well beyond the length of a challenge for the
+// longest valid username.
+const loginChallengeMaxLength = 256
+
+// TwoFactorCode parses either a TOTP code or a recovery code. Verifying the
+// code decides which kind it is.
+func TwoFactorCode(raw string) (string, error) {
+	code := strings.TrimSpace(raw)
+	if code == "" || len(code) > twoFactorCodeMaxLength {
+		return "", ErrInvalidTwoFactorCode
+	}
+	for _, r := range code {
+		switch {
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-', r == ' ':
+			continue
+		}
+		return "", ErrInvalidTwoFactorCode
+	}
+	return code, nil
+}
+
+func LoginChallenge(raw string) (screenjournal.LoginChallenge, error) {
+	if raw == "" || len(raw) > loginChallengeMaxLength {
+		return screenjournal.LoginChallenge(""), ErrInvalidLoginChallenge
+	}
+	return screenjournal.LoginChallenge(raw), nil
+}
@@ -0,0 +1,72 @@
+package parse_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+)
+
+func TestTwoFactorCode(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		input       string
+		code        string
+		err         error
+	}{
+		{
+			"six-digit TOTP code is valid",
+			"123456",
+			"123456",
+			nil,
+		},
+		{
+			"recovery code is valid",
+			"abcde-23456",
+			"abcde-23456",
+			nil,
+		},
+		{
+			"surrounding whitespace is removed",
+			"  123456\n",
+			"123456",
+			nil,
+		},
+		{
+			"empty string is invalid",
+			"",
+			"",
+			parse.ErrInvalidTwoFactorCode,
+		},
+		{
+			"whitespace-only string is invalid",
+			"   ",
+			"",
+			parse.ErrInvalidTwoFactorCode,
+		},
+		{
+			"code with punctuation is invalid",
+			"12345;",
+			"",
+			parse.ErrInvalidTwoFactorCode,
+		},
+		{
+			"very long code is invalid",
+			strings.Repeat("1", 33),
+			"",
+			parse.ErrInvalidTwoFactorCode,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s]", tt.description, tt.input), func(t *testing.T) {
+			code, err := parse.TwoFactorCode(tt.input)
+
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := code, tt.code; got != want {
+				t.Errorf("code=%v, want=%v", got, want)
+			}
+		})
+	}
+}
@@ -27,3 +27,18 @@ func Username(username string) (screenjournal.Username, error) {
 
 	return screenjournal.Username(username), nil
 }
+
+// NewUsername parses the username for a new account. It rejects usernames
+// that are valid for existing accounts but that the app reserves for itself.
+func NewUsername(username string) (screenjournal.Username, error) {
+	parsed, err := Username(username)
+	if err != nil {
+		return screenjournal.Username(""), err
+	}
+
+	if parsed.Equal(screenjournal.FormerMemberUsername) {
+		return screenjournal.Username(""), ErrInvalidUsername
+	}
+
+	return parsed, nil
+}
@@ -125,3 +125,42 @@ func TestUsername(t *testing.T) {
 		})
 	}
 }
+
+func TestNewUsername(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		in          string
+		username    screenjournal.Username
+		err         error
+	}{
+		{
+			"regular username is valid",
+			"jerry.seinfeld",
+			screenjournal.Username("jerry.seinfeld"),
+			nil,
+		},
+		{
+			"invalid username is invalid",
+			"jerry seinfeld",
+			screenjournal.Username(""),
+			parse.ErrInvalidUsername,
+		},
+		{
+			"former member placeholder is invalid",
+			screenjournal.FormerMemberUsername.String(),
+			screenjournal.Username(""),
+			parse.ErrInvalidUsername,
+		},
+	} {
+		t.Run(fmt.Sprintf("%s [%s]", tt.description, tt.in), func(t *testing.T) {
+			username, err := parse.NewUsername(tt.in)
+
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := username, tt.username; got != want {
+				t.Errorf("username=%v, want=%v", got, want)
+			}
+		})
+	}
+}
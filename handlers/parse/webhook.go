@@ -0,0 +1,50 @@
+package parse
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"strconv"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+const webhookURLMaxLength = 2000
+
+var (
+	ErrInvalidWebhookID  = errors.New("invalid webhook ID")
+	ErrInvalidWebhookURL = errors.New("webhook URL must be an absolute http or https URL")
+)
+
+func WebhookID(raw string) (screenjournal.WebhookID, error) {
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		log.Printf("failed to parse webhook ID: %v", err)
+		return screenjournal.WebhookID(0), ErrInvalidWebhookID
+	}
+
+	if id == 0 {
+		return screenjournal.WebhookID(0), ErrInvalidWebhookID
+	}
+
+	return screenjournal.WebhookID(id), nil
+}
+
+func WebhookURL(raw string) (screenjournal.WebhookURL, error) {
+	if len(raw) > webhookURLMaxLength {
+		return screenjournal.WebhookURL(""), ErrInvalidWebhookURL
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return screenjournal.WebhookURL(""), ErrInvalidWebhookURL
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return screenjournal.WebhookURL(""), ErrInvalidWebhookURL
+	}
+	if u.Host == "" || u.User != nil {
+		return screenjournal.WebhookURL(""), ErrInvalidWebhookURL
+	}
+
+	return screenjournal.WebhookURL(raw), nil
+}
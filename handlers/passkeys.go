@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/passkey"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/webauthn"
+)
+
+type passkeysProps struct {
+	Available bool
+	Passkeys  []screenjournal.Passkey
+}
+
+func (s Server) authPasskeyOptionsPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.passkeys == nil {
+			http.Error(w, "Passkeys are not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		options, err := s.passkeys.BeginLogin()
+		if err != nil {
+			log.Printf("failed to begin passkey login: %v", err)
+			http.Error(w, "Failed to start passkey login", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(options); err != nil {
+			log.Printf("failed to encode passkey login options: %v", err)
+		}
+	}
+}
+
+// authPasskeyPost logs the user in with a passkey. Passkey logins skip the
+// two-factor code step because the authenticator already verified the user
+// with a PIN or biometric in addition to proving possession of the passkey.
+func (s Server) authPasskeyPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.passkeys == nil {
+			http.Error(w, "Passkeys are not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		var response webauthn.AssertionResponse
+		if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+			log.Printf("invalid passkey auth request: %v", err)
+			http.Error(w, "Invalid passkey", http.StatusBadRequest)
+			return
+		}
+
+		username, err := s.passkeys.FinishLogin(response)
+		if err != nil {
+			log.Printf("passkey auth failed: %v", err)
+			switch {
+			case errors.Is(err, passkey.ErrInvalidChallenge):
+				http.Error(w, "Passkey login expired. Please try again.", http.StatusUnauthorized)
+			case errors.Is(err, passkey.ErrUnrecognizedPasskey):
+				http.Error(w, "That passkey isn't registered with ScreenJournal", http.StatusUnauthorized)
+			case errors.Is(err, passkey.ErrInvalidCredential):
+				http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+			default:
+				http.Error(w, "Failed to verify passkey", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		s.logIn(w, r, username)
+	}
+}
+
+func (s Server) accountPasskeysOptionsPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.passkeys == nil {
+			http.Error(w, "Passkeys are not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		options, err := s.passkeys.BeginRegistration(username)
+		if err != nil {
+			log.Printf("failed to begin passkey registration for user %s: %v", username, err)
+			http.Error(w, "Failed to start passkey registration", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(options); err != nil {
+			log.Printf("failed to encode passkey registration options: %v", err)
+		}
+	}
+}
+
+func (s Server) accountPasskeysPost() http.HandlerFunc {
+	t := template.Must(template.New("passkeys-list.html").Funcs(reviewPageFns).ParseFS(templatesFS, "templates/fragments/passkeys-list.html"))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.passkeys == nil {
+			http.Error(w, "Passkeys are not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		name, response, err := passkeyRegistrationFromRequest(r)
+		if err != nil {
+			log.Printf("invalid passkey registration request: %v", err)
+			http.Error(w, "Invalid passkey registration: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		if _, err := s.passkeys.FinishRegistration(username, name, response); err != nil {
+			switch {
+			case errors.Is(err, passkey.ErrInvalidChallenge):
+				http.Error(w, "Passkey registration expired. Please try again.", http.StatusBadRequest)
+			case errors.Is(err, passkey.ErrInvalidCredential):
+				http.Error(w, "Your browser returned an invalid passkey", http.StatusBadRequest)
+			case errors.Is(err, passkey.ErrAlreadyRegistered):
+				http.Error(w, "That passkey is already registered", http.StatusConflict)
+			default:
+				log.Printf("failed to register passkey for user %s: %v", username, err)
+				http.Error(w, "Failed to register passkey", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		s.renderPasskeysList(w, t, username)
+	}
+}
+
+func (s Server) accountPasskeysPut() http.HandlerFunc {
+	t := template.Must(template.New("passkeys-list.html").Funcs(reviewPageFns).ParseFS(templatesFS, "templates/fragments/passkeys-list.html"))
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := passkeyIDFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid passkey ID", http.StatusBadRequest)
+			return
+		}
+
+		name, err := parse.PasskeyName(r.PostFormValue("name"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		if err := s.store.RenamePasskey(username, id, name); err != nil {
+			if errors.Is(err, store.ErrPasskeyNotFound) {
+				http.Error(w, "Passkey not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("failed to rename passkey %s for user %s: %v", id, username, err)
+			http.Error(w, "Failed to rename passkey", http.StatusInternalServerError)
+			return
+		}
+
+		s.renderPasskeysList(w, t, username)
+	}
+}
+
+func (s Server) accountPasskeysDelete() http.HandlerFunc {
+	t := template.Must(template.New("passkeys-list.html").Funcs(reviewPageFns).ParseFS(templatesFS, "templates/fragments/passkeys-list.html"))
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := passkeyIDFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid passkey ID", http.StatusBadRequest)
+			return
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		if err := s.store.DeletePasskey(username, id); err != nil {
+			if errors.Is(err, store.ErrPasskeyNotFound) {
+				http.Error(w, "Passkey not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("failed to delete passkey %s for user %s: %v", id, username, err)
+			http.Error(w, "Failed to delete passkey", http.StatusInternalServerError)
+			return
+		}
+
+		s.renderPasskeysList(w, t, username)
+	}
+}
+
+func (s Server) renderPasskeysList(w http.ResponseWriter, t *template.Template, username screenjournal.Username) {
+	props, err := s.readPasskeys(username)
+	if err != nil {
+		log.Printf("failed to read passkeys for user %s: %v", username, err)
+		http.Error(w, "Failed to read passkeys", http.StatusInternalServerError)
+		return
+	}
+	renderTemplate(w, t, "passkeys-list.html", props)
+}
+
+func (s Server) readPasskeys(username screenjournal.Username) (passkeysProps, error) {
+	passkeys, err := s.store.ReadPasskeys(username)
+	if err != nil {
+		return passkeysProps{}, err
+	}
+	return passkeysProps{
+		Available: s.passkeys != nil,
+		Passkeys:  passkeys,
+	}, nil
+}
+
+func passkeyRegistrationFromRequest(r *http.Request) (screenjournal.PasskeyName, webauthn.RegistrationResponse, error) {
+	body := struct {
+		Name       string                        `json:"name"`
+		Credential webauthn.RegistrationResponse `json:"credential"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return screenjournal.PasskeyName(""), webauthn.RegistrationResponse{}, err
+	}
+
+	name, err := parse.PasskeyName(body.Name)
+	if err != nil {
+		return screenjournal.PasskeyName(""), webauthn.RegistrationResponse{}, err
+	}
+
+	return name, body.Credential, nil
+}
@@ -0,0 +1,341 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/passkey"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/sqlite"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+	"github.com/mtlynch/screenjournal/v2/twofactor"
+	"github.com/mtlynch/screenjournal/v2/webauthn"
+	"github.com/mtlynch/screenjournal/v2/webauthn/webauthntest"
+)
+
+const passkeyTestOrigin = "https://screenjournal.example.com"
+
+func newPasskeyManager(t *testing.T, dataStore sqlite.Store) passkey.Manager {
+	t.Helper()
+	rp, err := webauthn.New(passkeyTestOrigin, "ScreenJournal")
+	if err != nil {
+		t.Fatalf("failed to create relying party: %v", err)
+	}
+	return passkey.New(dataStore, rp, time.Now)
+}
+
+// mustRegisterPasskeyForUser registers a passkey directly through the
+// manager, skipping the HTTP routes.
+func mustRegisterPasskeyForUser(t *testing.T, m passkey.Manager, authenticator *webauthntest.Authenticator, username screenjournal.Username) screenjournal.Passkey {
+	t.Helper()
+	options, err := m.BeginRegistration(username)
+	if err != nil {
+		t.Fatalf("failed to begin passkey registration: %v", err)
+	}
+	response, err := authenticator.Register(options)
+	if err != nil {
+		t.Fatalf("authenticator failed to register: %v", err)
+	}
+	p, err := m.FinishRegistration(username, "Laptop", response)
+	if err != nil {
+		t.Fatalf("failed to finish passkey registration: %v", err)
+	}
+	return p
+}
+
+func postJSON(t *testing.T, s handlers.Server, route string, body any, sessionToken string) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest("POST", route, strings.NewReader(string(payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sessionToken != "" {
+		req.AddCookie(&http.Cookie{
+			Name:  mockSessionTokenName,
+			Value: sessionToken,
+		})
+	}
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPasskeyLogin(t *testing.T) {
+	for _, tt := range []struct {
+		description   string
+		register      bool
+		twoFactor     bool
+		tamper        func(*webauthn.AssertionResponse)
+		replay        bool
+		status        int
+		sessionsCount int
+	}{
+		{
+			description:   "registered passkey logs in",
+			register:      true,
+			status:        http.StatusOK,
+			sessionsCount: 1,
+		},
+		{
+			description:   "passkey login skips the two-factor code step",
+			register:      true,
+			twoFactor:     true,
+			status:        http.StatusOK,
+			sessionsCount: 1,
+		},
+		{
+			description: "unregistered passkey fails",
+			status:      http.StatusUnauthorized,
+		},
+		{
+			description: "forged signature fails",
+			register:    true,
+			tamper: func(r *webauthn.AssertionResponse) {
+				r.Response.Signature = []byte("forged")
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			description: "user handle for another user fails",
+			register:    true,
+			tamper: func(r *webauthn.AssertionResponse) {
+				r.Response.UserHandle = []byte("userB")
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			description:   "replayed assertion fails",
+			register:      true,
+			replay:        true,
+			status:        http.StatusUnauthorized,
+			sessionsCount: 1,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			if err := dataStore.InsertUser(userA); err != nil {
+				t.Fatalf("failed to insert user: %v", err)
+			}
+
+			passkeys := newPasskeyManager(t, dataStore)
+			authenticator := webauthntest.New(passkeyTestOrigin)
+			if tt.register {
+				mustRegisterPasskeyForUser(t, passkeys, authenticator, userA.Username)
+			} else {
+				// Give the authenticator a passkey that ScreenJournal never saw.
+				otherStore := test_sqlite.New()
+				if err := otherStore.InsertUser(userA); err != nil {
+					t.Fatalf("failed to insert user: %v", err)
+				}
+				mustRegisterPasskeyForUser(t, newPasskeyManager(t, otherStore), authenticator, userA.Username)
+			}
+
+			var twoFactor handlers.TwoFactorAuthenticator
+			if tt.twoFactor {
+				now := time.Now()
+				tf := twofactor.New(dataStore, []byte("dummy-key"), func() time.Time { return now })
+				secret, err := tf.BeginEnrollment(userA.Username)
+				if err != nil {
+					t.Fatalf("failed to begin two-factor enrollment: %v", err)
+				}
+				if _, err := tf.ConfirmEnrollment(userA.Username, mustTotpCode(t, secret, now)); err != nil {
+					t.Fatalf("failed to confirm two-factor enrollment: %v", err)
+				}
+				twoFactor = tf
+			}
+
+			sessionManager := newMockSessionManager([]mockSessionEntry{})
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+				TwoFactor:      twoFactor,
+				Passkeys:       passkeys,
+			})
+
+			rec := postJSON(t, s, "/api/auth/passkey/options", nil, "")
+			if got, want := rec.Code, http.StatusOK; got != want {
+				t.Fatalf("options httpStatus=%v, want=%v", got, want)
+			}
+			var options webauthn.RequestOptions
+			if err := json.NewDecoder(rec.Body).Decode(&options); err != nil {
+				t.Fatalf("failed to decode login options: %v", err)
+			}
+
+			assertion, err := authenticator.Login(options)
+			if err != nil {
+				t.Fatalf("authenticator failed to log in: %v", err)
+			}
+			if tt.tamper != nil {
+				tt.tamper(&assertion)
+			}
+
+			if tt.replay {
+				if rec := postJSON(t, s, "/api/auth/passkey", assertion, ""); rec.Code != http.StatusOK {
+					t.Fatalf("first login httpStatus=%v, want=%v", rec.Code, http.StatusOK)
+				}
+			}
+
+			rec = postJSON(t, s, "/api/auth/passkey", assertion, "")
+			if got, want := rec.Code, tt.status; got != want {
+				t.Fatalf("login httpStatus=%v, want=%v (%s)", got, want, rec.Body.String())
+			}
+			if got, want := len(sessionManager.sessions), tt.sessionsCount; got != want {
+				t.Fatalf("count(sessions)=%d, want=%d", got, want)
+			}
+			for _, session := range sessionManager.sessions {
+				if got, want := session.Username, userA.Username; !got.Equal(want) {
+					t.Errorf("username=%v, want=%v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPasskeyLoginUnavailable(t *testing.T) {
+	dataStore := test_sqlite.New()
+	sessionManager := newMockSessionManager([]mockSessionEntry{})
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	if got, want := postJSON(t, s, "/api/auth/passkey/options", nil, "").Code, http.StatusServiceUnavailable; got != want {
+		t.Errorf("options httpStatus=%v, want=%v", got, want)
+	}
+}
+
+func TestPasskeyManagementRoutes(t *testing.T) {
+	dataStore := test_sqlite.New()
+	sessions := []mockSessionEntry{
+		newMockSessionEntry("abc123", screenjournal.Username("userA")),
+		newMockSessionEntry("def456", screenjournal.Username("userB")),
+	}
+	insertMockUsersForSessions(t, dataStore, sessions)
+
+	sessionManager := newMockSessionManager(sessions)
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+		Passkeys:       newPasskeyManager(t, dataStore),
+	})
+
+	send := func(method, route string, form url.Values, sessionToken string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, route, strings.NewReader(form.Encode()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{
+			Name:  mockSessionTokenName,
+			Value: sessionToken,
+		})
+		rec := httptest.NewRecorder()
+		s.Router().ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	authenticator := webauthntest.New(passkeyTestOrigin)
+
+	rec := postJSON(t, s, "/account/security/passkeys/options", nil, "abc123")
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("options httpStatus=%v, want=%v", got, want)
+	}
+	var options webauthn.CreationOptions
+	if err := json.NewDecoder(rec.Body).Decode(&options); err != nil {
+		t.Fatalf("failed to decode creation options: %v", err)
+	}
+	if got, want := options.User.Name, "userA"; got != want {
+		t.Errorf("options user=%v, want=%v", got, want)
+	}
+
+	credential, err := authenticator.Register(options)
+	if err != nil {
+		t.Fatalf("authenticator failed to register: %v", err)
+	}
+
+	// Another user can't complete userA's registration.
+	rec = postJSON(t, s, "/account/security/passkeys", map[string]any{
+		"name":       "Hijacked",
+		"credential": credential,
+	}, "def456")
+	if got, want := rec.Code, http.StatusBadRequest; got != want {
+		t.Errorf("registration by other user httpStatus=%v, want=%v", got, want)
+	}
+
+	// The challenge is single-use, so userA has to start over.
+	rec = postJSON(t, s, "/account/security/passkeys/options", nil, "abc123")
+	if err := json.NewDecoder(rec.Body).Decode(&options); err != nil {
+		t.Fatalf("failed to decode creation options: %v", err)
+	}
+	credential, err = authenticator.Register(options)
+	if err != nil {
+		t.Fatalf("authenticator failed to register: %v", err)
+	}
+	rec = postJSON(t, s, "/account/security/passkeys", map[string]any{
+		"name":       "Laptop",
+		"credential": credential,
+	}, "abc123")
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("registration httpStatus=%v, want=%v (%s)", got, want, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `value="Laptop"`) {
+		t.Errorf("passkey list doesn't show new passkey: %s", rec.Body.String())
+	}
+
+	passkeys, err := dataStore.ReadPasskeys(screenjournal.Username("userA"))
+	if err != nil {
+		t.Fatalf("failed to read passkeys: %v", err)
+	}
+	if got, want := len(passkeys), 1; got != want {
+		t.Fatalf("count(passkeys)=%d, want=%d", got, want)
+	}
+	passkeyRoute := "/account/security/passkeys/" + passkeys[0].ID.String()
+
+	if status, _ := send("PUT", passkeyRoute, url.Values{"name": {"Stolen"}}, "def456"); status != http.StatusNotFound {
+		t.Errorf("rename by other user httpStatus=%v, want=%v", status, http.StatusNotFound)
+	}
+	if status, _ := send("PUT", passkeyRoute, url.Values{"name": {""}}, "abc123"); status != http.StatusBadRequest {
+		t.Errorf("rename to empty name httpStatus=%v, want=%v", status, http.StatusBadRequest)
+	}
+	status, body := send("PUT", passkeyRoute, url.Values{"name": {"Work laptop"}}, "abc123")
+	if got, want := status, http.StatusOK; got != want {
+		t.Fatalf("rename httpStatus=%v, want=%v", got, want)
+	}
+	if !strings.Contains(body, `value="Work laptop"`) {
+		t.Errorf("passkey list doesn't show new name: %s", body)
+	}
+
+	status, body = send("GET", "/account/security", url.Values{}, "abc123")
+	if got, want := status, http.StatusOK; got != want {
+		t.Fatalf("security page httpStatus=%v, want=%v", got, want)
+	}
+	if !strings.Contains(body, `value="Work laptop"`) {
+		t.Errorf("security page doesn't list passkey: %s", body)
+	}
+
+	if status, _ := send("DELETE", passkeyRoute, url.Values{}, "def456"); status != http.StatusNotFound {
+		t.Errorf("delete by other user httpStatus=%v, want=%v", status, http.StatusNotFound)
+	}
+	if status, _ := send("DELETE", passkeyRoute, url.Values{}, "abc123"); status != http.StatusOK {
+		t.Fatalf("delete httpStatus=%v, want=%v", status, http.StatusOK)
+	}
+	if _, err := dataStore.ReadPasskey(passkeys[0].ID); err != store.ErrPasskeyNotFound {
+		t.Errorf("ReadPasskey after delete err=%v, want=%v", err, store.ErrPasskeyNotFound)
+	}
+}
@@ -60,6 +60,26 @@ func (s Server) accountPasswordResetPut() http.HandlerFunc {
 			return
 		}
 
+		// A password reset proves control of the user's email, not their second
+		// factor, so users with two-factor authentication must log in normally.
+		twoFactorEnabled, err := s.isTwoFactorEnabled(username)
+		if err != nil {
+			log.Printf("failed to read two-factor configuration after password reset: %v", err)
+			http.Error(w, "Password updated but failed to log in", http.StatusInternalServerError)
+			return
+		}
+		if twoFactorEnabled {
+			w.WriteHeader(http.StatusOK)
+			if _, err := fmt.Fprint(w, "Password updated successfully. Please log in with your new password."); err != nil {
+				log.Printf("failed to write response: %v", err)
+			}
+			return
+		}
+
+		if !s.checkAccountEnabled(w, username) {
+			return
+		}
+
 		// Create a session to automatically log in the user.
 		userID, err := userIDFromUsername(username)
 		if err != nil {
@@ -22,6 +22,14 @@ func (noopPasswordResetter) Reset(screenjournal.Username, screenjournal.Password
 	return nil
 }
 
+func (noopPasswordResetter) SendLoginLink(screenjournal.Email) error {
+	return nil
+}
+
+func (noopPasswordResetter) UseLoginLink(screenjournal.Username, screenjournal.PasswordResetToken) error {
+	return nil
+}
+
 func TestResetPasswordGet(t *testing.T) {
 	for _, tt := range []struct {
 		description        string
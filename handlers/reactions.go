@@ -15,8 +15,10 @@ import (
 )
 
 type reactionPostRequest struct {
-	ReviewID screenjournal.ReviewID
-	Emoji    screenjournal.ReactionEmoji
+	TargetType screenjournal.ReactionTargetType
+	ReviewID   screenjournal.ReviewID
+	CommentID  screenjournal.CommentID
+	Emoji      screenjournal.ReactionEmoji
 }
 
 type reactionForTemplate struct {
@@ -43,6 +45,15 @@ func convertReactionsForTemplate(reactions []screenjournal.ReviewReaction, logge
 	return reactionsForTemplate
 }
 
+func userHasReacted(reactions []screenjournal.ReviewReaction, username screenjournal.Username) bool {
+	for _, reaction := range reactions {
+		if reaction.Owner.Equal(username) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s Server) reactionsPost() http.HandlerFunc {
 	t := template.Must(template.New("reviews-for-single-media-entry.html").
 		Funcs(moviePageFns).
@@ -54,6 +65,20 @@ func (s Server) reactionsPost() http.HandlerFunc {
 			return
 		}
 
+		var comment screenjournal.ReviewComment
+		if req.TargetType == screenjournal.ReactionTargetComment {
+			comment, err = s.store.ReadComment(req.CommentID)
+			if err == store.ErrCommentNotFound {
+				http.Error(w, "Comment not found", http.StatusNotFound)
+				return
+			} else if err != nil {
+				log.Printf("Failed to read comment: %v", err)
+				http.Error(w, fmt.Sprintf("Failed to read comment: %v", err), http.StatusInternalServerError)
+				return
+			}
+			req.ReviewID = comment.Review.ID
+		}
+
 		review, err := s.store.ReadReview(req.ReviewID)
 		if err == store.ErrReviewNotFound {
 			http.Error(w, "Review not found", http.StatusNotFound)
@@ -65,9 +90,10 @@ func (s Server) reactionsPost() http.HandlerFunc {
 		}
 
 		rr := screenjournal.ReviewReaction{
-			Review: review,
-			Owner:  mustGetUsernameFromContext(r.Context()),
-			Emoji:  req.Emoji,
+			Review:  review,
+			Comment: comment,
+			Owner:   mustGetUsernameFromContext(r.Context()),
+			Emoji:   req.Emoji,
 		}
 
 		rr.ID, err = s.store.InsertReaction(rr)
@@ -77,8 +103,15 @@ func (s Server) reactionsPost() http.HandlerFunc {
 			return
 		}
 
-		// Reload all reactions for this review to render the updated section.
-		reactions, err := s.store.ReadReactions(req.ReviewID)
+		// Reload all reactions for the target to render the updated section.
+		var reactions []screenjournal.ReviewReaction
+		targetID := req.ReviewID.UInt64()
+		if req.TargetType == screenjournal.ReactionTargetComment {
+			reactions, err = s.store.ReadCommentReactions(req.CommentID)
+			targetID = req.CommentID.UInt64()
+		} else {
+			reactions, err = s.store.ReadReactions(req.ReviewID)
+		}
 		if err != nil {
 			log.Printf("failed to read reactions: %v", err)
 			http.Error(w, "Failed to read reactions", http.StatusInternalServerError)
@@ -91,19 +124,25 @@ func (s Server) reactionsPost() http.HandlerFunc {
 		// Convert reactions to template format.
 		reactionsForTemplate := convertReactionsForTemplate(reactions, loggedInUsername, isAdminUser)
 
-		renderTemplate(w, t, "reactions-section", struct {
-			ReviewID         screenjournal.ReviewID
+		if !renderTemplate(w, t, "reactions-section", struct {
+			TargetType       screenjournal.ReactionTargetType
+			TargetID         uint64
 			Reactions        []reactionForTemplate
 			UserHasReacted   bool
 			AvailableEmojis  []screenjournal.ReactionEmoji
 			LoggedInUsername screenjournal.Username
 		}{
-			ReviewID:         req.ReviewID,
+			TargetType:       req.TargetType,
+			TargetID:         targetID,
 			Reactions:        reactionsForTemplate,
 			UserHasReacted:   true,
 			AvailableEmojis:  screenjournal.AllowedReactionEmojis(),
 			LoggedInUsername: loggedInUsername,
-		})
+		}) {
+			return
+		}
+
+		s.announcer.AnnounceNewReaction(rr)
 	}
 }
 
@@ -147,7 +186,24 @@ func parseReactionPostRequest(r *http.Request) (reactionPostRequest, error) {
 		return reactionPostRequest{}, err
 	}
 
-	rid, err := parse.ReviewIDFromString(r.PostFormValue("review-id"))
+	// Reactions target reviews unless the client specifies otherwise.
+	targetType := screenjournal.ReactionTargetReview
+	if raw := r.PostFormValue("target-type"); raw != "" {
+		var err error
+		targetType, err = parse.ReactionTargetType(raw)
+		if err != nil {
+			return reactionPostRequest{}, err
+		}
+	}
+
+	var rid screenjournal.ReviewID
+	var cid screenjournal.CommentID
+	var err error
+	if targetType == screenjournal.ReactionTargetComment {
+		cid, err = parse.CommentID(r.PostFormValue("comment-id"))
+	} else {
+		rid, err = parse.ReviewIDFromString(r.PostFormValue("review-id"))
+	}
 	if err != nil {
 		return reactionPostRequest{}, err
 	}
@@ -158,8 +214,10 @@ func parseReactionPostRequest(r *http.Request) (reactionPostRequest, error) {
 	}
 
 	return reactionPostRequest{
-		ReviewID: rid,
-		Emoji:    emoji,
+		TargetType: targetType,
+		ReviewID:   rid,
+		CommentID:  cid,
+		Emoji:      emoji,
 	}, nil
 }
 
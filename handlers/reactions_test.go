@@ -188,10 +188,12 @@ func TestReactionsPost(t *testing.T) {
 				}
 			}
 
+			announcer := mockAnnouncer{}
 			authenticator := auth.New(dataStore)
 			sessionManager := newMockSessionManager(tt.sessions)
 			s := handlers.New(handlers.ServerParams{
 				Authenticator:  authenticator,
+				Announcer:      &announcer,
 				SessionManager: &sessionManager,
 				Store:          dataStore,
 			})
@@ -225,6 +227,139 @@ func TestReactionsPost(t *testing.T) {
 			if got, want := reactions, tt.expectedReactions; !reviewReactionsEqual(got, want) {
 				t.Errorf("reactions=%+v, want=%+v", got, want)
 			}
+
+			if got, want := len(announcer.announcedReactions), 1; got != want {
+				t.Fatalf("reactionsAnnounced=%d, want=%d", got, want)
+			}
+			if got, want := announcer.announcedReactions[0].Review.Owner, makeReactionsTestData().reviews.userBTheWaterBoy.Owner; got != want {
+				t.Errorf("announced reaction review owner=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestReactionsPostOnComment(t *testing.T) {
+	for _, tt := range []struct {
+		description              string
+		payload                  string
+		sessionToken             string
+		status                   int
+		expectedCommentReactions []screenjournal.ReviewReaction
+	}{
+		{
+			description:  "allows user to react to a comment",
+			payload:      "target-type=comment&comment-id=1&emoji=👍",
+			sessionToken: makeReactionsTestData().sessions.userB.token,
+			status:       http.StatusOK,
+			expectedCommentReactions: []screenjournal.ReviewReaction{
+				{
+					ID:    screenjournal.ReactionID(1),
+					Owner: makeReactionsTestData().sessions.userB.session.Username,
+					Emoji: screenjournal.NewReactionEmoji("👍"),
+					Review: screenjournal.Review{
+						ID: makeReactionsTestData().reviews.userBTheWaterBoy.ID,
+					},
+					Comment: screenjournal.ReviewComment{
+						ID: screenjournal.CommentID(1),
+						Review: screenjournal.Review{
+							ID: makeReactionsTestData().reviews.userBTheWaterBoy.ID,
+						},
+					},
+				},
+			},
+		},
+		{
+			description:  "returns 404 for a reaction to a non-existent comment",
+			payload:      "target-type=comment&comment-id=999&emoji=👍",
+			sessionToken: makeReactionsTestData().sessions.userB.token,
+			status:       http.StatusNotFound,
+		},
+		{
+			description:  "rejects a comment reaction without a comment ID",
+			payload:      "target-type=comment&review-id=1&emoji=👍",
+			sessionToken: makeReactionsTestData().sessions.userB.token,
+			status:       http.StatusBadRequest,
+		},
+		{
+			description:  "rejects an unknown target type",
+			payload:      "target-type=user&review-id=1&emoji=👍",
+			sessionToken: makeReactionsTestData().sessions.userB.token,
+			status:       http.StatusBadRequest,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			td := makeReactionsTestData()
+			sessions := []mockSessionEntry{td.sessions.userA, td.sessions.userB}
+			dataStore := test_sqlite.New()
+			insertMockUsersForSessions(t, dataStore, sessions)
+			if _, err := dataStore.InsertMovie(td.movies.theWaterBoy); err != nil {
+				t.Fatalf("failed to insert mock movie: %v", err)
+			}
+			if _, err := dataStore.InsertReview(td.reviews.userBTheWaterBoy); err != nil {
+				t.Fatalf("failed to insert mock review: %v", err)
+			}
+			if _, err := dataStore.InsertComment(screenjournal.ReviewComment{
+				Owner:       td.sessions.userA.session.Username,
+				CommentText: screenjournal.CommentText("Good insights!"),
+				Review:      td.reviews.userBTheWaterBoy,
+			}); err != nil {
+				t.Fatalf("failed to insert mock comment: %v", err)
+			}
+
+			announcer := mockAnnouncer{}
+			sessionManager := newMockSessionManager(sessions)
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				Announcer:      &announcer,
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req, err := http.NewRequest("POST", "/reactions", strings.NewReader(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: tt.sessionToken,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+			res := rec.Result()
+
+			if got, want := res.StatusCode, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			commentReactions, err := dataStore.ReadCommentReactions(screenjournal.CommentID(1))
+			if err != nil {
+				t.Fatalf("failed to read comment reactions from datastore: %v", err)
+			}
+			if got, want := commentReactions, tt.expectedCommentReactions; !reviewReactionsEqual(got, want) {
+				t.Errorf("commentReactions=%+v, want=%+v", got, want)
+			}
+
+			if got, want := len(announcer.announcedReactions), 1; got != want {
+				t.Fatalf("reactionsAnnounced=%d, want=%d", got, want)
+			}
+			if got, want := announcer.announcedReactions[0].TargetType(), screenjournal.ReactionTargetComment; got != want {
+				t.Errorf("announced reaction target=%v, want=%v", got, want)
+			}
+
+			// Reactions to comments shouldn't show up as reactions to the review.
+			reviewReactions, err := dataStore.ReadReactions(td.reviews.userBTheWaterBoy.ID)
+			if err != nil {
+				t.Fatalf("failed to read reactions from datastore: %v", err)
+			}
+			if got, want := len(reviewReactions), 0; got != want {
+				t.Errorf("reviewReactions=%d, want=%d", got, want)
+			}
 		})
 	}
 }
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/mtlynch/screenjournal/v2/recap"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+func (s Server) recapGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			Funcs(template.FuncMap{
+				"ratingToStars":    ratingToStars,
+				"reviewMediaTitle": reviewMediaTitle,
+				"reviewTargetURL": func(review screenjournal.Review) string {
+					return reviewTargetURL(review, review.ID)
+				},
+			}).
+			ParseFS(
+				templatesFS,
+				append(baseTemplates, "templates/pages/recap.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		year, err := recapYearFromRequestPath(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid year: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		username, err := usernameFromRequestPath(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid username: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if _, err := s.store.ReadUser(username); err != nil {
+			if errors.Is(err, store.ErrUserNotFound) {
+				http.Error(w, "User not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("failed to read user %s: %v", username, err)
+			http.Error(w, "Failed to read user", http.StatusInternalServerError)
+			return
+		}
+
+		rc, ok := s.buildRecap(w, username, year)
+		if !ok {
+			return
+		}
+
+		loggedInUsername := mustGetUsernameFromContext(r.Context())
+		renderTemplate(w, t, "base.html", struct {
+			commonProps
+			Recap      screenjournal.Recap
+			CanEmail   bool
+			EmailRoute string
+		}{
+			commonProps: makeCommonProps(r.Context()),
+			Recap:       rc,
+			CanEmail:    s.recapSender != nil && loggedInUsername.Equal(username),
+			EmailRoute:  fmt.Sprintf("/recap/%d/%s/email", year, username),
+		})
+	}
+}
+
+func (s Server) recapEmailPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.recapSender == nil {
+			http.Error(w, "Email is not enabled on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		year, err := recapYearFromRequestPath(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid year: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		username, err := usernameFromRequestPath(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid username: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if !mustGetUsernameFromContext(r.Context()).Equal(username) && !isAdmin(r.Context()) {
+			http.Error(w, "You can't email another user's recap", http.StatusForbidden)
+			return
+		}
+
+		user, err := s.store.ReadUser(username)
+		if err != nil {
+			if errors.Is(err, store.ErrUserNotFound) {
+				http.Error(w, "User not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("failed to read user %s: %v", username, err)
+			http.Error(w, "Failed to read user", http.StatusInternalServerError)
+			return
+		}
+		if !user.EmailVerified {
+			http.Error(w, "Verify your email address before sending your recap", http.StatusConflict)
+			return
+		}
+
+		rc, ok := s.buildRecap(w, username, year)
+		if !ok {
+			return
+		}
+
+		if err := s.recapSender.SendRecap(user, rc); err != nil {
+			log.Printf("failed to send %d recap to %s: %v", year, username, err)
+			http.Error(w, "Failed to send recap email", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprint(w, "Recap sent"); err != nil {
+			log.Printf("failed to write response: %v", err)
+		}
+	}
+}
+
+// buildRecap calculates the recap for the given user and year. If it fails,
+// it writes an error to w and returns false.
+func (s Server) buildRecap(w http.ResponseWriter, username screenjournal.Username, year int) (screenjournal.Recap, bool) {
+	reviews, err := s.store.ReadReviews()
+	if err != nil {
+		log.Printf("failed to read reviews: %v", err)
+		http.Error(w, "Failed to load recap", http.StatusInternalServerError)
+		return screenjournal.Recap{}, false
+	}
+
+	for i, review := range reviews {
+		rr, err := s.store.ReadReactions(review.ID)
+		if err != nil {
+			log.Printf("failed to read reactions for review %s: %v", review.ID, err)
+			http.Error(w, "Failed to load recap", http.StatusInternalServerError)
+			return screenjournal.Recap{}, false
+		}
+		reviews[i].Reactions = rr
+	}
+
+	return recap.Build(username, year, reviews), true
+}
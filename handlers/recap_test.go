@@ -0,0 +1,191 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+type mockRecapSender struct {
+	sent []screenjournal.Recap
+}
+
+func (rs *mockRecapSender) SendRecap(_ screenjournal.User, r screenjournal.Recap) error {
+	rs.sent = append(rs.sent, r)
+	return nil
+}
+
+func TestRecapGet(t *testing.T) {
+	for _, tt := range []struct {
+		description  string
+		route        string
+		sessionToken string
+		sessions     []mockSessionEntry
+		status       int
+	}{
+		{
+			description:  "shows a user's recap",
+			route:        "/recap/2024/userB",
+			sessionToken: makeReactionsTestData().sessions.userA.token,
+			sessions: []mockSessionEntry{
+				makeReactionsTestData().sessions.userA,
+				makeReactionsTestData().sessions.userB,
+			},
+			status: http.StatusOK,
+		},
+		{
+			description:  "rejects a year before ScreenJournal existed",
+			route:        "/recap/1999/userB",
+			sessionToken: makeReactionsTestData().sessions.userA.token,
+			sessions: []mockSessionEntry{
+				makeReactionsTestData().sessions.userA,
+				makeReactionsTestData().sessions.userB,
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			description:  "returns 404 for a non-existent user",
+			route:        "/recap/2024/nobody",
+			sessionToken: makeReactionsTestData().sessions.userA.token,
+			sessions: []mockSessionEntry{
+				makeReactionsTestData().sessions.userA,
+				makeReactionsTestData().sessions.userB,
+			},
+			status: http.StatusNotFound,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			insertMockUsersForSessions(t, dataStore, tt.sessions)
+			if _, err := dataStore.InsertMovie(makeReactionsTestData().movies.theWaterBoy); err != nil {
+				t.Fatalf("failed to insert mock movie: %v", err)
+			}
+			if _, err := dataStore.InsertReview(makeReactionsTestData().reviews.userBTheWaterBoy); err != nil {
+				t.Fatalf("failed to insert mock review: %v", err)
+			}
+
+			sessionManager := newMockSessionManager(tt.sessions)
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req, err := http.NewRequest("GET", tt.route, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: tt.sessionToken,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+
+			if got, want := rec.Result().StatusCode, tt.status; got != want {
+				t.Errorf("httpStatus=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestRecapEmailPost(t *testing.T) {
+	for _, tt := range []struct {
+		description  string
+		route        string
+		sessionToken string
+		sessions     []mockSessionEntry
+		emailEnabled bool
+		status       int
+		recapsSent   int
+	}{
+		{
+			description:  "emails a user their own recap",
+			route:        "/recap/2020/userB/email",
+			sessionToken: makeReactionsTestData().sessions.userB.token,
+			sessions: []mockSessionEntry{
+				makeReactionsTestData().sessions.userA,
+				makeReactionsTestData().sessions.userB,
+			},
+			emailEnabled: true,
+			status:       http.StatusOK,
+			recapsSent:   1,
+		},
+		{
+			description:  "refuses to email another user's recap",
+			route:        "/recap/2020/userB/email",
+			sessionToken: makeReactionsTestData().sessions.userA.token,
+			sessions: []mockSessionEntry{
+				makeReactionsTestData().sessions.userA,
+				makeReactionsTestData().sessions.userB,
+			},
+			emailEnabled: true,
+			status:       http.StatusForbidden,
+		},
+		{
+			description:  "returns 503 when email is disabled",
+			route:        "/recap/2020/userB/email",
+			sessionToken: makeReactionsTestData().sessions.userB.token,
+			sessions: []mockSessionEntry{
+				makeReactionsTestData().sessions.userB,
+			},
+			emailEnabled: false,
+			status:       http.StatusServiceUnavailable,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			insertMockUsersForSessions(t, dataStore, tt.sessions)
+			if _, err := dataStore.InsertMovie(makeReactionsTestData().movies.theWaterBoy); err != nil {
+				t.Fatalf("failed to insert mock movie: %v", err)
+			}
+			if _, err := dataStore.InsertReview(makeReactionsTestData().reviews.userBTheWaterBoy); err != nil {
+				t.Fatalf("failed to insert mock review: %v", err)
+			}
+
+			recapSender := mockRecapSender{}
+			params := handlers.ServerParams{
+				Authenticator: auth.New(dataStore),
+				Store:         dataStore,
+			}
+			if tt.emailEnabled {
+				params.RecapSender = &recapSender
+			}
+			sessionManager := newMockSessionManager(tt.sessions)
+			params.SessionManager = &sessionManager
+			s := handlers.New(params)
+
+			req, err := http.NewRequest("POST", tt.route, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: tt.sessionToken,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+
+			if got, want := rec.Result().StatusCode, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			if got, want := len(recapSender.sent), tt.recapsSent; got != want {
+				t.Fatalf("recapsSent=%d, want=%d", got, want)
+			}
+			if tt.recapsSent == 0 {
+				return
+			}
+			if got, want := len(recapSender.sent[0].TitlesWatched), 1; got != want {
+				t.Errorf("titlesWatched=%d, want=%d", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mtlynch/screenjournal/v2/recommend"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+const maxRecommendations = 20
+
+func (s Server) recommendationsGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			Funcs(template.FuncMap{
+				"reviewMediaTitle": reviewMediaTitle,
+				"reviewPageURL":    reviewPageURL,
+				"reviewTargetURL": func(review screenjournal.Review) string {
+					return reviewTargetURL(review, review.ID)
+				},
+				"posterPathToURL": posterPathToURL,
+				"recommendationPoster": func(r screenjournal.Recommendation) url.URL {
+					if r.Title.MediaType() == screenjournal.MediaTypeMovie {
+						return r.Title.Movie.PosterPath
+					}
+					return r.Title.TvShow.PosterPath
+				},
+				"joinUsernames": joinUsernames,
+			}).
+			ParseFS(
+				templatesFS,
+				append(baseTemplates, "templates/pages/recommendations.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		reviews, err := s.store.ReadReviews()
+		if err != nil {
+			log.Printf("failed to read reviews: %v", err)
+			http.Error(w, "Failed to load recommendations", http.StatusInternalServerError)
+			return
+		}
+
+		renderTemplate(w, t, "base.html", struct {
+			commonProps
+			Recommendations []screenjournal.Recommendation
+		}{
+			commonProps:     makeCommonProps(r.Context()),
+			Recommendations: recommend.ForUser(mustGetUsernameFromContext(r.Context()), reviews, maxRecommendations),
+		})
+	}
+}
+
+// joinUsernames formats a list of usernames as English prose (e.g., "bob,
+// carol, and dave").
+func joinUsernames(usernames []screenjournal.Username) string {
+	names := make([]string, len(usernames))
+	for i, u := range usernames {
+		names[i] = u.String()
+	}
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	case 2:
+		return fmt.Sprintf("%s and %s", names[0], names[1])
+	}
+	return fmt.Sprintf("%s, and %s", strings.Join(names[:len(names)-1], ", "), names[len(names)-1])
+}
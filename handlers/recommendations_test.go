@@ -0,0 +1,96 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestRecommendationsGet(t *testing.T) {
+	userA := newMockSessionEntry("abc123", screenjournal.Username("userA"))
+	userB := newMockSessionEntry("def456", screenjournal.Username("userB"))
+	userC := newMockSessionEntry("ghi789", screenjournal.Username("userC"))
+	sessions := []mockSessionEntry{userA, userB, userC}
+
+	movies := []screenjournal.Movie{
+		{ID: screenjournal.MovieID(1), TmdbID: screenjournal.TmdbID(100), Title: screenjournal.MediaTitle("The Waterboy"), ImdbID: screenjournal.ImdbID("tt0120484")},
+		{ID: screenjournal.MovieID(2), TmdbID: screenjournal.TmdbID(200), Title: screenjournal.MediaTitle("Billy Madison"), ImdbID: screenjournal.ImdbID("tt0112508")},
+		{ID: screenjournal.MovieID(3), TmdbID: screenjournal.TmdbID(300), Title: screenjournal.MediaTitle("Happy Gilmore"), ImdbID: screenjournal.ImdbID("tt0116483")},
+	}
+	ratings := []struct {
+		owner   mockSessionEntry
+		movieID screenjournal.MovieID
+		rating  uint8
+	}{
+		{userA, 1, 9},
+		{userA, 2, 2},
+		{userB, 1, 9},
+		{userB, 2, 3},
+		{userB, 3, 10},
+		{userC, 1, 8},
+		{userC, 2, 2},
+		{userC, 3, 9},
+	}
+
+	dataStore := test_sqlite.New()
+	insertMockUsersForSessions(t, dataStore, sessions)
+	for _, movie := range movies {
+		if _, err := dataStore.InsertMovie(movie); err != nil {
+			t.Fatalf("failed to insert mock movie: %v", err)
+		}
+	}
+	for _, r := range ratings {
+		if _, err := dataStore.InsertReview(screenjournal.Review{
+			Owner:   r.owner.session.Username,
+			Rating:  screenjournal.NewRating(r.rating),
+			Movie:   screenjournal.Movie{ID: r.movieID},
+			Watched: mustParseWatchDate("2024-01-01"),
+		}); err != nil {
+			t.Fatalf("failed to insert mock review: %v", err)
+		}
+	}
+
+	sessionManager := newMockSessionManager(sessions)
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	req, err := http.NewRequest("GET", "/recommendations", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(&http.Cookie{
+		Name:  mockSessionTokenName,
+		Value: userA.token,
+	})
+
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+	res := rec.Result()
+
+	if got, want := res.StatusCode, http.StatusOK; got != want {
+		t.Fatalf("httpStatus=%v, want=%v", got, want)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	for _, want := range []string{
+		"Happy Gilmore",
+		"userB and userC, who agree with you 94%.",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("response body doesn't contain %q", want)
+		}
+	}
+}
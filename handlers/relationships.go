@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+type userRelationshipProps struct {
+	Username     screenjournal.Username
+	Relationship screenjournal.UserRelationship
+}
+
+func (s Server) userRelationshipPut() http.HandlerFunc {
+	t := template.Must(template.ParseFS(templatesFS, "templates/fragments/user-relationship.html"))
+	return func(w http.ResponseWriter, r *http.Request) {
+		target, err := usernameFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+
+		relationship, err := parse.UserRelationship(r.PostFormValue("relationship"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid relationship: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		if target.Equal(username) {
+			http.Error(w, "You can't follow or mute yourself", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := s.store.ReadUser(target); err != nil {
+			if errors.Is(err, store.ErrUserNotFound) {
+				http.Error(w, "User not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("failed to read user %s: %v", target, err)
+			http.Error(w, "Failed to read user", http.StatusInternalServerError)
+			return
+		}
+
+		if err := s.store.UpdateUserRelationship(username, target, relationship); err != nil {
+			log.Printf("failed to update relationship of %s to %s: %v", username, target, err)
+			http.Error(w, "Failed to save relationship", http.StatusInternalServerError)
+			return
+		}
+
+		renderTemplate(w, t, "user-relationship.html", userRelationshipProps{
+			Username:     target,
+			Relationship: relationship,
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestUserRelationshipPut(t *testing.T) {
+	for _, tt := range []struct {
+		description          string
+		route                string
+		payload              string
+		initialRelationship  screenjournal.UserRelationship
+		status               int
+		expectedRelationship screenjournal.UserRelationship
+	}{
+		{
+			description:          "follows another user",
+			route:                "/users/userB/relationship",
+			payload:              "relationship=follow",
+			status:               http.StatusOK,
+			expectedRelationship: screenjournal.RelationshipFollow,
+		},
+		{
+			description:          "mutes a followed user",
+			route:                "/users/userB/relationship",
+			payload:              "relationship=mute",
+			initialRelationship:  screenjournal.RelationshipFollow,
+			status:               http.StatusOK,
+			expectedRelationship: screenjournal.RelationshipMute,
+		},
+		{
+			description:          "unmutes a muted user",
+			route:                "/users/userB/relationship",
+			payload:              "relationship=none",
+			initialRelationship:  screenjournal.RelationshipMute,
+			status:               http.StatusOK,
+			expectedRelationship: screenjournal.RelationshipNone,
+		},
+		{
+			description:          "rejects unknown relationship",
+			route:                "/users/userB/relationship",
+			payload:              "relationship=block",
+			status:               http.StatusBadRequest,
+			expectedRelationship: screenjournal.RelationshipNone,
+		},
+		{
+			description: "rejects following yourself",
+			route:       "/users/userA/relationship",
+			payload:     "relationship=follow",
+			status:      http.StatusBadRequest,
+		},
+		{
+			description: "rejects following a user who doesn't exist",
+			route:       "/users/nobody/relationship",
+			payload:     "relationship=follow",
+			status:      http.StatusNotFound,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			sessions := []mockSessionEntry{
+				newMockSessionEntry("abc123", screenjournal.Username("userA")),
+				newMockSessionEntry("def456", screenjournal.Username("userB")),
+			}
+			insertMockUsersForSessions(t, dataStore, sessions)
+			if tt.initialRelationship != screenjournal.RelationshipNone {
+				if err := dataStore.UpdateUserRelationship(screenjournal.Username("userA"), screenjournal.Username("userB"), tt.initialRelationship); err != nil {
+					t.Fatalf("failed to set initial relationship: %v", err)
+				}
+			}
+
+			sessionManager := newMockSessionManager(sessions)
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req, err := http.NewRequest("PUT", tt.route, strings.NewReader(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: "abc123",
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+			res := rec.Result()
+
+			if got, want := res.StatusCode, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			relationship, err := dataStore.ReadUserRelationship(screenjournal.Username("userA"), screenjournal.Username("userB"))
+			if err != nil {
+				t.Fatalf("failed to read relationship: %v", err)
+			}
+			if got, want := relationship, tt.expectedRelationship; got != want {
+				t.Errorf("relationship=%q, want=%q", got, want)
+			}
+		})
+	}
+}
+
+func TestActivityGetRejectsInvalidFilter(t *testing.T) {
+	dataStore := test_sqlite.New()
+	sessions := []mockSessionEntry{
+		newMockSessionEntry("abc123", screenjournal.Username("userA")),
+	}
+	insertMockUsersForSessions(t, dataStore, sessions)
+
+	sessionManager := newMockSessionManager(sessions)
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	for _, tt := range []struct {
+		route  string
+		status int
+	}{
+		{"/activity", http.StatusOK},
+		{"/activity?filter=following", http.StatusOK},
+		{"/activity?filter=banana", http.StatusBadRequest},
+	} {
+		req, err := http.NewRequest("GET", tt.route, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.AddCookie(&http.Cookie{
+			Name:  mockSessionTokenName,
+			Value: "abc123",
+		})
+
+		rec := httptest.NewRecorder()
+		s.Router().ServeHTTP(rec, req)
+
+		if got, want := rec.Result().StatusCode, tt.status; got != want {
+			t.Errorf("%s: httpStatus=%v, want=%v", tt.route, got, want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/markdown"
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
 	"github.com/mtlynch/screenjournal/v2/store"
 	"github.com/mtlynch/screenjournal/v2/store/sqlite"
@@ -74,6 +75,7 @@ func (s Server) reviewsPost() http.HandlerFunc {
 		}
 
 		s.announcer.AnnounceNewReview(review)
+		s.announceMentions(review.Owner, markdown.BlurbMentions(review.Blurb), nil, review, screenjournal.ReviewComment{})
 
 		if review.MediaType() == screenjournal.MediaTypeMovie {
 			http.Redirect(w, r, fmt.Sprintf("/movies/%d#review%d", review.Movie.ID.Int64(), review.ID.UInt64()), http.StatusSeeOther)
@@ -113,6 +115,7 @@ func (s Server) reviewsPut() http.HandlerFunc {
 			return
 		}
 
+		previousMentions := markdown.BlurbMentions(review.Blurb)
 		review.Rating = parsedRequest.Rating
 		review.Blurb = parsedRequest.Blurb
 		review.Watched = parsedRequest.Watched
@@ -123,6 +126,8 @@ func (s Server) reviewsPut() http.HandlerFunc {
 			return
 		}
 
+		s.announceMentions(review.Owner, markdown.BlurbMentions(review.Blurb), previousMentions, review, screenjournal.ReviewComment{})
+
 		var newRoute string
 		if review.MediaType() == screenjournal.MediaTypeMovie {
 			newRoute = fmt.Sprintf("/movies/%d", review.Movie.ID.Int64())
@@ -33,8 +33,11 @@ var contextKeySession = &contextKey{"session"}
 var nilAuthenticator auth.Authenticator
 
 type mockAnnouncer struct {
-	announcedReviews  []screenjournal.Review
-	announcedComments []screenjournal.ReviewComment
+	announcedReviews               []screenjournal.Review
+	announcedComments              []screenjournal.ReviewComment
+	announcedFriendRecommendations []screenjournal.FriendRecommendation
+	announcedReactions             []screenjournal.ReviewReaction
+	announcedMentions              []screenjournal.Mention
 }
 
 func (a *mockAnnouncer) AnnounceNewReview(r screenjournal.Review) {
@@ -45,6 +48,18 @@ func (a *mockAnnouncer) AnnounceNewComment(rc screenjournal.ReviewComment) {
 	a.announcedComments = append(a.announcedComments, rc)
 }
 
+func (a *mockAnnouncer) AnnounceNewFriendRecommendation(fr screenjournal.FriendRecommendation) {
+	a.announcedFriendRecommendations = append(a.announcedFriendRecommendations, fr)
+}
+
+func (a *mockAnnouncer) AnnounceNewReaction(rr screenjournal.ReviewReaction) {
+	a.announcedReactions = append(a.announcedReactions, rr)
+}
+
+func (a *mockAnnouncer) AnnounceMention(m screenjournal.Mention) {
+	a.announcedMentions = append(a.announcedMentions, m)
+}
+
 type (
 	mockSessionEntry struct {
 		token   string
@@ -97,6 +112,14 @@ func (sm mockSessionManager) UserIDFromContext(ctx context.Context) (simple_sess
 	return simple_sessions.NewUserID(session.Username.String())
 }
 
+func (sm mockSessionManager) SessionIDFromContext(ctx context.Context) (screenjournal.SessionID, error) {
+	token, ok := ctx.Value(contextKeySession).(string)
+	if !ok {
+		return screenjournal.SessionID(""), simple_sessions.ErrNoSessionFound
+	}
+	return screenjournal.SessionID(token), nil
+}
+
 func (sm mockSessionManager) SessionFromToken(token string) (mockSession, error) {
 	session, ok := sm.sessions[token]
 	if !ok {
@@ -166,9 +189,10 @@ func newMockSessionEntry(token string, username screenjournal.Username) mockSess
 
 func newMockUser(username screenjournal.Username) screenjournal.User {
 	return screenjournal.User{
-		Username:     username,
-		Email:        screenjournal.Email(username.String() + "@example.com"),
-		PasswordHash: screenjournal.PasswordHash("dummy-password-hash"),
+		Username:      username,
+		Email:         screenjournal.Email(username.String() + "@example.com"),
+		EmailVerified: true,
+		PasswordHash:  screenjournal.PasswordHash("dummy-password-hash"),
 	}
 }
 
@@ -5,6 +5,9 @@ import "net/http"
 func (s *Server) routes() {
 	s.router.HandleFunc("/api/auth", s.authPost()).Methods(http.MethodPost)
 	s.router.HandleFunc("/api/auth", s.authDelete()).Methods(http.MethodDelete)
+	s.router.HandleFunc("/api/auth/two-factor", s.authTwoFactorPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/auth/passkey/options", s.authPasskeyOptionsPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/auth/passkey", s.authPasskeyPost()).Methods(http.MethodPost)
 	s.router.HandleFunc("/api/users/{username}", s.usersPut()).Methods(http.MethodPut)
 	s.router.Use(s.populateAuthenticationContext)
 
@@ -35,17 +38,32 @@ func (s *Server) routes() {
 	adminViews.Use(s.requireAdmin)
 	adminViews.Use(enforceContentSecurityPolicy)
 	adminViews.HandleFunc("/invites", s.invitesGet()).Methods(http.MethodGet)
+	adminViews.HandleFunc("/webhooks", s.webhooksGet()).Methods(http.MethodGet)
+	adminViews.HandleFunc("/webhooks/{webhookID}", s.webhookDeliveriesGet()).Methods(http.MethodGet)
+	adminViews.HandleFunc("/email-outbox", s.emailOutboxGet()).Methods(http.MethodGet)
+	adminViews.HandleFunc("/login-lockouts", s.loginLockoutsGet()).Methods(http.MethodGet)
+	adminViews.HandleFunc("/users", s.adminUsersGet()).Methods(http.MethodGet)
+	adminViews.HandleFunc("/users/{username}/delete", s.adminUsersDeleteGet()).Methods(http.MethodGet)
 
 	views := s.router.PathPrefix("/").Subrouter()
 	views.Use(upgradeToHttps)
 	views.Use(enforceContentSecurityPolicy)
 	views.HandleFunc("/about", s.aboutGet()).Methods(http.MethodGet)
 	views.HandleFunc("/login", s.logInGet()).Methods(http.MethodGet)
+	views.HandleFunc("/login/email-link", s.logInEmailLinkGet()).Methods(http.MethodGet)
+	views.HandleFunc("/login/email-link", s.logInEmailLinkPost()).Methods(http.MethodPost)
+	views.HandleFunc("/login/email-link/confirm", s.logInEmailLinkConfirmPost()).Methods(http.MethodPost)
+	views.HandleFunc("/auth/oidc/login", s.authSingleSignOnGet()).Methods(http.MethodGet)
+	views.HandleFunc("/auth/oidc/callback", s.authSingleSignOnCallbackGet()).Methods(http.MethodGet)
 	views.HandleFunc("/reset-password", s.resetPasswordGet()).Methods(http.MethodGet)
 	views.HandleFunc("/reset-password", s.resetPasswordPost()).Methods(http.MethodPost)
 	views.HandleFunc("/sign-up", s.signUpGet()).Methods(http.MethodGet)
 	views.HandleFunc("/account/password-reset", s.accountPasswordResetGet()).Methods(http.MethodGet)
 	views.HandleFunc("/account/password-reset", s.accountPasswordResetPut()).Methods(http.MethodPut)
+	views.HandleFunc("/account/verify-email", s.accountVerifyEmailGet()).Methods(http.MethodGet)
+	views.HandleFunc("/account/verify-email", s.accountVerifyEmailPost()).Methods(http.MethodPost)
+	views.HandleFunc("/unsubscribe/{token}", s.unsubscribeGet()).Methods(http.MethodGet)
+	views.HandleFunc("/unsubscribe/{token}", s.unsubscribePost()).Methods(http.MethodPost)
 	views.HandleFunc("/", s.indexGet()).Methods(http.MethodGet)
 
 	// Transitional subrouter as we get rid of the idea of separate API routes vs.
@@ -55,11 +73,29 @@ func (s *Server) routes() {
 	authenticatedRoutes.Use(enforceContentSecurityPolicy)
 	authenticatedRoutes.HandleFunc("/account/notifications", s.accountNotificationsPut()).Methods(http.MethodPut)
 	authenticatedRoutes.HandleFunc("/account/password", s.accountChangePasswordPut()).Methods(http.MethodPut)
+	authenticatedRoutes.HandleFunc("/account/delete", s.accountDeletePost()).Methods(http.MethodPost)
+	authenticatedRoutes.HandleFunc("/account/email", s.accountEmailPut()).Methods(http.MethodPut)
+	authenticatedRoutes.HandleFunc("/account/email/verification", s.accountEmailVerificationPost()).Methods(http.MethodPost)
+	authenticatedRoutes.HandleFunc("/account/security/two-factor/setup", s.accountTwoFactorSetupPost()).Methods(http.MethodPost)
+	authenticatedRoutes.HandleFunc("/account/security/two-factor", s.accountTwoFactorPut()).Methods(http.MethodPut)
+	authenticatedRoutes.HandleFunc("/account/security/two-factor", s.accountTwoFactorDelete()).Methods(http.MethodDelete)
+	authenticatedRoutes.HandleFunc("/account/security/passkeys/options", s.accountPasskeysOptionsPost()).Methods(http.MethodPost)
+	authenticatedRoutes.HandleFunc("/account/security/passkeys", s.accountPasskeysPost()).Methods(http.MethodPost)
+	authenticatedRoutes.HandleFunc("/account/security/passkeys/{passkeyID}", s.accountPasskeysPut()).Methods(http.MethodPut)
+	authenticatedRoutes.HandleFunc("/account/security/passkeys/{passkeyID}", s.accountPasskeysDelete()).Methods(http.MethodDelete)
+	authenticatedRoutes.HandleFunc("/account/security/sessions", s.accountOtherSessionsDelete()).Methods(http.MethodDelete)
+	authenticatedRoutes.HandleFunc("/account/security/sessions/{sessionHandle}", s.accountSessionsDelete()).Methods(http.MethodDelete)
 	authenticatedRoutes.HandleFunc("/reviews", s.reviewsPost()).Methods(http.MethodPost)
 	authenticatedRoutes.HandleFunc("/reviews/{reviewID}", s.reviewsPut()).Methods(http.MethodPut)
 	authenticatedRoutes.HandleFunc("/reviews/{reviewID}", s.reviewsDelete()).Methods(http.MethodDelete)
+	authenticatedRoutes.HandleFunc("/friend-recommendations", s.friendRecommendationsPost()).Methods(http.MethodPost)
+	authenticatedRoutes.HandleFunc("/invites", s.invitesPost()).Methods(http.MethodPost)
+	authenticatedRoutes.HandleFunc("/friend-recommendations/{recommendationID}", s.friendRecommendationsPut()).Methods(http.MethodPut)
+	authenticatedRoutes.HandleFunc("/notifications/read", s.notificationsReadPost()).Methods(http.MethodPost)
 	authenticatedRoutes.HandleFunc("/reactions", s.reactionsPost()).Methods(http.MethodPost)
 	authenticatedRoutes.HandleFunc("/reactions/{reactionID}", s.reactionsDelete()).Methods(http.MethodDelete)
+	authenticatedRoutes.HandleFunc("/recap/{year}/{username}/email", s.recapEmailPost()).Methods(http.MethodPost)
+	authenticatedRoutes.HandleFunc("/users/{username}/relationship", s.userRelationshipPut()).Methods(http.MethodPut)
 
 	// Transitional subrouter as we get rid of the idea of separate API routes vs.
 	// view routes.
@@ -68,6 +104,15 @@ func (s *Server) routes() {
 	adminRoutes.Use(s.requireAdmin)
 	adminRoutes.Use(enforceContentSecurityPolicy)
 	adminRoutes.HandleFunc("/invites", s.invitesPost()).Methods(http.MethodPost)
+	adminRoutes.HandleFunc("/invites/member-policy", s.adminMemberInvitePolicyPut()).Methods(http.MethodPut)
+	adminRoutes.HandleFunc("/webhooks", s.webhooksPost()).Methods(http.MethodPost)
+	adminRoutes.HandleFunc("/webhooks/{webhookID}", s.webhooksDelete()).Methods(http.MethodDelete)
+	adminRoutes.HandleFunc("/email-outbox/{emailID}/retry", s.emailOutboxRetryPost()).Methods(http.MethodPost)
+	adminRoutes.HandleFunc("/login-lockouts/{scope}/{key}", s.loginLockoutsDelete()).Methods(http.MethodDelete)
+	adminRoutes.HandleFunc("/users/{username}/admin", s.adminUsersAdminPut()).Methods(http.MethodPut)
+	adminRoutes.HandleFunc("/users/{username}/disabled", s.adminUsersDisabledPut()).Methods(http.MethodPut)
+	adminRoutes.HandleFunc("/users/{username}/password-reset", s.adminUsersPasswordResetPost()).Methods(http.MethodPost)
+	adminRoutes.HandleFunc("/users/{username}/delete", s.adminUsersDeletePost()).Methods(http.MethodPost)
 
 	authenticatedViews := s.router.PathPrefix("/").Subrouter()
 	authenticatedViews.Use(s.requireAuthenticationForView)
@@ -75,9 +120,14 @@ func (s *Server) routes() {
 	authenticatedViews.HandleFunc("/account/change-password", s.accountChangePasswordGet()).Methods(http.MethodGet)
 	authenticatedViews.HandleFunc("/account/notifications", s.accountNotificationsGet()).Methods(http.MethodGet)
 	authenticatedViews.HandleFunc("/account/security", s.accountSecurityGet()).Methods(http.MethodGet)
+	authenticatedViews.HandleFunc("/notifications", s.notificationsGet()).Methods(http.MethodGet)
 	authenticatedViews.HandleFunc("/activity", s.activityGet()).Methods(http.MethodGet)
+	authenticatedViews.HandleFunc("/friend-recommendations", s.friendRecommendationsGet()).Methods(http.MethodGet)
+	authenticatedViews.HandleFunc("/invites", s.memberInvitesGet()).Methods(http.MethodGet)
 	authenticatedViews.HandleFunc("/movies/{movieID}", s.moviesReadGet()).Methods(http.MethodGet)
 	authenticatedViews.HandleFunc("/tv-shows/{tvShowID}", s.tvShowsReadGet()).Methods(http.MethodGet)
+	authenticatedViews.HandleFunc("/recommendations", s.recommendationsGet()).Methods(http.MethodGet)
+	authenticatedViews.HandleFunc("/recap/{year}/{username}", s.recapGet()).Methods(http.MethodGet)
 	authenticatedViews.HandleFunc("/reviews", s.reviewsGet()).Methods(http.MethodGet)
 	authenticatedViews.HandleFunc("/reviews/by/{username}", s.reviewsGet()).Methods(http.MethodGet)
 	authenticatedViews.HandleFunc("/reviews/new", s.reviewsNewTitleSearchGet()).Methods(http.MethodGet)
@@ -85,6 +135,7 @@ func (s *Server) routes() {
 	authenticatedViews.HandleFunc("/reviews/new/write", s.reviewsNewWriteReviewGet()).Methods(http.MethodGet)
 	authenticatedViews.HandleFunc("/reviews/{reviewID}/edit", s.reviewsEditGet()).Methods(http.MethodGet)
 	authenticatedViews.HandleFunc("/users", s.usersGet()).Methods(http.MethodGet)
+	authenticatedViews.HandleFunc("/users/{username}/compatibility/{otherUsername}", s.compatibilityGet()).Methods(http.MethodGet)
 
 	s.addDevRoutes()
 }
@@ -13,22 +13,78 @@ import (
 	"github.com/mtlynch/screenjournal/v2/metadata"
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
 	"github.com/mtlynch/screenjournal/v2/store/sqlite"
+	"github.com/mtlynch/screenjournal/v2/webauthn"
 )
 
 type (
 	Announcer interface {
 		AnnounceNewReview(screenjournal.Review)
 		AnnounceNewComment(screenjournal.ReviewComment)
+		AnnounceNewReaction(screenjournal.ReviewReaction)
+		AnnounceNewFriendRecommendation(screenjournal.FriendRecommendation)
+		AnnounceMention(screenjournal.Mention)
 	}
 
 	PasswordResetter interface {
 		SendEmail(screenjournal.Email) error
 		Reset(screenjournal.Username, screenjournal.PasswordResetToken, screenjournal.PasswordHash) error
+		SendLoginLink(screenjournal.Email) error
+		UseLoginLink(screenjournal.Username, screenjournal.PasswordResetToken) error
+	}
+
+	// EmailVerifier confirms that users receive mail at their email addresses.
+	EmailVerifier interface {
+		SendVerification(screenjournal.Username) error
+		RequestChange(screenjournal.Username, screenjournal.Email) error
+		Confirm(screenjournal.PasswordResetToken) (screenjournal.Username, error)
+	}
+
+	RecapSender interface {
+		SendRecap(screenjournal.User, screenjournal.Recap) error
+	}
+
+	// InviteSender emails signup invitations to their invitees.
+	InviteSender interface {
+		SendInvitation(screenjournal.SignupInvitation) error
+	}
+
+	// Unsubscriber verifies the signed tokens in email unsubscribe links.
+	Unsubscriber interface {
+		Verify(screenjournal.UnsubscribeToken) (screenjournal.Username, screenjournal.UnsubscribeCategory, error)
+	}
+
+	// TwoFactorAuthenticator enrolls users in TOTP-based two-factor
+	// authentication and checks their codes during login.
+	TwoFactorAuthenticator interface {
+		BeginEnrollment(screenjournal.Username) (screenjournal.TotpSecret, error)
+		ConfirmEnrollment(screenjournal.Username, string) ([]screenjournal.RecoveryCode, error)
+		Verify(screenjournal.Username, string) error
+		Disable(screenjournal.Username, string) error
+		IssueChallenge(screenjournal.Username) screenjournal.LoginChallenge
+		VerifyChallenge(screenjournal.LoginChallenge) (screenjournal.Username, error)
+	}
+
+	// PasskeyAuthenticator registers passkeys and verifies passkey logins.
+	PasskeyAuthenticator interface {
+		BeginRegistration(screenjournal.Username) (webauthn.CreationOptions, error)
+		FinishRegistration(screenjournal.Username, screenjournal.PasskeyName, webauthn.RegistrationResponse) (screenjournal.Passkey, error)
+		BeginLogin() (webauthn.RequestOptions, error)
+		FinishLogin(webauthn.AssertionResponse) (screenjournal.Username, error)
+	}
+
+	// SingleSignOn logs users in through an external OpenID Connect identity
+	// provider.
+	SingleSignOn interface {
+		BeginLogin(context.Context, string) (string, error)
+		FinishLogin(context.Context, string, string) (screenjournal.Username, string, error)
 	}
 
 	SessionManager interface {
 		LogIn(context.Context, http.ResponseWriter, simple_sessions.UserID) error
 		UserIDFromContext(context.Context) (simple_sessions.UserID, error)
+		// SessionIDFromContext returns the ID of the session that LoadUser
+		// loaded for the current request.
+		SessionIDFromContext(context.Context) (screenjournal.SessionID, error)
 		LogOut(context.Context, http.ResponseWriter) error
 		// LoadUser wraps the given handler, adding the user ID (if there's an
 		// active session) to the request context before passing control to the
@@ -36,6 +92,14 @@ type (
 		LoadUser(http.Handler) http.Handler
 	}
 
+	// LoginLimiter slows down and locks out repeated failed logins.
+	LoginLimiter interface {
+		Lock(screenjournal.Username, string) func()
+		RetryAfter(screenjournal.Username, string) (time.Duration, error)
+		RecordFailure(screenjournal.Username, string) error
+		RecordSuccess(screenjournal.Username) error
+	}
+
 	Authenticator interface {
 		Authenticate(username screenjournal.Username, password screenjournal.Password) error
 	}
@@ -54,6 +118,17 @@ type (
 		Store            sqlite.Store
 		MetadataFinder   MetadataFinder
 		PasswordResetter PasswordResetter
+		EmailVerifier    EmailVerifier
+		RecapSender      RecapSender
+		InviteSender     InviteSender
+		Unsubscriber     Unsubscriber
+		TwoFactor        TwoFactorAuthenticator
+		Passkeys         PasskeyAuthenticator
+		LoginLimiter     LoginLimiter
+		SingleSignOn     SingleSignOn
+		// SingleSignOnName is the name of the identity provider to show on the
+		// login page.
+		SingleSignOnName string
 	}
 
 	Server struct {
@@ -64,6 +139,15 @@ type (
 		store            sqlite.Store
 		metadataFinder   MetadataFinder
 		passwordResetter PasswordResetter
+		emailVerifier    EmailVerifier
+		recapSender      RecapSender
+		inviteSender     InviteSender
+		unsubscriber     Unsubscriber
+		twoFactor        TwoFactorAuthenticator
+		passkeys         PasskeyAuthenticator
+		loginLimiter     LoginLimiter
+		singleSignOn     SingleSignOn
+		singleSignOnName string
 	}
 )
 
@@ -83,6 +167,15 @@ func New(params ServerParams) Server {
 		store:            params.Store,
 		metadataFinder:   params.MetadataFinder,
 		passwordResetter: params.PasswordResetter,
+		emailVerifier:    params.EmailVerifier,
+		recapSender:      params.RecapSender,
+		inviteSender:     params.InviteSender,
+		unsubscriber:     params.Unsubscriber,
+		twoFactor:        params.TwoFactor,
+		passkeys:         params.Passkeys,
+		loginLimiter:     params.LoginLimiter,
+		singleSignOn:     params.SingleSignOn,
+		singleSignOnName: params.SingleSignOnName,
 	}
 
 	s.routes()
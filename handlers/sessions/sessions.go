@@ -1,18 +1,147 @@
 package sessions
 
 import (
+	"context"
+	"log"
+	"net"
+	"net/http"
 	"time"
 
 	simple_sessions "codeberg.org/mtlynch/simpleauth/v3/sessions"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+const (
+	sessionLifetime = 30 * 24 * time.Hour
+
+	// touchInterval limits how often we record that a session is still in use
+	// so that browsing doesn't write to the database on every request.
+	touchInterval = time.Minute
+
+	maxUserAgentLength = 512
+)
+
+type (
+	Store interface {
+		simple_sessions.Store
+		InsertSession(context.Context, simple_sessions.Session, screenjournal.SessionClient) error
+		TouchSession(ctx context.Context, id screenjournal.SessionID, now time.Time, seenBefore time.Time) error
+	}
+
+	// Manager manages sessions like simple_sessions.Manager but also records
+	// which browser each session belongs to and when it was last used.
+	Manager struct {
+		simple_sessions.Manager
+	}
+
+	// trackingStore sits between the session library and the real store so
+	// that it can attach request details to the sessions that the library
+	// creates and reads.
+	trackingStore struct {
+		Store
+		now func() time.Time
+	}
+
+	// requestInfo carries details about the current request through the
+	// session library.
+	requestInfo struct {
+		client    screenjournal.SessionClient
+		sessionID screenjournal.SessionID
+	}
+
+	contextKey struct{}
 )
 
-const sessionLifetime = 30 * 24 * time.Hour
+func NewManager(store Store, requireTls bool) Manager {
+	return Manager{
+		Manager: simple_sessions.NewManager(simple_sessions.Config{
+			Store: trackingStore{
+				Store: store,
+				now:   time.Now,
+			},
+			RequireTLS: requireTls,
+			Now:        time.Now,
+			Lifetime:   sessionLifetime,
+		}),
+	}
+}
 
-func NewManager(store simple_sessions.Store, requireTls bool) simple_sessions.Manager {
-	return simple_sessions.NewManager(simple_sessions.Config{
-		Store:      store,
-		RequireTLS: requireTls,
-		Now:        time.Now,
-		Lifetime:   sessionLifetime,
+// LoadUser wraps the given handler, adding the user ID (if there's an active
+// session) to the request context before passing control to the next handler.
+func (m Manager) LoadUser(next http.Handler) http.Handler {
+	loadUser := m.Manager.LoadUser(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := &requestInfo{
+			client: clientFromRequest(r),
+		}
+		loadUser.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, info)))
 	})
 }
+
+// SessionIDFromContext returns the ID of the session that LoadUser loaded for
+// the current request.
+func (m Manager) SessionIDFromContext(ctx context.Context) (screenjournal.SessionID, error) {
+	info, ok := ctx.Value(contextKey{}).(*requestInfo)
+	if !ok || info.sessionID.Empty() {
+		return screenjournal.SessionID(""), simple_sessions.ErrNoSessionFound
+	}
+	return info.sessionID, nil
+}
+
+func (s trackingStore) CreateSession(ctx context.Context, session simple_sessions.Session) error {
+	var client screenjournal.SessionClient
+	info, ok := ctx.Value(contextKey{}).(*requestInfo)
+	if ok {
+		client = info.client
+	}
+
+	if err := s.Store.InsertSession(ctx, session, client); err != nil {
+		return err
+	}
+
+	if ok {
+		info.sessionID = screenjournal.SessionID(session.ID.String())
+	}
+	return nil
+}
+
+func (s trackingStore) ReadSession(ctx context.Context, id simple_sessions.ID) (simple_sessions.Session, error) {
+	session, err := s.Store.ReadSession(ctx, id)
+	if err != nil {
+		return simple_sessions.Session{}, err
+	}
+
+	sessionID := screenjournal.SessionID(id.String())
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		info.sessionID = sessionID
+	}
+
+	now := s.now()
+	// Failing to record the last-seen time shouldn't sign the user out.
+	if err := s.Store.TouchSession(ctx, sessionID, now, now.Add(-touchInterval)); err != nil {
+		log.Printf("failed to record session activity: %v", err)
+	}
+
+	return session, nil
+}
+
+func clientFromRequest(r *http.Request) screenjournal.SessionClient {
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	// If the server runs behind a proxy, the proxy middleware has already
+	// replaced RemoteAddr with the client's address, which might not include
+	// a port.
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return screenjournal.SessionClient{
+		UserAgent: userAgent,
+		IPAddress: ip,
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	simple_sessions "codeberg.org/mtlynch/simpleauth/v3/sessions"
 
@@ -99,6 +100,72 @@ func TestManagerStoresSessionsInSQLite(t *testing.T) {
 	}
 }
 
+func TestManagerRecordsSessionClient(t *testing.T) {
+	store := test_sqlite.New()
+	userID, err := simple_sessions.NewUserID("dummyUserID")
+	if err != nil {
+		panic("failed to create new user")
+	}
+	if err := store.InsertUser(screenjournal.User{
+		Username:     screenjournal.Username(userID.String()),
+		Email:        screenjournal.Email("dummy@example.com"),
+		PasswordHash: screenjournal.PasswordHash("dummy-password-hash"),
+	}); err != nil {
+		panic("failed to add user to store")
+	}
+	manager := sessions.NewManager(store, false)
+
+	// Log in through LoadUser so that the manager sees the request details.
+	loginReq := httptest.NewRequest(http.MethodPost, "/api/auth", nil)
+	loginReq.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64; rv:138.0) Gecko/20100101 Firefox/138.0")
+	loginReq.RemoteAddr = "203.0.113.7:52100"
+	loginRec := httptest.NewRecorder()
+	var loginSessionID screenjournal.SessionID
+	manager.LoadUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := manager.LogIn(r.Context(), w, userID); err != nil {
+			t.Fatalf("LogIn err=%v, want=%v", err, nil)
+		}
+		id, err := manager.SessionIDFromContext(r.Context())
+		if err != nil {
+			t.Fatalf("SessionIDFromContext after login err=%v, want=%v", err, nil)
+		}
+		loginSessionID = id
+	})).ServeHTTP(loginRec, loginReq)
+
+	userSessions, err := store.ReadUserSessions(screenjournal.Username(userID.String()), time.Now())
+	if err != nil {
+		t.Fatalf("ReadUserSessions err=%v, want=%v", err, nil)
+	}
+	if got, want := len(userSessions), 1; got != want {
+		t.Fatalf("len(sessions)=%d, want=%d", got, want)
+	}
+	if got, want := userSessions[0].Client, (screenjournal.SessionClient{
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:138.0) Gecko/20100101 Firefox/138.0",
+		IPAddress: "203.0.113.7",
+	}); got != want {
+		t.Errorf("client=%+v, want=%+v", got, want)
+	}
+	if got, want := userSessions[0].ID, loginSessionID; got != want {
+		t.Errorf("session ID=%v, want=%v", got, want)
+	}
+
+	// A later request with the cookie can tell which session it belongs to.
+	cookies := loginRec.Result().Cookies()
+	if got, want := len(cookies), 1; got != want {
+		t.Fatalf("len(cookies)=%d, want=%d", got, want)
+	}
+	var sessionID screenjournal.SessionID
+	manager.LoadUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID, err = manager.SessionIDFromContext(r.Context())
+	})).ServeHTTP(httptest.NewRecorder(), requestWithCookie(cookies[0]))
+	if got, want := err, error(nil); got != want {
+		t.Fatalf("SessionIDFromContext err=%v, want=%v", got, want)
+	}
+	if got, want := sessionID, loginSessionID; got != want {
+		t.Errorf("session ID=%v, want=%v", got, want)
+	}
+}
+
 func requestWithCookie(cookie *http.Cookie) *http.Request {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	req.AddCookie(cookie)
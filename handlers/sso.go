@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/sso"
+)
+
+// defaultNextPath is where users land after logging in if they didn't come
+// from another page.
+const defaultNextPath = "/reviews"
+
+func (s Server) authSingleSignOnGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.singleSignOn == nil {
+			http.Error(w, "Single sign-on is not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		authURL, err := s.singleSignOn.BeginLogin(r.Context(), nextPathFromQueryParams(r))
+		if err != nil {
+			log.Printf("failed to begin single sign-on: %v", err)
+			http.Error(w, "Failed to start single sign-on", http.StatusBadGateway)
+			return
+		}
+
+		http.Redirect(w, r, authURL, http.StatusFound)
+	}
+}
+
+// authSingleSignOnCallbackGet handles the identity provider redirecting the
+// user back to ScreenJournal. Users with two-factor authentication still have
+// to enter a code, since ScreenJournal can't tell whether the identity
+// provider asked for a second factor.
+func (s Server) authSingleSignOnCallbackGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.singleSignOn == nil {
+			http.Error(w, "Single sign-on is not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		q := r.URL.Query()
+		if idpError := q.Get("error"); idpError != "" {
+			log.Printf("identity provider returned error: %s %s", idpError, q.Get("error_description"))
+			http.Error(w, "Single sign-on failed. Please try again.", http.StatusUnauthorized)
+			return
+		}
+
+		username, nextPath, err := s.singleSignOn.FinishLogin(r.Context(), q.Get("state"), q.Get("code"))
+		if err != nil {
+			log.Printf("single sign-on failed: %v", err)
+			switch {
+			case errors.Is(err, sso.ErrInvalidState):
+				http.Error(w, "Login expired. Please try again.", http.StatusBadRequest)
+			case errors.Is(err, sso.ErrLoginFailed):
+				http.Error(w, "Single sign-on failed. Please try again.", http.StatusUnauthorized)
+			case errors.Is(err, sso.ErrNoAccount):
+				http.Error(w, "No ScreenJournal account matches your identity. Ask an admin for an invite.", http.StatusForbidden)
+			default:
+				http.Error(w, "Failed to complete single sign-on", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		s.logInAndRedirect(w, r, username, nextPath)
+	}
+}
+
+// logInAndRedirect finishes a login that happened outside the login page,
+// such as through the identity provider or an emailed link, and sends the
+// user to nextPath. Users with two-factor authentication go back to the login
+// page to enter a code first.
+func (s Server) logInAndRedirect(w http.ResponseWriter, r *http.Request, username screenjournal.Username, nextPath string) {
+	if !s.checkAccountEnabled(w, username) {
+		return
+	}
+
+	twoFactorEnabled, err := s.isTwoFactorEnabled(username)
+	if err != nil {
+		log.Printf("failed to read two-factor configuration for user %s: %v", username, err)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+	if twoFactorEnabled {
+		if s.twoFactor == nil {
+			log.Printf("user %s has two-factor authentication enabled, but server has no two-factor authenticator", username)
+			http.Error(w, "Failed to create session", http.StatusInternalServerError)
+			return
+		}
+		challengeURL := "/login?" + url.Values{
+			"two-factor-challenge": {s.twoFactor.IssueChallenge(username).String()},
+			"next":                 {nextPath},
+		}.Encode()
+		http.Redirect(w, r, challengeURL, http.StatusFound)
+		return
+	}
+
+	userID, err := userIDFromUsername(username)
+	if err != nil {
+		log.Printf("failed to create user ID for user %s: %v", username, err)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+	if err := s.sessionManager.LogIn(r.Context(), w, userID); err != nil {
+		log.Printf("failed to create session for user %s: %v", username, err)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, nextPath, http.StatusFound)
+}
+
+// nextPathFromQueryParams returns the local path in the next query parameter.
+// It ignores anything that would redirect the user to another site.
+func nextPathFromQueryParams(r *http.Request) string {
+	next := r.URL.Query().Get("next")
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, `/\`) {
+		return defaultNextPath
+	}
+	return next
+}
@@ -0,0 +1,216 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/oidc"
+	"github.com/mtlynch/screenjournal/v2/oidc/oidctest"
+	"github.com/mtlynch/screenjournal/v2/sso"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+	"github.com/mtlynch/screenjournal/v2/twofactor"
+)
+
+func TestSingleSignOn(t *testing.T) {
+	for _, tt := range []struct {
+		description      string
+		next             string
+		idpUser          oidctest.User
+		twoFactor        bool
+		status           int
+		location         string
+		sessionCreated   bool
+		twoFactorPending bool
+	}{
+		{
+			description: "logs in a user with a matching verified email",
+			next:        "/movies/1",
+			idpUser: oidctest.User{
+				Subject:       "user-1234",
+				Email:         "userA@example.com",
+				EmailVerified: true,
+			},
+			status:         http.StatusFound,
+			location:       "/movies/1",
+			sessionCreated: true,
+		},
+		{
+			description: "ignores a next URL on another site",
+			next:        "//evil.example.com/phish",
+			idpUser: oidctest.User{
+				Subject:       "user-1234",
+				Email:         "userA@example.com",
+				EmailVerified: true,
+			},
+			status:         http.StatusFound,
+			location:       "/reviews",
+			sessionCreated: true,
+		},
+		{
+			description: "rejects a user without an account",
+			next:        "/reviews",
+			idpUser: oidctest.User{
+				Subject:       "user-5678",
+				Email:         "stranger@example.com",
+				EmailVerified: true,
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			description: "asks a user with two-factor authentication for a code",
+			next:        "/movies/1",
+			idpUser: oidctest.User{
+				Subject:       "user-1234",
+				Email:         "userA@example.com",
+				EmailVerified: true,
+			},
+			twoFactor:        true,
+			status:           http.StatusFound,
+			twoFactorPending: true,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			idp := oidctest.New("screenjournal", "s3cr3t")
+			defer idp.Close()
+			idp.SetUser(tt.idpUser)
+
+			dataStore := test_sqlite.New()
+			user := userA
+			user.EmailVerified = true
+			if err := dataStore.InsertUser(user); err != nil {
+				t.Fatalf("failed to insert user: %v", err)
+			}
+
+			now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+			twoFactor := twofactor.New(dataStore, []byte("dummy-key"), func() time.Time { return now })
+			if tt.twoFactor {
+				secret, err := twoFactor.BeginEnrollment(userA.Username)
+				if err != nil {
+					t.Fatalf("failed to begin enrollment: %v", err)
+				}
+				if _, err := twoFactor.ConfirmEnrollment(userA.Username, mustTotpCode(t, secret, now)); err != nil {
+					t.Fatalf("failed to confirm enrollment: %v", err)
+				}
+			}
+
+			provider := oidc.New(oidc.Config{
+				IssuerURL:    idp.URL,
+				ClientID:     idp.ClientID,
+				ClientSecret: idp.ClientSecret,
+				RedirectURL:  "https://sj.example.com/auth/oidc/callback",
+			}, http.DefaultClient, time.Now)
+			sessionManager := newMockSessionManager([]mockSessionEntry{})
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:    auth.New(dataStore),
+				SessionManager:   &sessionManager,
+				Store:            dataStore,
+				TwoFactor:        twoFactor,
+				SingleSignOn:     sso.New(dataStore, provider, "", time.Now),
+				SingleSignOnName: "Example IdP",
+			})
+
+			req, err := http.NewRequest("GET", "/auth/oidc/login?next="+url.QueryEscape(tt.next), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+			if got, want := rec.Code, http.StatusFound; got != want {
+				t.Fatalf("login httpStatus=%v, want=%v", got, want)
+			}
+
+			callback, err := idp.Authorize(rec.Header().Get("Location"))
+			if err != nil {
+				t.Fatalf("authorization failed: %v", err)
+			}
+
+			req, err = http.NewRequest("GET", callback.RequestURI(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec = httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+
+			if got, want := rec.Code, tt.status; got != want {
+				t.Fatalf("callback httpStatus=%v, want=%v", got, want)
+			}
+			if tt.location != "" {
+				if got, want := rec.Header().Get("Location"), tt.location; got != want {
+					t.Errorf("callback Location=%v, want=%v", got, want)
+				}
+			}
+			if got, want := len(sessionManager.sessions) > 0, tt.sessionCreated; got != want {
+				t.Errorf("sessionCreated=%v, want=%v", got, want)
+			}
+			if tt.twoFactorPending {
+				location, err := url.Parse(rec.Header().Get("Location"))
+				if err != nil {
+					t.Fatalf("invalid callback Location: %v", err)
+				}
+				if got, want := location.Path, "/login"; got != want {
+					t.Errorf("callback redirect path=%v, want=%v", got, want)
+				}
+				if location.Query().Get("two-factor-challenge") == "" {
+					t.Errorf("callback redirect has no two-factor challenge")
+				}
+				if got, want := location.Query().Get("next"), tt.next; got != want {
+					t.Errorf("callback redirect next=%v, want=%v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSingleSignOnLoginPage(t *testing.T) {
+	for _, tt := range []struct {
+		description  string
+		singleSignOn handlers.SingleSignOn
+		wantButton   bool
+	}{
+		{
+			description: "hides the single sign-on button when it's not configured",
+			wantButton:  false,
+		},
+		{
+			description:  "shows the single sign-on button when it's configured",
+			singleSignOn: sso.New(test_sqlite.New(), nil, "", time.Now),
+			wantButton:   true,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			sessionManager := newMockSessionManager([]mockSessionEntry{})
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:    auth.New(dataStore),
+				SessionManager:   &sessionManager,
+				Store:            dataStore,
+				SingleSignOn:     tt.singleSignOn,
+				SingleSignOnName: "Example IdP",
+			})
+
+			req, err := http.NewRequest("GET", "/login?next=%2Fmovies%2F1", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+			if got, want := rec.Code, http.StatusOK; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			body := rec.Body.String()
+			if got, want := strings.Contains(body, `href="/auth/oidc/login?next=%2Fmovies%2F1"`), tt.wantButton; got != want {
+				t.Errorf("page has single sign-on link=%v, want=%v", got, want)
+			}
+			if got, want := strings.Contains(body, "Example IdP"), tt.wantButton; got != want {
+				t.Errorf("page names identity provider=%v, want=%v", got, want)
+			}
+		})
+	}
+}
@@ -9,6 +9,32 @@ export async function authenticate(username, password) {
       username: username,
       password: password,
     }),
+  }).then((response) => {
+    if (!response.ok) {
+      return response.text().then((error) => {
+        return Promise.reject(error);
+      });
+    }
+    // The server accepted the password but needs a second factor before it
+    // creates a session.
+    if (response.status === 202) {
+      return response.json().then((body) => body.challenge);
+    }
+    return Promise.resolve(null);
+  });
+}
+
+export async function verifySecondFactor(challenge, code) {
+  return fetch("/api/auth/two-factor", {
+    method: "POST",
+    mode: "same-origin",
+    credentials: "include",
+    cache: "no-cache",
+    redirect: "error",
+    body: JSON.stringify({
+      challenge: challenge,
+      code: code,
+    }),
   }).then((response) => {
     if (!response.ok) {
       return response.text().then((error) => {
@@ -0,0 +1,134 @@
+// WebAuthn passes binary fields as ArrayBuffers, but the server sends and
+// receives them as unpadded base64url strings.
+function base64UrlToBuffer(value) {
+  const base64 = value.replace(/-/g, "+").replace(/_/g, "/");
+  const padded = base64.padEnd(
+    base64.length + ((4 - (base64.length % 4)) % 4),
+    "="
+  );
+  return Uint8Array.from(atob(padded), (c) => c.charCodeAt(0)).buffer;
+}
+
+function bufferToBase64Url(buffer) {
+  const bytes = new Uint8Array(buffer);
+  let binary = "";
+  bytes.forEach((b) => {
+    binary += String.fromCharCode(b);
+  });
+  return btoa(binary)
+    .replace(/\+/g, "-")
+    .replace(/\//g, "_")
+    .replace(/=+$/, "");
+}
+
+function postJson(url, body) {
+  return fetch(url, {
+    method: "POST",
+    mode: "same-origin",
+    credentials: "include",
+    cache: "no-cache",
+    redirect: "error",
+    body: body === undefined ? undefined : JSON.stringify(body),
+  }).then((response) => {
+    if (!response.ok) {
+      return response.text().then((error) => {
+        return Promise.reject(error);
+      });
+    }
+    return response;
+  });
+}
+
+// describeWebAuthnError turns the DOMExceptions that browsers raise for
+// common WebAuthn failures into messages that make sense to the user.
+function describeWebAuthnError(error) {
+  if (error instanceof DOMException && error.name === "NotAllowedError") {
+    return Promise.reject("Passkey request was cancelled.");
+  }
+  if (error instanceof DOMException && error.name === "InvalidStateError") {
+    return Promise.reject(
+      "This device already has a passkey for your account."
+    );
+  }
+  return Promise.reject(error);
+}
+
+export function isPasskeySupported() {
+  return (
+    window.PublicKeyCredential !== undefined &&
+    navigator.credentials !== undefined
+  );
+}
+
+// registerPasskey creates a passkey for the signed-in user and resolves to the
+// HTML of their updated passkey list.
+export async function registerPasskey(name) {
+  const options = await postJson("/account/security/passkeys/options").then(
+    (response) => response.json()
+  );
+
+  const credential = await navigator.credentials
+    .create({
+      publicKey: {
+        ...options,
+        challenge: base64UrlToBuffer(options.challenge),
+        user: {
+          ...options.user,
+          id: base64UrlToBuffer(options.user.id),
+        },
+        excludeCredentials: options.excludeCredentials.map((c) => ({
+          ...c,
+          id: base64UrlToBuffer(c.id),
+        })),
+      },
+    })
+    .catch(describeWebAuthnError);
+
+  return postJson("/account/security/passkeys", {
+    name: name,
+    credential: {
+      id: credential.id,
+      rawId: bufferToBase64Url(credential.rawId),
+      type: credential.type,
+      response: {
+        clientDataJSON: bufferToBase64Url(credential.response.clientDataJSON),
+        attestationObject: bufferToBase64Url(
+          credential.response.attestationObject
+        ),
+      },
+    },
+  }).then((response) => response.text());
+}
+
+// logInWithPasskey asks the browser for any passkey the user has for this
+// site and exchanges it for a session.
+export async function logInWithPasskey() {
+  const options = await postJson("/api/auth/passkey/options").then(
+    (response) => response.json()
+  );
+
+  const credential = await navigator.credentials
+    .get({
+      publicKey: {
+        ...options,
+        challenge: base64UrlToBuffer(options.challenge),
+      },
+    })
+    .catch(describeWebAuthnError);
+
+  await postJson("/api/auth/passkey", {
+    id: credential.id,
+    rawId: bufferToBase64Url(credential.rawId),
+    type: credential.type,
+    response: {
+      clientDataJSON: bufferToBase64Url(credential.response.clientDataJSON),
+      authenticatorData: bufferToBase64Url(
+        credential.response.authenticatorData
+      ),
+      signature: bufferToBase64Url(credential.response.signature),
+      userHandle: credential.response.userHandle
+        ? bufferToBase64Url(credential.response.userHandle)
+        : null,
+    },
+  });
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/qrcode"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/totp"
+	"github.com/mtlynch/screenjournal/v2/twofactor"
+)
+
+// totpIssuer is the name that authenticator apps show next to the user's
+// codes.
+const totpIssuer = "ScreenJournal"
+
+type twoFactorStatusProps struct {
+	Available              bool
+	Enabled                bool
+	RecoveryCodesRemaining int
+}
+
+func (s Server) authTwoFactorPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.twoFactor == nil {
+			http.Error(w, "Two-factor authentication is not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		challenge, code, err := secondFactorFromRequest(r)
+		if err != nil {
+			log.Printf("invalid two-factor auth request: %v", err)
+			http.Error(w, "Invalid two-factor code", http.StatusBadRequest)
+			return
+		}
+
+		username, err := s.twoFactor.VerifyChallenge(challenge)
+		if err != nil {
+			log.Printf("two-factor auth failed: %v", err)
+			if errors.Is(err, twofactor.ErrExpiredChallenge) {
+				http.Error(w, "Login expired. Please enter your password again.", http.StatusUnauthorized)
+				return
+			}
+			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+			return
+		}
+
+		if err := s.twoFactor.Verify(username, code); err != nil {
+			log.Printf("two-factor auth failed for user %s: %v", username, err)
+			switch {
+			case errors.Is(err, twofactor.ErrTooManyAttempts):
+				http.Error(w, "Too many attempts. Please try again later.", http.StatusTooManyRequests)
+			case errors.Is(err, twofactor.ErrInvalidCode), errors.Is(err, twofactor.ErrNotEnabled):
+				http.Error(w, "Invalid two-factor code", http.StatusUnauthorized)
+			default:
+				http.Error(w, "Failed to verify two-factor code", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		s.logIn(w, r, username)
+	}
+}
+
+func (s Server) accountTwoFactorSetupPost() http.HandlerFunc {
+	t := template.Must(template.ParseFS(templatesFS, "templates/fragments/two-factor-setup.html"))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.twoFactor == nil {
+			http.Error(w, "Two-factor authentication is not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		secret, err := s.twoFactor.BeginEnrollment(username)
+		if err != nil {
+			if errors.Is(err, twofactor.ErrAlreadyEnabled) {
+				http.Error(w, "Two-factor authentication is already enabled", http.StatusConflict)
+				return
+			}
+			log.Printf("failed to begin two-factor enrollment for user %s: %v", username, err)
+			http.Error(w, "Failed to set up two-factor authentication", http.StatusInternalServerError)
+			return
+		}
+
+		qr, err := qrcode.Encode(totp.URI(totpIssuer, username, secret))
+		if err != nil {
+			log.Printf("failed to encode two-factor QR code for user %s: %v", username, err)
+			http.Error(w, "Failed to set up two-factor authentication", http.StatusInternalServerError)
+			return
+		}
+
+		renderTemplate(w, t, "two-factor-setup.html", struct {
+			// We generate the SVG ourselves, and it contains no user input.
+			QrCode template.HTML
+			Secret screenjournal.TotpSecret
+		}{
+			QrCode: template.HTML(qr.SVG()),
+			Secret: secret,
+		})
+	}
+}
+
+func (s Server) accountTwoFactorPut() http.HandlerFunc {
+	t := template.Must(template.ParseFS(templatesFS, "templates/fragments/two-factor-recovery-codes.html"))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.twoFactor == nil {
+			http.Error(w, "Two-factor authentication is not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		code, err := parse.TwoFactorCode(r.PostFormValue("code"))
+		if err != nil {
+			http.Error(w, "Invalid two-factor code", http.StatusBadRequest)
+			return
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		recoveryCodes, err := s.twoFactor.ConfirmEnrollment(username, code)
+		if err != nil {
+			switch {
+			case errors.Is(err, twofactor.ErrTooManyAttempts):
+				http.Error(w, "Too many attempts. Please try again later.", http.StatusTooManyRequests)
+			case errors.Is(err, twofactor.ErrInvalidCode):
+				http.Error(w, "That code didn't match. Check your authenticator app and try again.", http.StatusBadRequest)
+			case errors.Is(err, twofactor.ErrNoPendingEnrollment), errors.Is(err, twofactor.ErrAlreadyEnabled):
+				http.Error(w, "No two-factor setup is in progress", http.StatusConflict)
+			default:
+				log.Printf("failed to confirm two-factor enrollment for user %s: %v", username, err)
+				http.Error(w, "Failed to enable two-factor authentication", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		renderTemplate(w, t, "two-factor-recovery-codes.html", struct {
+			RecoveryCodes []screenjournal.RecoveryCode
+		}{
+			RecoveryCodes: recoveryCodes,
+		})
+	}
+}
+
+func (s Server) accountTwoFactorDelete() http.HandlerFunc {
+	t := template.Must(template.ParseFS(templatesFS, "templates/fragments/two-factor-status.html"))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.twoFactor == nil {
+			http.Error(w, "Two-factor authentication is not available on this server", http.StatusServiceUnavailable)
+			return
+		}
+
+		code, err := parse.TwoFactorCode(r.FormValue("code"))
+		if err != nil {
+			http.Error(w, "Invalid two-factor code", http.StatusBadRequest)
+			return
+		}
+
+		username := mustGetUsernameFromContext(r.Context())
+		if err := s.twoFactor.Disable(username, code); err != nil {
+			switch {
+			case errors.Is(err, twofactor.ErrTooManyAttempts):
+				http.Error(w, "Too many attempts. Please try again later.", http.StatusTooManyRequests)
+			case errors.Is(err, twofactor.ErrInvalidCode):
+				http.Error(w, "That code didn't match. Check your authenticator app and try again.", http.StatusBadRequest)
+			case errors.Is(err, twofactor.ErrNotEnabled):
+				http.Error(w, "Two-factor authentication is not enabled", http.StatusConflict)
+			default:
+				log.Printf("failed to disable two-factor authentication for user %s: %v", username, err)
+				http.Error(w, "Failed to disable two-factor authentication", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		renderTemplate(w, t, "two-factor-status.html", twoFactorStatusProps{
+			Available: true,
+		})
+	}
+}
+
+// requireSecondFactor responds to a correct password from a user with
+// two-factor authentication enabled. Rather than creating a session, it
+// returns a challenge that the client exchanges for a session along with a
+// valid code.
+func (s Server) requireSecondFactor(w http.ResponseWriter, username screenjournal.Username) {
+	if s.twoFactor == nil {
+		log.Printf("user %s has two-factor authentication enabled, but server has no two-factor authenticator", username)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(map[string]string{
+		"challenge": s.twoFactor.IssueChallenge(username).String(),
+	}); err != nil {
+		log.Printf("failed to encode login challenge response: %v", err)
+	}
+}
+
+func (s Server) isTwoFactorEnabled(username screenjournal.Username) (bool, error) {
+	tf, err := s.store.ReadTwoFactor(username)
+	if errors.Is(err, store.ErrTwoFactorNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return tf.Enabled, nil
+}
+
+func (s Server) readTwoFactorStatus(username screenjournal.Username) (twoFactorStatusProps, error) {
+	props := twoFactorStatusProps{
+		Available: s.twoFactor != nil,
+	}
+
+	enabled, err := s.isTwoFactorEnabled(username)
+	if err != nil {
+		return twoFactorStatusProps{}, err
+	}
+	if !enabled {
+		return props, nil
+	}
+
+	remaining, err := s.store.CountUnusedRecoveryCodes(username)
+	if err != nil {
+		return twoFactorStatusProps{}, err
+	}
+	props.Enabled = true
+	props.RecoveryCodesRemaining = remaining
+
+	return props, nil
+}
+
+func secondFactorFromRequest(r *http.Request) (screenjournal.LoginChallenge, string, error) {
+	body := struct {
+		Challenge string `json:"challenge"`
+		Code      string `json:"code"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return screenjournal.LoginChallenge(""), "", err
+	}
+
+	challenge, err := parse.LoginChallenge(body.Challenge)
+	if err != nil {
+		return screenjournal.LoginChallenge(""), "", err
+	}
+
+	code, err := parse.TwoFactorCode(body.Code)
+	if err != nil {
+		return screenjournal.LoginChallenge(""), "", err
+	}
+
+	return challenge, code, nil
+}
@@ -0,0 +1,261 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+	"github.com/mtlynch/screenjournal/v2/totp"
+	"github.com/mtlynch/screenjournal/v2/twofactor"
+)
+
+func mustTotpCode(t *testing.T, secret screenjournal.TotpSecret, at time.Time) string {
+	t.Helper()
+	code, err := totp.Code(secret, totp.Step(at))
+	if err != nil {
+		t.Fatalf("failed to generate TOTP code: %v", err)
+	}
+	return code
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	enrollmentTime := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	loginTime := enrollmentTime.Add(time.Minute)
+
+	for _, tt := range []struct {
+		description string
+		challenge   func(issued string) string
+		code        func(secret screenjournal.TotpSecret, recoveryCodes []screenjournal.RecoveryCode) string
+		elapsed     time.Duration
+		status      int
+	}{
+		{
+			description: "current TOTP code completes login",
+			challenge:   func(issued string) string { return issued },
+			code: func(secret screenjournal.TotpSecret, _ []screenjournal.RecoveryCode) string {
+				return mustTotpCode(t, secret, loginTime)
+			},
+			status: http.StatusOK,
+		},
+		{
+			description: "recovery code completes login",
+			challenge:   func(issued string) string { return issued },
+			code: func(_ screenjournal.TotpSecret, recoveryCodes []screenjournal.RecoveryCode) string {
+				return recoveryCodes[0].String()
+			},
+			status: http.StatusOK,
+		},
+		{
+			description: "wrong code fails",
+			challenge:   func(issued string) string { return issued },
+			code: func(screenjournal.TotpSecret, []screenjournal.RecoveryCode) string {
+				return "000000"
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			description: "code used for enrollment can't be replayed",
+			challenge:   func(issued string) string { return issued },
+			code: func(secret screenjournal.TotpSecret, _ []screenjournal.RecoveryCode) string {
+				return mustTotpCode(t, secret, enrollmentTime)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			description: "forged challenge fails",
+			challenge:   func(string) string { return "dXNlckE6OTk5OTk5OTk5OQ.forged" },
+			code: func(secret screenjournal.TotpSecret, _ []screenjournal.RecoveryCode) string {
+				return mustTotpCode(t, secret, loginTime)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			description: "expired challenge fails",
+			challenge:   func(issued string) string { return issued },
+			code: func(secret screenjournal.TotpSecret, _ []screenjournal.RecoveryCode) string {
+				return mustTotpCode(t, secret, loginTime.Add(10*time.Minute))
+			},
+			elapsed: 10 * time.Minute,
+			status:  http.StatusUnauthorized,
+		},
+		{
+			description: "malformed code fails",
+			challenge:   func(issued string) string { return issued },
+			code: func(screenjournal.TotpSecret, []screenjournal.RecoveryCode) string {
+				return "12345;"
+			},
+			status: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			if err := dataStore.InsertUser(userA); err != nil {
+				t.Fatalf("failed to insert user: %v", err)
+			}
+
+			now := enrollmentTime
+			twoFactor := twofactor.New(dataStore, []byte("dummy-key"), func() time.Time { return now })
+			secret, err := twoFactor.BeginEnrollment(userA.Username)
+			if err != nil {
+				t.Fatalf("failed to begin enrollment: %v", err)
+			}
+			recoveryCodes, err := twoFactor.ConfirmEnrollment(userA.Username, mustTotpCode(t, secret, now))
+			if err != nil {
+				t.Fatalf("failed to confirm enrollment: %v", err)
+			}
+
+			sessionManager := newMockSessionManager([]mockSessionEntry{})
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+				TwoFactor:      twoFactor,
+			})
+
+			now = loginTime
+			req, err := http.NewRequest("POST", "/api/auth", strings.NewReader(`{"username": "userA", "password": "dummyp@ss"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+			res := rec.Result()
+
+			if got, want := res.StatusCode, http.StatusAccepted; got != want {
+				t.Fatalf("password step httpStatus=%v, want=%v", got, want)
+			}
+			if got, want := len(sessionManager.sessions), 0; got != want {
+				t.Fatalf("count(sessions) after password step=%d, want=%d", got, want)
+			}
+			var body struct {
+				Challenge string `json:"challenge"`
+			}
+			if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode password step response: %v", err)
+			}
+
+			now = loginTime.Add(tt.elapsed)
+			payload, err := json.Marshal(map[string]string{
+				"challenge": tt.challenge(body.Challenge),
+				"code":      tt.code(secret, recoveryCodes),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, err = http.NewRequest("POST", "/api/auth/two-factor", strings.NewReader(string(payload)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec = httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+			res = rec.Result()
+
+			if got, want := res.StatusCode, tt.status; got != want {
+				t.Fatalf("second factor httpStatus=%v, want=%v", got, want)
+			}
+
+			sessionsWant := 0
+			if tt.status == http.StatusOK {
+				sessionsWant = 1
+			}
+			if got, want := len(sessionManager.sessions), sessionsWant; got != want {
+				t.Fatalf("count(sessions)=%d, want=%d", got, want)
+			}
+			for _, session := range sessionManager.sessions {
+				if got, want := session.Username, userA.Username; !got.Equal(want) {
+					t.Errorf("username=%v, want=%v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTwoFactorEnrollmentRoutes(t *testing.T) {
+	dataStore := test_sqlite.New()
+	sessions := []mockSessionEntry{
+		newMockSessionEntry("abc123", screenjournal.Username("userA")),
+	}
+	insertMockUsersForSessions(t, dataStore, sessions)
+
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	sessionManager := newMockSessionManager(sessions)
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+		TwoFactor:      twofactor.New(dataStore, []byte("dummy-key"), func() time.Time { return now }),
+	})
+
+	send := func(method, route string, form url.Values) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, route, strings.NewReader(form.Encode()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{
+			Name:  mockSessionTokenName,
+			Value: "abc123",
+		})
+		rec := httptest.NewRecorder()
+		s.Router().ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	status, body := send("POST", "/account/security/two-factor/setup", url.Values{})
+	if got, want := status, http.StatusOK; got != want {
+		t.Fatalf("setup httpStatus=%v, want=%v", got, want)
+	}
+	if !strings.Contains(body, "<svg") {
+		t.Errorf("setup response has no QR code: %s", body)
+	}
+	match := regexp.MustCompile(`id="two-factor-secret">([A-Z2-7]+)<`).FindStringSubmatch(body)
+	if match == nil {
+		t.Fatalf("setup response has no secret: %s", body)
+	}
+	secret := screenjournal.TotpSecret(match[1])
+
+	if status, _ := send("PUT", "/account/security/two-factor", url.Values{"code": {"000000"}}); status != http.StatusBadRequest {
+		t.Errorf("confirm with wrong code httpStatus=%v, want=%v", status, http.StatusBadRequest)
+	}
+
+	status, body = send("PUT", "/account/security/two-factor", url.Values{"code": {mustTotpCode(t, secret, now)}})
+	if got, want := status, http.StatusOK; got != want {
+		t.Fatalf("confirm httpStatus=%v, want=%v", got, want)
+	}
+	if got, want := strings.Count(body, "<li>"), screenjournal.RecoveryCodeCount; got != want {
+		t.Errorf("recovery codes shown=%d, want=%d", got, want)
+	}
+
+	tf, err := dataStore.ReadTwoFactor(screenjournal.Username("userA"))
+	if err != nil {
+		t.Fatalf("failed to read two-factor configuration: %v", err)
+	}
+	if !tf.Enabled {
+		t.Errorf("two-factor authentication is not enabled after confirming")
+	}
+
+	if status, _ := send("POST", "/account/security/two-factor/setup", url.Values{}); status != http.StatusConflict {
+		t.Errorf("setup while enabled httpStatus=%v, want=%v", status, http.StatusConflict)
+	}
+
+	now = now.Add(totp.Period)
+	disableRoute := fmt.Sprintf("/account/security/two-factor?code=%s", mustTotpCode(t, secret, now))
+	if status, _ := send("DELETE", disableRoute, url.Values{}); status != http.StatusOK {
+		t.Fatalf("disable httpStatus=%v, want=%v", status, http.StatusOK)
+	}
+	if _, err := dataStore.ReadTwoFactor(screenjournal.Username("userA")); err != store.ErrTwoFactorNotFound {
+		t.Errorf("ReadTwoFactor after disabling err=%v, want=%v", err, store.ErrTwoFactorNotFound)
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type unsubscribePageProps struct {
+	commonProps
+	Token        string
+	Description  string
+	Unsubscribed bool
+}
+
+// unsubscribeGet asks the user to confirm before unsubscribing. Email
+// scanners often follow links in messages, so a GET request must not change
+// any preferences.
+func (s Server) unsubscribeGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").ParseFS(
+			templatesFS,
+			append(baseTemplates, "templates/pages/unsubscribe.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := screenjournal.UnsubscribeToken(mux.Vars(r)["token"])
+		_, category, err := s.unsubscriber.Verify(token)
+		if err != nil || !isValidUnsubscribeCategory(category) {
+			http.Error(w, "Invalid unsubscribe link", http.StatusBadRequest)
+			return
+		}
+
+		renderTemplate(w, t, "base.html", unsubscribePageProps{
+			commonProps: makeCommonProps(r.Context()),
+			Token:       token.String(),
+			Description: unsubscribeCategoryDescription(category),
+		})
+	}
+}
+
+// unsubscribePost applies an unsubscribe token. Mail clients that support RFC
+// 8058 send this request directly when the user clicks their unsubscribe
+// button, so it doesn't require a session.
+func (s Server) unsubscribePost() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").ParseFS(
+			templatesFS,
+			append(baseTemplates, "templates/pages/unsubscribe.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := screenjournal.UnsubscribeToken(mux.Vars(r)["token"])
+		username, category, err := s.unsubscriber.Verify(token)
+		if err != nil || !isValidUnsubscribeCategory(category) {
+			http.Error(w, "Invalid unsubscribe link", http.StatusBadRequest)
+			return
+		}
+
+		prefs, err := s.store.ReadNotificationPreferences(username)
+		if err != nil {
+			log.Printf("failed to read notification preferences for %s: %v", username, err)
+			http.Error(w, "Failed to read notification preferences", http.StatusInternalServerError)
+			return
+		}
+
+		switch category {
+		case screenjournal.UnsubscribeNewReviews:
+			prefs.NewReviews = false
+		case screenjournal.UnsubscribeComments:
+			prefs.AllNewComments = false
+		case screenjournal.UnsubscribeReplies:
+			prefs.Replies = false
+		case screenjournal.UnsubscribeMentions:
+			prefs.Mentions = false
+		case screenjournal.UnsubscribeDigest:
+			prefs.Digest = screenjournal.DigestOff
+		}
+
+		if err := s.store.UpdateNotificationPreferences(username, prefs); err != nil {
+			log.Printf("failed to unsubscribe %s from %s: %v", username, category, err)
+			http.Error(w, "Failed to save notification preferences", http.StatusInternalServerError)
+			return
+		}
+
+		renderTemplate(w, t, "base.html", unsubscribePageProps{
+			commonProps:  makeCommonProps(r.Context()),
+			Token:        token.String(),
+			Description:  unsubscribeCategoryDescription(category),
+			Unsubscribed: true,
+		})
+	}
+}
+
+func isValidUnsubscribeCategory(category screenjournal.UnsubscribeCategory) bool {
+	return unsubscribeCategoryDescription(category) != ""
+}
+
+func unsubscribeCategoryDescription(category screenjournal.UnsubscribeCategory) string {
+	switch category {
+	case screenjournal.UnsubscribeNewReviews:
+		return "emails about new reviews"
+	case screenjournal.UnsubscribeComments:
+		return "emails about new comments"
+	case screenjournal.UnsubscribeReplies:
+		return "emails when someone replies to your comments"
+	case screenjournal.UnsubscribeMentions:
+		return "emails when someone mentions you"
+	case screenjournal.UnsubscribeDigest:
+		return "digest emails"
+	}
+	return ""
+}
@@ -0,0 +1,296 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	email_announce "github.com/mtlynch/screenjournal/v2/announce/email"
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/email"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+	"github.com/mtlynch/screenjournal/v2/unsubscribe"
+)
+
+func TestUnsubscribePost(t *testing.T) {
+	signer := unsubscribe.New([]byte("dummy-signing-key"))
+	allOn := screenjournal.NotificationPreferences{
+		NewReviews:      true,
+		NewReviewsScope: screenjournal.NotifyEveryone,
+		AllNewComments:  true,
+		Replies:         true,
+		Mentions:        true,
+		Digest:          screenjournal.DigestWeekly,
+	}
+
+	for _, tt := range []struct {
+		description   string
+		token         screenjournal.UnsubscribeToken
+		status        int
+		expectedPrefs screenjournal.NotificationPreferences
+	}{
+		{
+			description: "unsubscribes from new review emails",
+			token:       signer.Token(screenjournal.Username("userA"), screenjournal.UnsubscribeNewReviews),
+			status:      http.StatusOK,
+			expectedPrefs: screenjournal.NotificationPreferences{
+				NewReviewsScope: screenjournal.NotifyEveryone,
+				NewReviews:      false,
+				AllNewComments:  true,
+				Replies:         true,
+				Mentions:        true,
+				Digest:          screenjournal.DigestWeekly,
+			},
+		},
+		{
+			description: "unsubscribes from comment emails",
+			token:       signer.Token(screenjournal.Username("userA"), screenjournal.UnsubscribeComments),
+			status:      http.StatusOK,
+			expectedPrefs: screenjournal.NotificationPreferences{
+				NewReviewsScope: screenjournal.NotifyEveryone,
+				NewReviews:      true,
+				AllNewComments:  false,
+				Replies:         true,
+				Mentions:        true,
+				Digest:          screenjournal.DigestWeekly,
+			},
+		},
+		{
+			description: "unsubscribes from reply emails",
+			token:       signer.Token(screenjournal.Username("userA"), screenjournal.UnsubscribeReplies),
+			status:      http.StatusOK,
+			expectedPrefs: screenjournal.NotificationPreferences{
+				NewReviewsScope: screenjournal.NotifyEveryone,
+				NewReviews:      true,
+				AllNewComments:  true,
+				Replies:         false,
+				Mentions:        true,
+				Digest:          screenjournal.DigestWeekly,
+			},
+		},
+		{
+			description: "unsubscribes from mention emails",
+			token:       signer.Token(screenjournal.Username("userA"), screenjournal.UnsubscribeMentions),
+			status:      http.StatusOK,
+			expectedPrefs: screenjournal.NotificationPreferences{
+				NewReviewsScope: screenjournal.NotifyEveryone,
+				NewReviews:      true,
+				AllNewComments:  true,
+				Replies:         true,
+				Mentions:        false,
+				Digest:          screenjournal.DigestWeekly,
+			},
+		},
+		{
+			description: "unsubscribes from digest emails",
+			token:       signer.Token(screenjournal.Username("userA"), screenjournal.UnsubscribeDigest),
+			status:      http.StatusOK,
+			expectedPrefs: screenjournal.NotificationPreferences{
+				NewReviewsScope: screenjournal.NotifyEveryone,
+				NewReviews:      true,
+				AllNewComments:  true,
+				Replies:         true,
+				Mentions:        true,
+				Digest:          screenjournal.DigestOff,
+			},
+		},
+		{
+			description:   "rejects token signed with a different key",
+			token:         unsubscribe.New([]byte("other-key")).Token(screenjournal.Username("userA"), screenjournal.UnsubscribeNewReviews),
+			status:        http.StatusBadRequest,
+			expectedPrefs: allOn,
+		},
+		{
+			description:   "rejects token with unknown category",
+			token:         signer.Token(screenjournal.Username("userA"), screenjournal.UnsubscribeCategory("everything")),
+			status:        http.StatusBadRequest,
+			expectedPrefs: allOn,
+		},
+		{
+			description:   "rejects malformed token",
+			token:         screenjournal.UnsubscribeToken("banana"),
+			status:        http.StatusBadRequest,
+			expectedPrefs: allOn,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			sessions := []mockSessionEntry{
+				{
+					token: "abc123",
+					session: mockSession{
+						Username: screenjournal.Username("userA"),
+					},
+				},
+			}
+			insertMockUsersForSessions(t, dataStore, sessions)
+			if err := dataStore.UpdateNotificationPreferences(screenjournal.Username("userA"), allOn); err != nil {
+				t.Fatalf("failed to set notification preferences: %v", err)
+			}
+
+			sessionManager := newMockSessionManager(sessions)
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+				Unsubscriber:   signer,
+			})
+
+			// Send the request the way an RFC 8058 mail client would, without a
+			// session cookie.
+			req, err := http.NewRequest("POST", "/unsubscribe/"+tt.token.String(), strings.NewReader("List-Unsubscribe=One-Click"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+			res := rec.Result()
+
+			if got, want := res.StatusCode, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			prefs, err := dataStore.ReadNotificationPreferences(screenjournal.Username("userA"))
+			if err != nil {
+				t.Fatalf("failed to read notification preferences: %v", err)
+			}
+			if got, want := prefs, tt.expectedPrefs; got != want {
+				t.Errorf("notificationPreferences=%+v, want=%+v", got, want)
+			}
+		})
+	}
+}
+
+func TestUnsubscribeGetDoesNotChangePreferences(t *testing.T) {
+	signer := unsubscribe.New([]byte("dummy-signing-key"))
+	dataStore := test_sqlite.New()
+	sessions := []mockSessionEntry{
+		{
+			token: "abc123",
+			session: mockSession{
+				Username: screenjournal.Username("userA"),
+			},
+		},
+	}
+	insertMockUsersForSessions(t, dataStore, sessions)
+	prefsBefore, err := dataStore.ReadNotificationPreferences(screenjournal.Username("userA"))
+	if err != nil {
+		t.Fatalf("failed to read notification preferences: %v", err)
+	}
+
+	sessionManager := newMockSessionManager(sessions)
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+		Unsubscriber:   signer,
+	})
+
+	token := signer.Token(screenjournal.Username("userA"), screenjournal.UnsubscribeNewReviews)
+	req, err := http.NewRequest("GET", "/unsubscribe/"+token.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+	res := rec.Result()
+
+	if got, want := res.StatusCode, http.StatusOK; got != want {
+		t.Fatalf("httpStatus=%v, want=%v", got, want)
+	}
+
+	prefsAfter, err := dataStore.ReadNotificationPreferences(screenjournal.Username("userA"))
+	if err != nil {
+		t.Fatalf("failed to read notification preferences: %v", err)
+	}
+	if got, want := prefsAfter, prefsBefore; got != want {
+		t.Errorf("notificationPreferences=%+v, want=%+v", got, want)
+	}
+}
+
+type mockEmailSender struct {
+	emailsSent []email.Message
+}
+
+func (s *mockEmailSender) Send(msg email.Message) error {
+	s.emailsSent = append(s.emailsSent, msg)
+	return nil
+}
+
+func TestUnsubscribeFromReplyEmailStopsReplies(t *testing.T) {
+	const baseURL = "https://dev.thescreenjournal.com"
+	signer := unsubscribe.New([]byte("dummy-signing-key"))
+	td := makeCommentsTestData()
+	sessions := []mockSessionEntry{td.sessions.userA, td.sessions.userB}
+
+	dataStore := test_sqlite.New()
+	insertMockUsersForSessions(t, dataStore, sessions)
+	if _, err := dataStore.InsertMovie(td.movies.theWaterBoy); err != nil {
+		t.Fatalf("failed to insert movie: %v", err)
+	}
+	review := td.reviews.userBTheWaterBoy
+	if _, err := dataStore.InsertReview(review); err != nil {
+		t.Fatalf("failed to insert review: %v", err)
+	}
+
+	insertComment := func(owner screenjournal.Username, parentID screenjournal.CommentID) screenjournal.ReviewComment {
+		rc := screenjournal.ReviewComment{
+			ParentID:    parentID,
+			Owner:       owner,
+			CommentText: screenjournal.CommentText("Hi"),
+			Review:      review,
+		}
+		var err error
+		rc.ID, err = dataStore.InsertComment(rc)
+		if err != nil {
+			t.Fatalf("failed to insert comment: %v", err)
+		}
+		return rc
+	}
+	// userA also hears about every comment on the thread, so only the replies
+	// preference should control replies to their comment.
+	parent := insertComment(screenjournal.Username("userA"), screenjournal.CommentID(0))
+
+	sender := mockEmailSender{}
+	announcer := email_announce.New(baseURL, &sender, dataStore, signer)
+	announcer.AnnounceNewComment(insertComment(screenjournal.Username("userB"), parent.ID))
+
+	if got, want := len(sender.emailsSent), 1; got != want {
+		t.Fatalf("emails=%d, want=%d", got, want)
+	}
+	unsubscribeURL := strings.Trim(sender.emailsSent[0].Headers.Get("List-Unsubscribe"), "<>")
+	if !strings.HasPrefix(unsubscribeURL, baseURL+"/unsubscribe/") {
+		t.Fatalf("List-Unsubscribe=%s, want a link to %s/unsubscribe", unsubscribeURL, baseURL)
+	}
+
+	sessionManager := newMockSessionManager(sessions)
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+		Unsubscriber:   signer,
+	})
+	req, err := http.NewRequest("POST", strings.TrimPrefix(unsubscribeURL, baseURL), strings.NewReader("List-Unsubscribe=One-Click"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+	if got, want := rec.Result().StatusCode, http.StatusOK; got != want {
+		t.Fatalf("httpStatus=%v, want=%v", got, want)
+	}
+
+	sender.emailsSent = nil
+	announcer.AnnounceNewComment(insertComment(screenjournal.Username("userB"), parent.ID))
+
+	if got, want := len(sender.emailsSent), 0; got != want {
+		t.Errorf("emails after unsubscribing=%d, want=%d", got, want)
+	}
+}
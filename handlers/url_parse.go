@@ -88,6 +88,14 @@ func reviewIDFromQueryParams(r *http.Request) (screenjournal.ReviewID, error) {
 	return parse.ReviewIDFromString(raw)
 }
 
+func webhookIDFromRequestPath(r *http.Request) (screenjournal.WebhookID, error) {
+	return parse.WebhookID(mux.Vars(r)["webhookID"])
+}
+
+func outboxEmailIDFromRequestPath(r *http.Request) (screenjournal.OutboxEmailID, error) {
+	return parse.OutboxEmailID(mux.Vars(r)["emailID"])
+}
+
 func commentIDFromRequestPath(r *http.Request) (screenjournal.CommentID, error) {
 	return parse.CommentID(mux.Vars(r)["commentID"])
 }
@@ -101,10 +109,27 @@ func commentIDFromQueryParams(r *http.Request) (screenjournal.CommentID, error)
 	return parse.CommentID(raw)
 }
 
+func parentCommentIDFromQueryParams(r *http.Request) (screenjournal.CommentID, error) {
+	raw := r.URL.Query().Get("parentId")
+	if raw == "" {
+		return screenjournal.CommentID(0), ErrCommentIDNotProvided
+	}
+
+	return parse.CommentID(raw)
+}
+
 func usernameFromRequestPath(r *http.Request) (screenjournal.Username, error) {
 	return parse.Username(mux.Vars(r)["username"])
 }
 
+func otherUsernameFromRequestPath(r *http.Request) (screenjournal.Username, error) {
+	return parse.Username(mux.Vars(r)["otherUsername"])
+}
+
+func recapYearFromRequestPath(r *http.Request) (int, error) {
+	return parse.RecapYear(mux.Vars(r)["year"])
+}
+
 func inviteCodeFromQueryParams(r *http.Request) (screenjournal.InviteCode, error) {
 	raw := r.URL.Query().Get("invite")
 	if raw == "" {
@@ -135,3 +160,16 @@ func searchQueryFromQueryParams(r *http.Request) (screenjournal.SearchQuery, err
 
 	return parse.SearchQuery(raw)
 }
+
+func passkeyIDFromRequestPath(r *http.Request) (screenjournal.PasskeyID, error) {
+	return parse.PasskeyID(mux.Vars(r)["passkeyID"])
+}
+
+func sessionHandleFromRequestPath(r *http.Request) (screenjournal.SessionHandle, error) {
+	return parse.SessionHandle(mux.Vars(r)["sessionHandle"])
+}
+
+func loginThrottleFromRequestPath(r *http.Request) (screenjournal.LoginThrottleScope, string, error) {
+	vars := mux.Vars(r)
+	return parse.LoginThrottle(vars["scope"], vars["key"])
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
 
 	"github.com/mtlynch/screenjournal/v2/auth"
 	"github.com/mtlynch/screenjournal/v2/handlers/parse"
@@ -42,21 +45,41 @@ func (s Server) usersPut() http.HandlerFunc {
 			PasswordHash: req.PasswordHash,
 		}
 
-		if c >= 1 {
-			if _, err := s.store.ReadSignupInvitation(req.InviteCode); err != nil {
+		if c == 0 {
+			err = s.store.InsertUser(user)
+		} else {
+			var invite screenjournal.SignupInvitation
+			invite, err = s.store.ReadSignupInvitation(req.InviteCode)
+			if err != nil {
 				log.Printf("invalid invite code: %v", err)
 				http.Error(w, "Invalid invite code", http.StatusForbidden)
 				return
 			}
+			if reason := inviteUnavailableReason(invite, time.Now()); reason != "" {
+				http.Error(w, reason, http.StatusForbidden)
+				return
+			}
+			// The store checks the invite again when it adds the user in case
+			// someone else used up the invite in the meantime.
+			err = s.store.InsertInvitedUser(user, req.InviteCode, time.Now())
 		}
-
-		if err := s.store.InsertUser(user); err != nil {
-			if err == store.ErrEmailAssociatedWithAnotherAccount {
+		if err != nil {
+			switch err {
+			case store.ErrEmailAssociatedWithAnotherAccount:
 				http.Error(w, "Failed to add new user", http.StatusConflict)
 				return
-			} else if err == store.ErrUsernameNotAvailable {
+			case store.ErrUsernameNotAvailable:
 				http.Error(w, "Username is not avilable", http.StatusConflict)
 				return
+			case store.ErrInviteNotFound:
+				http.Error(w, "Invalid invite code", http.StatusForbidden)
+				return
+			case store.ErrInviteExpired:
+				http.Error(w, "This invite has expired", http.StatusForbidden)
+				return
+			case store.ErrInviteUsedUp:
+				http.Error(w, "This invite has already been used", http.StatusForbidden)
+				return
 			}
 			log.Printf("failed to add new user: %v", err)
 			http.Error(w, "Failed to add new user", http.StatusInternalServerError)
@@ -76,17 +99,18 @@ func (s Server) usersPut() http.HandlerFunc {
 			return
 		}
 
-		if !req.InviteCode.Empty() {
-			if err := s.store.DeleteSignupInvitation(req.InviteCode); err != nil {
-				log.Printf("failed to delete used signup invitation code: %v", err)
+		// The user can request another verification email from their account
+		// page, so a failure here shouldn't block sign-up.
+		if s.emailVerifier != nil {
+			if err := s.emailVerifier.SendVerification(user.Username); err != nil {
+				log.Printf("failed to send verification email to new user %s: %v", user.Username, err)
 			}
 		}
-
 	}
 }
 
 func newUserFromRequest(r *http.Request) (userPutRequest, error) {
-	username, err := usernameFromRequestPath(r)
+	username, err := parse.NewUsername(mux.Vars(r)["username"])
 	if err != nil {
 		return userPutRequest{}, err
 	}
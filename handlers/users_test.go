@@ -5,10 +5,12 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mtlynch/screenjournal/v2/auth"
 	"github.com/mtlynch/screenjournal/v2/handlers"
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
 	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
 )
 
@@ -111,6 +113,26 @@ func TestUsersPut(t *testing.T) {
 			},
 			status: http.StatusForbidden,
 		},
+		{
+			description: "rejects signup with expired invite code",
+			route:       "/api/users/someguy",
+			payload: `{
+					"email": "someguy@example.com",
+					"password": "dummyp@ss",
+					"inviteCode": "abc456"
+				}`,
+			users: []screenjournal.User{
+				userA,
+			},
+			invites: []screenjournal.SignupInvitation{
+				{
+					Invitee:    screenjournal.Invitee("Sammy"),
+					InviteCode: screenjournal.InviteCode("abc456"),
+					Expires:    time.Now().Add(-time.Hour),
+				},
+			},
+			status: http.StatusForbidden,
+		},
 		{
 			description: "rejects invalid username",
 			route:       "/api/users/q",
@@ -184,3 +206,63 @@ func TestUsersPut(t *testing.T) {
 		})
 	}
 }
+
+func TestUsersPutRedeemsInvite(t *testing.T) {
+	dataStore := test_sqlite.New()
+	if err := dataStore.InsertUser(userA); err != nil {
+		t.Fatalf("failed to insert mock user: %+v: %v", userA, err)
+	}
+	if err := dataStore.InsertSignupInvitation(screenjournal.SignupInvitation{
+		Invitee:    screenjournal.Invitee("Sammy"),
+		InviteCode: screenjournal.InviteCode("abc456"),
+		InvitedBy:  userA.Username,
+		MaxUses:    1,
+	}); err != nil {
+		t.Fatalf("failed to insert mock invite: %v", err)
+	}
+
+	sessionManager := newMockSessionManager([]mockSessionEntry{})
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	signUp := func(username string) int {
+		req, err := http.NewRequest("PUT", "/api/users/"+username, strings.NewReader(`{
+			"email": "`+username+`@example.com",
+			"password": "dummyp@ss",
+			"inviteCode": "abc456"
+		}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Add("Content-Type", "text/json")
+
+		rec := httptest.NewRecorder()
+		s.Router().ServeHTTP(rec, req)
+		return rec.Result().StatusCode
+	}
+
+	if got, want := signUp("sammy"), http.StatusOK; got != want {
+		t.Fatalf("first signup httpStatus=%v, want=%v", got, want)
+	}
+
+	invite, err := dataStore.ReadSignupInvitation(screenjournal.InviteCode("abc456"))
+	if err != nil {
+		t.Fatalf("failed to read invite: %v", err)
+	}
+	if got, want := len(invite.Redemptions), 1; got != want {
+		t.Fatalf("redemptions=%d, want=%d", got, want)
+	}
+	if got, want := invite.Redemptions[0].Username, screenjournal.Username("sammy"); got != want {
+		t.Errorf("redeemed by=%v, want=%v", got, want)
+	}
+
+	if got, want := signUp("sammysfriend"), http.StatusForbidden; got != want {
+		t.Errorf("second signup httpStatus=%v, want=%v", got, want)
+	}
+	if _, err := dataStore.ReadUser(screenjournal.Username("sammysfriend")); err != store.ErrUserNotFound {
+		t.Errorf("ReadUser err=%v, want=%v", err, store.ErrUserNotFound)
+	}
+}
@@ -17,14 +17,16 @@ import (
 	"github.com/mtlynch/screenjournal/v2/handlers/parse"
 	"github.com/mtlynch/screenjournal/v2/markdown"
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/stars"
 	"github.com/mtlynch/screenjournal/v2/store"
 )
 
 type commonProps struct {
-	IsAuthenticated  bool
-	IsAdmin          bool
-	LoggedInUsername screenjournal.Username
-	CspNonce         string
+	IsAuthenticated     bool
+	IsAdmin             bool
+	LoggedInUsername    screenjournal.Username
+	UnreadNotifications int
+	CspNonce            string
 }
 
 // reviewViewModel is a template model for displaying a review in
@@ -36,7 +38,7 @@ type reviewViewModel struct {
 	Rating    screenjournal.Rating
 	Blurb     screenjournal.Blurb
 	Watched   screenjournal.WatchDate
-	Comments  []screenjournal.ReviewComment
+	Comments  []commentThread
 	Reactions []reactionForTemplate
 	// CanEdit is true when the logged-in user is allowed to edit the review.
 	CanEdit bool
@@ -53,12 +55,72 @@ func makeReviewViewModel(
 		Rating:    r.Rating,
 		Blurb:     r.Blurb,
 		Watched:   r.Watched,
-		Comments:  r.Comments,
+		Comments:  makeCommentThreads(r.Comments, loggedInUsername, isAdminUser),
 		Reactions: convertReactionsForTemplate(r.Reactions, loggedInUsername, isAdminUser),
 		CanEdit:   r.Owner.Equal(loggedInUsername),
 	}
 }
 
+// commentThread is a comment along with its reactions and the replies to it,
+// so that templates can render a discussion as a nested tree.
+type commentThread struct {
+	Comment        screenjournal.ReviewComment
+	Reactions      []reactionForTemplate
+	UserHasReacted bool
+	Replies        []commentThread
+}
+
+func newCommentThread(
+	c screenjournal.ReviewComment,
+	loggedInUsername screenjournal.Username,
+	isAdminUser bool,
+) commentThread {
+	return commentThread{
+		Comment:        c,
+		Reactions:      convertReactionsForTemplate(c.Reactions, loggedInUsername, isAdminUser),
+		UserHasReacted: userHasReacted(c.Reactions, loggedInUsername),
+		Replies:        []commentThread{},
+	}
+}
+
+// makeCommentThreads arranges a review's comments into threads. Comments must
+// be in chronological order, and replies stay in chronological order within
+// their thread.
+func makeCommentThreads(
+	comments []screenjournal.ReviewComment,
+	loggedInUsername screenjournal.Username,
+	isAdminUser bool,
+) []commentThread {
+	ids := make(map[screenjournal.CommentID]bool, len(comments))
+	for _, c := range comments {
+		ids[c.ID] = true
+	}
+
+	roots := []screenjournal.ReviewComment{}
+	replies := map[screenjournal.CommentID][]screenjournal.ReviewComment{}
+	for _, c := range comments {
+		// Treat replies to comments we can't find as top-level comments rather
+		// than hiding them.
+		if c.ParentID.IsZero() || !ids[c.ParentID] {
+			roots = append(roots, c)
+			continue
+		}
+		replies[c.ParentID] = append(replies[c.ParentID], c)
+	}
+
+	var build func([]screenjournal.ReviewComment) []commentThread
+	build = func(cc []screenjournal.ReviewComment) []commentThread {
+		threads := make([]commentThread, len(cc))
+		for i, c := range cc {
+			threads[i] = newCommentThread(c, loggedInUsername, isAdminUser)
+			threads[i].Replies = build(replies[c.ID])
+		}
+		return threads
+	}
+
+	return build(roots)
+}
+
 func makeReviewViewModels(
 	reviews []screenjournal.Review,
 	loggedInUsername screenjournal.Username,
@@ -157,7 +219,8 @@ var moviePageFns = template.FuncMap{
 	"renderCommentText": func(comment screenjournal.CommentText) template.HTML {
 		return template.HTML(markdown.RenderComment(comment))
 	},
-	"posterPathToURL": posterPathToURL,
+	"posterPathToURL":         posterPathToURL,
+	"availableReactionEmojis": screenjournal.AllowedReactionEmojis,
 }
 
 var reviewPageFns = template.FuncMap{
@@ -211,10 +274,24 @@ func (s Server) logInGet() http.HandlerFunc {
 				templatesFS,
 				append(baseTemplates, "templates/pages/login.html")...))
 	return func(w http.ResponseWriter, r *http.Request) {
+		var singleSignOnURL string
+		if s.singleSignOn != nil {
+			singleSignOnURL = "/auth/oidc/login?" + url.Values{
+				"next": {nextPathFromQueryParams(r)},
+			}.Encode()
+		}
 		renderTemplate(w, t, "base.html", struct {
 			commonProps
+			PasskeysAvailable   bool
+			LoginLinksAvailable bool
+			SingleSignOnURL     string
+			SingleSignOnName    string
 		}{
-			commonProps: makeCommonProps(r.Context()),
+			commonProps:         makeCommonProps(r.Context()),
+			PasskeysAvailable:   s.passkeys != nil,
+			LoginLinksAvailable: s.passwordResetter != nil,
+			SingleSignOnURL:     singleSignOnURL,
+			SingleSignOnName:    s.singleSignOnName,
 		})
 	}
 }
@@ -247,6 +324,10 @@ func (s Server) signUpGet() http.HandlerFunc {
 				http.Error(w, "Invalid invite code", http.StatusUnauthorized)
 				return
 			}
+			if reason := inviteUnavailableReason(invite, time.Now()); reason != "" {
+				http.Error(w, reason, http.StatusUnauthorized)
+				return
+			}
 		}
 
 		uc, err := s.store.CountUsers()
@@ -305,8 +386,9 @@ func (s Server) reviewsGet() http.HandlerFunc {
 			}
 			return string(elidedChars)
 		},
-		"ratingToStars":   ratingToStars,
-		"posterPathToURL": posterPathToURL,
+		"ratingToStars":    ratingToStars,
+		"posterPathToURL":  posterPathToURL,
+		"compatibilityURL": compatibilityURL,
 		"splitByNewline": func(s string) []string {
 			return strings.Split(s, "\n")
 		},
@@ -317,7 +399,10 @@ func (s Server) reviewsGet() http.HandlerFunc {
 			Funcs(fns).
 			ParseFS(
 				templatesFS,
-				append(baseTemplates, "templates/pages/reviews-index.html")...))
+				append(
+					baseTemplates,
+					"templates/fragments/user-relationship.html",
+					"templates/pages/reviews-index.html")...))
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		var collectionOwner *screenjournal.Username
@@ -345,12 +430,38 @@ func (s Server) reviewsGet() http.HandlerFunc {
 			title = fmt.Sprintf("%s's %s", collectionOwner, title)
 		}
 
+		loggedInUsername := mustGetUsernameFromContext(r.Context())
+		var compatibility *screenjournal.Compatibility
+		var relationship *userRelationshipProps
+		if collectionOwner != nil && !collectionOwner.Equal(loggedInUsername) {
+			c, err := s.readCompatibility(loggedInUsername, *collectionOwner)
+			if err != nil {
+				log.Printf("failed to calculate compatibility between %s and %s: %v", loggedInUsername, *collectionOwner, err)
+				http.Error(w, "Failed to calculate compatibility", http.StatusInternalServerError)
+				return
+			}
+			compatibility = &c
+
+			rel, err := s.store.ReadUserRelationship(loggedInUsername, *collectionOwner)
+			if err != nil {
+				log.Printf("failed to read relationship of %s to %s: %v", loggedInUsername, *collectionOwner, err)
+				http.Error(w, "Failed to read relationship", http.StatusInternalServerError)
+				return
+			}
+			relationship = &userRelationshipProps{
+				Username:     *collectionOwner,
+				Relationship: rel,
+			}
+		}
+
 		renderTemplate(w, t, "base.html", struct {
 			commonProps
 			Title            string
 			Reviews          []screenjournal.Review
 			SortOrder        screenjournal.SortOrder
 			CollectionOwner  *screenjournal.Username
+			Compatibility    *screenjournal.Compatibility
+			Relationship     *userRelationshipProps
 			UserCanAddReview bool
 		}{
 			commonProps:      makeCommonProps(r.Context()),
@@ -358,7 +469,9 @@ func (s Server) reviewsGet() http.HandlerFunc {
 			Reviews:          reviews,
 			SortOrder:        sortOrder,
 			CollectionOwner:  collectionOwner,
-			UserCanAddReview: collectionOwner == nil || collectionOwner.Equal(mustGetUsernameFromContext(r.Context())),
+			Compatibility:    compatibility,
+			Relationship:     relationship,
+			UserCanAddReview: collectionOwner == nil || collectionOwner.Equal(loggedInUsername),
 		})
 	}
 }
@@ -418,6 +531,13 @@ func (s Server) moviesReadGet() http.HandlerFunc {
 		// Convert reviews to view models for templates.
 		reviewsForTemplate := makeReviewViewModels(reviews, loggedInUsername, isAdminUser)
 
+		recipients, err := s.readFriendRecommendationRecipients(loggedInUsername)
+		if err != nil {
+			log.Printf("failed to read users: %v", err)
+			http.Error(w, "Failed to retrieve users", http.StatusInternalServerError)
+			return
+		}
+
 		type mediaStub struct {
 			IsTvShow     bool
 			Type         screenjournal.MediaType
@@ -434,6 +554,7 @@ func (s Server) moviesReadGet() http.HandlerFunc {
 			Media           mediaStub
 			Reviews         []reviewViewModel
 			AvailableEmojis []screenjournal.ReactionEmoji
+			Recipients      []screenjournal.Username
 		}{
 			commonProps: makeCommonProps(r.Context()),
 			Media: mediaStub{
@@ -448,6 +569,7 @@ func (s Server) moviesReadGet() http.HandlerFunc {
 			},
 			Reviews:         reviewsForTemplate,
 			AvailableEmojis: screenjournal.AllowedReactionEmojis(),
+			Recipients:      recipients,
 		})
 	}
 }
@@ -514,6 +636,13 @@ func (s Server) tvShowsReadGet() http.HandlerFunc {
 		// Convert reviews to view models for templates.
 		reviewsForTemplate := makeReviewViewModels(reviews, loggedInUsername, isAdminUser)
 
+		recipients, err := s.readFriendRecommendationRecipients(loggedInUsername)
+		if err != nil {
+			log.Printf("failed to read users: %v", err)
+			http.Error(w, "Failed to retrieve users", http.StatusInternalServerError)
+			return
+		}
+
 		type mediaStub struct {
 			Type         screenjournal.MediaType
 			IsTvShow     bool
@@ -531,6 +660,7 @@ func (s Server) tvShowsReadGet() http.HandlerFunc {
 			Media           mediaStub
 			Reviews         []reviewViewModel
 			AvailableEmojis []screenjournal.ReactionEmoji
+			Recipients      []screenjournal.Username
 		}{
 			commonProps: makeCommonProps(r.Context()),
 			Media: mediaStub{
@@ -546,6 +676,7 @@ func (s Server) tvShowsReadGet() http.HandlerFunc {
 			},
 			Reviews:         reviewsForTemplate,
 			AvailableEmojis: screenjournal.AllowedReactionEmojis(),
+			Recipients:      recipients,
 		})
 	}
 }
@@ -831,12 +962,15 @@ func (s Server) getTvShow(r *http.Request, tvShowID *screenjournal.TvShowID, tmd
 
 func (s Server) invitesGet() http.HandlerFunc {
 	t := template.Must(
-		template.New("base.html").ParseFS(
-			templatesFS,
-			append(
-				baseTemplates,
-				"templates/fragments/invite-row.html",
-				"templates/pages/invites.html")...))
+		template.New("base.html").
+			Funcs(inviteRowFns).
+			ParseFS(
+				templatesFS,
+				append(
+					baseTemplates,
+					"templates/fragments/invite-row.html",
+					"templates/fragments/invite-form.html",
+					"templates/pages/invites.html")...))
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		invites, err := s.store.ReadSignupInvitations()
@@ -845,12 +979,31 @@ func (s Server) invitesGet() http.HandlerFunc {
 			http.Error(w, "Failed to read signup invitations", http.StatusInternalServerError)
 			return
 		}
+
+		policy, err := s.store.ReadMemberInvitePolicy()
+		if err != nil {
+			log.Printf("failed to read member invite policy: %v", err)
+			http.Error(w, "Failed to read invite settings", http.StatusInternalServerError)
+			return
+		}
+
 		renderTemplate(w, t, "base.html", struct {
 			commonProps
-			Invites []screenjournal.SignupInvitation
+			Form                 inviteFormProps
+			Invites              []inviteRowProps
+			MemberPolicy         screenjournal.MemberInvitePolicy
+			MaxMemberInviteLimit int
 		}{
 			commonProps: makeCommonProps(r.Context()),
-			Invites:     invites,
+			Form: inviteFormProps{
+				Action:         "/admin/invites",
+				EmailAvailable: s.inviteSender != nil,
+				CanSetLimits:   true,
+				MaxUses:        screenjournal.MaxInviteUses,
+			},
+			Invites:              makeInviteRows(invites, true, time.Now()),
+			MemberPolicy:         policy,
+			MaxMemberInviteLimit: screenjournal.MaxMemberInviteLimit,
 		})
 	}
 }
@@ -944,26 +1097,80 @@ func (s Server) accountNotificationsGet() http.HandlerFunc {
 		renderTemplate(w, t, "base.html", struct {
 			commonProps
 			ReceivesReviewNotices     bool
+			NewReviewsScope           string
 			ReceivesAllCommentNotices bool
+			ReceivesReplyNotices      bool
+			ReceivesMentionNotices    bool
+			Digest                    string
 		}{
 			commonProps:               makeCommonProps(r.Context()),
 			ReceivesReviewNotices:     prefs.NewReviews,
+			NewReviewsScope:           prefs.NewReviewsScope.String(),
 			ReceivesAllCommentNotices: prefs.AllNewComments,
+			ReceivesReplyNotices:      prefs.Replies,
+			ReceivesMentionNotices:    prefs.Mentions,
+			Digest:                    prefs.Digest.String(),
 		})
 	}
 }
 
 func (s Server) accountSecurityGet() http.HandlerFunc {
 	t := template.Must(
-		template.New("base.html").ParseFS(
-			templatesFS,
-			append(baseTemplates, "templates/pages/account-security.html")...))
+		template.New("base.html").
+			Funcs(reviewPageFns).
+			ParseFS(
+				templatesFS,
+				append(baseTemplates,
+					"templates/fragments/two-factor-status.html",
+					"templates/fragments/passkeys-list.html",
+					"templates/fragments/sessions-list.html",
+					"templates/fragments/delete-account-form.html",
+					"templates/pages/account-security.html")...))
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		username := mustGetUsernameFromContext(r.Context())
+		twoFactor, err := s.readTwoFactorStatus(username)
+		if err != nil {
+			log.Printf("failed to read two-factor status for user %s: %v", username, err)
+			http.Error(w, "Failed to read two-factor status", http.StatusInternalServerError)
+			return
+		}
+
+		passkeys, err := s.readPasskeys(username)
+		if err != nil {
+			log.Printf("failed to read passkeys for user %s: %v", username, err)
+			http.Error(w, "Failed to read passkeys", http.StatusInternalServerError)
+			return
+		}
+
+		sessions, err := s.readSessions(r, username)
+		if err != nil {
+			log.Printf("failed to read sessions for user %s: %v", username, err)
+			http.Error(w, "Failed to read sessions", http.StatusInternalServerError)
+			return
+		}
+
+		emailStatus, err := s.readEmailStatus(username)
+		if err != nil {
+			log.Printf("failed to read email status for user %s: %v", username, err)
+			http.Error(w, "Failed to read email address", http.StatusInternalServerError)
+			return
+		}
+
 		renderTemplate(w, t, "base.html", struct {
 			commonProps
+			Email         emailStatusProps
+			TwoFactor     twoFactorStatusProps
+			Passkeys      passkeysProps
+			Sessions      sessionsProps
+			DeleteAccount deleteAccountFormProps
 		}{
-			commonProps: makeCommonProps(r.Context()),
+			commonProps:   makeCommonProps(r.Context()),
+			Email:         emailStatus,
+			TwoFactor:     twoFactor,
+			Passkeys:      passkeys,
+			Sessions:      sessions,
+			DeleteAccount: deleteAccountFormProps{Action: "/account/delete"},
 		})
 	}
 }
@@ -972,6 +1179,7 @@ func (s Server) usersGet() http.HandlerFunc {
 	t := template.Must(
 		template.New("base.html").
 			Funcs(reviewPageFns).
+			Funcs(compatibilityFns).
 			ParseFS(
 				templatesFS,
 				append(baseTemplates, "templates/pages/users.html")...))
@@ -983,36 +1191,79 @@ func (s Server) usersGet() http.HandlerFunc {
 			return
 		}
 
+		reviews, err := s.store.ReadReviews()
+		if err != nil {
+			log.Printf("failed to read reviews: %v", err)
+			http.Error(w, "Failed to read reviews", http.StatusInternalServerError)
+			return
+		}
+
+		usernames := make([]screenjournal.Username, len(users))
+		for i, u := range users {
+			usernames[i] = u.Username
+		}
+
+		var sortBy screenjournal.Username
+		if raw := r.URL.Query().Get("sortBy"); raw != "" {
+			sortBy, err = parse.Username(raw)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid sort: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+
+		relationships, err := s.store.ReadUserRelationships(mustGetUsernameFromContext(r.Context()))
+		if err != nil {
+			log.Printf("failed to read user relationships: %v", err)
+			http.Error(w, "Failed to read user relationships", http.StatusInternalServerError)
+			return
+		}
+
+		var invitesURL string
+		if isAdmin(r.Context()) {
+			invitesURL = "/admin/invites"
+		} else {
+			policy, err := s.store.ReadMemberInvitePolicy()
+			if err != nil {
+				log.Printf("failed to read member invite policy: %v", err)
+				http.Error(w, "Failed to read invite settings", http.StatusInternalServerError)
+				return
+			}
+			if policy.Enabled {
+				invitesURL = "/invites"
+			}
+		}
+
 		renderTemplate(w, t, "base.html", struct {
 			commonProps
-			Users []screenjournal.UserPublicMeta
+			Users               []screenjournal.UserPublicMeta
+			Relationships       map[screenjournal.Username]screenjournal.UserRelationship
+			CompatibilityMatrix compatibilityMatrix
+			// InvitesURL is empty if the user can't create invites.
+			InvitesURL string
 		}{
-			commonProps: makeCommonProps(r.Context()),
-			Users:       users,
+			commonProps:         makeCommonProps(r.Context()),
+			Users:               users,
+			Relationships:       relationships,
+			CompatibilityMatrix: buildCompatibilityMatrix(usernames, reviews, sortBy),
+			InvitesURL:          invitesURL,
 		})
 	}
 }
 
 func ratingToStars(rating screenjournal.Rating) []string {
-	if rating.IsNil() {
-		return []string{}
-	}
-
-	ratingVal := rating.UInt8()
-	stars := make([]string, parse.MaxRating/2)
-	// Add whole stars.
-	for i := uint8(0); i < ratingVal/2; i++ {
-		stars = append(stars, "fa-solid fa-star")
-	}
-	if ratingVal%2 != 0 {
-		stars = append(stars, "fa-solid fa-star-half-stroke")
-	}
-	// Add empty stars.
-	emptyStars := (parse.MaxRating / 2) - (ratingVal / 2) - ratingVal%2
-	for range emptyStars {
-		stars = append(stars, "fa-regular fa-star")
+	classes := []string{}
+	for _, s := range stars.FromRating(rating) {
+		switch s {
+		case stars.Full:
+			classes = append(classes, "fa-solid fa-star")
+		case stars.Half:
+			classes = append(classes, "fa-solid fa-star-half-stroke")
+		case stars.Empty:
+			classes = append(classes, "fa-regular fa-star")
+		}
 	}
-	return stars
+	return classes
 }
 
 func relativeWatchDate(t screenjournal.WatchDate) string {
@@ -1103,9 +1354,10 @@ func makeCommonProps(ctx context.Context) commonProps {
 		username = screenjournal.Username("")
 	}
 	return commonProps{
-		IsAuthenticated:  isAuthenticated(ctx),
-		IsAdmin:          isAdmin(ctx),
-		LoggedInUsername: username,
-		CspNonce:         cspNonce(ctx),
+		IsAuthenticated:     isAuthenticated(ctx),
+		IsAdmin:             isAdmin(ctx),
+		LoggedInUsername:    username,
+		UnreadNotifications: unreadNotificationCount(ctx),
+		CspNonce:            cspNonce(ctx),
 	}
 }
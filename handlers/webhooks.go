@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+type webhooksPostRequest struct {
+	URL screenjournal.WebhookURL
+}
+
+func (s Server) webhooksGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").ParseFS(
+			templatesFS,
+			append(
+				baseTemplates,
+				"templates/fragments/webhook-row.html",
+				"templates/pages/webhooks.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhooks, err := s.store.ReadWebhooks()
+		if err != nil {
+			log.Printf("failed to read webhooks: %v", err)
+			http.Error(w, "Failed to read webhooks", http.StatusInternalServerError)
+			return
+		}
+		renderTemplate(w, t, "base.html", struct {
+			commonProps
+			Webhooks []screenjournal.Webhook
+		}{
+			commonProps: makeCommonProps(r.Context()),
+			Webhooks:    webhooks,
+		})
+	}
+}
+
+func (s Server) webhooksPost() http.HandlerFunc {
+	t := template.Must(template.ParseFS(templatesFS, "templates/fragments/webhook-row.html"))
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := parseWebhooksPostRequest(r)
+		if err != nil {
+			log.Printf("failed to parse webhooks POST: %v", err)
+			http.Error(w, fmt.Sprintf("Invalid webhook: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		wh := screenjournal.Webhook{
+			URL:    req.URL,
+			Secret: screenjournal.NewWebhookSecret(),
+		}
+		wh.ID, err = s.store.InsertWebhook(wh)
+		if err != nil {
+			log.Printf("failed to add new webhook: %v", err)
+			http.Error(w, "Failed to store new webhook", http.StatusInternalServerError)
+			return
+		}
+
+		renderTemplate(w, t, "webhook-row.html", wh)
+	}
+}
+
+func (s Server) webhooksDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := webhookIDFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := s.store.ReadWebhook(id); err != nil {
+			if errors.Is(err, store.ErrWebhookNotFound) {
+				http.Error(w, "Webhook not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("failed to read webhook %v: %v", id, err)
+			http.Error(w, "Failed to read webhook", http.StatusInternalServerError)
+			return
+		}
+
+		if err := s.store.DeleteWebhook(id); err != nil {
+			log.Printf("failed to delete webhook %v: %v", id, err)
+			http.Error(w, "Failed to delete webhook", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (s Server) webhookDeliveriesGet() http.HandlerFunc {
+	t := template.Must(
+		template.New("base.html").
+			Funcs(template.FuncMap{
+				"formatTime": formatIso8601Datetime,
+			}).
+			ParseFS(
+				templatesFS,
+				append(baseTemplates, "templates/pages/webhook-deliveries.html")...))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := webhookIDFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+			return
+		}
+
+		wh, err := s.store.ReadWebhook(id)
+		if err != nil {
+			if errors.Is(err, store.ErrWebhookNotFound) {
+				http.Error(w, "Webhook not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("failed to read webhook %v: %v", id, err)
+			http.Error(w, "Failed to read webhook", http.StatusInternalServerError)
+			return
+		}
+
+		deliveries, err := s.store.ReadWebhookDeliveries(id)
+		if err != nil {
+			log.Printf("failed to read deliveries for webhook %v: %v", id, err)
+			http.Error(w, "Failed to read webhook deliveries", http.StatusInternalServerError)
+			return
+		}
+
+		renderTemplate(w, t, "base.html", struct {
+			commonProps
+			Webhook    screenjournal.Webhook
+			Deliveries []screenjournal.WebhookDelivery
+		}{
+			commonProps: makeCommonProps(r.Context()),
+			Webhook:     wh,
+			Deliveries:  deliveries,
+		})
+	}
+}
+
+func parseWebhooksPostRequest(r *http.Request) (webhooksPostRequest, error) {
+	if err := r.ParseForm(); err != nil {
+		return webhooksPostRequest{}, err
+	}
+
+	url, err := parse.WebhookURL(r.PostFormValue("url"))
+	if err != nil {
+		return webhooksPostRequest{}, err
+	}
+
+	return webhooksPostRequest{
+		URL: url,
+	}, nil
+}
@@ -0,0 +1,165 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestWebhooksPost(t *testing.T) {
+	for _, tt := range []struct {
+		description  string
+		payload      string
+		sessionToken string
+		status       int
+		expectedURL  screenjournal.WebhookURL
+	}{
+		{
+			description:  "creates a new webhook",
+			payload:      "url=https%3A%2F%2Fchat.example.com%2Fhooks%2Fabc",
+			sessionToken: makeInvitesTestData().sessions.adminUser.token,
+			status:       http.StatusOK,
+			expectedURL:  screenjournal.WebhookURL("https://chat.example.com/hooks/abc"),
+		},
+		{
+			description:  "rejects a webhook with an invalid URL",
+			payload:      "url=chat.example.com",
+			sessionToken: makeInvitesTestData().sessions.adminUser.token,
+			status:       http.StatusBadRequest,
+		},
+		{
+			description:  "rejects webhook creation if user is not an admin",
+			payload:      "url=https%3A%2F%2Fchat.example.com%2Fhooks%2Fabc",
+			sessionToken: makeInvitesTestData().sessions.regularUser.token,
+			status:       http.StatusForbidden,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := test_sqlite.New()
+			sessions := []mockSessionEntry{
+				makeInvitesTestData().sessions.adminUser,
+				makeInvitesTestData().sessions.regularUser,
+			}
+			insertMockUsersForSessions(t, dataStore, sessions, screenjournal.Username("admin"))
+
+			sessionManager := newMockSessionManager(sessions)
+			s := handlers.New(handlers.ServerParams{
+				Authenticator:  auth.New(dataStore),
+				SessionManager: &sessionManager,
+				Store:          dataStore,
+			})
+
+			req, err := http.NewRequest("POST", "/admin/webhooks", strings.NewReader(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: tt.sessionToken,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+
+			if got, want := rec.Result().StatusCode, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+
+			webhooks, err := dataStore.ReadWebhooks()
+			if err != nil {
+				t.Fatalf("failed to read webhooks from datastore: %v", err)
+			}
+
+			if tt.status != http.StatusOK {
+				if got, want := len(webhooks), 0; got != want {
+					t.Errorf("webhooks=%d, want=%d", got, want)
+				}
+				return
+			}
+
+			if got, want := len(webhooks), 1; got != want {
+				t.Fatalf("webhooks=%d, want=%d", got, want)
+			}
+			if got, want := webhooks[0].URL, tt.expectedURL; got != want {
+				t.Errorf("url=%v, want=%v", got, want)
+			}
+			if webhooks[0].Secret.String() == "" {
+				t.Errorf("webhook has no secret")
+			}
+		})
+	}
+}
+
+func TestWebhookDeliveriesGet(t *testing.T) {
+	dataStore := test_sqlite.New()
+	sessions := []mockSessionEntry{
+		makeInvitesTestData().sessions.adminUser,
+	}
+	insertMockUsersForSessions(t, dataStore, sessions, screenjournal.Username("admin"))
+
+	webhookID, err := dataStore.InsertWebhook(screenjournal.Webhook{
+		URL:    screenjournal.WebhookURL("https://chat.example.com/hooks/abc"),
+		Secret: screenjournal.NewWebhookSecret(),
+	})
+	if err != nil {
+		t.Fatalf("failed to insert webhook: %v", err)
+	}
+	webhook, err := dataStore.ReadWebhook(webhookID)
+	if err != nil {
+		t.Fatalf("failed to read webhook: %v", err)
+	}
+	if _, err := dataStore.InsertWebhookDelivery(screenjournal.WebhookDelivery{
+		Webhook: webhook,
+		Event:   screenjournal.WebhookEventNewReview,
+		Payload: []byte(`{}`),
+	}); err != nil {
+		t.Fatalf("failed to insert webhook delivery: %v", err)
+	}
+
+	sessionManager := newMockSessionManager(sessions)
+	s := handlers.New(handlers.ServerParams{
+		Authenticator:  auth.New(dataStore),
+		SessionManager: &sessionManager,
+		Store:          dataStore,
+	})
+
+	for _, tt := range []struct {
+		route  string
+		status int
+	}{
+		{"/admin/webhooks/1", http.StatusOK},
+		{"/admin/webhooks/2", http.StatusNotFound},
+		{"/admin/webhooks/banana", http.StatusBadRequest},
+	} {
+		t.Run(tt.route, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.route, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.AddCookie(&http.Cookie{
+				Name:  mockSessionTokenName,
+				Value: makeInvitesTestData().sessions.adminUser.token,
+			})
+
+			rec := httptest.NewRecorder()
+			s.Router().ServeHTTP(rec, req)
+
+			if got, want := rec.Result().StatusCode, tt.status; got != want {
+				t.Fatalf("httpStatus=%v, want=%v", got, want)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if !strings.Contains(rec.Body.String(), "review.created") {
+				t.Errorf("delivery log doesn't show queued delivery")
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"log"
+	"net/mail"
+	"text/template"
+
+	"github.com/mtlynch/screenjournal/v2/email"
+	"github.com/mtlynch/screenjournal/v2/markdown"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type Sender struct {
+	baseURL string
+	sender  email.Sender
+}
+
+func New(baseURL string, sender email.Sender) Sender {
+	return Sender{
+		baseURL: baseURL,
+		sender:  sender,
+	}
+}
+
+//go:embed templates
+var templatesFS embed.FS
+
+var invitationTemplate = template.Must(
+	template.New("invitation.tmpl.txt").
+		ParseFS(templatesFS, "templates/invitation.tmpl.txt"))
+
+// SendInvitation emails the invite's sign-up link to the invite's email
+// address.
+func (s Sender) SendInvitation(invite screenjournal.SignupInvitation) error {
+	signUpURL := fmt.Sprintf("%s/sign-up?invite=%s", s.baseURL, invite.InviteCode)
+
+	var expires string
+	if !invite.Expires.IsZero() {
+		expires = invite.Expires.UTC().Format("January 2, 2006")
+	}
+
+	var bodyBuf bytes.Buffer
+	if err := invitationTemplate.Execute(&bodyBuf, struct {
+		Invitee   string
+		InvitedBy string
+		SignUpURL string
+		Expires   string
+	}{
+		Invitee:   invite.Invitee.String(),
+		InvitedBy: invite.InvitedBy.String(),
+		SignUpURL: signUpURL,
+		Expires:   expires,
+	}); err != nil {
+		return fmt.Errorf("rendering invitation email: %w", err)
+	}
+
+	subject := "You're invited to ScreenJournal"
+	if !invite.InvitedBy.Empty() {
+		subject = fmt.Sprintf("%s invited you to ScreenJournal", invite.InvitedBy)
+	}
+
+	bodyMarkdown := screenjournal.EmailBodyMarkdown(bodyBuf.String())
+	msg := email.Message{
+		From: mail.Address{
+			Name:    "ScreenJournal",
+			Address: "accounts@thescreenjournal.com",
+		},
+		To: []mail.Address{
+			{
+				Name:    invite.Invitee.String(),
+				Address: invite.Email.String(),
+			},
+		},
+		Subject:        subject,
+		TextBody:       bodyMarkdown.String(),
+		HtmlBody:       markdown.RenderEmail(bodyMarkdown),
+		IdempotencyKey: "invite/" + invite.InviteCode.String(),
+	}
+
+	if err := s.sender.Send(msg); err != nil {
+		return fmt.Errorf("sending invitation to %s: %w", invite.Invitee, err)
+	}
+
+	log.Printf("sent invitation email to %s", invite.Invitee)
+	return nil
+}
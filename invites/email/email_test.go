@@ -0,0 +1,105 @@
+package email_test
+
+import (
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/diff"
+
+	"github.com/mtlynch/screenjournal/v2/email"
+	invites_email "github.com/mtlynch/screenjournal/v2/invites/email"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type mockEmailSender struct {
+	emailsSent []email.Message
+}
+
+func (s *mockEmailSender) Send(msg email.Message) error {
+	s.emailsSent = append(s.emailsSent, msg)
+	return nil
+}
+
+func TestSendInvitation(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		invite      screenjournal.SignupInvitation
+		subject     string
+		body        string
+	}{
+		{
+			description: "sends an expiring invite from a member",
+			invite: screenjournal.SignupInvitation{
+				Invitee:    screenjournal.Invitee("Frank Smith"),
+				InviteCode: screenjournal.InviteCode("abc456"),
+				Email:      screenjournal.Email("frank@example.com"),
+				InvitedBy:  screenjournal.Username("alice"),
+				Expires:    time.Date(2024, 1, 16, 12, 0, 0, 0, time.UTC),
+				MaxUses:    1,
+			},
+			subject: "alice invited you to ScreenJournal",
+			body: `Hi Frank Smith,
+
+alice invited you to join ScreenJournal, a place to share ratings and reviews of movies and TV shows with friends.
+
+Click the link below to create your account:
+
+https://dev.thescreenjournal.com/sign-up?invite=abc456
+
+This invite expires on January 16, 2024.
+
+If you don't know who sent this, you can ignore this email.
+
+-ScreenJournal Bot
+`,
+		},
+		{
+			description: "sends an invite that never expires and has no inviter",
+			invite: screenjournal.SignupInvitation{
+				Invitee:    screenjournal.Invitee("Frank Smith"),
+				InviteCode: screenjournal.InviteCode("abc456"),
+				Email:      screenjournal.Email("frank@example.com"),
+				MaxUses:    1,
+			},
+			subject: "You're invited to ScreenJournal",
+			body: `Hi Frank Smith,
+
+You're invited to join ScreenJournal, a place to share ratings and reviews of movies and TV shows with friends.
+
+Click the link below to create your account:
+
+https://dev.thescreenjournal.com/sign-up?invite=abc456
+
+This invite doesn't expire.
+
+If you don't know who sent this, you can ignore this email.
+
+-ScreenJournal Bot
+`,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			sender := &mockEmailSender{}
+			s := invites_email.New("https://dev.thescreenjournal.com", sender)
+
+			if err := s.SendInvitation(tt.invite); err != nil {
+				t.Fatalf("err=%v, want=%v", err, nil)
+			}
+
+			if got, want := len(sender.emailsSent), 1; got != want {
+				t.Fatalf("email count=%d, want=%d", got, want)
+			}
+			msg := sender.emailsSent[0]
+			if got, want := msg.Subject, tt.subject; got != want {
+				t.Errorf("subject=%s, want=%s", got, want)
+			}
+			if got, want := msg.To, []mail.Address{{Name: "Frank Smith", Address: "frank@example.com"}}; len(got) != 1 || got[0] != want[0] {
+				t.Errorf("to=%v, want=%v", got, want)
+			}
+			if d := diff.Diff(tt.body, msg.TextBody); d != "" {
+				t.Errorf("email (plaintext): %s", d)
+			}
+		})
+	}
+}
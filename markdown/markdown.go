@@ -1,10 +1,14 @@
 package markdown
 
 import (
+	"bytes"
 	"html"
+	"regexp"
+	"slices"
 	"strings"
 
 	gomarkdown "github.com/gomarkdown/markdown"
+	gomarkdown_ast "github.com/gomarkdown/markdown/ast"
 	gomarkdown_html "github.com/gomarkdown/markdown/html"
 	gomarkdown_parser "github.com/gomarkdown/markdown/parser"
 	"github.com/microcosm-cc/bluemonday"
@@ -14,9 +18,20 @@ import (
 
 const SpoilersKeyword = "!spoilers"
 
+const (
+	// mentionClass is the CSS class of links generated from @mentions.
+	mentionClass = "mention"
+
+	userReviewsPathPrefix = "/reviews/by/"
+)
+
 var (
 	untrustedRenderer *gomarkdown_html.Renderer
 	trustedRenderer   *gomarkdown_html.Renderer
+
+	// mentionPattern matches an @mention at the start of its input. The
+	// username portion uses the same characters that are valid in usernames.
+	mentionPattern = regexp.MustCompile(`^@([a-zA-Z0-9\.]{2,80})`)
 )
 
 func init() {
@@ -30,7 +45,19 @@ func RenderBlurb(blurb screenjournal.Blurb) string {
 
 func RenderBlurbAsPlaintext(blurb screenjournal.Blurb) string {
 	unspoiled, _, _ := splitSpoilers(blurb.String())
-	asHtml := renderUntrusted(unspoiled)
+	return renderPlaintext(unspoiled)
+}
+
+func RenderComment(comment screenjournal.CommentText) string {
+	return renderUntrusted(comment.String())
+}
+
+func RenderCommentAsPlaintext(comment screenjournal.CommentText) string {
+	return renderPlaintext(comment.String())
+}
+
+func renderPlaintext(s string) string {
+	asHtml := renderUntrusted(s)
 	plaintext := bluemonday.StrictPolicy().Sanitize(asHtml)
 
 	// Decode HTML entities like ' back to characters
@@ -39,13 +66,84 @@ func RenderBlurbAsPlaintext(blurb screenjournal.Blurb) string {
 	return strings.TrimSpace(plaintext)
 }
 
-func RenderComment(comment screenjournal.CommentText) string {
-	return renderUntrusted(comment.String())
+// BlurbMentions returns the users mentioned in a blurb, in the order they
+// first appear.
+func BlurbMentions(blurb screenjournal.Blurb) []screenjournal.Username {
+	return findMentions(blurb.String())
+}
+
+// CommentMentions returns the users mentioned in a comment, in the order they
+// first appear.
+func CommentMentions(comment screenjournal.CommentText) []screenjournal.Username {
+	return findMentions(comment.String())
+}
+
+func findMentions(s string) []screenjournal.Username {
+	doc := newUntrustedParser().Parse([]byte(trimSpacesFromEachLine(s)))
+
+	mentions := []screenjournal.Username{}
+	gomarkdown_ast.WalkFunc(doc, func(node gomarkdown_ast.Node, entering bool) gomarkdown_ast.WalkStatus {
+		link, ok := node.(*gomarkdown_ast.Link)
+		if !entering || !ok || !isMentionLink(link) {
+			return gomarkdown_ast.GoToNext
+		}
+		username := screenjournal.Username(bytes.TrimPrefix(link.Destination, []byte(userReviewsPathPrefix)))
+		if !slices.Contains(mentions, username) {
+			mentions = append(mentions, username)
+		}
+		return gomarkdown_ast.GoToNext
+	})
+
+	return mentions
+}
+
+func newUntrustedParser() *gomarkdown_parser.Parser {
+	parser := gomarkdown_parser.NewWithExtensions(gomarkdown_parser.NoExtensions)
+	parser.RegisterInline('@', parseMention)
+	return parser
+}
+
+// parseMention turns @username into a link to the user's reviews.
+func parseMention(_ *gomarkdown_parser.Parser, data []byte, offset int) (int, gomarkdown_ast.Node) {
+	// Ignore @ signs in the middle of a word, like in email addresses.
+	if offset > 0 && isUsernameByte(data[offset-1]) {
+		return 0, nil
+	}
+
+	match := mentionPattern.FindSubmatch(data[offset:])
+	if match == nil {
+		return 0, nil
+	}
+
+	// Periods are valid in usernames, but a trailing period is more likely the
+	// end of a sentence.
+	username := strings.TrimRight(string(match[1]), ".")
+	if len(username) < 2 {
+		return 0, nil
+	}
+
+	link := &gomarkdown_ast.Link{
+		Destination:          []byte(userReviewsPathPrefix + username),
+		AdditionalAttributes: []string{`class="` + mentionClass + `"`},
+	}
+	gomarkdown_ast.AppendChild(link, &gomarkdown_ast.Text{
+		Leaf: gomarkdown_ast.Leaf{Literal: []byte("@" + username)},
+	})
+
+	return len(username) + 1, link
+}
+
+func isMentionLink(link *gomarkdown_ast.Link) bool {
+	return slices.Contains(link.AdditionalAttributes, `class="`+mentionClass+`"`)
+}
+
+func isUsernameByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || b == '.'
 }
 
 func renderUntrusted(s string) string {
 	renderMarkdown := func(markdown string) string {
-		parser := gomarkdown_parser.NewWithExtensions(gomarkdown_parser.NoExtensions)
+		parser := newUntrustedParser()
 		trimmed := trimSpacesFromEachLine(markdown)
 		asHtml := string(gomarkdown.ToHTML([]byte(trimmed), parser, untrustedRenderer))
 		return strings.TrimSpace(asHtml)
@@ -1,6 +1,7 @@
 package markdown_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/kylelemons/godebug/diff"
@@ -104,6 +105,31 @@ The butler did it!`,
 
 <p>Frank: I love pistachios...</p>`,
 		},
+		{
+			"links @mentions to the user's reviews",
+			"@userA you have to see this",
+			`<p><a class="mention" href="/reviews/by/userA">@userA</a> you have to see this</p>`,
+		},
+		{
+			"does not include a trailing period in a mention",
+			"I agree with @jo.smith.",
+			`<p>I agree with <a class="mention" href="/reviews/by/jo.smith">@jo.smith</a>.</p>`,
+		},
+		{
+			"ignores @ signs in email addresses",
+			"email me at mike@example.com",
+			"<p>email me at mike@example.com</p>",
+		},
+		{
+			"ignores @ signs without a valid username",
+			"meet @ 8pm or @x",
+			"<p>meet @ 8pm or @x</p>",
+		},
+		{
+			"does not link mentions in code spans",
+			"try `@userA`",
+			"<p>try <code>@userA</code></p>",
+		},
 	} {
 		t.Run(tt.description, func(t *testing.T) {
 			if got, want := markdown.RenderBlurb(screenjournal.Blurb(tt.in)), tt.out; got != want {
@@ -115,6 +141,45 @@ The butler did it!`,
 		})
 	}
 }
+
+func TestMentions(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		in          string
+		mentions    []screenjournal.Username
+	}{
+		{
+			"finds no mentions in plain text",
+			"hello, world!",
+			[]screenjournal.Username{},
+		},
+		{
+			"finds mentions in order of first appearance without duplicates",
+			"@userB and @userA, but mostly @userB",
+			[]screenjournal.Username{"userB", "userA"},
+		},
+		{
+			"finds mentions in spoilers",
+			"Great movie\n\n!spoilers\n\n@userA was right about the ending.",
+			[]screenjournal.Username{"userA"},
+		},
+		{
+			"ignores email addresses and code spans",
+			"mike@example.com said `@userA`",
+			[]screenjournal.Username{},
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			if got, want := markdown.BlurbMentions(screenjournal.Blurb(tt.in)), tt.mentions; !reflect.DeepEqual(got, want) {
+				t.Errorf("blurb mentions=%v, want=%v", got, want)
+			}
+			if got, want := markdown.CommentMentions(screenjournal.CommentText(tt.in)), tt.mentions; !reflect.DeepEqual(got, want) {
+				t.Errorf("comment mentions=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
 func TestRenderBlurbAsPlaintext(t *testing.T) {
 	for _, tt := range []struct {
 		description string
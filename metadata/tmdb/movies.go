@@ -3,6 +3,7 @@ package tmdb
 import (
 	"log"
 	"net/url"
+	"time"
 
 	"github.com/mtlynch/screenjournal/v2/handlers/parse"
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
@@ -50,5 +51,9 @@ func (f Finder) GetMovie(id screenjournal.TmdbID) (screenjournal.Movie, error) {
 		}
 	}
 
+	if m.Runtime > 0 {
+		info.Runtime = time.Duration(m.Runtime) * time.Minute
+	}
+
 	return info, nil
 }
@@ -18,6 +18,7 @@ type MovieResponse struct {
 	ImdbID      string `json:"imdb_id"`
 	ReleaseDate string `json:"release_date"`
 	PosterPath  string `json:"poster_path"`
+	Runtime     int    `json:"runtime"`
 }
 
 type TvResponse struct {
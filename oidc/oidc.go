@@ -0,0 +1,248 @@
+// Package oidc implements the parts of OpenID Connect that ScreenJournal needs
+// to log users in with an external identity provider: discovery, the
+// authorization code flow with PKCE, and ID token verification.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxResponseBytes limits how much we read from the identity provider.
+const maxResponseBytes = 1 << 20
+
+var (
+	ErrInvalidToken = errors.New("invalid ID token")
+	ErrTokenRequest = errors.New("identity provider rejected the token request")
+)
+
+type (
+	Config struct {
+		// IssuerURL is the identity provider's issuer identifier, which is also
+		// the base URL for discovery.
+		IssuerURL    string
+		ClientID     string
+		ClientSecret string
+		// RedirectURL is ScreenJournal's callback URL, registered with the
+		// identity provider.
+		RedirectURL string
+		// GroupsClaim is the name of the ID token claim that lists the user's
+		// groups.
+		GroupsClaim string
+	}
+
+	// AuthRequest holds the secrets for one login attempt. The caller keeps
+	// them between redirecting the user to the identity provider and handling
+	// the callback.
+	AuthRequest struct {
+		State        string
+		Nonce        string
+		CodeVerifier string
+	}
+
+	// Claims are the verified claims about the user from an ID token.
+	Claims struct {
+		Issuer            string
+		Subject           string
+		Email             string
+		EmailVerified     bool
+		PreferredUsername string
+		Groups            []string
+	}
+
+	Provider struct {
+		config     Config
+		httpClient *http.Client
+		now        func() time.Time
+
+		mu       sync.Mutex
+		metadata *providerMetadata
+		keys     map[string]any
+	}
+
+	providerMetadata struct {
+		Issuer                        string   `json:"issuer"`
+		AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+		TokenEndpoint                 string   `json:"token_endpoint"`
+		JwksURI                       string   `json:"jwks_uri"`
+		CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+	}
+)
+
+// New creates a provider for the given configuration. It doesn't contact the
+// identity provider until the first login, so the server can start even if
+// the identity provider is down.
+func New(config Config, httpClient *http.Client, now func() time.Time) *Provider {
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	return &Provider{
+		config:     config,
+		httpClient: httpClient,
+		now:        now,
+	}
+}
+
+// Issuer returns the identity provider's issuer identifier.
+func (p *Provider) Issuer() string {
+	return p.config.IssuerURL
+}
+
+// NewAuthRequest generates the state, nonce, and PKCE code verifier for a new
+// login attempt.
+func NewAuthRequest() AuthRequest {
+	return AuthRequest{
+		State:        randomToken(),
+		Nonce:        randomToken(),
+		CodeVerifier: randomToken(),
+	}
+}
+
+// AuthCodeURL returns the URL that starts the login at the identity provider.
+func (p *Provider) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	metadata, err := p.readMetadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.config.ClientID)
+	q.Set("redirect_uri", p.config.RedirectURL)
+	q.Set("scope", "openid email profile")
+	q.Set("state", req.State)
+	q.Set("nonce", req.Nonce)
+	q.Set("code_challenge", codeChallenge(req.CodeVerifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// Exchange trades the authorization code from the callback for an ID token
+// and returns the verified claims in it.
+func (p *Provider) Exchange(ctx context.Context, code string, req AuthRequest) (Claims, error) {
+	metadata, err := p.readMetadata(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", req.CodeVerifier)
+	if p.config.ClientSecret == "" {
+		form.Set("client_id", p.config.ClientID)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		httpReq.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	res, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return Claims{}, fmt.Errorf("token request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxResponseBytes)).Decode(&body); err != nil {
+		return Claims{}, fmt.Errorf("failed to decode token response (status %d): %w", res.StatusCode, err)
+	}
+	if res.StatusCode != http.StatusOK || body.Error != "" {
+		return Claims{}, fmt.Errorf("%w: %s %s", ErrTokenRequest, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return Claims{}, fmt.Errorf("%w: response has no ID token", ErrTokenRequest)
+	}
+
+	return p.verifyIDToken(ctx, body.IDToken, req.Nonce)
+}
+
+func (p *Provider) readMetadata(ctx context.Context) (providerMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return *p.metadata, nil
+	}
+
+	discoveryURL := strings.TrimSuffix(p.config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	var metadata providerMetadata
+	if err := p.getJSON(ctx, discoveryURL, &metadata); err != nil {
+		return providerMetadata{}, fmt.Errorf("discovery failed: %w", err)
+	}
+
+	if metadata.Issuer != p.config.IssuerURL {
+		return providerMetadata{}, fmt.Errorf("discovery returned issuer %q, want %q", metadata.Issuer, p.config.IssuerURL)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JwksURI == "" {
+		return providerMetadata{}, errors.New("discovery document is missing required endpoints")
+	}
+	if len(metadata.CodeChallengeMethodsSupported) > 0 && !slices.Contains(metadata.CodeChallengeMethodsSupported, "S256") {
+		return providerMetadata{}, errors.New("identity provider doesn't support PKCE with S256")
+	}
+
+	p.metadata = &metadata
+	return metadata, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, target string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned status %d", target, res.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(res.Body, maxResponseBytes)).Decode(v)
+}
+
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,237 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/oidc"
+	"github.com/mtlynch/screenjournal/v2/oidc/oidctest"
+)
+
+const redirectURL = "https://sj.example.com/auth/oidc/callback"
+
+var testUser = oidctest.User{
+	Subject:           "user-1234",
+	Email:             "jane@example.com",
+	EmailVerified:     true,
+	PreferredUsername: "jane",
+	Groups:            []string{"film-club", "staff"},
+}
+
+func TestExchange(t *testing.T) {
+	for _, tt := range []struct {
+		description  string
+		clientSecret string
+		modifyClaims func(map[string]any)
+		groupsClaim  string
+		claimsOut    oidc.Claims
+		errOut       error
+	}{
+		{
+			description:  "confidential client receives verified claims",
+			clientSecret: "s3cr3t/with+symbols",
+			claimsOut: oidc.Claims{
+				Subject:           "user-1234",
+				Email:             "jane@example.com",
+				EmailVerified:     true,
+				PreferredUsername: "jane",
+				Groups:            []string{"film-club", "staff"},
+			},
+		},
+		{
+			description:  "public client receives verified claims",
+			clientSecret: "",
+			claimsOut: oidc.Claims{
+				Subject:           "user-1234",
+				Email:             "jane@example.com",
+				EmailVerified:     true,
+				PreferredUsername: "jane",
+				Groups:            []string{"film-club", "staff"},
+			},
+		},
+		{
+			description:  "reads groups from a custom claim",
+			clientSecret: "s3cr3t",
+			groupsClaim:  "roles",
+			modifyClaims: func(c map[string]any) {
+				c["roles"] = "film-club"
+			},
+			claimsOut: oidc.Claims{
+				Subject:           "user-1234",
+				Email:             "jane@example.com",
+				EmailVerified:     true,
+				PreferredUsername: "jane",
+				Groups:            []string{"film-club"},
+			},
+		},
+		{
+			description:  "accepts email_verified as a string",
+			clientSecret: "s3cr3t",
+			modifyClaims: func(c map[string]any) {
+				c["email_verified"] = "true"
+			},
+			claimsOut: oidc.Claims{
+				Subject:           "user-1234",
+				Email:             "jane@example.com",
+				EmailVerified:     true,
+				PreferredUsername: "jane",
+				Groups:            []string{"film-club", "staff"},
+			},
+		},
+		{
+			description:  "rejects a token with the wrong nonce",
+			clientSecret: "s3cr3t",
+			modifyClaims: func(c map[string]any) {
+				c["nonce"] = "replayed-nonce"
+			},
+			errOut: oidc.ErrInvalidToken,
+		},
+		{
+			description:  "rejects a token for another client",
+			clientSecret: "s3cr3t",
+			modifyClaims: func(c map[string]any) {
+				c["aud"] = "other-client"
+			},
+			errOut: oidc.ErrInvalidToken,
+		},
+		{
+			description:  "rejects a token with multiple audiences and no authorized party",
+			clientSecret: "s3cr3t",
+			modifyClaims: func(c map[string]any) {
+				c["aud"] = []string{"screenjournal", "other-client"}
+			},
+			errOut: oidc.ErrInvalidToken,
+		},
+		{
+			description:  "rejects a token from another issuer",
+			clientSecret: "s3cr3t",
+			modifyClaims: func(c map[string]any) {
+				c["iss"] = "https://evil.example.com"
+			},
+			errOut: oidc.ErrInvalidToken,
+		},
+		{
+			description:  "rejects an expired token",
+			clientSecret: "s3cr3t",
+			modifyClaims: func(c map[string]any) {
+				c["exp"] = time.Now().Add(-time.Hour).Unix()
+			},
+			errOut: oidc.ErrInvalidToken,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			idp := oidctest.New("screenjournal", tt.clientSecret)
+			defer idp.Close()
+			idp.SetUser(testUser)
+			idp.ModifyClaims = tt.modifyClaims
+
+			provider := oidc.New(oidc.Config{
+				IssuerURL:    idp.URL,
+				ClientID:     "screenjournal",
+				ClientSecret: tt.clientSecret,
+				RedirectURL:  redirectURL,
+				GroupsClaim:  tt.groupsClaim,
+			}, http.DefaultClient, time.Now)
+
+			req := oidc.NewAuthRequest()
+			code := authorize(t, idp, provider, req)
+
+			claims, err := provider.Exchange(context.Background(), code, req)
+			if got, want := err, tt.errOut; !errors.Is(got, want) {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if tt.errOut != nil {
+				return
+			}
+
+			tt.claimsOut.Issuer = idp.URL
+			if got, want := claims, tt.claimsOut; !reflect.DeepEqual(got, want) {
+				t.Errorf("claims=%+v, want=%+v", got, want)
+			}
+		})
+	}
+}
+
+func TestExchangeRejectsReusedCode(t *testing.T) {
+	idp := oidctest.New("screenjournal", "s3cr3t")
+	defer idp.Close()
+	idp.SetUser(testUser)
+
+	provider := oidc.New(oidc.Config{
+		IssuerURL:    idp.URL,
+		ClientID:     "screenjournal",
+		ClientSecret: "s3cr3t",
+		RedirectURL:  redirectURL,
+	}, http.DefaultClient, time.Now)
+
+	req := oidc.NewAuthRequest()
+	code := authorize(t, idp, provider, req)
+
+	if _, err := provider.Exchange(context.Background(), code, req); err != nil {
+		t.Fatalf("first exchange err=%v, want=%v", err, nil)
+	}
+	if _, err := provider.Exchange(context.Background(), code, req); !errors.Is(err, oidc.ErrTokenRequest) {
+		t.Errorf("second exchange err=%v, want=%v", err, oidc.ErrTokenRequest)
+	}
+}
+
+func TestExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	idp := oidctest.New("screenjournal", "s3cr3t")
+	defer idp.Close()
+	idp.SetUser(testUser)
+
+	provider := oidc.New(oidc.Config{
+		IssuerURL:    idp.URL,
+		ClientID:     "screenjournal",
+		ClientSecret: "s3cr3t",
+		RedirectURL:  redirectURL,
+	}, http.DefaultClient, time.Now)
+
+	req := oidc.NewAuthRequest()
+	code := authorize(t, idp, provider, req)
+
+	// An attacker who intercepts the code doesn't know the code verifier.
+	stolen := oidc.NewAuthRequest()
+	stolen.Nonce = req.Nonce
+	if _, err := provider.Exchange(context.Background(), code, stolen); !errors.Is(err, oidc.ErrTokenRequest) {
+		t.Errorf("err=%v, want=%v", err, oidc.ErrTokenRequest)
+	}
+}
+
+func TestAuthCodeURLRejectsMismatchedIssuer(t *testing.T) {
+	idp := oidctest.New("screenjournal", "s3cr3t")
+	defer idp.Close()
+
+	provider := oidc.New(oidc.Config{
+		IssuerURL:   idp.URL + "/",
+		ClientID:    "screenjournal",
+		RedirectURL: redirectURL,
+	}, http.DefaultClient, time.Now)
+
+	if _, err := provider.AuthCodeURL(context.Background(), oidc.NewAuthRequest()); err == nil {
+		t.Errorf("AuthCodeURL succeeded for a provider that reports a different issuer")
+	}
+}
+
+// authorize sends the user through the identity provider and returns the
+// authorization code from the callback.
+func authorize(t *testing.T, idp *oidctest.Server, provider *oidc.Provider, req oidc.AuthRequest) string {
+	t.Helper()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), req)
+	if err != nil {
+		t.Fatalf("failed to create authorization URL: %v", err)
+	}
+	callback, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("authorization failed: %v", err)
+	}
+	if got, want := callback.Query().Get("state"), req.State; got != want {
+		t.Fatalf("state=%v, want=%v", got, want)
+	}
+	return callback.Query().Get("code")
+}
@@ -0,0 +1,195 @@
+// Package passkey manages passkeys: registering them for signed-in users and
+// logging users in with them instead of a password.
+package passkey
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/webauthn"
+)
+
+// challengeLifetime matches how long the browser waits for the user to
+// interact with their authenticator.
+const challengeLifetime = 5 * time.Minute
+
+var (
+	ErrInvalidChallenge    = errors.New("invalid or expired passkey challenge")
+	ErrInvalidCredential   = errors.New("invalid passkey")
+	ErrAlreadyRegistered   = errors.New("passkey is already registered")
+	ErrUnrecognizedPasskey = errors.New("passkey is not registered")
+)
+
+type (
+	Store interface {
+		InsertPasskeyChallenge(screenjournal.PasskeyChallenge) error
+		UsePasskeyChallenge([]byte, screenjournal.PasskeyCeremony, time.Time) (screenjournal.PasskeyChallenge, error)
+		InsertPasskey(screenjournal.Passkey) error
+		ReadPasskey(screenjournal.PasskeyID) (screenjournal.Passkey, error)
+		ReadPasskeys(screenjournal.Username) ([]screenjournal.Passkey, error)
+		UpdatePasskeyUsage(screenjournal.PasskeyID, uint32, time.Time) error
+	}
+
+	Manager struct {
+		store        Store
+		relyingParty webauthn.RelyingParty
+		now          func() time.Time
+	}
+)
+
+func New(store Store, relyingParty webauthn.RelyingParty, now func() time.Time) Manager {
+	if now == nil {
+		panic("passkey manager requires a clock")
+	}
+	return Manager{
+		store:        store,
+		relyingParty: relyingParty,
+		now:          now,
+	}
+}
+
+// BeginRegistration returns the options for the user's browser to create a
+// new passkey.
+func (m Manager) BeginRegistration(username screenjournal.Username) (webauthn.CreationOptions, error) {
+	existing, err := m.store.ReadPasskeys(username)
+	if err != nil {
+		return webauthn.CreationOptions{}, fmt.Errorf("read existing passkeys: %w", err)
+	}
+	exclude := make([][]byte, 0, len(existing))
+	for _, p := range existing {
+		id, err := p.ID.CredentialID()
+		if err != nil {
+			log.Printf("skipping passkey with invalid ID %s: %v", p.ID, err)
+			continue
+		}
+		exclude = append(exclude, id)
+	}
+
+	challenge, err := m.issueChallenge(screenjournal.PasskeyCeremonyRegistration, username)
+	if err != nil {
+		return webauthn.CreationOptions{}, err
+	}
+
+	return m.relyingParty.CreationOptions(challenge, userHandle(username), username.String(), exclude), nil
+}
+
+// FinishRegistration verifies the browser's response to BeginRegistration and
+// saves the new passkey.
+func (m Manager) FinishRegistration(username screenjournal.Username, name screenjournal.PasskeyName, response webauthn.RegistrationResponse) (screenjournal.Passkey, error) {
+	challenge, err := m.useChallenge(response.Challenge, screenjournal.PasskeyCeremonyRegistration)
+	if err != nil {
+		return screenjournal.Passkey{}, err
+	}
+	if !challenge.Username.Equal(username) {
+		return screenjournal.Passkey{}, ErrInvalidChallenge
+	}
+
+	cred, err := m.relyingParty.VerifyRegistration(challenge.Challenge, response)
+	if err != nil {
+		log.Printf("passkey registration failed for user %s: %v", username, err)
+		return screenjournal.Passkey{}, ErrInvalidCredential
+	}
+
+	passkey := screenjournal.Passkey{
+		ID:          screenjournal.NewPasskeyID(cred.ID),
+		Username:    username,
+		Name:        name,
+		PublicKey:   cred.PublicKey,
+		SignCount:   cred.SignCount,
+		CreatedTime: m.now(),
+	}
+	if err := m.store.InsertPasskey(passkey); err != nil {
+		if errors.Is(err, store.ErrPasskeyAlreadyRegistered) {
+			return screenjournal.Passkey{}, ErrAlreadyRegistered
+		}
+		return screenjournal.Passkey{}, fmt.Errorf("save passkey: %w", err)
+	}
+
+	return passkey, nil
+}
+
+// BeginLogin returns the options for the browser to sign in with any passkey
+// that the user has for this server.
+func (m Manager) BeginLogin() (webauthn.RequestOptions, error) {
+	challenge, err := m.issueChallenge(screenjournal.PasskeyCeremonyLogin, screenjournal.Username(""))
+	if err != nil {
+		return webauthn.RequestOptions{}, err
+	}
+	return m.relyingParty.RequestOptions(challenge), nil
+}
+
+// FinishLogin verifies the browser's response to BeginLogin and returns the
+// user who owns the passkey.
+func (m Manager) FinishLogin(response webauthn.AssertionResponse) (screenjournal.Username, error) {
+	challenge, err := m.useChallenge(response.Challenge, screenjournal.PasskeyCeremonyLogin)
+	if err != nil {
+		return screenjournal.Username(""), err
+	}
+
+	passkey, err := m.store.ReadPasskey(screenjournal.NewPasskeyID(response.RawID))
+	if errors.Is(err, store.ErrPasskeyNotFound) {
+		return screenjournal.Username(""), ErrUnrecognizedPasskey
+	} else if err != nil {
+		return screenjournal.Username(""), fmt.Errorf("read passkey: %w", err)
+	}
+
+	// Browsers report the user handle for discoverable credentials. If it's
+	// present, it has to match the passkey's owner.
+	if len(response.Response.UserHandle) > 0 && string(response.Response.UserHandle) != string(userHandle(passkey.Username)) {
+		log.Printf("passkey %s presented a user handle that doesn't match user %s", passkey.ID, passkey.Username)
+		return screenjournal.Username(""), ErrInvalidCredential
+	}
+
+	signCount, err := m.relyingParty.VerifyAssertion(challenge.Challenge, response, passkey.PublicKey, passkey.SignCount)
+	if err != nil {
+		log.Printf("passkey login failed for user %s: %v", passkey.Username, err)
+		return screenjournal.Username(""), ErrInvalidCredential
+	}
+
+	if err := m.store.UpdatePasskeyUsage(passkey.ID, signCount, m.now()); err != nil {
+		return screenjournal.Username(""), fmt.Errorf("record passkey usage: %w", err)
+	}
+
+	return passkey.Username, nil
+}
+
+func (m Manager) issueChallenge(ceremony screenjournal.PasskeyCeremony, username screenjournal.Username) ([]byte, error) {
+	challenge := webauthn.NewChallenge()
+	if err := m.store.InsertPasskeyChallenge(screenjournal.PasskeyChallenge{
+		Challenge: challenge,
+		Ceremony:  ceremony,
+		Username:  username,
+		ExpiresAt: m.now().Add(challengeLifetime),
+	}); err != nil {
+		return nil, fmt.Errorf("save passkey challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// useChallenge consumes the challenge that the response claims to answer.
+// The caller still has to verify that the response signed it.
+func (m Manager) useChallenge(responseChallenge func() ([]byte, error), ceremony screenjournal.PasskeyCeremony) (screenjournal.PasskeyChallenge, error) {
+	raw, err := responseChallenge()
+	if err != nil {
+		return screenjournal.PasskeyChallenge{}, ErrInvalidChallenge
+	}
+
+	challenge, err := m.store.UsePasskeyChallenge(raw, ceremony, m.now())
+	if errors.Is(err, store.ErrPasskeyChallengeNotFound) || errors.Is(err, store.ErrExpiredPasskeyChallenge) {
+		return screenjournal.PasskeyChallenge{}, ErrInvalidChallenge
+	} else if err != nil {
+		return screenjournal.PasskeyChallenge{}, fmt.Errorf("read passkey challenge: %w", err)
+	}
+
+	return challenge, nil
+}
+
+// userHandle identifies the user to their authenticator. Usernames never
+// change, so the username itself works as a stable handle.
+func userHandle(username screenjournal.Username) []byte {
+	return []byte(username.String())
+}
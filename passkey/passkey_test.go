@@ -0,0 +1,301 @@
+package passkey_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/passkey"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/webauthn"
+	"github.com/mtlynch/screenjournal/v2/webauthn/webauthntest"
+)
+
+const testOrigin = "https://screenjournal.example.com"
+
+type mockStore struct {
+	challenges map[string]screenjournal.PasskeyChallenge
+	passkeys   map[screenjournal.PasskeyID]screenjournal.Passkey
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{
+		challenges: map[string]screenjournal.PasskeyChallenge{},
+		passkeys:   map[screenjournal.PasskeyID]screenjournal.Passkey{},
+	}
+}
+
+func (s *mockStore) InsertPasskeyChallenge(c screenjournal.PasskeyChallenge) error {
+	s.challenges[string(c.Challenge)] = c
+	return nil
+}
+
+func (s *mockStore) UsePasskeyChallenge(challenge []byte, ceremony screenjournal.PasskeyCeremony, now time.Time) (screenjournal.PasskeyChallenge, error) {
+	c, ok := s.challenges[string(challenge)]
+	if !ok || c.Ceremony != ceremony {
+		return screenjournal.PasskeyChallenge{}, store.ErrPasskeyChallengeNotFound
+	}
+	delete(s.challenges, string(challenge))
+	if now.After(c.ExpiresAt) {
+		return screenjournal.PasskeyChallenge{}, store.ErrExpiredPasskeyChallenge
+	}
+	return c, nil
+}
+
+func (s *mockStore) InsertPasskey(p screenjournal.Passkey) error {
+	if _, ok := s.passkeys[p.ID]; ok {
+		return store.ErrPasskeyAlreadyRegistered
+	}
+	s.passkeys[p.ID] = p
+	return nil
+}
+
+func (s *mockStore) ReadPasskey(id screenjournal.PasskeyID) (screenjournal.Passkey, error) {
+	p, ok := s.passkeys[id]
+	if !ok {
+		return screenjournal.Passkey{}, store.ErrPasskeyNotFound
+	}
+	return p, nil
+}
+
+func (s *mockStore) ReadPasskeys(username screenjournal.Username) ([]screenjournal.Passkey, error) {
+	passkeys := []screenjournal.Passkey{}
+	for _, p := range s.passkeys {
+		if p.Username.Equal(username) {
+			passkeys = append(passkeys, p)
+		}
+	}
+	return passkeys, nil
+}
+
+func (s *mockStore) UpdatePasskeyUsage(id screenjournal.PasskeyID, signCount uint32, lastUsed time.Time) error {
+	p, ok := s.passkeys[id]
+	if !ok {
+		return store.ErrPasskeyNotFound
+	}
+	p.SignCount = signCount
+	p.LastUsedTime = lastUsed
+	s.passkeys[id] = p
+	return nil
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestManager(t *testing.T) (passkey.Manager, *mockStore, *testClock) {
+	t.Helper()
+	rp, err := webauthn.New(testOrigin, "ScreenJournal")
+	if err != nil {
+		t.Fatalf("failed to create relying party: %v", err)
+	}
+	s := newMockStore()
+	clock := &testClock{now: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+	return passkey.New(s, rp, clock.Now), s, clock
+}
+
+func mustRegisterPasskey(t *testing.T, m passkey.Manager, authenticator *webauthntest.Authenticator, username screenjournal.Username) screenjournal.Passkey {
+	t.Helper()
+	options, err := m.BeginRegistration(username)
+	if err != nil {
+		t.Fatalf("failed to begin registration: %v", err)
+	}
+	response, err := authenticator.Register(options)
+	if err != nil {
+		t.Fatalf("authenticator failed to register: %v", err)
+	}
+	p, err := m.FinishRegistration(username, "Laptop", response)
+	if err != nil {
+		t.Fatalf("failed to finish registration: %v", err)
+	}
+	return p
+}
+
+func TestRegistration(t *testing.T) {
+	for _, tt := range []struct {
+		description    string
+		finishAs       screenjournal.Username
+		advanceClock   time.Duration
+		finishTwice    bool
+		authenticator  func() *webauthntest.Authenticator
+		wantRegistered bool
+		err            error
+	}{
+		{
+			description:    "registering a passkey succeeds",
+			finishAs:       "userA",
+			wantRegistered: true,
+		},
+		{
+			description: "finishing another user's registration fails",
+			finishAs:    "userB",
+			err:         passkey.ErrInvalidChallenge,
+		},
+		{
+			description:  "finishing after the challenge expires fails",
+			finishAs:     "userA",
+			advanceClock: 6 * time.Minute,
+			err:          passkey.ErrInvalidChallenge,
+		},
+		{
+			description: "reusing a registration response fails",
+			finishAs:    "userA",
+			finishTwice: true,
+			err:         passkey.ErrInvalidChallenge,
+		},
+		{
+			description: "registering from another origin fails",
+			finishAs:    "userA",
+			authenticator: func() *webauthntest.Authenticator {
+				return webauthntest.New("https://evil.example.com")
+			},
+			err: passkey.ErrInvalidCredential,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			m, s, clock := newTestManager(t)
+			authenticator := webauthntest.New(testOrigin)
+			if tt.authenticator != nil {
+				authenticator = tt.authenticator()
+			}
+
+			options, err := m.BeginRegistration("userA")
+			if err != nil {
+				t.Fatalf("failed to begin registration: %v", err)
+			}
+			response, err := authenticator.Register(options)
+			if err != nil {
+				t.Fatalf("authenticator failed to register: %v", err)
+			}
+			clock.now = clock.now.Add(tt.advanceClock)
+
+			if tt.finishTwice {
+				if _, err := m.FinishRegistration(tt.finishAs, "Laptop", response); err != nil {
+					t.Fatalf("failed to finish first registration: %v", err)
+				}
+			}
+
+			p, err := m.FinishRegistration(tt.finishAs, "Laptop", response)
+			if got, want := err, tt.err; !errors.Is(got, want) {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if !tt.wantRegistered {
+				return
+			}
+
+			stored, err := s.ReadPasskey(p.ID)
+			if err != nil {
+				t.Fatalf("passkey was not saved: %v", err)
+			}
+			if got, want := stored.Username, screenjournal.Username("userA"); got != want {
+				t.Errorf("username=%v, want=%v", got, want)
+			}
+			if got, want := stored.Name, screenjournal.PasskeyName("Laptop"); got != want {
+				t.Errorf("name=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestBeginRegistrationExcludesExistingPasskeys(t *testing.T) {
+	m, _, _ := newTestManager(t)
+	authenticator := webauthntest.New(testOrigin)
+	mustRegisterPasskey(t, m, authenticator, "userA")
+
+	options, err := m.BeginRegistration("userA")
+	if err != nil {
+		t.Fatalf("failed to begin registration: %v", err)
+	}
+	if got, want := len(options.ExcludeCredentials), 1; got != want {
+		t.Fatalf("excluded credentials=%d, want=%d", got, want)
+	}
+	if _, err := authenticator.Register(options); err == nil {
+		t.Errorf("authenticator registered a second passkey despite exclusion")
+	}
+}
+
+func TestLogin(t *testing.T) {
+	for _, tt := range []struct {
+		description  string
+		register     bool
+		advanceClock time.Duration
+		replay       bool
+		err          error
+	}{
+		{
+			description: "logging in with a registered passkey succeeds",
+			register:    true,
+		},
+		{
+			description: "logging in with an unregistered passkey fails",
+			err:         passkey.ErrUnrecognizedPasskey,
+		},
+		{
+			description:  "logging in after the challenge expires fails",
+			register:     true,
+			advanceClock: 6 * time.Minute,
+			err:          passkey.ErrInvalidChallenge,
+		},
+		{
+			description: "replaying a login response fails",
+			register:    true,
+			replay:      true,
+			err:         passkey.ErrInvalidChallenge,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			m, s, clock := newTestManager(t)
+			authenticator := webauthntest.New(testOrigin)
+			if tt.register {
+				mustRegisterPasskey(t, m, authenticator, "userA")
+			} else {
+				// Create a passkey that the server never saw.
+				other, _, _ := newTestManager(t)
+				mustRegisterPasskey(t, other, authenticator, "userA")
+			}
+
+			options, err := m.BeginLogin()
+			if err != nil {
+				t.Fatalf("failed to begin login: %v", err)
+			}
+			response, err := authenticator.Login(options)
+			if err != nil {
+				t.Fatalf("authenticator failed to log in: %v", err)
+			}
+			clock.now = clock.now.Add(tt.advanceClock)
+
+			if tt.replay {
+				if _, err := m.FinishLogin(response); err != nil {
+					t.Fatalf("failed to finish first login: %v", err)
+				}
+			}
+
+			username, err := m.FinishLogin(response)
+			if got, want := err, tt.err; !errors.Is(got, want) {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if err != nil {
+				return
+			}
+			if got, want := username, screenjournal.Username("userA"); got != want {
+				t.Errorf("username=%v, want=%v", got, want)
+			}
+
+			stored, err := s.ReadPasskey(screenjournal.NewPasskeyID(response.RawID))
+			if err != nil {
+				t.Fatalf("failed to read passkey: %v", err)
+			}
+			if got, want := stored.SignCount, uint32(1); got != want {
+				t.Errorf("sign count=%d, want=%d", got, want)
+			}
+			if got, want := stored.LastUsedTime, clock.now; !got.Equal(want) {
+				t.Errorf("last used=%v, want=%v", got, want)
+			}
+		})
+	}
+}
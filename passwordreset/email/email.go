@@ -28,9 +28,14 @@ func New(baseURL string, sender email.Sender) Resetter {
 //go:embed templates
 var templatesFS embed.FS
 
-var emailTemplate = template.Must(
-	template.New("password-reset.tmpl.txt").
-		ParseFS(templatesFS, "templates/password-reset.tmpl.txt"))
+var (
+	emailTemplate = template.Must(
+		template.New("password-reset.tmpl.txt").
+			ParseFS(templatesFS, "templates/password-reset.tmpl.txt"))
+	loginLinkTemplate = template.Must(
+		template.New("login-link.tmpl.txt").
+			ParseFS(templatesFS, "templates/login-link.tmpl.txt"))
+)
 
 func (r Resetter) Send(user screenjournal.User, entry screenjournal.PasswordResetEntry) error {
 	resetURL := fmt.Sprintf("%s/account/password-reset?username=%s&token=%s", r.baseURL, user.Username, entry.Token)
@@ -70,3 +75,42 @@ func (r Resetter) Send(user screenjournal.User, entry screenjournal.PasswordRese
 	log.Printf("sent password reset email for user %s", user.Username)
 	return nil
 }
+
+func (r Resetter) SendLoginLink(user screenjournal.User, entry screenjournal.LoginLinkEntry) error {
+	loginURL := fmt.Sprintf("%s/login/email-link?username=%s&token=%s", r.baseURL, user.Username, entry.Token)
+
+	var bodyBuf bytes.Buffer
+	if err := loginLinkTemplate.Execute(&bodyBuf, struct {
+		Username string
+		LoginURL string
+	}{
+		Username: user.Username.String(),
+		LoginURL: loginURL,
+	}); err != nil {
+		return fmt.Errorf("rendering login link email: %w", err)
+	}
+
+	bodyMarkdown := screenjournal.EmailBodyMarkdown(bodyBuf.String())
+	msg := email.Message{
+		From: mail.Address{
+			Name:    "ScreenJournal",
+			Address: "password-resets@thescreenjournal.com",
+		},
+		To: []mail.Address{
+			{
+				Name:    user.Username.String(),
+				Address: user.Email.String(),
+			},
+		},
+		Subject:  "Your ScreenJournal login link",
+		TextBody: bodyMarkdown.String(),
+		HtmlBody: markdown.RenderEmail(bodyMarkdown),
+	}
+
+	if err := r.sender.Send(msg); err != nil {
+		return fmt.Errorf("sending login link email for user %s: %w", user.Username, err)
+	}
+
+	log.Printf("sent login link email for user %s", user.Username)
+	return nil
+}
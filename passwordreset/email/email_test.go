@@ -182,6 +182,55 @@ If you didn't request a password reset, reply and let me know.
 	}
 }
 
+func TestSendLoginLink(t *testing.T) {
+	sender := &mockEmailSender{
+		emailsSent: []email.Message{},
+	}
+	resetter := passwordreset_email.New("https://dev.thescreenjournal.com", sender)
+
+	user := screenjournal.User{
+		Username: screenjournal.Username("alice"),
+		Email:    screenjournal.Email("alice@example.com"),
+	}
+	entry := screenjournal.LoginLinkEntry{
+		Username:  user.Username,
+		Token:     dummyToken,
+		ExpiresAt: time.Date(2024, 1, 15, 12, 15, 0, 0, time.UTC),
+	}
+
+	if err := resetter.SendLoginLink(user, entry); err != nil {
+		t.Fatalf("err=%v, want=%v", err, nil)
+	}
+
+	if got, want := len(sender.emailsSent), 1; got != want {
+		t.Fatalf("email count=%d, want=%d", got, want)
+	}
+	msg := sender.emailsSent[0]
+	if got, want := msg.Subject, "Your ScreenJournal login link"; got != want {
+		t.Errorf("subject=%s, want=%s", got, want)
+	}
+	if got, want := msg.To, []mail.Address{{Name: "alice", Address: "alice@example.com"}}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("to=%v, want=%v", got, want)
+	}
+	wantBody := `Hi alice,
+
+Someone (hopefully you) asked to log in to ScreenJournal without a password.
+
+Click the link below to log in:
+
+https://dev.thescreenjournal.com/login/email-link?username=alice&token=abc123tokenXYZ
+
+This link works once and will self-destruct in 15 minutes.
+
+If you didn't request a login link, reply and let me know.
+
+-ScreenJournal Bot
+`
+	if d := diff.Diff(wantBody, msg.TextBody); d != "" {
+		t.Errorf("email (plaintext): %s", d)
+	}
+}
+
 func errToString(err error) string {
 	if err == nil {
 		return ""
@@ -0,0 +1,80 @@
+package passwordreset
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+// loginLinkExpiry is much shorter than a password reset's expiry because a
+// login link grants access immediately.
+const loginLinkExpiry = 15 * time.Minute
+
+var (
+	ErrTooManyLoginLinkAttempts = errors.New("too many login link attempts")
+	ErrInvalidLoginLink         = errors.New("invalid or already used login link")
+	ErrExpiredLoginLink         = errors.New("login link has expired")
+)
+
+// SendLoginLink emails the user a link that logs them in without their
+// password. Like SendEmail, it reports success even if no user has the email
+// address so that callers can't use it to discover accounts.
+func (r Resetter) SendLoginLink(emailAddr screenjournal.Email) error {
+	user, err := r.store.ReadUserByEmail(emailAddr)
+	if err != nil {
+		if err == store.ErrUserNotFound {
+			log.Printf("login link requested for unregistered email")
+			return nil
+		}
+		return fmt.Errorf("look up user by email: %w", err)
+	}
+
+	if !r.loginLinkLimiter.HasAttemptsRemaining(user.Username) {
+		log.Printf("login link rate limited for user %s", user.Username)
+		return nil
+	}
+
+	entry := screenjournal.LoginLinkEntry{
+		Username:  user.Username,
+		Token:     screenjournal.NewPasswordResetToken(),
+		ExpiresAt: r.now().Add(loginLinkExpiry),
+	}
+	if err := r.store.InsertLoginLinkEntry(entry); err != nil {
+		return fmt.Errorf("insert login link entry: %w", err)
+	}
+
+	if err := r.emailSender.SendLoginLink(user, entry); err != nil {
+		return fmt.Errorf("send login link email for user %s: %w", user.Username, err)
+	}
+
+	r.loginLinkLimiter.RecordAttempt(user.Username)
+	return nil
+}
+
+// UseLoginLink verifies and consumes the user's login link. Failed attempts
+// count toward the same limit as password reset tokens, since both are
+// guesses at a token that we emailed the user.
+func (r Resetter) UseLoginLink(username screenjournal.Username, token screenjournal.PasswordResetToken) error {
+	if !r.tokenAttemptLimiter.HasAttemptsRemaining(username) {
+		log.Printf("login link attempt rate limited for user %s", username)
+		return ErrTooManyLoginLinkAttempts
+	}
+
+	err := r.store.UseLoginLinkEntry(username, token, r.now())
+	if err != nil {
+		r.tokenAttemptLimiter.RecordAttempt(username)
+		switch {
+		case errors.Is(err, store.ErrInvalidLoginLinkToken):
+			return ErrInvalidLoginLink
+		case errors.Is(err, store.ErrExpiredLoginLinkToken):
+			return ErrExpiredLoginLink
+		}
+		return fmt.Errorf("use login link token %s: %w", tokenPrefix(token), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package passwordreset_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/passwordreset"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+func TestSendLoginLink(t *testing.T) {
+	fixedNow := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	user := screenjournal.User{
+		Username: screenjournal.Username("alice"),
+		Email:    screenjournal.Email("alice@example.com"),
+	}
+
+	t.Run("sends a short-lived link to a known user", func(t *testing.T) {
+		dataStore := newMockStore()
+		dataStore.usersByEmail[user.Email] = user
+		sender := &mockEmailSender{}
+		resetter := passwordreset.New(dataStore, sender, func() time.Time { return fixedNow })
+
+		if err := resetter.SendLoginLink(user.Email); err != nil {
+			t.Fatalf("err=%v, want=%v", err, nil)
+		}
+		if got, want := len(sender.sentLoginLinks), 1; got != want {
+			t.Fatalf("linksSent=%d, want=%d", got, want)
+		}
+		if got, want := sender.sentLoginLinks[0].ExpiresAt, fixedNow.Add(15*time.Minute); !got.Equal(want) {
+			t.Errorf("expiresAt=%s, want=%s", got, want)
+		}
+		if got, want := len(sender.sentMessages), 0; got != want {
+			t.Errorf("passwordResetsSent=%d, want=%d", got, want)
+		}
+	})
+
+	t.Run("returns success for unknown user email", func(t *testing.T) {
+		dataStore := newMockStore()
+		sender := &mockEmailSender{}
+		resetter := passwordreset.New(dataStore, sender, func() time.Time { return fixedNow })
+
+		if err := resetter.SendLoginLink(screenjournal.Email("nobody@example.com")); err != nil {
+			t.Fatalf("err=%v, want=%v", err, nil)
+		}
+		if got, want := len(sender.sentLoginLinks), 0; got != want {
+			t.Fatalf("linksSent=%d, want=%d", got, want)
+		}
+	})
+
+	t.Run("rate limits after three links per user", func(t *testing.T) {
+		dataStore := newMockStore()
+		dataStore.usersByEmail[user.Email] = user
+		sender := &mockEmailSender{}
+		resetter := passwordreset.New(dataStore, sender, func() time.Time { return fixedNow })
+
+		for range 4 {
+			if err := resetter.SendLoginLink(user.Email); err != nil {
+				t.Fatalf("err=%v, want=%v", err, nil)
+			}
+		}
+		if got, want := len(sender.sentLoginLinks), 3; got != want {
+			t.Fatalf("linksSent=%d, want=%d", got, want)
+		}
+	})
+}
+
+func TestUseLoginLink(t *testing.T) {
+	fixedNow := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	username := screenjournal.Username("alice")
+	token := screenjournal.NewPasswordResetTokenFromString("ABCDEFGHJKLMNPQRSTUVWXYZabcdef23")
+
+	for _, tt := range []struct {
+		description string
+		expiresAt   time.Time
+		useToken    screenjournal.PasswordResetToken
+		errOut      error
+	}{
+		{
+			description: "accepts a valid link",
+			expiresAt:   fixedNow.Add(time.Minute),
+			useToken:    token,
+			errOut:      nil,
+		},
+		{
+			description: "rejects an expired link",
+			expiresAt:   fixedNow.Add(-time.Minute),
+			useToken:    token,
+			errOut:      passwordreset.ErrExpiredLoginLink,
+		},
+		{
+			description: "rejects the wrong token",
+			expiresAt:   fixedNow.Add(time.Minute),
+			useToken:    screenjournal.NewPasswordResetTokenFromString("ZZZZZZZZZZZZZZZZZZZZZZZZZZZZZZZZ"),
+			errOut:      passwordreset.ErrInvalidLoginLink,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			dataStore := newMockStore()
+			dataStore.loginLinks[username] = screenjournal.LoginLinkEntry{
+				Username:  username,
+				Token:     token,
+				ExpiresAt: tt.expiresAt,
+			}
+			resetter := passwordreset.New(dataStore, &mockEmailSender{}, func() time.Time { return fixedNow })
+
+			if got, want := resetter.UseLoginLink(username, tt.useToken), tt.errOut; !errors.Is(got, want) {
+				t.Errorf("err=%v, want=%v", got, want)
+			}
+		})
+	}
+
+	t.Run("link works only once", func(t *testing.T) {
+		dataStore := newMockStore()
+		dataStore.loginLinks[username] = screenjournal.LoginLinkEntry{
+			Username:  username,
+			Token:     token,
+			ExpiresAt: fixedNow.Add(time.Minute),
+		}
+		resetter := passwordreset.New(dataStore, &mockEmailSender{}, func() time.Time { return fixedNow })
+
+		if err := resetter.UseLoginLink(username, token); err != nil {
+			t.Fatalf("first use err=%v, want=%v", err, nil)
+		}
+		if got, want := resetter.UseLoginLink(username, token), passwordreset.ErrInvalidLoginLink; !errors.Is(got, want) {
+			t.Errorf("second use err=%v, want=%v", got, want)
+		}
+	})
+
+	t.Run("rate limits after five failed attempts", func(t *testing.T) {
+		dataStore := newMockStore()
+		resetter := passwordreset.New(dataStore, &mockEmailSender{}, func() time.Time { return fixedNow })
+
+		for range 5 {
+			if got, want := resetter.UseLoginLink(username, token), passwordreset.ErrInvalidLoginLink; !errors.Is(got, want) {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+		}
+		if got, want := resetter.UseLoginLink(username, token), passwordreset.ErrTooManyLoginLinkAttempts; !errors.Is(got, want) {
+			t.Errorf("err=%v, want=%v", got, want)
+		}
+	})
+}
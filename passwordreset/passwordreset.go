@@ -29,16 +29,20 @@ type (
 			screenjournal.PasswordHash,
 			time.Time,
 		) error
+		InsertLoginLinkEntry(screenjournal.LoginLinkEntry) error
+		UseLoginLinkEntry(screenjournal.Username, screenjournal.PasswordResetToken, time.Time) error
 	}
 
 	emailSender interface {
 		Send(screenjournal.User, screenjournal.PasswordResetEntry) error
+		SendLoginLink(screenjournal.User, screenjournal.LoginLinkEntry) error
 	}
 
 	Resetter struct {
 		store                Store
 		emailSender          emailSender
 		passwordResetLimiter *ratelimit.PasswordResetLimiter
+		loginLinkLimiter     *ratelimit.LoginLinkLimiter
 		tokenAttemptLimiter  *ratelimit.TokenAttemptLimiter
 		now                  func() time.Time
 	}
@@ -52,6 +56,7 @@ func New(store Store, sender emailSender, now func() time.Time) Resetter {
 		store:                store,
 		emailSender:          sender,
 		passwordResetLimiter: ratelimit.NewPasswordResetLimiter(now),
+		loginLinkLimiter:     ratelimit.NewLoginLinkLimiter(now),
 		tokenAttemptLimiter:  ratelimit.NewTokenAttemptLimiter(now),
 		now:                  now,
 	}
@@ -133,6 +138,15 @@ func (noopEmailSender) Send(user screenjournal.User, entry screenjournal.Passwor
 	return nil
 }
 
+func (noopEmailSender) SendLoginLink(user screenjournal.User, entry screenjournal.LoginLinkEntry) error {
+	log.Printf(
+		"login link email skipped for user %s (token %s)",
+		user.Username,
+		tokenPrefix(entry.Token),
+	)
+	return nil
+}
+
 func tokenPrefix(token screenjournal.PasswordResetToken) string {
 	tokenRaw := token.String()
 	const tokenPreviewLength = 6
@@ -16,6 +16,7 @@ type mockStore struct {
 	usersByEmail    map[screenjournal.Email]screenjournal.User
 	usersByUsername map[screenjournal.Username]screenjournal.User
 	entriesByToken  map[string]screenjournal.PasswordResetEntry
+	loginLinks      map[screenjournal.Username]screenjournal.LoginLinkEntry
 
 	readUserByEmailErr error
 	readEntryErr       error
@@ -31,6 +32,7 @@ func newMockStore() *mockStore {
 		usersByEmail:     map[screenjournal.Email]screenjournal.User{},
 		usersByUsername:  map[screenjournal.Username]screenjournal.User{},
 		entriesByToken:   map[string]screenjournal.PasswordResetEntry{},
+		loginLinks:       map[screenjournal.Username]screenjournal.LoginLinkEntry{},
 		updatedPasswords: map[screenjournal.Username]screenjournal.PasswordHash{},
 	}
 }
@@ -110,9 +112,30 @@ func (s *mockStore) DeletePasswordResetEntry(token screenjournal.PasswordResetTo
 	return nil
 }
 
+func (s *mockStore) InsertLoginLinkEntry(entry screenjournal.LoginLinkEntry) error {
+	if s.insertEntryErr != nil {
+		return s.insertEntryErr
+	}
+	s.loginLinks[entry.Username] = entry
+	return nil
+}
+
+func (s *mockStore) UseLoginLinkEntry(username screenjournal.Username, token screenjournal.PasswordResetToken, now time.Time) error {
+	entry, ok := s.loginLinks[username]
+	if !ok || !entry.Token.Equal(token) {
+		return store.ErrInvalidLoginLinkToken
+	}
+	delete(s.loginLinks, username)
+	if now.After(entry.ExpiresAt) {
+		return store.ErrExpiredLoginLinkToken
+	}
+	return nil
+}
+
 type mockEmailSender struct {
-	sentMessages []emailMessage
-	err          error
+	sentMessages   []emailMessage
+	sentLoginLinks []screenjournal.LoginLinkEntry
+	err            error
 }
 
 type emailMessage struct {
@@ -131,6 +154,11 @@ func (s *mockEmailSender) Send(user screenjournal.User, entry screenjournal.Pass
 	return nil
 }
 
+func (s *mockEmailSender) SendLoginLink(user screenjournal.User, entry screenjournal.LoginLinkEntry) error {
+	s.sentLoginLinks = append(s.sentLoginLinks, entry)
+	return s.err
+}
+
 func TestSendEmail(t *testing.T) {
 	fixedNow := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 
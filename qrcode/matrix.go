@@ -0,0 +1,290 @@
+package qrcode
+
+type builder struct {
+	version    int
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+func newBuilder(version int) *builder {
+	size := version*4 + 17
+	q := &builder{
+		version:    version,
+		size:       size,
+		modules:    make([][]bool, size),
+		isFunction: make([][]bool, size),
+	}
+	for y := range size {
+		q.modules[y] = make([]bool, size)
+		q.isFunction[y] = make([]bool, size)
+	}
+	return q
+}
+
+func (q *builder) setFunctionModule(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.isFunction[y][x] = true
+}
+
+func (q *builder) drawFunctionPatterns() {
+	for i := range q.size {
+		q.setFunctionModule(6, i, i%2 == 0)
+		q.setFunctionModule(i, 6, i%2 == 0)
+	}
+
+	q.drawFinderPattern(3, 3)
+	q.drawFinderPattern(q.size-4, 3)
+	q.drawFinderPattern(3, q.size-4)
+
+	positions := versions[q.version].alignment
+	last := len(positions) - 1
+	for i, row := range positions {
+		for j, col := range positions {
+			// Skip the three corners that overlap finder patterns.
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			q.drawAlignmentPattern(col, row)
+		}
+	}
+
+	// Reserve the format areas now so that data doesn't land there. The real
+	// format bits are drawn after choosing a mask.
+	q.drawFormatBits(0)
+	q.drawVersionBits()
+}
+
+// drawFinderPattern draws a finder pattern and its separator centered at
+// (x, y).
+func (q *builder) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= q.size || yy < 0 || yy >= q.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			q.setFunctionModule(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (q *builder) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.setFunctionModule(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+func (q *builder) drawFormatBits(mask int) {
+	bits := formatBits(eccLevelM, mask)
+
+	// First copy, around the top-left finder pattern.
+	for i := 0; i <= 5; i++ {
+		q.setFunctionModule(8, i, bit(bits, i))
+	}
+	q.setFunctionModule(8, 7, bit(bits, 6))
+	q.setFunctionModule(8, 8, bit(bits, 7))
+	q.setFunctionModule(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		q.setFunctionModule(14-i, 8, bit(bits, i))
+	}
+
+	// Second copy, split between the other two finder patterns.
+	for i := range 8 {
+		q.setFunctionModule(q.size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunctionModule(8, q.size-15+i, bit(bits, i))
+	}
+	// The dark module is always dark.
+	q.setFunctionModule(8, q.size-8, true)
+}
+
+func (q *builder) drawVersionBits() {
+	if q.version < 7 {
+		return
+	}
+	bits := versionBits(q.version)
+	for i := range 18 {
+		a := q.size - 11 + i%3
+		b := i / 3
+		q.setFunctionModule(a, b, bit(bits, i))
+		q.setFunctionModule(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords places data in the zigzag order the QR spec defines, moving
+// up and down in two-module-wide columns from the right edge.
+func (q *builder) drawCodewords(data []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		// Skip the vertical timing pattern.
+		if right == 6 {
+			right = 5
+		}
+		for vert := range q.size {
+			for j := range 2 {
+				x := right - j
+				upward := (right+1)&2 == 0
+				y := vert
+				if upward {
+					y = q.size - 1 - vert
+				}
+				if !q.isFunction[y][x] && i < len(data)*8 {
+					q.modules[y][x] = bit(int(data[i/8]), 7-i%8)
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (q *builder) applyMask(mask int) {
+	for y := range q.size {
+		for x := range q.size {
+			if q.isFunction[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the code is to scan, following the four rules in
+// the QR spec. Lower is better.
+func (q *builder) penalty() int {
+	result := 0
+
+	// Rule 1: runs of five or more same-colored modules in a row or column.
+	// Rule 3: patterns that look like finder patterns.
+	for i := range q.size {
+		row := make([]bool, q.size)
+		col := make([]bool, q.size)
+		for j := range q.size {
+			row[j] = q.modules[i][j]
+			col[j] = q.modules[j][i]
+		}
+		result += runPenalty(row) + runPenalty(col)
+		result += finderLikePenalty(row) + finderLikePenalty(col)
+	}
+
+	// Rule 2: 2x2 blocks of the same color.
+	for y := 0; y < q.size-1; y++ {
+		for x := 0; x < q.size-1; x++ {
+			c := q.modules[y][x]
+			if c == q.modules[y][x+1] && c == q.modules[y+1][x] && c == q.modules[y+1][x+1] {
+				result += 3
+			}
+		}
+	}
+
+	// Rule 4: imbalance between dark and light modules.
+	dark := 0
+	for y := range q.size {
+		for x := range q.size {
+			if q.modules[y][x] {
+				dark++
+			}
+		}
+	}
+	total := q.size * q.size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	result += max(k, 0) * 10
+
+	return result
+}
+
+func runPenalty(line []bool) int {
+	result := 0
+	runLength := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			runLength++
+			continue
+		}
+		if runLength >= 5 {
+			result += runLength - 2
+		}
+		runLength = 1
+	}
+	return result
+}
+
+func finderLikePenalty(line []bool) int {
+	patterns := [][]bool{
+		{true, false, true, true, true, false, true, false, false, false, false},
+		{false, false, false, false, true, false, true, true, true, false, true},
+	}
+	result := 0
+	for i := 0; i+11 <= len(line); i++ {
+		for _, pattern := range patterns {
+			match := true
+			for j, want := range pattern {
+				if line[i+j] != want {
+					match = false
+					break
+				}
+			}
+			if match {
+				result += 40
+			}
+		}
+	}
+	return result
+}
+
+// formatBits returns the 15-bit format information for an error correction
+// level and mask, protected by a BCH code.
+func formatBits(eccLevel, mask int) int {
+	data := eccLevel<<3 | mask
+	rem := data
+	for range 10 {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// versionBits returns the 18-bit version information for versions 7 and
+// above, protected by a BCH code.
+func versionBits(version int) int {
+	rem := version
+	for range 12 {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+func bit(x, i int) bool {
+	return (x>>i)&1 == 1
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
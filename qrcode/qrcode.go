@@ -0,0 +1,199 @@
+// Package qrcode encodes short strings as QR codes, such as the otpauth URIs
+// that authenticator apps scan during two-factor enrollment.
+//
+// It supports only byte mode at error correction level M for versions 1-10,
+// which holds up to 213 bytes.
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrDataTooLong = errors.New("data is too long to encode as a QR code")
+
+type (
+	// Code is a square grid of modules, where true is a dark module.
+	Code struct {
+		modules [][]bool
+	}
+
+	versionInfo struct {
+		// ecPerBlock is the number of error correction codewords in each block.
+		ecPerBlock int
+		// blocks holds the number of data codewords in each block.
+		blocks []int
+		// alignment holds the row and column centers of alignment patterns.
+		alignment []int
+	}
+)
+
+// versions describes each supported version at error correction level M.
+var versions = []versionInfo{
+	1:  {10, []int{16}, nil},
+	2:  {16, []int{28}, []int{6, 18}},
+	3:  {26, []int{44}, []int{6, 22}},
+	4:  {18, []int{32, 32}, []int{6, 26}},
+	5:  {24, []int{43, 43}, []int{6, 30}},
+	6:  {16, []int{27, 27, 27, 27}, []int{6, 34}},
+	7:  {18, []int{31, 31, 31, 31}, []int{6, 22, 38}},
+	8:  {22, []int{38, 38, 39, 39}, []int{6, 24, 42}},
+	9:  {22, []int{36, 36, 36, 37, 37}, []int{6, 26, 46}},
+	10: {26, []int{43, 43, 43, 43, 44}, []int{6, 28, 50}},
+}
+
+// eccLevelM is the format information indicator for error correction level M.
+const eccLevelM = 0
+
+// Encode returns the smallest QR code that holds data.
+func Encode(data string) (Code, error) {
+	version, err := pickVersion(len(data))
+	if err != nil {
+		return Code{}, err
+	}
+
+	q := newBuilder(version)
+	q.drawFunctionPatterns()
+	q.drawCodewords(addErrorCorrection(versions[version], encodeData(version, []byte(data))))
+
+	bestMask := 0
+	bestPenalty := -1
+	for mask := range 8 {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if p := q.penalty(); bestPenalty < 0 || p < bestPenalty {
+			bestMask = mask
+			bestPenalty = p
+		}
+		// Masks are self-inverse, so applying it again restores the original.
+		q.applyMask(mask)
+	}
+	q.applyMask(bestMask)
+	q.drawFormatBits(bestMask)
+
+	return Code{modules: q.modules}, nil
+}
+
+// Size returns the number of modules along each side of the code.
+func (c Code) Size() int {
+	return len(c.modules)
+}
+
+// Dark reports whether the module at column x and row y is dark.
+func (c Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// SVG renders the code as an SVG image with a four-module quiet zone.
+func (c Code) SVG() string {
+	const border = 4
+	dim := c.Size() + border*2
+	var path strings.Builder
+	for y := range c.Size() {
+		for x := range c.Size() {
+			if c.Dark(x, y) {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+border, y+border)
+			}
+		}
+	}
+	return fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+			`<rect width="100%%" height="100%%" fill="#ffffff"/>`+
+			`<path d="%s" fill="#000000"/>`+
+			`</svg>`,
+		dim, dim, path.String())
+}
+
+func pickVersion(dataLen int) (int, error) {
+	for version := 1; version < len(versions); version++ {
+		capacityBits := dataCodewords(versions[version]) * 8
+		if 4+charCountBits(version)+dataLen*8 <= capacityBits {
+			return version, nil
+		}
+	}
+	return 0, ErrDataTooLong
+}
+
+func charCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+func dataCodewords(v versionInfo) int {
+	total := 0
+	for _, n := range v.blocks {
+		total += n
+	}
+	return total
+}
+
+// encodeData returns the data codewords for data in byte mode, including the
+// terminator and padding.
+func encodeData(version int, data []byte) []byte {
+	var bits bitBuffer
+	bits.append(0b0100, 4)
+	bits.append(len(data), charCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacityBits := dataCodewords(versions[version]) * 8
+	bits.append(0, min(4, capacityBits-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacityBits; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	return bits.bytes()
+}
+
+// addErrorCorrection splits data into blocks, computes each block's error
+// correction codewords, and interleaves the results.
+func addErrorCorrection(v versionInfo, data []byte) []byte {
+	divisor := reedSolomonDivisor(v.ecPerBlock)
+	dataBlocks := [][]byte{}
+	ecBlocks := [][]byte{}
+	offset := 0
+	for _, n := range v.blocks {
+		block := data[offset : offset+n]
+		offset += n
+		dataBlocks = append(dataBlocks, block)
+		ecBlocks = append(ecBlocks, reedSolomonRemainder(block, divisor))
+	}
+
+	result := []byte{}
+	for i := range v.blocks[len(v.blocks)-1] {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := range v.ecPerBlock {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 == 1)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			result[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return result
+}
@@ -0,0 +1,162 @@
+package qrcode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomonRemainder(t *testing.T) {
+	// Data and error correction codewords for "HELLO WORLD" as a version 1-M
+	// code, from https://www.thonky.com/qr-code-tutorial/.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if got := reedSolomonRemainder(data, reedSolomonDivisor(10)); !reflect.DeepEqual(got, want) {
+		t.Errorf("remainder=%v, want=%v", got, want)
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	for _, tt := range []struct {
+		mask int
+		want int
+	}{
+		{0, 0b101010000010010},
+		{1, 0b101000100100101},
+		{4, 0b100010111111001},
+		{7, 0b100101010100000},
+	} {
+		if got := formatBits(eccLevelM, tt.mask); got != tt.want {
+			t.Errorf("formatBits(M, %d)=%015b, want=%015b", tt.mask, got, tt.want)
+		}
+	}
+}
+
+func TestVersionBits(t *testing.T) {
+	for _, tt := range []struct {
+		version int
+		want    int
+	}{
+		{7, 0b000111110010010100},
+		{10, 0b001010010011010011},
+	} {
+		if got := versionBits(tt.version); got != tt.want {
+			t.Errorf("versionBits(%d)=%018b, want=%018b", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestEncodePicksSmallestVersion(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		data        string
+		size        int
+		err         error
+	}{
+		{"short string fits in version 1", "hello", 21, nil},
+		{"14 bytes fill version 1", strings.Repeat("a", 14), 21, nil},
+		{"15 bytes need version 2", strings.Repeat("a", 15), 25, nil},
+		{"typical otpauth URI", "otpauth://totp/ScreenJournal:alice?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=ScreenJournal", 41, nil},
+		{"213 bytes fill version 10", strings.Repeat("a", 213), 57, nil},
+		{"214 bytes are too long", strings.Repeat("a", 214), 0, ErrDataTooLong},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			code, err := Encode(tt.data)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := code.Size(), tt.size; got != want {
+				t.Errorf("size=%d, want=%d", got, want)
+			}
+		})
+	}
+}
+
+func TestEncodeDrawsFinderPatterns(t *testing.T) {
+	code, err := Encode("hello")
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+
+	want := []string{
+		"#######",
+		"#.....#",
+		"#.###.#",
+		"#.###.#",
+		"#.###.#",
+		"#.....#",
+		"#######",
+	}
+	for _, corner := range [][2]int{{0, 0}, {code.Size() - 7, 0}, {0, code.Size() - 7}} {
+		for dy, row := range want {
+			for dx, c := range row {
+				if got, want := code.Dark(corner[0]+dx, corner[1]+dy), c == '#'; got != want {
+					t.Errorf("module (%d, %d) dark=%v, want=%v", corner[0]+dx, corner[1]+dy, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestEncodeWritesReadableFormatBits(t *testing.T) {
+	code, err := Encode("otpauth://totp/ScreenJournal:alice")
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+
+	// Read both copies of the format information back out of the symbol.
+	var first, second int
+	for i := 0; i <= 5; i++ {
+		first |= boolToBit(code.Dark(8, i)) << i
+	}
+	first |= boolToBit(code.Dark(8, 7)) << 6
+	first |= boolToBit(code.Dark(8, 8)) << 7
+	first |= boolToBit(code.Dark(7, 8)) << 8
+	for i := 9; i < 15; i++ {
+		first |= boolToBit(code.Dark(14-i, 8)) << i
+	}
+	for i := range 8 {
+		second |= boolToBit(code.Dark(code.Size()-1-i, 8)) << i
+	}
+	for i := 8; i < 15; i++ {
+		second |= boolToBit(code.Dark(8, code.Size()-15+i)) << i
+	}
+
+	if first != second {
+		t.Fatalf("format copies differ: %015b vs %015b", first, second)
+	}
+	found := false
+	for mask := range 8 {
+		if formatBits(eccLevelM, mask) == first {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("format bits %015b don't match any mask at level M", first)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	code, err := Encode("hello")
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+
+	svg := code.SVG()
+	if !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 29 29"`) {
+		t.Errorf("unexpected SVG header: %s", svg[:80])
+	}
+	// The top-left corner of the top-left finder pattern is offset by the quiet
+	// zone.
+	if !strings.Contains(svg, "M4,4h1v1h-1z") {
+		t.Errorf("SVG is missing the top-left finder module")
+	}
+}
+
+func boolToBit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
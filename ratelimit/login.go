@@ -0,0 +1,240 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+// loginPolicy describes how a login throttle responds to repeated failures.
+// After delayThreshold consecutive failures, each further failure makes the
+// caller wait before trying again, doubling the wait each time. After
+// lockoutThreshold failures, every further failure locks out logins for the
+// full lockout duration.
+type loginPolicy struct {
+	delayThreshold   int
+	lockoutThreshold int
+	lockout          time.Duration
+}
+
+var (
+	usernameLoginPolicy = loginPolicy{
+		delayThreshold:   3,
+		lockoutThreshold: 10,
+		lockout:          15 * time.Minute,
+	}
+	// ipLoginPolicy is looser than the username policy because several users
+	// can share an IP address.
+	ipLoginPolicy = loginPolicy{
+		delayThreshold:   10,
+		lockoutThreshold: 30,
+		lockout:          15 * time.Minute,
+	}
+)
+
+// loginFailureWindow is how long a throttle remembers failures. If there are
+// no failures for this long, the count starts over.
+const loginFailureWindow = time.Hour
+
+type (
+	// LoginStore persists login throttles so that a restart doesn't reset
+	// them.
+	LoginStore interface {
+		ReadLoginThrottle(screenjournal.LoginThrottleScope, string) (screenjournal.LoginThrottle, error)
+		UpsertLoginThrottle(screenjournal.LoginThrottle) error
+		DeleteLoginThrottle(screenjournal.LoginThrottleScope, string) error
+		DeleteStaleLoginThrottles(time.Time) error
+	}
+
+	// LoginLimiter limits failed login attempts per username and per IP
+	// address.
+	LoginLimiter struct {
+		mu       sync.Mutex
+		store    LoginStore
+		now      func() time.Time
+		attempts keyedMutex
+	}
+
+	// keyedMutex is a set of mutexes, one per key, that it creates on demand
+	// and discards once nobody holds or waits for them.
+	keyedMutex struct {
+		mu    sync.Mutex
+		locks map[string]*keyLock
+	}
+
+	keyLock struct {
+		sync.Mutex
+		// waiters counts the callers that hold or are waiting for the lock.
+		waiters int
+	}
+)
+
+// NewLoginLimiter creates a limiter that stores its state in the given store
+// and uses the given function to determine the current time.
+func NewLoginLimiter(store LoginStore, now func() time.Time) *LoginLimiter {
+	return &LoginLimiter{
+		store: store,
+		now:   now,
+	}
+}
+
+// Lock waits until no other login attempt for the same username or IP address
+// is in progress. Callers hold the lock from RetryAfter until they record the
+// attempt's result, so that parallel guesses each see the failures recorded
+// before them. Lock returns a function that releases the lock.
+func (l *LoginLimiter) Lock(username screenjournal.Username, ip string) func() {
+	// Every caller locks keys in the same order (username, then IP address), so
+	// two attempts can't deadlock each other.
+	keys := loginThrottleKeys(username, ip)
+	unlocks := make([]func(), 0, len(keys))
+	for _, key := range keys {
+		unlocks = append(unlocks, l.attempts.lock(key.scope.String()+":"+key.key))
+	}
+	return func() {
+		for _, unlock := range slices.Backward(unlocks) {
+			unlock()
+		}
+	}
+}
+
+// RetryAfter reports how long the caller has to wait before attempting to log
+// in as the given user from the given IP address. It returns zero if the
+// attempt may proceed.
+func (l *LoginLimiter) RetryAfter(username screenjournal.Username, ip string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var wait time.Duration
+	for _, key := range loginThrottleKeys(username, ip) {
+		throttle, err := l.store.ReadLoginThrottle(key.scope, key.key)
+		if errors.Is(err, store.ErrLoginThrottleNotFound) {
+			continue
+		} else if err != nil {
+			return 0, fmt.Errorf("read login throttle: %w", err)
+		}
+		if throttle.IsBlocked(now) {
+			wait = max(wait, throttle.BlockedUntil.Sub(now))
+		}
+	}
+
+	return wait, nil
+}
+
+// RecordFailure logs a failed login attempt as the given user from the given
+// IP address.
+func (l *LoginLimiter) RecordFailure(username screenjournal.Username, ip string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for _, key := range loginThrottleKeys(username, ip) {
+		throttle, err := l.store.ReadLoginThrottle(key.scope, key.key)
+		if errors.Is(err, store.ErrLoginThrottleNotFound) {
+			throttle = screenjournal.LoginThrottle{
+				Scope: key.scope,
+				Key:   key.key,
+			}
+		} else if err != nil {
+			return fmt.Errorf("read login throttle: %w", err)
+		}
+
+		// Forget old failures once they fall out of the window.
+		if now.Sub(throttle.LastFailureAt) > loginFailureWindow && !throttle.IsBlocked(now) {
+			throttle.Failures = 0
+		}
+
+		throttle.Failures++
+		throttle.LastFailureAt = now
+		throttle.BlockedUntil = key.policy.blockedUntil(throttle.Failures, now)
+
+		if err := l.store.UpsertLoginThrottle(throttle); err != nil {
+			return fmt.Errorf("save login throttle: %w", err)
+		}
+	}
+
+	if err := l.store.DeleteStaleLoginThrottles(now.Add(-loginFailureWindow)); err != nil {
+		return fmt.Errorf("delete stale login throttles: %w", err)
+	}
+
+	return nil
+}
+
+// RecordSuccess clears the failed login count for a user who just logged in.
+// It leaves the IP address's count alone so that logging in to one account
+// doesn't reset the limit on guessing passwords for others.
+func (l *LoginLimiter) RecordSuccess(username screenjournal.Username) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	err := l.store.DeleteLoginThrottle(screenjournal.LoginThrottleScopeUsername, username.String())
+	if err != nil && !errors.Is(err, store.ErrLoginThrottleNotFound) {
+		return fmt.Errorf("clear login throttle: %w", err)
+	}
+	return nil
+}
+
+func (km *keyedMutex) lock(key string) func() {
+	km.mu.Lock()
+	if km.locks == nil {
+		km.locks = map[string]*keyLock{}
+	}
+	kl, ok := km.locks[key]
+	if !ok {
+		kl = &keyLock{}
+		km.locks[key] = kl
+	}
+	kl.waiters++
+	km.mu.Unlock()
+
+	kl.Lock()
+	return func() {
+		kl.Unlock()
+		km.mu.Lock()
+		kl.waiters--
+		if kl.waiters == 0 {
+			delete(km.locks, key)
+		}
+		km.mu.Unlock()
+	}
+}
+
+func (p loginPolicy) blockedUntil(failures int, now time.Time) time.Time {
+	if failures >= p.lockoutThreshold {
+		return now.Add(p.lockout)
+	}
+	if failures >= p.delayThreshold {
+		delay := time.Second << (failures - p.delayThreshold)
+		return now.Add(min(delay, p.lockout))
+	}
+	return time.Time{}
+}
+
+type loginThrottleKey struct {
+	scope  screenjournal.LoginThrottleScope
+	key    string
+	policy loginPolicy
+}
+
+func loginThrottleKeys(username screenjournal.Username, ip string) []loginThrottleKey {
+	keys := []loginThrottleKey{
+		{
+			scope:  screenjournal.LoginThrottleScopeUsername,
+			key:    username.String(),
+			policy: usernameLoginPolicy,
+		},
+	}
+	if ip != "" {
+		keys = append(keys, loginThrottleKey{
+			scope:  screenjournal.LoginThrottleScopeIP,
+			key:    ip,
+			policy: ipLoginPolicy,
+		})
+	}
+	return keys
+}
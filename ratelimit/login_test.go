@@ -0,0 +1,222 @@
+package ratelimit_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/ratelimit"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestLoginLimiter(t *testing.T) {
+	baseTime := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		description     string
+		priorFailures   int
+		otherIPFailures int
+		elapsed         time.Duration
+		succeeded       bool
+		wantRetryAfter  time.Duration
+	}{
+		{
+			description:    "first attempt is allowed",
+			wantRetryAfter: 0,
+		},
+		{
+			description:    "two failures don't delay the next attempt",
+			priorFailures:  2,
+			wantRetryAfter: 0,
+		},
+		{
+			description:    "third failure delays the next attempt by a second",
+			priorFailures:  3,
+			wantRetryAfter: time.Second,
+		},
+		{
+			description:    "each failure doubles the delay",
+			priorFailures:  6,
+			wantRetryAfter: 8 * time.Second,
+		},
+		{
+			description:    "delay expires",
+			priorFailures:  6,
+			elapsed:        8 * time.Second,
+			wantRetryAfter: 0,
+		},
+		{
+			description:    "tenth failure locks out the username",
+			priorFailures:  10,
+			wantRetryAfter: 15 * time.Minute,
+		},
+		{
+			description:     "failures from other IPs count against the username",
+			otherIPFailures: 10,
+			wantRetryAfter:  15 * time.Minute,
+		},
+		{
+			description:    "lockout expires",
+			priorFailures:  10,
+			elapsed:        15 * time.Minute,
+			wantRetryAfter: 0,
+		},
+		{
+			description:    "successful login clears the username's failures",
+			priorFailures:  6,
+			succeeded:      true,
+			wantRetryAfter: 0,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			now := baseTime
+			limiter := ratelimit.NewLoginLimiter(test_sqlite.New(), func() time.Time { return now })
+
+			for range tt.priorFailures {
+				if err := limiter.RecordFailure("userA", "203.0.113.7"); err != nil {
+					t.Fatalf("RecordFailure err=%v, want=%v", err, nil)
+				}
+			}
+			for range tt.otherIPFailures {
+				if err := limiter.RecordFailure("userA", "198.51.100.1"); err != nil {
+					t.Fatalf("RecordFailure err=%v, want=%v", err, nil)
+				}
+			}
+			if tt.succeeded {
+				if err := limiter.RecordSuccess("userA"); err != nil {
+					t.Fatalf("RecordSuccess err=%v, want=%v", err, nil)
+				}
+			}
+			now = now.Add(tt.elapsed)
+
+			retryAfter, err := limiter.RetryAfter("userA", "203.0.113.7")
+			if err != nil {
+				t.Fatalf("RetryAfter err=%v, want=%v", err, nil)
+			}
+			if got, want := retryAfter, tt.wantRetryAfter; got != want {
+				t.Errorf("retryAfter=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoginLimiterIPLockout(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	limiter := ratelimit.NewLoginLimiter(test_sqlite.New(), func() time.Time { return now })
+
+	// Spread the failures across many usernames so that no single username
+	// gets locked out.
+	usernames := []screenjournal.Username{"userA", "userB", "userC", "userD", "userE", "userF", "userG", "userH", "userI", "userJ"}
+	for i := range 30 {
+		if err := limiter.RecordFailure(usernames[i%len(usernames)], "203.0.113.7"); err != nil {
+			t.Fatalf("RecordFailure err=%v, want=%v", err, nil)
+		}
+	}
+	// Logging in successfully to one account doesn't clear the IP's failures.
+	if err := limiter.RecordSuccess("userK"); err != nil {
+		t.Fatalf("RecordSuccess err=%v, want=%v", err, nil)
+	}
+
+	retryAfter, err := limiter.RetryAfter("userK", "203.0.113.7")
+	if err != nil {
+		t.Fatalf("RetryAfter err=%v, want=%v", err, nil)
+	}
+	if got, want := retryAfter, 15*time.Minute; got != want {
+		t.Errorf("retryAfter from locked IP=%v, want=%v", got, want)
+	}
+
+	retryAfter, err = limiter.RetryAfter("userK", "198.51.100.1")
+	if err != nil {
+		t.Fatalf("RetryAfter err=%v, want=%v", err, nil)
+	}
+	if got, want := retryAfter, time.Duration(0); got != want {
+		t.Errorf("retryAfter from another IP=%v, want=%v", got, want)
+	}
+}
+
+func TestLoginLimiterForgetsOldFailures(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	limiter := ratelimit.NewLoginLimiter(test_sqlite.New(), func() time.Time { return now })
+
+	for range 9 {
+		if err := limiter.RecordFailure("userA", "203.0.113.7"); err != nil {
+			t.Fatalf("RecordFailure err=%v, want=%v", err, nil)
+		}
+	}
+
+	// After an hour without failures, the count starts over, so the next
+	// failure doesn't trigger a lockout.
+	now = now.Add(2 * time.Hour)
+	if err := limiter.RecordFailure("userA", "203.0.113.7"); err != nil {
+		t.Fatalf("RecordFailure err=%v, want=%v", err, nil)
+	}
+	retryAfter, err := limiter.RetryAfter("userA", "203.0.113.7")
+	if err != nil {
+		t.Fatalf("RetryAfter err=%v, want=%v", err, nil)
+	}
+	if got, want := retryAfter, time.Duration(0); got != want {
+		t.Errorf("retryAfter=%v, want=%v", got, want)
+	}
+}
+
+func TestLoginLimiterSurvivesRestart(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	store := test_sqlite.New()
+
+	limiter := ratelimit.NewLoginLimiter(store, clock)
+	for range 10 {
+		if err := limiter.RecordFailure("userA", "203.0.113.7"); err != nil {
+			t.Fatalf("RecordFailure err=%v, want=%v", err, nil)
+		}
+	}
+
+	restarted := ratelimit.NewLoginLimiter(store, clock)
+	retryAfter, err := restarted.RetryAfter("userA", "198.51.100.1")
+	if err != nil {
+		t.Fatalf("RetryAfter err=%v, want=%v", err, nil)
+	}
+	if got, want := retryAfter, 15*time.Minute; got != want {
+		t.Errorf("retryAfter=%v, want=%v", got, want)
+	}
+}
+
+func TestLoginLimiterParallelGuesses(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	limiter := ratelimit.NewLoginLimiter(test_sqlite.New(), func() time.Time { return now })
+
+	// Each guess comes from a different IP address so that only the username's
+	// limit applies.
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := range 20 {
+		ip := fmt.Sprintf("203.0.113.%d", i)
+		wg.Go(func() {
+			unlock := limiter.Lock("userA", ip)
+			defer unlock()
+
+			retryAfter, err := limiter.RetryAfter("userA", ip)
+			if err != nil {
+				t.Errorf("RetryAfter err=%v, want=%v", err, nil)
+				return
+			}
+			if retryAfter > 0 {
+				return
+			}
+			allowed.Add(1)
+			if err := limiter.RecordFailure("userA", ip); err != nil {
+				t.Errorf("RecordFailure err=%v, want=%v", err, nil)
+			}
+		})
+	}
+	wg.Wait()
+
+	// The third failure starts the delay, so the rest of the guesses have to
+	// wait.
+	if got, want := allowed.Load(), int32(3); got != want {
+		t.Errorf("allowed guesses=%d, want=%d", got, want)
+	}
+}
@@ -149,3 +149,225 @@ func (l *TokenAttemptLimiter) removeExpiredEvents() {
 	}
 	l.events = kept
 }
+
+const (
+	// twoFactorAttemptPerUserLimit is the maximum number of failed two-factor
+	// codes a single user can submit within the rate limit window.
+	twoFactorAttemptPerUserLimit = 5
+	// twoFactorAttemptWindow is the sliding time window over which two-factor
+	// attempt rate limits are enforced.
+	twoFactorAttemptWindow = 15 * time.Minute
+)
+
+// TwoFactorAttemptLimiter enforces rate limits on two-factor code attempts.
+type TwoFactorAttemptLimiter struct {
+	mu       sync.Mutex
+	events   []event
+	now      func() time.Time
+	attempts keyedMutex
+}
+
+// NewTwoFactorAttemptLimiter creates a limiter that uses the given function to
+// determine the current time.
+func NewTwoFactorAttemptLimiter(now func() time.Time) *TwoFactorAttemptLimiter {
+	return &TwoFactorAttemptLimiter{
+		now: now,
+	}
+}
+
+// Lock waits until no other two-factor code attempt for the same user is in
+// progress. Callers hold the lock from HasAttemptsRemaining until they record
+// the attempt's result, so that parallel guesses each see the failures
+// recorded before them. Lock returns a function that releases the lock.
+func (l *TwoFactorAttemptLimiter) Lock(username screenjournal.Username) func() {
+	return l.attempts.lock(username.String())
+}
+
+// HasAttemptsRemaining reports whether a two-factor code attempt may proceed
+// for the given user without exceeding the per-user (5/15m) limit.
+func (l *TwoFactorAttemptLimiter) HasAttemptsRemaining(username screenjournal.Username) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.removeExpiredEvents()
+
+	var userCount int
+	for _, e := range l.events {
+		if e.username.Equal(username) {
+			userCount++
+		}
+	}
+
+	return userCount < twoFactorAttemptPerUserLimit
+}
+
+// RecordAttempt logs that a failed two-factor code attempt was made for the
+// given user.
+func (l *TwoFactorAttemptLimiter) RecordAttempt(username screenjournal.Username) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events = append(l.events, event{
+		username:  username,
+		timestamp: l.now(),
+	})
+}
+
+func (l *TwoFactorAttemptLimiter) removeExpiredEvents() {
+	cutoff := l.now().Add(-twoFactorAttemptWindow)
+	kept := l.events[:0]
+	for _, e := range l.events {
+		if !e.timestamp.Before(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	l.events = kept
+}
+
+const (
+	// loginLinkPerUserLimit is the maximum number of login link emails a
+	// single user can receive within the rate limit window.
+	loginLinkPerUserLimit = 3
+	// loginLinkGlobalLimit is the maximum number of login link emails that
+	// can be sent to all users combined within the rate limit window.
+	loginLinkGlobalLimit = 20
+	// loginLinkWindow is the sliding time window over which login link rate
+	// limits are enforced.
+	loginLinkWindow = time.Hour
+)
+
+// LoginLinkLimiter enforces rate limits on login link emails.
+type LoginLinkLimiter struct {
+	mu     sync.Mutex
+	events []event
+	now    func() time.Time
+}
+
+// NewLoginLinkLimiter creates a limiter that uses the given function to
+// determine the current time.
+func NewLoginLinkLimiter(now func() time.Time) *LoginLinkLimiter {
+	return &LoginLinkLimiter{
+		now: now,
+	}
+}
+
+// HasAttemptsRemaining reports whether a login link email may be sent for the
+// given user without exceeding the per-user (3/1h) or global (20/1h) limits.
+func (l *LoginLinkLimiter) HasAttemptsRemaining(username screenjournal.Username) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.removeExpiredEvents()
+
+	var userCount int
+	for _, e := range l.events {
+		if e.username.Equal(username) {
+			userCount++
+		}
+	}
+
+	if userCount >= loginLinkPerUserLimit {
+		return false
+	}
+	if len(l.events) >= loginLinkGlobalLimit {
+		return false
+	}
+	return true
+}
+
+// RecordAttempt logs that a login link email was sent for the given user.
+func (l *LoginLinkLimiter) RecordAttempt(username screenjournal.Username) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events = append(l.events, event{
+		username:  username,
+		timestamp: l.now(),
+	})
+}
+
+func (l *LoginLinkLimiter) removeExpiredEvents() {
+	cutoff := l.now().Add(-loginLinkWindow)
+	kept := l.events[:0]
+	for _, e := range l.events {
+		if !e.timestamp.Before(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	l.events = kept
+}
+
+const (
+	// emailVerificationPerUserLimit is the maximum number of verification
+	// emails a single user can receive within the rate limit window.
+	emailVerificationPerUserLimit = 3
+	// emailVerificationGlobalLimit is the maximum number of verification
+	// emails that can be sent to all users combined within the rate limit
+	// window.
+	emailVerificationGlobalLimit = 20
+	// emailVerificationWindow is the sliding time window over which email
+	// verification rate limits are enforced.
+	emailVerificationWindow = time.Hour
+)
+
+// EmailVerificationLimiter enforces rate limits on email verification emails.
+type EmailVerificationLimiter struct {
+	mu     sync.Mutex
+	events []event
+	now    func() time.Time
+}
+
+// NewEmailVerificationLimiter creates a limiter that uses the given function
+// to determine the current time.
+func NewEmailVerificationLimiter(now func() time.Time) *EmailVerificationLimiter {
+	return &EmailVerificationLimiter{
+		now: now,
+	}
+}
+
+// HasAttemptsRemaining reports whether a verification email may be sent for
+// the given user without exceeding the per-user (3/1h) or global (20/1h)
+// limits.
+func (l *EmailVerificationLimiter) HasAttemptsRemaining(username screenjournal.Username) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.removeExpiredEvents()
+
+	var userCount int
+	for _, e := range l.events {
+		if e.username.Equal(username) {
+			userCount++
+		}
+	}
+
+	if userCount >= emailVerificationPerUserLimit {
+		return false
+	}
+	if len(l.events) >= emailVerificationGlobalLimit {
+		return false
+	}
+	return true
+}
+
+// RecordAttempt logs that a verification email was sent for the given user.
+func (l *EmailVerificationLimiter) RecordAttempt(username screenjournal.Username) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events = append(l.events, event{
+		username:  username,
+		timestamp: l.now(),
+	})
+}
+
+func (l *EmailVerificationLimiter) removeExpiredEvents() {
+	cutoff := l.now().Add(-emailVerificationWindow)
+	kept := l.events[:0]
+	for _, e := range l.events {
+		if !e.timestamp.Before(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	l.events = kept
+}
@@ -124,3 +124,180 @@ func TestTokenAttemptLimiter(t *testing.T) {
 		})
 	}
 }
+
+func TestTwoFactorAttemptLimiter(t *testing.T) {
+	baseTime := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		description            string
+		priorAttemptsForUser   int
+		priorAttemptsForOthers int
+		timeSincePriorAttempts time.Duration
+		allowExpected          bool
+	}{
+		{
+			description:   "first attempt is allowed",
+			allowExpected: true,
+		},
+		{
+			description:          "fifth attempt for same user is allowed",
+			priorAttemptsForUser: 4,
+			allowExpected:        true,
+		},
+		{
+			description:          "sixth attempt for same user is blocked",
+			priorAttemptsForUser: 5,
+			allowExpected:        false,
+		},
+		{
+			description:            "attempts by other users don't count",
+			priorAttemptsForOthers: 5,
+			allowExpected:          true,
+		},
+		{
+			description:            "per-user limit still applies within 15 minutes",
+			priorAttemptsForUser:   5,
+			timeSincePriorAttempts: 14 * time.Minute,
+			allowExpected:          false,
+		},
+		{
+			description:            "per-user limit resets after 15 minutes",
+			priorAttemptsForUser:   5,
+			timeSincePriorAttempts: 15*time.Minute + time.Second,
+			allowExpected:          true,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			now := baseTime
+			limiter := ratelimit.NewTwoFactorAttemptLimiter(func() time.Time { return now })
+
+			queryUser := screenjournal.Username("alice")
+			for range tt.priorAttemptsForUser {
+				limiter.RecordAttempt(queryUser)
+			}
+			for i := range tt.priorAttemptsForOthers {
+				limiter.RecordAttempt(screenjournal.Username(fmt.Sprintf("other-user-%d", i)))
+			}
+
+			now = baseTime.Add(tt.timeSincePriorAttempts)
+
+			if got, want := limiter.HasAttemptsRemaining(queryUser), tt.allowExpected; got != want {
+				t.Errorf("HasAttemptsRemaining(%s)=%v, want=%v", queryUser, got, want)
+			}
+		})
+	}
+}
+
+func TestLoginLinkLimiter(t *testing.T) {
+	baseTime := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		description         string
+		priorLinksForUser   int
+		priorLinksForOthers int
+		timeSincePriorLinks time.Duration
+		allowExpected       bool
+	}{
+		{
+			description:   "first request is allowed",
+			allowExpected: true,
+		},
+		{
+			description:       "third request for same user is allowed",
+			priorLinksForUser: 2,
+			allowExpected:     true,
+		},
+		{
+			description:       "fourth request for same user is blocked",
+			priorLinksForUser: 3,
+			allowExpected:     false,
+		},
+		{
+			description:         "per-user limit resets after an hour",
+			priorLinksForUser:   3,
+			timeSincePriorLinks: time.Hour + time.Second,
+			allowExpected:       true,
+		},
+		{
+			description:         "global limit of 20 blocks new user",
+			priorLinksForOthers: 20,
+			allowExpected:       false,
+		},
+		{
+			description:         "per-user blocked but other users still allowed",
+			priorLinksForOthers: 3,
+			allowExpected:       true,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			now := baseTime
+			limiter := ratelimit.NewLoginLinkLimiter(func() time.Time { return now })
+
+			queryUser := screenjournal.Username("alice")
+			for range tt.priorLinksForUser {
+				limiter.RecordAttempt(queryUser)
+			}
+			for i := range tt.priorLinksForOthers {
+				limiter.RecordAttempt(screenjournal.Username(fmt.Sprintf("other-user-%d", i)))
+			}
+
+			now = baseTime.Add(tt.timeSincePriorLinks)
+
+			if got, want := limiter.HasAttemptsRemaining(queryUser), tt.allowExpected; got != want {
+				t.Errorf("HasAttemptsRemaining(%s)=%v, want=%v", queryUser, got, want)
+			}
+		})
+	}
+}
+
+func TestEmailVerificationLimiter(t *testing.T) {
+	baseTime := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		description          string
+		priorEmailsForUser   int
+		priorEmailsForOthers int
+		timeSincePriorEmails time.Duration
+		allowExpected        bool
+	}{
+		{
+			description:   "first request is allowed",
+			allowExpected: true,
+		},
+		{
+			description:        "fourth request for same user is blocked",
+			priorEmailsForUser: 3,
+			allowExpected:      false,
+		},
+		{
+			description:          "per-user limit resets after an hour",
+			priorEmailsForUser:   3,
+			timeSincePriorEmails: time.Hour + time.Second,
+			allowExpected:        true,
+		},
+		{
+			description:          "global limit of 20 blocks new user",
+			priorEmailsForOthers: 20,
+			allowExpected:        false,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			now := baseTime
+			limiter := ratelimit.NewEmailVerificationLimiter(func() time.Time { return now })
+
+			queryUser := screenjournal.Username("alice")
+			for range tt.priorEmailsForUser {
+				limiter.RecordAttempt(queryUser)
+			}
+			for i := range tt.priorEmailsForOthers {
+				limiter.RecordAttempt(screenjournal.Username(fmt.Sprintf("other-user-%d", i)))
+			}
+
+			now = baseTime.Add(tt.timeSincePriorEmails)
+
+			if got, want := limiter.HasAttemptsRemaining(queryUser), tt.allowExpected; got != want {
+				t.Errorf("HasAttemptsRemaining(%s)=%v, want=%v", queryUser, got, want)
+			}
+		})
+	}
+}
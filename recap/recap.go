@@ -0,0 +1,97 @@
+package recap
+
+import (
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+// Build summarizes what the given user watched and how they interacted with
+// other members during the given calendar year. The reviews should include
+// every review on the server, with comments and reactions populated, so that
+// the user's interactions with other members' reviews are counted.
+func Build(username screenjournal.Username, year int, reviews []screenjournal.Review) screenjournal.Recap {
+	r := screenjournal.Recap{
+		Username:      username,
+		Year:          year,
+		TitlesWatched: []screenjournal.Review{},
+	}
+
+	interactions := map[screenjournal.Username]int{}
+	emojiCounts := map[string]int{}
+
+	for _, review := range reviews {
+		if review.Owner.Equal(username) {
+			if review.Watched.Time().Year() == year {
+				addWatchedTitle(&r, review)
+			}
+			continue
+		}
+
+		for _, comment := range review.Comments {
+			if comment.Owner.Equal(username) && comment.Created.Year() == year {
+				interactions[review.Owner]++
+			}
+		}
+		for _, reaction := range review.Reactions {
+			if reaction.Owner.Equal(username) && reaction.Created.Year() == year {
+				interactions[review.Owner]++
+				emojiCounts[reaction.Emoji.String()]++
+			}
+		}
+	}
+
+	// The former member placeholder stands in for everyone who deleted their
+	// account, so it can't be anyone's favorite reviewer.
+	delete(interactions, screenjournal.FormerMemberUsername)
+
+	for reviewer, count := range interactions {
+		// Break ties alphabetically so that the result is deterministic.
+		if count > r.FavoriteReviewerInteractions ||
+			(count == r.FavoriteReviewerInteractions && reviewer.String() < r.FavoriteReviewer.String()) {
+			r.FavoriteReviewer = reviewer
+			r.FavoriteReviewerInteractions = count
+		}
+	}
+
+	// Iterate in the order of allowed emojis so that ties resolve
+	// deterministically.
+	for _, emoji := range screenjournal.AllowedReactionEmojis() {
+		if count := emojiCounts[emoji.String()]; count > r.FavoriteEmojiCount {
+			r.FavoriteEmoji = emoji
+			r.FavoriteEmojiCount = count
+		}
+	}
+
+	return r
+}
+
+func addWatchedTitle(r *screenjournal.Recap, review screenjournal.Review) {
+	r.TitlesWatched = append(r.TitlesWatched, review)
+
+	if review.MediaType() == screenjournal.MediaTypeMovie {
+		if review.Movie.Runtime == 0 {
+			r.MoviesWithoutRuntime++
+		} else {
+			r.MovieTimeWatched += review.Movie.Runtime
+		}
+	}
+
+	if !review.Rating.IsNil() {
+		if r.HighestRated.Rating.IsNil() || review.Rating.UInt8() > r.HighestRated.Rating.UInt8() {
+			r.HighestRated = review
+		}
+		if r.LowestRated.Rating.IsNil() || review.Rating.UInt8() < r.LowestRated.Rating.UInt8() {
+			r.LowestRated = review
+		}
+	}
+
+	commentCount := 0
+	for _, comment := range review.Comments {
+		if !comment.Owner.Equal(review.Owner) {
+			commentCount++
+		}
+	}
+	if commentCount > r.MostCommentedCount {
+		r.MostCommented = review
+		r.MostCommentedCount = commentCount
+	}
+}
@@ -0,0 +1,211 @@
+package recap_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/recap"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+func TestBuild(t *testing.T) {
+	inYear := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	beforeYear := time.Date(2023, time.December, 31, 12, 0, 0, 0, time.UTC)
+
+	theMatrix := screenjournal.Review{
+		ID:      screenjournal.ReviewID(1),
+		Owner:   screenjournal.Username("alice"),
+		Rating:  screenjournal.NewRating(9),
+		Watched: screenjournal.WatchDate(inYear),
+		Movie: screenjournal.Movie{
+			ID:      screenjournal.MovieID(10),
+			Title:   screenjournal.MediaTitle("The Matrix"),
+			Runtime: 136 * time.Minute,
+		},
+		Comments: []screenjournal.ReviewComment{
+			{Owner: screenjournal.Username("bob"), Created: inYear},
+			{Owner: screenjournal.Username("carol"), Created: inYear},
+			// The review author's own replies don't count toward comments.
+			{Owner: screenjournal.Username("alice"), Created: inYear},
+		},
+	}
+	waterboy := screenjournal.Review{
+		ID:      screenjournal.ReviewID(2),
+		Owner:   screenjournal.Username("alice"),
+		Rating:  screenjournal.NewRating(3),
+		Watched: screenjournal.WatchDate(inYear),
+		Movie: screenjournal.Movie{
+			ID:      screenjournal.MovieID(11),
+			Title:   screenjournal.MediaTitle("The Waterboy"),
+			Runtime: 90 * time.Minute,
+		},
+		Comments: []screenjournal.ReviewComment{
+			{Owner: screenjournal.Username("bob"), Created: inYear},
+		},
+	}
+	unrated := screenjournal.Review{
+		ID:           screenjournal.ReviewID(3),
+		Owner:        screenjournal.Username("alice"),
+		Watched:      screenjournal.WatchDate(inYear),
+		TvShow:       screenjournal.TvShow{ID: screenjournal.TvShowID(4), Title: screenjournal.MediaTitle("Seinfeld")},
+		TvShowSeason: screenjournal.TvShowSeason(1),
+	}
+	// Movies added before ScreenJournal stored runtimes have no runtime.
+	noRuntime := screenjournal.Review{
+		ID:      screenjournal.ReviewID(7),
+		Owner:   screenjournal.Username("alice"),
+		Watched: screenjournal.WatchDate(inYear),
+		Movie: screenjournal.Movie{
+			ID:    screenjournal.MovieID(15),
+			Title: screenjournal.MediaTitle("Happy Gilmore"),
+		},
+	}
+	lastYear := screenjournal.Review{
+		ID:      screenjournal.ReviewID(4),
+		Owner:   screenjournal.Username("alice"),
+		Rating:  screenjournal.NewRating(10),
+		Watched: screenjournal.WatchDate(beforeYear),
+		Movie: screenjournal.Movie{
+			ID:      screenjournal.MovieID(12),
+			Title:   screenjournal.MediaTitle("Billy Madison"),
+			Runtime: 89 * time.Minute,
+		},
+	}
+	bobReview := screenjournal.Review{
+		ID:      screenjournal.ReviewID(5),
+		Owner:   screenjournal.Username("bob"),
+		Watched: screenjournal.WatchDate(inYear),
+		Movie:   screenjournal.Movie{ID: screenjournal.MovieID(13)},
+		Comments: []screenjournal.ReviewComment{
+			{Owner: screenjournal.Username("alice"), Created: inYear},
+		},
+		Reactions: []screenjournal.ReviewReaction{
+			{Owner: screenjournal.Username("alice"), Emoji: screenjournal.NewReactionEmoji("🥞"), Created: inYear},
+			{Owner: screenjournal.Username("carol"), Emoji: screenjournal.NewReactionEmoji("👍"), Created: inYear},
+		},
+	}
+	carolReview := screenjournal.Review{
+		ID:      screenjournal.ReviewID(6),
+		Owner:   screenjournal.Username("carol"),
+		Watched: screenjournal.WatchDate(inYear),
+		Movie:   screenjournal.Movie{ID: screenjournal.MovieID(14)},
+		Reactions: []screenjournal.ReviewReaction{
+			{Owner: screenjournal.Username("alice"), Emoji: screenjournal.NewReactionEmoji("🥞"), Created: inYear},
+			// Reactions from other years don't count.
+			{Owner: screenjournal.Username("alice"), Emoji: screenjournal.NewReactionEmoji("👀"), Created: beforeYear},
+		},
+	}
+	formerMemberReview := screenjournal.Review{
+		ID:      screenjournal.ReviewID(8),
+		Owner:   screenjournal.FormerMemberUsername,
+		Watched: screenjournal.WatchDate(inYear),
+		Movie:   screenjournal.Movie{ID: screenjournal.MovieID(16)},
+		Comments: []screenjournal.ReviewComment{
+			{Owner: screenjournal.Username("alice"), Created: inYear},
+			{Owner: screenjournal.Username("alice"), Created: inYear},
+			{Owner: screenjournal.Username("alice"), Created: inYear},
+		},
+	}
+
+	for _, tt := range []struct {
+		description          string
+		username             screenjournal.Username
+		reviews              []screenjournal.Review
+		titlesWatched        []screenjournal.ReviewID
+		movieTimeWatched     time.Duration
+		moviesWithoutRuntime int
+		highestRated         screenjournal.ReviewID
+		lowestRated          screenjournal.ReviewID
+		mostCommented        screenjournal.ReviewID
+		mostCommentedCount   int
+		favoriteReviewer     screenjournal.Username
+		favoriteInteractions int
+		favoriteEmoji        string
+		favoriteEmojiCount   int
+	}{
+		{
+			description:          "summarizes a year of reviews and interactions",
+			username:             screenjournal.Username("alice"),
+			reviews:              []screenjournal.Review{theMatrix, waterboy, unrated, noRuntime, lastYear, bobReview, carolReview},
+			titlesWatched:        []screenjournal.ReviewID{1, 2, 3, 7},
+			movieTimeWatched:     226 * time.Minute,
+			moviesWithoutRuntime: 1,
+			highestRated:         screenjournal.ReviewID(1),
+			lowestRated:          screenjournal.ReviewID(2),
+			mostCommented:        screenjournal.ReviewID(1),
+			mostCommentedCount:   2,
+			favoriteReviewer:     screenjournal.Username("bob"),
+			favoriteInteractions: 2,
+			favoriteEmoji:        "🥞",
+			favoriteEmojiCount:   2,
+		},
+		{
+			description:          "doesn't pick a former member as favorite reviewer",
+			username:             screenjournal.Username("alice"),
+			reviews:              []screenjournal.Review{theMatrix, bobReview, formerMemberReview},
+			titlesWatched:        []screenjournal.ReviewID{1},
+			movieTimeWatched:     136 * time.Minute,
+			highestRated:         screenjournal.ReviewID(1),
+			lowestRated:          screenjournal.ReviewID(1),
+			mostCommented:        screenjournal.ReviewID(1),
+			mostCommentedCount:   2,
+			favoriteReviewer:     screenjournal.Username("bob"),
+			favoriteInteractions: 2,
+			favoriteEmoji:        "🥞",
+			favoriteEmojiCount:   1,
+		},
+		{
+			description:   "returns an empty recap for a user with no activity",
+			username:      screenjournal.Username("dave"),
+			reviews:       []screenjournal.Review{theMatrix, bobReview},
+			titlesWatched: []screenjournal.ReviewID{},
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			r := recap.Build(tt.username, 2024, tt.reviews)
+
+			titles := []screenjournal.ReviewID{}
+			for _, review := range r.TitlesWatched {
+				titles = append(titles, review.ID)
+			}
+			if got, want := len(titles), len(tt.titlesWatched); got != want {
+				t.Fatalf("len(TitlesWatched)=%d, want=%d", got, want)
+			}
+			for i := range titles {
+				if got, want := titles[i], tt.titlesWatched[i]; got != want {
+					t.Errorf("TitlesWatched[%d]=%v, want=%v", i, got, want)
+				}
+			}
+			if got, want := r.MovieTimeWatched, tt.movieTimeWatched; got != want {
+				t.Errorf("MovieTimeWatched=%v, want=%v", got, want)
+			}
+			if got, want := r.MoviesWithoutRuntime, tt.moviesWithoutRuntime; got != want {
+				t.Errorf("MoviesWithoutRuntime=%d, want=%d", got, want)
+			}
+			if got, want := r.HighestRated.ID, tt.highestRated; got != want {
+				t.Errorf("HighestRated=%v, want=%v", got, want)
+			}
+			if got, want := r.LowestRated.ID, tt.lowestRated; got != want {
+				t.Errorf("LowestRated=%v, want=%v", got, want)
+			}
+			if got, want := r.MostCommented.ID, tt.mostCommented; got != want {
+				t.Errorf("MostCommented=%v, want=%v", got, want)
+			}
+			if got, want := r.MostCommentedCount, tt.mostCommentedCount; got != want {
+				t.Errorf("MostCommentedCount=%d, want=%d", got, want)
+			}
+			if got, want := r.FavoriteReviewer, tt.favoriteReviewer; got != want {
+				t.Errorf("FavoriteReviewer=%v, want=%v", got, want)
+			}
+			if got, want := r.FavoriteReviewerInteractions, tt.favoriteInteractions; got != want {
+				t.Errorf("FavoriteReviewerInteractions=%d, want=%d", got, want)
+			}
+			if got, want := r.FavoriteEmoji.String(), tt.favoriteEmoji; got != want {
+				t.Errorf("FavoriteEmoji=%v, want=%v", got, want)
+			}
+			if got, want := r.FavoriteEmojiCount, tt.favoriteEmojiCount; got != want {
+				t.Errorf("FavoriteEmojiCount=%d, want=%d", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// Package recommend suggests titles to watch using item-based collaborative
+// filtering: two titles are similar if users tend to rate them the same way
+// relative to their own average ratings, and a user's predicted rating for a
+// new title is a similarity-weighted average of their ratings of similar
+// titles they've already rated.
+package recommend
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/mtlynch/screenjournal/v2/compatibility"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+const (
+	// fanRatingThreshold is the minimum rating at which we consider a user to
+	// be a fan of a title.
+	fanRatingThreshold = 8
+	// minCommonRaters is the minimum number of users who must have rated two
+	// titles before we trust the similarity between them.
+	minCommonRaters = 2
+	// maxBecauseYouLiked is the maximum number of titles to cite as reasons for
+	// a recommendation.
+	maxBecauseYouLiked = 2
+
+	maxRating = 10
+)
+
+type (
+	// ratingMatrix maps each user to their ratings, keyed by title.
+	ratingMatrix map[screenjournal.Username]map[string]screenjournal.Review
+
+	neighbor struct {
+		review     screenjournal.Review
+		similarity float64
+	}
+)
+
+// ForUser returns up to limit recommendations for the given user, best first.
+// The reviews should include every review on the server. Titles that the user
+// has already reviewed are never recommended.
+func ForUser(username screenjournal.Username, reviews []screenjournal.Review, limit int) []screenjournal.Recommendation {
+	ratings := ratingMatrix{}
+	alreadyReviewed := map[string]bool{}
+	for _, r := range reviews {
+		if r.Owner.Equal(username) {
+			alreadyReviewed[r.TitleKey()] = true
+		}
+		// Reviews from every former member share one placeholder owner, so
+		// together they don't reflect any one person's taste.
+		if r.Owner.Equal(screenjournal.FormerMemberUsername) {
+			continue
+		}
+		if _, ok := ratings[r.Owner]; !ok {
+			ratings[r.Owner] = compatibility.LatestRatingsByTitle(r.Owner, reviews)
+		}
+	}
+
+	userRatings := ratings[username]
+	if len(userRatings) == 0 {
+		return []screenjournal.Recommendation{}
+	}
+	means := ratings.userMeans()
+
+	agreements := map[screenjournal.Username]screenjournal.Compatibility{}
+	for u := range ratings {
+		if !u.Equal(username) {
+			agreements[u] = compatibility.Compare(username, u, reviews)
+		}
+	}
+
+	candidates := map[string]screenjournal.Review{}
+	for owner, rr := range ratings {
+		if owner.Equal(username) {
+			continue
+		}
+		for key, r := range rr {
+			if !alreadyReviewed[key] {
+				candidates[key] = r
+			}
+		}
+	}
+
+	recs := []screenjournal.Recommendation{}
+	for key, title := range candidates {
+		neighbors := []neighbor{}
+		for ratedKey, ratedReview := range userRatings {
+			sim := ratings.similarity(key, ratedKey, means)
+			if sim > 0 {
+				neighbors = append(neighbors, neighbor{review: ratedReview, similarity: sim})
+			}
+		}
+		if len(neighbors) == 0 {
+			continue
+		}
+
+		var weightedSum, totalWeight float64
+		for _, n := range neighbors {
+			weightedSum += n.similarity * (float64(n.review.Rating.UInt8()) - means[username])
+			totalWeight += n.similarity
+		}
+		predicted := means[username] + weightedSum/totalWeight
+		// Only recommend titles we expect the user to like more than average.
+		if predicted < means[username] {
+			continue
+		}
+		predicted = math.Min(maxRating, predicted)
+
+		rec := screenjournal.Recommendation{
+			Title:           title,
+			PredictedRating: predicted,
+			BecauseYouLiked: becauseYouLiked(neighbors),
+		}
+		addFans(&rec, username, key, ratings, agreements)
+		recs = append(recs, rec)
+	}
+
+	slices.SortFunc(recs, func(a, b screenjournal.Recommendation) int {
+		if a.PredictedRating != b.PredictedRating {
+			if a.PredictedRating > b.PredictedRating {
+				return -1
+			}
+			return 1
+		}
+		if d := len(b.Fans) - len(a.Fans); d != 0 {
+			return d
+		}
+		return strings.Compare(a.Title.TitleKey(), b.Title.TitleKey())
+	})
+
+	if len(recs) > limit {
+		recs = recs[:limit]
+	}
+	return recs
+}
+
+func (m ratingMatrix) userMeans() map[screenjournal.Username]float64 {
+	means := map[screenjournal.Username]float64{}
+	for u, rr := range m {
+		if len(rr) == 0 {
+			continue
+		}
+		var sum float64
+		for _, r := range rr {
+			sum += float64(r.Rating.UInt8())
+		}
+		means[u] = sum / float64(len(rr))
+	}
+	return means
+}
+
+// similarity calculates the adjusted cosine similarity between two titles,
+// which compares each user's ratings against that user's average rating so
+// that generous and harsh raters are treated alike.
+func (m ratingMatrix) similarity(a, b string, means map[screenjournal.Username]float64) float64 {
+	var dot, normA, normB float64
+	commonRaters := 0
+	for u, rr := range m {
+		ra, okA := rr[a]
+		rb, okB := rr[b]
+		if !okA || !okB {
+			continue
+		}
+		commonRaters++
+		da := float64(ra.Rating.UInt8()) - means[u]
+		db := float64(rb.Rating.UInt8()) - means[u]
+		dot += da * db
+		normA += da * da
+		normB += db * db
+	}
+	if commonRaters < minCommonRaters || normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+func becauseYouLiked(neighbors []neighbor) []screenjournal.Review {
+	slices.SortFunc(neighbors, func(a, b neighbor) int {
+		if a.similarity != b.similarity {
+			if a.similarity > b.similarity {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.review.TitleKey(), b.review.TitleKey())
+	})
+
+	liked := []screenjournal.Review{}
+	for _, n := range neighbors {
+		if n.review.Rating.UInt8() < fanRatingThreshold {
+			continue
+		}
+		liked = append(liked, n.review)
+		if len(liked) == maxBecauseYouLiked {
+			break
+		}
+	}
+	return liked
+}
+
+func addFans(rec *screenjournal.Recommendation, username screenjournal.Username, key string, ratings ratingMatrix, agreements map[screenjournal.Username]screenjournal.Compatibility) {
+	type fan struct {
+		username  screenjournal.Username
+		agreement screenjournal.Compatibility
+	}
+	fans := []fan{}
+	for u, rr := range ratings {
+		r, ok := rr[key]
+		if !ok || u.Equal(username) || r.Rating.UInt8() < fanRatingThreshold {
+			continue
+		}
+		fans = append(fans, fan{
+			username:  u,
+			agreement: agreements[u],
+		})
+		if rec.FansLowestRating.IsNil() || r.Rating.UInt8() < rec.FansLowestRating.UInt8() {
+			rec.FansLowestRating = r.Rating
+		}
+	}
+
+	slices.SortFunc(fans, func(a, b fan) int {
+		if d := b.agreement.AgreementPercent - a.agreement.AgreementPercent; d != 0 {
+			return d
+		}
+		return strings.Compare(a.username.String(), b.username.String())
+	})
+
+	rec.Fans = make([]screenjournal.Username, len(fans))
+	totalAgreement, comparable := 0, 0
+	for i, f := range fans {
+		rec.Fans[i] = f.username
+		if !f.agreement.Empty() {
+			totalAgreement += f.agreement.AgreementPercent
+			comparable++
+		}
+	}
+	if comparable > 0 {
+		rec.FansAgreementPercent = int(math.Round(float64(totalAgreement) / float64(comparable)))
+	}
+}
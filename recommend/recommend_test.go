@@ -0,0 +1,146 @@
+package recommend_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/recommend"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+func makeReview(owner string, movieID int64, rating uint8) screenjournal.Review {
+	r := screenjournal.Review{
+		Owner: screenjournal.Username(owner),
+		Movie: screenjournal.Movie{
+			ID: screenjournal.MovieID(movieID),
+		},
+	}
+	if rating > 0 {
+		r.Rating = screenjournal.NewRating(rating)
+	}
+	return r
+}
+
+func TestForUser(t *testing.T) {
+	groupReviews := []screenjournal.Review{
+		makeReview("alice", 1, 9),
+		makeReview("alice", 2, 2),
+		// Alice reviewed movie 6 without rating it, so it's not a candidate.
+		makeReview("alice", 6, 0),
+		makeReview("bob", 1, 9),
+		makeReview("bob", 2, 3),
+		makeReview("bob", 4, 10),
+		makeReview("bob", 6, 10),
+		makeReview("carol", 1, 8),
+		makeReview("carol", 2, 2),
+		makeReview("carol", 4, 9),
+		makeReview("carol", 5, 2),
+		makeReview("carol", 6, 10),
+		makeReview("dave", 1, 2),
+		makeReview("dave", 2, 9),
+		makeReview("dave", 5, 9),
+	}
+
+	for _, tt := range []struct {
+		explanation      string
+		username         string
+		reviews          []screenjournal.Review
+		limit            int
+		movieIDs         []screenjournal.MovieID
+		predictedRating  float64
+		fans             []screenjournal.Username
+		fansLowestRating screenjournal.Rating
+		fansAgreement    int
+		becauseYouLiked  []screenjournal.MovieID
+	}{
+		{
+			"recommends titles that similar titles' fans loved",
+			"alice",
+			groupReviews,
+			10,
+			[]screenjournal.MovieID{4},
+			9,
+			[]screenjournal.Username{"bob", "carol"},
+			screenjournal.NewRating(9),
+			94,
+			[]screenjournal.MovieID{1},
+		},
+		{
+			"ignores reviews from former members",
+			"alice",
+			append([]screenjournal.Review{
+				makeReview(screenjournal.FormerMemberUsername.String(), 1, 9),
+				makeReview(screenjournal.FormerMemberUsername.String(), 2, 2),
+				makeReview(screenjournal.FormerMemberUsername.String(), 4, 10),
+			}, groupReviews...),
+			10,
+			[]screenjournal.MovieID{4},
+			9,
+			[]screenjournal.Username{"bob", "carol"},
+			screenjournal.NewRating(9),
+			94,
+			[]screenjournal.MovieID{1},
+		},
+		{
+			"user with no ratings gets no recommendations",
+			"erin",
+			groupReviews,
+			10,
+			[]screenjournal.MovieID{},
+			0,
+			nil,
+			screenjournal.Rating{},
+			0,
+			nil,
+		},
+		{
+			"respects limit",
+			"alice",
+			groupReviews,
+			0,
+			[]screenjournal.MovieID{},
+			0,
+			nil,
+			screenjournal.Rating{},
+			0,
+			nil,
+		},
+	} {
+		t.Run(tt.explanation, func(t *testing.T) {
+			recs := recommend.ForUser(screenjournal.Username(tt.username), tt.reviews, tt.limit)
+
+			movieIDs := []screenjournal.MovieID{}
+			for _, rec := range recs {
+				movieIDs = append(movieIDs, rec.Title.Movie.ID)
+			}
+			if got, want := movieIDs, tt.movieIDs; !reflect.DeepEqual(got, want) {
+				t.Fatalf("movieIDs=%v, want=%v", got, want)
+			}
+			if len(recs) == 0 {
+				return
+			}
+
+			top := recs[0]
+			if got, want := top.PredictedRating, tt.predictedRating; math.Abs(got-want) > 1e-9 {
+				t.Errorf("predictedRating=%v, want=%v", got, want)
+			}
+			if got, want := top.Fans, tt.fans; !reflect.DeepEqual(got, want) {
+				t.Errorf("fans=%v, want=%v", got, want)
+			}
+			if got, want := top.FansLowestRating, tt.fansLowestRating; !got.Equal(want) {
+				t.Errorf("fansLowestRating=%v, want=%v", got, want)
+			}
+			if got, want := top.FansAgreementPercent, tt.fansAgreement; got != want {
+				t.Errorf("fansAgreementPercent=%d, want=%d", got, want)
+			}
+			liked := []screenjournal.MovieID{}
+			for _, r := range top.BecauseYouLiked {
+				liked = append(liked, r.Movie.ID)
+			}
+			if got, want := liked, tt.becauseYouLiked; !reflect.DeepEqual(got, want) {
+				t.Errorf("becauseYouLiked=%v, want=%v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package screenjournal
+
+// AccountDeletionMode controls what happens to a deleted user's reviews,
+// comments, and reactions.
+type AccountDeletionMode string
+
+const (
+	// AccountDeletionDeleteContent removes the user's reviews, comments, and
+	// reactions along with their account.
+	AccountDeletionDeleteContent = AccountDeletionMode("delete")
+	// AccountDeletionAnonymizeContent keeps the user's reviews, comments, and
+	// reactions but attributes them to FormerMemberUsername.
+	AccountDeletionAnonymizeContent = AccountDeletionMode("anonymize")
+)
+
+// FormerMemberUsername is the placeholder account that owns anonymized
+// content from deleted users. Nobody can sign up or log in with it.
+const FormerMemberUsername = Username("former.member")
+
+func (m AccountDeletionMode) String() string {
+	return string(m)
+}
@@ -11,6 +11,10 @@ func (id CommentID) UInt64() uint64 {
 	return uint64(id)
 }
 
+func (id CommentID) IsZero() bool {
+	return id == CommentID(0)
+}
+
 func (id CommentID) String() string {
 	return strconv.FormatUint(id.UInt64(), 10)
 }
@@ -0,0 +1,42 @@
+package screenjournal
+
+// Compatibility measures how closely two users' ratings agree on the titles
+// they've both rated.
+type Compatibility struct {
+	Username      Username
+	OtherUsername Username
+	// SharedTitles contains both users' reviews of each title they've both
+	// rated, ordered from the largest disagreement to the smallest.
+	SharedTitles []SharedRating
+	// Correlation is the Pearson correlation coefficient of the two users'
+	// ratings, or nil if it's undefined because there are too few shared
+	// titles or one user gave every shared title the same rating.
+	Correlation *float64
+	// MeanAbsoluteDifference is the average number of points by which the two
+	// users' ratings differ on shared titles.
+	MeanAbsoluteDifference float64
+	// AgreementPercent expresses MeanAbsoluteDifference as a score from 0 to
+	// 100, where 100 means the users gave identical ratings to every shared
+	// title.
+	AgreementPercent int
+}
+
+// SharedRating pairs two users' reviews of the same title.
+type SharedRating struct {
+	Review      Review
+	OtherReview Review
+}
+
+// Difference returns the absolute difference between the two ratings.
+func (sr SharedRating) Difference() int {
+	d := int(sr.Review.Rating.UInt8()) - int(sr.OtherReview.Rating.UInt8())
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// Empty returns true if the users have no rated titles in common.
+func (c Compatibility) Empty() bool {
+	return len(c.SharedTitles) == 0
+}
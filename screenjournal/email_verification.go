@@ -0,0 +1,14 @@
+package screenjournal
+
+import "time"
+
+// EmailVerification is an emailed link that proves the user receives mail at
+// Email. If Email is different from the user's current address, confirming it
+// changes the user's address. Email verifications use the same tokens as
+// password resets.
+type EmailVerification struct {
+	Username  Username
+	Email     Email
+	Token     PasswordResetToken
+	ExpiresAt time.Time
+}
@@ -0,0 +1,64 @@
+package screenjournal
+
+import (
+	"strconv"
+	"time"
+)
+
+type (
+	FriendRecommendationID     uint64
+	FriendRecommendationStatus string
+	RecommendationMessage      string
+
+	// FriendRecommendation is a title that one user suggested directly to
+	// another user.
+	FriendRecommendation struct {
+		ID           FriendRecommendationID
+		From         Username
+		To           Username
+		Movie        Movie
+		TvShow       TvShow
+		TvShowSeason TvShowSeason
+		// Message is an optional note from the sender.
+		Message RecommendationMessage
+		Status  FriendRecommendationStatus
+		Created time.Time
+	}
+)
+
+const (
+	FriendRecommendationPending   = FriendRecommendationStatus("pending")
+	FriendRecommendationWatched   = FriendRecommendationStatus("watched")
+	FriendRecommendationDismissed = FriendRecommendationStatus("dismissed")
+)
+
+func (id FriendRecommendationID) UInt64() uint64 {
+	return uint64(id)
+}
+
+func (id FriendRecommendationID) String() string {
+	return strconv.FormatUint(id.UInt64(), 10)
+}
+
+func (s FriendRecommendationStatus) String() string {
+	return string(s)
+}
+
+func (m RecommendationMessage) String() string {
+	return string(m)
+}
+
+func (r FriendRecommendation) MediaType() MediaType {
+	if !r.Movie.ID.IsZero() {
+		return MediaTypeMovie
+	}
+	return MediaTypeTvShow
+}
+
+// MediaTitle returns the title of the recommended movie or TV show.
+func (r FriendRecommendation) MediaTitle() MediaTitle {
+	if r.MediaType() == MediaTypeMovie {
+		return r.Movie.Title
+	}
+	return r.TvShow.Title
+}
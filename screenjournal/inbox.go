@@ -0,0 +1,49 @@
+package screenjournal
+
+import (
+	"strconv"
+	"time"
+)
+
+type (
+	NotificationID   uint64
+	NotificationKind string
+
+	// Notification is an in-app notice to a user about activity that involves
+	// them.
+	Notification struct {
+		ID        NotificationID
+		Recipient Username
+		// Actor is the user whose activity triggered the notification.
+		Actor  Username
+		Kind   NotificationKind
+		Review Review
+		// Comment is the comment that triggered the notification. If the comment
+		// ID is zero, the notification relates to the review itself.
+		Comment ReviewComment
+		// Emoji is the reaction emoji for reaction notifications.
+		Emoji   ReactionEmoji
+		Read    bool
+		Created time.Time
+	}
+)
+
+const (
+	NotificationKindNewReview = NotificationKind("new-review")
+	NotificationKindComment   = NotificationKind("comment")
+	NotificationKindReply     = NotificationKind("reply")
+	NotificationKindReaction  = NotificationKind("reaction")
+	NotificationKindMention   = NotificationKind("mention")
+)
+
+func (id NotificationID) UInt64() uint64 {
+	return uint64(id)
+}
+
+func (id NotificationID) String() string {
+	return strconv.FormatUint(id.UInt64(), 10)
+}
+
+func (k NotificationKind) String() string {
+	return string(k)
+}
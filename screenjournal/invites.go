@@ -2,6 +2,7 @@ package screenjournal
 
 import (
 	"regexp"
+	"time"
 
 	"github.com/mtlynch/screenjournal/v2/random"
 )
@@ -13,11 +14,48 @@ type (
 	SignupInvitation struct {
 		Invitee    Invitee
 		InviteCode InviteCode
+		// Email is the address the invite was sent to, or empty if the inviter
+		// shared the link themselves.
+		Email Email
+		// InvitedBy is empty if the invite predates tracking who created
+		// invites or if its creator deleted their account.
+		InvitedBy Username
+		Created   time.Time
+		// Expires is the zero time if the invite never expires.
+		Expires time.Time
+		// MaxUses is the number of accounts that can sign up with the invite.
+		MaxUses     int
+		Redemptions []InviteRedemption
+	}
+
+	// InviteRedemption records an account that signed up with an invite.
+	InviteRedemption struct {
+		Username Username
+		Redeemed time.Time
+	}
+
+	// MemberInvitePolicy controls whether users who aren't admins may create
+	// invites.
+	MemberInvitePolicy struct {
+		Enabled bool
+		// Limit is the total number of invites each member may create.
+		Limit int
 	}
 )
 
 const (
 	InviteCodeLength = 6
+
+	// MaxInviteUses is the most accounts an admin can let sign up with a
+	// single invite.
+	MaxInviteUses = 100
+
+	// MaxMemberInviteLimit is the most invites an admin can let each member
+	// create.
+	MaxMemberInviteLimit = 100
+
+	// MemberInviteLifetime is how long an invite that a member creates lasts.
+	MemberInviteLifetime = 7 * 24 * time.Hour
 )
 
 var (
@@ -59,3 +97,18 @@ func (ic InviteCode) Equal(other InviteCode) bool {
 func (si SignupInvitation) Empty() bool {
 	return si.Invitee.Empty()
 }
+
+// Expired returns true if the invite has an expiration time that has passed.
+func (si SignupInvitation) Expired(now time.Time) bool {
+	return !si.Expires.IsZero() && !now.Before(si.Expires)
+}
+
+// RemainingUses returns how many more accounts can sign up with the invite.
+func (si SignupInvitation) RemainingUses() int {
+	return max(si.MaxUses-len(si.Redemptions), 0)
+}
+
+// Redeemable returns true if someone can still sign up with the invite.
+func (si SignupInvitation) Redeemable(now time.Time) bool {
+	return !si.Expired(now) && si.RemainingUses() > 0
+}
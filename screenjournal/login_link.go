@@ -0,0 +1,11 @@
+package screenjournal
+
+import "time"
+
+// LoginLinkEntry is an emailed link that logs a user in without their
+// password. Login links use the same tokens as password resets.
+type LoginLinkEntry struct {
+	Username  Username
+	Token     PasswordResetToken
+	ExpiresAt time.Time
+}
@@ -0,0 +1,35 @@
+package screenjournal
+
+import "time"
+
+type (
+	// LoginThrottleScope is what a login throttle counts failures against.
+	LoginThrottleScope string
+
+	// LoginThrottle tracks recent failed logins for a single username or IP
+	// address.
+	LoginThrottle struct {
+		Scope         LoginThrottleScope
+		Key           string
+		Failures      int
+		LastFailureAt time.Time
+		// BlockedUntil is when the next login attempt is allowed. It's zero if
+		// there's no block.
+		BlockedUntil time.Time
+	}
+)
+
+const (
+	LoginThrottleScopeUsername = LoginThrottleScope("username")
+	LoginThrottleScopeIP       = LoginThrottleScope("ip")
+)
+
+func (s LoginThrottleScope) String() string {
+	return string(s)
+}
+
+// IsBlocked reports whether the throttle rejects login attempts at the given
+// time.
+func (t LoginThrottle) IsBlocked(now time.Time) bool {
+	return now.Before(t.BlockedUntil)
+}
@@ -0,0 +1,11 @@
+package screenjournal
+
+// Mention is a reference to a user by @username in a review or comment.
+type Mention struct {
+	Mentioned Username
+	Author    Username
+	Review    Review
+	// Comment is the comment that contains the mention. If the comment ID is
+	// zero, the mention is in the review's blurb.
+	Comment ReviewComment
+}
@@ -3,6 +3,7 @@ package screenjournal
 import (
 	"net/url"
 	"strconv"
+	"time"
 )
 
 type (
@@ -16,6 +17,8 @@ type (
 		Title       MediaTitle
 		ReleaseDate ReleaseDate
 		PosterPath  url.URL
+		// Runtime is the length of the movie, or zero if it's unknown.
+		Runtime time.Duration
 	}
 )
 
@@ -1,6 +1,70 @@
 package screenjournal
 
-type NotificationPreferences struct {
-	NewReviews     bool
-	AllNewComments bool
+import "time"
+
+type (
+	NotificationPreferences struct {
+		NewReviews bool
+		// NewReviewsScope controls whose reviews the user hears about when
+		// NewReviews is enabled.
+		NewReviewsScope NotificationScope
+		AllNewComments  bool
+		// Replies controls emails about direct replies to the user's comments.
+		// A reply only follows this preference, even if the user also receives
+		// all new comments.
+		Replies  bool
+		Mentions bool
+		// Digest controls how often the user receives emails about new reviews
+		// and comments.
+		Digest DigestFrequency
+	}
+
+	DigestFrequency string
+
+	// NotificationScope is the set of users whose activity triggers a
+	// notification.
+	NotificationScope string
+
+	// DigestSubscriber is a user who receives new reviews and comments in a
+	// periodic summary email.
+	DigestSubscriber struct {
+		EmailSubscriber
+		Preferences NotificationPreferences
+		// LastDigest is when the user last received a digest, or the zero time
+		// if they never have.
+		LastDigest time.Time
+	}
+)
+
+const (
+	DigestImmediate = DigestFrequency("immediate")
+	DigestDaily     = DigestFrequency("daily")
+	DigestWeekly    = DigestFrequency("weekly")
+	DigestOff       = DigestFrequency("off")
+)
+
+const (
+	NotifyEveryone  = NotificationScope("everyone")
+	NotifyFollowing = NotificationScope("following")
+)
+
+func (s NotificationScope) String() string {
+	return string(s)
+}
+
+func (f DigestFrequency) String() string {
+	return string(f)
+}
+
+// Period returns the time between digests, or zero if the frequency doesn't
+// send digests.
+func (f DigestFrequency) Period() time.Duration {
+	switch f {
+	case DigestDaily:
+		return 24 * time.Hour
+	case DigestWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
 }
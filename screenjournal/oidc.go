@@ -0,0 +1,27 @@
+package screenjournal
+
+import "time"
+
+type (
+	// OidcIdentity links a user to their account at an external OpenID Connect
+	// identity provider.
+	OidcIdentity struct {
+		// Issuer and Subject together identify the account at the identity
+		// provider. Subjects are only unique within an issuer.
+		Issuer      string
+		Subject     string
+		Username    Username
+		CreatedTime time.Time
+	}
+
+	// OidcLoginState is the secret state for a login in progress at an
+	// identity provider. It's single-use and short-lived.
+	OidcLoginState struct {
+		State        string
+		Nonce        string
+		CodeVerifier string
+		// NextPath is where to send the user after they log in.
+		NextPath  string
+		ExpiresAt time.Time
+	}
+)
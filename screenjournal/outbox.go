@@ -0,0 +1,62 @@
+package screenjournal
+
+import (
+	"net/mail"
+	"strconv"
+	"time"
+)
+
+type (
+	OutboxEmailID uint64
+
+	// OutboxEmailKey identifies the event and recipients of an outbox email so
+	// that the same message is only queued once.
+	OutboxEmailKey string
+
+	// OutboxEmailStatus is where an outbox email is in its lifecycle.
+	OutboxEmailStatus string
+
+	// OutboxEmail is an email message that's waiting to be sent, has been
+	// sent, or has failed so many times that ScreenJournal gave up on it.
+	OutboxEmail struct {
+		ID          OutboxEmailID
+		Key         OutboxEmailKey
+		From        mail.Address
+		To          []mail.Address
+		Subject     string
+		TextBody    string
+		HtmlBody    string
+		Headers     mail.Header
+		Status      OutboxEmailStatus
+		Attempts    int
+		NextAttempt time.Time
+		// LastError describes why the most recent attempt failed, or is empty
+		// if no attempt has failed.
+		LastError string
+		Created   time.Time
+	}
+)
+
+const (
+	OutboxEmailPending = OutboxEmailStatus("pending")
+	OutboxEmailSent    = OutboxEmailStatus("sent")
+	// OutboxEmailFailed means the email is in the dead-letter queue and
+	// won't be sent unless an admin retries it.
+	OutboxEmailFailed = OutboxEmailStatus("failed")
+)
+
+func (id OutboxEmailID) UInt64() uint64 {
+	return uint64(id)
+}
+
+func (id OutboxEmailID) String() string {
+	return strconv.FormatUint(id.UInt64(), 10)
+}
+
+func (k OutboxEmailKey) String() string {
+	return string(k)
+}
+
+func (s OutboxEmailStatus) String() string {
+	return string(s)
+}
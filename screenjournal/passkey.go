@@ -0,0 +1,76 @@
+package screenjournal
+
+import (
+	"encoding/base64"
+	"time"
+)
+
+type (
+	// PasskeyID is a WebAuthn credential ID in unpadded base64url encoding,
+	// the same form that browsers use for a credential's id property.
+	PasskeyID string
+
+	// PasskeyName is the user's label for a passkey, such as the device that
+	// holds it.
+	PasskeyName string
+
+	// Passkey is a WebAuthn credential that a user can log in with instead of a
+	// password.
+	Passkey struct {
+		ID        PasskeyID
+		Username  Username
+		Name      PasskeyName
+		PublicKey []byte
+		// SignCount is the authenticator's signature counter from the most
+		// recent login, which helps detect cloned authenticators.
+		SignCount   uint32
+		CreatedTime time.Time
+		// LastUsedTime is zero if the user has never logged in with the passkey.
+		LastUsedTime time.Time
+	}
+
+	// PasskeyCeremony is the WebAuthn operation that a challenge is for.
+	PasskeyCeremony string
+
+	// PasskeyChallenge is a single-use random value that the user's
+	// authenticator signs to prove that a registration or login is fresh.
+	PasskeyChallenge struct {
+		Challenge []byte
+		Ceremony  PasskeyCeremony
+		// Username is the user registering a passkey. It's empty for logins,
+		// where the server doesn't know who the user is until they pick a
+		// passkey.
+		Username  Username
+		ExpiresAt time.Time
+	}
+)
+
+const (
+	PasskeyCeremonyRegistration = PasskeyCeremony("registration")
+	PasskeyCeremonyLogin        = PasskeyCeremony("login")
+
+	// PasskeyNameMaxLength is the longest name a user can give a passkey.
+	PasskeyNameMaxLength = 60
+)
+
+// NewPasskeyID encodes a raw WebAuthn credential ID.
+func NewPasskeyID(credentialID []byte) PasskeyID {
+	return PasskeyID(base64.RawURLEncoding.EncodeToString(credentialID))
+}
+
+// CredentialID decodes the raw WebAuthn credential ID.
+func (id PasskeyID) CredentialID() ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(string(id))
+}
+
+func (id PasskeyID) String() string {
+	return string(id)
+}
+
+func (n PasskeyName) String() string {
+	return string(n)
+}
+
+func (c PasskeyCeremony) String() string {
+	return string(c)
+}
@@ -12,15 +12,38 @@ type (
 		value string
 	}
 
+	// ReactionTargetType is the kind of item that a reaction is attached to.
+	ReactionTargetType string
+
 	ReviewReaction struct {
 		ID      ReactionID
 		Owner   Username
 		Emoji   ReactionEmoji
 		Created time.Time
 		Review  Review
+		// Comment is the comment on Review that the reaction targets. If the
+		// comment ID is zero, the reaction targets the review itself.
+		Comment ReviewComment
 	}
 )
 
+const (
+	ReactionTargetReview  = ReactionTargetType("review")
+	ReactionTargetComment = ReactionTargetType("comment")
+)
+
+// TargetType returns whether the reaction is on a review or on a comment.
+func (rr ReviewReaction) TargetType() ReactionTargetType {
+	if rr.Comment.ID.IsZero() {
+		return ReactionTargetReview
+	}
+	return ReactionTargetComment
+}
+
+func (t ReactionTargetType) String() string {
+	return string(t)
+}
+
 func (id ReactionID) UInt64() uint64 {
 	return uint64(id)
 }
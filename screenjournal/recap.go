@@ -0,0 +1,40 @@
+package screenjournal
+
+import "time"
+
+// Recap summarizes a single user's activity over one calendar year.
+type Recap struct {
+	Username Username
+	Year     int
+	// TitlesWatched contains the user's reviews of everything they watched
+	// during the year, most recent first.
+	TitlesWatched []Review
+	// MovieTimeWatched is the total runtime of the movies watched. TV shows
+	// and movies with an unknown runtime don't contribute to the total.
+	MovieTimeWatched time.Duration
+	// MoviesWithoutRuntime is the number of movies watched whose runtime is
+	// unknown, so they're missing from MovieTimeWatched.
+	MoviesWithoutRuntime int
+	HighestRated         Review
+	LowestRated          Review
+	MostCommented        Review
+	// MostCommentedCount is the number of comments other users left on
+	// MostCommented.
+	MostCommentedCount int
+	// FavoriteReviewer is the user whose reviews this user commented on or
+	// reacted to most often during the year.
+	FavoriteReviewer             Username
+	FavoriteReviewerInteractions int
+	FavoriteEmoji                ReactionEmoji
+	FavoriteEmojiCount           int
+}
+
+// MovieHoursWatched returns MovieTimeWatched rounded down to whole hours.
+func (r Recap) MovieHoursWatched() int {
+	return int(r.MovieTimeWatched.Hours())
+}
+
+// Empty returns true if the user had no activity during the year.
+func (r Recap) Empty() bool {
+	return len(r.TitlesWatched) == 0 && r.FavoriteReviewer.Empty() && r.FavoriteEmojiCount == 0
+}
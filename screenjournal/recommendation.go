@@ -0,0 +1,22 @@
+package screenjournal
+
+// Recommendation is a title that a user hasn't reviewed yet but will likely
+// enjoy, based on how other users rated it.
+type Recommendation struct {
+	// Title is another user's review of the recommended title. Only the
+	// title's metadata (movie or TV show and season) is meaningful.
+	Title Review
+	// PredictedRating is the rating we expect the user would give the title.
+	PredictedRating float64
+	// Fans are the other users who rated the title highly, ordered from most
+	// to least compatible with the user.
+	Fans []Username
+	// FansLowestRating is the lowest rating any of the fans gave the title.
+	FansLowestRating Rating
+	// FansAgreementPercent is the average agreement between the user and the
+	// fans, or zero if the user has no titles in common with them.
+	FansAgreementPercent int
+	// BecauseYouLiked contains the user's own highly-rated reviews of the
+	// titles most similar to the recommended title.
+	BecauseYouLiked []Review
+}
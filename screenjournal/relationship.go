@@ -0,0 +1,23 @@
+package screenjournal
+
+type (
+	// UserRelationship is how one user has chosen to treat another user's
+	// activity.
+	UserRelationship string
+)
+
+const (
+	// RelationshipNone is the default for users who haven't followed or muted
+	// another user.
+	RelationshipNone = UserRelationship("")
+	// RelationshipFollow means the user wants to hear about the other user's
+	// reviews even if they only receive reviews from people they follow.
+	RelationshipFollow = UserRelationship("follow")
+	// RelationshipMute means the user doesn't want notifications about the
+	// other user's activity.
+	RelationshipMute = UserRelationship("mute")
+)
+
+func (r UserRelationship) String() string {
+	return string(r)
+}
@@ -33,12 +33,16 @@ type (
 	}
 
 	ReviewComment struct {
-		ID          CommentID
+		ID CommentID
+		// ParentID is the ID of the comment this comment replies to, or zero if
+		// it's a top-level comment on the review.
+		ParentID    CommentID
 		Owner       Username
 		CommentText CommentText
 		Created     time.Time
 		Modified    time.Time
 		Review      Review
+		Reactions   []ReviewReaction
 	}
 )
 
@@ -121,3 +125,12 @@ func (r Review) MediaType() MediaType {
 	}
 	return MediaTypeTvShow
 }
+
+// TitleKey returns a string that uniquely identifies the title under review.
+// Each season of a TV show is a separate title.
+func (r Review) TitleKey() string {
+	if r.MediaType() == MediaTypeMovie {
+		return fmt.Sprintf("movie/%s", r.Movie.ID.String())
+	}
+	return fmt.Sprintf("tv/%s/%d", r.TvShow.ID.String(), r.TvShowSeason.UInt8())
+}
@@ -0,0 +1,51 @@
+package screenjournal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+type (
+	// SessionID is the secret token in a user's session cookie.
+	SessionID string
+
+	// SessionHandle identifies a session without revealing its ID, so that
+	// pages can refer to a session without exposing a token that would let
+	// someone hijack it.
+	SessionHandle string
+
+	// SessionClient describes the browser that started a session.
+	SessionClient struct {
+		UserAgent string
+		IPAddress string
+	}
+
+	// UserSession is a signed-in session for one of the user's browsers.
+	UserSession struct {
+		ID         SessionID
+		Username   Username
+		Client     SessionClient
+		CreatedAt  time.Time
+		LastSeenAt time.Time
+		ExpiresAt  time.Time
+	}
+)
+
+func (id SessionID) String() string {
+	return string(id)
+}
+
+func (id SessionID) Empty() bool {
+	return id == ""
+}
+
+// Handle derives the session's public identifier.
+func (id SessionID) Handle() SessionHandle {
+	sum := sha256.Sum256([]byte(id))
+	return SessionHandle(hex.EncodeToString(sum[:16]))
+}
+
+func (h SessionHandle) String() string {
+	return string(h)
+}
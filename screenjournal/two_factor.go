@@ -0,0 +1,85 @@
+package screenjournal
+
+import (
+	"strings"
+
+	"github.com/mtlynch/screenjournal/v2/random"
+)
+
+type (
+	// TotpSecret is the base32-encoded key that a user's authenticator app
+	// shares with ScreenJournal.
+	TotpSecret string
+
+	// TwoFactor is a user's time-based one-time password configuration. A
+	// configuration that isn't Enabled is awaiting its first verification code.
+	TwoFactor struct {
+		Username Username
+		Secret   TotpSecret
+		Enabled  bool
+		// LastUsedStep is the most recent TOTP time step that the user logged in
+		// with, which prevents replaying a code within its validity window.
+		LastUsedStep int64
+	}
+
+	// RecoveryCode is a one-time code that substitutes for a TOTP code when the
+	// user loses access to their authenticator app.
+	RecoveryCode string
+
+	// RecoveryCodeHash is the hashed form of a recovery code that the store
+	// keeps, so that a database leak doesn't reveal usable codes.
+	RecoveryCodeHash string
+
+	// LoginChallenge proves that a user has already passed the password step of
+	// a two-step login.
+	LoginChallenge string
+)
+
+const (
+	RecoveryCodeCount = 10
+
+	recoveryCodeGroupLength = 5
+)
+
+// RecoveryCodeCharset contains the allowed characters for a recovery code. It
+// excludes characters that are easy to confuse when copied by hand.
+var RecoveryCodeCharset = []rune("abcdefghjkmnpqrstuvwxyz23456789")
+
+func (s TotpSecret) String() string {
+	return string(s)
+}
+
+func (tf TwoFactor) Empty() bool {
+	return tf.Secret == ""
+}
+
+func (c RecoveryCode) String() string {
+	return string(c)
+}
+
+// Normalize returns the code in lowercase without separators or whitespace, so
+// that users can type it however it's easiest.
+func (c RecoveryCode) Normalize() RecoveryCode {
+	return RecoveryCode(strings.Map(func(r rune) rune {
+		switch {
+		case r == '-' || r == ' ':
+			return -1
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return r
+	}, c.String()))
+}
+
+func (h RecoveryCodeHash) String() string {
+	return string(h)
+}
+
+func (c LoginChallenge) String() string {
+	return string(c)
+}
+
+// NewRecoveryCode returns a random recovery code in the form xxxxx-xxxxx.
+func NewRecoveryCode() RecoveryCode {
+	return RecoveryCode(random.String(recoveryCodeGroupLength, RecoveryCodeCharset) + "-" + random.String(recoveryCodeGroupLength, RecoveryCodeCharset))
+}
@@ -0,0 +1,27 @@
+package screenjournal
+
+type (
+	// UnsubscribeCategory is a kind of email that a user can unsubscribe from
+	// without logging in.
+	UnsubscribeCategory string
+
+	// UnsubscribeToken authorizes an unsubscribe request for a single user and
+	// category.
+	UnsubscribeToken string
+)
+
+const (
+	UnsubscribeNewReviews = UnsubscribeCategory("new-reviews")
+	UnsubscribeComments   = UnsubscribeCategory("comments")
+	UnsubscribeReplies    = UnsubscribeCategory("replies")
+	UnsubscribeMentions   = UnsubscribeCategory("mentions")
+	UnsubscribeDigest     = UnsubscribeCategory("digest")
+)
+
+func (c UnsubscribeCategory) String() string {
+	return string(c)
+}
+
+func (t UnsubscribeToken) String() string {
+	return string(t)
+}
@@ -17,9 +17,14 @@ type (
 	PasswordHash []byte
 
 	User struct {
-		IsAdmin      bool
-		Username     Username
-		Email        Email
+		IsAdmin  bool
+		Username Username
+		Email    Email
+		// EmailVerified is true if the user has proven that they receive mail
+		// at Email.
+		EmailVerified bool
+		// IsDisabled is true if an admin has blocked the user from logging in.
+		IsDisabled   bool
 		PasswordHash PasswordHash
 	}
 
@@ -28,6 +33,19 @@ type (
 		JoinDate    time.Time
 		ReviewCount uint
 	}
+
+	// UserAccountMeta is what admins see about a user's account.
+	UserAccountMeta struct {
+		Username   Username
+		Email      Email
+		IsAdmin    bool
+		IsDisabled bool
+		JoinDate   time.Time
+		// LastLogin is the zero time if the user hasn't logged in since
+		// ScreenJournal started tracking logins.
+		LastLogin   time.Time
+		ReviewCount uint
+	}
 )
 
 func (e Email) String() string {
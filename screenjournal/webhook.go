@@ -0,0 +1,94 @@
+package screenjournal
+
+import (
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/random"
+)
+
+type (
+	WebhookID         uint64
+	WebhookURL        string
+	WebhookSecret     string
+	WebhookEvent      string
+	WebhookDeliveryID uint64
+
+	// WebhookDeliveryStatus is where a delivery is in its lifecycle.
+	WebhookDeliveryStatus string
+
+	// Webhook is an external URL that receives a signed POST request whenever
+	// there's new activity on ScreenJournal.
+	Webhook struct {
+		ID  WebhookID
+		URL WebhookURL
+		// Secret is the key that ScreenJournal uses to sign payloads so that the
+		// receiver can verify they're authentic.
+		Secret  WebhookSecret
+		Created time.Time
+	}
+
+	// WebhookDelivery is a single payload queued for delivery to a webhook.
+	WebhookDelivery struct {
+		ID          WebhookDeliveryID
+		Webhook     Webhook
+		Event       WebhookEvent
+		Payload     []byte
+		Status      WebhookDeliveryStatus
+		Attempts    int
+		NextAttempt time.Time
+		// LastResult describes the outcome of the most recent attempt, such as
+		// an HTTP status or a connection error.
+		LastResult string
+		Created    time.Time
+	}
+)
+
+const (
+	WebhookEventNewReview   = WebhookEvent("review.created")
+	WebhookEventNewComment  = WebhookEvent("comment.created")
+	WebhookEventNewReaction = WebhookEvent("reaction.created")
+
+	WebhookDeliveryPending   = WebhookDeliveryStatus("pending")
+	WebhookDeliveryDelivered = WebhookDeliveryStatus("delivered")
+	WebhookDeliveryFailed    = WebhookDeliveryStatus("failed")
+
+	webhookSecretBytes = 32
+)
+
+func NewWebhookSecret() WebhookSecret {
+	return WebhookSecret(hex.EncodeToString(random.Bytes(webhookSecretBytes)))
+}
+
+func (id WebhookID) UInt64() uint64 {
+	return uint64(id)
+}
+
+func (id WebhookID) String() string {
+	return strconv.FormatUint(id.UInt64(), 10)
+}
+
+func (u WebhookURL) String() string {
+	return string(u)
+}
+
+func (s WebhookSecret) String() string {
+	return string(s)
+}
+
+func (e WebhookEvent) String() string {
+	return string(e)
+}
+
+func (id WebhookDeliveryID) UInt64() uint64 {
+	return uint64(id)
+}
+
+func (id WebhookDeliveryID) String() string {
+	return strconv.FormatUint(id.UInt64(), 10)
+}
+
+func (s WebhookDeliveryStatus) String() string {
+	return string(s)
+}
@@ -0,0 +1,247 @@
+// Package sso logs users in through an external OpenID Connect identity
+// provider, linking each identity to a ScreenJournal account.
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/auth"
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/oidc"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+// loginLifetime is how long the user has to finish logging in at the identity
+// provider.
+const loginLifetime = 10 * time.Minute
+
+// maxUsernameAttempts limits how many numeric suffixes we try when a new
+// user's preferred username is taken.
+const maxUsernameAttempts = 100
+
+var (
+	ErrInvalidState = errors.New("invalid or expired login state")
+	ErrLoginFailed  = errors.New("identity provider login failed")
+	ErrNoAccount    = errors.New("no account matches this identity")
+
+	invalidUsernameChars = regexp.MustCompile(`[^a-zA-Z0-9\.]`)
+)
+
+type (
+	Provider interface {
+		Issuer() string
+		AuthCodeURL(context.Context, oidc.AuthRequest) (string, error)
+		Exchange(context.Context, string, oidc.AuthRequest) (oidc.Claims, error)
+	}
+
+	Store interface {
+		InsertOidcLoginState(screenjournal.OidcLoginState) error
+		UseOidcLoginState(string, time.Time) (screenjournal.OidcLoginState, error)
+		ReadOidcIdentity(string, string) (screenjournal.OidcIdentity, error)
+		InsertOidcIdentity(screenjournal.OidcIdentity) error
+		ReadUserByEmail(screenjournal.Email) (screenjournal.User, error)
+		InsertUser(screenjournal.User) error
+	}
+
+	Manager struct {
+		store    Store
+		provider Provider
+		// signUpGroup is the identity provider group whose members can create
+		// an account without an invite. If it's empty, only existing users can
+		// log in.
+		signUpGroup string
+		now         func() time.Time
+	}
+)
+
+func New(store Store, provider Provider, signUpGroup string, now func() time.Time) Manager {
+	if now == nil {
+		panic("sso manager requires a clock")
+	}
+	return Manager{
+		store:       store,
+		provider:    provider,
+		signUpGroup: signUpGroup,
+		now:         now,
+	}
+}
+
+// BeginLogin returns the identity provider URL to send the user to. Once the
+// user logs in there, FinishLogin sends them to nextPath.
+func (m Manager) BeginLogin(ctx context.Context, nextPath string) (string, error) {
+	req := oidc.NewAuthRequest()
+	if err := m.store.InsertOidcLoginState(screenjournal.OidcLoginState{
+		State:        req.State,
+		Nonce:        req.Nonce,
+		CodeVerifier: req.CodeVerifier,
+		NextPath:     nextPath,
+		ExpiresAt:    m.now().Add(loginLifetime),
+	}); err != nil {
+		return "", fmt.Errorf("save login state: %w", err)
+	}
+
+	return m.provider.AuthCodeURL(ctx, req)
+}
+
+// FinishLogin handles the identity provider's callback. It returns the user
+// who logged in and where to send them next.
+func (m Manager) FinishLogin(ctx context.Context, state, code string) (screenjournal.Username, string, error) {
+	loginState, err := m.store.UseOidcLoginState(state, m.now())
+	if errors.Is(err, store.ErrOidcLoginStateNotFound) || errors.Is(err, store.ErrExpiredOidcLoginState) {
+		return screenjournal.Username(""), "", ErrInvalidState
+	} else if err != nil {
+		return screenjournal.Username(""), "", fmt.Errorf("read login state: %w", err)
+	}
+
+	claims, err := m.provider.Exchange(ctx, code, oidc.AuthRequest{
+		State:        loginState.State,
+		Nonce:        loginState.Nonce,
+		CodeVerifier: loginState.CodeVerifier,
+	})
+	if err != nil {
+		log.Printf("OIDC code exchange failed: %v", err)
+		return screenjournal.Username(""), "", ErrLoginFailed
+	}
+
+	username, err := m.userForIdentity(claims)
+	if err != nil {
+		return screenjournal.Username(""), "", err
+	}
+
+	return username, loginState.NextPath, nil
+}
+
+// userForIdentity finds the user that the identity belongs to. If the
+// identity isn't linked yet, it links it to the user with the same email
+// address if both ScreenJournal and the identity provider have verified it
+// or, if the identity provider says the user may sign up, creates a new user.
+func (m Manager) userForIdentity(claims oidc.Claims) (screenjournal.Username, error) {
+	identity, err := m.store.ReadOidcIdentity(claims.Issuer, claims.Subject)
+	if err == nil {
+		return identity.Username, nil
+	} else if !errors.Is(err, store.ErrOidcIdentityNotFound) {
+		return screenjournal.Username(""), fmt.Errorf("read OIDC identity: %w", err)
+	}
+
+	// An unverified email could belong to anyone, so it's not safe to use it
+	// to match accounts.
+	if !claims.EmailVerified {
+		log.Printf("OIDC identity %s has no verified email address", claims.Subject)
+		return screenjournal.Username(""), ErrNoAccount
+	}
+	email, err := parse.Email(claims.Email)
+	if err != nil {
+		log.Printf("OIDC identity %s has an invalid email address: %v", claims.Subject, err)
+		return screenjournal.Username(""), ErrNoAccount
+	}
+
+	var username screenjournal.Username
+	user, err := m.store.ReadUserByEmail(email)
+	if err == nil {
+		// Anyone can sign up with an address they don't own, so only trust the
+		// match if the local user has also proven that the address is theirs.
+		if !user.EmailVerified {
+			log.Printf("OIDC identity %s matches user %s, who hasn't verified their email address", claims.Subject, user.Username)
+			return screenjournal.Username(""), ErrNoAccount
+		}
+		username = user.Username
+	} else if !errors.Is(err, store.ErrUserNotFound) {
+		return screenjournal.Username(""), fmt.Errorf("read user by email: %w", err)
+	} else if m.signUpGroup != "" && slices.Contains(claims.Groups, m.signUpGroup) {
+		if username, err = m.signUp(claims, email); err != nil {
+			return screenjournal.Username(""), err
+		}
+	} else {
+		log.Printf("OIDC identity %s doesn't match any user and can't sign up", claims.Subject)
+		return screenjournal.Username(""), ErrNoAccount
+	}
+
+	if err := m.store.InsertOidcIdentity(screenjournal.OidcIdentity{
+		Issuer:      claims.Issuer,
+		Subject:     claims.Subject,
+		Username:    username,
+		CreatedTime: m.now(),
+	}); err != nil {
+		return screenjournal.Username(""), fmt.Errorf("link OIDC identity: %w", err)
+	}
+
+	return username, nil
+}
+
+// signUp creates a user for a new member of the sign-up group. The user gets
+// a random password, which they can replace with a password reset if they
+// want to log in without the identity provider.
+func (m Manager) signUp(claims oidc.Claims, email screenjournal.Email) (screenjournal.Username, error) {
+	passwordHash, err := auth.HashPassword(screenjournal.Password(randomPassword()))
+	if err != nil {
+		return screenjournal.Username(""), fmt.Errorf("hash password: %w", err)
+	}
+
+	base := usernameBase(claims, email)
+	for i := range maxUsernameAttempts {
+		candidate := base
+		if i > 0 {
+			candidate += strconv.Itoa(i + 1)
+		}
+		username, err := parse.NewUsername(candidate)
+		if err != nil {
+			continue
+		}
+
+		// The identity provider has already verified the email address.
+		err = m.store.InsertUser(screenjournal.User{
+			Username:      username,
+			Email:         email,
+			EmailVerified: true,
+			PasswordHash:  passwordHash,
+		})
+		if errors.Is(err, store.ErrUsernameNotAvailable) {
+			continue
+		} else if err != nil {
+			return screenjournal.Username(""), fmt.Errorf("create user: %w", err)
+		}
+
+		log.Printf("created user %s for OIDC identity %s", username, claims.Subject)
+		return username, nil
+	}
+
+	return screenjournal.Username(""), fmt.Errorf("no available username for OIDC identity %s", claims.Subject)
+}
+
+// usernameBase suggests a ScreenJournal username based on the user's
+// preferred username at the identity provider or their email address.
+func usernameBase(claims oidc.Claims, email screenjournal.Email) string {
+	for _, raw := range []string{
+		claims.PreferredUsername,
+		strings.Split(email.String(), "@")[0],
+	} {
+		// Leave room for a numeric suffix.
+		candidate := invalidUsernameChars.ReplaceAllString(raw, "")
+		if len(candidate) > 77 {
+			candidate = candidate[:77]
+		}
+		if len(candidate) >= 2 {
+			return candidate
+		}
+	}
+	return "member"
+}
+
+func randomPassword() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,261 @@
+package sso_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/oidc"
+	"github.com/mtlynch/screenjournal/v2/oidc/oidctest"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/sso"
+	"github.com/mtlynch/screenjournal/v2/store/sqlite"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestFinishLogin(t *testing.T) {
+	for _, tt := range []struct {
+		description   string
+		existingUsers []screenjournal.User
+		linkedUser    screenjournal.Username
+		signUpGroup   string
+		idpUser       oidctest.User
+		usernameOut   screenjournal.Username
+		errOut        error
+	}{
+		{
+			description: "logs in the user linked to the identity",
+			existingUsers: []screenjournal.User{
+				{Username: "jane", Email: "jane@example.com"},
+			},
+			linkedUser: "jane",
+			idpUser: oidctest.User{
+				Subject: "user-1234",
+				// The identity stays linked even if the user's email changes at
+				// the identity provider.
+				Email:         "jane.doe@example.com",
+				EmailVerified: true,
+			},
+			usernameOut: "jane",
+		},
+		{
+			description: "links an existing user by verified email",
+			existingUsers: []screenjournal.User{
+				{Username: "jane", Email: "jane@example.com", EmailVerified: true},
+			},
+			idpUser: oidctest.User{
+				Subject:           "user-1234",
+				Email:             "jane@example.com",
+				EmailVerified:     true,
+				PreferredUsername: "jdoe",
+			},
+			usernameOut: "jane",
+		},
+		{
+			description: "refuses to link a user who hasn't verified their email",
+			existingUsers: []screenjournal.User{
+				{Username: "jane", Email: "jane@example.com", EmailVerified: false},
+			},
+			idpUser: oidctest.User{
+				Subject:       "user-1234",
+				Email:         "jane@example.com",
+				EmailVerified: true,
+			},
+			errOut: sso.ErrNoAccount,
+		},
+		{
+			description: "refuses to link by unverified email",
+			existingUsers: []screenjournal.User{
+				{Username: "jane", Email: "jane@example.com", EmailVerified: true},
+			},
+			idpUser: oidctest.User{
+				Subject:       "user-1234",
+				Email:         "jane@example.com",
+				EmailVerified: false,
+			},
+			errOut: sso.ErrNoAccount,
+		},
+		{
+			description: "rejects an unknown user when sign-up is disabled",
+			idpUser: oidctest.User{
+				Subject:       "user-1234",
+				Email:         "jane@example.com",
+				EmailVerified: true,
+				Groups:        []string{"film-club"},
+			},
+			errOut: sso.ErrNoAccount,
+		},
+		{
+			description: "rejects an unknown user outside the sign-up group",
+			signUpGroup: "film-club",
+			idpUser: oidctest.User{
+				Subject:       "user-1234",
+				Email:         "jane@example.com",
+				EmailVerified: true,
+				Groups:        []string{"staff"},
+			},
+			errOut: sso.ErrNoAccount,
+		},
+		{
+			description: "signs up a member of the sign-up group",
+			signUpGroup: "film-club",
+			idpUser: oidctest.User{
+				Subject:           "user-1234",
+				Email:             "jane@example.com",
+				EmailVerified:     true,
+				PreferredUsername: "jane_doe",
+				Groups:            []string{"staff", "film-club"},
+			},
+			usernameOut: "janedoe",
+		},
+		{
+			description: "falls back to the email address for the new username",
+			signUpGroup: "film-club",
+			idpUser: oidctest.User{
+				Subject:       "user-1234",
+				Email:         "jane.doe@example.com",
+				EmailVerified: true,
+				Groups:        []string{"film-club"},
+			},
+			usernameOut: "jane.doe",
+		},
+		{
+			description: "adds a suffix if the new username is taken",
+			existingUsers: []screenjournal.User{
+				{Username: "jane", Email: "jane@example.org"},
+			},
+			signUpGroup: "film-club",
+			idpUser: oidctest.User{
+				Subject:           "user-1234",
+				Email:             "jane@example.com",
+				EmailVerified:     true,
+				PreferredUsername: "jane",
+				Groups:            []string{"film-club"},
+			},
+			usernameOut: "jane2",
+		},
+		{
+			description: "refuses to sign up a group member with an unverified email",
+			signUpGroup: "film-club",
+			idpUser: oidctest.User{
+				Subject:       "user-1234",
+				Email:         "jane@example.com",
+				EmailVerified: false,
+				Groups:        []string{"film-club"},
+			},
+			errOut: sso.ErrNoAccount,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			idp := oidctest.New("screenjournal", "s3cr3t")
+			defer idp.Close()
+			idp.SetUser(tt.idpUser)
+
+			dataStore := test_sqlite.New()
+			for _, u := range tt.existingUsers {
+				u.PasswordHash = screenjournal.PasswordHash("dummy-hash")
+				if err := dataStore.InsertUser(u); err != nil {
+					t.Fatalf("failed to insert user: %v", err)
+				}
+			}
+			if !tt.linkedUser.Empty() {
+				if err := dataStore.InsertOidcIdentity(screenjournal.OidcIdentity{
+					Issuer:      idp.URL,
+					Subject:     tt.idpUser.Subject,
+					Username:    tt.linkedUser,
+					CreatedTime: time.Now(),
+				}); err != nil {
+					t.Fatalf("failed to link identity: %v", err)
+				}
+			}
+
+			m := newManager(dataStore, idp, tt.signUpGroup)
+			state, code := logInAtProvider(t, m, idp, "/reviews")
+
+			username, nextPath, err := m.FinishLogin(context.Background(), state, code)
+			if got, want := err, tt.errOut; !errors.Is(got, want) {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if tt.errOut != nil {
+				return
+			}
+			if got, want := username, tt.usernameOut; !got.Equal(want) {
+				t.Errorf("username=%v, want=%v", got, want)
+			}
+			if got, want := nextPath, "/reviews"; got != want {
+				t.Errorf("nextPath=%v, want=%v", got, want)
+			}
+
+			// The identity is now linked, so the next login finds the same
+			// user.
+			identity, err := dataStore.ReadOidcIdentity(idp.URL, tt.idpUser.Subject)
+			if err != nil {
+				t.Fatalf("failed to read linked identity: %v", err)
+			}
+			if got, want := identity.Username, tt.usernameOut; !got.Equal(want) {
+				t.Errorf("linked username=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestFinishLoginRejectsReusedState(t *testing.T) {
+	idp := oidctest.New("screenjournal", "s3cr3t")
+	defer idp.Close()
+	idp.SetUser(oidctest.User{
+		Subject:       "user-1234",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+	})
+
+	dataStore := test_sqlite.New()
+	if err := dataStore.InsertUser(screenjournal.User{
+		Username:      "jane",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		PasswordHash:  screenjournal.PasswordHash("dummy-hash"),
+	}); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+
+	m := newManager(dataStore, idp, "")
+	state, code := logInAtProvider(t, m, idp, "/")
+
+	if _, _, err := m.FinishLogin(context.Background(), state, code); err != nil {
+		t.Fatalf("first login err=%v, want=%v", err, nil)
+	}
+	if _, _, err := m.FinishLogin(context.Background(), state, code); !errors.Is(err, sso.ErrInvalidState) {
+		t.Errorf("replayed login err=%v, want=%v", err, sso.ErrInvalidState)
+	}
+	if _, _, err := m.FinishLogin(context.Background(), "forged-state", code); !errors.Is(err, sso.ErrInvalidState) {
+		t.Errorf("forged login err=%v, want=%v", err, sso.ErrInvalidState)
+	}
+}
+
+func newManager(dataStore sqlite.Store, idp *oidctest.Server, signUpGroup string) sso.Manager {
+	provider := oidc.New(oidc.Config{
+		IssuerURL:    idp.URL,
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "https://sj.example.com/auth/oidc/callback",
+	}, http.DefaultClient, time.Now)
+	return sso.New(dataStore, provider, signUpGroup, time.Now)
+}
+
+// logInAtProvider starts a login and follows it through the identity provider,
+// returning the state and code from the callback.
+func logInAtProvider(t *testing.T, m sso.Manager, idp *oidctest.Server, nextPath string) (string, string) {
+	t.Helper()
+
+	authURL, err := m.BeginLogin(context.Background(), nextPath)
+	if err != nil {
+		t.Fatalf("failed to begin login: %v", err)
+	}
+	callback, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("authorization failed: %v", err)
+	}
+	return callback.Query().Get("state"), callback.Query().Get("code")
+}
@@ -0,0 +1,57 @@
+// Package stars converts ratings to a five-star scale with half stars.
+package stars
+
+import (
+	"strings"
+
+	"github.com/mtlynch/screenjournal/v2/handlers/parse"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+type Star int
+
+const (
+	Full Star = iota
+	Half
+	Empty
+)
+
+// FromRating returns the stars that represent a rating, or an empty slice if
+// the rating is nil.
+func FromRating(rating screenjournal.Rating) []Star {
+	if rating.IsNil() {
+		return []Star{}
+	}
+
+	ratingVal := rating.UInt8()
+	stars := make([]Star, 0, parse.MaxRating/2)
+	// Add whole stars.
+	for i := uint8(0); i < ratingVal/2; i++ {
+		stars = append(stars, Full)
+	}
+	if ratingVal%2 != 0 {
+		stars = append(stars, Half)
+	}
+	// Add empty stars.
+	emptyStars := (parse.MaxRating / 2) - (ratingVal / 2) - ratingVal%2
+	for range emptyStars {
+		stars = append(stars, Empty)
+	}
+	return stars
+}
+
+// Plaintext renders a rating as Unicode characters, like "★★★½☆".
+func Plaintext(rating screenjournal.Rating) string {
+	var sb strings.Builder
+	for _, s := range FromRating(rating) {
+		switch s {
+		case Full:
+			sb.WriteString("★")
+		case Half:
+			sb.WriteString("½")
+		case Empty:
+			sb.WriteString("☆")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,43 @@
+package stars_test
+
+import (
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/stars"
+)
+
+func TestPlaintext(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		rating      screenjournal.Rating
+		expected    string
+	}{
+		{
+			description: "nil rating has no stars",
+			rating:      screenjournal.Rating{},
+			expected:    "",
+		},
+		{
+			description: "lowest rating is one half star",
+			rating:      screenjournal.NewRating(1),
+			expected:    "½☆☆☆☆",
+		},
+		{
+			description: "odd rating ends in a half star",
+			rating:      screenjournal.NewRating(7),
+			expected:    "★★★½☆",
+		},
+		{
+			description: "highest rating is five full stars",
+			rating:      screenjournal.NewRating(10),
+			expected:    "★★★★★",
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			if got, want := stars.Plaintext(tt.rating), tt.expected; got != want {
+				t.Errorf("stars=%q, want=%q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+// formerMemberEmail is the address of the placeholder account that owns
+// anonymized content. The .invalid domain guarantees that it never receives
+// mail.
+const formerMemberEmail = "former.member@screenjournal.invalid"
+
+// formerMemberPasswordHash isn't a valid bcrypt hash, so no password matches
+// it.
+const formerMemberPasswordHash = "!"
+
+// DeleteUser deletes the user's account and everything tied to their
+// identity. Depending on mode, it either deletes the user's reviews,
+// comments, and reactions or attributes them to the former member
+// placeholder account.
+func (s Store) DeleteUser(username screenjournal.Username, mode screenjournal.AccountDeletionMode) error {
+	log.Printf("deleting user %s (content=%s)", username, mode)
+
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to rollback delete user: %v", err)
+		}
+	}()
+
+	var exists bool
+	if err := tx.QueryRow(`SELECT 1 FROM users WHERE username = :username`,
+		sql.Named("username", username.String())).Scan(&exists); err == sql.ErrNoRows {
+		return store.ErrUserNotFound
+	} else if err != nil {
+		return err
+	}
+
+	switch mode {
+	case screenjournal.AccountDeletionDeleteContent:
+		err = deleteUserContent(tx, username)
+	case screenjournal.AccountDeletionAnonymizeContent:
+		err = anonymizeUserContent(tx, username)
+	default:
+		err = fmt.Errorf("unrecognized account deletion mode: %s", mode)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := deleteOutboxEmailsTo(tx, username); err != nil {
+		return err
+	}
+
+	for _, stmt := range []string{
+		`DELETE FROM notifications WHERE recipient = :username OR actor = :username`,
+		`DELETE FROM friend_recommendations WHERE sender = :username OR recipient = :username`,
+		`DELETE FROM user_relationships WHERE username = :username OR target = :username`,
+		`DELETE FROM auth_sessions WHERE user_id = :username`,
+		`DELETE FROM password_reset_tokens WHERE username = :username`,
+		`DELETE FROM login_links WHERE username = :username`,
+		`DELETE FROM email_verifications WHERE username = :username`,
+		`DELETE FROM notification_preferences WHERE username = :username`,
+		`DELETE FROM two_factor_recovery_codes WHERE username = :username`,
+		`DELETE FROM two_factor WHERE username = :username`,
+		`DELETE FROM passkeys WHERE username = :username`,
+		`DELETE FROM passkey_challenges WHERE username = :username`,
+		`DELETE FROM oidc_identities WHERE username = :username`,
+		`DELETE FROM login_throttles WHERE scope = 'username' AND throttle_key = :username`,
+		// Keep the invites the user created and redeemed so that their uses
+		// still count, but forget who the user was.
+		`UPDATE invites SET invited_by = NULL WHERE invited_by = :username`,
+		`UPDATE invite_redemptions SET username = NULL WHERE username = :username`,
+		`DELETE FROM users WHERE username = :username`,
+	} {
+		if _, err := tx.Exec(stmt, sql.Named("username", username.String())); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// deleteOutboxEmailsTo removes queued and past emails to the user's address
+// so that ScreenJournal stops emailing them and doesn't keep their address.
+func deleteOutboxEmailsTo(tx *sql.Tx, username screenjournal.Username) error {
+	var email string
+	if err := tx.QueryRow(`SELECT email FROM users WHERE username = :username`,
+		sql.Named("username", username.String())).Scan(&email); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(`SELECT id, to_addresses FROM email_outbox`)
+	if err != nil {
+		return err
+	}
+	ids := []uint64{}
+	for rows.Next() {
+		var id uint64
+		var toRaw string
+		if err := rows.Scan(&id, &toRaw); err != nil {
+			rows.Close()
+			return err
+		}
+		to, err := mail.ParseAddressList(toRaw)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("parse recipients of outbox email %d: %w", id, err)
+		}
+		for _, addr := range to {
+			if strings.EqualFold(addr.Address, email) {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if _, err := tx.Exec(`DELETE FROM email_outbox WHERE id = :id`, sql.Named("id", id)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteUserContent(tx *sql.Tx, username screenjournal.Username) error {
+	rows, err := tx.Query(`SELECT id FROM review_comments WHERE comment_owner = :username`,
+		sql.Named("username", username.String()))
+	if err != nil {
+		return err
+	}
+	commentIDs := []uint64{}
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		commentIDs = append(commentIDs, id)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	// Move replies to the user's comments up a level, the same way deleting a
+	// single comment does, so that other members' replies stay in the thread.
+	for _, id := range commentIDs {
+		if _, err := tx.Exec(`
+		UPDATE review_comments
+		SET
+			parent_comment_id = (
+				SELECT parent_comment_id FROM review_comments WHERE id = :id
+			)
+		WHERE
+			parent_comment_id = :id`, sql.Named("id", id)); err != nil {
+			return err
+		}
+	}
+
+	for _, stmt := range []string{
+		`
+		DELETE FROM review_reactions
+		WHERE
+			reaction_owner = :username OR
+			EXISTS (
+				SELECT 1 FROM reviews
+				WHERE reviews.id = review_reactions.review_id AND reviews.review_owner = :username
+			) OR
+			EXISTS (
+				SELECT 1 FROM review_comments
+				WHERE review_comments.id = review_reactions.comment_id AND review_comments.comment_owner = :username
+			)`,
+		`
+		DELETE FROM notifications
+		WHERE
+			EXISTS (
+				SELECT 1 FROM reviews
+				WHERE reviews.id = notifications.review_id AND reviews.review_owner = :username
+			) OR
+			EXISTS (
+				SELECT 1 FROM review_comments
+				WHERE review_comments.id = notifications.comment_id AND review_comments.comment_owner = :username
+			)`,
+		`
+		DELETE FROM review_comments
+		WHERE
+			comment_owner = :username OR
+			EXISTS (
+				SELECT 1 FROM reviews
+				WHERE reviews.id = review_comments.review_id AND reviews.review_owner = :username
+			)`,
+		`DELETE FROM reviews WHERE review_owner = :username`,
+	} {
+		if _, err := tx.Exec(stmt, sql.Named("username", username.String())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func anonymizeUserContent(tx *sql.Tx, username screenjournal.Username) error {
+	now := formatTime(time.Now())
+	if _, err := tx.Exec(`
+	INSERT INTO
+		users
+	(
+		username,
+		is_admin,
+		email,
+		email_verified,
+		password_hash,
+		created_time,
+		last_modified_time
+	)
+	VALUES (
+		:username, 0, :email, 0, :password_hash, :created_time, :last_modified_time
+	)
+	ON CONFLICT (username) DO NOTHING`,
+		sql.Named("username", screenjournal.FormerMemberUsername.String()),
+		sql.Named("email", formerMemberEmail),
+		sql.Named("password_hash", formerMemberPasswordHash),
+		sql.Named("created_time", now),
+		sql.Named("last_modified_time", now)); err != nil {
+		return err
+	}
+
+	for _, stmt := range []string{
+		`UPDATE reviews SET review_owner = :former_member WHERE review_owner = :username`,
+		`UPDATE review_comments SET comment_owner = :former_member WHERE comment_owner = :username`,
+		// If the placeholder already has the same reaction from another former
+		// member, keep that one and drop this user's duplicate.
+		`UPDATE OR IGNORE review_reactions SET reaction_owner = :former_member WHERE reaction_owner = :username`,
+		`DELETE FROM review_reactions WHERE reaction_owner = :username`,
+	} {
+		if _, err := tx.Exec(stmt,
+			sql.Named("username", username.String()),
+			sql.Named("former_member", screenjournal.FormerMemberUsername.String())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
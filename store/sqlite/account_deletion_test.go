@@ -0,0 +1,241 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"testing"
+	"time"
+
+	simple_sessions "codeberg.org/mtlynch/simpleauth/v3/sessions"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/sqlite"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+type accountDeletionTestData struct {
+	ownerReview screenjournal.Review
+	userAReview screenjournal.Review
+	userAID     screenjournal.CommentID
+	replyID     screenjournal.CommentID
+}
+
+// insertAccountDeletionTestData creates a review by "owner" that userA
+// comments and reacts on and userB replies to, plus a review by userA. It
+// also gives userA a session so that deleting the user exercises the
+// cascade.
+func insertAccountDeletionTestData(t *testing.T, db sqlite.Store) accountDeletionTestData {
+	ownerReview := insertCommentThreadTestData(t, db, "userA", "userB")
+
+	userAReview := screenjournal.Review{
+		Owner:   screenjournal.Username("userA"),
+		Rating:  screenjournal.NewRating(3),
+		Movie:   ownerReview.Movie,
+		Watched: screenjournal.WatchDate(mustParseTime(t, "2024-02-01T00:00:00Z")),
+	}
+	var err error
+	userAReview.ID, err = db.InsertReview(userAReview)
+	if err != nil {
+		t.Fatalf("failed to insert review: %v", err)
+	}
+
+	userAID, err := db.InsertComment(screenjournal.ReviewComment{
+		Owner:       screenjournal.Username("userA"),
+		CommentText: screenjournal.CommentText("Great pick"),
+		Review:      ownerReview,
+	})
+	if err != nil {
+		t.Fatalf("failed to insert comment: %v", err)
+	}
+	replyID, err := db.InsertComment(screenjournal.ReviewComment{
+		ParentID:    userAID,
+		Owner:       screenjournal.Username("userB"),
+		CommentText: screenjournal.CommentText("Agreed"),
+		Review:      ownerReview,
+	})
+	if err != nil {
+		t.Fatalf("failed to insert comment: %v", err)
+	}
+
+	for _, username := range []screenjournal.Username{"userA", "userB"} {
+		if _, err := db.InsertReaction(screenjournal.ReviewReaction{
+			Owner:  username,
+			Emoji:  screenjournal.NewReactionEmoji("👍"),
+			Review: ownerReview,
+		}); err != nil {
+			t.Fatalf("failed to insert reaction: %v", err)
+		}
+	}
+	if _, err := db.InsertComment(screenjournal.ReviewComment{
+		Owner:       screenjournal.Username("userB"),
+		CommentText: screenjournal.CommentText("Nice review"),
+		Review:      userAReview,
+	}); err != nil {
+		t.Fatalf("failed to insert comment: %v", err)
+	}
+
+	now := time.Now()
+	if err := db.CreateSession(context.Background(), simple_sessions.Session{
+		ID:        newSessionID(t, "userA"),
+		UserID:    newUserID(t, "userA"),
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	return accountDeletionTestData{
+		ownerReview: ownerReview,
+		userAReview: userAReview,
+		userAID:     userAID,
+		replyID:     replyID,
+	}
+}
+
+func TestDeleteUserDeletesContent(t *testing.T) {
+	db := test_sqlite.New()
+	data := insertAccountDeletionTestData(t, db)
+
+	if err := db.DeleteUser(screenjournal.Username("userA"), screenjournal.AccountDeletionDeleteContent); err != nil {
+		t.Fatalf("DeleteUser err=%v, want=%v", err, nil)
+	}
+
+	if _, err := db.ReadUser(screenjournal.Username("userA")); !errors.Is(err, store.ErrUserNotFound) {
+		t.Errorf("ReadUser err=%v, want=%v", err, store.ErrUserNotFound)
+	}
+	if _, err := db.ReadReview(data.userAReview.ID); !errors.Is(err, store.ErrReviewNotFound) {
+		t.Errorf("ReadReview err=%v, want=%v", err, store.ErrReviewNotFound)
+	}
+	if _, err := db.ReadComment(data.userAID); !errors.Is(err, store.ErrCommentNotFound) {
+		t.Errorf("ReadComment err=%v, want=%v", err, store.ErrCommentNotFound)
+	}
+
+	reply, err := db.ReadComment(data.replyID)
+	if err != nil {
+		t.Fatalf("failed to read reply: %v", err)
+	}
+	if got, want := reply.ParentID, screenjournal.CommentID(0); got != want {
+		t.Errorf("reply parentID=%v, want=%v", got, want)
+	}
+
+	reactions, err := db.ReadReactions(data.ownerReview.ID)
+	if err != nil {
+		t.Fatalf("failed to read reactions: %v", err)
+	}
+	if got, want := len(reactions), 1; got != want {
+		t.Fatalf("len(reactions)=%d, want=%d", got, want)
+	}
+	if got, want := reactions[0].Owner, screenjournal.Username("userB"); got != want {
+		t.Errorf("reaction owner=%v, want=%v", got, want)
+	}
+
+	if _, err := db.ReadUser(screenjournal.FormerMemberUsername); !errors.Is(err, store.ErrUserNotFound) {
+		t.Errorf("ReadUser(%s) err=%v, want=%v", screenjournal.FormerMemberUsername, err, store.ErrUserNotFound)
+	}
+}
+
+func TestDeleteUserAnonymizesContent(t *testing.T) {
+	db := test_sqlite.New()
+	data := insertAccountDeletionTestData(t, db)
+
+	for _, username := range []screenjournal.Username{"userA", "userB"} {
+		if err := db.DeleteUser(username, screenjournal.AccountDeletionAnonymizeContent); err != nil {
+			t.Fatalf("DeleteUser(%s) err=%v, want=%v", username, err, nil)
+		}
+	}
+
+	if _, err := db.ReadUser(screenjournal.Username("userA")); !errors.Is(err, store.ErrUserNotFound) {
+		t.Errorf("ReadUser err=%v, want=%v", err, store.ErrUserNotFound)
+	}
+
+	review, err := db.ReadReview(data.userAReview.ID)
+	if err != nil {
+		t.Fatalf("failed to read review: %v", err)
+	}
+	if got, want := review.Owner, screenjournal.FormerMemberUsername; got != want {
+		t.Errorf("review owner=%v, want=%v", got, want)
+	}
+
+	for _, id := range []screenjournal.CommentID{data.userAID, data.replyID} {
+		comment, err := db.ReadComment(id)
+		if err != nil {
+			t.Fatalf("failed to read comment: %v", err)
+		}
+		if got, want := comment.Owner, screenjournal.FormerMemberUsername; got != want {
+			t.Errorf("comment owner=%v, want=%v", got, want)
+		}
+	}
+
+	// Both users left the same reaction, so only one survives.
+	reactions, err := db.ReadReactions(data.ownerReview.ID)
+	if err != nil {
+		t.Fatalf("failed to read reactions: %v", err)
+	}
+	if got, want := len(reactions), 1; got != want {
+		t.Fatalf("len(reactions)=%d, want=%d", got, want)
+	}
+	if got, want := reactions[0].Owner, screenjournal.FormerMemberUsername; got != want {
+		t.Errorf("reaction owner=%v, want=%v", got, want)
+	}
+
+	users, err := db.ReadUsersPublicMeta()
+	if err != nil {
+		t.Fatalf("failed to read users: %v", err)
+	}
+	for _, u := range users {
+		if u.Username.Equal(screenjournal.FormerMemberUsername) {
+			t.Errorf("user list includes %s placeholder", screenjournal.FormerMemberUsername)
+		}
+	}
+	if _, err := db.ReadUser(screenjournal.FormerMemberUsername); !errors.Is(err, store.ErrUserNotFound) {
+		t.Errorf("ReadUser(%s) err=%v, want=%v", screenjournal.FormerMemberUsername, err, store.ErrUserNotFound)
+	}
+}
+
+func TestDeleteUserDeletesOutboxEmails(t *testing.T) {
+	db := test_sqlite.New()
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+	insertUser(t, db, "userA")
+	insertUser(t, db, "userB")
+
+	for _, to := range []mail.Address{
+		{Name: "userA", Address: "userA@example.com"},
+		{Name: "userB", Address: "userB@example.com"},
+	} {
+		if err := db.InsertOutboxEmail(screenjournal.OutboxEmail{
+			Key:         screenjournal.OutboxEmailKey("new-review-" + to.Name),
+			From:        mail.Address{Name: "ScreenJournal", Address: "activity@thescreenjournal.com"},
+			To:          []mail.Address{to},
+			Subject:     "owner posted a new review: The Waterboy",
+			NextAttempt: now,
+		}); err != nil {
+			t.Fatalf("failed to insert outbox email: %v", err)
+		}
+	}
+
+	if err := db.DeleteUser(screenjournal.Username("userA"), screenjournal.AccountDeletionDeleteContent); err != nil {
+		t.Fatalf("DeleteUser err=%v, want=%v", err, nil)
+	}
+
+	due, err := db.ReadDueOutboxEmails(now)
+	if err != nil {
+		t.Fatalf("failed to read due outbox emails: %v", err)
+	}
+	if got, want := len(due), 1; got != want {
+		t.Fatalf("due emails=%d, want=%d", got, want)
+	}
+	if got, want := due[0].To[0].Address, "userB@example.com"; got != want {
+		t.Errorf("to=%v, want=%v", got, want)
+	}
+}
+
+func TestDeleteUserRejectsUnknownUser(t *testing.T) {
+	db := test_sqlite.New()
+
+	err := db.DeleteUser(screenjournal.Username("nobody"), screenjournal.AccountDeletionDeleteContent)
+	if got, want := err, store.ErrUserNotFound; !errors.Is(got, want) {
+		t.Errorf("err=%v, want=%v", got, want)
+	}
+}
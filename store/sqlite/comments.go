@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"time"
@@ -19,6 +20,7 @@ func (s Store) ReadComments(rid screenjournal.ReviewID) ([]screenjournal.ReviewC
 	SELECT
 		id,
 		review_id,
+		parent_comment_id,
 		comment_owner,
 		comment_text,
 		created_time,
@@ -54,6 +56,14 @@ func (s Store) ReadComments(rid screenjournal.ReviewID) ([]screenjournal.ReviewC
 		return []screenjournal.ReviewComment{}, err
 	}
 
+	reactions, err := s.readCommentReactionsForReview(rid)
+	if err != nil {
+		return []screenjournal.ReviewComment{}, err
+	}
+	for i := range comments {
+		comments[i].Reactions = reactions[comments[i].ID]
+	}
+
 	return comments, nil
 }
 
@@ -62,6 +72,7 @@ func (s Store) ReadComment(cid screenjournal.CommentID) (screenjournal.ReviewCom
 	SELECT
 		id,
 		review_id,
+		parent_comment_id,
 		comment_owner,
 		comment_text,
 		created_time,
@@ -72,12 +83,30 @@ func (s Store) ReadComment(cid screenjournal.CommentID) (screenjournal.ReviewCom
 		id = :id
 	`, sql.Named("id", cid))
 
-	return reviewCommentFromRow(row)
+	rc, err := reviewCommentFromRow(row)
+	if err != nil {
+		return screenjournal.ReviewComment{}, err
+	}
+
+	reactions, err := s.ReadCommentReactions(cid)
+	if err != nil {
+		return screenjournal.ReviewComment{}, err
+	}
+	if len(reactions) > 0 {
+		rc.Reactions = reactions
+	}
+
+	return rc, nil
 }
 
 func (s Store) InsertComment(rc screenjournal.ReviewComment) (screenjournal.CommentID, error) {
 	log.Printf("inserting new comment from %v on %v's review ID %d", rc.Owner, rc.Review.Owner, rc.Review.ID.UInt64())
 
+	var parentID *uint64
+	if !rc.ParentID.IsZero() {
+		parentID = new(rc.ParentID.UInt64())
+	}
+
 	now := time.Now()
 
 	res, err := s.db.Exec(`
@@ -85,16 +114,18 @@ func (s Store) InsertComment(rc screenjournal.ReviewComment) (screenjournal.Comm
 		review_comments
 	(
 		review_id,
+		parent_comment_id,
 		comment_owner,
 		comment_text,
 		created_time,
 		last_modified_time
 	)
 	VALUES (
-		:review_id, :comment_owner, :comment_text, :created_time, :last_modified_time
+		:review_id, :parent_comment_id, :comment_owner, :comment_text, :created_time, :last_modified_time
 	)
 	`,
 		sql.Named("review_id", rc.Review.ID),
+		sql.Named("parent_comment_id", parentID),
 		sql.Named("comment_owner", rc.Owner),
 		sql.Named("comment_text", rc.CommentText),
 		sql.Named("created_time", formatTime(now)),
@@ -134,22 +165,55 @@ func (s Store) UpdateComment(rc screenjournal.ReviewComment) error {
 
 func (s Store) DeleteComment(cid screenjournal.CommentID) error {
 	log.Printf("deleting comment ID=%v", cid)
-	_, err := s.db.Exec(`DELETE FROM review_comments WHERE id = :id`, sql.Named("id", cid.String()))
+
+	tx, err := s.db.BeginTx(context.Background(), nil)
 	if err != nil {
 		return err
 	}
-	return nil
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to rollback delete comment: %v", err)
+		}
+	}()
+
+	// Move any replies up a level so that they stay in the thread.
+	if _, err := tx.Exec(`
+	UPDATE review_comments
+	SET
+		parent_comment_id = (
+			SELECT parent_comment_id FROM review_comments WHERE id = :id
+		)
+	WHERE
+		parent_comment_id = :id`, sql.Named("id", cid.UInt64())); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM review_reactions WHERE comment_id = :id`, sql.Named("id", cid.UInt64())); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM notifications WHERE comment_id = :id`, sql.Named("id", cid.UInt64())); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM review_comments WHERE id = :id`, sql.Named("id", cid.UInt64())); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func reviewCommentFromRow(row rowScanner) (screenjournal.ReviewComment, error) {
 	var id int
 	var reviewId int
+	var parentID *uint64
 	var owner string
 	var comment string
 	var createdTimeRaw string
 	var lastModifiedTimeRaw string
 
-	err := row.Scan(&id, &reviewId, &owner, &comment, &createdTimeRaw, &lastModifiedTimeRaw)
+	err := row.Scan(&id, &reviewId, &parentID, &owner, &comment, &createdTimeRaw, &lastModifiedTimeRaw)
 	if err == sql.ErrNoRows {
 		return screenjournal.ReviewComment{}, store.ErrCommentNotFound
 	} else if err != nil {
@@ -166,8 +230,14 @@ func reviewCommentFromRow(row rowScanner) (screenjournal.ReviewComment, error) {
 		return screenjournal.ReviewComment{}, err
 	}
 
+	var pid screenjournal.CommentID
+	if parentID != nil {
+		pid = screenjournal.CommentID(*parentID)
+	}
+
 	return screenjournal.ReviewComment{
-		ID: screenjournal.CommentID(id),
+		ID:       screenjournal.CommentID(id),
+		ParentID: pid,
 		Review: screenjournal.Review{
 			ID: screenjournal.ReviewID(reviewId),
 		},
@@ -0,0 +1,86 @@
+package sqlite_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/sqlite"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func insertCommentThreadTestData(t *testing.T, db sqlite.Store, commenters ...screenjournal.Username) screenjournal.Review {
+	for _, username := range append([]screenjournal.Username{"owner"}, commenters...) {
+		if err := db.InsertUser(screenjournal.User{
+			Username:      username,
+			Email:         screenjournal.Email(username.String() + "@example.com"),
+			EmailVerified: true,
+			PasswordHash:  screenjournal.PasswordHash("dummy-hash"),
+		}); err != nil {
+			t.Fatalf("failed to insert user %s: %v", username, err)
+		}
+	}
+
+	movieID, err := db.InsertMovie(screenjournal.Movie{
+		TmdbID: screenjournal.TmdbID(1),
+		ImdbID: screenjournal.ImdbID("tt0120484"),
+		Title:  screenjournal.MediaTitle("The Waterboy"),
+	})
+	if err != nil {
+		t.Fatalf("failed to insert movie: %v", err)
+	}
+
+	review := screenjournal.Review{
+		Owner:   screenjournal.Username("owner"),
+		Rating:  screenjournal.NewRating(5),
+		Movie:   screenjournal.Movie{ID: movieID},
+		Watched: screenjournal.WatchDate(mustParseTime(t, "2024-01-01T00:00:00Z")),
+	}
+	review.ID, err = db.InsertReview(review)
+	if err != nil {
+		t.Fatalf("failed to insert review: %v", err)
+	}
+
+	return review
+}
+
+func TestDeleteCommentKeepsRepliesInThread(t *testing.T) {
+	db := test_sqlite.New()
+	review := insertCommentThreadTestData(t, db, "userA", "userB", "userC")
+
+	insertComment := func(owner screenjournal.Username, parentID screenjournal.CommentID) screenjournal.CommentID {
+		id, err := db.InsertComment(screenjournal.ReviewComment{
+			ParentID:    parentID,
+			Owner:       owner,
+			CommentText: screenjournal.CommentText("Hi"),
+			Review:      review,
+		})
+		if err != nil {
+			t.Fatalf("failed to insert comment: %v", err)
+		}
+		return id
+	}
+	rootID := insertComment("userA", 0)
+	middleID := insertComment("userB", rootID)
+	leafID := insertComment("userC", middleID)
+
+	if err := db.DeleteComment(middleID); err != nil {
+		t.Fatalf("failed to delete comment: %v", err)
+	}
+
+	leaf, err := db.ReadComment(leafID)
+	if err != nil {
+		t.Fatalf("failed to read comment: %v", err)
+	}
+	if got, want := leaf.ParentID, rootID; got != want {
+		t.Errorf("parentID=%v, want=%v", got, want)
+	}
+}
+
+func mustParseTime(t *testing.T, s string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf("failed to parse time %s: %v", s, err)
+	}
+	return parsed
+}
@@ -0,0 +1,300 @@
+package sqlite
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+// ReadDigestSubscribers returns the users who receive digests at the given
+// frequency.
+func (s Store) ReadDigestSubscribers(frequency screenjournal.DigestFrequency) ([]screenjournal.DigestSubscriber, error) {
+	rows, err := s.db.Query(`
+	SELECT
+		users.username,
+		users.email,
+		notification_preferences.new_reviews,
+		notification_preferences.new_reviews_scope,
+		notification_preferences.all_new_comments,
+		notification_preferences.replies,
+		notification_preferences.mentions,
+		notification_preferences.last_digest_time
+	FROM
+		users, notification_preferences
+	WHERE
+		users.username = notification_preferences.username AND
+		users.email_verified = 1 AND
+		notification_preferences.digest_frequency = :digest_frequency
+	ORDER BY
+		users.username`, sql.Named("digest_frequency", frequency.String()))
+	if err != nil {
+		return []screenjournal.DigestSubscriber{}, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close digest subscriber rows: %v", err)
+		}
+	}()
+
+	subscribers := []screenjournal.DigestSubscriber{}
+	for rows.Next() {
+		var username string
+		var email string
+		var newReviews bool
+		var newReviewsScope string
+		var allNewComments bool
+		var replies bool
+		var mentions bool
+		var lastDigestRaw *string
+		if err := rows.Scan(&username, &email, &newReviews, &newReviewsScope, &allNewComments, &replies, &mentions, &lastDigestRaw); err != nil {
+			return []screenjournal.DigestSubscriber{}, err
+		}
+
+		var lastDigest time.Time
+		if lastDigestRaw != nil {
+			lastDigest, err = parseDatetime(*lastDigestRaw)
+			if err != nil {
+				return []screenjournal.DigestSubscriber{}, err
+			}
+		}
+
+		subscribers = append(subscribers, screenjournal.DigestSubscriber{
+			EmailSubscriber: screenjournal.EmailSubscriber{
+				Username: screenjournal.Username(username),
+				Email:    screenjournal.Email(email),
+			},
+			Preferences: screenjournal.NotificationPreferences{
+				NewReviews:      newReviews,
+				NewReviewsScope: screenjournal.NotificationScope(newReviewsScope),
+				AllNewComments:  allNewComments,
+				Replies:         replies,
+				Mentions:        mentions,
+				Digest:          frequency,
+			},
+			LastDigest: lastDigest,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return []screenjournal.DigestSubscriber{}, err
+	}
+
+	return subscribers, nil
+}
+
+// ReadDigestReviews returns reviews that other users created after since and
+// at or before until, oldest first. It skips reviews from users the recipient
+// has muted and, if the recipient only wants reviews from people they follow,
+// from users they don't follow.
+func (s Store) ReadDigestReviews(recipient screenjournal.Username, since, until time.Time) ([]screenjournal.Review, error) {
+	ids, err := s.readIDs(`
+	SELECT
+		reviews.id
+	FROM
+		reviews, notification_preferences
+	WHERE
+		notification_preferences.username = :recipient AND
+		reviews.review_owner != :recipient AND
+		datetime(reviews.created_time) > datetime(:since) AND
+		datetime(reviews.created_time) <= datetime(:until) AND
+		(
+			notification_preferences.new_reviews_scope = 'everyone' OR
+			EXISTS (
+				SELECT 1
+				FROM
+					user_relationships
+				WHERE
+					user_relationships.username = :recipient AND
+					user_relationships.target = reviews.review_owner AND
+					user_relationships.relationship = 'follow'
+			)
+		) AND
+		NOT EXISTS (
+			SELECT 1
+			FROM
+				user_relationships
+			WHERE
+				user_relationships.username = :recipient AND
+				user_relationships.target = reviews.review_owner AND
+				user_relationships.relationship = 'mute'
+		)
+	ORDER BY
+		reviews.created_time,
+		reviews.id`,
+		sql.Named("recipient", recipient.String()),
+		sql.Named("since", formatTime(since)),
+		sql.Named("until", formatTime(until)))
+	if err != nil {
+		return []screenjournal.Review{}, err
+	}
+
+	reviews := []screenjournal.Review{}
+	for _, id := range ids {
+		review, err := s.ReadReview(screenjournal.ReviewID(id))
+		if err != nil {
+			return []screenjournal.Review{}, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+// ReadDigestComments returns comments created after since and at or before
+// until that the recipient would have received as immediate notifications,
+// oldest first.
+func (s Store) ReadDigestComments(recipient screenjournal.Username, since, until time.Time) ([]screenjournal.ReviewComment, error) {
+	rows, err := s.db.Query(`
+	SELECT
+		review_comments.id,
+		review_comments.review_id,
+		review_comments.parent_comment_id,
+		review_comments.comment_owner,
+		review_comments.comment_text,
+		review_comments.created_time,
+		review_comments.last_modified_time
+	FROM
+		review_comments, notification_preferences
+	WHERE
+		notification_preferences.username = :recipient AND
+		review_comments.comment_owner != :recipient AND
+		datetime(review_comments.created_time) > datetime(:since) AND
+		datetime(review_comments.created_time) <= datetime(:until) AND
+		NOT EXISTS (
+			SELECT 1
+			FROM
+				user_relationships
+			WHERE
+				user_relationships.username = :recipient AND
+				user_relationships.target = review_comments.comment_owner AND
+				user_relationships.relationship = 'mute'
+		) AND
+		(
+			(
+				notification_preferences.all_new_comments = 1 AND
+				(
+					EXISTS (
+						SELECT 1
+						FROM
+							reviews
+						WHERE
+							id = review_comments.review_id AND
+							review_owner = :recipient
+					) OR
+					EXISTS (
+						SELECT 1
+						FROM
+							review_comments AS earlier
+						WHERE
+							earlier.review_id = review_comments.review_id AND
+							earlier.comment_owner = :recipient
+					)
+				) AND
+				-- The replies preference alone decides whether authors hear about
+				-- direct replies to their comments.
+				NOT EXISTS (
+					SELECT 1
+					FROM
+						review_comments AS parent
+					WHERE
+						parent.id = review_comments.parent_comment_id AND
+						parent.comment_owner = :recipient
+				)
+			) OR
+			(
+				notification_preferences.replies = 1 AND
+				EXISTS (
+					SELECT 1
+					FROM
+						review_comments AS parent
+					WHERE
+						parent.id = review_comments.parent_comment_id AND
+						parent.comment_owner = :recipient
+				)
+			)
+		)
+	ORDER BY
+		review_comments.created_time,
+		review_comments.id`,
+		sql.Named("recipient", recipient.String()),
+		sql.Named("since", formatTime(since)),
+		sql.Named("until", formatTime(until)))
+	if err != nil {
+		return []screenjournal.ReviewComment{}, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close digest comment rows: %v", err)
+		}
+	}()
+
+	comments := []screenjournal.ReviewComment{}
+	for rows.Next() {
+		rc, err := reviewCommentFromRow(rows)
+		if err != nil {
+			return []screenjournal.ReviewComment{}, err
+		}
+		comments = append(comments, rc)
+	}
+	if err := rows.Err(); err != nil {
+		return []screenjournal.ReviewComment{}, err
+	}
+
+	reviews := map[screenjournal.ReviewID]screenjournal.Review{}
+	for i := range comments {
+		id := comments[i].Review.ID
+		review, ok := reviews[id]
+		if !ok {
+			if review, err = s.ReadReview(id); err != nil {
+				return []screenjournal.ReviewComment{}, err
+			}
+			reviews[id] = review
+		}
+		comments[i].Review = review
+	}
+
+	return comments, nil
+}
+
+// UpdateLastDigestTime records when the user last received a digest.
+func (s Store) UpdateLastDigestTime(username screenjournal.Username, t time.Time) error {
+	if _, err := s.db.Exec(`
+	UPDATE notification_preferences
+	SET
+		last_digest_time = :last_digest_time
+	WHERE
+		username = :username`,
+		sql.Named("last_digest_time", formatTime(t)),
+		sql.Named("username", username.String())); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Store) readIDs(query string, args ...any) ([]uint64, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return []uint64{}, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close ID rows: %v", err)
+		}
+	}()
+
+	ids := []uint64{}
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return []uint64{}, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return []uint64{}, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,122 @@
+package sqlite_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestDigests(t *testing.T) {
+	db := test_sqlite.New()
+	since := time.Now().Add(-time.Minute)
+	review := insertCommentThreadTestData(t, db, "userA", "userB")
+
+	if err := db.UpdateNotificationPreferences(screenjournal.Username("userA"), screenjournal.NotificationPreferences{
+		NewReviews:      true,
+		NewReviewsScope: screenjournal.NotifyEveryone,
+		Replies:         true,
+		Digest:          screenjournal.DigestDaily,
+	}); err != nil {
+		t.Fatalf("failed to update notification preferences: %v", err)
+	}
+
+	parentID, err := db.InsertComment(screenjournal.ReviewComment{
+		Owner:       screenjournal.Username("userA"),
+		CommentText: screenjournal.CommentText("Loved it"),
+		Review:      review,
+	})
+	if err != nil {
+		t.Fatalf("failed to insert comment: %v", err)
+	}
+	for _, rc := range []screenjournal.ReviewComment{
+		{
+			Owner:       screenjournal.Username("userB"),
+			CommentText: screenjournal.CommentText("Top-level comment"),
+			Review:      review,
+		},
+		{
+			ParentID:    parentID,
+			Owner:       screenjournal.Username("userB"),
+			CommentText: screenjournal.CommentText("Reply to userA"),
+			Review:      review,
+		},
+	} {
+		if _, err := db.InsertComment(rc); err != nil {
+			t.Fatalf("failed to insert comment: %v", err)
+		}
+	}
+	until := time.Now().Add(time.Minute)
+
+	subscribers, err := db.ReadDigestSubscribers(screenjournal.DigestDaily)
+	if err != nil {
+		t.Fatalf("failed to read digest subscribers: %v", err)
+	}
+	if got, want := len(subscribers), 1; got != want {
+		t.Fatalf("subscribers=%d, want=%d", got, want)
+	}
+	if got, want := subscribers[0].Username, screenjournal.Username("userA"); got != want {
+		t.Errorf("subscriber=%v, want=%v", got, want)
+	}
+	if !subscribers[0].LastDigest.IsZero() {
+		t.Errorf("last digest=%v, want zero time", subscribers[0].LastDigest)
+	}
+
+	// userA doesn't subscribe to new reviews immediately anymore.
+	immediate, err := db.ReadReviewSubscribers(screenjournal.Username("owner"))
+	if err != nil {
+		t.Fatalf("failed to read review subscribers: %v", err)
+	}
+	for _, s := range immediate {
+		if s.Username.Equal("userA") {
+			t.Errorf("digest subscriber %v is also an immediate subscriber", s.Username)
+		}
+	}
+
+	reviews, err := db.ReadDigestReviews(screenjournal.Username("userA"), since, until)
+	if err != nil {
+		t.Fatalf("failed to read digest reviews: %v", err)
+	}
+	if got, want := len(reviews), 1; got != want {
+		t.Fatalf("reviews=%d, want=%d", got, want)
+	}
+	if got, want := reviews[0].Movie.Title, screenjournal.MediaTitle("The Waterboy"); got != want {
+		t.Errorf("title=%v, want=%v", got, want)
+	}
+
+	// userA opted out of all comments, so they only see the direct reply.
+	comments, err := db.ReadDigestComments(screenjournal.Username("userA"), since, until)
+	if err != nil {
+		t.Fatalf("failed to read digest comments: %v", err)
+	}
+	if got, want := len(comments), 1; got != want {
+		t.Fatalf("comments=%d, want=%d", got, want)
+	}
+	if got, want := comments[0].CommentText, screenjournal.CommentText("Reply to userA"); got != want {
+		t.Errorf("comment=%v, want=%v", got, want)
+	}
+	if got, want := comments[0].Review.Owner, screenjournal.Username("owner"); got != want {
+		t.Errorf("review owner=%v, want=%v", got, want)
+	}
+
+	// The review owner gets every comment on their review.
+	comments, err = db.ReadDigestComments(screenjournal.Username("owner"), since, until)
+	if err != nil {
+		t.Fatalf("failed to read digest comments: %v", err)
+	}
+	if got, want := len(comments), 3; got != want {
+		t.Errorf("comments=%d, want=%d", got, want)
+	}
+
+	if err := db.UpdateLastDigestTime(screenjournal.Username("userA"), until); err != nil {
+		t.Fatalf("failed to update last digest time: %v", err)
+	}
+	subscribers, err = db.ReadDigestSubscribers(screenjournal.DigestDaily)
+	if err != nil {
+		t.Fatalf("failed to read digest subscribers: %v", err)
+	}
+	if got, want := subscribers[0].LastDigest.Unix(), until.Unix(); got != want {
+		t.Errorf("last digest=%v, want=%v", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+// InsertEmailVerification saves a new email verification for the user,
+// replacing any verification they requested earlier.
+func (s Store) InsertEmailVerification(v screenjournal.EmailVerification) error {
+	log.Printf("inserting new email verification for user %s", v.Username)
+
+	_, err := s.db.Exec(`
+	INSERT INTO
+		email_verifications
+	(
+		username,
+		email,
+		token,
+		expires_time
+	)
+	VALUES (
+		:username, :email, :token, :expires_time
+	)
+	ON CONFLICT(username) DO UPDATE SET
+		email = excluded.email,
+		token = excluded.token,
+		expires_time = excluded.expires_time`,
+		sql.Named("username", v.Username.String()),
+		sql.Named("email", v.Email.String()),
+		sql.Named("token", v.Token.String()),
+		sql.Named("expires_time", formatTime(v.ExpiresAt)))
+	return err
+}
+
+// UseEmailVerification deletes the email verification with the given token
+// and returns it, so that each verification works at most once. It also
+// clears out any other verifications that have expired.
+func (s Store) UseEmailVerification(token screenjournal.PasswordResetToken, now time.Time) (screenjournal.EmailVerification, error) {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return screenjournal.EmailVerification{}, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to rollback email verification transaction: %v", err)
+		}
+	}()
+
+	var username string
+	var email string
+	var expiresRaw string
+	err = tx.QueryRow(`
+	DELETE FROM
+		email_verifications
+	WHERE
+		token = :token
+	RETURNING
+		username,
+		email,
+		expires_time`,
+		sql.Named("token", token.String())).Scan(&username, &email, &expiresRaw)
+	if err == sql.ErrNoRows {
+		return screenjournal.EmailVerification{}, store.ErrInvalidEmailVerificationToken
+	} else if err != nil {
+		return screenjournal.EmailVerification{}, err
+	}
+
+	if _, err := tx.Exec(`
+	DELETE FROM
+		email_verifications
+	WHERE
+		expires_time < :now`, sql.Named("now", formatTime(now))); err != nil {
+		return screenjournal.EmailVerification{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return screenjournal.EmailVerification{}, err
+	}
+
+	expiresAt, err := parseDatetime(expiresRaw)
+	if err != nil {
+		return screenjournal.EmailVerification{}, err
+	}
+	if now.After(expiresAt) {
+		return screenjournal.EmailVerification{}, store.ErrExpiredEmailVerificationToken
+	}
+
+	return screenjournal.EmailVerification{
+		Username:  screenjournal.Username(username),
+		Email:     screenjournal.Email(email),
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
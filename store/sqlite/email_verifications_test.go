@@ -0,0 +1,113 @@
+package sqlite_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestUseEmailVerification(t *testing.T) {
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+
+	for _, tt := range []struct {
+		description string
+		expiresAt   time.Time
+		useIssued   bool
+		errOut      error
+	}{
+		{
+			description: "accepts a valid token",
+			expiresAt:   now.Add(time.Hour),
+			useIssued:   true,
+			errOut:      nil,
+		},
+		{
+			description: "rejects an expired token",
+			expiresAt:   now.Add(-time.Minute),
+			useIssued:   true,
+			errOut:      store.ErrExpiredEmailVerificationToken,
+		},
+		{
+			description: "rejects the wrong token",
+			expiresAt:   now.Add(time.Hour),
+			useIssued:   false,
+			errOut:      store.ErrInvalidEmailVerificationToken,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			db := test_sqlite.New()
+			insertCommentThreadTestData(t, db, "userA")
+
+			issued := screenjournal.NewPasswordResetToken()
+			if err := db.InsertEmailVerification(screenjournal.EmailVerification{
+				Username:  "userA",
+				Email:     "userA@example.org",
+				Token:     issued,
+				ExpiresAt: tt.expiresAt,
+			}); err != nil {
+				t.Fatalf("failed to insert email verification: %v", err)
+			}
+
+			token := issued
+			if !tt.useIssued {
+				token = screenjournal.NewPasswordResetToken()
+			}
+			v, err := db.UseEmailVerification(token, now)
+			if got, want := err, tt.errOut; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if tt.errOut != nil {
+				return
+			}
+			if got, want := v.Username, screenjournal.Username("userA"); !got.Equal(want) {
+				t.Errorf("username=%v, want=%v", got, want)
+			}
+			if got, want := v.Email, screenjournal.Email("userA@example.org"); got != want {
+				t.Errorf("email=%v, want=%v", got, want)
+			}
+
+			if _, err := db.UseEmailVerification(token, now); err != store.ErrInvalidEmailVerificationToken {
+				t.Errorf("reused token err=%v, want=%v", err, store.ErrInvalidEmailVerificationToken)
+			}
+		})
+	}
+}
+
+func TestUpdateUserEmail(t *testing.T) {
+	db := test_sqlite.New()
+	if err := db.InsertUser(screenjournal.User{
+		Username:     "userA",
+		Email:        "userA@example.com",
+		PasswordHash: screenjournal.PasswordHash("dummy-hash"),
+	}); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	if err := db.InsertUser(screenjournal.User{
+		Username:     "userB",
+		Email:        "userB@example.com",
+		PasswordHash: screenjournal.PasswordHash("dummy-hash"),
+	}); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+
+	if err := db.UpdateUserEmail("userA", "userA@example.org"); err != nil {
+		t.Fatalf("err=%v, want=%v", err, nil)
+	}
+	user, err := db.ReadUser("userA")
+	if err != nil {
+		t.Fatalf("failed to read user: %v", err)
+	}
+	if got, want := user.Email, screenjournal.Email("userA@example.org"); got != want {
+		t.Errorf("email=%v, want=%v", got, want)
+	}
+	if !user.EmailVerified {
+		t.Errorf("email is unverified after update")
+	}
+
+	if got, want := db.UpdateUserEmail("userA", "userB@example.com"), store.ErrEmailAssociatedWithAnotherAccount; got != want {
+		t.Errorf("taken email err=%v, want=%v", got, want)
+	}
+}
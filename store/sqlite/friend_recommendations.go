@@ -0,0 +1,213 @@
+package sqlite
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+func (s Store) ReadFriendRecommendation(id screenjournal.FriendRecommendationID) (screenjournal.FriendRecommendation, error) {
+	row := s.db.QueryRow(`
+	SELECT
+		id,
+		sender,
+		recipient,
+		movie_id,
+		tv_show_id,
+		tv_show_season,
+		message,
+		status,
+		created_time
+	FROM
+		friend_recommendations
+	WHERE
+		id = :id
+	`, sql.Named("id", id.UInt64()))
+
+	fr, err := friendRecommendationFromRow(row)
+	if err != nil {
+		return screenjournal.FriendRecommendation{}, err
+	}
+
+	return s.populateFriendRecommendationMedia(fr)
+}
+
+// ReadFriendRecommendationsTo returns all recommendations sent to the given
+// user, newest first.
+func (s Store) ReadFriendRecommendationsTo(recipient screenjournal.Username) ([]screenjournal.FriendRecommendation, error) {
+	rows, err := s.db.Query(`
+	SELECT
+		id,
+		sender,
+		recipient,
+		movie_id,
+		tv_show_id,
+		tv_show_season,
+		message,
+		status,
+		created_time
+	FROM
+		friend_recommendations
+	WHERE
+		recipient = :recipient
+	ORDER BY
+		created_time DESC,
+		id DESC
+	`, sql.Named("recipient", recipient.String()))
+	if err != nil {
+		return []screenjournal.FriendRecommendation{}, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close friend recommendation rows: %v", err)
+		}
+	}()
+
+	frs := []screenjournal.FriendRecommendation{}
+	for rows.Next() {
+		fr, err := friendRecommendationFromRow(rows)
+		if err != nil {
+			return []screenjournal.FriendRecommendation{}, err
+		}
+		frs = append(frs, fr)
+	}
+	if err := rows.Err(); err != nil {
+		return []screenjournal.FriendRecommendation{}, err
+	}
+
+	for i, fr := range frs {
+		if frs[i], err = s.populateFriendRecommendationMedia(fr); err != nil {
+			return []screenjournal.FriendRecommendation{}, err
+		}
+	}
+
+	return frs, nil
+}
+
+func (s Store) InsertFriendRecommendation(fr screenjournal.FriendRecommendation) (screenjournal.FriendRecommendationID, error) {
+	log.Printf("inserting new recommendation from %v to %v", fr.From, fr.To)
+
+	var movieID *screenjournal.MovieID
+	var tvShowID *screenjournal.TvShowID
+	var tvShowSeason *screenjournal.TvShowSeason
+	if fr.MediaType() == screenjournal.MediaTypeMovie {
+		movieID = &fr.Movie.ID
+	} else {
+		tvShowID = &fr.TvShow.ID
+		tvShowSeason = &fr.TvShowSeason
+	}
+
+	res, err := s.db.Exec(`
+	INSERT INTO
+		friend_recommendations
+	(
+		sender,
+		recipient,
+		movie_id,
+		tv_show_id,
+		tv_show_season,
+		message,
+		status,
+		created_time
+	)
+	VALUES (
+		:sender, :recipient, :movie_id, :tv_show_id, :tv_show_season, :message, :status, :created_time
+	)
+	`,
+		sql.Named("sender", fr.From.String()),
+		sql.Named("recipient", fr.To.String()),
+		sql.Named("movie_id", movieID),
+		sql.Named("tv_show_id", tvShowID),
+		sql.Named("tv_show_season", tvShowSeason),
+		sql.Named("message", fr.Message.String()),
+		sql.Named("status", screenjournal.FriendRecommendationPending.String()),
+		sql.Named("created_time", formatTime(time.Now())))
+	if err != nil {
+		return screenjournal.FriendRecommendationID(0), err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return screenjournal.FriendRecommendationID(0), err
+	}
+
+	return screenjournal.FriendRecommendationID(lastID), nil
+}
+
+func (s Store) UpdateFriendRecommendationStatus(id screenjournal.FriendRecommendationID, status screenjournal.FriendRecommendationStatus) error {
+	log.Printf("marking recommendation %v as %v", id, status)
+
+	if _, err := s.db.Exec(`
+	UPDATE friend_recommendations
+	SET
+		status = :status
+	WHERE
+		id = :id
+	`,
+		sql.Named("status", status.String()),
+		sql.Named("id", id.UInt64())); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Store) populateFriendRecommendationMedia(fr screenjournal.FriendRecommendation) (screenjournal.FriendRecommendation, error) {
+	var err error
+	if fr.MediaType() == screenjournal.MediaTypeMovie {
+		fr.Movie, err = s.ReadMovie(fr.Movie.ID)
+	} else {
+		fr.TvShow, err = s.ReadTvShow(fr.TvShow.ID)
+	}
+	if err != nil {
+		return screenjournal.FriendRecommendation{}, err
+	}
+	return fr, nil
+}
+
+func friendRecommendationFromRow(row rowScanner) (screenjournal.FriendRecommendation, error) {
+	var id int
+	var sender string
+	var recipient string
+	var movieIDRaw *int64
+	var tvShowIDRaw *int64
+	var tvShowSeasonRaw *uint8
+	var message string
+	var status string
+	var createdTimeRaw string
+
+	err := row.Scan(&id, &sender, &recipient, &movieIDRaw, &tvShowIDRaw, &tvShowSeasonRaw, &message, &status, &createdTimeRaw)
+	if err == sql.ErrNoRows {
+		return screenjournal.FriendRecommendation{}, store.ErrFriendRecommendationNotFound
+	} else if err != nil {
+		return screenjournal.FriendRecommendation{}, err
+	}
+
+	ct, err := parseDatetime(createdTimeRaw)
+	if err != nil {
+		return screenjournal.FriendRecommendation{}, err
+	}
+
+	fr := screenjournal.FriendRecommendation{
+		ID:      screenjournal.FriendRecommendationID(id),
+		From:    screenjournal.Username(sender),
+		To:      screenjournal.Username(recipient),
+		Message: screenjournal.RecommendationMessage(message),
+		Status:  screenjournal.FriendRecommendationStatus(status),
+		Created: ct,
+	}
+	if movieIDRaw != nil {
+		fr.Movie.ID = screenjournal.MovieID(*movieIDRaw)
+	}
+	if tvShowIDRaw != nil {
+		fr.TvShow.ID = screenjournal.TvShowID(*tvShowIDRaw)
+	}
+	if tvShowSeasonRaw != nil {
+		fr.TvShowSeason = screenjournal.TvShowSeason(*tvShowSeasonRaw)
+	}
+
+	return fr, nil
+}
@@ -0,0 +1,227 @@
+package sqlite
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+// ReadNotifications returns up to limit in-app notifications for the given
+// user, newest first. If before is nonzero, it returns only notifications older
+// than the notification with that ID, so callers can page through older
+// notifications.
+func (s Store) ReadNotifications(recipient screenjournal.Username, before screenjournal.NotificationID, limit int) ([]screenjournal.Notification, error) {
+	rows, err := s.db.Query(`
+	SELECT
+		n.id,
+		n.recipient,
+		n.actor,
+		n.kind,
+		n.review_id,
+		n.comment_id,
+		n.emoji,
+		n.is_read,
+		n.created_time,
+		r.review_owner,
+		r.movie_id,
+		r.tv_show_id,
+		r.tv_show_season,
+		COALESCE(m.title, t.title)
+	FROM
+		notifications n
+	JOIN
+		reviews r ON r.id = n.review_id
+	LEFT JOIN
+		movies m ON m.id = r.movie_id
+	LEFT JOIN
+		tv_shows t ON t.id = r.tv_show_id
+	WHERE
+		n.recipient = :recipient AND
+		(
+			:before = 0 OR
+			(n.created_time, n.id) < (SELECT created_time, id FROM notifications WHERE id = :before)
+		)
+	ORDER BY
+		n.created_time DESC,
+		n.id DESC
+	LIMIT :limit
+	`,
+		sql.Named("recipient", recipient.String()),
+		sql.Named("before", before.UInt64()),
+		sql.Named("limit", limit))
+	if err != nil {
+		return []screenjournal.Notification{}, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close notification rows: %v", err)
+		}
+	}()
+
+	notifications := []screenjournal.Notification{}
+	for rows.Next() {
+		n, err := notificationFromRow(rows)
+		if err != nil {
+			return []screenjournal.Notification{}, err
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return []screenjournal.Notification{}, err
+	}
+
+	return notifications, nil
+}
+
+// CountUnreadNotifications returns the number of in-app notifications the user
+// hasn't read yet.
+func (s Store) CountUnreadNotifications(recipient screenjournal.Username) (int, error) {
+	var count int
+	if err := s.db.QueryRow(`
+	SELECT
+		COUNT(*)
+	FROM
+		notifications
+	WHERE
+		recipient = :recipient AND
+		is_read = 0
+	`, sql.Named("recipient", recipient.String())).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s Store) InsertNotification(n screenjournal.Notification) error {
+	log.Printf("inserting %v notification for %v from %v", n.Kind, n.Recipient, n.Actor)
+
+	var commentID *uint64
+	if !n.Comment.ID.IsZero() {
+		commentID = new(n.Comment.ID.UInt64())
+	}
+	var emoji *string
+	if n.Emoji.String() != "" {
+		emoji = new(n.Emoji.String())
+	}
+
+	// Users never receive notifications about activity from users they've
+	// muted.
+	if _, err := s.db.Exec(`
+	INSERT INTO
+		notifications
+	(
+		recipient,
+		actor,
+		kind,
+		review_id,
+		comment_id,
+		emoji,
+		created_time
+	)
+	SELECT
+		:recipient, :actor, :kind, :review_id, :comment_id, :emoji, :created_time
+	WHERE
+		NOT EXISTS (
+			SELECT 1
+			FROM
+				user_relationships
+			WHERE
+				username = :recipient AND
+				target = :actor AND
+				relationship = 'mute'
+		)
+	`,
+		sql.Named("recipient", n.Recipient.String()),
+		sql.Named("actor", n.Actor.String()),
+		sql.Named("kind", n.Kind.String()),
+		sql.Named("review_id", n.Review.ID.UInt64()),
+		sql.Named("comment_id", commentID),
+		sql.Named("emoji", emoji),
+		sql.Named("created_time", formatTime(time.Now()))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarkAllNotificationsRead marks every in-app notification for the given user
+// as read.
+func (s Store) MarkAllNotificationsRead(recipient screenjournal.Username) error {
+	log.Printf("marking all notifications read for %v", recipient)
+
+	if _, err := s.db.Exec(`
+	UPDATE notifications
+	SET
+		is_read = 1
+	WHERE
+		recipient = :recipient AND
+		is_read = 0
+	`, sql.Named("recipient", recipient.String())); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func notificationFromRow(row rowScanner) (screenjournal.Notification, error) {
+	var id int
+	var recipient string
+	var actor string
+	var kind string
+	var reviewID int
+	var commentIDRaw *uint64
+	var emojiRaw *string
+	var isRead int
+	var createdTimeRaw string
+	var reviewOwner string
+	var movieIDRaw *int
+	var tvShowIDRaw *int
+	var tvShowSeasonRaw *int
+	var title string
+
+	if err := row.Scan(&id, &recipient, &actor, &kind, &reviewID, &commentIDRaw, &emojiRaw, &isRead, &createdTimeRaw, &reviewOwner, &movieIDRaw, &tvShowIDRaw, &tvShowSeasonRaw, &title); err != nil {
+		return screenjournal.Notification{}, err
+	}
+
+	ct, err := parseDatetime(createdTimeRaw)
+	if err != nil {
+		return screenjournal.Notification{}, err
+	}
+
+	n := screenjournal.Notification{
+		ID:        screenjournal.NotificationID(id),
+		Recipient: screenjournal.Username(recipient),
+		Actor:     screenjournal.Username(actor),
+		Kind:      screenjournal.NotificationKind(kind),
+		Review: screenjournal.Review{
+			ID:    screenjournal.ReviewID(reviewID),
+			Owner: screenjournal.Username(reviewOwner),
+		},
+		Read:    isRead == 1,
+		Created: ct,
+	}
+	if movieIDRaw != nil {
+		n.Review.Movie = screenjournal.Movie{
+			ID:    screenjournal.MovieID(*movieIDRaw),
+			Title: screenjournal.MediaTitle(title),
+		}
+	}
+	if tvShowIDRaw != nil {
+		n.Review.TvShow = screenjournal.TvShow{
+			ID:    screenjournal.TvShowID(*tvShowIDRaw),
+			Title: screenjournal.MediaTitle(title),
+		}
+	}
+	if tvShowSeasonRaw != nil {
+		n.Review.TvShowSeason = screenjournal.TvShowSeason(*tvShowSeasonRaw)
+	}
+	if commentIDRaw != nil {
+		n.Comment.ID = screenjournal.CommentID(*commentIDRaw)
+	}
+	if emojiRaw != nil {
+		n.Emoji = screenjournal.NewReactionEmoji(*emojiRaw)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,120 @@
+package sqlite_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/sqlite"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestNotificationsReadState(t *testing.T) {
+	db := test_sqlite.New()
+	review := insertCommentThreadTestData(t, db, "userA", "userB")
+
+	for _, n := range []screenjournal.Notification{
+		{
+			Recipient: screenjournal.Username("owner"),
+			Actor:     screenjournal.Username("userA"),
+			Kind:      screenjournal.NotificationKindReaction,
+			Review:    review,
+			Emoji:     screenjournal.NewReactionEmoji("👀"),
+		},
+		{
+			Recipient: screenjournal.Username("owner"),
+			Actor:     screenjournal.Username("userB"),
+			Kind:      screenjournal.NotificationKindMention,
+			Review:    review,
+		},
+		{
+			Recipient: screenjournal.Username("userA"),
+			Actor:     screenjournal.Username("owner"),
+			Kind:      screenjournal.NotificationKindNewReview,
+			Review:    review,
+		},
+	} {
+		if err := db.InsertNotification(n); err != nil {
+			t.Fatalf("failed to insert notification: %v", err)
+		}
+	}
+
+	notifications, err := db.ReadNotifications(screenjournal.Username("owner"), screenjournal.NotificationID(0), 10)
+	if err != nil {
+		t.Fatalf("failed to read notifications: %v", err)
+	}
+	if got, want := len(notifications), 2; got != want {
+		t.Fatalf("notifications=%d, want=%d", got, want)
+	}
+	// Newest notifications come first.
+	if got, want := notifications[0].Kind, screenjournal.NotificationKindMention; got != want {
+		t.Errorf("kind=%v, want=%v", got, want)
+	}
+	if got, want := notifications[1].Emoji.String(), "👀"; got != want {
+		t.Errorf("emoji=%v, want=%v", got, want)
+	}
+	if got, want := notifications[1].Review.Movie.Title, screenjournal.MediaTitle("The Waterboy"); got != want {
+		t.Errorf("title=%v, want=%v", got, want)
+	}
+
+	if got, want := mustCountUnread(t, db, "owner"), 2; got != want {
+		t.Errorf("unread=%d, want=%d", got, want)
+	}
+
+	if err := db.MarkAllNotificationsRead(screenjournal.Username("owner")); err != nil {
+		t.Fatalf("failed to mark notifications read: %v", err)
+	}
+
+	if got, want := mustCountUnread(t, db, "owner"), 0; got != want {
+		t.Errorf("unread=%d, want=%d", got, want)
+	}
+	// Marking one user's notifications read doesn't affect other users.
+	if got, want := mustCountUnread(t, db, "userA"), 1; got != want {
+		t.Errorf("unread=%d, want=%d", got, want)
+	}
+}
+
+func TestReadNotificationsPages(t *testing.T) {
+	db := test_sqlite.New()
+	review := insertCommentThreadTestData(t, db, "userA")
+
+	for range 5 {
+		if err := db.InsertNotification(screenjournal.Notification{
+			Recipient: screenjournal.Username("owner"),
+			Actor:     screenjournal.Username("userA"),
+			Kind:      screenjournal.NotificationKindMention,
+			Review:    review,
+		}); err != nil {
+			t.Fatalf("failed to insert notification: %v", err)
+		}
+	}
+
+	var ids []screenjournal.NotificationID
+	var before screenjournal.NotificationID
+	for _, wantLen := range []int{2, 2, 1, 0} {
+		page, err := db.ReadNotifications(screenjournal.Username("owner"), before, 2)
+		if err != nil {
+			t.Fatalf("failed to read notifications: %v", err)
+		}
+		if got, want := len(page), wantLen; got != want {
+			t.Fatalf("page size=%d, want=%d", got, want)
+		}
+		for _, n := range page {
+			ids = append(ids, n.ID)
+			before = n.ID
+		}
+	}
+
+	// Each notification appears exactly once, newest first.
+	if got, want := ids, []screenjournal.NotificationID{5, 4, 3, 2, 1}; !slices.Equal(got, want) {
+		t.Errorf("ids=%v, want=%v", got, want)
+	}
+}
+
+func mustCountUnread(t *testing.T, db sqlite.Store, username screenjournal.Username) int {
+	count, err := db.CountUnreadNotifications(username)
+	if err != nil {
+		t.Fatalf("failed to count unread notifications: %v", err)
+	}
+	return count
+}
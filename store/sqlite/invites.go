@@ -1,66 +1,139 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"time"
 
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
 )
 
 func (s Store) InsertSignupInvitation(invite screenjournal.SignupInvitation) error {
 	log.Printf("inserting new signup invite code for %s: %v", invite.Invitee, invite.InviteCode)
 
+	_, err := s.insertSignupInvitation(invite, "")
+	return err
+}
+
+// InsertMemberSignupInvitation adds an invite from a member who may only have
+// the given number of invites. If the member already has that many, it
+// returns store.ErrInviteLimitReached and doesn't add the invite.
+func (s Store) InsertMemberSignupInvitation(invite screenjournal.SignupInvitation, limit int) error {
+	log.Printf("inserting new signup invite code from %s for %s: %v", invite.InvitedBy, invite.Invitee, invite.InviteCode)
+
+	// Count the member's invites in the same statement as the insert so that
+	// parallel requests can't both squeeze in under the limit.
+	inserted, err := s.insertSignupInvitation(invite, `
+	WHERE
+		(SELECT COUNT(*) FROM invites WHERE invited_by = :invited_by) < :limit`,
+		sql.Named("limit", limit))
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		return store.ErrInviteLimitReached
+	}
+
+	return nil
+}
+
+func (s Store) insertSignupInvitation(invite screenjournal.SignupInvitation, where string, args ...any) (int64, error) {
 	now := time.Now()
 
-	if _, err := s.db.Exec(`
+	var invitedBy *string
+	if !invite.InvitedBy.Empty() {
+		invitedBy = new(invite.InvitedBy.String())
+	}
+	var email *string
+	if invite.Email != "" {
+		email = new(invite.Email.String())
+	}
+	var expires *string
+	if !invite.Expires.IsZero() {
+		expires = new(formatTime(invite.Expires))
+	}
+	maxUses := invite.MaxUses
+	if maxUses == 0 {
+		maxUses = 1
+	}
+
+	result, err := s.db.Exec(`
 	INSERT INTO
 		invites
 	(
 		invitee,
 		code,
+		invited_by,
+		email,
+		expires_time,
+		max_uses,
 		created_time
 	)
-	VALUES (
-		:invitee, :code, :created_time
-	)
-	`,
-		sql.Named("invitee", invite.Invitee),
-		sql.Named("code", invite.InviteCode),
-		sql.Named("created_time", formatTime(now))); err != nil {
-		return err
+	SELECT
+		:invitee, :code, :invited_by, :email, :expires_time, :max_uses, :created_time
+	`+where,
+		append([]any{
+			sql.Named("invitee", invite.Invitee),
+			sql.Named("code", invite.InviteCode),
+			sql.Named("invited_by", invitedBy),
+			sql.Named("email", email),
+			sql.Named("expires_time", expires),
+			sql.Named("max_uses", maxUses),
+			sql.Named("created_time", formatTime(now)),
+		}, args...)...)
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	return result.RowsAffected()
 }
 
 func (s Store) ReadSignupInvitation(code screenjournal.InviteCode) (screenjournal.SignupInvitation, error) {
-	var invitee string
-	if err := s.db.QueryRow(`
-		SELECT
-			invitee
-		FROM
-			invites
-		WHERE
-			code = :code`, sql.Named("code", code)).Scan(&invitee); err != nil {
+	invites, err := s.readSignupInvitations(`WHERE i.code = :code`, sql.Named("code", code.String()))
+	if err != nil {
 		return screenjournal.SignupInvitation{}, err
 	}
+	if len(invites) == 0 {
+		return screenjournal.SignupInvitation{}, store.ErrInviteNotFound
+	}
 
-	return screenjournal.SignupInvitation{
-		Invitee:    screenjournal.Invitee(invitee),
-		InviteCode: code,
-	}, nil
+	return invites[0], nil
 }
 
 func (s Store) ReadSignupInvitations() ([]screenjournal.SignupInvitation, error) {
+	return s.readSignupInvitations("")
+}
+
+// ReadSignupInvitationsByInviter returns the invites that the given user
+// created, newest first.
+func (s Store) ReadSignupInvitationsByInviter(username screenjournal.Username) ([]screenjournal.SignupInvitation, error) {
+	return s.readSignupInvitations(`WHERE i.invited_by = :invited_by`, sql.Named("invited_by", username.String()))
+}
+
+func (s Store) readSignupInvitations(where string, args ...any) ([]screenjournal.SignupInvitation, error) {
 	rows, err := s.db.Query(`
 		SELECT
-			invitee,
-			code
+			i.id,
+			i.invitee,
+			i.code,
+			i.email,
+			i.invited_by,
+			i.created_time,
+			i.expires_time,
+			i.max_uses,
+			r.username,
+			r.redeemed_time
 		FROM
-			invites
+			invites i
+		LEFT JOIN
+			invite_redemptions r ON r.invite_id = i.id
+		`+where+`
 		ORDER BY
-			created_time DESC`)
+			i.created_time DESC,
+			i.id DESC,
+			r.redeemed_time`, args...)
 	if err != nil {
 		return []screenjournal.SignupInvitation{}, err
 	}
@@ -71,17 +144,64 @@ func (s Store) ReadSignupInvitations() ([]screenjournal.SignupInvitation, error)
 	}()
 
 	invites := []screenjournal.SignupInvitation{}
+	var lastID int64
 	for rows.Next() {
+		var id int64
 		var inviteeRaw string
 		var inviteCodeRaw string
-		if err := rows.Scan(&inviteeRaw, &inviteCodeRaw); err != nil {
+		var emailRaw *string
+		var invitedByRaw *string
+		var createdTimeRaw string
+		var expiresTimeRaw *string
+		var maxUses int
+		var redeemedByRaw *string
+		var redeemedTimeRaw *string
+		if err := rows.Scan(&id, &inviteeRaw, &inviteCodeRaw, &emailRaw, &invitedByRaw, &createdTimeRaw, &expiresTimeRaw, &maxUses, &redeemedByRaw, &redeemedTimeRaw); err != nil {
 			return []screenjournal.SignupInvitation{}, err
 		}
 
-		invites = append(invites, screenjournal.SignupInvitation{
-			Invitee:    screenjournal.Invitee(inviteeRaw),
-			InviteCode: screenjournal.InviteCode(inviteCodeRaw),
-		})
+		// Each redemption adds another row for the same invite, so only start a
+		// new invite when the ID changes.
+		if len(invites) == 0 || id != lastID {
+			invite := screenjournal.SignupInvitation{
+				Invitee:     screenjournal.Invitee(inviteeRaw),
+				InviteCode:  screenjournal.InviteCode(inviteCodeRaw),
+				MaxUses:     maxUses,
+				Redemptions: []screenjournal.InviteRedemption{},
+			}
+			if emailRaw != nil {
+				invite.Email = screenjournal.Email(*emailRaw)
+			}
+			if invitedByRaw != nil {
+				invite.InvitedBy = screenjournal.Username(*invitedByRaw)
+			}
+			invite.Created, err = parseDatetime(createdTimeRaw)
+			if err != nil {
+				return []screenjournal.SignupInvitation{}, err
+			}
+			if expiresTimeRaw != nil {
+				invite.Expires, err = parseDatetime(*expiresTimeRaw)
+				if err != nil {
+					return []screenjournal.SignupInvitation{}, err
+				}
+			}
+			invites = append(invites, invite)
+			lastID = id
+		}
+
+		if redeemedTimeRaw == nil {
+			continue
+		}
+		redemption := screenjournal.InviteRedemption{}
+		if redeemedByRaw != nil {
+			redemption.Username = screenjournal.Username(*redeemedByRaw)
+		}
+		redemption.Redeemed, err = parseDatetime(*redeemedTimeRaw)
+		if err != nil {
+			return []screenjournal.SignupInvitation{}, err
+		}
+		current := &invites[len(invites)-1]
+		current.Redemptions = append(current.Redemptions, redemption)
 	}
 	if err := rows.Err(); err != nil {
 		return []screenjournal.SignupInvitation{}, err
@@ -90,11 +210,119 @@ func (s Store) ReadSignupInvitations() ([]screenjournal.SignupInvitation, error)
 	return invites, nil
 }
 
-func (s Store) DeleteSignupInvitation(code screenjournal.InviteCode) error {
-	log.Printf("deleting signup code: %s", code)
-	_, err := s.db.Exec(`DELETE FROM invites WHERE code = :code`, sql.Named("code", code.String()))
+// InsertInvitedUser adds a new user who signed up with the given invite and
+// records the invite redemption. If the invite has expired or has no uses
+// left, it returns an error and doesn't add the user.
+func (s Store) InsertInvitedUser(user screenjournal.User, code screenjournal.InviteCode, now time.Time) error {
+	log.Printf("inserting new user %s with signup code %s", user.Username, code)
+
+	tx, err := s.db.BeginTx(context.Background(), nil)
 	if err != nil {
 		return err
 	}
-	return nil
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to rollback insert invited user: %v", err)
+		}
+	}()
+
+	inviteID, err := checkSignupInvitation(tx, code, now)
+	if err != nil {
+		return err
+	}
+
+	if err := insertUser(tx, user, now); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+	INSERT INTO
+		invite_redemptions
+	(
+		invite_id,
+		username,
+		redeemed_time
+	)
+	VALUES (
+		:invite_id, :username, :redeemed_time
+	)`,
+		sql.Named("invite_id", inviteID),
+		sql.Named("username", user.Username.String()),
+		sql.Named("redeemed_time", formatTime(now))); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// checkSignupInvitation returns the ID of the invite with the given code if
+// someone can still sign up with it.
+func checkSignupInvitation(tx *sql.Tx, code screenjournal.InviteCode, now time.Time) (int64, error) {
+	var id int64
+	var expiresTimeRaw *string
+	var maxUses int
+	var uses int
+	err := tx.QueryRow(`
+	SELECT
+		i.id,
+		i.expires_time,
+		i.max_uses,
+		(SELECT COUNT(*) FROM invite_redemptions r WHERE r.invite_id = i.id)
+	FROM
+		invites i
+	WHERE
+		i.code = :code`, sql.Named("code", code.String())).Scan(&id, &expiresTimeRaw, &maxUses, &uses)
+	if err == sql.ErrNoRows {
+		return 0, store.ErrInviteNotFound
+	} else if err != nil {
+		return 0, err
+	}
+
+	if expiresTimeRaw != nil {
+		expires, err := parseDatetime(*expiresTimeRaw)
+		if err != nil {
+			return 0, err
+		}
+		if !now.Before(expires) {
+			return 0, store.ErrInviteExpired
+		}
+	}
+	if uses >= maxUses {
+		return 0, store.ErrInviteUsedUp
+	}
+
+	return id, nil
+}
+
+// ReadMemberInvitePolicy returns the admin's settings for whether members may
+// create invites.
+func (s Store) ReadMemberInvitePolicy() (screenjournal.MemberInvitePolicy, error) {
+	var policy screenjournal.MemberInvitePolicy
+	if err := s.db.QueryRow(`
+	SELECT
+		enabled,
+		invite_limit
+	FROM
+		member_invite_policy
+	WHERE
+		id = 1`).Scan(&policy.Enabled, &policy.Limit); err != nil {
+		return screenjournal.MemberInvitePolicy{}, err
+	}
+	return policy, nil
+}
+
+func (s Store) UpdateMemberInvitePolicy(policy screenjournal.MemberInvitePolicy) error {
+	log.Printf("updating member invite policy: enabled=%v, limit=%d", policy.Enabled, policy.Limit)
+
+	_, err := s.db.Exec(`
+	UPDATE
+		member_invite_policy
+	SET
+		enabled = :enabled,
+		invite_limit = :invite_limit
+	WHERE
+		id = 1`,
+		sql.Named("enabled", policy.Enabled),
+		sql.Named("invite_limit", policy.Limit))
+	return err
 }
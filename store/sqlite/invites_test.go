@@ -0,0 +1,212 @@
+package sqlite_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestInsertInvitedUser(t *testing.T) {
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+
+	for _, tt := range []struct {
+		description string
+		code        screenjournal.InviteCode
+		expires     time.Time
+		maxUses     int
+		priorUses   []string
+		errOut      error
+	}{
+		{
+			description: "redeems an invite that never expires",
+			code:        "abc456",
+			maxUses:     1,
+			errOut:      nil,
+		},
+		{
+			description: "redeems an invite before it expires",
+			code:        "abc456",
+			expires:     now.Add(time.Hour),
+			maxUses:     1,
+			errOut:      nil,
+		},
+		{
+			description: "redeems a multi-use invite that has uses left",
+			code:        "abc456",
+			maxUses:     2,
+			priorUses:   []string{"userB"},
+			errOut:      nil,
+		},
+		{
+			description: "rejects an expired invite",
+			code:        "abc456",
+			expires:     now.Add(-time.Hour),
+			maxUses:     1,
+			errOut:      store.ErrInviteExpired,
+		},
+		{
+			description: "rejects an invite with no uses left",
+			code:        "abc456",
+			maxUses:     1,
+			priorUses:   []string{"userB"},
+			errOut:      store.ErrInviteUsedUp,
+		},
+		{
+			description: "rejects an invite that doesn't exist",
+			code:        "zzz999",
+			maxUses:     1,
+			errOut:      store.ErrInviteNotFound,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			db := test_sqlite.New()
+			insertUser(t, db, "userA")
+
+			if err := db.InsertSignupInvitation(screenjournal.SignupInvitation{
+				Invitee:    screenjournal.Invitee("Sammy"),
+				InviteCode: screenjournal.InviteCode("abc456"),
+				InvitedBy:  screenjournal.Username("userA"),
+				Expires:    tt.expires,
+				MaxUses:    tt.maxUses,
+			}); err != nil {
+				t.Fatalf("failed to insert invite: %v", err)
+			}
+			for _, username := range tt.priorUses {
+				if err := db.InsertInvitedUser(newInvitedUser(username), "abc456", now.Add(-time.Minute)); err != nil {
+					t.Fatalf("failed to redeem invite for %s: %v", username, err)
+				}
+			}
+
+			err := db.InsertInvitedUser(newInvitedUser("userC"), tt.code, now)
+			if got, want := err, tt.errOut; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+
+			if tt.errOut != nil {
+				if _, err := db.ReadUser(screenjournal.Username("userC")); err != store.ErrUserNotFound {
+					t.Errorf("ReadUser err=%v, want=%v", err, store.ErrUserNotFound)
+				}
+				return
+			}
+
+			if _, err := db.ReadUser(screenjournal.Username("userC")); err != nil {
+				t.Fatalf("failed to read new user: %v", err)
+			}
+
+			invite, err := db.ReadSignupInvitation(tt.code)
+			if err != nil {
+				t.Fatalf("failed to read invite: %v", err)
+			}
+			if got, want := len(invite.Redemptions), len(tt.priorUses)+1; got != want {
+				t.Fatalf("redemptions=%d, want=%d", got, want)
+			}
+			last := invite.Redemptions[len(invite.Redemptions)-1]
+			if got, want := last.Username, screenjournal.Username("userC"); got != want {
+				t.Errorf("redeemed by=%v, want=%v", got, want)
+			}
+			if got, want := last.Redeemed, now; !got.Equal(want) {
+				t.Errorf("redeemed=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestDeleteUserKeepsInviteUses(t *testing.T) {
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+
+	db := test_sqlite.New()
+	insertUser(t, db, "userA")
+
+	if err := db.InsertSignupInvitation(screenjournal.SignupInvitation{
+		Invitee:    screenjournal.Invitee("Sammy"),
+		InviteCode: screenjournal.InviteCode("abc456"),
+		InvitedBy:  screenjournal.Username("userA"),
+		MaxUses:    1,
+	}); err != nil {
+		t.Fatalf("failed to insert invite: %v", err)
+	}
+	if err := db.InsertInvitedUser(newInvitedUser("userB"), "abc456", now); err != nil {
+		t.Fatalf("failed to redeem invite: %v", err)
+	}
+
+	for _, username := range []screenjournal.Username{"userA", "userB"} {
+		if err := db.DeleteUser(username, screenjournal.AccountDeletionDeleteContent); err != nil {
+			t.Fatalf("failed to delete user %s: %v", username, err)
+		}
+	}
+
+	invite, err := db.ReadSignupInvitation("abc456")
+	if err != nil {
+		t.Fatalf("failed to read invite: %v", err)
+	}
+	if got, want := invite.InvitedBy, screenjournal.Username(""); got != want {
+		t.Errorf("invitedBy=%v, want=%v", got, want)
+	}
+	if got, want := invite.Redemptions, []screenjournal.InviteRedemption{{Redeemed: now}}; len(got) != 1 || got[0].Username != want[0].Username || !got[0].Redeemed.Equal(want[0].Redeemed) {
+		t.Errorf("redemptions=%+v, want=%+v", got, want)
+	}
+
+	if got, want := db.InsertInvitedUser(newInvitedUser("userC"), "abc456", now), store.ErrInviteUsedUp; got != want {
+		t.Errorf("err=%v, want=%v", got, want)
+	}
+}
+
+func newInvitedUser(username string) screenjournal.User {
+	return screenjournal.User{
+		Username:     screenjournal.Username(username),
+		Email:        screenjournal.Email(username + "@example.com"),
+		PasswordHash: screenjournal.PasswordHash("dummy-password-hash"),
+	}
+}
+
+func TestInsertMemberSignupInvitationStopsAtLimit(t *testing.T) {
+	db := test_sqlite.New()
+	insertUser(t, db, "userA")
+	insertUser(t, db, "userB")
+
+	if err := db.InsertMemberSignupInvitation(screenjournal.SignupInvitation{
+		Invitee:    screenjournal.Invitee("Sammy"),
+		InviteCode: screenjournal.InviteCode("bbb111"),
+		InvitedBy:  screenjournal.Username("userB"),
+	}, 3); err != nil {
+		t.Fatalf("failed to insert invite from userB: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Go(func() {
+			errs[i] = db.InsertMemberSignupInvitation(screenjournal.SignupInvitation{
+				Invitee:    screenjournal.Invitee("Sammy"),
+				InviteCode: screenjournal.InviteCode(fmt.Sprintf("aaa%03d", i)),
+				InvitedBy:  screenjournal.Username("userA"),
+			}, 3)
+		})
+	}
+	wg.Wait()
+
+	inserted := 0
+	for _, err := range errs {
+		if err == nil {
+			inserted++
+		} else if err != store.ErrInviteLimitReached {
+			t.Fatalf("err=%v, want=%v", err, store.ErrInviteLimitReached)
+		}
+	}
+	if got, want := inserted, 3; got != want {
+		t.Errorf("inserted=%d, want=%d", got, want)
+	}
+
+	invites, err := db.ReadSignupInvitationsByInviter(screenjournal.Username("userA"))
+	if err != nil {
+		t.Fatalf("failed to read invites: %v", err)
+	}
+	if got, want := len(invites), 3; got != want {
+		t.Errorf("invites=%d, want=%d", got, want)
+	}
+}
@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+// InsertLoginLinkEntry saves a new login link for the user, replacing any
+// link they requested earlier.
+func (s Store) InsertLoginLinkEntry(entry screenjournal.LoginLinkEntry) error {
+	log.Printf("inserting new login link for user %s", entry.Username)
+
+	_, err := s.db.Exec(`
+	INSERT INTO
+		login_links
+	(
+		username,
+		token,
+		expires_time
+	)
+	VALUES (
+		:username, :token, :expires_time
+	)
+	ON CONFLICT(username) DO UPDATE SET
+		token = excluded.token,
+		expires_time = excluded.expires_time`,
+		sql.Named("username", entry.Username.String()),
+		sql.Named("token", entry.Token.String()),
+		sql.Named("expires_time", formatTime(entry.ExpiresAt)))
+	return err
+}
+
+// UseLoginLinkEntry deletes the user's login link if the token matches, so
+// that each link works at most once. It also clears out any other links that
+// have expired.
+func (s Store) UseLoginLinkEntry(username screenjournal.Username, token screenjournal.PasswordResetToken, now time.Time) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to rollback login link transaction: %v", err)
+		}
+	}()
+
+	var expiresRaw string
+	err = tx.QueryRow(`
+	DELETE FROM
+		login_links
+	WHERE
+		username = :username AND
+		token = :token
+	RETURNING
+		expires_time`,
+		sql.Named("username", username.String()),
+		sql.Named("token", token.String())).Scan(&expiresRaw)
+	if err == sql.ErrNoRows {
+		return store.ErrInvalidLoginLinkToken
+	} else if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+	DELETE FROM
+		login_links
+	WHERE
+		expires_time < :now`, sql.Named("now", formatTime(now))); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	expiresAt, err := parseDatetime(expiresRaw)
+	if err != nil {
+		return err
+	}
+	if now.After(expiresAt) {
+		return store.ErrExpiredLoginLinkToken
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package sqlite_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestUseLoginLinkEntry(t *testing.T) {
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+
+	for _, tt := range []struct {
+		description string
+		username    screenjournal.Username
+		token       func(issued screenjournal.PasswordResetToken) screenjournal.PasswordResetToken
+		expiresAt   time.Time
+		errOut      error
+	}{
+		{
+			description: "accepts a valid link",
+			username:    "userA",
+			token:       func(issued screenjournal.PasswordResetToken) screenjournal.PasswordResetToken { return issued },
+			expiresAt:   now.Add(15 * time.Minute),
+			errOut:      nil,
+		},
+		{
+			description: "rejects an expired link",
+			username:    "userA",
+			token:       func(issued screenjournal.PasswordResetToken) screenjournal.PasswordResetToken { return issued },
+			expiresAt:   now.Add(-time.Minute),
+			errOut:      store.ErrExpiredLoginLinkToken,
+		},
+		{
+			description: "rejects another user's link",
+			username:    "userB",
+			token:       func(issued screenjournal.PasswordResetToken) screenjournal.PasswordResetToken { return issued },
+			expiresAt:   now.Add(15 * time.Minute),
+			errOut:      store.ErrInvalidLoginLinkToken,
+		},
+		{
+			description: "rejects the wrong token",
+			username:    "userA",
+			token: func(screenjournal.PasswordResetToken) screenjournal.PasswordResetToken {
+				return screenjournal.NewPasswordResetToken()
+			},
+			expiresAt: now.Add(15 * time.Minute),
+			errOut:    store.ErrInvalidLoginLinkToken,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			db := test_sqlite.New()
+			insertCommentThreadTestData(t, db, "userA", "userB")
+
+			issued := screenjournal.NewPasswordResetToken()
+			if err := db.InsertLoginLinkEntry(screenjournal.LoginLinkEntry{
+				Username:  "userA",
+				Token:     issued,
+				ExpiresAt: tt.expiresAt,
+			}); err != nil {
+				t.Fatalf("failed to insert login link: %v", err)
+			}
+
+			if got, want := db.UseLoginLinkEntry(tt.username, tt.token(issued), now), tt.errOut; got != want {
+				t.Errorf("err=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoginLinkEntryWorksOnce(t *testing.T) {
+	db := test_sqlite.New()
+	insertCommentThreadTestData(t, db, "userA")
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+
+	first := screenjournal.NewPasswordResetToken()
+	second := screenjournal.NewPasswordResetToken()
+	for _, token := range []screenjournal.PasswordResetToken{first, second} {
+		if err := db.InsertLoginLinkEntry(screenjournal.LoginLinkEntry{
+			Username:  "userA",
+			Token:     token,
+			ExpiresAt: now.Add(15 * time.Minute),
+		}); err != nil {
+			t.Fatalf("failed to insert login link: %v", err)
+		}
+	}
+
+	// Requesting a new link replaces the old one.
+	if got, want := db.UseLoginLinkEntry("userA", first, now), store.ErrInvalidLoginLinkToken; got != want {
+		t.Errorf("replaced link err=%v, want=%v", got, want)
+	}
+	if err := db.UseLoginLinkEntry("userA", second, now); err != nil {
+		t.Fatalf("latest link err=%v, want=%v", err, nil)
+	}
+	if got, want := db.UseLoginLinkEntry("userA", second, now), store.ErrInvalidLoginLinkToken; got != want {
+		t.Errorf("reused link err=%v, want=%v", got, want)
+	}
+}
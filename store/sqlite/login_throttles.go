@@ -0,0 +1,182 @@
+package sqlite
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+func (s Store) ReadLoginThrottle(scope screenjournal.LoginThrottleScope, key string) (screenjournal.LoginThrottle, error) {
+	row := s.db.QueryRow(`
+	SELECT
+		scope,
+		throttle_key,
+		failures,
+		last_failure_at,
+		blocked_until
+	FROM
+		login_throttles
+	WHERE
+		scope = :scope AND
+		throttle_key = :key`,
+		sql.Named("scope", scope.String()),
+		sql.Named("key", key))
+
+	throttle, err := loginThrottleFromRow(row)
+	if err == sql.ErrNoRows {
+		return screenjournal.LoginThrottle{}, store.ErrLoginThrottleNotFound
+	} else if err != nil {
+		return screenjournal.LoginThrottle{}, err
+	}
+
+	return throttle, nil
+}
+
+// ReadBlockedLoginThrottles returns the throttles that are blocking logins at
+// the given time, the longest blocks first.
+func (s Store) ReadBlockedLoginThrottles(now time.Time) ([]screenjournal.LoginThrottle, error) {
+	rows, err := s.db.Query(`
+	SELECT
+		scope,
+		throttle_key,
+		failures,
+		last_failure_at,
+		blocked_until
+	FROM
+		login_throttles
+	WHERE
+		blocked_until IS NOT NULL AND
+		datetime(blocked_until) > datetime(:now)
+	ORDER BY
+		datetime(blocked_until) DESC,
+		scope ASC,
+		throttle_key ASC`, sql.Named("now", formatTime(now)))
+	if err != nil {
+		return []screenjournal.LoginThrottle{}, err
+	}
+	defer rows.Close()
+
+	throttles := []screenjournal.LoginThrottle{}
+	for rows.Next() {
+		throttle, err := loginThrottleFromRow(rows)
+		if err != nil {
+			return []screenjournal.LoginThrottle{}, err
+		}
+		throttles = append(throttles, throttle)
+	}
+	if err := rows.Err(); err != nil {
+		return []screenjournal.LoginThrottle{}, err
+	}
+
+	return throttles, nil
+}
+
+// UpsertLoginThrottle saves the throttle, replacing any existing throttle for
+// the same scope and key.
+func (s Store) UpsertLoginThrottle(throttle screenjournal.LoginThrottle) error {
+	var blockedUntil *string
+	if !throttle.BlockedUntil.IsZero() {
+		blockedUntil = new(formatTime(throttle.BlockedUntil))
+	}
+
+	_, err := s.db.Exec(`
+	INSERT INTO
+		login_throttles
+	(
+		scope,
+		throttle_key,
+		failures,
+		last_failure_at,
+		blocked_until
+	)
+	VALUES (
+		:scope, :key, :failures, :last_failure_at, :blocked_until
+	)
+	ON CONFLICT (scope, throttle_key) DO UPDATE SET
+		failures = excluded.failures,
+		last_failure_at = excluded.last_failure_at,
+		blocked_until = excluded.blocked_until`,
+		sql.Named("scope", throttle.Scope.String()),
+		sql.Named("key", throttle.Key),
+		sql.Named("failures", throttle.Failures),
+		sql.Named("last_failure_at", formatTime(throttle.LastFailureAt)),
+		sql.Named("blocked_until", blockedUntil))
+	return err
+}
+
+// DeleteLoginThrottle clears the failed login count for the given scope and
+// key. It returns ErrLoginThrottleNotFound if there was nothing to clear.
+func (s Store) DeleteLoginThrottle(scope screenjournal.LoginThrottleScope, key string) error {
+	log.Printf("clearing login throttle for %s %s", scope, key)
+
+	result, err := s.db.Exec(`
+	DELETE FROM
+		login_throttles
+	WHERE
+		scope = :scope AND
+		throttle_key = :key`,
+		sql.Named("scope", scope.String()),
+		sql.Named("key", key))
+	if err != nil {
+		return err
+	}
+	rowsDeleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsDeleted != 1 {
+		return store.ErrLoginThrottleNotFound
+	}
+
+	return nil
+}
+
+// DeleteStaleLoginThrottles removes throttles whose last failure was before
+// the given time and that are no longer blocking logins.
+func (s Store) DeleteStaleLoginThrottles(before time.Time) error {
+	_, err := s.db.Exec(`
+	DELETE FROM
+		login_throttles
+	WHERE
+		datetime(last_failure_at) < datetime(:before) AND
+		(
+			blocked_until IS NULL OR
+			datetime(blocked_until) < datetime(:before)
+		)`, sql.Named("before", formatTime(before)))
+	return err
+}
+
+func loginThrottleFromRow(row rowScanner) (screenjournal.LoginThrottle, error) {
+	var scope string
+	var key string
+	var failures int
+	var lastFailureAtRaw string
+	var blockedUntilRaw *string
+	if err := row.Scan(&scope, &key, &failures, &lastFailureAtRaw, &blockedUntilRaw); err != nil {
+		return screenjournal.LoginThrottle{}, err
+	}
+
+	lastFailureAt, err := parseDatetime(lastFailureAtRaw)
+	if err != nil {
+		return screenjournal.LoginThrottle{}, err
+	}
+
+	var blockedUntil time.Time
+	if blockedUntilRaw != nil {
+		blockedUntil, err = parseDatetime(*blockedUntilRaw)
+		if err != nil {
+			return screenjournal.LoginThrottle{}, err
+		}
+	}
+
+	return screenjournal.LoginThrottle{
+		Scope:         screenjournal.LoginThrottleScope(scope),
+		Key:           key,
+		Failures:      failures,
+		LastFailureAt: lastFailureAt,
+		BlockedUntil:  blockedUntil,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package sqlite_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestLoginThrottles(t *testing.T) {
+	db := test_sqlite.New()
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+
+	lockedUser := screenjournal.LoginThrottle{
+		Scope:         screenjournal.LoginThrottleScopeUsername,
+		Key:           "userA",
+		Failures:      10,
+		LastFailureAt: now.Add(-5 * time.Minute),
+		BlockedUntil:  now.Add(10 * time.Minute),
+	}
+	delayedIP := screenjournal.LoginThrottle{
+		Scope:         screenjournal.LoginThrottleScopeIP,
+		Key:           "203.0.113.7",
+		Failures:      11,
+		LastFailureAt: now.Add(-time.Second),
+		BlockedUntil:  now.Add(time.Second),
+	}
+	unblockedUser := screenjournal.LoginThrottle{
+		Scope:         screenjournal.LoginThrottleScopeUsername,
+		Key:           "userB",
+		Failures:      1,
+		LastFailureAt: now.Add(-2 * time.Hour),
+	}
+	for _, throttle := range []screenjournal.LoginThrottle{lockedUser, delayedIP, unblockedUser} {
+		if err := db.UpsertLoginThrottle(throttle); err != nil {
+			t.Fatalf("UpsertLoginThrottle err=%v, want=%v", err, nil)
+		}
+	}
+
+	got, err := db.ReadLoginThrottle(screenjournal.LoginThrottleScopeUsername, "userB")
+	if err != nil {
+		t.Fatalf("ReadLoginThrottle err=%v, want=%v", err, nil)
+	}
+	if want := unblockedUser; !reflect.DeepEqual(got, want) {
+		t.Errorf("throttle=%+v, want=%+v", got, want)
+	}
+
+	// The same key in a different scope is a different throttle.
+	if _, err := db.ReadLoginThrottle(screenjournal.LoginThrottleScopeIP, "userB"); err != store.ErrLoginThrottleNotFound {
+		t.Errorf("ReadLoginThrottle err=%v, want=%v", err, store.ErrLoginThrottleNotFound)
+	}
+
+	blocked, err := db.ReadBlockedLoginThrottles(now)
+	if err != nil {
+		t.Fatalf("ReadBlockedLoginThrottles err=%v, want=%v", err, nil)
+	}
+	if got, want := blocked, []screenjournal.LoginThrottle{lockedUser, delayedIP}; !reflect.DeepEqual(got, want) {
+		t.Errorf("blocked=%+v, want=%+v", got, want)
+	}
+
+	// Upserting replaces the existing throttle.
+	delayedIP.Failures = 12
+	delayedIP.BlockedUntil = now.Add(2 * time.Second)
+	if err := db.UpsertLoginThrottle(delayedIP); err != nil {
+		t.Fatalf("UpsertLoginThrottle err=%v, want=%v", err, nil)
+	}
+	got, err = db.ReadLoginThrottle(delayedIP.Scope, delayedIP.Key)
+	if err != nil {
+		t.Fatalf("ReadLoginThrottle err=%v, want=%v", err, nil)
+	}
+	if want := delayedIP; !reflect.DeepEqual(got, want) {
+		t.Errorf("throttle after upsert=%+v, want=%+v", got, want)
+	}
+
+	if err := db.DeleteStaleLoginThrottles(now.Add(-time.Hour)); err != nil {
+		t.Fatalf("DeleteStaleLoginThrottles err=%v, want=%v", err, nil)
+	}
+	if _, err := db.ReadLoginThrottle(unblockedUser.Scope, unblockedUser.Key); err != store.ErrLoginThrottleNotFound {
+		t.Errorf("stale throttle err=%v, want=%v", err, store.ErrLoginThrottleNotFound)
+	}
+
+	if err := db.DeleteLoginThrottle(lockedUser.Scope, lockedUser.Key); err != nil {
+		t.Fatalf("DeleteLoginThrottle err=%v, want=%v", err, nil)
+	}
+	if err := db.DeleteLoginThrottle(lockedUser.Scope, lockedUser.Key); err != store.ErrLoginThrottleNotFound {
+		t.Errorf("deleting missing throttle err=%v, want=%v", err, store.ErrLoginThrottleNotFound)
+	}
+}
@@ -0,0 +1,21 @@
+CREATE TABLE friend_recommendations (
+    id INTEGER PRIMARY KEY,
+    sender TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    movie_id INTEGER,
+    tv_show_id INTEGER,
+    tv_show_season INTEGER CHECK (tv_show_season IS NULL OR tv_show_season > 0),
+    message TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'watched', 'dismissed')),
+    created_time TEXT NOT NULL CHECK (datetime(created_time) IS NOT NULL),
+    FOREIGN KEY (sender) REFERENCES users (username),
+    FOREIGN KEY (recipient) REFERENCES users (username),
+    FOREIGN KEY (movie_id) REFERENCES movies (id),
+    FOREIGN KEY (tv_show_id) REFERENCES tv_shows (id),
+    CHECK ((movie_id IS NULL) != (tv_show_id IS NULL)),
+    CHECK ((tv_show_id IS NOT NULL) = (tv_show_season IS NOT NULL)),
+    CHECK (sender != recipient)
+) STRICT;
+
+CREATE INDEX idx_friend_recommendations_recipient
+ON friend_recommendations (recipient);
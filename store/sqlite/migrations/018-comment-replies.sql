@@ -0,0 +1,4 @@
+ALTER TABLE review_comments ADD COLUMN parent_comment_id INTEGER
+REFERENCES review_comments (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_review_comments_parent ON review_comments (parent_comment_id);
@@ -0,0 +1,33 @@
+-- Reactions can now target either a review or a comment on a review, so the
+-- uniqueness constraint has to account for the comment.
+CREATE TABLE review_reactions_new (
+    id INTEGER PRIMARY KEY,
+    review_id INTEGER NOT NULL,
+    comment_id INTEGER,
+    reaction_owner TEXT NOT NULL,
+    emoji TEXT NOT NULL,
+    created_time TEXT NOT NULL,
+    FOREIGN KEY (review_id) REFERENCES reviews (id),
+    FOREIGN KEY (comment_id) REFERENCES review_comments (id),
+    FOREIGN KEY (reaction_owner) REFERENCES users (username)
+);
+
+INSERT INTO review_reactions_new
+SELECT
+    id,
+    review_id,
+    NULL,
+    reaction_owner,
+    emoji,
+    created_time
+FROM review_reactions;
+
+DROP TABLE review_reactions;
+
+ALTER TABLE review_reactions_new RENAME TO review_reactions;
+
+CREATE UNIQUE INDEX idx_review_reactions_unique ON review_reactions (
+    review_id, IFNULL(comment_id, 0), reaction_owner, emoji
+);
+
+CREATE INDEX idx_review_reactions_comment ON review_reactions (comment_id);
@@ -0,0 +1,3 @@
+ALTER TABLE notification_preferences ADD COLUMN mentions INTEGER NOT NULL CHECK (
+    mentions IN (0, 1)
+) DEFAULT 1;
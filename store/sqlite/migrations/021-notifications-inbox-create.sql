@@ -0,0 +1,20 @@
+CREATE TABLE notifications (
+    id INTEGER PRIMARY KEY,
+    recipient TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (
+        kind IN ('new-review', 'comment', 'reply', 'reaction', 'mention')
+    ),
+    review_id INTEGER NOT NULL,
+    comment_id INTEGER,
+    emoji TEXT,
+    is_read INTEGER NOT NULL CHECK (is_read IN (0, 1)) DEFAULT 0,
+    created_time TEXT NOT NULL CHECK (datetime(created_time) IS NOT NULL),
+    FOREIGN KEY (recipient) REFERENCES users (username),
+    FOREIGN KEY (actor) REFERENCES users (username),
+    FOREIGN KEY (review_id) REFERENCES reviews (id),
+    FOREIGN KEY (comment_id) REFERENCES review_comments (id)
+) STRICT;
+
+CREATE INDEX idx_notifications_recipient
+ON notifications (recipient, is_read);
@@ -0,0 +1,27 @@
+CREATE TABLE webhooks (
+    id INTEGER PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    created_time TEXT NOT NULL CHECK (datetime(created_time) IS NOT NULL)
+) STRICT;
+
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY,
+    webhook_id INTEGER NOT NULL,
+    event TEXT NOT NULL,
+    payload BLOB NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_time TEXT NOT NULL CHECK (
+        datetime(next_attempt_time) IS NOT NULL
+    ),
+    last_result TEXT NOT NULL DEFAULT '',
+    created_time TEXT NOT NULL CHECK (datetime(created_time) IS NOT NULL),
+    FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE
+) STRICT;
+
+CREATE INDEX idx_webhook_deliveries_pending
+ON webhook_deliveries (status, next_attempt_time);
+
+CREATE INDEX idx_webhook_deliveries_webhook
+ON webhook_deliveries (webhook_id);
@@ -0,0 +1,20 @@
+CREATE TABLE email_outbox (
+    id INTEGER PRIMARY KEY,
+    -- dedupe_key prevents the same message from being queued twice.
+    dedupe_key TEXT NOT NULL UNIQUE,
+    from_address TEXT NOT NULL,
+    to_addresses TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    html_body TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_time TEXT NOT NULL CHECK (
+        datetime(next_attempt_time) IS NOT NULL
+    ),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_time TEXT NOT NULL CHECK (datetime(created_time) IS NOT NULL)
+) STRICT;
+
+CREATE INDEX idx_email_outbox_pending
+ON email_outbox (status, next_attempt_time);
@@ -0,0 +1,7 @@
+ALTER TABLE notification_preferences ADD COLUMN digest_frequency TEXT NOT NULL CHECK (
+    digest_frequency IN ('immediate', 'daily', 'weekly', 'off')
+) DEFAULT 'immediate';
+
+ALTER TABLE notification_preferences ADD COLUMN last_digest_time TEXT CHECK (
+    last_digest_time IS NULL OR datetime(last_digest_time) IS NOT NULL
+);
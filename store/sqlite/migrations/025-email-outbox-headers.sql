@@ -0,0 +1,3 @@
+-- headers holds extra message headers as a JSON object that maps each header
+-- name to a list of values.
+ALTER TABLE email_outbox ADD COLUMN headers TEXT NOT NULL DEFAULT '{}';
@@ -0,0 +1,9 @@
+-- signing_keys holds server-side secrets for signing tokens that ScreenJournal
+-- verifies later, such as unsubscribe links.
+CREATE TABLE signing_keys (
+    name TEXT PRIMARY KEY,
+    signing_key TEXT NOT NULL
+) STRICT;
+
+INSERT INTO signing_keys (name, signing_key)
+VALUES ('unsubscribe', lower(hex(randomblob(32))));
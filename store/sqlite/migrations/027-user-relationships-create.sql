@@ -0,0 +1,17 @@
+CREATE TABLE user_relationships (
+    username TEXT NOT NULL,
+    target TEXT NOT NULL,
+    relationship TEXT NOT NULL CHECK (relationship IN ('follow', 'mute')),
+    created_time TEXT NOT NULL CHECK (datetime(created_time) IS NOT NULL),
+    PRIMARY KEY (username, target),
+    FOREIGN KEY (username) REFERENCES users (username),
+    FOREIGN KEY (target) REFERENCES users (username),
+    CHECK (username != target)
+) STRICT;
+
+CREATE INDEX idx_user_relationships_target
+ON user_relationships (target, relationship);
+
+ALTER TABLE notification_preferences ADD COLUMN new_reviews_scope TEXT NOT NULL CHECK (
+    new_reviews_scope IN ('everyone', 'following')
+) DEFAULT 'everyone';
@@ -0,0 +1,23 @@
+-- two_factor holds each user's TOTP secret. A row with a NULL enabled_time is
+-- an enrollment that the user hasn't confirmed with a code yet.
+CREATE TABLE two_factor (
+    username TEXT PRIMARY KEY,
+    totp_secret TEXT NOT NULL,
+    enabled_time TEXT CHECK (
+        enabled_time IS NULL OR datetime(enabled_time) IS NOT NULL
+    ),
+    last_used_step INTEGER NOT NULL DEFAULT 0,
+    created_time TEXT NOT NULL CHECK (datetime(created_time) IS NOT NULL),
+    FOREIGN KEY (username) REFERENCES users (username)
+) STRICT;
+
+CREATE TABLE two_factor_recovery_codes (
+    username TEXT NOT NULL,
+    code_hash TEXT NOT NULL,
+    used_time TEXT CHECK (used_time IS NULL OR datetime(used_time) IS NOT NULL),
+    PRIMARY KEY (username, code_hash),
+    FOREIGN KEY (username) REFERENCES users (username)
+) STRICT;
+
+INSERT INTO signing_keys (name, signing_key)
+VALUES ('login-challenge', lower(hex(randomblob(32))));
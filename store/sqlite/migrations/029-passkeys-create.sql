@@ -0,0 +1,25 @@
+CREATE TABLE passkeys (
+    id TEXT PRIMARY KEY,
+    username TEXT NOT NULL,
+    name TEXT NOT NULL,
+    public_key BLOB NOT NULL,
+    sign_count INTEGER NOT NULL DEFAULT 0 CHECK (sign_count >= 0),
+    created_time TEXT NOT NULL CHECK (datetime(created_time) IS NOT NULL),
+    last_used_time TEXT CHECK (
+        last_used_time IS NULL OR datetime(last_used_time) IS NOT NULL
+    ),
+    FOREIGN KEY (username) REFERENCES users (username)
+) STRICT;
+
+CREATE INDEX idx_passkeys_username ON passkeys (username);
+
+-- passkey_challenges holds the outstanding challenges for passkey
+-- registrations and logins. Each challenge is deleted when it's used.
+CREATE TABLE passkey_challenges (
+    challenge TEXT PRIMARY KEY,
+    ceremony TEXT NOT NULL CHECK (ceremony IN ('registration', 'login')),
+    username TEXT,
+    expires_time TEXT NOT NULL CHECK (datetime(expires_time) IS NOT NULL),
+    FOREIGN KEY (username) REFERENCES users (username),
+    CHECK ((ceremony = 'registration') = (username IS NOT NULL))
+) STRICT;
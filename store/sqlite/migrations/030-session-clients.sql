@@ -0,0 +1,9 @@
+ALTER TABLE auth_sessions ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE auth_sessions ADD COLUMN ip_address TEXT NOT NULL DEFAULT '';
+-- last_seen_at is NULL until the session is used after the request that
+-- created it.
+ALTER TABLE auth_sessions ADD COLUMN last_seen_at TEXT CHECK (
+    last_seen_at IS NULL OR datetime(last_seen_at) IS NOT NULL
+);
+
+CREATE INDEX idx_auth_sessions_user_id ON auth_sessions (user_id);
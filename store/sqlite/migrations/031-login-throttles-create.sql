@@ -0,0 +1,12 @@
+-- login_throttles counts recent failed logins per username and per IP address
+-- so that the limits survive a server restart.
+CREATE TABLE login_throttles (
+    scope TEXT NOT NULL CHECK (scope IN ('username', 'ip')),
+    throttle_key TEXT NOT NULL,
+    failures INTEGER NOT NULL CHECK (failures > 0),
+    last_failure_at TEXT NOT NULL CHECK (datetime(last_failure_at) IS NOT NULL),
+    blocked_until TEXT CHECK (
+        blocked_until IS NULL OR datetime(blocked_until) IS NOT NULL
+    ),
+    PRIMARY KEY (scope, throttle_key)
+) STRICT;
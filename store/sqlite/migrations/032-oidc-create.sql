@@ -0,0 +1,23 @@
+-- oidc_identities links users to their accounts at an external OpenID
+-- Connect identity provider.
+CREATE TABLE oidc_identities (
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    username TEXT NOT NULL,
+    created_time TEXT NOT NULL CHECK (datetime(created_time) IS NOT NULL),
+    PRIMARY KEY (issuer, subject),
+    FOREIGN KEY (username) REFERENCES users (username)
+) STRICT;
+
+CREATE INDEX idx_oidc_identities_username ON oidc_identities (username);
+
+-- oidc_login_states holds the state for logins in progress at the identity
+-- provider. Each state is deleted when the identity provider redirects the
+-- user back.
+CREATE TABLE oidc_login_states (
+    state TEXT PRIMARY KEY,
+    nonce TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    next_path TEXT NOT NULL,
+    expires_time TEXT NOT NULL CHECK (datetime(expires_time) IS NOT NULL)
+) STRICT;
@@ -0,0 +1,8 @@
+-- login_links holds the outstanding emailed login links. Each user has at most
+-- one, and it's deleted when it's used.
+CREATE TABLE login_links (
+    username TEXT PRIMARY KEY,
+    token TEXT NOT NULL UNIQUE,
+    expires_time TEXT NOT NULL CHECK (datetime(expires_time) IS NOT NULL),
+    FOREIGN KEY (username) REFERENCES users (username)
+) STRICT;
@@ -0,0 +1,15 @@
+ALTER TABLE users ADD COLUMN email_verified INTEGER NOT NULL DEFAULT 0;
+
+-- Users who signed up before we verified email addresses have been receiving
+-- email all along, so treat their addresses as verified.
+UPDATE users SET email_verified = 1;
+
+-- email_verifications holds the outstanding email verification links. Each
+-- user has at most one, and it's deleted when it's used.
+CREATE TABLE email_verifications (
+    username TEXT PRIMARY KEY,
+    email TEXT NOT NULL,
+    token TEXT NOT NULL UNIQUE,
+    expires_time TEXT NOT NULL CHECK (datetime(expires_time) IS NOT NULL),
+    FOREIGN KEY (username) REFERENCES users (username)
+) STRICT;
@@ -0,0 +1,11 @@
+-- Admins can disable accounts to stop users from logging in without deleting
+-- their data.
+ALTER TABLE users ADD COLUMN is_disabled INTEGER NOT NULL DEFAULT 0 CHECK (
+    is_disabled IN (0, 1)
+);
+
+-- last_login_time is NULL for users who haven't logged in since we started
+-- tracking logins.
+ALTER TABLE users ADD COLUMN last_login_time TEXT CHECK (
+    last_login_time IS NULL OR datetime(last_login_time) IS NOT NULL
+);
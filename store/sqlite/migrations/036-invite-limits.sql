@@ -0,0 +1,47 @@
+-- invited_by is NULL for invites created before we tracked who created them
+-- and for invites whose creator has since deleted their account.
+ALTER TABLE invites ADD COLUMN invited_by TEXT REFERENCES users (username);
+
+-- email is the address we sent the invite to, or NULL if the inviter shared
+-- the link themselves.
+ALTER TABLE invites ADD COLUMN email TEXT CHECK (
+    email IS NULL OR length(email) > 0
+);
+
+-- expires_time is NULL for invites that never expire.
+ALTER TABLE invites ADD COLUMN expires_time TEXT CHECK (
+    expires_time IS NULL OR datetime(expires_time) IS NOT NULL
+);
+
+-- Invites used to be deleted when someone signed up with them, so existing
+-- invites have never been used.
+ALTER TABLE invites ADD COLUMN max_uses INTEGER NOT NULL DEFAULT 1 CHECK (
+    max_uses >= 1
+);
+
+-- invite_redemptions records each account that signed up with an invite.
+-- username is NULL if the account has since been deleted, so that the
+-- redemption still counts against the invite's uses.
+CREATE TABLE invite_redemptions (
+    id INTEGER PRIMARY KEY,
+    invite_id INTEGER NOT NULL,
+    username TEXT,
+    redeemed_time TEXT NOT NULL CHECK (datetime(redeemed_time) IS NOT NULL),
+    FOREIGN KEY (invite_id) REFERENCES invites (id),
+    FOREIGN KEY (username) REFERENCES users (username)
+) STRICT;
+
+CREATE INDEX idx_invite_redemptions_invite_id
+ON invite_redemptions (invite_id);
+
+CREATE INDEX idx_invites_invited_by ON invites (invited_by);
+
+-- member_invite_policy holds a single row that controls whether members who
+-- aren't admins may create invites of their own.
+CREATE TABLE member_invite_policy (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    enabled INTEGER NOT NULL CHECK (enabled IN (0, 1)),
+    invite_limit INTEGER NOT NULL CHECK (invite_limit >= 0)
+) STRICT;
+
+INSERT INTO member_invite_policy (id, enabled, invite_limit) VALUES (1, 0, 3);
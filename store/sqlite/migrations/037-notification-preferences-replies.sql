@@ -0,0 +1,5 @@
+-- replies controls emails about direct replies to the user's comments, which
+-- used to go out regardless of the user's preferences.
+ALTER TABLE notification_preferences ADD COLUMN replies INTEGER NOT NULL CHECK (
+    replies IN (0, 1)
+) DEFAULT 1;
@@ -0,0 +1,2 @@
+CREATE INDEX idx_notifications_recipient_created
+ON notifications (recipient, created_time, id);
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"log"
 	"net/url"
+	"time"
 
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
 	"github.com/mtlynch/screenjournal/v2/store"
@@ -17,7 +18,8 @@ func (s Store) ReadMovie(id screenjournal.MovieID) (screenjournal.Movie, error)
 		imdb_id,
 		title,
 		release_date,
-		poster_path
+		poster_path,
+		runtime_minutes
 	FROM
 		movies
 	WHERE
@@ -34,7 +36,8 @@ func (s Store) ReadMovieByTmdbID(tmdbID screenjournal.TmdbID) (screenjournal.Mov
 		imdb_id,
 		title,
 		release_date,
-		poster_path
+		poster_path,
+		runtime_minutes
 	FROM
 		movies
 	WHERE
@@ -54,16 +57,18 @@ func (s Store) InsertMovie(m screenjournal.Movie) (screenjournal.MovieID, error)
 		imdb_id,
 		title,
 		release_date,
-		poster_path
+		poster_path,
+		runtime_minutes
 	)
 	VALUES (
-		:tmdb_id, :imdb_id, :title, :release_date, :poster_path
+		:tmdb_id, :imdb_id, :title, :release_date, :poster_path, :runtime_minutes
 	)`,
 		sql.Named("tmdb_id", m.TmdbID),
 		sql.Named("imdb_id", m.ImdbID),
 		sql.Named("title", m.Title),
 		sql.Named("release_date", formatReleaseDate(m.ReleaseDate)),
 		sql.Named("poster_path", m.PosterPath.String()),
+		sql.Named("runtime_minutes", formatRuntime(m.Runtime)),
 	)
 	if err != nil {
 		return screenjournal.MovieID(0), err
@@ -86,13 +91,15 @@ func (s Store) UpdateMovie(m screenjournal.Movie) error {
 		title = :title,
 		imdb_id = :imdb_id,
 		release_date = :release_date,
-		poster_path = :poster_path
+		poster_path = :poster_path,
+		runtime_minutes = :runtime_minutes
 	WHERE
 		id = :id`,
 		sql.Named("title", m.Title),
 		sql.Named("imdb_id", m.ImdbID),
 		sql.Named("release_date", formatReleaseDate(m.ReleaseDate)),
 		sql.Named("poster_path", m.PosterPath.String()),
+		sql.Named("runtime_minutes", formatRuntime(m.Runtime)),
 		sql.Named("id", m.ID.Int64())); err != nil {
 		return err
 	}
@@ -107,8 +114,9 @@ func movieFromRow(row rowScanner) (screenjournal.Movie, error) {
 	var title string
 	var releaseDateRaw *string
 	var posterPathRaw *string
+	var runtimeMinutesRaw *int64
 
-	err := row.Scan(&id, &tmdbID, &imdbIDRaw, &title, &releaseDateRaw, &posterPathRaw)
+	err := row.Scan(&id, &tmdbID, &imdbIDRaw, &title, &releaseDateRaw, &posterPathRaw, &runtimeMinutesRaw)
 	if err == sql.ErrNoRows {
 		return screenjournal.Movie{}, store.ErrMovieNotFound
 	} else if err != nil {
@@ -140,6 +148,11 @@ func movieFromRow(row rowScanner) (screenjournal.Movie, error) {
 		}
 	}
 
+	var runtime time.Duration
+	if runtimeMinutesRaw != nil {
+		runtime = time.Duration(*runtimeMinutesRaw) * time.Minute
+	}
+
 	return screenjournal.Movie{
 		ID:          screenjournal.MovieID(id),
 		TmdbID:      screenjournal.TmdbID(tmdbID),
@@ -147,5 +160,15 @@ func movieFromRow(row rowScanner) (screenjournal.Movie, error) {
 		Title:       screenjournal.MediaTitle(title),
 		ReleaseDate: releaseDate,
 		PosterPath:  posterPath,
+		Runtime:     runtime,
 	}, nil
 }
+
+// formatRuntime converts a movie runtime to whole minutes for storage, using
+// NULL to represent an unknown runtime.
+func formatRuntime(runtime time.Duration) *int64 {
+	if runtime <= 0 {
+		return nil
+	}
+	return new(int64(runtime / time.Minute))
+}
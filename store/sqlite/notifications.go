@@ -7,7 +7,9 @@ import (
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
 )
 
-func (s Store) ReadReviewSubscribers() ([]screenjournal.EmailSubscriber, error) {
+// ReadReviewSubscribers returns the users who want an immediate email about a
+// new review from the given author.
+func (s Store) ReadReviewSubscribers(author screenjournal.Username) ([]screenjournal.EmailSubscriber, error) {
 	rows, err := s.db.Query(`
 	SELECT
 		users.username AS username,
@@ -16,7 +18,33 @@ func (s Store) ReadReviewSubscribers() ([]screenjournal.EmailSubscriber, error)
 		users, notification_preferences
 	WHERE
 		users.username = notification_preferences.username AND
-		notification_preferences.new_reviews = 1`)
+		users.email_verified = 1 AND
+		users.username != :author AND
+		notification_preferences.new_reviews = 1 AND
+		notification_preferences.digest_frequency = 'immediate' AND
+		(
+			notification_preferences.new_reviews_scope = 'everyone' OR
+			EXISTS (
+				SELECT 1
+				FROM
+					user_relationships
+				WHERE
+					user_relationships.username = users.username AND
+					user_relationships.target = :author AND
+					user_relationships.relationship = 'follow'
+			)
+		) AND
+		NOT EXISTS (
+			SELECT 1
+			FROM
+				user_relationships
+			WHERE
+				user_relationships.username = users.username AND
+				user_relationships.target = :author AND
+				user_relationships.relationship = 'mute'
+		)
+	ORDER BY
+		users.username`, sql.Named("author", author.String()))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return []screenjournal.EmailSubscriber{}, nil
@@ -46,6 +74,7 @@ func (s Store) ReadReviewSubscribers() ([]screenjournal.EmailSubscriber, error)
 func (s Store) ReadCommentSubscribers(
 	reviewID screenjournal.ReviewID,
 	commentAuthor screenjournal.Username,
+	parentCommentID screenjournal.CommentID,
 ) ([]screenjournal.EmailSubscriber, error) {
 	rows, err := s.db.Query(`
 	SELECT
@@ -55,25 +84,67 @@ func (s Store) ReadCommentSubscribers(
 		users, notification_preferences
 	WHERE
 		users.username = notification_preferences.username AND
-		notification_preferences.all_new_comments = 1 AND
+		users.email_verified = 1 AND
 		users.username != :comment_author AND
-		users.username IN (
-			SELECT
-				review_owner
-			FROM
-				reviews
-			WHERE
-				id = :review_id
-			UNION
-			SELECT
-				comment_owner
+		notification_preferences.digest_frequency = 'immediate' AND
+		NOT EXISTS (
+			SELECT 1
 			FROM
-				review_comments
+				user_relationships
 			WHERE
-				review_id = :review_id
-		)`,
+				user_relationships.username = users.username AND
+				user_relationships.target = :comment_author AND
+				user_relationships.relationship = 'mute'
+		) AND
+		(
+			(
+				notification_preferences.all_new_comments = 1 AND
+				(
+					EXISTS (
+						SELECT 1
+						FROM
+							reviews
+						WHERE
+							id = :review_id AND
+							review_owner = users.username
+					) OR
+					EXISTS (
+						SELECT 1
+						FROM
+							review_comments
+						WHERE
+							review_id = :review_id AND
+							comment_owner = users.username
+					)
+				) AND
+				-- The replies preference alone decides whether authors hear about
+				-- direct replies to their comments.
+				NOT EXISTS (
+					SELECT 1
+					FROM
+						review_comments
+					WHERE
+						id = :parent_comment_id AND
+						comment_owner = users.username
+				)
+			) OR
+			(
+				notification_preferences.replies = 1 AND
+				EXISTS (
+					SELECT 1
+					FROM
+						review_comments
+					WHERE
+						id = :parent_comment_id AND
+						comment_owner = users.username
+				)
+			)
+		)
+	ORDER BY
+		users.username`,
 		sql.Named("review_id", reviewID.UInt64()),
-		sql.Named("comment_author", commentAuthor.String()))
+		sql.Named("comment_author", commentAuthor.String()),
+		sql.Named("parent_comment_id", parentCommentID.UInt64()))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return []screenjournal.EmailSubscriber{}, nil
@@ -102,36 +173,56 @@ func (s Store) ReadCommentSubscribers(
 
 func (s Store) ReadNotificationPreferences(username screenjournal.Username) (screenjournal.NotificationPreferences, error) {
 	var newReviews bool
+	var newReviewsScope string
 	var allNewComments bool
+	var replies bool
+	var mentions bool
+	var digest string
 	err := s.db.QueryRow(`
 	SELECT
 		new_reviews,
-		all_new_comments
+		new_reviews_scope,
+		all_new_comments,
+		replies,
+		mentions,
+		digest_frequency
 	FROM
 		notification_preferences
 	WHERE
-		username = :username`, sql.Named("username", username.String())).Scan(&newReviews, &allNewComments)
+		username = :username`, sql.Named("username", username.String())).Scan(&newReviews, &newReviewsScope, &allNewComments, &replies, &mentions, &digest)
 	if err != nil {
 		return screenjournal.NotificationPreferences{}, err
 	}
 
 	return screenjournal.NotificationPreferences{
-		NewReviews:     newReviews,
-		AllNewComments: allNewComments,
+		NewReviews:      newReviews,
+		NewReviewsScope: screenjournal.NotificationScope(newReviewsScope),
+		AllNewComments:  allNewComments,
+		Replies:         replies,
+		Mentions:        mentions,
+		Digest:          screenjournal.DigestFrequency(digest),
 	}, nil
 }
 
 func (s Store) UpdateNotificationPreferences(username screenjournal.Username, prefs screenjournal.NotificationPreferences) error {
-	log.Printf("updating notifications preferences for %s: newReviews=%v, allNewComments=%v", username, prefs.NewReviews, prefs.AllNewComments)
+	log.Printf("updating notifications preferences for %s: newReviews=%v, newReviewsScope=%v, allNewComments=%v, replies=%v, mentions=%v, digest=%v", username, prefs.NewReviews, prefs.NewReviewsScope, prefs.AllNewComments, prefs.Replies, prefs.Mentions, prefs.Digest)
 	if _, err := s.db.Exec(`
 	UPDATE notification_preferences
 	SET
 		new_reviews = :new_reviews,
-		all_new_comments = :all_new_comments
+		new_reviews_scope = :new_reviews_scope,
+		all_new_comments = :all_new_comments,
+		replies = :replies,
+		mentions = :mentions,
+		digest_frequency = :digest_frequency
 	WHERE
 		username = :username`,
 		sql.Named("new_reviews", prefs.NewReviews),
+		sql.Named("new_reviews_scope", prefs.NewReviewsScope.String()),
 		sql.Named("all_new_comments", prefs.AllNewComments),
+		sql.Named("replies", prefs.Replies),
+		sql.Named("mentions", prefs.Mentions),
+		sql.Named("digest_frequency", prefs.Digest.String()),
 		sql.Named("username", username)); err != nil {
 		return err
 	}
@@ -0,0 +1,87 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestReadCommentSubscribers(t *testing.T) {
+	for _, tt := range []struct {
+		description    string
+		replyToUser    bool
+		allNewComments bool
+		replies        bool
+		expected       []screenjournal.Username
+	}{
+		{
+			description: "top-level comment notifies only users who want all comments",
+			replyToUser: false,
+			replies:     true,
+			expected:    []screenjournal.Username{"owner"},
+		},
+		{
+			description: "reply notifies parent comment's author even if they opted out of all comments",
+			replyToUser: true,
+			replies:     true,
+			expected:    []screenjournal.Username{"owner", "userA"},
+		},
+		{
+			description:    "reply skips parent comment's author if they opted out of replies",
+			replyToUser:    true,
+			allNewComments: true,
+			replies:        false,
+			expected:       []screenjournal.Username{"owner", "userB"},
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			db := test_sqlite.New()
+			review := insertCommentThreadTestData(t, db, "userA", "userB", "userC")
+
+			var parentID screenjournal.CommentID
+			for _, username := range []screenjournal.Username{"userA", "userB"} {
+				if err := db.UpdateNotificationPreferences(username, screenjournal.NotificationPreferences{
+					NewReviewsScope: screenjournal.NotifyEveryone,
+					AllNewComments:  tt.allNewComments,
+					Replies:         tt.replies,
+					Digest:          screenjournal.DigestImmediate,
+				}); err != nil {
+					t.Fatalf("failed to update notification preferences: %v", err)
+				}
+				id, err := db.InsertComment(screenjournal.ReviewComment{
+					Owner:       username,
+					CommentText: screenjournal.CommentText("Hi"),
+					Review:      review,
+				})
+				if err != nil {
+					t.Fatalf("failed to insert comment: %v", err)
+				}
+				if username.Equal("userA") {
+					parentID = id
+				}
+			}
+			if !tt.replyToUser {
+				parentID = screenjournal.CommentID(0)
+			}
+
+			subscribers, err := db.ReadCommentSubscribers(review.ID, screenjournal.Username("userC"), parentID)
+			if err != nil {
+				t.Fatalf("failed to read subscribers: %v", err)
+			}
+
+			got := []screenjournal.Username{}
+			for _, s := range subscribers {
+				got = append(got, s.Username)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("subscribers=%v, want=%v", got, tt.expected)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.expected[i]) {
+					t.Errorf("subscribers=%v, want=%v", got, tt.expected)
+				}
+			}
+		})
+	}
+}
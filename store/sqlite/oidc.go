@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+func (s Store) InsertOidcIdentity(identity screenjournal.OidcIdentity) error {
+	log.Printf("linking user %s to identity %s at %s", identity.Username, identity.Subject, identity.Issuer)
+
+	_, err := s.db.Exec(`
+	INSERT INTO
+		oidc_identities
+	(
+		issuer,
+		subject,
+		username,
+		created_time
+	)
+	VALUES (
+		:issuer, :subject, :username, :created_time
+	)`,
+		sql.Named("issuer", identity.Issuer),
+		sql.Named("subject", identity.Subject),
+		sql.Named("username", identity.Username.String()),
+		sql.Named("created_time", formatTime(identity.CreatedTime)))
+	return err
+}
+
+func (s Store) ReadOidcIdentity(issuer, subject string) (screenjournal.OidcIdentity, error) {
+	var username string
+	var createdRaw string
+	err := s.db.QueryRow(`
+	SELECT
+		username,
+		created_time
+	FROM
+		oidc_identities
+	WHERE
+		issuer = :issuer AND
+		subject = :subject`,
+		sql.Named("issuer", issuer),
+		sql.Named("subject", subject)).Scan(&username, &createdRaw)
+	if err == sql.ErrNoRows {
+		return screenjournal.OidcIdentity{}, store.ErrOidcIdentityNotFound
+	} else if err != nil {
+		return screenjournal.OidcIdentity{}, err
+	}
+
+	createdTime, err := parseDatetime(createdRaw)
+	if err != nil {
+		return screenjournal.OidcIdentity{}, err
+	}
+
+	return screenjournal.OidcIdentity{
+		Issuer:      issuer,
+		Subject:     subject,
+		Username:    screenjournal.Username(username),
+		CreatedTime: createdTime,
+	}, nil
+}
+
+func (s Store) InsertOidcLoginState(state screenjournal.OidcLoginState) error {
+	_, err := s.db.Exec(`
+	INSERT INTO
+		oidc_login_states
+	(
+		state,
+		nonce,
+		code_verifier,
+		next_path,
+		expires_time
+	)
+	VALUES (
+		:state, :nonce, :code_verifier, :next_path, :expires_time
+	)`,
+		sql.Named("state", state.State),
+		sql.Named("nonce", state.Nonce),
+		sql.Named("code_verifier", state.CodeVerifier),
+		sql.Named("next_path", state.NextPath),
+		sql.Named("expires_time", formatTime(state.ExpiresAt)))
+	return err
+}
+
+// UseOidcLoginState deletes an outstanding login state and returns it, so
+// that each state works at most once. It also clears out any other states that
+// have expired.
+func (s Store) UseOidcLoginState(state string, now time.Time) (screenjournal.OidcLoginState, error) {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return screenjournal.OidcLoginState{}, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to rollback OIDC login state transaction: %v", err)
+		}
+	}()
+
+	var nonce, codeVerifier, nextPath, expiresRaw string
+	err = tx.QueryRow(`
+	DELETE FROM
+		oidc_login_states
+	WHERE
+		state = :state
+	RETURNING
+		nonce,
+		code_verifier,
+		next_path,
+		expires_time`,
+		sql.Named("state", state)).Scan(&nonce, &codeVerifier, &nextPath, &expiresRaw)
+	if err == sql.ErrNoRows {
+		return screenjournal.OidcLoginState{}, store.ErrOidcLoginStateNotFound
+	} else if err != nil {
+		return screenjournal.OidcLoginState{}, err
+	}
+
+	if _, err := tx.Exec(`
+	DELETE FROM
+		oidc_login_states
+	WHERE
+		expires_time < :now`, sql.Named("now", formatTime(now))); err != nil {
+		return screenjournal.OidcLoginState{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return screenjournal.OidcLoginState{}, err
+	}
+
+	expiresAt, err := parseDatetime(expiresRaw)
+	if err != nil {
+		return screenjournal.OidcLoginState{}, err
+	}
+	if now.After(expiresAt) {
+		return screenjournal.OidcLoginState{}, store.ErrExpiredOidcLoginState
+	}
+
+	return screenjournal.OidcLoginState{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		NextPath:     nextPath,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package sqlite_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestOidcIdentities(t *testing.T) {
+	db := test_sqlite.New()
+	insertCommentThreadTestData(t, db, "userA")
+
+	identity := screenjournal.OidcIdentity{
+		Issuer:      "https://idp.example.com",
+		Subject:     "user-1234",
+		Username:    screenjournal.Username("userA"),
+		CreatedTime: mustParseTime(t, "2025-03-01T12:00:00Z"),
+	}
+	if err := db.InsertOidcIdentity(identity); err != nil {
+		t.Fatalf("failed to insert OIDC identity: %v", err)
+	}
+
+	got, err := db.ReadOidcIdentity(identity.Issuer, identity.Subject)
+	if err != nil {
+		t.Fatalf("failed to read OIDC identity: %v", err)
+	}
+	if !reflect.DeepEqual(got, identity) {
+		t.Errorf("identity=%+v, want=%+v", got, identity)
+	}
+
+	// Subjects are only unique within an issuer.
+	if _, err := db.ReadOidcIdentity("https://other.example.com", identity.Subject); err != store.ErrOidcIdentityNotFound {
+		t.Errorf("identity from another issuer err=%v, want=%v", err, store.ErrOidcIdentityNotFound)
+	}
+}
+
+func TestUseOidcLoginState(t *testing.T) {
+	db := test_sqlite.New()
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+
+	fresh := screenjournal.OidcLoginState{
+		State:        "fresh-state",
+		Nonce:        "fresh-nonce",
+		CodeVerifier: "fresh-verifier",
+		NextPath:     "/reviews",
+		ExpiresAt:    now.Add(10 * time.Minute),
+	}
+	expired := screenjournal.OidcLoginState{
+		State:        "expired-state",
+		Nonce:        "expired-nonce",
+		CodeVerifier: "expired-verifier",
+		NextPath:     "/",
+		ExpiresAt:    now.Add(-time.Minute),
+	}
+	for _, s := range []screenjournal.OidcLoginState{fresh, expired} {
+		if err := db.InsertOidcLoginState(s); err != nil {
+			t.Fatalf("failed to insert OIDC login state: %v", err)
+		}
+	}
+
+	if _, err := db.UseOidcLoginState(expired.State, now); err != store.ErrExpiredOidcLoginState {
+		t.Errorf("expired state err=%v, want=%v", err, store.ErrExpiredOidcLoginState)
+	}
+
+	got, err := db.UseOidcLoginState(fresh.State, now)
+	if err != nil {
+		t.Fatalf("failed to use OIDC login state: %v", err)
+	}
+	if !reflect.DeepEqual(got, fresh) {
+		t.Errorf("state=%+v, want=%+v", got, fresh)
+	}
+
+	if _, err := db.UseOidcLoginState(fresh.State, now); err != store.ErrOidcLoginStateNotFound {
+		t.Errorf("reused state err=%v, want=%v", err, store.ErrOidcLoginStateNotFound)
+	}
+}
@@ -0,0 +1,247 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+// maxFailedOutboxEmails is the number of recent failed emails to show in the
+// dead-letter queue.
+const maxFailedOutboxEmails = 100
+
+func (s Store) ReadOutboxEmail(id screenjournal.OutboxEmailID) (screenjournal.OutboxEmail, error) {
+	emails, err := s.readOutboxEmails(`
+	WHERE
+		id = :id
+	`, sql.Named("id", id.UInt64()))
+	if err != nil {
+		return screenjournal.OutboxEmail{}, err
+	}
+	if len(emails) == 0 {
+		return screenjournal.OutboxEmail{}, store.ErrOutboxEmailNotFound
+	}
+
+	return emails[0], nil
+}
+
+// InsertOutboxEmail queues an email to send. If the outbox already has an
+// email with the same key, InsertOutboxEmail leaves the outbox unchanged.
+func (s Store) InsertOutboxEmail(e screenjournal.OutboxEmail) error {
+	headers := e.Headers
+	if headers == nil {
+		headers = mail.Header{}
+	}
+	headersRaw, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.db.Exec(`
+	INSERT INTO
+		email_outbox
+	(
+		dedupe_key,
+		from_address,
+		to_addresses,
+		subject,
+		text_body,
+		html_body,
+		headers,
+		status,
+		next_attempt_time,
+		created_time
+	)
+	VALUES (
+		:dedupe_key, :from_address, :to_addresses, :subject, :text_body, :html_body, :headers, :status, :next_attempt_time, :created_time
+	)
+	ON CONFLICT (dedupe_key) DO NOTHING
+	`,
+		sql.Named("dedupe_key", e.Key.String()),
+		sql.Named("from_address", e.From.String()),
+		sql.Named("to_addresses", formatAddressList(e.To)),
+		sql.Named("subject", e.Subject),
+		sql.Named("text_body", e.TextBody),
+		sql.Named("html_body", e.HtmlBody),
+		sql.Named("headers", string(headersRaw)),
+		sql.Named("status", screenjournal.OutboxEmailPending.String()),
+		sql.Named("next_attempt_time", formatTime(e.NextAttempt)),
+		sql.Named("created_time", formatTime(time.Now())))
+	if err != nil {
+		return err
+	}
+
+	if inserted, err := res.RowsAffected(); err != nil {
+		return err
+	} else if inserted == 0 {
+		log.Printf("email [%s] is already in the outbox", e.Subject)
+	}
+
+	return nil
+}
+
+// ReadDueOutboxEmails returns pending emails whose next attempt is at or
+// before the given time, oldest first.
+func (s Store) ReadDueOutboxEmails(now time.Time) ([]screenjournal.OutboxEmail, error) {
+	return s.readOutboxEmails(`
+	WHERE
+		status = 'pending' AND
+		datetime(next_attempt_time) <= datetime(:now)
+	ORDER BY
+		next_attempt_time,
+		id
+	`, sql.Named("now", formatTime(now)))
+}
+
+// ReadFailedOutboxEmails returns the most recent emails that exhausted their
+// retries, newest first.
+func (s Store) ReadFailedOutboxEmails() ([]screenjournal.OutboxEmail, error) {
+	return s.readOutboxEmails(`
+	WHERE
+		status = 'failed'
+	ORDER BY
+		id DESC
+	LIMIT :limit
+	`, sql.Named("limit", maxFailedOutboxEmails))
+}
+
+func (s Store) UpdateOutboxEmail(e screenjournal.OutboxEmail) error {
+	if _, err := s.db.Exec(`
+	UPDATE email_outbox
+	SET
+		status = :status,
+		attempts = :attempts,
+		next_attempt_time = :next_attempt_time,
+		last_error = :last_error
+	WHERE
+		id = :id
+	`,
+		sql.Named("status", e.Status.String()),
+		sql.Named("attempts", e.Attempts),
+		sql.Named("next_attempt_time", formatTime(e.NextAttempt)),
+		sql.Named("last_error", e.LastError),
+		sql.Named("id", e.ID.UInt64())); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Store) readOutboxEmails(clauses string, args ...any) ([]screenjournal.OutboxEmail, error) {
+	rows, err := s.db.Query(`
+	SELECT
+		id,
+		dedupe_key,
+		from_address,
+		to_addresses,
+		subject,
+		text_body,
+		html_body,
+		headers,
+		status,
+		attempts,
+		next_attempt_time,
+		last_error,
+		created_time
+	FROM
+		email_outbox
+	`+clauses, args...)
+	if err != nil {
+		return []screenjournal.OutboxEmail{}, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close outbox email rows: %v", err)
+		}
+	}()
+
+	emails := []screenjournal.OutboxEmail{}
+	for rows.Next() {
+		e, err := outboxEmailFromRow(rows)
+		if err != nil {
+			return []screenjournal.OutboxEmail{}, err
+		}
+		emails = append(emails, e)
+	}
+	if err := rows.Err(); err != nil {
+		return []screenjournal.OutboxEmail{}, err
+	}
+
+	return emails, nil
+}
+
+func outboxEmailFromRow(row rowScanner) (screenjournal.OutboxEmail, error) {
+	var id int
+	var key string
+	var fromRaw string
+	var toRaw string
+	var subject string
+	var textBody string
+	var htmlBody string
+	var headersRaw string
+	var status string
+	var attempts int
+	var nextAttemptRaw string
+	var lastError string
+	var createdTimeRaw string
+
+	if err := row.Scan(&id, &key, &fromRaw, &toRaw, &subject, &textBody, &htmlBody, &headersRaw, &status, &attempts, &nextAttemptRaw, &lastError, &createdTimeRaw); err != nil {
+		return screenjournal.OutboxEmail{}, err
+	}
+
+	from, err := mail.ParseAddress(fromRaw)
+	if err != nil {
+		return screenjournal.OutboxEmail{}, err
+	}
+	to, err := mail.ParseAddressList(toRaw)
+	if err != nil {
+		return screenjournal.OutboxEmail{}, err
+	}
+	var headers mail.Header
+	if err := json.Unmarshal([]byte(headersRaw), &headers); err != nil {
+		return screenjournal.OutboxEmail{}, err
+	}
+	nextAttempt, err := parseDatetime(nextAttemptRaw)
+	if err != nil {
+		return screenjournal.OutboxEmail{}, err
+	}
+	ct, err := parseDatetime(createdTimeRaw)
+	if err != nil {
+		return screenjournal.OutboxEmail{}, err
+	}
+
+	recipients := make([]mail.Address, 0, len(to))
+	for _, addr := range to {
+		recipients = append(recipients, *addr)
+	}
+
+	return screenjournal.OutboxEmail{
+		ID:          screenjournal.OutboxEmailID(id),
+		Key:         screenjournal.OutboxEmailKey(key),
+		From:        *from,
+		To:          recipients,
+		Subject:     subject,
+		TextBody:    textBody,
+		HtmlBody:    htmlBody,
+		Headers:     headers,
+		Status:      screenjournal.OutboxEmailStatus(status),
+		Attempts:    attempts,
+		NextAttempt: nextAttempt,
+		LastError:   lastError,
+		Created:     ct,
+	}, nil
+}
+
+func formatAddressList(addrs []mail.Address) string {
+	formatted := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		formatted = append(formatted, addr.String())
+	}
+	return strings.Join(formatted, ", ")
+}
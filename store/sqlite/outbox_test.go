@@ -0,0 +1,105 @@
+package sqlite_test
+
+import (
+	"errors"
+	"net/mail"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestEmailOutbox(t *testing.T) {
+	db := test_sqlite.New()
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+
+	e := screenjournal.OutboxEmail{
+		Key:  screenjournal.OutboxEmailKey("key-1"),
+		From: mail.Address{Name: "ScreenJournal", Address: "activity@thescreenjournal.com"},
+		To: []mail.Address{
+			{Name: "userA", Address: "userA@example.com"},
+		},
+		Subject:  "userB posted a new review: The Waterboy",
+		TextBody: "Hi userA,",
+		HtmlBody: "<p>Hi userA,</p>",
+		Headers: mail.Header{
+			"List-Unsubscribe": {"<https://example.com/unsubscribe/abc>"},
+		},
+		NextAttempt: now.Add(-time.Minute),
+	}
+	if err := db.InsertOutboxEmail(e); err != nil {
+		t.Fatalf("failed to insert outbox email: %v", err)
+	}
+	// Queuing the same message again should have no effect.
+	if err := db.InsertOutboxEmail(e); err != nil {
+		t.Fatalf("failed to insert duplicate outbox email: %v", err)
+	}
+	if err := db.InsertOutboxEmail(screenjournal.OutboxEmail{
+		Key:         screenjournal.OutboxEmailKey("key-2"),
+		From:        e.From,
+		To:          e.To,
+		Subject:     "later",
+		NextAttempt: now.Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("failed to insert outbox email: %v", err)
+	}
+
+	due, err := db.ReadDueOutboxEmails(now)
+	if err != nil {
+		t.Fatalf("failed to read due outbox emails: %v", err)
+	}
+	if got, want := len(due), 1; got != want {
+		t.Fatalf("due emails=%d, want=%d", got, want)
+	}
+	if got, want := due[0].To, e.To; !reflect.DeepEqual(got, want) {
+		t.Errorf("to=%v, want=%v", got, want)
+	}
+	if got, want := due[0].From, e.From; got != want {
+		t.Errorf("from=%v, want=%v", got, want)
+	}
+	if got, want := due[0].HtmlBody, e.HtmlBody; got != want {
+		t.Errorf("html body=%v, want=%v", got, want)
+	}
+	if got, want := due[0].Headers, e.Headers; !reflect.DeepEqual(got, want) {
+		t.Errorf("headers=%v, want=%v", got, want)
+	}
+	if got, want := due[0].Status, screenjournal.OutboxEmailPending; got != want {
+		t.Errorf("status=%v, want=%v", got, want)
+	}
+
+	due[0].Status = screenjournal.OutboxEmailFailed
+	due[0].Attempts = 8
+	due[0].LastError = "connection refused"
+	if err := db.UpdateOutboxEmail(due[0]); err != nil {
+		t.Fatalf("failed to update outbox email: %v", err)
+	}
+
+	failed, err := db.ReadFailedOutboxEmails()
+	if err != nil {
+		t.Fatalf("failed to read failed outbox emails: %v", err)
+	}
+	if got, want := len(failed), 1; got != want {
+		t.Fatalf("failed emails=%d, want=%d", got, want)
+	}
+	if got, want := failed[0].LastError, "connection refused"; got != want {
+		t.Errorf("last error=%v, want=%v", got, want)
+	}
+
+	due, err = db.ReadDueOutboxEmails(now.Add(2 * time.Hour))
+	if err != nil {
+		t.Fatalf("failed to read due outbox emails: %v", err)
+	}
+	if got, want := len(due), 1; got != want {
+		t.Fatalf("due emails=%d, want=%d", got, want)
+	}
+	if got, want := due[0].Subject, "later"; got != want {
+		t.Errorf("subject=%v, want=%v", got, want)
+	}
+
+	if _, err := db.ReadOutboxEmail(screenjournal.OutboxEmailID(99)); !errors.Is(err, store.ErrOutboxEmailNotFound) {
+		t.Errorf("err=%v, want=%v", err, store.ErrOutboxEmailNotFound)
+	}
+}
@@ -0,0 +1,311 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"log"
+	"time"
+
+	sqlite3 "github.com/ncruces/go-sqlite3"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+func (s Store) InsertPasskey(passkey screenjournal.Passkey) error {
+	log.Printf("saving new passkey for user %s", passkey.Username)
+
+	if _, err := s.db.Exec(`
+	INSERT INTO
+		passkeys
+	(
+		id,
+		username,
+		name,
+		public_key,
+		sign_count,
+		created_time
+	)
+	VALUES (
+		:id, :username, :name, :public_key, :sign_count, :created_time
+	)`,
+		sql.Named("id", passkey.ID.String()),
+		sql.Named("username", passkey.Username.String()),
+		sql.Named("name", passkey.Name.String()),
+		sql.Named("public_key", passkey.PublicKey),
+		sql.Named("sign_count", int64(passkey.SignCount)),
+		sql.Named("created_time", formatTime(passkey.CreatedTime))); err != nil {
+		if errors.Is(err, sqlite3.CONSTRAINT_PRIMARYKEY) {
+			return store.ErrPasskeyAlreadyRegistered
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s Store) ReadPasskey(id screenjournal.PasskeyID) (screenjournal.Passkey, error) {
+	row := s.db.QueryRow(`
+	SELECT
+		id,
+		username,
+		name,
+		public_key,
+		sign_count,
+		created_time,
+		last_used_time
+	FROM
+		passkeys
+	WHERE
+		id = :id`, sql.Named("id", id.String()))
+
+	passkey, err := passkeyFromRow(row)
+	if err == sql.ErrNoRows {
+		return screenjournal.Passkey{}, store.ErrPasskeyNotFound
+	} else if err != nil {
+		return screenjournal.Passkey{}, err
+	}
+
+	return passkey, nil
+}
+
+// ReadPasskeys returns the user's passkeys, oldest first.
+func (s Store) ReadPasskeys(username screenjournal.Username) ([]screenjournal.Passkey, error) {
+	rows, err := s.db.Query(`
+	SELECT
+		id,
+		username,
+		name,
+		public_key,
+		sign_count,
+		created_time,
+		last_used_time
+	FROM
+		passkeys
+	WHERE
+		username = :username
+	ORDER BY
+		created_time ASC,
+		id ASC`, sql.Named("username", username.String()))
+	if err != nil {
+		return []screenjournal.Passkey{}, err
+	}
+	defer rows.Close()
+
+	passkeys := []screenjournal.Passkey{}
+	for rows.Next() {
+		passkey, err := passkeyFromRow(rows)
+		if err != nil {
+			return []screenjournal.Passkey{}, err
+		}
+		passkeys = append(passkeys, passkey)
+	}
+	if err := rows.Err(); err != nil {
+		return []screenjournal.Passkey{}, err
+	}
+
+	return passkeys, nil
+}
+
+// UpdatePasskeyUsage records a successful login with the passkey.
+func (s Store) UpdatePasskeyUsage(id screenjournal.PasskeyID, signCount uint32, lastUsed time.Time) error {
+	result, err := s.db.Exec(`
+	UPDATE passkeys
+	SET
+		sign_count = :sign_count,
+		last_used_time = :last_used_time
+	WHERE
+		id = :id`,
+		sql.Named("sign_count", int64(signCount)),
+		sql.Named("last_used_time", formatTime(lastUsed)),
+		sql.Named("id", id.String()))
+	if err != nil {
+		return err
+	}
+
+	return requirePasskeyUpdated(result)
+}
+
+// RenamePasskey changes the name of one of the user's passkeys. It returns
+// ErrPasskeyNotFound if the passkey belongs to someone else.
+func (s Store) RenamePasskey(username screenjournal.Username, id screenjournal.PasskeyID, name screenjournal.PasskeyName) error {
+	result, err := s.db.Exec(`
+	UPDATE passkeys
+	SET
+		name = :name
+	WHERE
+		id = :id AND
+		username = :username`,
+		sql.Named("name", name.String()),
+		sql.Named("id", id.String()),
+		sql.Named("username", username.String()))
+	if err != nil {
+		return err
+	}
+
+	return requirePasskeyUpdated(result)
+}
+
+// DeletePasskey removes one of the user's passkeys. It returns
+// ErrPasskeyNotFound if the passkey belongs to someone else.
+func (s Store) DeletePasskey(username screenjournal.Username, id screenjournal.PasskeyID) error {
+	log.Printf("deleting passkey %s for user %s", id, username)
+
+	result, err := s.db.Exec(`
+	DELETE FROM
+		passkeys
+	WHERE
+		id = :id AND
+		username = :username`,
+		sql.Named("id", id.String()),
+		sql.Named("username", username.String()))
+	if err != nil {
+		return err
+	}
+
+	return requirePasskeyUpdated(result)
+}
+
+func (s Store) InsertPasskeyChallenge(challenge screenjournal.PasskeyChallenge) error {
+	var username *string
+	if !challenge.Username.Empty() {
+		username = new(challenge.Username.String())
+	}
+
+	_, err := s.db.Exec(`
+	INSERT INTO
+		passkey_challenges
+	(
+		challenge,
+		ceremony,
+		username,
+		expires_time
+	)
+	VALUES (
+		:challenge, :ceremony, :username, :expires_time
+	)`,
+		sql.Named("challenge", encodePasskeyChallenge(challenge.Challenge)),
+		sql.Named("ceremony", challenge.Ceremony.String()),
+		sql.Named("username", username),
+		sql.Named("expires_time", formatTime(challenge.ExpiresAt)))
+	return err
+}
+
+// UsePasskeyChallenge deletes an outstanding challenge for the given ceremony
+// and returns it, so that each challenge works at most once. It also clears
+// out any other challenges that have expired.
+func (s Store) UsePasskeyChallenge(challenge []byte, ceremony screenjournal.PasskeyCeremony, now time.Time) (screenjournal.PasskeyChallenge, error) {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return screenjournal.PasskeyChallenge{}, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to rollback passkey challenge transaction: %v", err)
+		}
+	}()
+
+	var usernameRaw *string
+	var expiresRaw string
+	err = tx.QueryRow(`
+	DELETE FROM
+		passkey_challenges
+	WHERE
+		challenge = :challenge AND
+		ceremony = :ceremony
+	RETURNING
+		username,
+		expires_time`,
+		sql.Named("challenge", encodePasskeyChallenge(challenge)),
+		sql.Named("ceremony", ceremony.String())).Scan(&usernameRaw, &expiresRaw)
+	if err == sql.ErrNoRows {
+		return screenjournal.PasskeyChallenge{}, store.ErrPasskeyChallengeNotFound
+	} else if err != nil {
+		return screenjournal.PasskeyChallenge{}, err
+	}
+
+	if _, err := tx.Exec(`
+	DELETE FROM
+		passkey_challenges
+	WHERE
+		expires_time < :now`, sql.Named("now", formatTime(now))); err != nil {
+		return screenjournal.PasskeyChallenge{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return screenjournal.PasskeyChallenge{}, err
+	}
+
+	expiresAt, err := parseDatetime(expiresRaw)
+	if err != nil {
+		return screenjournal.PasskeyChallenge{}, err
+	}
+	if now.After(expiresAt) {
+		return screenjournal.PasskeyChallenge{}, store.ErrExpiredPasskeyChallenge
+	}
+
+	var username screenjournal.Username
+	if usernameRaw != nil {
+		username = screenjournal.Username(*usernameRaw)
+	}
+
+	return screenjournal.PasskeyChallenge{
+		Challenge: challenge,
+		Ceremony:  ceremony,
+		Username:  username,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func passkeyFromRow(row rowScanner) (screenjournal.Passkey, error) {
+	var id string
+	var username string
+	var name string
+	var publicKey []byte
+	var signCount int64
+	var createdTimeRaw string
+	var lastUsedTimeRaw *string
+	if err := row.Scan(&id, &username, &name, &publicKey, &signCount, &createdTimeRaw, &lastUsedTimeRaw); err != nil {
+		return screenjournal.Passkey{}, err
+	}
+
+	createdTime, err := parseDatetime(createdTimeRaw)
+	if err != nil {
+		return screenjournal.Passkey{}, err
+	}
+
+	var lastUsedTime time.Time
+	if lastUsedTimeRaw != nil {
+		lastUsedTime, err = parseDatetime(*lastUsedTimeRaw)
+		if err != nil {
+			return screenjournal.Passkey{}, err
+		}
+	}
+
+	return screenjournal.Passkey{
+		ID:           screenjournal.PasskeyID(id),
+		Username:     screenjournal.Username(username),
+		Name:         screenjournal.PasskeyName(name),
+		PublicKey:    publicKey,
+		SignCount:    uint32(signCount),
+		CreatedTime:  createdTime,
+		LastUsedTime: lastUsedTime,
+	}, nil
+}
+
+func requirePasskeyUpdated(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected != 1 {
+		return store.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+func encodePasskeyChallenge(challenge []byte) string {
+	return base64.RawURLEncoding.EncodeToString(challenge)
+}
@@ -0,0 +1,185 @@
+package sqlite_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestPasskeys(t *testing.T) {
+	db := test_sqlite.New()
+	insertCommentThreadTestData(t, db, "userA", "userB")
+
+	laptop := screenjournal.Passkey{
+		ID:          screenjournal.PasskeyID("cred-laptop"),
+		Username:    screenjournal.Username("userA"),
+		Name:        screenjournal.PasskeyName("Laptop"),
+		PublicKey:   []byte{0x01, 0x02},
+		CreatedTime: mustParseTime(t, "2025-03-01T12:00:00Z"),
+	}
+	phone := screenjournal.Passkey{
+		ID:          screenjournal.PasskeyID("cred-phone"),
+		Username:    screenjournal.Username("userA"),
+		Name:        screenjournal.PasskeyName("Phone"),
+		PublicKey:   []byte{0x03, 0x04},
+		SignCount:   3,
+		CreatedTime: mustParseTime(t, "2025-03-02T12:00:00Z"),
+	}
+	for _, p := range []screenjournal.Passkey{phone, laptop} {
+		if err := db.InsertPasskey(p); err != nil {
+			t.Fatalf("failed to insert passkey: %v", err)
+		}
+	}
+
+	if err := db.InsertPasskey(screenjournal.Passkey{
+		ID:          laptop.ID,
+		Username:    screenjournal.Username("userB"),
+		Name:        screenjournal.PasskeyName("Copy"),
+		PublicKey:   []byte{0x05},
+		CreatedTime: mustParseTime(t, "2025-03-03T12:00:00Z"),
+	}); err != store.ErrPasskeyAlreadyRegistered {
+		t.Errorf("inserting duplicate passkey err=%v, want=%v", err, store.ErrPasskeyAlreadyRegistered)
+	}
+
+	passkeys, err := db.ReadPasskeys(screenjournal.Username("userA"))
+	if err != nil {
+		t.Fatalf("failed to read passkeys: %v", err)
+	}
+	if got, want := passkeys, []screenjournal.Passkey{laptop, phone}; !reflect.DeepEqual(got, want) {
+		t.Errorf("passkeys=%+v, want=%+v", got, want)
+	}
+
+	lastUsed := mustParseTime(t, "2025-03-04T08:00:00Z")
+	if err := db.UpdatePasskeyUsage(phone.ID, 4, lastUsed); err != nil {
+		t.Fatalf("failed to update passkey usage: %v", err)
+	}
+	got, err := db.ReadPasskey(phone.ID)
+	if err != nil {
+		t.Fatalf("failed to read passkey: %v", err)
+	}
+	if got.SignCount != 4 || !got.LastUsedTime.Equal(lastUsed) {
+		t.Errorf("passkey after use=%+v, want sign count 4 and last used %v", got, lastUsed)
+	}
+
+	// Other users can't rename or delete the passkey.
+	if err := db.RenamePasskey(screenjournal.Username("userB"), phone.ID, "Stolen"); err != store.ErrPasskeyNotFound {
+		t.Errorf("renaming another user's passkey err=%v, want=%v", err, store.ErrPasskeyNotFound)
+	}
+	if err := db.DeletePasskey(screenjournal.Username("userB"), phone.ID); err != store.ErrPasskeyNotFound {
+		t.Errorf("deleting another user's passkey err=%v, want=%v", err, store.ErrPasskeyNotFound)
+	}
+
+	if err := db.RenamePasskey(screenjournal.Username("userA"), phone.ID, "Work phone"); err != nil {
+		t.Fatalf("failed to rename passkey: %v", err)
+	}
+	got, err = db.ReadPasskey(phone.ID)
+	if err != nil {
+		t.Fatalf("failed to read passkey: %v", err)
+	}
+	if got, want := got.Name, screenjournal.PasskeyName("Work phone"); got != want {
+		t.Errorf("name=%v, want=%v", got, want)
+	}
+
+	if err := db.DeletePasskey(screenjournal.Username("userA"), phone.ID); err != nil {
+		t.Fatalf("failed to delete passkey: %v", err)
+	}
+	if _, err := db.ReadPasskey(phone.ID); err != store.ErrPasskeyNotFound {
+		t.Errorf("ReadPasskey after delete err=%v, want=%v", err, store.ErrPasskeyNotFound)
+	}
+}
+
+func TestUsePasskeyChallenge(t *testing.T) {
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+
+	for _, tt := range []struct {
+		description string
+		stored      screenjournal.PasskeyChallenge
+		challenge   []byte
+		ceremony    screenjournal.PasskeyCeremony
+		err         error
+	}{
+		{
+			description: "login challenge succeeds",
+			stored: screenjournal.PasskeyChallenge{
+				Challenge: []byte("login-challenge"),
+				Ceremony:  screenjournal.PasskeyCeremonyLogin,
+				ExpiresAt: now.Add(time.Minute),
+			},
+			challenge: []byte("login-challenge"),
+			ceremony:  screenjournal.PasskeyCeremonyLogin,
+		},
+		{
+			description: "registration challenge returns its user",
+			stored: screenjournal.PasskeyChallenge{
+				Challenge: []byte("registration-challenge"),
+				Ceremony:  screenjournal.PasskeyCeremonyRegistration,
+				Username:  screenjournal.Username("userA"),
+				ExpiresAt: now.Add(time.Minute),
+			},
+			challenge: []byte("registration-challenge"),
+			ceremony:  screenjournal.PasskeyCeremonyRegistration,
+		},
+		{
+			description: "challenge for another ceremony fails",
+			stored: screenjournal.PasskeyChallenge{
+				Challenge: []byte("registration-challenge"),
+				Ceremony:  screenjournal.PasskeyCeremonyRegistration,
+				Username:  screenjournal.Username("userA"),
+				ExpiresAt: now.Add(time.Minute),
+			},
+			challenge: []byte("registration-challenge"),
+			ceremony:  screenjournal.PasskeyCeremonyLogin,
+			err:       store.ErrPasskeyChallengeNotFound,
+		},
+		{
+			description: "unknown challenge fails",
+			stored: screenjournal.PasskeyChallenge{
+				Challenge: []byte("login-challenge"),
+				Ceremony:  screenjournal.PasskeyCeremonyLogin,
+				ExpiresAt: now.Add(time.Minute),
+			},
+			challenge: []byte("other-challenge"),
+			ceremony:  screenjournal.PasskeyCeremonyLogin,
+			err:       store.ErrPasskeyChallengeNotFound,
+		},
+		{
+			description: "expired challenge fails",
+			stored: screenjournal.PasskeyChallenge{
+				Challenge: []byte("login-challenge"),
+				Ceremony:  screenjournal.PasskeyCeremonyLogin,
+				ExpiresAt: now.Add(-time.Second),
+			},
+			challenge: []byte("login-challenge"),
+			ceremony:  screenjournal.PasskeyCeremonyLogin,
+			err:       store.ErrExpiredPasskeyChallenge,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			db := test_sqlite.New()
+			insertCommentThreadTestData(t, db, "userA")
+
+			if err := db.InsertPasskeyChallenge(tt.stored); err != nil {
+				t.Fatalf("failed to insert challenge: %v", err)
+			}
+
+			got, err := db.UsePasskeyChallenge(tt.challenge, tt.ceremony, now)
+			if err != tt.err {
+				t.Fatalf("err=%v, want=%v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.stored) {
+				t.Errorf("challenge=%+v, want=%+v", got, tt.stored)
+			}
+
+			if _, err := db.UsePasskeyChallenge(tt.challenge, tt.ceremony, now); err != store.ErrPasskeyChallengeNotFound {
+				t.Errorf("reusing challenge err=%v, want=%v", err, store.ErrPasskeyChallengeNotFound)
+			}
+		})
+	}
+}
@@ -183,5 +183,16 @@ func (s Store) UsePasswordResetEntry(
 		return store.ErrInvalidPasswordResetToken
 	}
 
+	// Whoever knew the old password could still be signed in, so a reset ends
+	// all of the user's sessions.
+	if _, err := tx.Exec(`
+		DELETE FROM
+			auth_sessions
+		WHERE
+			user_id = :username`,
+		sql.Named("username", entryUsername.String())); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
@@ -19,13 +19,15 @@ func (s Store) ReadReactions(rid screenjournal.ReviewID) ([]screenjournal.Review
 	SELECT
 		id,
 		review_id,
+		comment_id,
 		reaction_owner,
 		emoji,
 		created_time
 	FROM
 		review_reactions
 	WHERE
-		review_id = :review_id
+		review_id = :review_id AND
+		comment_id IS NULL
 	ORDER BY
 		created_time ASC
 	`, sql.Named("review_id", rid))
@@ -56,11 +58,72 @@ func (s Store) ReadReactions(rid screenjournal.ReviewID) ([]screenjournal.Review
 	return reactions, nil
 }
 
+// ReadCommentReactions returns the reactions to a comment in chronological
+// order.
+func (s Store) ReadCommentReactions(cid screenjournal.CommentID) ([]screenjournal.ReviewReaction, error) {
+	rows, err := s.db.Query(`
+	SELECT
+		id,
+		review_id,
+		comment_id,
+		reaction_owner,
+		emoji,
+		created_time
+	FROM
+		review_reactions
+	WHERE
+		comment_id = :comment_id
+	ORDER BY
+		created_time ASC
+	`, sql.Named("comment_id", cid.UInt64()))
+	if err != nil {
+		return []screenjournal.ReviewReaction{}, err
+	}
+
+	return reviewReactionsFromRows(rows)
+}
+
+// readCommentReactionsForReview returns the reactions to all comments on a
+// review, keyed by comment ID.
+func (s Store) readCommentReactionsForReview(rid screenjournal.ReviewID) (map[screenjournal.CommentID][]screenjournal.ReviewReaction, error) {
+	rows, err := s.db.Query(`
+	SELECT
+		id,
+		review_id,
+		comment_id,
+		reaction_owner,
+		emoji,
+		created_time
+	FROM
+		review_reactions
+	WHERE
+		review_id = :review_id AND
+		comment_id IS NOT NULL
+	ORDER BY
+		created_time ASC
+	`, sql.Named("review_id", rid.UInt64()))
+	if err != nil {
+		return nil, err
+	}
+
+	reactions, err := reviewReactionsFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	byComment := map[screenjournal.CommentID][]screenjournal.ReviewReaction{}
+	for _, rr := range reactions {
+		byComment[rr.Comment.ID] = append(byComment[rr.Comment.ID], rr)
+	}
+	return byComment, nil
+}
+
 func (s Store) ReadReaction(id screenjournal.ReactionID) (screenjournal.ReviewReaction, error) {
 	row := s.db.QueryRow(`
 	SELECT
 		id,
 		review_id,
+		comment_id,
 		reaction_owner,
 		emoji,
 		created_time
@@ -74,7 +137,16 @@ func (s Store) ReadReaction(id screenjournal.ReactionID) (screenjournal.ReviewRe
 }
 
 func (s Store) InsertReaction(rr screenjournal.ReviewReaction) (screenjournal.ReactionID, error) {
-	log.Printf("inserting new reaction from %v on review ID %d", rr.Owner, rr.Review.ID.UInt64())
+	if rr.Comment.ID.IsZero() {
+		log.Printf("inserting new reaction from %v on review ID %d", rr.Owner, rr.Review.ID.UInt64())
+	} else {
+		log.Printf("inserting new reaction from %v on comment ID %d", rr.Owner, rr.Comment.ID.UInt64())
+	}
+
+	var commentID *uint64
+	if !rr.Comment.ID.IsZero() {
+		commentID = new(rr.Comment.ID.UInt64())
+	}
 
 	now := time.Now()
 
@@ -83,15 +155,17 @@ func (s Store) InsertReaction(rr screenjournal.ReviewReaction) (screenjournal.Re
 		review_reactions
 	(
 		review_id,
+		comment_id,
 		reaction_owner,
 		emoji,
 		created_time
 	)
 	VALUES (
-		:review_id, :reaction_owner, :emoji, :created_time
+		:review_id, :comment_id, :reaction_owner, :emoji, :created_time
 	)
 	`,
 		sql.Named("review_id", rr.Review.ID),
+		sql.Named("comment_id", commentID),
 		sql.Named("reaction_owner", rr.Owner),
 		sql.Named("emoji", rr.Emoji.String()),
 		sql.Named("created_time", formatTime(now)))
@@ -116,14 +190,37 @@ func (s Store) DeleteReaction(id screenjournal.ReactionID) error {
 	return nil
 }
 
+func reviewReactionsFromRows(rows *sql.Rows) ([]screenjournal.ReviewReaction, error) {
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close reaction rows: %v", err)
+		}
+	}()
+
+	reactions := []screenjournal.ReviewReaction{}
+	for rows.Next() {
+		rr, err := reviewReactionFromRow(rows)
+		if err != nil {
+			return []screenjournal.ReviewReaction{}, err
+		}
+		reactions = append(reactions, rr)
+	}
+	if err := rows.Err(); err != nil {
+		return []screenjournal.ReviewReaction{}, err
+	}
+
+	return reactions, nil
+}
+
 func reviewReactionFromRow(row rowScanner) (screenjournal.ReviewReaction, error) {
 	var id int
 	var reviewId int
+	var commentID *uint64
 	var owner string
 	var emoji string
 	var createdTimeRaw string
 
-	err := row.Scan(&id, &reviewId, &owner, &emoji, &createdTimeRaw)
+	err := row.Scan(&id, &reviewId, &commentID, &owner, &emoji, &createdTimeRaw)
 	if err == sql.ErrNoRows {
 		return screenjournal.ReviewReaction{}, store.ErrReactionNotFound
 	} else if err != nil {
@@ -135,11 +232,18 @@ func reviewReactionFromRow(row rowScanner) (screenjournal.ReviewReaction, error)
 		return screenjournal.ReviewReaction{}, err
 	}
 
+	var comment screenjournal.ReviewComment
+	if commentID != nil {
+		comment.ID = screenjournal.CommentID(*commentID)
+		comment.Review.ID = screenjournal.ReviewID(reviewId)
+	}
+
 	return screenjournal.ReviewReaction{
 		ID: screenjournal.ReactionID(id),
 		Review: screenjournal.Review{
 			ID: screenjournal.ReviewID(reviewId),
 		},
+		Comment: comment,
 		Owner:   screenjournal.Username(owner),
 		Emoji:   screenjournal.NewReactionEmoji(emoji),
 		Created: ct,
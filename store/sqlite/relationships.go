@@ -0,0 +1,106 @@
+package sqlite
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+// ReadUserRelationships returns every user that the given user has followed
+// or muted.
+func (s Store) ReadUserRelationships(username screenjournal.Username) (map[screenjournal.Username]screenjournal.UserRelationship, error) {
+	rows, err := s.db.Query(`
+	SELECT
+		target,
+		relationship
+	FROM
+		user_relationships
+	WHERE
+		username = :username`, sql.Named("username", username.String()))
+	if err != nil {
+		return map[screenjournal.Username]screenjournal.UserRelationship{}, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close user relationship rows: %v", err)
+		}
+	}()
+
+	relationships := map[screenjournal.Username]screenjournal.UserRelationship{}
+	for rows.Next() {
+		var target string
+		var relationship string
+		if err := rows.Scan(&target, &relationship); err != nil {
+			return map[screenjournal.Username]screenjournal.UserRelationship{}, err
+		}
+		relationships[screenjournal.Username(target)] = screenjournal.UserRelationship(relationship)
+	}
+	if err := rows.Err(); err != nil {
+		return map[screenjournal.Username]screenjournal.UserRelationship{}, err
+	}
+
+	return relationships, nil
+}
+
+// ReadUserRelationship returns how the user treats the target user, or
+// RelationshipNone if they haven't followed or muted them.
+func (s Store) ReadUserRelationship(username, target screenjournal.Username) (screenjournal.UserRelationship, error) {
+	var relationship string
+	err := s.db.QueryRow(`
+	SELECT
+		relationship
+	FROM
+		user_relationships
+	WHERE
+		username = :username AND
+		target = :target`,
+		sql.Named("username", username.String()),
+		sql.Named("target", target.String())).Scan(&relationship)
+	if err == sql.ErrNoRows {
+		return screenjournal.RelationshipNone, nil
+	} else if err != nil {
+		return screenjournal.RelationshipNone, err
+	}
+
+	return screenjournal.UserRelationship(relationship), nil
+}
+
+// UpdateUserRelationship sets how the user treats the target user. Setting
+// RelationshipNone removes any existing follow or mute.
+func (s Store) UpdateUserRelationship(username, target screenjournal.Username, relationship screenjournal.UserRelationship) error {
+	log.Printf("setting relationship of %s to %s: %q", username, target, relationship)
+	if relationship == screenjournal.RelationshipNone {
+		_, err := s.db.Exec(`
+		DELETE FROM
+			user_relationships
+		WHERE
+			username = :username AND
+			target = :target`,
+			sql.Named("username", username.String()),
+			sql.Named("target", target.String()))
+		return err
+	}
+
+	_, err := s.db.Exec(`
+	INSERT INTO
+		user_relationships
+	(
+		username,
+		target,
+		relationship,
+		created_time
+	)
+	VALUES (
+		:username, :target, :relationship, :created_time
+	)
+	ON CONFLICT (username, target) DO UPDATE SET
+		relationship = excluded.relationship,
+		created_time = excluded.created_time`,
+		sql.Named("username", username.String()),
+		sql.Named("target", target.String()),
+		sql.Named("relationship", relationship.String()),
+		sql.Named("created_time", formatTime(time.Now())))
+	return err
+}
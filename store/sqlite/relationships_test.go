@@ -0,0 +1,146 @@
+package sqlite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestUserRelationships(t *testing.T) {
+	db := test_sqlite.New()
+	insertCommentThreadTestData(t, db, "userA", "userB")
+
+	for _, update := range []struct {
+		target       screenjournal.Username
+		relationship screenjournal.UserRelationship
+	}{
+		{"owner", screenjournal.RelationshipFollow},
+		{"userB", screenjournal.RelationshipFollow},
+		// Muting a followed user replaces the follow.
+		{"userB", screenjournal.RelationshipMute},
+	} {
+		if err := db.UpdateUserRelationship(screenjournal.Username("userA"), update.target, update.relationship); err != nil {
+			t.Fatalf("failed to update relationship: %v", err)
+		}
+	}
+
+	relationships, err := db.ReadUserRelationships(screenjournal.Username("userA"))
+	if err != nil {
+		t.Fatalf("failed to read relationships: %v", err)
+	}
+	if got, want := relationships, map[screenjournal.Username]screenjournal.UserRelationship{
+		"owner": screenjournal.RelationshipFollow,
+		"userB": screenjournal.RelationshipMute,
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("relationships=%v, want=%v", got, want)
+	}
+
+	if err := db.UpdateUserRelationship(screenjournal.Username("userA"), screenjournal.Username("owner"), screenjournal.RelationshipNone); err != nil {
+		t.Fatalf("failed to clear relationship: %v", err)
+	}
+	relationship, err := db.ReadUserRelationship(screenjournal.Username("userA"), screenjournal.Username("owner"))
+	if err != nil {
+		t.Fatalf("failed to read relationship: %v", err)
+	}
+	if got, want := relationship, screenjournal.RelationshipNone; got != want {
+		t.Errorf("relationship=%q, want=%q", got, want)
+	}
+}
+
+func TestReadReviewSubscribersRespectsRelationships(t *testing.T) {
+	for _, tt := range []struct {
+		description  string
+		scope        screenjournal.NotificationScope
+		relationship screenjournal.UserRelationship
+		expected     []screenjournal.Username
+	}{
+		{
+			description:  "user who wants reviews from everyone hears about strangers",
+			scope:        screenjournal.NotifyEveryone,
+			relationship: screenjournal.RelationshipNone,
+			expected:     []screenjournal.Username{"userA", "userB"},
+		},
+		{
+			description:  "user who wants reviews from people they follow skips strangers",
+			scope:        screenjournal.NotifyFollowing,
+			relationship: screenjournal.RelationshipNone,
+			expected:     []screenjournal.Username{"userB"},
+		},
+		{
+			description:  "user who wants reviews from people they follow hears about followed author",
+			scope:        screenjournal.NotifyFollowing,
+			relationship: screenjournal.RelationshipFollow,
+			expected:     []screenjournal.Username{"userA", "userB"},
+		},
+		{
+			description:  "user who muted the author never hears about their reviews",
+			scope:        screenjournal.NotifyEveryone,
+			relationship: screenjournal.RelationshipMute,
+			expected:     []screenjournal.Username{"userB"},
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			db := test_sqlite.New()
+			insertCommentThreadTestData(t, db, "userA", "userB")
+
+			if err := db.UpdateNotificationPreferences(screenjournal.Username("userA"), screenjournal.NotificationPreferences{
+				NewReviews:      true,
+				NewReviewsScope: tt.scope,
+				Digest:          screenjournal.DigestImmediate,
+			}); err != nil {
+				t.Fatalf("failed to update notification preferences: %v", err)
+			}
+			if err := db.UpdateUserRelationship(screenjournal.Username("userA"), screenjournal.Username("owner"), tt.relationship); err != nil {
+				t.Fatalf("failed to update relationship: %v", err)
+			}
+
+			subscribers, err := db.ReadReviewSubscribers(screenjournal.Username("owner"))
+			if err != nil {
+				t.Fatalf("failed to read review subscribers: %v", err)
+			}
+			usernames := []screenjournal.Username{}
+			for _, s := range subscribers {
+				usernames = append(usernames, s.Username)
+			}
+			if got, want := usernames, tt.expected; !reflect.DeepEqual(got, want) {
+				t.Errorf("subscribers=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestMutedUsersDontTriggerNotifications(t *testing.T) {
+	db := test_sqlite.New()
+	review := insertCommentThreadTestData(t, db, "userA")
+
+	if err := db.UpdateUserRelationship(screenjournal.Username("owner"), screenjournal.Username("userA"), screenjournal.RelationshipMute); err != nil {
+		t.Fatalf("failed to update relationship: %v", err)
+	}
+
+	subscribers, err := db.ReadCommentSubscribers(review.ID, screenjournal.Username("userA"), screenjournal.CommentID(0))
+	if err != nil {
+		t.Fatalf("failed to read comment subscribers: %v", err)
+	}
+	if got, want := len(subscribers), 0; got != want {
+		t.Errorf("comment subscribers=%d, want=%d", got, want)
+	}
+
+	if err := db.InsertNotification(screenjournal.Notification{
+		Recipient: screenjournal.Username("owner"),
+		Actor:     screenjournal.Username("userA"),
+		Kind:      screenjournal.NotificationKindReaction,
+		Review:    review,
+		Emoji:     screenjournal.NewReactionEmoji("👍"),
+	}); err != nil {
+		t.Fatalf("failed to insert notification: %v", err)
+	}
+	notifications, err := db.ReadNotifications(screenjournal.Username("owner"), screenjournal.NotificationID(0), 10)
+	if err != nil {
+		t.Fatalf("failed to read notifications: %v", err)
+	}
+	if got, want := len(notifications), 0; got != want {
+		t.Errorf("notifications=%d, want=%d", got, want)
+	}
+}
@@ -250,6 +250,14 @@ func (s Store) DeleteReview(id screenjournal.ReviewID) error {
 		}
 	}()
 
+	if _, err := tx.Exec(`DELETE FROM review_reactions WHERE review_id = :review_id`, sql.Named("review_id", id.UInt64())); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM notifications WHERE review_id = :review_id`, sql.Named("review_id", id.UInt64())); err != nil {
+		return err
+	}
+
 	if _, err := tx.Exec(`DELETE FROM reviews WHERE id = :id`, sql.Named("id", id.UInt64())); err != nil {
 		return err
 	}
@@ -9,35 +9,79 @@ import (
 	"time"
 
 	simple_sessions "codeberg.org/mtlynch/simpleauth/v3/sessions"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
 )
 
 func (s Store) CreateSession(
 	ctx context.Context,
 	session simple_sessions.Session,
 ) error {
-	if _, err := s.db.ExecContext(ctx, `
+	return s.InsertSession(ctx, session, screenjournal.SessionClient{})
+}
+
+// InsertSession saves a new session along with details about the browser
+// that started it.
+func (s Store) InsertSession(
+	ctx context.Context,
+	session simple_sessions.Session,
+	client screenjournal.SessionClient,
+) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to rollback insert session: %v", err)
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, `
 		INSERT INTO auth_sessions
 		(
 			session_id,
 			user_id,
 			created_at,
-			expires_at
+			expires_at,
+			user_agent,
+			ip_address
 		)
 		VALUES
 		(
 			:session_id,
 			:user_id,
 			:created_at,
-			:expires_at
+			:expires_at,
+			:user_agent,
+			:ip_address
 		)`,
 		sql.Named("session_id", session.ID.String()),
 		sql.Named("user_id", session.UserID.String()),
 		sql.Named("created_at", formatTime(session.CreatedAt)),
 		sql.Named("expires_at", formatTime(session.ExpiresAt)),
+		sql.Named("user_agent", client.UserAgent),
+		sql.Named("ip_address", client.IPAddress),
 	); err != nil {
 		return err
 	}
-	return nil
+
+	// Every login starts a new session, so this is the user's latest login.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET
+			last_login_time = :last_login_time
+		WHERE
+			username = :username`,
+		sql.Named("last_login_time", formatTime(session.CreatedAt)),
+		sql.Named("username", session.UserID.String()),
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (s Store) ReadSession(
@@ -125,3 +169,139 @@ func (s Store) DeleteExpiredSessions(ctx context.Context, now time.Time) error {
 	}
 	return nil
 }
+
+// TouchSession records that the session was just used. To avoid a write on
+// every request, it only updates sessions that haven't been seen since
+// seenBefore.
+func (s Store) TouchSession(
+	ctx context.Context,
+	id screenjournal.SessionID,
+	now time.Time,
+	seenBefore time.Time,
+) error {
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE auth_sessions
+		SET
+			last_seen_at = :now
+		WHERE
+			session_id = :session_id AND
+			datetime(COALESCE(last_seen_at, created_at)) < datetime(:seen_before)`,
+		sql.Named("now", formatTime(now)),
+		sql.Named("session_id", id.String()),
+		sql.Named("seen_before", formatTime(seenBefore)),
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadUserSessions returns the user's unexpired sessions, most recently used
+// first.
+func (s Store) ReadUserSessions(username screenjournal.Username, now time.Time) ([]screenjournal.UserSession, error) {
+	rows, err := s.db.Query(`
+		SELECT
+			session_id,
+			user_agent,
+			ip_address,
+			created_at,
+			COALESCE(last_seen_at, created_at) AS last_seen_at,
+			expires_at
+		FROM
+			auth_sessions
+		WHERE
+			user_id = :username AND
+			datetime(expires_at) > datetime(:now)
+		ORDER BY
+			datetime(COALESCE(last_seen_at, created_at)) DESC`,
+		sql.Named("username", username.String()),
+		sql.Named("now", formatTime(now)))
+	if err != nil {
+		return []screenjournal.UserSession{}, err
+	}
+	defer rows.Close()
+
+	sessions := []screenjournal.UserSession{}
+	for rows.Next() {
+		var id string
+		var client screenjournal.SessionClient
+		var createdAtRaw string
+		var lastSeenAtRaw string
+		var expiresAtRaw string
+		if err := rows.Scan(&id, &client.UserAgent, &client.IPAddress, &createdAtRaw, &lastSeenAtRaw, &expiresAtRaw); err != nil {
+			return []screenjournal.UserSession{}, err
+		}
+
+		createdAt, err := parseDatetime(createdAtRaw)
+		if err != nil {
+			return []screenjournal.UserSession{}, err
+		}
+		lastSeenAt, err := parseDatetime(lastSeenAtRaw)
+		if err != nil {
+			return []screenjournal.UserSession{}, err
+		}
+		expiresAt, err := parseDatetime(expiresAtRaw)
+		if err != nil {
+			return []screenjournal.UserSession{}, err
+		}
+
+		sessions = append(sessions, screenjournal.UserSession{
+			ID:         screenjournal.SessionID(id),
+			Username:   username,
+			Client:     client,
+			CreatedAt:  createdAt,
+			LastSeenAt: lastSeenAt,
+			ExpiresAt:  expiresAt,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return []screenjournal.UserSession{}, err
+	}
+
+	return sessions, nil
+}
+
+// DeleteUserSession ends one of the user's sessions. It returns
+// ErrSessionNotFound if the session belongs to someone else.
+func (s Store) DeleteUserSession(username screenjournal.Username, id screenjournal.SessionID) error {
+	log.Printf("ending session %s for user %s", id.Handle(), username)
+
+	result, err := s.db.Exec(`
+		DELETE FROM
+			auth_sessions
+		WHERE
+			session_id = :session_id AND
+			user_id = :username`,
+		sql.Named("session_id", id.String()),
+		sql.Named("username", username.String()))
+	if err != nil {
+		return err
+	}
+	rowsDeleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsDeleted != 1 {
+		return store.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// DeleteUserSessions ends all of the user's sessions except the one to keep.
+// If keep is empty, it ends every session.
+func (s Store) DeleteUserSessions(username screenjournal.Username, keep screenjournal.SessionID) error {
+	log.Printf("ending sessions for user %s", username)
+
+	if _, err := s.db.Exec(`
+		DELETE FROM
+			auth_sessions
+		WHERE
+			user_id = :username AND
+			session_id != :keep`,
+		sql.Named("username", username.String()),
+		sql.Named("keep", keep.String())); err != nil {
+		return err
+	}
+
+	return nil
+}
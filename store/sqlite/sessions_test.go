@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
 	simple_sessions "codeberg.org/mtlynch/simpleauth/v3/sessions"
 
 	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
 	"github.com/mtlynch/screenjournal/v2/store/sqlite"
 	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
 )
@@ -125,6 +127,150 @@ func TestReadSession(t *testing.T) {
 	}
 }
 
+func TestUserSessions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2100, time.May, 2, 12, 0, 0, 0, time.UTC)
+	db := test_sqlite.New()
+	insertUser(t, db, "userA")
+	insertUser(t, db, "userB")
+
+	laptop := simple_sessions.Session{
+		ID:        newSessionID(t, "userA-laptop"),
+		UserID:    newUserID(t, "userA"),
+		CreatedAt: now.Add(-48 * time.Hour),
+		ExpiresAt: now.Add(24 * time.Hour),
+	}
+	phone := simple_sessions.Session{
+		ID:        newSessionID(t, "userA-phone"),
+		UserID:    newUserID(t, "userA"),
+		CreatedAt: now.Add(-24 * time.Hour),
+		ExpiresAt: now.Add(24 * time.Hour),
+	}
+	expired := simple_sessions.Session{
+		ID:        newSessionID(t, "userA-expired"),
+		UserID:    newUserID(t, "userA"),
+		CreatedAt: now.Add(-72 * time.Hour),
+		ExpiresAt: now.Add(-time.Hour),
+	}
+	otherUser := simple_sessions.Session{
+		ID:        newSessionID(t, "userB-laptop"),
+		UserID:    newUserID(t, "userB"),
+		CreatedAt: now.Add(-24 * time.Hour),
+		ExpiresAt: now.Add(24 * time.Hour),
+	}
+	laptopClient := screenjournal.SessionClient{
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:138.0) Gecko/20100101 Firefox/138.0",
+		IPAddress: "203.0.113.7",
+	}
+	for _, session := range []simple_sessions.Session{phone, expired, otherUser} {
+		if err := db.CreateSession(ctx, session); err != nil {
+			t.Fatalf("CreateSession err=%v, want=%v", err, nil)
+		}
+	}
+	if err := db.InsertSession(ctx, laptop, laptopClient); err != nil {
+		t.Fatalf("InsertSession err=%v, want=%v", err, nil)
+	}
+
+	// The laptop was used most recently, so it comes first. Touching it again
+	// within the same interval doesn't move its last-seen time.
+	laptopID := screenjournal.SessionID(laptop.ID.String())
+	lastSeen := now.Add(-time.Hour)
+	if err := db.TouchSession(ctx, laptopID, lastSeen, lastSeen.Add(-time.Minute)); err != nil {
+		t.Fatalf("TouchSession err=%v, want=%v", err, nil)
+	}
+	if err := db.TouchSession(ctx, laptopID, lastSeen.Add(30*time.Second), lastSeen.Add(-30*time.Second)); err != nil {
+		t.Fatalf("TouchSession err=%v, want=%v", err, nil)
+	}
+
+	sessions, err := db.ReadUserSessions(screenjournal.Username("userA"), now)
+	if err != nil {
+		t.Fatalf("ReadUserSessions err=%v, want=%v", err, nil)
+	}
+	if got, want := sessions, []screenjournal.UserSession{
+		{
+			ID:         laptopID,
+			Username:   screenjournal.Username("userA"),
+			Client:     laptopClient,
+			CreatedAt:  laptop.CreatedAt,
+			LastSeenAt: lastSeen,
+			ExpiresAt:  laptop.ExpiresAt,
+		},
+		{
+			ID:         screenjournal.SessionID(phone.ID.String()),
+			Username:   screenjournal.Username("userA"),
+			CreatedAt:  phone.CreatedAt,
+			LastSeenAt: phone.CreatedAt,
+			ExpiresAt:  phone.ExpiresAt,
+		},
+	}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("sessions=%+v, want=%+v", got, want)
+	}
+
+	// Users can't end each other's sessions.
+	if got, want := db.DeleteUserSession(screenjournal.Username("userB"), laptopID), store.ErrSessionNotFound; !errors.Is(got, want) {
+		t.Errorf("deleting another user's session err=%v, want=%v", got, want)
+	}
+
+	if err := db.DeleteUserSession(screenjournal.Username("userA"), screenjournal.SessionID(phone.ID.String())); err != nil {
+		t.Fatalf("DeleteUserSession err=%v, want=%v", err, nil)
+	}
+	if _, exists := readSessionIfExists(t, db, ctx, phone); exists {
+		t.Errorf("session still exists after DeleteUserSession")
+	}
+
+	if err := db.CreateSession(ctx, phone); err != nil {
+		t.Fatalf("CreateSession err=%v, want=%v", err, nil)
+	}
+	if err := db.DeleteUserSessions(screenjournal.Username("userA"), laptopID); err != nil {
+		t.Fatalf("DeleteUserSessions err=%v, want=%v", err, nil)
+	}
+	for _, tt := range []struct {
+		session simple_sessions.Session
+		exists  bool
+	}{
+		{laptop, true},
+		{phone, false},
+		{otherUser, true},
+	} {
+		if _, exists := readSessionIfExists(t, db, ctx, tt.session); exists != tt.exists {
+			t.Errorf("session %s exists=%v, want=%v", tt.session.ID, exists, tt.exists)
+		}
+	}
+}
+
+func TestUsePasswordResetEntryEndsSessions(t *testing.T) {
+	ctx := context.Background()
+	db := test_sqlite.New()
+	insertUser(t, db, "userA")
+
+	session := simple_sessions.Session{
+		ID:        newSessionID(t, "userA"),
+		UserID:    newUserID(t, "userA"),
+		CreatedAt: time.Date(2026, time.May, 2, 12, 0, 0, 0, time.UTC),
+		ExpiresAt: time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if err := db.CreateSession(ctx, session); err != nil {
+		t.Fatalf("CreateSession err=%v, want=%v", err, nil)
+	}
+
+	token := screenjournal.NewPasswordResetToken()
+	now := time.Date(2026, time.May, 3, 12, 0, 0, 0, time.UTC)
+	if err := db.InsertPasswordResetEntry(screenjournal.PasswordResetEntry{
+		Username:  screenjournal.Username("userA"),
+		Token:     token,
+		ExpiresAt: now.Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("InsertPasswordResetEntry err=%v, want=%v", err, nil)
+	}
+	if err := db.UsePasswordResetEntry(screenjournal.Username("userA"), token, screenjournal.PasswordHash("new-hash"), now); err != nil {
+		t.Fatalf("UsePasswordResetEntry err=%v, want=%v", err, nil)
+	}
+
+	if _, exists := readSessionIfExists(t, db, ctx, session); exists {
+		t.Errorf("session still exists after password reset")
+	}
+}
+
 func newSessionID(t *testing.T, username string) simple_sessions.ID {
 	t.Helper()
 	id, err := simple_sessions.NewID(
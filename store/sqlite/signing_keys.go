@@ -0,0 +1,22 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/hex"
+)
+
+// ReadSigningKey returns the secret key with the given name.
+func (s Store) ReadSigningKey(name string) ([]byte, error) {
+	var raw string
+	if err := s.db.QueryRow(`
+	SELECT
+		signing_key
+	FROM
+		signing_keys
+	WHERE
+		name = :name`, sql.Named("name", name)).Scan(&raw); err != nil {
+		return nil, err
+	}
+
+	return hex.DecodeString(raw)
+}
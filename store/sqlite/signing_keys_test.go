@@ -0,0 +1,31 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestReadSigningKey(t *testing.T) {
+	db := test_sqlite.New()
+
+	key, err := db.ReadSigningKey("unsubscribe")
+	if err != nil {
+		t.Fatalf("failed to read signing key: %v", err)
+	}
+	if got, want := len(key), 32; got != want {
+		t.Errorf("len(key)=%d, want=%d", got, want)
+	}
+
+	again, err := db.ReadSigningKey("unsubscribe")
+	if err != nil {
+		t.Fatalf("failed to read signing key: %v", err)
+	}
+	if got, want := string(again), string(key); got != want {
+		t.Errorf("signing key changed between reads")
+	}
+
+	if _, err := db.ReadSigningKey("nonexistent"); err == nil {
+		t.Errorf("expected error reading nonexistent signing key, got nil")
+	}
+}
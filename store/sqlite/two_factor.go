@@ -0,0 +1,237 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+func (s Store) ReadTwoFactor(username screenjournal.Username) (screenjournal.TwoFactor, error) {
+	var secret string
+	var enabledTimeRaw *string
+	var lastUsedStep int64
+	err := s.db.QueryRow(`
+	SELECT
+		totp_secret,
+		enabled_time,
+		last_used_step
+	FROM
+		two_factor
+	WHERE
+		username = :username`, sql.Named("username", username.String())).
+		Scan(&secret, &enabledTimeRaw, &lastUsedStep)
+	if err == sql.ErrNoRows {
+		return screenjournal.TwoFactor{}, store.ErrTwoFactorNotFound
+	} else if err != nil {
+		return screenjournal.TwoFactor{}, err
+	}
+
+	return screenjournal.TwoFactor{
+		Username:     username,
+		Secret:       screenjournal.TotpSecret(secret),
+		Enabled:      enabledTimeRaw != nil,
+		LastUsedStep: lastUsedStep,
+	}, nil
+}
+
+// InsertPendingTwoFactor saves a secret that the user hasn't yet confirmed,
+// replacing any earlier unconfirmed secret. It doesn't affect a user who
+// already has two-factor authentication enabled.
+func (s Store) InsertPendingTwoFactor(username screenjournal.Username, secret screenjournal.TotpSecret) error {
+	log.Printf("saving pending two-factor enrollment for user %s", username)
+	_, err := s.db.Exec(`
+	INSERT INTO
+		two_factor
+	(
+		username,
+		totp_secret,
+		created_time
+	)
+	VALUES (
+		:username, :totp_secret, :created_time
+	)
+	ON CONFLICT (username) DO UPDATE SET
+		totp_secret = excluded.totp_secret,
+		created_time = excluded.created_time
+	WHERE
+		two_factor.enabled_time IS NULL`,
+		sql.Named("username", username.String()),
+		sql.Named("totp_secret", secret.String()),
+		sql.Named("created_time", formatTime(time.Now())))
+	return err
+}
+
+// EnableTwoFactor confirms the user's pending enrollment and replaces their
+// recovery codes.
+func (s Store) EnableTwoFactor(username screenjournal.Username, step int64, recoveryCodes []screenjournal.RecoveryCodeHash) error {
+	log.Printf("enabling two-factor authentication for user %s", username)
+
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to rollback two-factor enrollment transaction: %v", err)
+		}
+	}()
+
+	result, err := tx.Exec(`
+	UPDATE two_factor
+	SET
+		enabled_time = :enabled_time,
+		last_used_step = :last_used_step
+	WHERE
+		username = :username AND
+		enabled_time IS NULL`,
+		sql.Named("enabled_time", formatTime(time.Now())),
+		sql.Named("last_used_step", step),
+		sql.Named("username", username.String()))
+	if err != nil {
+		return err
+	}
+	rowsUpdated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsUpdated != 1 {
+		return store.ErrTwoFactorNotFound
+	}
+
+	if _, err := tx.Exec(`
+	DELETE FROM
+		two_factor_recovery_codes
+	WHERE
+		username = :username`, sql.Named("username", username.String())); err != nil {
+		return err
+	}
+
+	for _, hash := range recoveryCodes {
+		if _, err := tx.Exec(`
+		INSERT INTO
+			two_factor_recovery_codes
+		(
+			username,
+			code_hash
+		)
+		VALUES (
+			:username, :code_hash
+		)`,
+			sql.Named("username", username.String()),
+			sql.Named("code_hash", hash.String())); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseTotpStep records that the user logged in with a code from the given time
+// step. It fails if the user already used a code from that step or a later
+// one, so that an intercepted code can't be replayed.
+func (s Store) UseTotpStep(username screenjournal.Username, step int64) error {
+	result, err := s.db.Exec(`
+	UPDATE two_factor
+	SET
+		last_used_step = :step
+	WHERE
+		username = :username AND
+		enabled_time IS NOT NULL AND
+		last_used_step < :step`,
+		sql.Named("step", step),
+		sql.Named("username", username.String()))
+	if err != nil {
+		return err
+	}
+	rowsUpdated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsUpdated != 1 {
+		return store.ErrTotpStepAlreadyUsed
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks one of the user's recovery codes as used.
+func (s Store) UseRecoveryCode(username screenjournal.Username, hash screenjournal.RecoveryCodeHash, now time.Time) error {
+	result, err := s.db.Exec(`
+	UPDATE two_factor_recovery_codes
+	SET
+		used_time = :used_time
+	WHERE
+		username = :username AND
+		code_hash = :code_hash AND
+		used_time IS NULL`,
+		sql.Named("used_time", formatTime(now)),
+		sql.Named("username", username.String()),
+		sql.Named("code_hash", hash.String()))
+	if err != nil {
+		return err
+	}
+	rowsUpdated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsUpdated != 1 {
+		return store.ErrRecoveryCodeNotFound
+	}
+
+	log.Printf("user %s used a two-factor recovery code", username)
+	return nil
+}
+
+func (s Store) CountUnusedRecoveryCodes(username screenjournal.Username) (int, error) {
+	var count int
+	if err := s.db.QueryRow(`
+	SELECT
+		COUNT(*)
+	FROM
+		two_factor_recovery_codes
+	WHERE
+		username = :username AND
+		used_time IS NULL`, sql.Named("username", username.String())).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// DeleteTwoFactor turns off two-factor authentication for the user and
+// removes their recovery codes.
+func (s Store) DeleteTwoFactor(username screenjournal.Username) error {
+	log.Printf("disabling two-factor authentication for user %s", username)
+
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to rollback two-factor deletion transaction: %v", err)
+		}
+	}()
+
+	if _, err := tx.Exec(`
+	DELETE FROM
+		two_factor_recovery_codes
+	WHERE
+		username = :username`, sql.Named("username", username.String())); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+	DELETE FROM
+		two_factor
+	WHERE
+		username = :username`, sql.Named("username", username.String())); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
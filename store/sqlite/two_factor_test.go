@@ -0,0 +1,140 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestTwoFactorEnrollment(t *testing.T) {
+	db := test_sqlite.New()
+	insertCommentThreadTestData(t, db, "userA")
+	username := screenjournal.Username("userA")
+
+	if _, err := db.ReadTwoFactor(username); err != store.ErrTwoFactorNotFound {
+		t.Fatalf("ReadTwoFactor err=%v, want=%v", err, store.ErrTwoFactorNotFound)
+	}
+
+	// Starting enrollment twice keeps only the latest secret.
+	for _, secret := range []screenjournal.TotpSecret{"OLDSECRET", "NEWSECRET"} {
+		if err := db.InsertPendingTwoFactor(username, secret); err != nil {
+			t.Fatalf("failed to insert pending two-factor: %v", err)
+		}
+	}
+	tf, err := db.ReadTwoFactor(username)
+	if err != nil {
+		t.Fatalf("failed to read two-factor: %v", err)
+	}
+	if got, want := tf, (screenjournal.TwoFactor{Username: username, Secret: "NEWSECRET"}); got != want {
+		t.Errorf("two-factor=%+v, want=%+v", got, want)
+	}
+
+	if err := db.EnableTwoFactor(username, 100, []screenjournal.RecoveryCodeHash{"hash-1", "hash-2"}); err != nil {
+		t.Fatalf("failed to enable two-factor: %v", err)
+	}
+	if err := db.EnableTwoFactor(username, 100, nil); err != store.ErrTwoFactorNotFound {
+		t.Errorf("enabling twice err=%v, want=%v", err, store.ErrTwoFactorNotFound)
+	}
+
+	// An enabled secret can't be replaced by a new pending enrollment.
+	if err := db.InsertPendingTwoFactor(username, "REPLACEMENT"); err != nil {
+		t.Fatalf("failed to insert pending two-factor: %v", err)
+	}
+	tf, err = db.ReadTwoFactor(username)
+	if err != nil {
+		t.Fatalf("failed to read two-factor: %v", err)
+	}
+	if got, want := tf, (screenjournal.TwoFactor{Username: username, Secret: "NEWSECRET", Enabled: true, LastUsedStep: 100}); got != want {
+		t.Errorf("two-factor=%+v, want=%+v", got, want)
+	}
+
+	count, err := db.CountUnusedRecoveryCodes(username)
+	if err != nil {
+		t.Fatalf("failed to count recovery codes: %v", err)
+	}
+	if got, want := count, 2; got != want {
+		t.Errorf("unused recovery codes=%d, want=%d", got, want)
+	}
+
+	if err := db.DeleteTwoFactor(username); err != nil {
+		t.Fatalf("failed to delete two-factor: %v", err)
+	}
+	if _, err := db.ReadTwoFactor(username); err != store.ErrTwoFactorNotFound {
+		t.Errorf("ReadTwoFactor after delete err=%v, want=%v", err, store.ErrTwoFactorNotFound)
+	}
+	count, err = db.CountUnusedRecoveryCodes(username)
+	if err != nil {
+		t.Fatalf("failed to count recovery codes: %v", err)
+	}
+	if got, want := count, 0; got != want {
+		t.Errorf("unused recovery codes after delete=%d, want=%d", got, want)
+	}
+}
+
+func TestUseTotpStepRejectsReplays(t *testing.T) {
+	db := test_sqlite.New()
+	insertCommentThreadTestData(t, db, "userA")
+	username := screenjournal.Username("userA")
+
+	if err := db.InsertPendingTwoFactor(username, "SECRET"); err != nil {
+		t.Fatalf("failed to insert pending two-factor: %v", err)
+	}
+	if err := db.EnableTwoFactor(username, 100, nil); err != nil {
+		t.Fatalf("failed to enable two-factor: %v", err)
+	}
+
+	for _, tt := range []struct {
+		step int64
+		err  error
+	}{
+		{100, store.ErrTotpStepAlreadyUsed},
+		{101, nil},
+		{101, store.ErrTotpStepAlreadyUsed},
+		{99, store.ErrTotpStepAlreadyUsed},
+		{103, nil},
+	} {
+		if got, want := db.UseTotpStep(username, tt.step), tt.err; got != want {
+			t.Errorf("UseTotpStep(%d) err=%v, want=%v", tt.step, got, want)
+		}
+	}
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	db := test_sqlite.New()
+	insertCommentThreadTestData(t, db, "userA", "userB")
+	username := screenjournal.Username("userA")
+
+	if err := db.InsertPendingTwoFactor(username, "SECRET"); err != nil {
+		t.Fatalf("failed to insert pending two-factor: %v", err)
+	}
+	if err := db.EnableTwoFactor(username, 0, []screenjournal.RecoveryCodeHash{"hash-1", "hash-2"}); err != nil {
+		t.Fatalf("failed to enable two-factor: %v", err)
+	}
+
+	now := mustParseTime(t, "2024-05-01T00:00:00Z")
+	for _, tt := range []struct {
+		description string
+		username    screenjournal.Username
+		hash        screenjournal.RecoveryCodeHash
+		err         error
+	}{
+		{"valid code succeeds", username, "hash-1", nil},
+		{"used code fails", username, "hash-1", store.ErrRecoveryCodeNotFound},
+		{"unknown code fails", username, "hash-3", store.ErrRecoveryCodeNotFound},
+		{"another user's code fails", "userB", "hash-2", store.ErrRecoveryCodeNotFound},
+	} {
+		if got, want := db.UseRecoveryCode(tt.username, tt.hash, now), tt.err; got != want {
+			t.Errorf("%s: err=%v, want=%v", tt.description, got, want)
+		}
+	}
+
+	count, err := db.CountUnusedRecoveryCodes(username)
+	if err != nil {
+		t.Fatalf("failed to count recovery codes: %v", err)
+	}
+	if got, want := count, 1; got != want {
+		t.Errorf("unused recovery codes=%d, want=%d", got, want)
+	}
+}
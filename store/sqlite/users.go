@@ -22,6 +22,14 @@ func (s Store) CountUsers() (uint, error) {
 	return c, nil
 }
 
+func (s Store) CountAdmins() (uint, error) {
+	var c uint
+	if err := s.db.QueryRow(`SELECT COUNT(*) AS admin_count FROM users WHERE is_admin = 1`).Scan(&c); err != nil {
+		return 0, err
+	}
+	return c, nil
+}
+
 func (s Store) ReadUsersPublicMeta() ([]screenjournal.UserPublicMeta, error) {
 	rows, err := s.db.Query(`
 	SELECT
@@ -32,12 +40,14 @@ func (s Store) ReadUsersPublicMeta() ([]screenjournal.UserPublicMeta, error) {
 		users u
 	LEFT JOIN
 		reviews r ON u.username = r.review_owner
+	WHERE
+		u.username != :former_member
 	GROUP BY
 		u.username,
 		u.created_time
 	ORDER BY
 		u.created_time
-`)
+`, sql.Named("former_member", screenjournal.FormerMemberUsername.String()))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return []screenjournal.UserPublicMeta{}, nil
@@ -74,17 +84,25 @@ func (s Store) ReadUsersPublicMeta() ([]screenjournal.UserPublicMeta, error) {
 	return users, nil
 }
 
+// ReadUser returns the user with the given username. The placeholder account
+// that owns former members' content isn't a real person, so ReadUser doesn't
+// find it.
 func (s Store) ReadUser(username screenjournal.Username) (screenjournal.User, error) {
 	row := s.db.QueryRow(`
 	SELECT
 		username,
 		is_admin,
 		email,
+		email_verified,
+		is_disabled,
 		password_hash
 	FROM
 		users
 	WHERE
-		username = :username`, sql.Named("username", username.String()))
+		username = :username AND
+		username != :former_member`,
+		sql.Named("username", username.String()),
+		sql.Named("former_member", screenjournal.FormerMemberUsername.String()))
 	user, err := userFromRow(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -102,11 +120,16 @@ func (s Store) ReadUserByEmail(email screenjournal.Email) (screenjournal.User, e
 		username,
 		is_admin,
 		email,
+		email_verified,
+		is_disabled,
 		password_hash
 	FROM
 		users
 	WHERE
-		email = :email`, sql.Named("email", email.String()))
+		email = :email AND
+		username != :former_member`,
+		sql.Named("email", email.String()),
+		sql.Named("former_member", screenjournal.FormerMemberUsername.String()))
 	user, err := userFromRow(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -122,15 +145,128 @@ func userFromRow(row rowScanner) (screenjournal.User, error) {
 	var username string
 	var isAdmin bool
 	var email string
+	var emailVerified bool
+	var isDisabled bool
 	var passwordHashEncoded string
-	if err := row.Scan(&username, &isAdmin, &email, &passwordHashEncoded); err != nil {
+	if err := row.Scan(&username, &isAdmin, &email, &emailVerified, &isDisabled, &passwordHashEncoded); err != nil {
 		return screenjournal.User{}, err
 	}
 	return screenjournal.User{
-		IsAdmin:      isAdmin,
-		Username:     screenjournal.Username(username),
-		Email:        screenjournal.Email(email),
-		PasswordHash: decodePasswordHash(passwordHashEncoded),
+		IsAdmin:       isAdmin,
+		Username:      screenjournal.Username(username),
+		Email:         screenjournal.Email(email),
+		EmailVerified: emailVerified,
+		IsDisabled:    isDisabled,
+		PasswordHash:  decodePasswordHash(passwordHashEncoded),
+	}, nil
+}
+
+// ReadUsersAccountMeta returns the account details that admins manage for
+// every user.
+func (s Store) ReadUsersAccountMeta() ([]screenjournal.UserAccountMeta, error) {
+	rows, err := s.db.Query(`
+	SELECT
+		u.username,
+		u.email,
+		u.is_admin,
+		u.is_disabled,
+		u.created_time,
+		u.last_login_time,
+		COUNT(r.id) AS review_count
+	FROM
+		users u
+	LEFT JOIN
+		reviews r ON u.username = r.review_owner
+	WHERE
+		u.username != :former_member
+	GROUP BY
+		u.username
+	ORDER BY
+		u.created_time`, sql.Named("former_member", screenjournal.FormerMemberUsername.String()))
+	if err != nil {
+		return []screenjournal.UserAccountMeta{}, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close user account rows: %v", err)
+		}
+	}()
+
+	users := []screenjournal.UserAccountMeta{}
+	for rows.Next() {
+		u, err := userAccountMetaFromRow(rows)
+		if err != nil {
+			return []screenjournal.UserAccountMeta{}, err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return []screenjournal.UserAccountMeta{}, err
+	}
+	return users, nil
+}
+
+// ReadUserAccountMeta returns the account details that admins manage for a
+// single user.
+func (s Store) ReadUserAccountMeta(username screenjournal.Username) (screenjournal.UserAccountMeta, error) {
+	row := s.db.QueryRow(`
+	SELECT
+		u.username,
+		u.email,
+		u.is_admin,
+		u.is_disabled,
+		u.created_time,
+		u.last_login_time,
+		COUNT(r.id) AS review_count
+	FROM
+		users u
+	LEFT JOIN
+		reviews r ON u.username = r.review_owner
+	WHERE
+		u.username = :username
+	GROUP BY
+		u.username`, sql.Named("username", username.String()))
+	u, err := userAccountMetaFromRow(row)
+	if err == sql.ErrNoRows {
+		return screenjournal.UserAccountMeta{}, store.ErrUserNotFound
+	} else if err != nil {
+		return screenjournal.UserAccountMeta{}, err
+	}
+	return u, nil
+}
+
+func userAccountMetaFromRow(row rowScanner) (screenjournal.UserAccountMeta, error) {
+	var username string
+	var email string
+	var isAdmin bool
+	var isDisabled bool
+	var joinTimeRaw string
+	var lastLoginRaw *string
+	var reviewCount uint
+	if err := row.Scan(&username, &email, &isAdmin, &isDisabled, &joinTimeRaw, &lastLoginRaw, &reviewCount); err != nil {
+		return screenjournal.UserAccountMeta{}, err
+	}
+
+	joinTime, err := parseDatetime(joinTimeRaw)
+	if err != nil {
+		return screenjournal.UserAccountMeta{}, err
+	}
+
+	var lastLogin time.Time
+	if lastLoginRaw != nil {
+		if lastLogin, err = parseDatetime(*lastLoginRaw); err != nil {
+			return screenjournal.UserAccountMeta{}, err
+		}
+	}
+
+	return screenjournal.UserAccountMeta{
+		Username:    screenjournal.Username(username),
+		Email:       screenjournal.Email(email),
+		IsAdmin:     isAdmin,
+		IsDisabled:  isDisabled,
+		JoinDate:    joinTime,
+		LastLogin:   lastLogin,
+		ReviewCount: reviewCount,
 	}, nil
 }
 
@@ -150,6 +286,14 @@ func (s Store) InsertUser(user screenjournal.User) error {
 		}
 	}()
 
+	if err := insertUser(tx, user, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertUser(tx *sql.Tx, user screenjournal.User, now time.Time) error {
 	if _, err := tx.Exec(`
 	INSERT INTO
 		users
@@ -157,17 +301,19 @@ func (s Store) InsertUser(user screenjournal.User) error {
 		username,
 		is_admin,
 		email,
+		email_verified,
 		password_hash,
 		created_time,
 		last_modified_time
 	)
 	VALUES (
-		:username, :is_admin, :email, :password_hash, :created_time, :last_modified_time
+		:username, :is_admin, :email, :email_verified, :password_hash, :created_time, :last_modified_time
 	)
 	`,
 		sql.Named("username", user.Username.String()),
 		sql.Named("is_admin", user.IsAdmin),
 		sql.Named("email", user.Email.String()),
+		sql.Named("email_verified", user.EmailVerified),
 		sql.Named("password_hash", encodePasswordHash(user.PasswordHash)),
 		sql.Named("created_time", formatTime(now)),
 		sql.Named("last_modified_time", formatTime(now))); err != nil {
@@ -199,7 +345,7 @@ func (s Store) InsertUser(user screenjournal.User) error {
 		return err
 	}
 
-	return tx.Commit()
+	return nil
 }
 
 func (s Store) UpdateUserPassword(username screenjournal.Username, newPasswordHash screenjournal.PasswordHash) error {
@@ -219,6 +365,107 @@ func (s Store) UpdateUserPassword(username screenjournal.Username, newPasswordHa
 	return nil
 }
 
+// UpdateUserEmail sets the user's email address and marks it as verified.
+func (s Store) UpdateUserEmail(username screenjournal.Username, email screenjournal.Email) error {
+	log.Printf("updating email for user %s", username.String())
+
+	if _, err := s.db.Exec(`
+	UPDATE users
+	SET
+		email = :email,
+		email_verified = 1,
+		last_modified_time = :last_modified_time
+	WHERE
+		username = :username`,
+		sql.Named("email", email.String()),
+		sql.Named("last_modified_time", formatTime(time.Now())),
+		sql.Named("username", username.String())); err != nil {
+		if errors.Is(err, sqlite3.CONSTRAINT_UNIQUE) {
+			return store.ErrEmailAssociatedWithAnotherAccount
+		}
+		return err
+	}
+
+	return nil
+}
+
+// UpdateUserAdmin grants or revokes the user's admin privileges.
+func (s Store) UpdateUserAdmin(username screenjournal.Username, isAdmin bool) error {
+	log.Printf("setting isAdmin=%v for user %s", isAdmin, username.String())
+
+	result, err := s.db.Exec(`
+	UPDATE users
+	SET
+		is_admin = :is_admin,
+		last_modified_time = :last_modified_time
+	WHERE
+		username = :username`,
+		sql.Named("is_admin", isAdmin),
+		sql.Named("last_modified_time", formatTime(time.Now())),
+		sql.Named("username", username.String()))
+	if err != nil {
+		return err
+	}
+
+	rowsUpdated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsUpdated == 0 {
+		return store.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UpdateUserDisabled blocks or restores the user's ability to log in.
+// Disabling a user also ends all of their sessions.
+func (s Store) UpdateUserDisabled(username screenjournal.Username, isDisabled bool) error {
+	log.Printf("setting isDisabled=%v for user %s", isDisabled, username.String())
+
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("failed to rollback update user disabled: %v", err)
+		}
+	}()
+
+	result, err := tx.Exec(`
+	UPDATE users
+	SET
+		is_disabled = :is_disabled,
+		last_modified_time = :last_modified_time
+	WHERE
+		username = :username`,
+		sql.Named("is_disabled", isDisabled),
+		sql.Named("last_modified_time", formatTime(time.Now())),
+		sql.Named("username", username.String()))
+	if err != nil {
+		return err
+	}
+
+	rowsUpdated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsUpdated == 0 {
+		return store.ErrUserNotFound
+	}
+
+	if isDisabled {
+		if _, err := tx.Exec(`DELETE FROM auth_sessions WHERE user_id = :username`,
+			sql.Named("username", username.String())); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 func encodePasswordHash(ph screenjournal.PasswordHash) string {
 	return string(ph.Bytes())
 }
@@ -0,0 +1,116 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	simple_sessions "codeberg.org/mtlynch/simpleauth/v3/sessions"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestInsertSessionRecordsLastLogin(t *testing.T) {
+	db := test_sqlite.New()
+	insertUser(t, db, "userA")
+
+	meta, err := db.ReadUserAccountMeta(screenjournal.Username("userA"))
+	if err != nil {
+		t.Fatalf("ReadUserAccountMeta err=%v, want=%v", err, nil)
+	}
+	if !meta.LastLogin.IsZero() {
+		t.Errorf("LastLogin=%v before first login, want zero time", meta.LastLogin)
+	}
+
+	loginTime := time.Date(2100, time.May, 2, 12, 0, 0, 0, time.UTC)
+	if err := db.CreateSession(context.Background(), simple_sessions.Session{
+		ID:        newSessionID(t, "userA"),
+		UserID:    newUserID(t, "userA"),
+		CreatedAt: loginTime,
+		ExpiresAt: loginTime.Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("CreateSession err=%v, want=%v", err, nil)
+	}
+
+	meta, err = db.ReadUserAccountMeta(screenjournal.Username("userA"))
+	if err != nil {
+		t.Fatalf("ReadUserAccountMeta err=%v, want=%v", err, nil)
+	}
+	if got, want := meta.LastLogin, loginTime; !got.Equal(want) {
+		t.Errorf("LastLogin=%v, want=%v", got, want)
+	}
+}
+
+func TestUpdateUserDisabled(t *testing.T) {
+	ctx := context.Background()
+	db := test_sqlite.New()
+	insertUser(t, db, "userA")
+
+	now := time.Now()
+	session := simple_sessions.Session{
+		ID:        newSessionID(t, "userA"),
+		UserID:    newUserID(t, "userA"),
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	}
+	if err := db.CreateSession(ctx, session); err != nil {
+		t.Fatalf("CreateSession err=%v, want=%v", err, nil)
+	}
+
+	if err := db.UpdateUserDisabled(screenjournal.Username("userA"), true); err != nil {
+		t.Fatalf("UpdateUserDisabled err=%v, want=%v", err, nil)
+	}
+
+	user, err := db.ReadUser(screenjournal.Username("userA"))
+	if err != nil {
+		t.Fatalf("ReadUser err=%v, want=%v", err, nil)
+	}
+	if !user.IsDisabled {
+		t.Errorf("IsDisabled=%v, want=%v", user.IsDisabled, true)
+	}
+	if _, err := db.ReadSession(ctx, session.ID); !errors.Is(err, simple_sessions.ErrNoSessionFound) {
+		t.Errorf("ReadSession err=%v, want=%v", err, simple_sessions.ErrNoSessionFound)
+	}
+
+	if err := db.UpdateUserDisabled(screenjournal.Username("userA"), false); err != nil {
+		t.Fatalf("UpdateUserDisabled err=%v, want=%v", err, nil)
+	}
+	user, err = db.ReadUser(screenjournal.Username("userA"))
+	if err != nil {
+		t.Fatalf("ReadUser err=%v, want=%v", err, nil)
+	}
+	if user.IsDisabled {
+		t.Errorf("IsDisabled=%v, want=%v", user.IsDisabled, false)
+	}
+
+	if err := db.UpdateUserDisabled(screenjournal.Username("nobody"), true); !errors.Is(err, store.ErrUserNotFound) {
+		t.Errorf("UpdateUserDisabled for missing user err=%v, want=%v", err, store.ErrUserNotFound)
+	}
+}
+
+func TestUpdateUserAdmin(t *testing.T) {
+	db := test_sqlite.New()
+	insertUser(t, db, "userA")
+
+	if err := db.UpdateUserAdmin(screenjournal.Username("userA"), true); err != nil {
+		t.Fatalf("UpdateUserAdmin err=%v, want=%v", err, nil)
+	}
+
+	users, err := db.ReadUsersAccountMeta()
+	if err != nil {
+		t.Fatalf("ReadUsersAccountMeta err=%v, want=%v", err, nil)
+	}
+	if got, want := len(users), 1; got != want {
+		t.Fatalf("len(users)=%d, want=%d", got, want)
+	}
+	if !users[0].IsAdmin {
+		t.Errorf("IsAdmin=%v, want=%v", users[0].IsAdmin, true)
+	}
+
+	if err := db.UpdateUserAdmin(screenjournal.Username("nobody"), true); !errors.Is(err, store.ErrUserNotFound) {
+		t.Errorf("UpdateUserAdmin for missing user err=%v, want=%v", err, store.ErrUserNotFound)
+	}
+}
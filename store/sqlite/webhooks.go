@@ -0,0 +1,307 @@
+package sqlite
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+)
+
+// maxWebhookDeliveriesInLog is the number of recent deliveries to show for
+// each webhook.
+const maxWebhookDeliveriesInLog = 50
+
+func (s Store) ReadWebhook(id screenjournal.WebhookID) (screenjournal.Webhook, error) {
+	row := s.db.QueryRow(`
+	SELECT
+		id,
+		url,
+		secret,
+		created_time
+	FROM
+		webhooks
+	WHERE
+		id = :id
+	`, sql.Named("id", id.UInt64()))
+
+	return webhookFromRow(row)
+}
+
+func (s Store) ReadWebhooks() ([]screenjournal.Webhook, error) {
+	rows, err := s.db.Query(`
+	SELECT
+		id,
+		url,
+		secret,
+		created_time
+	FROM
+		webhooks
+	ORDER BY
+		id
+	`)
+	if err != nil {
+		return []screenjournal.Webhook{}, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close webhook rows: %v", err)
+		}
+	}()
+
+	webhooks := []screenjournal.Webhook{}
+	for rows.Next() {
+		wh, err := webhookFromRow(rows)
+		if err != nil {
+			return []screenjournal.Webhook{}, err
+		}
+		webhooks = append(webhooks, wh)
+	}
+	if err := rows.Err(); err != nil {
+		return []screenjournal.Webhook{}, err
+	}
+
+	return webhooks, nil
+}
+
+func (s Store) InsertWebhook(wh screenjournal.Webhook) (screenjournal.WebhookID, error) {
+	log.Printf("inserting new webhook for %v", wh.URL)
+
+	res, err := s.db.Exec(`
+	INSERT INTO
+		webhooks
+	(
+		url,
+		secret,
+		created_time
+	)
+	VALUES (
+		:url, :secret, :created_time
+	)
+	`,
+		sql.Named("url", wh.URL.String()),
+		sql.Named("secret", wh.Secret.String()),
+		sql.Named("created_time", formatTime(time.Now())))
+	if err != nil {
+		return screenjournal.WebhookID(0), err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return screenjournal.WebhookID(0), err
+	}
+
+	return screenjournal.WebhookID(lastID), nil
+}
+
+// DeleteWebhook removes the webhook along with its delivery log.
+func (s Store) DeleteWebhook(id screenjournal.WebhookID) error {
+	log.Printf("deleting webhook %v", id)
+
+	if _, err := s.db.Exec(`DELETE FROM webhooks WHERE id = :id`, sql.Named("id", id.UInt64())); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Store) InsertWebhookDelivery(d screenjournal.WebhookDelivery) (screenjournal.WebhookDeliveryID, error) {
+	res, err := s.db.Exec(`
+	INSERT INTO
+		webhook_deliveries
+	(
+		webhook_id,
+		event,
+		payload,
+		status,
+		next_attempt_time,
+		created_time
+	)
+	VALUES (
+		:webhook_id, :event, :payload, :status, :next_attempt_time, :created_time
+	)
+	`,
+		sql.Named("webhook_id", d.Webhook.ID.UInt64()),
+		sql.Named("event", d.Event.String()),
+		sql.Named("payload", d.Payload),
+		sql.Named("status", screenjournal.WebhookDeliveryPending.String()),
+		sql.Named("next_attempt_time", formatTime(d.NextAttempt)),
+		sql.Named("created_time", formatTime(time.Now())))
+	if err != nil {
+		return screenjournal.WebhookDeliveryID(0), err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return screenjournal.WebhookDeliveryID(0), err
+	}
+
+	return screenjournal.WebhookDeliveryID(lastID), nil
+}
+
+// ReadDueWebhookDeliveries returns pending deliveries whose next attempt is at
+// or before the given time, oldest first.
+func (s Store) ReadDueWebhookDeliveries(now time.Time) ([]screenjournal.WebhookDelivery, error) {
+	return s.readWebhookDeliveries(`
+	WHERE
+		webhook_deliveries.status = 'pending' AND
+		datetime(webhook_deliveries.next_attempt_time) <= datetime(:now)
+	ORDER BY
+		webhook_deliveries.next_attempt_time,
+		webhook_deliveries.id
+	`, sql.Named("now", formatTime(now)))
+}
+
+// ReadWebhookDeliveries returns the most recent deliveries for the given
+// webhook, newest first.
+func (s Store) ReadWebhookDeliveries(id screenjournal.WebhookID) ([]screenjournal.WebhookDelivery, error) {
+	return s.readWebhookDeliveries(`
+	WHERE
+		webhook_deliveries.webhook_id = :webhook_id
+	ORDER BY
+		webhook_deliveries.id DESC
+	LIMIT :limit
+	`, sql.Named("webhook_id", id.UInt64()), sql.Named("limit", maxWebhookDeliveriesInLog))
+}
+
+func (s Store) UpdateWebhookDelivery(d screenjournal.WebhookDelivery) error {
+	if _, err := s.db.Exec(`
+	UPDATE webhook_deliveries
+	SET
+		status = :status,
+		attempts = :attempts,
+		next_attempt_time = :next_attempt_time,
+		last_result = :last_result
+	WHERE
+		id = :id
+	`,
+		sql.Named("status", d.Status.String()),
+		sql.Named("attempts", d.Attempts),
+		sql.Named("next_attempt_time", formatTime(d.NextAttempt)),
+		sql.Named("last_result", d.LastResult),
+		sql.Named("id", d.ID.UInt64())); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Store) readWebhookDeliveries(clauses string, args ...any) ([]screenjournal.WebhookDelivery, error) {
+	rows, err := s.db.Query(`
+	SELECT
+		webhook_deliveries.id,
+		webhook_deliveries.event,
+		webhook_deliveries.payload,
+		webhook_deliveries.status,
+		webhook_deliveries.attempts,
+		webhook_deliveries.next_attempt_time,
+		webhook_deliveries.last_result,
+		webhook_deliveries.created_time,
+		webhooks.id,
+		webhooks.url,
+		webhooks.secret,
+		webhooks.created_time
+	FROM
+		webhook_deliveries
+	INNER JOIN webhooks ON
+		webhook_deliveries.webhook_id = webhooks.id
+	`+clauses, args...)
+	if err != nil {
+		return []screenjournal.WebhookDelivery{}, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close webhook delivery rows: %v", err)
+		}
+	}()
+
+	deliveries := []screenjournal.WebhookDelivery{}
+	for rows.Next() {
+		d, err := webhookDeliveryFromRow(rows)
+		if err != nil {
+			return []screenjournal.WebhookDelivery{}, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return []screenjournal.WebhookDelivery{}, err
+	}
+
+	return deliveries, nil
+}
+
+func webhookFromRow(row rowScanner) (screenjournal.Webhook, error) {
+	var id int
+	var url string
+	var secret string
+	var createdTimeRaw string
+
+	err := row.Scan(&id, &url, &secret, &createdTimeRaw)
+	if err == sql.ErrNoRows {
+		return screenjournal.Webhook{}, store.ErrWebhookNotFound
+	} else if err != nil {
+		return screenjournal.Webhook{}, err
+	}
+
+	ct, err := parseDatetime(createdTimeRaw)
+	if err != nil {
+		return screenjournal.Webhook{}, err
+	}
+
+	return screenjournal.Webhook{
+		ID:      screenjournal.WebhookID(id),
+		URL:     screenjournal.WebhookURL(url),
+		Secret:  screenjournal.WebhookSecret(secret),
+		Created: ct,
+	}, nil
+}
+
+func webhookDeliveryFromRow(row rowScanner) (screenjournal.WebhookDelivery, error) {
+	var id int
+	var event string
+	var payload []byte
+	var status string
+	var attempts int
+	var nextAttemptRaw string
+	var lastResult string
+	var createdTimeRaw string
+	var webhookID int
+	var webhookURL string
+	var webhookSecret string
+	var webhookCreatedRaw string
+
+	if err := row.Scan(&id, &event, &payload, &status, &attempts, &nextAttemptRaw, &lastResult, &createdTimeRaw, &webhookID, &webhookURL, &webhookSecret, &webhookCreatedRaw); err != nil {
+		return screenjournal.WebhookDelivery{}, err
+	}
+
+	nextAttempt, err := parseDatetime(nextAttemptRaw)
+	if err != nil {
+		return screenjournal.WebhookDelivery{}, err
+	}
+	ct, err := parseDatetime(createdTimeRaw)
+	if err != nil {
+		return screenjournal.WebhookDelivery{}, err
+	}
+	webhookCreated, err := parseDatetime(webhookCreatedRaw)
+	if err != nil {
+		return screenjournal.WebhookDelivery{}, err
+	}
+
+	return screenjournal.WebhookDelivery{
+		ID: screenjournal.WebhookDeliveryID(id),
+		Webhook: screenjournal.Webhook{
+			ID:      screenjournal.WebhookID(webhookID),
+			URL:     screenjournal.WebhookURL(webhookURL),
+			Secret:  screenjournal.WebhookSecret(webhookSecret),
+			Created: webhookCreated,
+		},
+		Event:       screenjournal.WebhookEvent(event),
+		Payload:     payload,
+		Status:      screenjournal.WebhookDeliveryStatus(status),
+		Attempts:    attempts,
+		NextAttempt: nextAttempt,
+		LastResult:  lastResult,
+		Created:     ct,
+	}, nil
+}
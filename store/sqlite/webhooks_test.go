@@ -0,0 +1,105 @@
+package sqlite_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/store/test_sqlite"
+)
+
+func TestWebhookDeliveryQueue(t *testing.T) {
+	db := test_sqlite.New()
+
+	webhookID, err := db.InsertWebhook(screenjournal.Webhook{
+		URL:    screenjournal.WebhookURL("https://chat.example.com/hooks/abc"),
+		Secret: screenjournal.WebhookSecret("dummy-secret"),
+	})
+	if err != nil {
+		t.Fatalf("failed to insert webhook: %v", err)
+	}
+	webhook, err := db.ReadWebhook(webhookID)
+	if err != nil {
+		t.Fatalf("failed to read webhook: %v", err)
+	}
+
+	now := mustParseTime(t, "2025-03-01T12:00:00Z")
+	dueID, err := db.InsertWebhookDelivery(screenjournal.WebhookDelivery{
+		Webhook:     webhook,
+		Event:       screenjournal.WebhookEventNewReview,
+		Payload:     []byte(`{"event":"review.created"}`),
+		NextAttempt: now.Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("failed to insert delivery: %v", err)
+	}
+	if _, err := db.InsertWebhookDelivery(screenjournal.WebhookDelivery{
+		Webhook:     webhook,
+		Event:       screenjournal.WebhookEventNewComment,
+		Payload:     []byte(`{"event":"comment.created"}`),
+		NextAttempt: now.Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("failed to insert delivery: %v", err)
+	}
+
+	due, err := db.ReadDueWebhookDeliveries(now)
+	if err != nil {
+		t.Fatalf("failed to read due deliveries: %v", err)
+	}
+	if got, want := len(due), 1; got != want {
+		t.Fatalf("due deliveries=%d, want=%d", got, want)
+	}
+	if got, want := due[0].ID, dueID; got != want {
+		t.Errorf("due delivery ID=%v, want=%v", got, want)
+	}
+	if got, want := string(due[0].Payload), `{"event":"review.created"}`; got != want {
+		t.Errorf("payload=%s, want=%s", got, want)
+	}
+	if got, want := due[0].Webhook.Secret, webhook.Secret; got != want {
+		t.Errorf("secret=%v, want=%v", got, want)
+	}
+
+	due[0].Status = screenjournal.WebhookDeliveryDelivered
+	due[0].Attempts = 1
+	due[0].LastResult = "HTTP 200"
+	if err := db.UpdateWebhookDelivery(due[0]); err != nil {
+		t.Fatalf("failed to update delivery: %v", err)
+	}
+
+	due, err = db.ReadDueWebhookDeliveries(now.Add(2 * time.Hour))
+	if err != nil {
+		t.Fatalf("failed to read due deliveries: %v", err)
+	}
+	if got, want := len(due), 1; got != want {
+		t.Fatalf("due deliveries=%d, want=%d", got, want)
+	}
+	if got, want := due[0].Event, screenjournal.WebhookEventNewComment; got != want {
+		t.Errorf("event=%v, want=%v", got, want)
+	}
+
+	deliveries, err := db.ReadWebhookDeliveries(webhookID)
+	if err != nil {
+		t.Fatalf("failed to read delivery log: %v", err)
+	}
+	if got, want := len(deliveries), 2; got != want {
+		t.Fatalf("delivery log length=%d, want=%d", got, want)
+	}
+	if got, want := deliveries[1].LastResult, "HTTP 200"; got != want {
+		t.Errorf("last result=%v, want=%v", got, want)
+	}
+
+	if err := db.DeleteWebhook(webhookID); err != nil {
+		t.Fatalf("failed to delete webhook: %v", err)
+	}
+	if _, err := db.ReadWebhook(webhookID); err != store.ErrWebhookNotFound {
+		t.Errorf("err=%v, want=%v", err, store.ErrWebhookNotFound)
+	}
+	due, err = db.ReadDueWebhookDeliveries(now.Add(2 * time.Hour))
+	if err != nil {
+		t.Fatalf("failed to read due deliveries: %v", err)
+	}
+	if got, want := len(due), 0; got != want {
+		t.Errorf("due deliveries after deleting webhook=%d, want=%d", got, want)
+	}
+}
@@ -6,19 +6,61 @@ import "log"
 
 func (s Store) Clear() {
 	log.Printf("clearing all SQLite tables")
+	if _, err := s.db.Exec(`DELETE FROM notifications`); err != nil {
+		log.Fatalf("failed to delete notifications: %v", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM user_relationships`); err != nil {
+		log.Fatalf("failed to delete user_relationships: %v", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM email_verifications`); err != nil {
+		log.Fatalf("failed to delete email_verifications: %v", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM login_links`); err != nil {
+		log.Fatalf("failed to delete login_links: %v", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM oidc_login_states`); err != nil {
+		log.Fatalf("failed to delete oidc_login_states: %v", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM oidc_identities`); err != nil {
+		log.Fatalf("failed to delete oidc_identities: %v", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM login_throttles`); err != nil {
+		log.Fatalf("failed to delete login_throttles: %v", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM passkey_challenges`); err != nil {
+		log.Fatalf("failed to delete passkey_challenges: %v", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM passkeys`); err != nil {
+		log.Fatalf("failed to delete passkeys: %v", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM two_factor_recovery_codes`); err != nil {
+		log.Fatalf("failed to delete two_factor_recovery_codes: %v", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM two_factor`); err != nil {
+		log.Fatalf("failed to delete two_factor: %v", err)
+	}
 	if _, err := s.db.Exec(`DELETE FROM movies`); err != nil {
 		log.Fatalf("failed to delete movies: %v", err)
 	}
 	if _, err := s.db.Exec(`DELETE FROM reviews`); err != nil {
 		log.Fatalf("failed to delete reviews: %v", err)
 	}
-	if _, err := s.db.Exec(`DELETE FROM users`); err != nil {
-		log.Fatalf("failed to delete users: %v", err)
+	if _, err := s.db.Exec(`DELETE FROM invite_redemptions`); err != nil {
+		log.Fatalf("failed to delete invite_redemptions: %v", err)
 	}
 	if _, err := s.db.Exec(`DELETE FROM invites`); err != nil {
 		log.Fatalf("failed to delete invites: %v", err)
 	}
+	if _, err := s.db.Exec(`UPDATE member_invite_policy SET enabled = 0, invite_limit = 3`); err != nil {
+		log.Fatalf("failed to reset member_invite_policy: %v", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM users`); err != nil {
+		log.Fatalf("failed to delete users: %v", err)
+	}
 	if _, err := s.db.Exec(`DELETE FROM notification_preferences`); err != nil {
 		log.Fatalf("failed to delete notification_preferences: %v", err)
 	}
+	if _, err := s.db.Exec(`DELETE FROM email_outbox`); err != nil {
+		log.Fatalf("failed to delete email_outbox: %v", err)
+	}
 }
@@ -27,12 +27,35 @@ var (
 	ErrTvShowNotFound                    = errors.New("could not find TV show")
 	ErrCommentNotFound                   = errors.New("could not find comment")
 	ErrReactionNotFound                  = errors.New("could not find reaction")
+	ErrFriendRecommendationNotFound      = errors.New("could not find recommendation")
+	ErrWebhookNotFound                   = errors.New("could not find webhook")
+	ErrOutboxEmailNotFound               = errors.New("could not find outbox email")
 	ErrReviewNotFound                    = errors.New("could not find review")
 	ErrUserNotFound                      = errors.New("could not find user")
 	ErrUsernameNotAvailable              = errors.New("username is not available")
 	ErrEmailAssociatedWithAnotherAccount = errors.New("email address is associated with another account")
 	ErrInvalidPasswordResetToken         = errors.New("could not find password reset token")
 	ErrExpiredPasswordResetToken         = errors.New("password reset token has expired")
+	ErrInvalidLoginLinkToken             = errors.New("could not find login link token")
+	ErrExpiredLoginLinkToken             = errors.New("login link token has expired")
+	ErrInvalidEmailVerificationToken     = errors.New("could not find email verification token")
+	ErrExpiredEmailVerificationToken     = errors.New("email verification token has expired")
+	ErrTwoFactorNotFound                 = errors.New("could not find two-factor configuration")
+	ErrTotpStepAlreadyUsed               = errors.New("TOTP code has already been used")
+	ErrRecoveryCodeNotFound              = errors.New("could not find unused recovery code")
+	ErrPasskeyNotFound                   = errors.New("could not find passkey")
+	ErrSessionNotFound                   = errors.New("could not find session")
+	ErrLoginThrottleNotFound             = errors.New("could not find login throttle")
+	ErrPasskeyAlreadyRegistered          = errors.New("passkey is already registered")
+	ErrPasskeyChallengeNotFound          = errors.New("could not find passkey challenge")
+	ErrExpiredPasskeyChallenge           = errors.New("passkey challenge has expired")
+	ErrOidcIdentityNotFound              = errors.New("could not find OIDC identity")
+	ErrOidcLoginStateNotFound            = errors.New("could not find OIDC login state")
+	ErrExpiredOidcLoginState             = errors.New("OIDC login state has expired")
+	ErrInviteNotFound                    = errors.New("could not find invite")
+	ErrInviteExpired                     = errors.New("invite has expired")
+	ErrInviteUsedUp                      = errors.New("invite has no uses left")
+	ErrInviteLimitReached                = errors.New("user has already created all of their invites")
 )
 
 func FilterReviewsByUsername(u screenjournal.Username) func(*ReadReviewsParams) {
@@ -0,0 +1,104 @@
+// Package totp implements time-based one-time passwords as described in
+// RFC 6238, using the parameters that common authenticator apps expect:
+// HMAC-SHA1, six digits, and a 30-second time step.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/random"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+const (
+	// Digits is the number of digits in each code.
+	Digits = 6
+	// Period is how long each code is valid for.
+	Period = 30 * time.Second
+
+	// secretBytes is the size of generated secrets. RFC 4226 recommends 160
+	// bits to match the HMAC-SHA1 output.
+	secretBytes = 20
+	// skew is the number of time steps before and after the current one that
+	// Validate accepts to tolerate clock drift.
+	skew = 1
+)
+
+var ErrInvalidSecret = errors.New("invalid TOTP secret")
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret returns a random secret for enrolling a new authenticator.
+func NewSecret() screenjournal.TotpSecret {
+	return screenjournal.TotpSecret(encoding.EncodeToString(random.Bytes(secretBytes)))
+}
+
+// Step returns the time step that contains t.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code returns the code for the given secret at time step.
+func Code(secret screenjournal.TotpSecret, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret.String()))
+	if err != nil || len(key) == 0 {
+		return "", ErrInvalidSecret
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, as described in RFC 4226 section 5.3.
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulus := uint32(1)
+	for range Digits {
+		modulus *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%modulus), nil
+}
+
+// Validate checks code against the secret at time now, allowing for a small
+// amount of clock drift. On success, it returns the time step the code
+// belongs to so that callers can reject codes they've already accepted.
+func Validate(secret screenjournal.TotpSecret, code string, now time.Time) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+	current := Step(now)
+	for step := current - skew; step <= current+skew; step++ {
+		want, err := Code(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// URI returns the otpauth URI that authenticator apps read from a QR code.
+func URI(issuer string, username screenjournal.Username, secret screenjournal.TotpSecret) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(username.String())
+	params := url.Values{}
+	params.Set("secret", secret.String())
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", Digits))
+	params.Set("period", fmt.Sprintf("%d", int(Period/time.Second)))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
@@ -0,0 +1,134 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/totp"
+)
+
+// rfcSecret is the SHA1 key from the RFC 6238 test vectors.
+var rfcSecret = screenjournal.TotpSecret(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890")))
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B lists eight-digit codes, so the expected values are
+	// the last six digits.
+	for _, tt := range []struct {
+		unixTime int64
+		want     string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	} {
+		t.Run(time.Unix(tt.unixTime, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			got, err := totp.Code(rfcSecret, totp.Step(time.Unix(tt.unixTime, 0)))
+			if err != nil {
+				t.Fatalf("err=%v", err)
+			}
+			if got != tt.want {
+				t.Errorf("code=%s, want=%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeRejectsInvalidSecret(t *testing.T) {
+	for _, secret := range []screenjournal.TotpSecret{"", "not base32!"} {
+		if _, err := totp.Code(secret, 1); err != totp.ErrInvalidSecret {
+			t.Errorf("Code(%q) err=%v, want=%v", secret, err, totp.ErrInvalidSecret)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	current := totp.Step(now)
+
+	codeAt := func(step int64) string {
+		code, err := totp.Code(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("failed to generate code: %v", err)
+		}
+		return code
+	}
+
+	for _, tt := range []struct {
+		description string
+		code        string
+		okExpected  bool
+		stepWant    int64
+	}{
+		{
+			description: "accepts current code",
+			code:        codeAt(current),
+			okExpected:  true,
+			stepWant:    current,
+		},
+		{
+			description: "accepts code from previous step",
+			code:        codeAt(current - 1),
+			okExpected:  true,
+			stepWant:    current - 1,
+		},
+		{
+			description: "accepts code from next step",
+			code:        codeAt(current + 1),
+			okExpected:  true,
+			stepWant:    current + 1,
+		},
+		{
+			description: "rejects code from two steps ago",
+			code:        codeAt(current - 2),
+			okExpected:  false,
+		},
+		{
+			description: "rejects wrong code",
+			code:        "000000",
+			okExpected:  false,
+		},
+		{
+			description: "rejects code with wrong length",
+			code:        codeAt(current)[1:],
+			okExpected:  false,
+		},
+		{
+			description: "rejects empty code",
+			code:        "",
+			okExpected:  false,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			step, ok := totp.Validate(rfcSecret, tt.code, now)
+			if got, want := ok, tt.okExpected; got != want {
+				t.Fatalf("ok=%v, want=%v", got, want)
+			}
+			if got, want := step, tt.stepWant; got != want {
+				t.Errorf("step=%d, want=%d", got, want)
+			}
+		})
+	}
+}
+
+func TestNewSecretProducesUsableSecrets(t *testing.T) {
+	secret := totp.NewSecret()
+	if secret == totp.NewSecret() {
+		t.Errorf("NewSecret returned the same secret twice")
+	}
+	if _, err := totp.Code(secret, 1); err != nil {
+		t.Errorf("Code(NewSecret()) err=%v", err)
+	}
+}
+
+func TestURI(t *testing.T) {
+	got := totp.URI("ScreenJournal", screenjournal.Username("alice"), screenjournal.TotpSecret("JBSWY3DPEHPK3PXP"))
+	want := "otpauth://totp/ScreenJournal:alice?algorithm=SHA1&digits=6&issuer=ScreenJournal&period=30&secret=JBSWY3DPEHPK3PXP"
+	if got != want {
+		t.Errorf("URI=%s, want=%s", got, want)
+	}
+}
@@ -0,0 +1,259 @@
+// Package twofactor manages optional TOTP-based two-factor authentication:
+// enrolling authenticator apps, verifying codes during login, and issuing the
+// short-lived challenges that connect the password step to the code step.
+package twofactor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/ratelimit"
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/totp"
+)
+
+// challengeLifetime is how long a user has to enter their code after
+// entering their password.
+const challengeLifetime = 5 * time.Minute
+
+var (
+	ErrAlreadyEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrNotEnabled          = errors.New("two-factor authentication is not enabled")
+	ErrNoPendingEnrollment = errors.New("no two-factor enrollment in progress")
+	ErrInvalidCode         = errors.New("invalid two-factor code")
+	ErrTooManyAttempts     = errors.New("too many two-factor attempts")
+	ErrInvalidChallenge    = errors.New("invalid login challenge")
+	ErrExpiredChallenge    = errors.New("login challenge has expired")
+)
+
+var encoding = base64.RawURLEncoding
+
+type (
+	Store interface {
+		ReadTwoFactor(screenjournal.Username) (screenjournal.TwoFactor, error)
+		InsertPendingTwoFactor(screenjournal.Username, screenjournal.TotpSecret) error
+		EnableTwoFactor(screenjournal.Username, int64, []screenjournal.RecoveryCodeHash) error
+		UseTotpStep(screenjournal.Username, int64) error
+		UseRecoveryCode(screenjournal.Username, screenjournal.RecoveryCodeHash, time.Time) error
+		DeleteTwoFactor(screenjournal.Username) error
+	}
+
+	Manager struct {
+		store          Store
+		challengeKey   []byte
+		attemptLimiter *ratelimit.TwoFactorAttemptLimiter
+		now            func() time.Time
+	}
+)
+
+func New(store Store, challengeKey []byte, now func() time.Time) Manager {
+	if len(challengeKey) == 0 {
+		panic("two-factor manager requires a challenge key")
+	}
+	if now == nil {
+		panic("two-factor manager requires a clock")
+	}
+	return Manager{
+		store:          store,
+		challengeKey:   challengeKey,
+		attemptLimiter: ratelimit.NewTwoFactorAttemptLimiter(now),
+		now:            now,
+	}
+}
+
+// BeginEnrollment generates a new secret for the user. Two-factor
+// authentication doesn't take effect until the user confirms the secret with
+// ConfirmEnrollment.
+func (m Manager) BeginEnrollment(username screenjournal.Username) (screenjournal.TotpSecret, error) {
+	tf, err := m.store.ReadTwoFactor(username)
+	if err == nil && tf.Enabled {
+		return screenjournal.TotpSecret(""), ErrAlreadyEnabled
+	} else if err != nil && !errors.Is(err, store.ErrTwoFactorNotFound) {
+		return screenjournal.TotpSecret(""), fmt.Errorf("read two-factor configuration: %w", err)
+	}
+
+	secret := totp.NewSecret()
+	if err := m.store.InsertPendingTwoFactor(username, secret); err != nil {
+		return screenjournal.TotpSecret(""), fmt.Errorf("save pending two-factor secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// ConfirmEnrollment enables two-factor authentication if the code matches the
+// pending secret. It returns the user's recovery codes, which ScreenJournal
+// stores only as hashes, so this is the only time they're available.
+func (m Manager) ConfirmEnrollment(username screenjournal.Username, code string) ([]screenjournal.RecoveryCode, error) {
+	unlock := m.attemptLimiter.Lock(username)
+	defer unlock()
+
+	if !m.attemptLimiter.HasAttemptsRemaining(username) {
+		log.Printf("two-factor enrollment rate limited for user %s", username)
+		return []screenjournal.RecoveryCode{}, ErrTooManyAttempts
+	}
+
+	tf, err := m.store.ReadTwoFactor(username)
+	if errors.Is(err, store.ErrTwoFactorNotFound) {
+		return []screenjournal.RecoveryCode{}, ErrNoPendingEnrollment
+	} else if err != nil {
+		return []screenjournal.RecoveryCode{}, fmt.Errorf("read two-factor configuration: %w", err)
+	}
+	if tf.Enabled {
+		return []screenjournal.RecoveryCode{}, ErrAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(tf.Secret, code, m.now())
+	if !ok {
+		m.attemptLimiter.RecordAttempt(username)
+		return []screenjournal.RecoveryCode{}, ErrInvalidCode
+	}
+
+	codes := make([]screenjournal.RecoveryCode, screenjournal.RecoveryCodeCount)
+	hashes := make([]screenjournal.RecoveryCodeHash, screenjournal.RecoveryCodeCount)
+	for i := range codes {
+		codes[i] = screenjournal.NewRecoveryCode()
+		hashes[i] = hashRecoveryCode(username, codes[i])
+	}
+
+	if err := m.store.EnableTwoFactor(username, step, hashes); err != nil {
+		if errors.Is(err, store.ErrTwoFactorNotFound) {
+			return []screenjournal.RecoveryCode{}, ErrNoPendingEnrollment
+		}
+		return []screenjournal.RecoveryCode{}, fmt.Errorf("enable two-factor authentication: %w", err)
+	}
+
+	return codes, nil
+}
+
+// Verify checks a TOTP code or recovery code for a user who has two-factor
+// authentication enabled. Each code works only once.
+func (m Manager) Verify(username screenjournal.Username, code string) error {
+	unlock := m.attemptLimiter.Lock(username)
+	defer unlock()
+
+	if !m.attemptLimiter.HasAttemptsRemaining(username) {
+		log.Printf("two-factor verification rate limited for user %s", username)
+		return ErrTooManyAttempts
+	}
+
+	tf, err := m.store.ReadTwoFactor(username)
+	if errors.Is(err, store.ErrTwoFactorNotFound) {
+		return ErrNotEnabled
+	} else if err != nil {
+		return fmt.Errorf("read two-factor configuration: %w", err)
+	}
+	if !tf.Enabled {
+		return ErrNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if isTotpCode(code) {
+		err = m.verifyTotp(tf, code)
+	} else {
+		err = m.store.UseRecoveryCode(username, hashRecoveryCode(username, screenjournal.RecoveryCode(code)), m.now())
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidCode) || errors.Is(err, store.ErrTotpStepAlreadyUsed) || errors.Is(err, store.ErrRecoveryCodeNotFound) {
+			m.attemptLimiter.RecordAttempt(username)
+			return ErrInvalidCode
+		}
+		return fmt.Errorf("verify two-factor code: %w", err)
+	}
+
+	return nil
+}
+
+// Disable turns off two-factor authentication after checking that the user
+// can still produce a valid code.
+func (m Manager) Disable(username screenjournal.Username, code string) error {
+	if err := m.Verify(username, code); err != nil {
+		return err
+	}
+	if err := m.store.DeleteTwoFactor(username); err != nil {
+		return fmt.Errorf("delete two-factor configuration: %w", err)
+	}
+	return nil
+}
+
+// IssueChallenge returns a token that lets the user complete a login by
+// entering their code within the next few minutes.
+func (m Manager) IssueChallenge(username screenjournal.Username) screenjournal.LoginChallenge {
+	payload := username.String() + ":" + strconv.FormatInt(m.now().Add(challengeLifetime).Unix(), 10)
+	return screenjournal.LoginChallenge(encoding.EncodeToString([]byte(payload)) + "." + encoding.EncodeToString(m.sign(payload)))
+}
+
+// VerifyChallenge checks the challenge's signature and expiry and returns the
+// user who passed the password step.
+func (m Manager) VerifyChallenge(challenge screenjournal.LoginChallenge) (screenjournal.Username, error) {
+	encodedPayload, encodedSig, ok := strings.Cut(challenge.String(), ".")
+	if !ok {
+		return screenjournal.Username(""), ErrInvalidChallenge
+	}
+	payload, err := encoding.DecodeString(encodedPayload)
+	if err != nil {
+		return screenjournal.Username(""), ErrInvalidChallenge
+	}
+	sig, err := encoding.DecodeString(encodedSig)
+	if err != nil {
+		return screenjournal.Username(""), ErrInvalidChallenge
+	}
+	if !hmac.Equal(sig, m.sign(string(payload))) {
+		return screenjournal.Username(""), ErrInvalidChallenge
+	}
+
+	username, expiryRaw, ok := strings.Cut(string(payload), ":")
+	if !ok {
+		return screenjournal.Username(""), ErrInvalidChallenge
+	}
+	expiry, err := strconv.ParseInt(expiryRaw, 10, 64)
+	if err != nil {
+		return screenjournal.Username(""), ErrInvalidChallenge
+	}
+	if m.now().After(time.Unix(expiry, 0)) {
+		return screenjournal.Username(""), ErrExpiredChallenge
+	}
+
+	return screenjournal.Username(username), nil
+}
+
+func (m Manager) verifyTotp(tf screenjournal.TwoFactor, code string) error {
+	step, ok := totp.Validate(tf.Secret, code, m.now())
+	if !ok {
+		return ErrInvalidCode
+	}
+	return m.store.UseTotpStep(tf.Username, step)
+}
+
+func (m Manager) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, m.challengeKey)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func isTotpCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// hashRecoveryCode includes the username so that identical codes for
+// different users don't produce the same hash.
+func hashRecoveryCode(username screenjournal.Username, code screenjournal.RecoveryCode) screenjournal.RecoveryCodeHash {
+	sum := sha256.Sum256([]byte(username.String() + ":" + code.Normalize().String()))
+	return screenjournal.RecoveryCodeHash(hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,364 @@
+package twofactor_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/store"
+	"github.com/mtlynch/screenjournal/v2/totp"
+	"github.com/mtlynch/screenjournal/v2/twofactor"
+)
+
+type mockStore struct {
+	configs       map[screenjournal.Username]screenjournal.TwoFactor
+	recoveryCodes map[screenjournal.Username]map[screenjournal.RecoveryCodeHash]bool
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{
+		configs:       map[screenjournal.Username]screenjournal.TwoFactor{},
+		recoveryCodes: map[screenjournal.Username]map[screenjournal.RecoveryCodeHash]bool{},
+	}
+}
+
+func (s *mockStore) ReadTwoFactor(username screenjournal.Username) (screenjournal.TwoFactor, error) {
+	tf, ok := s.configs[username]
+	if !ok {
+		return screenjournal.TwoFactor{}, store.ErrTwoFactorNotFound
+	}
+	return tf, nil
+}
+
+func (s *mockStore) InsertPendingTwoFactor(username screenjournal.Username, secret screenjournal.TotpSecret) error {
+	if s.configs[username].Enabled {
+		return nil
+	}
+	s.configs[username] = screenjournal.TwoFactor{Username: username, Secret: secret}
+	return nil
+}
+
+func (s *mockStore) EnableTwoFactor(username screenjournal.Username, step int64, hashes []screenjournal.RecoveryCodeHash) error {
+	tf, ok := s.configs[username]
+	if !ok || tf.Enabled {
+		return store.ErrTwoFactorNotFound
+	}
+	tf.Enabled = true
+	tf.LastUsedStep = step
+	s.configs[username] = tf
+	s.recoveryCodes[username] = map[screenjournal.RecoveryCodeHash]bool{}
+	for _, h := range hashes {
+		s.recoveryCodes[username][h] = false
+	}
+	return nil
+}
+
+func (s *mockStore) UseTotpStep(username screenjournal.Username, step int64) error {
+	tf := s.configs[username]
+	if step <= tf.LastUsedStep {
+		return store.ErrTotpStepAlreadyUsed
+	}
+	tf.LastUsedStep = step
+	s.configs[username] = tf
+	return nil
+}
+
+func (s *mockStore) UseRecoveryCode(username screenjournal.Username, hash screenjournal.RecoveryCodeHash, _ time.Time) error {
+	used, ok := s.recoveryCodes[username][hash]
+	if !ok || used {
+		return store.ErrRecoveryCodeNotFound
+	}
+	s.recoveryCodes[username][hash] = true
+	return nil
+}
+
+func (s *mockStore) DeleteTwoFactor(username screenjournal.Username) error {
+	delete(s.configs, username)
+	delete(s.recoveryCodes, username)
+	return nil
+}
+
+// slowStore takes a moment to read the two-factor configuration so that
+// parallel attempts overlap.
+type slowStore struct {
+	*mockStore
+}
+
+func (s slowStore) ReadTwoFactor(username screenjournal.Username) (screenjournal.TwoFactor, error) {
+	time.Sleep(10 * time.Millisecond)
+	return s.mockStore.ReadTwoFactor(username)
+}
+
+var baseTime = time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+func mustCode(t *testing.T, secret screenjournal.TotpSecret, at time.Time) string {
+	t.Helper()
+	code, err := totp.Code(secret, totp.Step(at))
+	if err != nil {
+		t.Fatalf("failed to generate TOTP code: %v", err)
+	}
+	return code
+}
+
+// enroll enables two-factor authentication for the user and returns their
+// secret and recovery codes.
+func enroll(t *testing.T, m twofactor.Manager, username screenjournal.Username, now time.Time) (screenjournal.TotpSecret, []screenjournal.RecoveryCode) {
+	t.Helper()
+	secret, err := m.BeginEnrollment(username)
+	if err != nil {
+		t.Fatalf("failed to begin enrollment: %v", err)
+	}
+	codes, err := m.ConfirmEnrollment(username, mustCode(t, secret, now))
+	if err != nil {
+		t.Fatalf("failed to confirm enrollment: %v", err)
+	}
+	return secret, codes
+}
+
+func TestEnrollment(t *testing.T) {
+	now := baseTime
+	db := newMockStore()
+	m := twofactor.New(db, []byte("dummy-key"), func() time.Time { return now })
+	username := screenjournal.Username("alice")
+
+	if _, err := m.ConfirmEnrollment(username, "123456"); err != twofactor.ErrNoPendingEnrollment {
+		t.Fatalf("confirming without enrollment err=%v, want=%v", err, twofactor.ErrNoPendingEnrollment)
+	}
+
+	secret, err := m.BeginEnrollment(username)
+	if err != nil {
+		t.Fatalf("failed to begin enrollment: %v", err)
+	}
+	if err := m.Verify(username, mustCode(t, secret, now)); err != twofactor.ErrNotEnabled {
+		t.Errorf("verify before confirming err=%v, want=%v", err, twofactor.ErrNotEnabled)
+	}
+	if _, err := m.ConfirmEnrollment(username, "not-a-code"); err != twofactor.ErrInvalidCode {
+		t.Errorf("confirming with bad code err=%v, want=%v", err, twofactor.ErrInvalidCode)
+	}
+
+	codes, err := m.ConfirmEnrollment(username, mustCode(t, secret, now))
+	if err != nil {
+		t.Fatalf("failed to confirm enrollment: %v", err)
+	}
+	if got, want := len(codes), screenjournal.RecoveryCodeCount; got != want {
+		t.Errorf("recovery codes=%d, want=%d", got, want)
+	}
+	for hash := range db.recoveryCodes[username] {
+		for _, code := range codes {
+			if hash.String() == code.String() {
+				t.Errorf("store holds recovery code %s in plaintext", code)
+			}
+		}
+	}
+
+	if _, err := m.BeginEnrollment(username); err != twofactor.ErrAlreadyEnabled {
+		t.Errorf("enrolling twice err=%v, want=%v", err, twofactor.ErrAlreadyEnabled)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := baseTime
+	db := newMockStore()
+	m := twofactor.New(db, []byte("dummy-key"), func() time.Time { return now })
+	username := screenjournal.Username("alice")
+	secret, recoveryCodes := enroll(t, m, username, now)
+
+	// The code used to confirm enrollment can't be replayed.
+	if err := m.Verify(username, mustCode(t, secret, now)); err != twofactor.ErrInvalidCode {
+		t.Errorf("replayed enrollment code err=%v, want=%v", err, twofactor.ErrInvalidCode)
+	}
+
+	now = now.Add(totp.Period)
+	if err := m.Verify(username, mustCode(t, secret, now)); err != nil {
+		t.Errorf("valid code err=%v", err)
+	}
+	if err := m.Verify(username, mustCode(t, secret, now)); err != twofactor.ErrInvalidCode {
+		t.Errorf("replayed code err=%v, want=%v", err, twofactor.ErrInvalidCode)
+	}
+
+	// Recovery codes are case-insensitive and ignore the separator, but work
+	// only once.
+	if err := m.Verify(username, " "+string(recoveryCodes[0][:5])+string(recoveryCodes[0][6:])+" "); err != nil {
+		t.Errorf("recovery code err=%v", err)
+	}
+	if err := m.Verify(username, recoveryCodes[0].String()); err != twofactor.ErrInvalidCode {
+		t.Errorf("reused recovery code err=%v, want=%v", err, twofactor.ErrInvalidCode)
+	}
+	if err := m.Verify(username, recoveryCodes[1].String()); err != nil {
+		t.Errorf("second recovery code err=%v", err)
+	}
+
+	if err := m.Verify(screenjournal.Username("bob"), "123456"); err != twofactor.ErrNotEnabled {
+		t.Errorf("verify for user without 2FA err=%v, want=%v", err, twofactor.ErrNotEnabled)
+	}
+}
+
+func TestVerifyIsRateLimited(t *testing.T) {
+	now := baseTime
+	db := newMockStore()
+	m := twofactor.New(db, []byte("dummy-key"), func() time.Time { return now })
+	username := screenjournal.Username("alice")
+	secret, _ := enroll(t, m, username, now)
+
+	for range 5 {
+		if err := m.Verify(username, "000000"); err != twofactor.ErrInvalidCode {
+			t.Fatalf("wrong code err=%v, want=%v", err, twofactor.ErrInvalidCode)
+		}
+	}
+
+	now = now.Add(totp.Period)
+	if err := m.Verify(username, mustCode(t, secret, now)); err != twofactor.ErrTooManyAttempts {
+		t.Errorf("valid code after too many failures err=%v, want=%v", err, twofactor.ErrTooManyAttempts)
+	}
+
+	now = now.Add(15*time.Minute + time.Second)
+	if err := m.Verify(username, mustCode(t, secret, now)); err != nil {
+		t.Errorf("valid code after rate limit window err=%v", err)
+	}
+}
+
+func TestParallelGuessesAreRateLimited(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		enrolled    bool
+		guess       func(twofactor.Manager, screenjournal.Username) error
+	}{
+		{
+			description: "verifying a code",
+			enrolled:    true,
+			guess: func(m twofactor.Manager, username screenjournal.Username) error {
+				return m.Verify(username, "000000")
+			},
+		},
+		{
+			description: "confirming enrollment",
+			guess: func(m twofactor.Manager, username screenjournal.Username) error {
+				_, err := m.ConfirmEnrollment(username, "000000")
+				return err
+			},
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			now := baseTime
+			db := slowStore{newMockStore()}
+			m := twofactor.New(db, []byte("dummy-key"), func() time.Time { return now })
+			username := screenjournal.Username("alice")
+			if tt.enrolled {
+				enroll(t, m, username, now)
+			} else if _, err := m.BeginEnrollment(username); err != nil {
+				t.Fatalf("failed to begin enrollment: %v", err)
+			}
+
+			var checked atomic.Int32
+			var wg sync.WaitGroup
+			for range 20 {
+				wg.Go(func() {
+					switch err := tt.guess(m, username); err {
+					case twofactor.ErrInvalidCode:
+						checked.Add(1)
+					case twofactor.ErrTooManyAttempts:
+					default:
+						t.Errorf("err=%v, want=%v or %v", err, twofactor.ErrInvalidCode, twofactor.ErrTooManyAttempts)
+					}
+				})
+			}
+			wg.Wait()
+
+			if got, want := checked.Load(), int32(5); got != want {
+				t.Errorf("checked guesses=%d, want=%d", got, want)
+			}
+		})
+	}
+}
+
+func TestDisable(t *testing.T) {
+	now := baseTime
+	db := newMockStore()
+	m := twofactor.New(db, []byte("dummy-key"), func() time.Time { return now })
+	username := screenjournal.Username("alice")
+	secret, _ := enroll(t, m, username, now)
+
+	if err := m.Disable(username, "000000"); err != twofactor.ErrInvalidCode {
+		t.Fatalf("disable with wrong code err=%v, want=%v", err, twofactor.ErrInvalidCode)
+	}
+	if _, ok := db.configs[username]; !ok {
+		t.Fatalf("wrong code disabled two-factor authentication")
+	}
+
+	now = now.Add(totp.Period)
+	if err := m.Disable(username, mustCode(t, secret, now)); err != nil {
+		t.Fatalf("disable err=%v", err)
+	}
+	if _, ok := db.configs[username]; ok {
+		t.Errorf("two-factor configuration still exists after disabling")
+	}
+}
+
+func TestLoginChallenge(t *testing.T) {
+	now := baseTime
+	m := twofactor.New(newMockStore(), []byte("dummy-key"), func() time.Time { return now })
+	otherKey := twofactor.New(newMockStore(), []byte("other-key"), func() time.Time { return now })
+
+	challenge := m.IssueChallenge(screenjournal.Username("alice"))
+
+	for _, tt := range []struct {
+		description string
+		manager     twofactor.Manager
+		challenge   screenjournal.LoginChallenge
+		elapsed     time.Duration
+		username    screenjournal.Username
+		err         error
+	}{
+		{
+			description: "accepts fresh challenge",
+			manager:     m,
+			challenge:   challenge,
+			username:    "alice",
+		},
+		{
+			description: "accepts challenge just before expiry",
+			manager:     m,
+			challenge:   challenge,
+			elapsed:     5 * time.Minute,
+			username:    "alice",
+		},
+		{
+			description: "rejects expired challenge",
+			manager:     m,
+			challenge:   challenge,
+			elapsed:     5*time.Minute + time.Second,
+			err:         twofactor.ErrExpiredChallenge,
+		},
+		{
+			description: "rejects challenge signed with another key",
+			manager:     otherKey,
+			challenge:   challenge,
+			err:         twofactor.ErrInvalidChallenge,
+		},
+		{
+			description: "rejects tampered challenge",
+			manager:     m,
+			challenge:   "Ym9iOjk5OTk5OTk5OTk." + challenge[len(challenge)-43:],
+			err:         twofactor.ErrInvalidChallenge,
+		},
+		{
+			description: "rejects malformed challenge",
+			manager:     m,
+			challenge:   "garbage",
+			err:         twofactor.ErrInvalidChallenge,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			now = baseTime.Add(tt.elapsed)
+			username, err := tt.manager.VerifyChallenge(tt.challenge)
+			if got, want := err, tt.err; got != want {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := username, tt.username; got != want {
+				t.Errorf("username=%s, want=%s", got, want)
+			}
+		})
+	}
+}
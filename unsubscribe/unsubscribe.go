@@ -0,0 +1,71 @@
+// Package unsubscribe creates and verifies signed tokens that let users
+// unsubscribe from a category of email without logging in.
+package unsubscribe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+)
+
+var ErrInvalidToken = errors.New("invalid unsubscribe token")
+
+var encoding = base64.RawURLEncoding
+
+// Signer creates and verifies unsubscribe tokens. Tokens don't expire, so
+// that links in old emails keep working.
+type Signer struct {
+	key []byte
+}
+
+func New(key []byte) Signer {
+	if len(key) == 0 {
+		panic("unsubscribe signer requires a key")
+	}
+	return Signer{
+		key: key,
+	}
+}
+
+// Token returns a token that unsubscribes the user from the category.
+func (s Signer) Token(username screenjournal.Username, category screenjournal.UnsubscribeCategory) screenjournal.UnsubscribeToken {
+	payload := username.String() + ":" + category.String()
+	return screenjournal.UnsubscribeToken(encoding.EncodeToString([]byte(payload)) + "." + encoding.EncodeToString(s.sign(payload)))
+}
+
+// Verify checks the token's signature and returns the user and category it
+// applies to.
+func (s Signer) Verify(token screenjournal.UnsubscribeToken) (screenjournal.Username, screenjournal.UnsubscribeCategory, error) {
+	encodedPayload, encodedSig, ok := strings.Cut(token.String(), ".")
+	if !ok {
+		return screenjournal.Username(""), screenjournal.UnsubscribeCategory(""), ErrInvalidToken
+	}
+	payload, err := encoding.DecodeString(encodedPayload)
+	if err != nil {
+		return screenjournal.Username(""), screenjournal.UnsubscribeCategory(""), ErrInvalidToken
+	}
+	sig, err := encoding.DecodeString(encodedSig)
+	if err != nil {
+		return screenjournal.Username(""), screenjournal.UnsubscribeCategory(""), ErrInvalidToken
+	}
+	if !hmac.Equal(sig, s.sign(string(payload))) {
+		return screenjournal.Username(""), screenjournal.UnsubscribeCategory(""), ErrInvalidToken
+	}
+
+	username, category, ok := strings.Cut(string(payload), ":")
+	if !ok {
+		return screenjournal.Username(""), screenjournal.UnsubscribeCategory(""), ErrInvalidToken
+	}
+
+	return screenjournal.Username(username), screenjournal.UnsubscribeCategory(category), nil
+}
+
+func (s Signer) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
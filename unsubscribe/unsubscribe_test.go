@@ -0,0 +1,49 @@
+package unsubscribe_test
+
+import (
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/screenjournal"
+	"github.com/mtlynch/screenjournal/v2/unsubscribe"
+)
+
+func TestTokenRoundTrip(t *testing.T) {
+	signer := unsubscribe.New([]byte("dummy-key"))
+
+	token := signer.Token(screenjournal.Username("alice"), screenjournal.UnsubscribeNewReviews)
+	username, category, err := signer.Verify(token)
+	if err != nil {
+		t.Fatalf("failed to verify token: %v", err)
+	}
+	if got, want := username, screenjournal.Username("alice"); got != want {
+		t.Errorf("username=%v, want=%v", got, want)
+	}
+	if got, want := category, screenjournal.UnsubscribeNewReviews; got != want {
+		t.Errorf("category=%v, want=%v", got, want)
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	signer := unsubscribe.New([]byte("dummy-key"))
+	valid := signer.Token(screenjournal.Username("alice"), screenjournal.UnsubscribeComments).String()
+	otherUser := signer.Token(screenjournal.Username("bob"), screenjournal.UnsubscribeComments).String()
+	otherKey := unsubscribe.New([]byte("other-key")).Token(screenjournal.Username("alice"), screenjournal.UnsubscribeComments)
+
+	for _, tt := range []struct {
+		description string
+		token       screenjournal.UnsubscribeToken
+	}{
+		{"empty token", screenjournal.UnsubscribeToken("")},
+		{"token without signature", screenjournal.UnsubscribeToken(valid[:len(valid)-44])},
+		{"token with truncated signature", screenjournal.UnsubscribeToken(valid[:len(valid)-2])},
+		{"token signed with a different key", otherKey},
+		{"payload from one user with another user's signature", screenjournal.UnsubscribeToken(valid[:len(valid)-43] + otherUser[len(otherUser)-43:])},
+		{"token that isn't base64", screenjournal.UnsubscribeToken("!!!.???")},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			if _, _, err := signer.Verify(tt.token); err != unsubscribe.ErrInvalidToken {
+				t.Errorf("err=%v, want=%v", err, unsubscribe.ErrInvalidToken)
+			}
+		})
+	}
+}
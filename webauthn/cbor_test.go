@@ -0,0 +1,115 @@
+package webauthn
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	for _, tt := range []struct {
+		description string
+		input       []byte
+		want        any
+		rest        []byte
+		err         error
+	}{
+		{
+			description: "small unsigned integer",
+			input:       []byte{0x0a},
+			want:        int64(10),
+		},
+		{
+			description: "two-byte unsigned integer",
+			input:       []byte{0x19, 0x03, 0xe8},
+			want:        int64(1000),
+		},
+		{
+			description: "negative integer",
+			input:       []byte{0x26},
+			want:        int64(-7),
+		},
+		{
+			description: "two-byte negative integer",
+			input:       []byte{0x39, 0x01, 0x00},
+			want:        int64(-257),
+		},
+		{
+			description: "byte string",
+			input:       []byte{0x43, 0x01, 0x02, 0x03},
+			want:        []byte{0x01, 0x02, 0x03},
+		},
+		{
+			description: "text string",
+			input:       []byte{0x64, 'n', 'o', 'n', 'e'},
+			want:        "none",
+		},
+		{
+			description: "array",
+			input:       []byte{0x82, 0x01, 0xf5},
+			want:        []any{int64(1), true},
+		},
+		{
+			description: "map with integer and string keys",
+			input:       []byte{0xa2, 0x01, 0x02, 0x63, 'f', 'm', 't', 0xf6},
+			want:        map[any]any{int64(1): int64(2), "fmt": nil},
+		},
+		{
+			description: "returns bytes after the first item",
+			input:       []byte{0x01, 0x02, 0x03},
+			want:        int64(1),
+			rest:        []byte{0x02, 0x03},
+		},
+		{
+			description: "empty input fails",
+			input:       []byte{},
+			err:         errInvalidCBOR,
+		},
+		{
+			description: "byte string longer than data fails",
+			input:       []byte{0x45, 0x01},
+			err:         errInvalidCBOR,
+		},
+		{
+			description: "huge array length fails without allocating",
+			input:       []byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			err:         errInvalidCBOR,
+		},
+		{
+			description: "indefinite-length string fails",
+			input:       []byte{0x5f, 0x41, 0x01, 0xff},
+			err:         errInvalidCBOR,
+		},
+		{
+			description: "byte string map key fails",
+			input:       []byte{0xa1, 0x41, 0x01, 0x01},
+			err:         errInvalidCBOR,
+		},
+		{
+			description: "duplicate map key fails",
+			input:       []byte{0xa2, 0x01, 0x01, 0x01, 0x02},
+			err:         errInvalidCBOR,
+		},
+		{
+			description: "deeply nested arrays fail",
+			input:       []byte{0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x01},
+			err:         errInvalidCBOR,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			got, rest, err := decodeCBOR(tt.input)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err=%v, want=%v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decoded=%#v, want=%#v", got, tt.want)
+			}
+			if len(rest) != len(tt.rest) || (len(rest) > 0 && !reflect.DeepEqual(rest, tt.rest)) {
+				t.Errorf("rest=%x, want=%x", rest, tt.rest)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithm identifiers from the IANA registry.
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// SupportedAlgorithms lists the signature algorithms that ScreenJournal
+// accepts, in order of preference.
+var SupportedAlgorithms = []int{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE key parameters from RFC 9052 and RFC 9053.
+const (
+	coseKeyType      = 1
+	coseKeyAlgorithm = 3
+
+	coseKeyTypeOKP = 1
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+
+	// EC2 and OKP keys share the -1 and -2 labels; RSA keys reuse them for
+	// different fields.
+	coseEC2Curve = -1
+	coseEC2X     = -2
+	coseEC2Y     = -3
+	coseRSAN     = -1
+	coseRSAE     = -2
+
+	// minRSAKeyBits rejects RSA keys too short to be secure.
+	minRSAKeyBits = 2048
+)
+
+var ErrUnsupportedKey = errors.New("unsupported public key")
+
+// publicKey is a parsed credential public key.
+type publicKey struct {
+	algorithm int
+	key       crypto.PublicKey
+}
+
+// parsePublicKey parses a COSE_Key structure.
+func parsePublicKey(raw []byte) (publicKey, error) {
+	decoded, rest, err := decodeCBOR(raw)
+	if err != nil {
+		return publicKey{}, err
+	}
+	if len(rest) != 0 {
+		return publicKey{}, fmt.Errorf("%w: trailing data after key", ErrUnsupportedKey)
+	}
+	m, ok := decoded.(map[any]any)
+	if !ok {
+		return publicKey{}, fmt.Errorf("%w: key is not a map", ErrUnsupportedKey)
+	}
+
+	kty, _ := m[int64(coseKeyType)].(int64)
+	alg, _ := m[int64(coseKeyAlgorithm)].(int64)
+
+	switch {
+	case kty == coseKeyTypeEC2 && alg == AlgES256:
+		crv, _ := m[int64(coseEC2Curve)].(int64)
+		x, _ := m[int64(coseEC2X)].([]byte)
+		y, _ := m[int64(coseEC2Y)].([]byte)
+		if crv != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return publicKey{}, fmt.Errorf("%w: invalid P-256 key", ErrUnsupportedKey)
+		}
+		point := append(append([]byte{0x04}, x...), y...)
+		key, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+		if err != nil {
+			return publicKey{}, fmt.Errorf("%w: %v", ErrUnsupportedKey, err)
+		}
+		return publicKey{algorithm: AlgES256, key: key}, nil
+	case kty == coseKeyTypeOKP && alg == AlgEdDSA:
+		crv, _ := m[int64(coseEC2Curve)].(int64)
+		x, _ := m[int64(coseEC2X)].([]byte)
+		if crv != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return publicKey{}, fmt.Errorf("%w: invalid Ed25519 key", ErrUnsupportedKey)
+		}
+		return publicKey{algorithm: AlgEdDSA, key: ed25519.PublicKey(x)}, nil
+	case kty == coseKeyTypeRSA && alg == AlgRS256:
+		n, _ := m[int64(coseRSAN)].([]byte)
+		e, _ := m[int64(coseRSAE)].([]byte)
+		if len(n)*8 < minRSAKeyBits || len(e) == 0 || len(e) > 4 {
+			return publicKey{}, fmt.Errorf("%w: invalid RSA key", ErrUnsupportedKey)
+		}
+		exponent := 0
+		for _, b := range e {
+			exponent = exponent<<8 | int(b)
+		}
+		return publicKey{
+			algorithm: AlgRS256,
+			key: &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: exponent,
+			},
+		}, nil
+	}
+
+	return publicKey{}, fmt.Errorf("%w: key type %d with algorithm %d", ErrUnsupportedKey, kty, alg)
+}
+
+// verify checks the signature over data.
+func (k publicKey) verify(data, signature []byte) bool {
+	switch k.algorithm {
+	case AlgES256:
+		digest := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(k.key.(*ecdsa.PublicKey), digest[:], signature)
+	case AlgEdDSA:
+		return ed25519.Verify(k.key.(ed25519.PublicKey), data, signature)
+	case AlgRS256:
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(k.key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	}
+	return false
+}
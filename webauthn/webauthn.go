@@ -0,0 +1,450 @@
+// Package webauthn implements the relying party side of Web Authentication
+// (WebAuthn) for passkey registration and login.
+//
+// It verifies the signatures that prove possession of a passkey but doesn't
+// verify attestation statements. ScreenJournal requests no attestation, so it
+// trusts any authenticator the user chooses, and it stores the public key the
+// browser reports at registration.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mtlynch/screenjournal/v2/random"
+)
+
+const (
+	// challengeBytes is the size of each random challenge. The spec requires
+	// at least 16.
+	challengeBytes = 32
+	// timeoutMilliseconds is how long the browser waits for the user to
+	// interact with their authenticator.
+	timeoutMilliseconds = 5 * 60 * 1000
+	// maxCredentialIDLength is the limit the WebAuthn spec places on
+	// credential IDs.
+	maxCredentialIDLength = 1023
+
+	flagUserPresent            = 0x01
+	flagUserVerified           = 0x04
+	flagAttestedCredentialData = 0x40
+	flagExtensionData          = 0x80
+
+	// authenticatorDataMinLength is the length of the RP ID hash, flags, and
+	// signature counter that begin all authenticator data.
+	authenticatorDataMinLength = 37
+	aaguidLength               = 16
+)
+
+var (
+	ErrInvalidResponse      = errors.New("invalid WebAuthn response")
+	ErrInvalidSignature     = errors.New("invalid WebAuthn signature")
+	ErrSignCountRegressed   = errors.New("authenticator signature counter went backwards")
+	ErrUserNotVerified      = errors.New("authenticator did not verify the user")
+	ErrInvalidRelyingParty  = errors.New("invalid relying party origin")
+	errChallengeMismatch    = errors.New("challenge does not match")
+	errOriginMismatch       = errors.New("origin does not match")
+	errRelyingPartyMismatch = errors.New("relying party ID does not match")
+)
+
+type (
+	// RelyingParty holds the identity of the ScreenJournal server that
+	// passkeys are bound to.
+	RelyingParty struct {
+		id     string
+		name   string
+		origin string
+	}
+
+	// Bytes is binary data that appears in JSON as an unpadded base64url
+	// string, matching the JSON serialization of WebAuthn structures.
+	Bytes []byte
+
+	RelyingPartyEntity struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	UserEntity struct {
+		ID          Bytes  `json:"id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	}
+
+	CredentialParameter struct {
+		Type      string `json:"type"`
+		Algorithm int    `json:"alg"`
+	}
+
+	CredentialDescriptor struct {
+		Type string `json:"type"`
+		ID   Bytes  `json:"id"`
+	}
+
+	AuthenticatorSelection struct {
+		ResidentKey      string `json:"residentKey"`
+		RequireResident  bool   `json:"requireResidentKey"`
+		UserVerification string `json:"userVerification"`
+	}
+
+	// CreationOptions are the options the browser passes to
+	// navigator.credentials.create() to register a passkey.
+	CreationOptions struct {
+		Challenge              Bytes                  `json:"challenge"`
+		RelyingParty           RelyingPartyEntity     `json:"rp"`
+		User                   UserEntity             `json:"user"`
+		PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+		Timeout                int                    `json:"timeout"`
+		ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+		AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+		Attestation            string                 `json:"attestation"`
+	}
+
+	// RequestOptions are the options the browser passes to
+	// navigator.credentials.get() to log in with a passkey.
+	RequestOptions struct {
+		Challenge        Bytes  `json:"challenge"`
+		Timeout          int    `json:"timeout"`
+		RelyingPartyID   string `json:"rpId"`
+		UserVerification string `json:"userVerification"`
+	}
+
+	// RegistrationResponse is the credential that
+	// navigator.credentials.create() returns.
+	RegistrationResponse struct {
+		ID       string `json:"id"`
+		RawID    Bytes  `json:"rawId"`
+		Type     string `json:"type"`
+		Response struct {
+			ClientDataJSON    Bytes `json:"clientDataJSON"`
+			AttestationObject Bytes `json:"attestationObject"`
+		} `json:"response"`
+	}
+
+	// AssertionResponse is the credential that navigator.credentials.get()
+	// returns.
+	AssertionResponse struct {
+		ID       string `json:"id"`
+		RawID    Bytes  `json:"rawId"`
+		Type     string `json:"type"`
+		Response struct {
+			ClientDataJSON    Bytes `json:"clientDataJSON"`
+			AuthenticatorData Bytes `json:"authenticatorData"`
+			Signature         Bytes `json:"signature"`
+			UserHandle        Bytes `json:"userHandle"`
+		} `json:"response"`
+	}
+
+	// Credential is a newly registered passkey.
+	Credential struct {
+		ID        []byte
+		PublicKey []byte
+		SignCount uint32
+	}
+
+	clientData struct {
+		Type        string `json:"type"`
+		Challenge   string `json:"challenge"`
+		Origin      string `json:"origin"`
+		CrossOrigin bool   `json:"crossOrigin"`
+	}
+
+	authenticatorData struct {
+		rpIDHash     []byte
+		flags        byte
+		signCount    uint32
+		credentialID []byte
+		publicKey    []byte
+	}
+)
+
+var encoding = base64.RawURLEncoding
+
+// New creates a relying party for the server at origin, such as
+// https://screenjournal.example.com. Passkeys are bound to the origin's
+// hostname.
+func New(origin, name string) (RelyingParty, error) {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return RelyingParty{}, fmt.Errorf("%w: %v", ErrInvalidRelyingParty, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Hostname() == "" {
+		return RelyingParty{}, fmt.Errorf("%w: %s", ErrInvalidRelyingParty, origin)
+	}
+	return RelyingParty{
+		id:     u.Hostname(),
+		name:   name,
+		origin: u.Scheme + "://" + u.Host,
+	}, nil
+}
+
+// NewChallenge returns a random challenge for a single registration or login
+// ceremony.
+func NewChallenge() []byte {
+	return random.Bytes(challengeBytes)
+}
+
+// CreationOptions returns the options for registering a new passkey. The
+// browser refuses to register a second passkey on an authenticator that
+// already holds one of the excluded credentials.
+func (rp RelyingParty) CreationOptions(challenge, userID []byte, userName string, exclude [][]byte) CreationOptions {
+	params := make([]CredentialParameter, len(SupportedAlgorithms))
+	for i, alg := range SupportedAlgorithms {
+		params[i] = CredentialParameter{Type: "public-key", Algorithm: alg}
+	}
+	excludeCredentials := make([]CredentialDescriptor, len(exclude))
+	for i, id := range exclude {
+		excludeCredentials[i] = CredentialDescriptor{Type: "public-key", ID: id}
+	}
+	return CreationOptions{
+		Challenge: challenge,
+		RelyingParty: RelyingPartyEntity{
+			ID:   rp.id,
+			Name: rp.name,
+		},
+		User: UserEntity{
+			ID:          userID,
+			Name:        userName,
+			DisplayName: userName,
+		},
+		PubKeyCredParams:   params,
+		Timeout:            timeoutMilliseconds,
+		ExcludeCredentials: excludeCredentials,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "required",
+			RequireResident:  true,
+			UserVerification: "required",
+		},
+		Attestation: "none",
+	}
+}
+
+// RequestOptions returns the options for logging in with any passkey the
+// user has for this relying party.
+func (rp RelyingParty) RequestOptions(challenge []byte) RequestOptions {
+	return RequestOptions{
+		Challenge:        challenge,
+		Timeout:          timeoutMilliseconds,
+		RelyingPartyID:   rp.id,
+		UserVerification: "required",
+	}
+}
+
+// VerifyRegistration checks a response to CreationOptions with the given
+// challenge and returns the new credential.
+func (rp RelyingParty) VerifyRegistration(challenge []byte, r RegistrationResponse) (Credential, error) {
+	if r.Type != "public-key" {
+		return Credential{}, fmt.Errorf("%w: unexpected credential type %q", ErrInvalidResponse, r.Type)
+	}
+	if err := rp.verifyClientData(r.Response.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return Credential{}, err
+	}
+
+	decoded, rest, err := decodeCBOR(r.Response.AttestationObject)
+	if err != nil {
+		return Credential{}, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	if len(rest) != 0 {
+		return Credential{}, fmt.Errorf("%w: trailing data after attestation object", ErrInvalidResponse)
+	}
+	attestation, ok := decoded.(map[any]any)
+	if !ok {
+		return Credential{}, fmt.Errorf("%w: attestation object is not a map", ErrInvalidResponse)
+	}
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return Credential{}, fmt.Errorf("%w: attestation object has no authenticator data", ErrInvalidResponse)
+	}
+
+	authData, err := rp.verifyAuthenticatorData(rawAuthData)
+	if err != nil {
+		return Credential{}, err
+	}
+	if authData.flags&flagAttestedCredentialData == 0 {
+		return Credential{}, fmt.Errorf("%w: authenticator data has no credential", ErrInvalidResponse)
+	}
+	if !bytes.Equal(authData.credentialID, r.RawID) {
+		return Credential{}, fmt.Errorf("%w: credential ID does not match authenticator data", ErrInvalidResponse)
+	}
+	if _, err := parsePublicKey(authData.publicKey); err != nil {
+		return Credential{}, err
+	}
+
+	return Credential{
+		ID:        authData.credentialID,
+		PublicKey: authData.publicKey,
+		SignCount: authData.signCount,
+	}, nil
+}
+
+// VerifyAssertion checks a response to RequestOptions with the given
+// challenge against a stored credential's public key and signature counter.
+// It returns the authenticator's new signature counter.
+func (rp RelyingParty) VerifyAssertion(challenge []byte, r AssertionResponse, storedPublicKey []byte, storedSignCount uint32) (uint32, error) {
+	if r.Type != "public-key" {
+		return 0, fmt.Errorf("%w: unexpected credential type %q", ErrInvalidResponse, r.Type)
+	}
+	if err := rp.verifyClientData(r.Response.ClientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+
+	authData, err := rp.verifyAuthenticatorData(r.Response.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+
+	key, err := parsePublicKey(storedPublicKey)
+	if err != nil {
+		return 0, err
+	}
+	clientDataHash := sha256.Sum256(r.Response.ClientDataJSON)
+	signed := append(append([]byte{}, r.Response.AuthenticatorData...), clientDataHash[:]...)
+	if !key.verify(signed, r.Response.Signature) {
+		return 0, ErrInvalidSignature
+	}
+
+	// Authenticators that don't keep a counter always report zero. Otherwise,
+	// a counter that doesn't increase suggests a cloned authenticator.
+	if (authData.signCount != 0 || storedSignCount != 0) && authData.signCount <= storedSignCount {
+		return 0, ErrSignCountRegressed
+	}
+
+	return authData.signCount, nil
+}
+
+// Challenge returns the challenge that the browser says it signed, so that
+// the server can look up the ceremony that the response belongs to. It
+// doesn't verify the response.
+func (r RegistrationResponse) Challenge() ([]byte, error) {
+	return challengeFromClientData(r.Response.ClientDataJSON)
+}
+
+// Challenge returns the challenge that the browser says it signed, so that
+// the server can look up the ceremony that the response belongs to. It
+// doesn't verify the response.
+func (r AssertionResponse) Challenge() ([]byte, error) {
+	return challengeFromClientData(r.Response.ClientDataJSON)
+}
+
+func (rp RelyingParty) verifyClientData(raw []byte, ceremony string, challenge []byte) error {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	if cd.Type != ceremony {
+		return fmt.Errorf("%w: unexpected ceremony %q", ErrInvalidResponse, cd.Type)
+	}
+	got, err := decodeChallenge(cd.Challenge)
+	if err != nil || subtle.ConstantTimeCompare(got, challenge) != 1 {
+		return fmt.Errorf("%w: %v", ErrInvalidResponse, errChallengeMismatch)
+	}
+	if cd.Origin != rp.origin || cd.CrossOrigin {
+		return fmt.Errorf("%w: %v: %s", ErrInvalidResponse, errOriginMismatch, cd.Origin)
+	}
+	return nil
+}
+
+func (rp RelyingParty) verifyAuthenticatorData(raw []byte) (authenticatorData, error) {
+	authData, err := parseAuthenticatorData(raw)
+	if err != nil {
+		return authenticatorData{}, err
+	}
+	rpIDHash := sha256.Sum256([]byte(rp.id))
+	if !bytes.Equal(authData.rpIDHash, rpIDHash[:]) {
+		return authenticatorData{}, fmt.Errorf("%w: %v", ErrInvalidResponse, errRelyingPartyMismatch)
+	}
+	if authData.flags&flagUserPresent == 0 {
+		return authenticatorData{}, fmt.Errorf("%w: user was not present", ErrInvalidResponse)
+	}
+	if authData.flags&flagUserVerified == 0 {
+		return authenticatorData{}, ErrUserNotVerified
+	}
+	return authData, nil
+}
+
+func challengeFromClientData(raw []byte) ([]byte, error) {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	challenge, err := decodeChallenge(cd.Challenge)
+	if err != nil || len(challenge) == 0 {
+		return nil, fmt.Errorf("%w: invalid challenge", ErrInvalidResponse)
+	}
+	return challenge, nil
+}
+
+func decodeChallenge(raw string) ([]byte, error) {
+	return encoding.DecodeString(strings.TrimRight(raw, "="))
+}
+
+func parseAuthenticatorData(raw []byte) (authenticatorData, error) {
+	if len(raw) < authenticatorDataMinLength {
+		return authenticatorData{}, fmt.Errorf("%w: authenticator data is too short", ErrInvalidResponse)
+	}
+	authData := authenticatorData{
+		rpIDHash:  raw[:32],
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	rest := raw[authenticatorDataMinLength:]
+
+	if authData.flags&flagAttestedCredentialData != 0 {
+		if len(rest) < aaguidLength+2 {
+			return authenticatorData{}, fmt.Errorf("%w: attested credential data is too short", ErrInvalidResponse)
+		}
+		rest = rest[aaguidLength:]
+		idLength := int(binary.BigEndian.Uint16(rest))
+		rest = rest[2:]
+		if idLength > maxCredentialIDLength || idLength > len(rest) {
+			return authenticatorData{}, fmt.Errorf("%w: invalid credential ID length", ErrInvalidResponse)
+		}
+		authData.credentialID = rest[:idLength]
+		rest = rest[idLength:]
+
+		_, afterKey, err := decodeCBOR(rest)
+		if err != nil {
+			return authenticatorData{}, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+		}
+		authData.publicKey = rest[:len(rest)-len(afterKey)]
+		rest = afterKey
+	}
+
+	if authData.flags&flagExtensionData != 0 {
+		_, afterExtensions, err := decodeCBOR(rest)
+		if err != nil {
+			return authenticatorData{}, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+		}
+		rest = afterExtensions
+	}
+	if len(rest) != 0 {
+		return authenticatorData{}, fmt.Errorf("%w: trailing data after authenticator data", ErrInvalidResponse)
+	}
+
+	return authData, nil
+}
+
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encoding.EncodeToString(b))
+}
+
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	decoded, err := encoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
@@ -0,0 +1,226 @@
+package webauthn_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mtlynch/screenjournal/v2/webauthn"
+	"github.com/mtlynch/screenjournal/v2/webauthn/webauthntest"
+)
+
+const testOrigin = "https://screenjournal.example.com"
+
+func mustNewRelyingParty(t *testing.T, origin string) webauthn.RelyingParty {
+	t.Helper()
+	rp, err := webauthn.New(origin, "ScreenJournal")
+	if err != nil {
+		t.Fatalf("failed to create relying party: %v", err)
+	}
+	return rp
+}
+
+func mustRegister(t *testing.T, rp webauthn.RelyingParty, authenticator *webauthntest.Authenticator) webauthn.Credential {
+	t.Helper()
+	challenge := webauthn.NewChallenge()
+	response, err := authenticator.Register(rp.CreationOptions(challenge, []byte("alice"), "alice", nil))
+	if err != nil {
+		t.Fatalf("authenticator failed to register: %v", err)
+	}
+	cred, err := rp.VerifyRegistration(challenge, response)
+	if err != nil {
+		t.Fatalf("failed to verify registration: %v", err)
+	}
+	return cred
+}
+
+func TestNew(t *testing.T) {
+	for _, tt := range []struct {
+		origin string
+		valid  bool
+	}{
+		{"https://screenjournal.example.com", true},
+		{"https://screenjournal.example.com/", true},
+		{"http://localhost:4003", true},
+		{"ftp://example.com", false},
+		{"screenjournal.example.com", false},
+		{"", false},
+	} {
+		_, err := webauthn.New(tt.origin, "ScreenJournal")
+		if got, want := err == nil, tt.valid; got != want {
+			t.Errorf("New(%q) err=%v, want valid=%v", tt.origin, err, tt.valid)
+		}
+	}
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	rp := mustNewRelyingParty(t, testOrigin)
+
+	for _, tt := range []struct {
+		description          string
+		authenticatorOrigin  string
+		skipUserVerification bool
+		tamper               func(*webauthn.RegistrationResponse)
+		wrongChallenge       bool
+		err                  error
+	}{
+		{
+			description:         "valid registration succeeds",
+			authenticatorOrigin: testOrigin,
+		},
+		{
+			description:         "registration from another origin fails",
+			authenticatorOrigin: "https://evil.example.com",
+			err:                 webauthn.ErrInvalidResponse,
+		},
+		{
+			description:         "registration for another challenge fails",
+			authenticatorOrigin: testOrigin,
+			wrongChallenge:      true,
+			err:                 webauthn.ErrInvalidResponse,
+		},
+		{
+			description:          "registration without user verification fails",
+			authenticatorOrigin:  testOrigin,
+			skipUserVerification: true,
+			err:                  webauthn.ErrUserNotVerified,
+		},
+		{
+			description:         "registration with mismatched credential ID fails",
+			authenticatorOrigin: testOrigin,
+			tamper: func(r *webauthn.RegistrationResponse) {
+				r.RawID = []byte("some-other-credential")
+			},
+			err: webauthn.ErrInvalidResponse,
+		},
+		{
+			description:         "registration with truncated attestation object fails",
+			authenticatorOrigin: testOrigin,
+			tamper: func(r *webauthn.RegistrationResponse) {
+				r.Response.AttestationObject = r.Response.AttestationObject[:len(r.Response.AttestationObject)-10]
+			},
+			err: webauthn.ErrInvalidResponse,
+		},
+		{
+			description:         "registration with wrong ceremony type fails",
+			authenticatorOrigin: testOrigin,
+			tamper: func(r *webauthn.RegistrationResponse) {
+				r.Type = "password"
+			},
+			err: webauthn.ErrInvalidResponse,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			authenticator := webauthntest.New(tt.authenticatorOrigin)
+			authenticator.SkipUserVerification = tt.skipUserVerification
+
+			challenge := webauthn.NewChallenge()
+			response, err := authenticator.Register(rp.CreationOptions(challenge, []byte("alice"), "alice", nil))
+			if err != nil {
+				t.Fatalf("authenticator failed to register: %v", err)
+			}
+			if tt.tamper != nil {
+				tt.tamper(&response)
+			}
+			if tt.wrongChallenge {
+				challenge = webauthn.NewChallenge()
+			}
+
+			cred, err := rp.VerifyRegistration(challenge, response)
+			if got, want := err, tt.err; !errors.Is(got, want) {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if err != nil {
+				return
+			}
+			if got, want := string(cred.ID), string(response.RawID); got != want {
+				t.Errorf("credential ID=%x, want=%x", got, want)
+			}
+			if len(cred.PublicKey) == 0 {
+				t.Errorf("credential has no public key")
+			}
+		})
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	rp := mustNewRelyingParty(t, testOrigin)
+
+	for _, tt := range []struct {
+		description     string
+		tamper          func(*webauthn.AssertionResponse)
+		wrongChallenge  bool
+		wrongKey        bool
+		storedSignCount uint32
+		signCountWant   uint32
+		err             error
+	}{
+		{
+			description:   "valid assertion succeeds",
+			signCountWant: 1,
+		},
+		{
+			description:    "assertion for another challenge fails",
+			wrongChallenge: true,
+			err:            webauthn.ErrInvalidResponse,
+		},
+		{
+			description: "assertion signed by another passkey fails",
+			wrongKey:    true,
+			err:         webauthn.ErrInvalidSignature,
+		},
+		{
+			description: "assertion with tampered authenticator data fails",
+			tamper: func(r *webauthn.AssertionResponse) {
+				r.Response.AuthenticatorData[len(r.Response.AuthenticatorData)-1]++
+			},
+			err: webauthn.ErrInvalidSignature,
+		},
+		{
+			description: "assertion with tampered client data fails",
+			tamper: func(r *webauthn.AssertionResponse) {
+				r.Response.ClientDataJSON = append(r.Response.ClientDataJSON[:len(r.Response.ClientDataJSON)-1], []byte(`,"extra":1}`)...)
+			},
+			err: webauthn.ErrInvalidSignature,
+		},
+		{
+			description:     "assertion with a counter that went backwards fails",
+			storedSignCount: 5,
+			err:             webauthn.ErrSignCountRegressed,
+		},
+		{
+			description: "assertion with garbage signature fails",
+			tamper: func(r *webauthn.AssertionResponse) {
+				r.Response.Signature = []byte("not a signature")
+			},
+			err: webauthn.ErrInvalidSignature,
+		},
+	} {
+		t.Run(tt.description, func(t *testing.T) {
+			authenticator := webauthntest.New(testOrigin)
+			cred := mustRegister(t, rp, authenticator)
+			if tt.wrongKey {
+				cred = mustRegister(t, rp, webauthntest.New(testOrigin))
+			}
+
+			challenge := webauthn.NewChallenge()
+			response, err := authenticator.Login(rp.RequestOptions(challenge))
+			if err != nil {
+				t.Fatalf("authenticator failed to log in: %v", err)
+			}
+			if tt.tamper != nil {
+				tt.tamper(&response)
+			}
+			if tt.wrongChallenge {
+				challenge = webauthn.NewChallenge()
+			}
+
+			signCount, err := rp.VerifyAssertion(challenge, response, cred.PublicKey, tt.storedSignCount)
+			if got, want := err, tt.err; !errors.Is(got, want) {
+				t.Fatalf("err=%v, want=%v", got, want)
+			}
+			if got, want := signCount, tt.signCountWant; got != want {
+				t.Errorf("signCount=%d, want=%d", got, want)
+			}
+		})
+	}
+}
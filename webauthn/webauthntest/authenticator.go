@@ -0,0 +1,195 @@
+// Package webauthntest provides a software authenticator for testing code that
+// registers and logs in with passkeys.
+package webauthntest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/mtlynch/screenjournal/v2/webauthn"
+)
+
+var ErrNoCredential = errors.New("authenticator has no matching credential")
+
+type (
+	// Authenticator behaves like a platform authenticator with a browser in
+	// front of it. It creates ES256 passkeys and signs assertions for them.
+	Authenticator struct {
+		origin      string
+		credentials []*credential
+
+		// SkipUserVerification simulates an authenticator that only checks for
+		// user presence, such as a security key without a PIN.
+		SkipUserVerification bool
+	}
+
+	credential struct {
+		id         []byte
+		rpID       string
+		userHandle []byte
+		key        *ecdsa.PrivateKey
+		signCount  uint32
+	}
+)
+
+// New creates an authenticator that reports the given origin in its client
+// data, as a browser would for a page on that origin.
+func New(origin string) *Authenticator {
+	return &Authenticator{
+		origin: origin,
+	}
+}
+
+// Register creates a new passkey as navigator.credentials.create() would.
+func (a *Authenticator) Register(options webauthn.CreationOptions) (webauthn.RegistrationResponse, error) {
+	if !slices.ContainsFunc(options.PubKeyCredParams, func(p webauthn.CredentialParameter) bool {
+		return p.Algorithm == webauthn.AlgES256
+	}) {
+		return webauthn.RegistrationResponse{}, errors.New("relying party doesn't accept ES256")
+	}
+	for _, excluded := range options.ExcludeCredentials {
+		if a.find(excluded.ID, options.RelyingParty.ID) != nil {
+			return webauthn.RegistrationResponse{}, errors.New("authenticator already holds an excluded credential")
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return webauthn.RegistrationResponse{}, err
+	}
+	cred := &credential{
+		id:         randomBytes(16),
+		rpID:       options.RelyingParty.ID,
+		userHandle: options.User.ID,
+		key:        key,
+	}
+	a.credentials = append(a.credentials, cred)
+
+	point, err := key.PublicKey.Bytes()
+	if err != nil {
+		return webauthn.RegistrationResponse{}, err
+	}
+	coseKey := encodeCBOR(cborMap{
+		{int64(1), int64(2)},     // kty: EC2
+		{int64(3), int64(-7)},    // alg: ES256
+		{int64(-1), int64(1)},    // crv: P-256
+		{int64(-2), point[1:33]}, // x
+		{int64(-3), point[33:]},  // y
+	})
+
+	attestedCredentialData := make([]byte, 16, 16+2+len(cred.id)+len(coseKey))
+	attestedCredentialData = binary.BigEndian.AppendUint16(attestedCredentialData, uint16(len(cred.id)))
+	attestedCredentialData = append(attestedCredentialData, cred.id...)
+	attestedCredentialData = append(attestedCredentialData, coseKey...)
+
+	authData := a.authenticatorData(cred, 0x40)
+	authData = append(authData, attestedCredentialData...)
+
+	var r webauthn.RegistrationResponse
+	r.ID = base64.RawURLEncoding.EncodeToString(cred.id)
+	r.RawID = cred.id
+	r.Type = "public-key"
+	r.Response.ClientDataJSON = a.clientData("webauthn.create", options.Challenge)
+	r.Response.AttestationObject = encodeCBOR(cborMap{
+		{"fmt", "none"},
+		{"attStmt", cborMap{}},
+		{"authData", authData},
+	})
+	return r, nil
+}
+
+// Login signs an assertion with the most recently registered passkey for the
+// relying party, as navigator.credentials.get() would when the user picks
+// that passkey.
+func (a *Authenticator) Login(options webauthn.RequestOptions) (webauthn.AssertionResponse, error) {
+	for _, cred := range slices.Backward(a.credentials) {
+		if cred.rpID == options.RelyingPartyID {
+			return a.assert(cred, options)
+		}
+	}
+	return webauthn.AssertionResponse{}, ErrNoCredential
+}
+
+// LoginWith signs an assertion with a specific passkey.
+func (a *Authenticator) LoginWith(id []byte, options webauthn.RequestOptions) (webauthn.AssertionResponse, error) {
+	cred := a.find(id, options.RelyingPartyID)
+	if cred == nil {
+		return webauthn.AssertionResponse{}, ErrNoCredential
+	}
+	return a.assert(cred, options)
+}
+
+func (a *Authenticator) assert(cred *credential, options webauthn.RequestOptions) (webauthn.AssertionResponse, error) {
+	cred.signCount++
+	authData := a.authenticatorData(cred, 0)
+	clientData := a.clientData("webauthn.get", options.Challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, cred.key, digest[:])
+	if err != nil {
+		return webauthn.AssertionResponse{}, err
+	}
+
+	var r webauthn.AssertionResponse
+	r.ID = base64.RawURLEncoding.EncodeToString(cred.id)
+	r.RawID = cred.id
+	r.Type = "public-key"
+	r.Response.ClientDataJSON = clientData
+	r.Response.AuthenticatorData = authData
+	r.Response.Signature = signature
+	r.Response.UserHandle = cred.userHandle
+	return r, nil
+}
+
+func (a *Authenticator) find(id []byte, rpID string) *credential {
+	for _, cred := range a.credentials {
+		if cred.rpID == rpID && string(cred.id) == string(id) {
+			return cred
+		}
+	}
+	return nil
+}
+
+func (a *Authenticator) authenticatorData(cred *credential, extraFlags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(cred.rpID))
+	flags := byte(0x01) | extraFlags
+	if !a.SkipUserVerification {
+		flags |= 0x04
+	}
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	return binary.BigEndian.AppendUint32(data, cred.signCount)
+}
+
+func (a *Authenticator) clientData(ceremony string, challenge []byte) []byte {
+	data, err := json.Marshal(struct {
+		Type        string `json:"type"`
+		Challenge   string `json:"challenge"`
+		Origin      string `json:"origin"`
+		CrossOrigin bool   `json:"crossOrigin"`
+	}{
+		Type:      ceremony,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    a.origin,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("failed to encode client data: %v", err))
+	}
+	return data
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate random bytes: %v", err))
+	}
+	return b
+}